@@ -4,19 +4,26 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"net"
+	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
 	"perf-runner/config"
 	"perf-runner/coordinator"
+	"perf-runner/envinfo"
 	"perf-runner/output"
 	"perf-runner/runner"
 )
 
 const appVersion = "1.0.0"
 
+// httpClient is shared by everything that sends results to external services
+var httpClient = &http.Client{Timeout: 30 * time.Second}
+
 // App represents the main application
 type App struct {
 	flags  *Flags
@@ -46,9 +53,39 @@ func (a *App) Run() error {
 		return nil
 	}
 	
+	// Listing runners needs no configuration
+	if *a.flags.ListRunners {
+		return writeRunnerList(os.Stdout)
+	}
+	
+	// Resolve text output coloring
+	colorMode := *a.flags.Color
+	if *a.flags.NoColor {
+		colorMode = output.ColorNever
+	}
+	useColor, err := output.ResolveColorMode(colorMode, os.Stdout)
+	if err != nil {
+		return err
+	}
+	
+	if err := output.ValidateBandwidthUnit(*a.flags.BwUnit); err != nil {
+		return err
+	}
+	
+	format, err := output.ResolveFormat(*a.flags.OutputFormat, *a.flags.JSONOutput)
+	if err != nil {
+		return err
+	}
+	*a.flags.OutputFormat = format
+	
+	// Report parser diagnostics, such as conflicting metric values, when verbose
+	if *a.flags.Verbose {
+		runner.SetDebugLogger(a.logger)
+	}
+	
 	// Load configuration
-	a.logger.Printf("Loading configuration from %s", *a.flags.ConfigFile)
-	cfg, err := config.LoadConfig(*a.flags.ConfigFile)
+	a.logger.Printf("Loading configuration from %s", a.flags.ConfigFiles)
+	cfg, err := config.LoadConfigs(*a.flags.ConfigFiles...)
 	if err != nil {
 		return fmt.Errorf("failed to load configuration: %w", err)
 	}
@@ -58,6 +95,29 @@ func (a *App) Run() error {
 		cfg.Timeout = *a.flags.Timeout
 	}
 	
+	// Warn about (or extend) timeouts too short for the scenario durations
+	for _, warning := range cfg.CheckTimeouts(*a.flags.AutoTimeout) {
+		a.logger.Printf("Warning: %s", warning)
+	}
+	
+	for _, warning := range config.NewValidator().Warnings(cfg) {
+		a.logger.Printf("Warning: %s", warning)
+	}
+	
+	// Environment modules read the configured sysctl keys
+	envinfo.RegisterSysctlModule(cfg.SysctlKeys)
+	
+	// Read the baseline before running, so a bad path fails fast
+	var baseline output.Baseline
+	if path := *a.flags.Baseline; path != "" {
+		if baseline, err = output.LoadBaseline(path); err != nil {
+			return err
+		}
+		a.logger.Printf("Comparing against baseline %s (%d scenarios)", path, len(baseline))
+	} else if len(cfg.Thresholds) > 0 {
+		a.logger.Printf("Warning: thresholds are set but no -baseline was given; they are ignored")
+	}
+	
 	a.logger.Printf("Loaded configuration: %s", cfg.Name)
 	if cfg.Description != "" {
 		a.logger.Printf("Description: %s", cfg.Description)
@@ -74,19 +134,58 @@ func (a *App) Run() error {
 	coord := coordinator.NewCoordinator(cfg, a.logger)
 	defer coord.Cleanup()
 	
+	// Run only the scenarios selected by -filter and -tag
+	filter := coordinator.ScenarioFilter{Names: *a.flags.Filters, Tags: *a.flags.Tags}
+	if err := coord.SelectScenarios(filter); err != nil {
+		return err
+	}
+	
+	// Record exactly what will run, after merging, defaults, and selection
+	if path := *a.flags.WriteEffectiveConfig; path != "" {
+		if err := cfg.SaveEffectiveConfig(path); err != nil {
+			return fmt.Errorf("failed to write effective config: %w", err)
+		}
+		a.logger.Printf("Wrote effective configuration to %s", path)
+	}
+	
 	// Set environment collection if enabled in config
 	if cfg.CollectEnv {
 		coord.SetEnvironmentCollection(true)
 		a.logger.Printf("Environment information collection enabled")
 	}
 	
+	if *a.flags.NoCache {
+		coord.SetCaching(false)
+	}
+	
+	coord.SetSkipUnreachable(*a.flags.SkipUnreachable)
+	
+	// Show role output as it arrives, so a hung test is visible while it runs
+	if *a.flags.Verbose {
+		coord.SetOutputStreaming(true)
+	}
+	
 	// Register runners
 	if err := a.registerRunners(coord, cfg); err != nil {
 		return fmt.Errorf("failed to register runners: %w", err)
 	}
 	
-	// Connect to hosts
-	a.logger.Printf("Connecting to %d hosts...", len(cfg.Hosts))
+	// -validate checks that the scenarios could start, without running them
+	if *a.flags.Validate {
+		return a.validateSetup(ctx, coord)
+	}
+	
+	// Start the live dashboard before connecting so it shows the whole run
+	if *a.flags.Serve != "" {
+		stopDashboard, err := a.startDashboard(*a.flags.Serve, coord)
+		if err != nil {
+			return err
+		}
+		defer stopDashboard()
+	}
+	
+	// Connect to the hosts the selected scenarios use
+	a.logger.Printf("Connecting to hosts...")
 	if err := coord.ConnectHosts(ctx); err != nil {
 		return fmt.Errorf("failed to connect to hosts: %w", err)
 	}
@@ -103,10 +202,57 @@ func (a *App) Run() error {
 	duration := time.Since(startTime)
 	a.logger.Printf("Test execution completed in %v", duration)
 	
+	if unreachable := coord.UnreachableHosts(); len(unreachable) > 0 {
+		a.logger.Printf("Unreachable hosts: %s", strings.Join(unreachable, ", "))
+	}
+	if skipped := skippedScenarios(results); len(skipped) > 0 {
+		a.logger.Printf("Skipped %d scenarios on unreachable hosts: %s", len(skipped), strings.Join(skipped, ", "))
+	}
+	
+	var comparison *output.BaselineComparison
+	if baseline != nil {
+		comparison = output.CompareBaseline(baseline, results, cfg.Thresholds)
+		comparison.Path = *a.flags.Baseline
+	}
+	
 	// Output results
-	formatter := output.NewFormatter(*a.flags.JSONOutput)
-	if err := formatter.OutputResults(results, duration); err != nil {
-		return fmt.Errorf("failed to output results: %w", err)
+	// The connection report goes into JSON, and into text only when verbose
+	var connections []coordinator.HostConnection
+	if format == output.FormatJSON || *a.flags.Verbose {
+		connections = coord.ConnectionReport()
+	}
+	if err := a.writeResults(cfg, results, connections, comparison, duration, useColor); err != nil {
+		return err
+	}
+	
+	if *a.flags.IntervalCSV != "" {
+		if err := a.writeIntervalCSV(*a.flags.IntervalCSV, results); err != nil {
+			return err
+		}
+	}
+	
+	if *a.flags.EnvFlat != "" {
+		if err := a.writeEnvFlat(*a.flags.EnvFlat, results); err != nil {
+			return err
+		}
+	}
+	
+	if *a.flags.Markdown != "" {
+		if err := a.writeMarkdown(*a.flags.Markdown, cfg, results, duration); err != nil {
+			return err
+		}
+	}
+	
+	if *a.flags.MetricsFile != "" {
+		if err := a.writeMetricsFile(*a.flags.MetricsFile, results); err != nil {
+			return err
+		}
+	}
+	
+	if *a.flags.OpenSearchURL != "" {
+		if err := a.exportOpenSearch(ctx, cfg, results); err != nil {
+			return err
+		}
 	}
 	
 	// Exit with appropriate code
@@ -115,10 +261,192 @@ func (a *App) Run() error {
 		a.logger.Printf("Some tests failed, exiting with code %d", exitCode)
 		os.Exit(exitCode)
 	}
+	if regressions := comparison.Regressions(); regressions > 0 {
+		a.logger.Printf("%d metrics regressed beyond their thresholds against %s, exiting with code 1", regressions, *a.flags.Baseline)
+		os.Exit(1)
+	}
 	
 	return nil
 }
 
+// writeResults formats results to stdout, or to the file selected by -out/-output-dir
+func (a *App) writeResults(cfg *config.TestConfig, results []*coordinator.TestResult, connections []coordinator.HostConnection, comparison *output.BaselineComparison, duration time.Duration, useColor bool) error {
+	format := *a.flags.OutputFormat
+	formatter := output.NewFormatter(format == output.FormatJSON)
+	formatter.SetFormat(format)
+	formatter.SetTitle(cfg.Name)
+	formatter.SetColor(useColor)
+	formatter.SetComparison(*a.flags.Compare)
+	formatter.SetSizeTables(*a.flags.SizeTable)
+	formatter.SetFailuresOnly(*a.flags.FailuresOnly)
+	formatter.SetBandwidthUnit(*a.flags.BwUnit)
+	formatter.SetConnections(connections)
+	formatter.SetBaseline(comparison)
+	
+	outputPath := resolveOutputPath(*a.flags.Out, *a.flags.OutputDir, format, cfg, time.Now())
+	if outputPath == "" {
+		if err := formatter.OutputResults(results, duration); err != nil {
+			return fmt.Errorf("failed to output results: %w", err)
+		}
+		return nil
+	}
+	
+	file, err := createOutputFile(outputPath)
+	if err != nil {
+		return err
+	}
+	
+	// Terminal colors make no sense in a file
+	formatter.SetColor(false)
+	formatter.SetOutput(file)
+	
+	if err := formatter.OutputResults(results, duration); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to output results: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to close output file %s: %w", outputPath, err)
+	}
+	
+	a.logger.Printf("Results written to %s", outputPath)
+	return nil
+}
+
+// writeIntervalCSV writes per-interval samples from all results to path
+func (a *App) writeIntervalCSV(path string, results []*coordinator.TestResult) error {
+	file, err := createOutputFile(path)
+	if err != nil {
+		return err
+	}
+	
+	rows, err := output.WriteIntervalCSV(file, results, *a.flags.BwUnit)
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write interval CSV: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to close interval CSV %s: %w", path, err)
+	}
+	
+	if rows == 0 {
+		a.logger.Printf("No per-interval samples captured; %s contains only the header", path)
+	} else {
+		a.logger.Printf("Wrote %d interval samples to %s", rows, path)
+	}
+	return nil
+}
+
+// writeEnvFlat writes every host's collected environment as flat key/value lines to path
+func (a *App) writeEnvFlat(path string, results []*coordinator.TestResult) error {
+	file, err := createOutputFile(path)
+	if err != nil {
+		return err
+	}
+	
+	hosts, err := output.WriteEnvFlat(file, results)
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write flat environment: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to close flat environment file %s: %w", path, err)
+	}
+	
+	if hosts == 0 {
+		a.logger.Printf("No environment data collected (set collect_env: true); %s is empty", path)
+	} else {
+		a.logger.Printf("Wrote the environment of %d hosts to %s", hosts, path)
+	}
+	return nil
+}
+
+// writeMarkdown writes a Markdown report of the results to path
+func (a *App) writeMarkdown(path string, cfg *config.TestConfig, results []*coordinator.TestResult, duration time.Duration) error {
+	file, err := createOutputFile(path)
+	if err != nil {
+		return err
+	}
+	
+	formatter := output.NewFormatter(false)
+	formatter.SetBandwidthUnit(*a.flags.BwUnit)
+	if err := formatter.WriteMarkdown(file, cfg.Name, results, duration); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write Markdown report: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to close Markdown report %s: %w", path, err)
+	}
+	
+	a.logger.Printf("Wrote a Markdown report of %d scenarios to %s", len(results), path)
+	return nil
+}
+
+// writeMetricsFile writes the results in Prometheus text format to path.
+// The file is replaced in one rename, so a collector scraping it never reads
+// a partial file.
+func (a *App) writeMetricsFile(path string, results []*coordinator.TestResult) error {
+	tmpPath := path + ".tmp"
+	file, err := createOutputFile(tmpPath)
+	if err != nil {
+		return err
+	}
+	
+	if err := output.WritePrometheus(file, results); err != nil {
+		file.Close()
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to write Prometheus metrics: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to close Prometheus metrics file %s: %w", tmpPath, err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to replace Prometheus metrics file %s: %w", path, err)
+	}
+	
+	a.logger.Printf("Wrote Prometheus metrics of %d scenarios to %s", len(results), path)
+	return nil
+}
+
+// exportOpenSearch indexes results into the OpenSearch cluster given by -opensearch-url
+func (a *App) exportOpenSearch(ctx context.Context, cfg *config.TestConfig, results []*coordinator.TestResult) error {
+	exporter := &output.OpenSearchExporter{
+		URL:    *a.flags.OpenSearchURL,
+		Index:  *a.flags.OpenSearchIndex,
+		Client: httpClient,
+		Labels: map[string]string{"config": cfg.Name},
+	}
+	if err := exporter.Export(ctx, results); err != nil {
+		return fmt.Errorf("failed to export results: %w", err)
+	}
+	
+	a.logger.Printf("Indexed %d results into %s", len(results), exporter.Index)
+	return nil
+}
+
+// startDashboard serves the live dashboard on addr and returns a function that stops it
+func (a *App) startDashboard(addr string, coord *coordinator.Coordinator) (func(), error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start dashboard on %s: %w", addr, err)
+	}
+	
+	server := &http.Server{Handler: output.NewDashboardHandler(coord.Status)}
+	go func() {
+		if err := server.Serve(listener); err != nil && err != http.ErrServerClosed {
+			a.logger.Printf("Dashboard stopped: %v", err)
+		}
+	}()
+	a.logger.Printf("Serving live dashboard on http://%s/", listener.Addr())
+	
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Shutdown(ctx)
+	}, nil
+}
+
 // setupSignalHandling configures graceful shutdown
 func (a *App) setupSignalHandling(cancel context.CancelFunc) {
 	sigCh := make(chan os.Signal, 1)
@@ -132,26 +460,50 @@ func (a *App) setupSignalHandling(cancel context.CancelFunc) {
 
 // registerRunners registers available runner implementations using auto-discovery
 func (a *App) registerRunners(coord *coordinator.Coordinator, cfg *config.TestConfig) error {
-	// Get custom binary path if configured
-	binaryPath := cfg.GetBinaryPath(cfg.Runner)
-	
-	// Create runner instance from registry with custom path
-	runnerInstance, err := runner.CreateWithPath(cfg.Runner, binaryPath)
-	if err != nil {
-		availableRunners := runner.GetRegistered()
-		return fmt.Errorf("unsupported runner '%s'. Available runners: %v", cfg.Runner, availableRunners)
+	// Scenarios may override the top-level runner, so register every one in use
+	for _, name := range cfg.RunnerNames() {
+		// Get custom binary path if configured
+		binaryPath := cfg.GetBinaryPath(name)
+		
+		// Create runner instance from registry with custom path
+		runnerInstance, err := runner.CreateWithPath(name, binaryPath)
+		if err != nil {
+			availableRunners := runner.GetRegistered()
+			return fmt.Errorf("unsupported runner '%s'. Available runners: %v", name, availableRunners)
+		}
+		
+		if binaryPath != "" {
+			a.logger.Printf("Using custom binary path for %s: %s", name, binaryPath)
+		}
+		
+		// Register with coordinator
+		coord.RegisterRunner(name, runnerInstance)
 	}
 	
-	if binaryPath != "" {
-		a.logger.Printf("Using custom binary path for %s: %s", cfg.Runner, binaryPath)
+	// Fail before connecting if a selected parser was never registered
+	for _, name := range cfg.ParserNames() {
+		if _, err := runner.GetParser(name); err != nil {
+			return fmt.Errorf("unknown parser '%s'. Available parsers: %v", name, runner.GetRegisteredParsers())
+		}
+		a.logger.Printf("Using parser %s", name)
 	}
 	
-	// Register with coordinator
-	coord.RegisterRunner(cfg.Runner, runnerInstance)
-	
 	return nil
 }
 
+// skippedScenarios returns the names of skipped scenarios, once each
+func skippedScenarios(results []*coordinator.TestResult) []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, result := range results {
+		if result.Skipped && !seen[result.ScenarioName] {
+			seen[result.ScenarioName] = true
+			names = append(names, result.ScenarioName)
+		}
+	}
+	return names
+}
+
 // calculateExitCode determines the appropriate exit code
 func (a *App) calculateExitCode(results []*coordinator.TestResult) int {
 	for _, result := range results {
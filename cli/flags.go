@@ -2,33 +2,106 @@ package cli
 
 import (
 	"flag"
+	"strings"
 	"time"
 )
 
 const (
-	defaultConfigFile = "config.yaml"
-	defaultTimeout    = 10 * time.Minute
+	defaultConfigFile      = "config.yaml"
+	defaultTimeout         = 10 * time.Minute
+	defaultOpenSearchIndex = "perf-runner"
 )
 
 // Flags represents command line flags
 type Flags struct {
-	ConfigFile  *string
-	Timeout     *time.Duration
-	Verbose     *bool
-	JSONOutput  *bool
-	Version     *bool
+	ConfigFiles          *stringList
+	Filters              *stringList
+	Tags                 *stringList
+	Timeout              *time.Duration
+	AutoTimeout          *bool
+	Verbose              *bool
+	JSONOutput           *bool
+	OutputFormat         *string
+	Version              *bool
+	ListRunners          *bool
+	Color                *string
+	NoColor              *bool
+	Out                  *string
+	OutputDir            *string
+	Serve                *string
+	NoCache              *bool
+	IntervalCSV          *string
+	Compare              *bool
+	SizeTable            *bool
+	FailuresOnly         *bool
+	BwUnit               *string
+	EnvFlat              *string
+	Markdown             *string
+	MetricsFile          *string
+	Baseline             *string
+	SkipUnreachable      *bool
+	Validate             *bool
+	OpenSearchURL        *string
+	OpenSearchIndex      *string
+	WriteEffectiveConfig *string
 }
 
 // NewFlags creates and parses command line flags
 func NewFlags() *Flags {
 	flags := &Flags{
-		ConfigFile: flag.String("config", defaultConfigFile, "Path to configuration file"),
-		Timeout:    flag.Duration("timeout", defaultTimeout, "Global timeout for all tests"),
-		Verbose:    flag.Bool("verbose", false, "Enable verbose logging"),
-		JSONOutput: flag.Bool("json", false, "Output results in JSON format"),
-		Version:    flag.Bool("version", false, "Show version information"),
+		ConfigFiles:          &stringList{},
+		Filters:              &stringList{},
+		Tags:                 &stringList{},
+		Timeout:              flag.Duration("timeout", defaultTimeout, "Global timeout for all tests"),
+		AutoTimeout:          flag.Bool("auto-timeout", false, "Extend the timeout of scenarios whose duration would not fit in it"),
+		Verbose:              flag.Bool("verbose", false, "Enable verbose logging"),
+		JSONOutput:           flag.Bool("json", false, "Output results in JSON format"),
+		OutputFormat:         flag.String("output-format", "", "Result format: text, json, html (a self-contained report), or prometheus; -json is short for json"),
+		Version:              flag.Bool("version", false, "Show version information"),
+		ListRunners:          flag.Bool("list-runners", false, "List the available runners and the roles they support, then exit"),
+		Color:                flag.String("color", "auto", "Colorize text output: auto, always, or never"),
+		NoColor:              flag.Bool("no-color", false, "Disable colored text output (same as -color=never)"),
+		Out:                  flag.String("out", "", "Write results to this file; supports {date}, {time}, {config_name}, {runner}"),
+		OutputDir:            flag.String("output-dir", "", "Write results into this directory (file name from -out or a dated default)"),
+		IntervalCSV:          flag.String("interval-csv", "", "Write per-interval throughput samples (iperf3) to this CSV file"),
+		EnvFlat:              flag.String("env-flat", "", "Write each host's collected environment as host.module.key=value lines to this file"),
+		Markdown:             flag.String("markdown", "", "Write a Markdown summary and per-scenario metrics tables to this file"),
+		MetricsFile:          flag.String("metrics-file", "", "Write results in Prometheus text format to this file, e.g. for node_exporter's textfile collector"),
+		Baseline:             flag.String("baseline", "", "Compare client metrics to this JSON results file; exceeding the config's thresholds fails the run"),
+		OpenSearchURL:        flag.String("opensearch-url", "", "Index results into OpenSearch/Elasticsearch at this URL through the bulk API"),
+		OpenSearchIndex:      flag.String("opensearch-index", defaultOpenSearchIndex, "Index name for -opensearch-url"),
+		Compare:              flag.Bool("compare", false, "Show a matrix of each runner's primary metric per comparison_group"),
+		SizeTable:            flag.Bool("size-table", false, "Show each runner's primary metric by message size for comparison groups and size sweeps"),
+		FailuresOnly:         flag.Bool("failures-only", false, "In text output, show details only for failed scenarios (the summary still counts all)"),
+		BwUnit:               flag.String("bw-unit", "", "Show bandwidth in text, Markdown, and interval CSV output as mbps, gbps, MBps, or GBps"),
+		SkipUnreachable:      flag.Bool("skip-unreachable", false, "Skip, and report as skipped, the scenarios whose hosts cannot be connected instead of aborting"),
+		Validate:             flag.Bool("validate", false, "Check the configuration, host connections, and runner binaries, report each check, then exit without running scenarios"),
+		NoCache:              flag.Bool("no-cache", false, "Re-validate binaries and re-collect environment info for every scenario"),
+		Serve:                flag.String("serve", "", "Serve a live dashboard on this address during the run (e.g. :8080)"),
+		WriteEffectiveConfig: flag.String("write-effective-config", "", "Write the merged configuration that will run to this YAML file"),
 	}
+	flag.StringVar(flags.Out, "output-file", "", "Same as -out")
+	flag.Var(flags.Filters, "filter", "Run only scenarios whose name matches this glob pattern; repeat to match any of several")
+	flag.Var(flags.Tags, "tag", "Run only scenarios with this tag; repeat to match any of several")
+	flag.Var(flags.ConfigFiles, "config", "Path to configuration file; repeat to deep-merge later files over earlier ones (default \""+defaultConfigFile+"\")")
 	
 	flag.Parse()
+	if len(*flags.ConfigFiles) == 0 {
+		*flags.ConfigFiles = stringList{defaultConfigFile}
+	}
 	return flags
+}
+
+// stringList is a flag that collects every occurrence of a repeated option
+type stringList []string
+
+// String returns the collected values, comma-separated
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+// Set appends one occurrence of the flag
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
 }
\ No newline at end of file
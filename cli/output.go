@@ -0,0 +1,78 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"perf-runner/config"
+	"perf-runner/output"
+)
+
+// defaultOutputTemplate names result files written to -output-dir when -out is not given
+const defaultOutputTemplate = "{date}_{config_name}_{runner}"
+
+// unsafeFilenameChars matches characters replaced when substituting names into paths
+var unsafeFilenameChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// expandOutputTemplate resolves {date}, {time}, {config_name}, and {runner}
+// tokens in an output path using the run's configuration
+func expandOutputTemplate(template string, cfg *config.TestConfig, now time.Time) string {
+	replacer := strings.NewReplacer(
+		"{date}", now.Format("2006-01-02"),
+		"{time}", now.Format("150405"),
+		"{config_name}", sanitizeFilename(cfg.Name),
+		"{runner}", sanitizeFilename(cfg.Runner),
+	)
+	return replacer.Replace(template)
+}
+
+// sanitizeFilename makes a value safe to embed in a file name
+func sanitizeFilename(value string) string {
+	return strings.Trim(unsafeFilenameChars.ReplaceAllString(value, "_"), "_")
+}
+
+// resolveOutputPath determines the results file from the -out and -output-dir
+// flags, returning an empty string when results should go to stdout
+func resolveOutputPath(outTemplate, outputDir, format string, cfg *config.TestConfig, now time.Time) string {
+	if outTemplate == "" && outputDir == "" {
+		return ""
+	}
+	
+	if outTemplate == "" {
+		extension := ".txt"
+		switch format {
+		case output.FormatJSON:
+			extension = ".json"
+		case output.FormatHTML:
+			extension = ".html"
+		case output.FormatPrometheus:
+			extension = ".prom"
+		}
+		outTemplate = defaultOutputTemplate + extension
+	}
+	
+	path := expandOutputTemplate(outTemplate, cfg, now)
+	if outputDir != "" && !filepath.IsAbs(path) {
+		path = filepath.Join(expandOutputTemplate(outputDir, cfg, now), path)
+	}
+	return path
+}
+
+// createOutputFile creates (or truncates) the results file, including parent directories
+func createOutputFile(path string) (*os.File, error) {
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, fmt.Errorf("failed to create output directory %s: %w", dir, err)
+		}
+	}
+	
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create output file %s: %w", path, err)
+	}
+	return file, nil
+}
@@ -0,0 +1,137 @@
+package cli
+
+import (
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"perf-runner/config"
+	"perf-runner/coordinator"
+)
+
+func TestExpandOutputTemplate(t *testing.T) {
+	cfg := &config.TestConfig{Name: "100G", Runner: "iperf3"}
+	now := time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		template string
+		expected string
+	}{
+		{"{date}_{runner}_{config_name}.json", "2024-01-02_iperf3_100G.json"},
+		{"results/{config_name}-{time}.txt", "results/100G-150405.txt"},
+		{"plain.json", "plain.json"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.template, func(t *testing.T) {
+			if got := expandOutputTemplate(tt.template, cfg, now); got != tt.expected {
+				t.Errorf("expandOutputTemplate(%q) = %q, expected %q", tt.template, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestExpandOutputTemplate_SanitizesNames(t *testing.T) {
+	cfg := &config.TestConfig{Name: "Lab A / 100G test", Runner: "ib_send_bw"}
+	now := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+
+	got := expandOutputTemplate("{config_name}.json", cfg, now)
+	if got != "Lab_A_100G_test.json" {
+		t.Errorf("Expected sanitized name 'Lab_A_100G_test.json', got %q", got)
+	}
+}
+
+func TestResolveOutputPath(t *testing.T) {
+	cfg := &config.TestConfig{Name: "100G", Runner: "iperf3"}
+	now := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		out       string
+		outputDir string
+		format    string
+		expected  string
+	}{
+		{"stdout", "", "", "text", ""},
+		{"out only", "{date}.json", "", "json", "2024-01-02.json"},
+		{"dir with default name", "", "archive", "json", filepath.Join("archive", "2024-01-02_100G_iperf3.json")},
+		{"dir with default text name", "", "archive", "text", filepath.Join("archive", "2024-01-02_100G_iperf3.txt")},
+		{"dir with default html name", "", "archive", "html", filepath.Join("archive", "2024-01-02_100G_iperf3.html")},
+		{"dir with default prometheus name", "", "archive", "prometheus", filepath.Join("archive", "2024-01-02_100G_iperf3.prom")},
+		{"dir and out", "{runner}.json", "archive/{date}", "json", filepath.Join("archive", "2024-01-02", "iperf3.json")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := resolveOutputPath(tt.out, tt.outputDir, tt.format, cfg, now)
+			if got != tt.expected {
+				t.Errorf("resolveOutputPath() = %q, expected %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestWriteResults_ToFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "results.json")
+	if err := os.WriteFile(path, []byte(strings.Repeat("stale ", 1000)), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	jsonOutput, format, disabled, empty := true, "json", false, ""
+	app := &App{
+		flags: &Flags{
+			JSONOutput:   &jsonOutput,
+			OutputFormat: &format,
+			Compare:      &disabled,
+			SizeTable:    &disabled,
+			FailuresOnly: &disabled,
+			BwUnit:       &empty,
+			Out:          &path,
+			OutputDir:    &empty,
+		},
+		logger: log.New(io.Discard, "", 0),
+	}
+	results := []*coordinator.TestResult{{ScenarioName: "tcp", Success: true}}
+	if err := app.writeResults(&config.TestConfig{Name: "file"}, results, nil, nil, time.Second, true); err != nil {
+		t.Fatalf("writeResults returned error: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := string(data)
+	if strings.Contains(out, "stale") {
+		t.Error("Expected the existing file to be truncated")
+	}
+	if !strings.HasPrefix(out, "{\n  ") {
+		t.Errorf("Expected indented JSON, got:\n%s", out)
+	}
+	if strings.Contains(out, "\x1b[") {
+		t.Error("Expected no color codes in a results file")
+	}
+}
+
+func TestWriteMetricsFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "textfile", "perf.prom")
+	app := &App{logger: log.New(io.Discard, "", 0)}
+	results := []*coordinator.TestResult{{ScenarioName: "tcp", Success: true}}
+	if err := app.writeMetricsFile(path, results); err != nil {
+		t.Fatalf("writeMetricsFile returned error: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `perf_runner_test_success{scenario="tcp"} 1`) {
+		t.Errorf("Expected the success gauge, got:\n%s", data)
+	}
+	if _, err := os.Stat(path + ".tmp"); !os.IsNotExist(err) {
+		t.Errorf("Expected the temporary file to be renamed away, got %v", err)
+	}
+}
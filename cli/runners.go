@@ -0,0 +1,43 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"perf-runner/runner"
+)
+
+// runnerRoles are the roles a runner can support, in listing order
+var runnerRoles = []string{"client", "server", "intermediate"}
+
+// writeRunnerList writes every registered runner with the roles it
+// supports and its description, sorted by name
+func writeRunnerList(w io.Writer) error {
+	names := runner.GetRegistered()
+	sort.Strings(names)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "RUNNER\tROLES\tDESCRIPTION")
+	for _, name := range names {
+		r, err := runner.Create(name)
+		if err != nil {
+			return fmt.Errorf("failed to create runner %s: %w", name, err)
+		}
+
+		var roles []string
+		for _, role := range runnerRoles {
+			if r.SupportsRole(role) {
+				roles = append(roles, role)
+			}
+		}
+		var description string
+		if described, ok := r.(runner.Described); ok {
+			description = described.Description()
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", name, strings.Join(roles, ", "), description)
+	}
+	return tw.Flush()
+}
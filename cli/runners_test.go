@@ -0,0 +1,28 @@
+package cli
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestWriteRunnerList(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeRunnerList(&buf); err != nil {
+		t.Fatalf("writeRunnerList: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if !strings.HasPrefix(lines[0], "RUNNER") {
+		t.Errorf("Expected a header line, got %q", lines[0])
+	}
+	var iperf3 string
+	for _, line := range lines[1:] {
+		if strings.HasPrefix(line, "iperf3 ") {
+			iperf3 = line
+		}
+	}
+	if !strings.Contains(iperf3, "client, server, intermediate") || !strings.Contains(iperf3, "iperf3") {
+		t.Errorf("Expected iperf3 with all three roles, got %q", iperf3)
+	}
+}
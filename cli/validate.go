@@ -0,0 +1,59 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"perf-runner/coordinator"
+)
+
+// validateSetup connects to the hosts of the selected scenarios and checks
+// their runner binaries, prints each check, and fails if any check failed.
+// No scenario is run.
+func (a *App) validateSetup(ctx context.Context, coord *coordinator.Coordinator) error {
+	// Connect to every host, so all unreachable ones are reported at once
+	coord.SetSkipUnreachable(true)
+	a.logger.Printf("Connecting to hosts...")
+	if err := coord.ConnectHosts(ctx); err != nil {
+		return fmt.Errorf("failed to connect to hosts: %w", err)
+	}
+
+	checks := coord.ValidateSetup(ctx)
+	failed, err := writeSetupChecks(os.Stdout, checks)
+	if err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("validation failed: %d of %d checks failed", failed, len(checks))
+	}
+	return nil
+}
+
+// writeSetupChecks writes one line per check and a pass/fail summary, and
+// returns the number of failed checks
+func writeSetupChecks(w io.Writer, checks []coordinator.SetupCheck) (int, error) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "STATUS\tHOST\tCHECK\tERROR")
+	failed := 0
+	for _, check := range checks {
+		status, message := "PASS", ""
+		if check.Err != nil {
+			status, message = "FAIL", check.Err.Error()
+			failed++
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", status, check.Host, check.Check, message)
+	}
+	if err := tw.Flush(); err != nil {
+		return failed, err
+	}
+
+	if failed > 0 {
+		_, err := fmt.Fprintf(w, "\nValidation FAILED: %d of %d checks failed\n", failed, len(checks))
+		return failed, err
+	}
+	_, err := fmt.Fprintf(w, "\nValidation passed: %d checks\n", len(checks))
+	return failed, err
+}
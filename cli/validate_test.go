@@ -0,0 +1,34 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"perf-runner/coordinator"
+)
+
+func TestWriteSetupChecks(t *testing.T) {
+	checks := []coordinator.SetupCheck{
+		{Host: "client", Check: "connect"},
+		{Host: "server", Check: "connect", Err: fmt.Errorf("connection refused")},
+	}
+
+	var buf bytes.Buffer
+	failed, err := writeSetupChecks(&buf, checks)
+	if err != nil {
+		t.Fatalf("writeSetupChecks: %v", err)
+	}
+	if failed != 1 {
+		t.Errorf("Expected 1 failed check, got %d", failed)
+	}
+
+	lines := strings.Split(buf.String(), "\n")
+	if got := strings.Join(strings.Fields(lines[2]), " "); got != "FAIL server connect connection refused" {
+		t.Errorf("Expected the failed check with its error, got %q", lines[2])
+	}
+	if !strings.Contains(buf.String(), "Validation FAILED: 1 of 2 checks failed") {
+		t.Errorf("Expected a failure summary, got:\n%s", buf.String())
+	}
+}
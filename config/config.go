@@ -3,6 +3,7 @@ package config
 import (
 	"fmt"
 	"os"
+	"sort"
 	"time"
 
 	"perf-runner/runner"
@@ -16,26 +17,87 @@ type TestConfig struct {
 	Name        string              `yaml:"name"`
 	Description string              `yaml:"description,omitempty"`
 	Runner      string              `yaml:"runner"`
+	Parser      string              `yaml:"parser,omitempty"` // Registered parser replacing the runner's built-in metric parsing
 	Timeout     time.Duration       `yaml:"timeout"`
 	
 	// Environment information collection
 	CollectEnv  bool                `yaml:"collect_env,omitempty"`
+	// EnvRetries is how many more times a failed environment collection is
+	// tried on a host, without re-running the test (unset means the
+	// default, 2; 0 disables retries)
+	EnvRetries  *int                `yaml:"env_retries,omitempty"`
+	// SysctlKeys replaces the kernel parameters the sysctl environment
+	// module reads (empty means envinfo.DefaultSysctlKeys)
+	SysctlKeys  []string            `yaml:"sysctl_keys,omitempty"`
 	
 	// Binary path configurations
 	BinaryPaths map[string]string   `yaml:"binary_paths,omitempty"`
 	
+	// ResolveHosts pins target host names to IPs once per run: "controller"
+	// resolves on this machine, "host" resolves on the connecting host
+	ResolveHosts string             `yaml:"resolve_hosts,omitempty"`
+	
+	// MaxConcurrentCommands bounds the SSH commands in flight across all
+	// hosts at once (0 means unlimited), e.g. to protect a shared bastion
+	MaxConcurrentCommands int        `yaml:"max_concurrent_commands,omitempty"`
+	
+	// NodeStartupDelay is the fixed wait after starting a server or
+	// intermediate node before starting the next hop, for roles without a
+	// readiness check (unset means the default, 2s; 0 disables the wait)
+	NodeStartupDelay *time.Duration  `yaml:"node_startup_delay,omitempty"`
+	// IntermediateCleanupTimeout is how long intermediate nodes and
+	// persistent servers may keep running after the client finishes before
+	// they are stopped (0 means the default, 5s)
+	IntermediateCleanupTimeout time.Duration `yaml:"intermediate_cleanup_timeout,omitempty"`
+	
 	// Host configurations
 	Hosts       map[string]*HostConfig `yaml:"hosts"`
 	
+	// HostGroups name ordered lists of host keys, so scenarios can refer to
+	// a host as "group[i]"
+	HostGroups  map[string][]string    `yaml:"host_groups,omitempty"`
+	
 	// Test scenarios
 	Tests       []TestScenario         `yaml:"tests"`
+	
+	// Groups share host preparation between the scenarios that name them
+	Groups      []ScenarioGroup        `yaml:"groups,omitempty"`
+	
+	// Thresholds map a metric to the regression, in percent, allowed against
+	// the -baseline results before the run fails
+	Thresholds  map[string]float64     `yaml:"thresholds,omitempty"`
+}
+
+// ScenarioGroup is host preparation shared by several scenarios. Setup runs
+// once before the first scenario naming the group and teardown once after
+// the last one, instead of around every scenario.
+type ScenarioGroup struct {
+	Name     string              `yaml:"name"`
+	Setup    map[string][]string `yaml:"setup,omitempty"`    // Host name -> commands run in order
+	Teardown map[string][]string `yaml:"teardown,omitempty"` // Host name -> commands run in order
 }
 
+// FileTransfer copies one file between this machine and a host. In
+// post_files, Local may contain {scenario} and {iteration}, so repeated runs
+// do not overwrite each other's files.
+type FileTransfer struct {
+	Host   string `yaml:"host"`   // Host name
+	Local  string `yaml:"local"`  // Path on this machine
+	Remote string `yaml:"remote"` // Path on the host
+}
+
+// Host name resolution modes for ResolveHosts
+const (
+	ResolveOnController = "controller"
+	ResolveOnHost       = "host"
+)
+
 // HostConfig represents configuration for a single host
 type HostConfig struct {
 	SSH      *ssh.Config       `yaml:"ssh"`
 	Role     string            `yaml:"role"` // "client" or "server"
 	Runner   *runner.Config    `yaml:"runner"`
+	Labels   []string          `yaml:"labels,omitempty"` // Scenarios can refer to the host as "@label"
 }
 
 // TestScenario represents a single test scenario
@@ -45,14 +107,101 @@ type TestScenario struct {
 	Client      string            `yaml:"client"` // Host name for client
 	Server      string            `yaml:"server"` // Host name for server
 	Intermediate string           `yaml:"intermediate,omitempty"` // Host name for intermediate node (optional)
+	Chain       []string          `yaml:"chain,omitempty"` // Hosts in traffic order, client first and server last; hosts between relay
 	Config      *runner.Config    `yaml:"config"`
+	Runner      string            `yaml:"runner,omitempty"` // Overrides the top-level runner for this scenario
+	Parser      string            `yaml:"parser,omitempty"` // Overrides the top-level parser for this scenario
 	
 	// Test-specific settings
 	Repeat      int               `yaml:"repeat,omitempty"`
 	Delay       time.Duration     `yaml:"delay,omitempty"`
+	Timeout     time.Duration     `yaml:"timeout,omitempty"` // Overrides the global timeout for this scenario
+	Retries     int               `yaml:"retries,omitempty"` // Re-runs after transient (connection) failures
+	Prewarm     bool              `yaml:"prewarm,omitempty"` // Run a brief throwaway test before the measured one
+	Autotune    *AutotuneConfig   `yaml:"autotune,omitempty"` // Sweep an arg to find where throughput plateaus
+	Matrix      Matrix            `yaml:"matrix,omitempty"` // Expanded at load into one scenario per combination of arg values
+	Group       string            `yaml:"group,omitempty"` // Shares the named group's setup/teardown
+	Tags        []string          `yaml:"tags,omitempty"` // Labels for selecting scenarios with -tag
+	StrictVersions bool           `yaml:"strict_versions,omitempty"` // Fail instead of warn when hosts run different tool versions
+	ThermalCheck   bool           `yaml:"thermal_check,omitempty"` // Sample CPU temperature and throttling during the run
+	StrictThermal  bool           `yaml:"strict_thermal,omitempty"` // Fail instead of warn when a host throttles (implies thermal_check)
+	
+	// FallbackHosts maps a role (client, server, intermediate) to an alternate
+	// host used when the scenario fails on its primary hosts
+	FallbackHosts map[string]string `yaml:"fallback_hosts,omitempty"`
+	
+	// Output matching for tools whose exit code is unreliable. When set,
+	// success_pattern must match the client output and failure_pattern must
+	// not match any role's output, regardless of exit codes.
+	SuccessPattern string `yaml:"success_pattern,omitempty"`
+	FailurePattern string `yaml:"failure_pattern,omitempty"`
+	
+	// DataPlaneSubnet (CIDR) selects which of a host's addresses the client
+	// targets, instead of the SSH management address
+	DataPlaneSubnet string `yaml:"data_plane_subnet,omitempty"`
+	
+	// EnvRoles limits environment collection to the listed roles (client,
+	// server, intermediate); when empty every role is collected
+	EnvRoles []string `yaml:"env_roles,omitempty"`
+	
+	// Clients lists additional client hosts that run against the same server
+	// at the same time as Client (fan-out/incast). ClientStagger spaces out
+	// successive client launches to avoid a burst of simultaneous connects.
+	Clients       []string      `yaml:"clients,omitempty"`
+	ClientStagger time.Duration `yaml:"client_stagger,omitempty"`
+	
+	// ServerReadyTimeout, when set, replaces the fixed wait after starting the
+	// server: the server host is polled every ServerReadyInterval until the
+	// server's port is listening, failing the test if it is not within the
+	// timeout
+	ServerReadyTimeout  time.Duration `yaml:"server_ready_timeout,omitempty"`
+	ServerReadyInterval time.Duration `yaml:"server_ready_interval,omitempty"`
+	
+	// PreFiles are uploaded to their hosts before each run and PostFiles
+	// downloaded from them after it, e.g. tuning scripts and packet captures
+	PreFiles  []FileTransfer `yaml:"pre_files,omitempty"`
+	PostFiles []FileTransfer `yaml:"post_files,omitempty"`
+	
+	// QueueStats maps a host name to the NIC whose per-queue RX counters are
+	// read with ethtool around the test, to check how RSS spread the load
+	QueueStats map[string]string `yaml:"queue_stats,omitempty"`
+	
+	// Iteration is the repeat being run, starting at 1. It is set by the
+	// coordinator for each run and available to args as {{.Iteration}}.
+	Iteration int `yaml:"-"`
+	
+	// ComparisonGroup tags scenarios that measure the same link with
+	// different runners so their primary metrics can be compared side by side
+	ComparisonGroup string `yaml:"comparison_group,omitempty"`
+	
+	// ExpectFail marks a scenario that tests a failure condition, e.g. a
+	// blocked port: it passes when it fails and fails when it succeeds
+	ExpectFail bool `yaml:"expect_fail,omitempty"`
+}
+
+// AutotuneConfig sweeps an integer arg upward from Start, re-running the
+// scenario until the primary metric stops improving or Max is reached
+type AutotuneConfig struct {
+	Arg       string  `yaml:"arg"`
+	Start     int     `yaml:"start"`
+	Max       int     `yaml:"max"`
+	Step      int     `yaml:"step,omitempty"`      // Added each round; the value doubles when unset
+	Tolerance float64 `yaml:"tolerance,omitempty"` // Relative gain treated as a plateau (default 0.05)
 }
 
-// LoadConfig loads configuration from a YAML file
+// DefaultAutotuneTolerance is the relative gain below which autotune stops
+const DefaultAutotuneTolerance = 0.05
+
+// Next returns the autotune value that follows value
+func (a *AutotuneConfig) Next(value int) int {
+	if a.Step > 0 {
+		return value + a.Step
+	}
+	return value * 2
+}
+
+// LoadConfig loads configuration from a YAML file, expanding ${VAR} and
+// ${VAR:-default} references to environment variables in its values
 func LoadConfig(filename string) (*TestConfig, error) {
 	data, err := os.ReadFile(filename)
 	if err != nil {
@@ -60,22 +209,42 @@ func LoadConfig(filename string) (*TestConfig, error) {
 	}
 	
 	var config TestConfig
-	if err := yaml.Unmarshal(data, &config); err != nil {
+	if err := unmarshalWithEnv(data, &config); err != nil {
 		return nil, fmt.Errorf("failed to parse config file %s: %w", filename, err)
 	}
 	
+	return finishConfig(&config)
+}
+
+// finishConfig applies defaults to a parsed configuration and validates it
+func finishConfig(config *TestConfig) (*TestConfig, error) {
 	// Set defaults
 	if config.Timeout == 0 {
 		config.Timeout = 10 * time.Minute
 	}
 	
+	// Replace matrix scenarios with the concrete scenarios they stand for
+	if err := config.expandMatrices(); err != nil {
+		return nil, fmt.Errorf("config validation failed: %w", err)
+	}
+	
+	// Turn @label, group[i], and SSH address references into host keys
+	if err := config.ResolveHostRefs(); err != nil {
+		return nil, fmt.Errorf("config validation failed: %w", err)
+	}
+	
+	// A chain's ends and first relay are also its client, server, and intermediate
+	for i := range config.Tests {
+		config.Tests[i].expandChain()
+	}
+	
 	// Validate configuration
 	validator := NewValidator()
-	if err := validator.ValidateConfig(&config); err != nil {
+	if err := validator.ValidateConfig(config); err != nil {
 		return nil, fmt.Errorf("config validation failed: %w", err)
 	}
 	
-	return &config, nil
+	return config, nil
 }
 
 
@@ -97,9 +266,230 @@ func (c *TestConfig) GetIntermediateHost(test *TestScenario) *HostConfig {
 	return c.Hosts[test.Intermediate]
 }
 
+// GetRunner returns the runner name for a test, honoring a per-scenario override
+func (c *TestConfig) GetRunner(test *TestScenario) string {
+	if test.Runner != "" {
+		return test.Runner
+	}
+	return c.Runner
+}
+
+// GetParser returns the name of the registered parser for a test's metrics,
+// or "" for the runner's built-in parsing. The top-level parser applies only
+// to scenarios running the top-level runner.
+func (c *TestConfig) GetParser(test *TestScenario) string {
+	if test.Parser != "" {
+		return test.Parser
+	}
+	if c.GetRunner(test) == c.Runner {
+		return c.Parser
+	}
+	return ""
+}
+
+// ParserNames returns every registered parser the configuration selects
+func (c *TestConfig) ParserNames() []string {
+	var names []string
+	seen := make(map[string]bool)
+	for i := range c.Tests {
+		name := c.GetParser(&c.Tests[i])
+		if name != "" && !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// ConcurrentRoles returns how many role commands a scenario runs at once
+func (t *TestScenario) ConcurrentRoles() int {
+	roles := 1 + len(t.Clients) + len(t.Relays())
+	if t.Server != "" {
+		roles++
+	}
+	return roles
+}
+
+// Relays returns the hosts between the client and the server in traffic
+// order: the inner hosts of Chain, or the single Intermediate
+func (t *TestScenario) Relays() []string {
+	if len(t.Chain) > 2 {
+		return t.Chain[1 : len(t.Chain)-1]
+	}
+	if t.Intermediate != "" {
+		return []string{t.Intermediate}
+	}
+	return nil
+}
+
+// HostNames returns every host the scenario runs on or copies files to, in
+// the order they first appear. Fallback hosts are left out.
+func (t *TestScenario) HostNames() []string {
+	candidates := append([]string{t.Client, t.Server}, t.Relays()...)
+	candidates = append(candidates, t.Clients...)
+	for _, transfer := range append(append([]FileTransfer(nil), t.PreFiles...), t.PostFiles...) {
+		candidates = append(candidates, transfer.Host)
+	}
+	queueHosts := make([]string, 0, len(t.QueueStats))
+	for host := range t.QueueStats {
+		queueHosts = append(queueHosts, host)
+	}
+	sort.Strings(queueHosts)
+	candidates = append(candidates, queueHosts...)
+	
+	var names []string
+	seen := make(map[string]bool)
+	for _, name := range candidates {
+		if name != "" && !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// expandChain fills Client, Server, and Intermediate from Chain so code that
+// only knows those fields sees the ends of the chain and its first relay
+func (t *TestScenario) expandChain() {
+	if len(t.Chain) < 2 {
+		return
+	}
+	if t.Client == "" {
+		t.Client = t.Chain[0]
+	}
+	if t.Server == "" {
+		t.Server = t.Chain[len(t.Chain)-1]
+	}
+	if t.Intermediate == "" && len(t.Chain) > 2 {
+		t.Intermediate = t.Chain[1]
+	}
+}
+
+// CollectsEnv reports whether environment information is collected from role
+func (t *TestScenario) CollectsEnv(role string) bool {
+	if len(t.EnvRoles) == 0 {
+		return true
+	}
+	for _, envRole := range t.EnvRoles {
+		if envRole == role {
+			return true
+		}
+	}
+	return false
+}
+
+// RunnerNames returns every runner used by the configuration, top-level first
+func (c *TestConfig) RunnerNames() []string {
+	names := []string{c.Runner}
+	seen := map[string]bool{c.Runner: true}
+	for i := range c.Tests {
+		name := c.GetRunner(&c.Tests[i])
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// GetGroup returns the scenario group with the given name, or nil
+func (c *TestConfig) GetGroup(name string) *ScenarioGroup {
+	for i := range c.Groups {
+		if c.Groups[i].Name == name {
+			return &c.Groups[i]
+		}
+	}
+	return nil
+}
+
+// RunsServer reports whether the scenario's runner has a server role.
+// Single-node runners, such as fio, run on the client host alone.
+func (c *TestConfig) RunsServer(test *TestScenario) bool {
+	r, err := runner.Create(c.GetRunner(test))
+	return err != nil || r.SupportsRole("server")
+}
+
 // HasIntermediateNode returns true if the test scenario includes an intermediate node
 func (c *TestConfig) HasIntermediateNode(test *TestScenario) bool {
-	return test.Intermediate != ""
+	return len(test.Relays()) > 0
+}
+
+// PrewarmDuration is the duration of the throwaway run before a prewarmed scenario
+const PrewarmDuration = 2 * time.Second
+
+// PrewarmScenario returns a brief, single-attempt copy of the scenario whose
+// result is discarded. It warms up devices (e.g. RDMA page-table setup) so
+// first-run costs do not land in the measured result.
+func (t *TestScenario) PrewarmScenario() *TestScenario {
+	prewarm := *t
+	prewarm.Name = t.Name + " (prewarm)"
+	prewarm.Prewarm = false
+	prewarm.Repeat = 0
+	prewarm.Retries = 0
+	prewarm.FallbackHosts = nil
+	
+	var runnerConfig runner.Config
+	if t.Config != nil {
+		runnerConfig = *t.Config
+	}
+	runnerConfig.Duration = PrewarmDuration
+	prewarm.Config = &runnerConfig
+	
+	return &prewarm
+}
+
+// WithArg returns a copy of the scenario whose config sets arg to value,
+// leaving the original scenario's config untouched
+func (t *TestScenario) WithArg(arg string, value interface{}) *TestScenario {
+	scenario := *t
+	
+	var runnerConfig runner.Config
+	if t.Config != nil {
+		runnerConfig = *t.Config
+	}
+	runnerConfig.Args = make(map[string]interface{}, len(runnerConfig.Args)+1)
+	if t.Config != nil {
+		for k, v := range t.Config.Args {
+			runnerConfig.Args[k] = v
+		}
+	}
+	runnerConfig.Args[arg] = value
+	scenario.Config = &runnerConfig
+	
+	return &scenario
+}
+
+// WithFallbackHosts returns a copy of the scenario with each role in
+// FallbackHosts replaced by its alternate host. The copy has no fallbacks itself.
+func (t *TestScenario) WithFallbackHosts() *TestScenario {
+	fallback := *t
+	fallback.FallbackHosts = nil
+	
+	if len(t.Chain) > 0 {
+		fallback.Chain = append([]string(nil), t.Chain...)
+	}
+	
+	for role, host := range t.FallbackHosts {
+		switch role {
+		case "client":
+			fallback.Client = host
+			if len(fallback.Chain) > 0 {
+				fallback.Chain[0] = host
+			}
+		case "server":
+			fallback.Server = host
+			if len(fallback.Chain) > 0 {
+				fallback.Chain[len(fallback.Chain)-1] = host
+			}
+		case "intermediate":
+			fallback.Intermediate = host
+			if len(fallback.Chain) > 2 {
+				fallback.Chain[1] = host
+			}
+		}
+	}
+	
+	return &fallback
 }
 
 // MergeRunnerConfig merges test-specific runner config with host-specific config
@@ -163,17 +553,20 @@ func (c *TestConfig) MergeRunnerConfig(hostConfig *runner.Config, testConfig *ru
 	
 	// Create a merged configuration
 	merged := &runner.Config{
-		Duration:   hostConfig.Duration,
-		Args:       make(map[string]interface{}),
-		Env:        make(map[string]string),
-		ServerArgs: make(map[string]interface{}),
-		ClientArgs: make(map[string]interface{}),
-		ServerEnv:  make(map[string]string),
-		ClientEnv:  make(map[string]string),
-		Role:       hostConfig.Role,
-		Host:       hostConfig.Host,
-		TargetHost: hostConfig.TargetHost,
-		Port:       hostConfig.Port,
+		Duration:    hostConfig.Duration,
+		Args:        make(map[string]interface{}),
+		Env:         make(map[string]string),
+		ServerArgs:  make(map[string]interface{}),
+		ClientArgs:  make(map[string]interface{}),
+		ServerEnv:   make(map[string]string),
+		ClientEnv:   make(map[string]string),
+		Role:        hostConfig.Role,
+		Host:        hostConfig.Host,
+		TargetHost:  hostConfig.TargetHost,
+		Port:        hostConfig.Port,
+		ListenPort:  hostConfig.ListenPort,
+		ConnectPort: hostConfig.ConnectPort,
+		CPUAffinity: hostConfig.CPUAffinity,
 	}
 	
 	// Copy host config
@@ -209,6 +602,15 @@ func (c *TestConfig) MergeRunnerConfig(hostConfig *runner.Config, testConfig *ru
 	if testConfig.Port > 0 {
 		merged.Port = testConfig.Port
 	}
+	if testConfig.ListenPort > 0 {
+		merged.ListenPort = testConfig.ListenPort
+	}
+	if testConfig.ConnectPort > 0 {
+		merged.ConnectPort = testConfig.ConnectPort
+	}
+	if testConfig.CPUAffinity != "" {
+		merged.CPUAffinity = testConfig.CPUAffinity
+	}
 	if testConfig.Role != "" {
 		merged.Role = testConfig.Role
 	}
@@ -255,4 +657,58 @@ func (c *TestConfig) SaveConfig(filename string) error {
 	}
 	
 	return nil
+}
+
+// RedactedSecret replaces SSH passwords and key passphrases in a saved
+// effective configuration
+const RedactedSecret = "REDACTED"
+
+// SaveEffectiveConfig saves the configuration like SaveConfig, but with SSH
+// passwords and key passphrases, including those of jump hosts, replaced by
+// RedactedSecret, and readable by its owner only. Environment references are
+// already expanded, so the secrets would otherwise be written in plain text.
+func (c *TestConfig) SaveEffectiveConfig(filename string) error {
+	redacted := *c
+	redacted.Hosts = make(map[string]*HostConfig, len(c.Hosts))
+	for name, host := range c.Hosts {
+		if host == nil {
+			redacted.Hosts[name] = nil
+			continue
+		}
+		hostCopy := *host
+		hostCopy.SSH = redactSSHSecrets(host.SSH)
+		redacted.Hosts[name] = &hostCopy
+	}
+	
+	data, err := yaml.Marshal(&redacted)
+	if err != nil {
+		return fmt.Errorf("failed to marshal config: %w", err)
+	}
+	
+	// WriteFile keeps the mode of an existing file, so set it explicitly
+	if err := os.WriteFile(filename, data, 0600); err != nil {
+		return fmt.Errorf("failed to write config file %s: %w", filename, err)
+	}
+	if err := os.Chmod(filename, 0600); err != nil {
+		return fmt.Errorf("failed to restrict config file %s: %w", filename, err)
+	}
+	
+	return nil
+}
+
+// redactSSHSecrets returns a copy of an SSH config, and of its jump hosts,
+// with the password and key passphrase redacted
+func redactSSHSecrets(sshConfig *ssh.Config) *ssh.Config {
+	if sshConfig == nil {
+		return nil
+	}
+	redacted := *sshConfig
+	if redacted.Password != "" {
+		redacted.Password = RedactedSecret
+	}
+	if redacted.KeyPassphrase != "" {
+		redacted.KeyPassphrase = RedactedSecret
+	}
+	redacted.ProxyJump = redactSSHSecrets(sshConfig.ProxyJump)
+	return &redacted
 }
\ No newline at end of file
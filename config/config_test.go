@@ -3,9 +3,12 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
+	"gopkg.in/yaml.v3"
+
 	"perf-runner/runner"
 	"perf-runner/ssh"
 )
@@ -341,6 +344,18 @@ func TestMergeRunnerConfig(t *testing.T) {
 	}
 }
 
+func TestMergeRunnerConfig_RolePorts(t *testing.T) {
+	hostConfig := &runner.Config{Port: 5201, ListenPort: 5202}
+	testConfig := &runner.Config{ConnectPort: 6201}
+
+	config := &TestConfig{}
+	result := config.MergeRunnerConfig(hostConfig, testConfig)
+
+	if result.Port != 5201 || result.ListenPort != 5202 || result.ConnectPort != 6201 {
+		t.Errorf("Expected ports 5201/5202/6201, got %d/%d/%d", result.Port, result.ListenPort, result.ConnectPort)
+	}
+}
+
 func TestMergeRunnerConfig_NilConfigs(t *testing.T) {
 	config := &TestConfig{}
 
@@ -503,4 +518,56 @@ func TestSaveConfig_InvalidPath(t *testing.T) {
 	if err == nil {
 		t.Error("Expected error for invalid path")
 	}
-}
\ No newline at end of file
+}
+
+func TestGetRunner_ScenarioOverride(t *testing.T) {
+	config := &TestConfig{
+		Runner: "iperf3",
+		Tests: []TestScenario{
+			{Name: "default"},
+			{Name: "rdma", Runner: "ib_send_bw"},
+			{Name: "rdma again", Runner: "ib_send_bw"},
+		},
+	}
+
+	if got := config.GetRunner(&config.Tests[0]); got != "iperf3" {
+		t.Errorf("Expected top-level runner, got %s", got)
+	}
+	if got := config.GetRunner(&config.Tests[1]); got != "ib_send_bw" {
+		t.Errorf("Expected scenario runner, got %s", got)
+	}
+	if got := strings.Join(config.RunnerNames(), ","); got != "iperf3,ib_send_bw" {
+		t.Errorf("Expected iperf3,ib_send_bw, got %s", got)
+	}
+}
+
+func TestTestScenario_HostNames(t *testing.T) {
+	test := TestScenario{
+		Chain:         []string{"c", "r1", "r2", "s"},
+		Clients:       []string{"c2", "c"},
+		PreFiles:      []FileTransfer{{Host: "s"}},
+		PostFiles:     []FileTransfer{{Host: "capture"}},
+		QueueStats:    map[string]string{"s": "eth0", "nic": "eth1"},
+		FallbackHosts: map[string]string{"server": "spare"},
+	}
+	test.expandChain()
+	if got := strings.Join(test.HostNames(), ","); got != "c,s,r1,r2,c2,capture,nic" {
+		t.Errorf("Expected every host once, without fallbacks, got %s", got)
+	}
+}
+
+func TestTestConfig_NodeStartupDelayZeroIsSet(t *testing.T) {
+	var unset, zero TestConfig
+	if err := yaml.Unmarshal([]byte("tests: []\n"), &unset); err != nil {
+		t.Fatal(err)
+	}
+	if err := yaml.Unmarshal([]byte("node_startup_delay: 0s\n"), &zero); err != nil {
+		t.Fatal(err)
+	}
+	if unset.NodeStartupDelay != nil {
+		t.Errorf("Expected no delay when unset, got %v", *unset.NodeStartupDelay)
+	}
+	if zero.NodeStartupDelay == nil || *zero.NodeStartupDelay != 0 {
+		t.Errorf("Expected an explicit zero delay, got %v", zero.NodeStartupDelay)
+	}
+}
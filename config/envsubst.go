@@ -0,0 +1,85 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+
+	"gopkg.in/yaml.v3"
+)
+
+// envRefPattern matches ${VAR} and ${VAR:-default}; a leading $$ escapes the
+// reference so it is kept literally, e.g. for remote shell commands
+var envRefPattern = regexp.MustCompile(`\$?\$\{([A-Za-z_][A-Za-z0-9_]*)(:-[^}]*)?\}`)
+
+// unmarshalWithEnv parses YAML into out after expanding environment variable
+// references in every scalar value. Values are expanded after parsing, so a
+// substituted password containing YAML syntax such as "#" or ": " stays one
+// value.
+func unmarshalWithEnv(data []byte, out interface{}) error {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return err
+	}
+	if err := expandEnvNode(&root); err != nil {
+		return err
+	}
+	// An empty document decodes to nothing, as with yaml.Unmarshal
+	if root.Kind == 0 {
+		return nil
+	}
+	return root.Decode(out)
+}
+
+// expandEnvNode expands environment variable references in the scalars of
+// node and its children
+func expandEnvNode(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		value, err := expandEnv(node.Value)
+		if err != nil {
+			return fmt.Errorf("line %d: %w", node.Line, err)
+		}
+		if value != node.Value {
+			node.Value = value
+			// Let a plain substituted value resolve anew, so "${PORT}" can fill an int
+			if node.Style == 0 {
+				node.Tag = ""
+			}
+		}
+		return nil
+	}
+	for _, child := range node.Content {
+		if err := expandEnvNode(child); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// expandEnv replaces ${VAR} with the variable's value and ${VAR:-default}
+// with its value or, when it is unset or empty, the default. Referencing an
+// unset variable without a default is an error.
+func expandEnv(value string) (string, error) {
+	var missing string
+	expanded := envRefPattern.ReplaceAllStringFunc(value, func(ref string) string {
+		if ref[1] == '$' {
+			return ref[1:]
+		}
+		match := envRefPattern.FindStringSubmatch(ref)
+		name, fallback := match[1], match[2]
+		if v, set := os.LookupEnv(name); set && (v != "" || fallback == "") {
+			return v
+		}
+		if fallback != "" {
+			return fallback[len(":-"):]
+		}
+		if missing == "" {
+			missing = name
+		}
+		return ref
+	})
+	if missing != "" {
+		return "", fmt.Errorf("environment variable %s is not set and has no default", missing)
+	}
+	return expanded, nil
+}
@@ -0,0 +1,108 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const envConfig = `
+name: "Env"
+runner: "iperf3"
+
+hosts:
+  server1:
+    ssh:
+      host: "${SERVER_IP}"
+      user: ${SSH_USER:-perf}
+      password: "${HOST_PASSWORD}"
+  client1:
+    ssh:
+      host: "192.168.1.101"
+      user: "testuser"
+      key_path: "~/.ssh/id_rsa"
+
+tests:
+  - name: "TCP"
+    client: "client1"
+    server: "server1"
+    config:
+      port: ${IPERF_PORT}
+      args:
+        bitrate: "${RATE:-10G}"
+        title: "run on ${SERVER_IP}"
+`
+
+func writeEnvConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "env.yaml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	return path
+}
+
+func TestLoadConfig_ExpandsEnvironment(t *testing.T) {
+	t.Setenv("SERVER_IP", "10.0.0.2")
+	t.Setenv("HOST_PASSWORD", "s3cret #1: yes")
+	t.Setenv("IPERF_PORT", "6201")
+
+	cfg, err := LoadConfig(writeEnvConfig(t, envConfig))
+	if err != nil {
+		t.Fatalf("LoadConfig returned error: %v", err)
+	}
+
+	ssh := cfg.Hosts["server1"].SSH
+	if ssh.Host != "10.0.0.2" || ssh.User != "perf" || ssh.Password != "s3cret #1: yes" {
+		t.Errorf("Unexpected SSH config host=%q user=%q password=%q", ssh.Host, ssh.User, ssh.Password)
+	}
+
+	test := cfg.Tests[0].Config
+	if test.Port != 6201 {
+		t.Errorf("Expected port 6201, got %d", test.Port)
+	}
+	if test.Args["bitrate"] != "10G" || test.Args["title"] != "run on 10.0.0.2" {
+		t.Errorf("Unexpected args %v", test.Args)
+	}
+}
+
+func TestLoadConfig_UnsetEnvironmentVariable(t *testing.T) {
+	t.Setenv("SERVER_IP", "10.0.0.2")
+	t.Setenv("IPERF_PORT", "6201")
+	os.Unsetenv("HOST_PASSWORD")
+
+	_, err := LoadConfig(writeEnvConfig(t, envConfig))
+	if err == nil || !strings.Contains(err.Error(), "HOST_PASSWORD is not set") {
+		t.Errorf("Expected an unset variable error, got %v", err)
+	}
+}
+
+func TestExpandEnv(t *testing.T) {
+	t.Setenv("SET", "value")
+	t.Setenv("EMPTY", "")
+
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"${SET}", "value"},
+		{"a-${SET}-b", "a-value-b"},
+		{"${EMPTY}", ""},
+		{"${EMPTY:-fallback}", "fallback"},
+		{"${UNSET_FOR_TEST:-fallback}", "fallback"},
+		{"$${UNSET_FOR_TEST}", "${UNSET_FOR_TEST}"},
+		{"cost $5", "cost $5"},
+	}
+
+	for _, tt := range tests {
+		got, err := expandEnv(tt.input)
+		if err != nil {
+			t.Errorf("expandEnv(%q) returned error: %v", tt.input, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("expandEnv(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,225 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// HostResolver resolves one form of host reference to a key of the hosts
+// map. It reports matched=false when ref is not in its form, so the next
+// resolver can try it, and an error when ref is in its form but names no
+// single host.
+type HostResolver func(c *TestConfig, ref string) (name string, matched bool, err error)
+
+// hostResolvers are tried in order on every host reference. The SSH address
+// form comes last since any unmatched name is tried as an address.
+var hostResolvers = []HostResolver{
+	resolveHostKey,
+	resolveHostLabel,
+	resolveHostGroupIndex,
+	resolveHostAddress,
+}
+
+// RegisterHostResolver adds a resolver for another form of host reference.
+// It is tried after the built-in forms other than the SSH address.
+func RegisterHostResolver(resolver HostResolver) {
+	last := len(hostResolvers) - 1
+	hostResolvers = append(hostResolvers[:last:last], resolver, hostResolvers[last])
+}
+
+// ResolveHostRef resolves a host reference in a scenario to a key of the
+// hosts map. A reference is a host key, "@label" for the host carrying that
+// label, "group[i]" for the i-th (0-based) host of a host group, or the SSH
+// address of a host.
+func (c *TestConfig) ResolveHostRef(ref string) (string, error) {
+	for _, resolver := range hostResolvers {
+		name, matched, err := resolver(c, ref)
+		if err != nil {
+			return "", err
+		}
+		if matched {
+			return name, nil
+		}
+	}
+	return "", fmt.Errorf("host %s not found in hosts configuration", ref)
+}
+
+// ResolveHostRefs rewrites the host references of every scenario and
+// scenario group to host keys, so the rest of the run only deals with keys
+func (c *TestConfig) ResolveHostRefs() error {
+	for i := range c.Groups {
+		group := &c.Groups[i]
+		for _, commands := range []*map[string][]string{&group.Setup, &group.Teardown} {
+			if *commands == nil {
+				continue
+			}
+			refs := make([]string, 0, len(*commands))
+			for ref := range *commands {
+				refs = append(refs, ref)
+			}
+			names, err := c.resolveHostKeyRefs(refs)
+			if err != nil {
+				return fmt.Errorf("group %s: %w", group.Name, err)
+			}
+			resolved := make(map[string][]string, len(names))
+			for ref, name := range names {
+				resolved[name] = (*commands)[ref]
+			}
+			*commands = resolved
+		}
+	}
+
+	for i := range c.Tests {
+		test := &c.Tests[i]
+		refs := []*string{&test.Client, &test.Server, &test.Intermediate}
+		for j := range test.Clients {
+			refs = append(refs, &test.Clients[j])
+		}
+		for j := range test.Chain {
+			refs = append(refs, &test.Chain[j])
+		}
+		for j := range test.PreFiles {
+			refs = append(refs, &test.PreFiles[j].Host)
+		}
+		for j := range test.PostFiles {
+			refs = append(refs, &test.PostFiles[j].Host)
+		}
+		for _, ref := range refs {
+			if *ref == "" {
+				continue
+			}
+			name, err := c.ResolveHostRef(*ref)
+			if err != nil {
+				return fmt.Errorf("test %s: %w", test.Name, err)
+			}
+			*ref = name
+		}
+		for role, ref := range test.FallbackHosts {
+			name, err := c.ResolveHostRef(ref)
+			if err != nil {
+				return fmt.Errorf("test %s: fallback %s: %w", test.Name, role, err)
+			}
+			test.FallbackHosts[role] = name
+		}
+		if test.QueueStats != nil {
+			refs := make([]string, 0, len(test.QueueStats))
+			for ref := range test.QueueStats {
+				refs = append(refs, ref)
+			}
+			names, err := c.resolveHostKeyRefs(refs)
+			if err != nil {
+				return fmt.Errorf("test %s: queue_stats: %w", test.Name, err)
+			}
+			resolved := make(map[string]string, len(names))
+			for ref, name := range names {
+				resolved[name] = test.QueueStats[ref]
+			}
+			test.QueueStats = resolved
+		}
+	}
+	return nil
+}
+
+// resolveHostKeyRefs returns the host key named by each reference in refs.
+// Two references to the same host are an error, since the entries they key
+// would silently replace each other.
+func (c *TestConfig) resolveHostKeyRefs(refs []string) (map[string]string, error) {
+	sort.Strings(refs)
+	names := make(map[string]string, len(refs))
+	seen := make(map[string]bool, len(refs))
+	for _, ref := range refs {
+		name, err := c.ResolveHostRef(ref)
+		if err != nil {
+			return nil, err
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("host %s is listed more than once", name)
+		}
+		seen[name] = true
+		names[ref] = name
+	}
+	return names, nil
+}
+
+// resolveHostKey matches a key of the hosts map
+func resolveHostKey(c *TestConfig, ref string) (string, bool, error) {
+	_, exists := c.Hosts[ref]
+	return ref, exists, nil
+}
+
+// resolveHostLabel matches "@label" to the one host carrying the label
+func resolveHostLabel(c *TestConfig, ref string) (string, bool, error) {
+	label, isLabel := strings.CutPrefix(ref, "@")
+	if !isLabel {
+		return "", false, nil
+	}
+	names := matchingHosts(c, func(host *HostConfig) bool {
+		for _, l := range host.Labels {
+			if l == label {
+				return true
+			}
+		}
+		return false
+	})
+	if len(names) == 0 {
+		return "", true, fmt.Errorf("no host has label %s", label)
+	}
+	name, err := onlyHost("label "+label, names)
+	return name, true, err
+}
+
+// hostGroupIndexRegex matches "group[i]" references
+var hostGroupIndexRegex = regexp.MustCompile(`^(.+)\[(\d+)\]$`)
+
+// resolveHostGroupIndex matches "group[i]" to the i-th host of a host group
+func resolveHostGroupIndex(c *TestConfig, ref string) (string, bool, error) {
+	match := hostGroupIndexRegex.FindStringSubmatch(ref)
+	if match == nil {
+		return "", false, nil
+	}
+	group, exists := c.HostGroups[match[1]]
+	if !exists {
+		return "", true, fmt.Errorf("host group %s not found in host_groups configuration", match[1])
+	}
+	index, err := strconv.Atoi(match[2])
+	if err != nil || index >= len(group) {
+		return "", true, fmt.Errorf("host group %s has no host %s (it has %d)", match[1], match[2], len(group))
+	}
+	return group[index], true, nil
+}
+
+// resolveHostAddress matches the SSH address of exactly one host
+func resolveHostAddress(c *TestConfig, ref string) (string, bool, error) {
+	names := matchingHosts(c, func(host *HostConfig) bool {
+		return host.SSH != nil && host.SSH.Host == ref
+	})
+	if len(names) == 0 {
+		return "", false, nil
+	}
+	name, err := onlyHost("SSH host "+ref, names)
+	return name, true, err
+}
+
+// matchingHosts returns the keys of the hosts satisfying match, sorted
+func matchingHosts(c *TestConfig, match func(host *HostConfig) bool) []string {
+	var names []string
+	for name, host := range c.Hosts {
+		if host != nil && match(host) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// onlyHost returns the single host key in names, or an error naming every
+// host a reference is ambiguous between
+func onlyHost(what string, names []string) (string, error) {
+	if len(names) > 1 {
+		return "", fmt.Errorf("%s matches several hosts: %s", what, strings.Join(names, ", "))
+	}
+	return names[0], nil
+}
@@ -0,0 +1,145 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"perf-runner/ssh"
+)
+
+// hostRefConfig has three hosts, two of them in a host group
+func hostRefConfig() *TestConfig {
+	host := func(addr string, labels ...string) *HostConfig {
+		return &HostConfig{SSH: &ssh.Config{Host: addr, User: "u", KeyPath: "k"}, Labels: labels}
+	}
+	return &TestConfig{
+		Hosts: map[string]*HostConfig{
+			"gen1": host("10.0.0.1", "loadgen"),
+			"gen2": host("10.0.0.2"),
+			"dut":  host("10.0.0.9", "dut", "rdma"),
+		},
+		HostGroups: map[string][]string{"generators": {"gen1", "gen2"}},
+	}
+}
+
+func TestResolveHostRef(t *testing.T) {
+	tests := []struct {
+		ref  string
+		want string
+	}{
+		{"gen2", "gen2"},
+		{"@dut", "dut"},
+		{"@rdma", "dut"},
+		{"@loadgen", "gen1"},
+		{"generators[0]", "gen1"},
+		{"generators[1]", "gen2"},
+		{"10.0.0.9", "dut"},
+	}
+	c := hostRefConfig()
+	for _, tt := range tests {
+		got, err := c.ResolveHostRef(tt.ref)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.ref, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s: expected %s, got %s", tt.ref, tt.want, got)
+		}
+	}
+}
+
+func TestResolveHostRef_Errors(t *testing.T) {
+	c := hostRefConfig()
+	c.Hosts["gen2"].Labels = []string{"loadgen"}
+	c.Hosts["dut2"] = &HostConfig{SSH: &ssh.Config{Host: "10.0.0.9", Port: 2222}}
+
+	tests := map[string]string{
+		"@loadgen":      "several hosts: gen1, gen2",
+		"@missing":      "no host has label missing",
+		"generators[2]": "has no host 2",
+		"spares[0]":     "host group spares not found",
+		"10.0.0.9":      "several hosts: dut, dut2",
+		"nowhere":       "host nowhere not found",
+	}
+	for ref, want := range tests {
+		_, err := c.ResolveHostRef(ref)
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%s: expected error containing %q, got %v", ref, want, err)
+		}
+	}
+}
+
+func TestLoadConfig_ResolvesHostRefs(t *testing.T) {
+	content := `name: refs
+runner: ethr
+hosts:
+  gen1:
+    ssh: {host: 10.0.0.1, user: u, key_path: k}
+  gen2:
+    ssh: {host: 10.0.0.2, user: u, key_path: k}
+  dut:
+    ssh: {host: 10.0.0.9, user: u, key_path: k}
+    labels: [dut]
+  spare:
+    ssh: {host: 10.0.0.3, user: u, key_path: k}
+host_groups:
+  generators: [gen1, gen2]
+groups:
+  - name: tuned
+    setup:
+      "@dut": ["sysctl -w net.core.rmem_max=268435456"]
+    teardown:
+      10.0.0.9: ["sysctl -w net.core.rmem_max=212992"]
+tests:
+  - name: incast
+    group: tuned
+    client: generators[0]
+    clients: ["generators[1]"]
+    server: "@dut"
+    fallback_hosts:
+      client: 10.0.0.3
+    queue_stats:
+      "@dut": eth1
+    pre_files:
+      - {host: "@dut", local: tune.sh, remote: /tmp/tune.sh}
+    post_files:
+      - {host: "generators[0]", local: run.pcap, remote: /tmp/run.pcap}
+`
+	path := filepath.Join(t.TempDir(), "refs.yaml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	config, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	test := config.Tests[0]
+	if test.Client != "gen1" || test.Server != "dut" || test.Clients[0] != "gen2" || test.FallbackHosts["client"] != "spare" {
+		t.Errorf("Expected references resolved to host keys, got client=%s server=%s clients=%v fallback=%v",
+			test.Client, test.Server, test.Clients, test.FallbackHosts)
+	}
+	if test.QueueStats["dut"] != "eth1" || test.PreFiles[0].Host != "dut" || test.PostFiles[0].Host != "gen1" {
+		t.Errorf("Expected queue_stats and file hosts resolved, got queue_stats=%v pre_files=%s post_files=%s",
+			test.QueueStats, test.PreFiles[0].Host, test.PostFiles[0].Host)
+	}
+	group := config.GetGroup("tuned")
+	if len(group.Setup["dut"]) != 1 || len(group.Teardown["dut"]) != 1 {
+		t.Errorf("Expected group setup and teardown keyed by dut, got setup=%v teardown=%v", group.Setup, group.Teardown)
+	}
+}
+
+func TestResolveHostRefs_RejectsDuplicateHostKeys(t *testing.T) {
+	c := &TestConfig{
+		Hosts: map[string]*HostConfig{
+			"dut": {SSH: &ssh.Config{Host: "10.0.0.9"}, Labels: []string{"dut"}},
+		},
+		Tests: []TestScenario{{Name: "dup", QueueStats: map[string]string{"dut": "eth0", "@dut": "eth1"}}},
+	}
+	err := c.ResolveHostRefs()
+	if err == nil || !strings.Contains(err.Error(), "host dut is listed more than once") {
+		t.Errorf("Expected a duplicate host error, got %v", err)
+	}
+}
@@ -0,0 +1,186 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"perf-runner/runner"
+
+	"gopkg.in/yaml.v3"
+)
+
+// MatrixAxis is one dimension of a scenario matrix: a runner arg and the
+// values it takes
+type MatrixAxis struct {
+	Arg    string
+	Values []interface{}
+}
+
+// Matrix lists the axes of a scenario matrix in the order they are written,
+// e.g. "matrix: {size: [1024, 4096], qp: [1, 4]}"
+type Matrix []MatrixAxis
+
+// UnmarshalYAML reads a mapping of arg names to lists of values, keeping the
+// written order so generated names follow it
+func (m *Matrix) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.MappingNode {
+		return fmt.Errorf("line %d: matrix must map args to lists of values", node.Line)
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		var axis MatrixAxis
+		if err := node.Content[i].Decode(&axis.Arg); err != nil {
+			return err
+		}
+		if err := node.Content[i+1].Decode(&axis.Values); err != nil {
+			return fmt.Errorf("line %d: matrix axis %s must be a list of values", node.Content[i+1].Line, axis.Arg)
+		}
+		*m = append(*m, axis)
+	}
+	return nil
+}
+
+// MarshalYAML writes the axes back as a mapping in their order
+func (m Matrix) MarshalYAML() (interface{}, error) {
+	node := &yaml.Node{Kind: yaml.MappingNode}
+	for _, axis := range m {
+		var values yaml.Node
+		if err := values.Encode(axis.Values); err != nil {
+			return nil, err
+		}
+		node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: axis.Arg}, &values)
+	}
+	return node, nil
+}
+
+// expandMatrices replaces every scenario that has a matrix with one plain
+// scenario per combination of its axis values, so the coordinator only sees
+// concrete scenarios
+func (c *TestConfig) expandMatrices() error {
+	var tests []TestScenario
+	for i := range c.Tests {
+		test := &c.Tests[i]
+		if len(test.Matrix) == 0 {
+			tests = append(tests, *test)
+			continue
+		}
+
+		expanded, err := test.expandMatrix()
+		if err != nil {
+			return err
+		}
+		runnerName := test.Runner
+		if runnerName == "" {
+			runnerName = c.Runner
+		}
+		if err := validateMatrixArgs(runnerName, test, expanded); err != nil {
+			return err
+		}
+		tests = append(tests, expanded...)
+	}
+	c.Tests = tests
+	return nil
+}
+
+// expandMatrix returns the scenarios for every combination of the matrix
+// values, the first axis varying slowest. Each is named after the values it
+// sets, e.g. "RDMA [size=4096 qp=4]".
+func (t *TestScenario) expandMatrix() ([]TestScenario, error) {
+	seen := make(map[string]bool)
+	for _, axis := range t.Matrix {
+		if axis.Arg == "" {
+			return nil, fmt.Errorf("test %s: matrix axis name is required", t.Name)
+		}
+		if seen[axis.Arg] {
+			return nil, fmt.Errorf("test %s: duplicate matrix axis %s", t.Name, axis.Arg)
+		}
+		seen[axis.Arg] = true
+		if len(axis.Values) == 0 {
+			return nil, fmt.Errorf("test %s: matrix axis %s has no values", t.Name, axis.Arg)
+		}
+		for _, value := range axis.Values {
+			switch value.(type) {
+			case map[string]interface{}, []interface{}, nil:
+				return nil, fmt.Errorf("test %s: matrix axis %s values must be scalars, got %v", t.Name, axis.Arg, value)
+			}
+		}
+	}
+
+	base := *t
+	base.Matrix = nil
+	scenarios := []TestScenario{base}
+	labels := []string{""}
+	for _, axis := range t.Matrix {
+		var nextScenarios []TestScenario
+		var nextLabels []string
+		for i := range scenarios {
+			for _, value := range axis.Values {
+				nextScenarios = append(nextScenarios, *scenarios[i].WithArg(axis.Arg, value))
+				nextLabels = append(nextLabels, strings.TrimSpace(fmt.Sprintf("%s %s=%v", labels[i], axis.Arg, value)))
+			}
+		}
+		scenarios, labels = nextScenarios, nextLabels
+	}
+
+	for i := range scenarios {
+		scenarios[i].Name = fmt.Sprintf("%s [%s]", t.Name, labels[i])
+	}
+	return scenarios, nil
+}
+
+// validateMatrixArgs checks that the runner accepts the args of every
+// expanded scenario in each role it supports. A role the base scenario is
+// not valid for on its own, e.g. because it relies on host-level args, is
+// skipped, so only errors introduced by matrix values are reported. An axis
+// that changes no role's command is not an arg the runner knows, e.g. a
+// misspelled one, and is rejected. An unknown runner is left to the
+// coordinator to report.
+func validateMatrixArgs(runnerName string, base *TestScenario, scenarios []TestScenario) error {
+	r, err := runner.Create(runnerName)
+	if err != nil {
+		return nil
+	}
+
+	roleConfig := func(test *TestScenario, role string) runner.Config {
+		var config runner.Config
+		if test.Config != nil {
+			config = *test.Config
+		}
+		config.Role = role
+		// Clients need a target; the real one is only known at run time
+		config.TargetHost = "matrix-target"
+		return config
+	}
+
+	roles := []string{"server", "client", "intermediate"}
+	for _, axis := range base.Matrix {
+		used := false
+		for _, role := range roles {
+			if !r.SupportsRole(role) {
+				continue
+			}
+			commands := map[string]bool{r.BuildCommand(roleConfig(base, role)): true}
+			for _, value := range axis.Values {
+				commands[r.BuildCommand(roleConfig(base.WithArg(axis.Arg, value), role))] = true
+			}
+			if len(commands) > 1 {
+				used = true
+				break
+			}
+		}
+		if !used {
+			return fmt.Errorf("test %s: matrix axis %s is not a %s arg: its values do not change any command", base.Name, axis.Arg, runnerName)
+		}
+	}
+
+	for _, role := range roles {
+		if !r.SupportsRole(role) || r.Validate(roleConfig(base, role)) != nil {
+			continue
+		}
+		for i := range scenarios {
+			if err := r.Validate(roleConfig(&scenarios[i], role)); err != nil {
+				return fmt.Errorf("test %s: invalid matrix value for %s: %w", scenarios[i].Name, runnerName, err)
+			}
+		}
+	}
+	return nil
+}
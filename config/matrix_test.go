@@ -0,0 +1,114 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const matrixConfig = `
+name: "Matrix"
+runner: "iperf3"
+
+hosts:
+  server1:
+    ssh:
+      host: "192.168.1.100"
+      user: "testuser"
+      key_path: "~/.ssh/id_rsa"
+  client1:
+    ssh:
+      host: "192.168.1.101"
+      user: "testuser"
+      key_path: "~/.ssh/id_rsa"
+
+tests:
+  - name: "Sweep"
+    client: "client1"
+    server: "server1"
+    config:
+      duration: 10s
+      args:
+        json: true
+    matrix:
+      buffer_length: [1K, 4K, 64K]
+      parallel_streams: [1, 4]
+  - name: "Plain"
+    client: "client1"
+    server: "server1"
+`
+
+func loadMatrixConfig(t *testing.T, content string) (*TestConfig, error) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "matrix.yaml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	return LoadConfig(path)
+}
+
+func TestLoadConfig_ExpandsMatrix(t *testing.T) {
+	cfg, err := loadMatrixConfig(t, matrixConfig)
+	if err != nil {
+		t.Fatalf("LoadConfig returned error: %v", err)
+	}
+
+	var names []string
+	for _, test := range cfg.Tests {
+		names = append(names, test.Name)
+	}
+	want := []string{
+		"Sweep [buffer_length=1K parallel_streams=1]",
+		"Sweep [buffer_length=1K parallel_streams=4]",
+		"Sweep [buffer_length=4K parallel_streams=1]",
+		"Sweep [buffer_length=4K parallel_streams=4]",
+		"Sweep [buffer_length=64K parallel_streams=1]",
+		"Sweep [buffer_length=64K parallel_streams=4]",
+		"Plain",
+	}
+	if !reflect.DeepEqual(names, want) {
+		t.Fatalf("Expected scenarios %v, got %v", want, names)
+	}
+
+	last := cfg.Tests[5]
+	if last.Matrix != nil {
+		t.Errorf("Expected expanded scenarios to have no matrix, got %v", last.Matrix)
+	}
+	wantArgs := map[string]interface{}{"json": true, "buffer_length": "64K", "parallel_streams": 4}
+	if !reflect.DeepEqual(last.Config.Args, wantArgs) {
+		t.Errorf("Expected args %v, got %v", wantArgs, last.Config.Args)
+	}
+	if last.Client != "client1" || last.Config.Duration.String() != "10s" {
+		t.Errorf("Expected the base scenario's settings to be kept, got %+v", last)
+	}
+	if _, set := cfg.Tests[0].Config.Args["parallel_streams"]; !set || cfg.Tests[0].Config.Args["buffer_length"] != "1K" {
+		t.Errorf("Expected each scenario to have its own args, got %v", cfg.Tests[0].Config.Args)
+	}
+}
+
+func TestLoadConfig_RejectsInvalidMatrix(t *testing.T) {
+	tests := []struct {
+		name   string
+		matrix string
+		want   string
+	}{
+		{"invalid runner arg", "parallel_streams: [4, 0]", "Sweep [parallel_streams=0]: invalid matrix value for iperf3: parallel_streams must be greater than 0"},
+		{"empty axis", "buffer_length: []", "matrix axis buffer_length has no values"},
+		{"unknown arg", "length: [1024, 4096]", "matrix axis length is not a iperf3 arg"},
+		{"not a list", "buffer_length: 1024", "matrix axis buffer_length must be a list of values"},
+		{"nested value", "buffer_length: [[1, 2]]", "values must be scalars"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content := strings.Replace(matrixConfig, `      buffer_length: [1K, 4K, 64K]
+      parallel_streams: [1, 4]`, "      "+tt.matrix, 1)
+			_, err := loadMatrixConfig(t, content)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package config
+
+import (
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// LoadConfigs loads one or more configuration files, deep-merging each file
+// over the ones before it (e.g. a base file and a CI override), then applies
+// defaults and validates the merged configuration. Environment variable
+// references are expanded in each file before merging.
+func LoadConfigs(filenames ...string) (*TestConfig, error) {
+	if len(filenames) == 0 {
+		return nil, fmt.Errorf("no config file given")
+	}
+	if len(filenames) == 1 {
+		return LoadConfig(filenames[0])
+	}
+
+	var merged map[string]interface{}
+	for _, filename := range filenames {
+		data, err := os.ReadFile(filename)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read config file %s: %w", filename, err)
+		}
+
+		var overlay map[string]interface{}
+		if err := unmarshalWithEnv(data, &overlay); err != nil {
+			return nil, fmt.Errorf("failed to parse config file %s: %w", filename, err)
+		}
+		merged = mergeMaps(merged, overlay)
+	}
+
+	data, err := yaml.Marshal(merged)
+	if err != nil {
+		return nil, fmt.Errorf("failed to merge config files: %w", err)
+	}
+
+	var config TestConfig
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse merged config files %v: %w", filenames, err)
+	}
+	return finishConfig(&config)
+}
+
+// mergeMaps deep-merges overlay into base. Nested maps merge key by key, lists
+// of named entries (such as tests) merge entry by name, and any other
+// overlay value replaces the base value.
+func mergeMaps(base, overlay map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(base)+len(overlay))
+	for key, value := range base {
+		merged[key] = value
+	}
+	for key, value := range overlay {
+		merged[key] = mergeValues(merged[key], value)
+	}
+	return merged
+}
+
+// mergeValues merges one overlay value over its base value
+func mergeValues(base, overlay interface{}) interface{} {
+	switch overlayValue := overlay.(type) {
+	case map[string]interface{}:
+		if baseMap, ok := base.(map[string]interface{}); ok {
+			return mergeMaps(baseMap, overlayValue)
+		}
+	case []interface{}:
+		if baseList, ok := base.([]interface{}); ok && isNamedList(baseList) && isNamedList(overlayValue) {
+			return mergeNamedLists(baseList, overlayValue)
+		}
+	}
+	return overlay
+}
+
+// mergeNamedLists merges overlay entries into base entries with the same
+// name; entries with new names are appended
+func mergeNamedLists(base, overlay []interface{}) []interface{} {
+	merged := append([]interface{}{}, base...)
+	index := make(map[interface{}]int, len(base))
+	for i, entry := range base {
+		index[entry.(map[string]interface{})["name"]] = i
+	}
+
+	for _, entry := range overlay {
+		entryMap := entry.(map[string]interface{})
+		if i, exists := index[entryMap["name"]]; exists {
+			merged[i] = mergeMaps(merged[i].(map[string]interface{}), entryMap)
+		} else {
+			merged = append(merged, entry)
+		}
+	}
+	return merged
+}
+
+// isNamedList reports whether every entry of list is a map with a name key
+func isNamedList(list []interface{}) bool {
+	for _, entry := range list {
+		entryMap, ok := entry.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		if _, named := entryMap["name"]; !named {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,184 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"perf-runner/ssh"
+)
+
+const mergeBaseConfig = `
+name: "Base"
+runner: "iperf3"
+
+hosts:
+  server1:
+    ssh:
+      host: "192.168.1.100"
+      user: "testuser"
+      key_path: "~/.ssh/id_rsa"
+    runner:
+      port: 5201
+      args:
+        json: true
+  client1:
+    ssh:
+      host: "192.168.1.101"
+      user: "testuser"
+      key_path: "~/.ssh/id_rsa"
+
+tests:
+  - name: "TCP"
+    client: "client1"
+    server: "server1"
+    config:
+      duration: 10s
+      args:
+        parallel: 4
+  - name: "UDP"
+    client: "client1"
+    server: "server1"
+`
+
+const mergeOverrideConfig = `
+hosts:
+  server1:
+    runner:
+      port: 6201
+
+tests:
+  - name: "TCP"
+    config:
+      duration: 60s
+  - name: "Reverse"
+    client: "client1"
+    server: "server1"
+`
+
+func TestLoadConfigs_OverrideDeepMerges(t *testing.T) {
+	tmpDir := t.TempDir()
+	base := filepath.Join(tmpDir, "base.yaml")
+	override := filepath.Join(tmpDir, "override.yaml")
+	if err := os.WriteFile(base, []byte(mergeBaseConfig), 0644); err != nil {
+		t.Fatalf("Failed to write base config: %v", err)
+	}
+	if err := os.WriteFile(override, []byte(mergeOverrideConfig), 0644); err != nil {
+		t.Fatalf("Failed to write override config: %v", err)
+	}
+
+	config, err := LoadConfigs(base, override)
+	if err != nil {
+		t.Fatalf("LoadConfigs returned error: %v", err)
+	}
+
+	server := config.Hosts["server1"]
+	if server.Runner.Port != 6201 {
+		t.Errorf("Expected overridden port 6201, got %d", server.Runner.Port)
+	}
+	if server.Runner.Args["json"] != true || server.SSH.Host != "192.168.1.100" {
+		t.Errorf("Expected the rest of server1 to be kept, got %+v / %+v", server.Runner, server.SSH)
+	}
+
+	if len(config.Tests) != 3 {
+		t.Fatalf("Expected TCP, UDP and the added Reverse scenario, got %d tests", len(config.Tests))
+	}
+	tcp := config.Tests[0]
+	if tcp.Name != "TCP" || tcp.Config.Duration != 60*time.Second {
+		t.Errorf("Expected TCP duration 60s, got %s %v", tcp.Name, tcp.Config.Duration)
+	}
+	if tcp.Client != "client1" || tcp.Config.Args["parallel"] != 4 {
+		t.Errorf("Expected the rest of TCP to be kept, got client %q args %v", tcp.Client, tcp.Config.Args)
+	}
+	if config.Tests[2].Name != "Reverse" {
+		t.Errorf("Expected the new scenario to be appended, got %s", config.Tests[2].Name)
+	}
+	if config.Timeout != 10*time.Minute {
+		t.Errorf("Expected defaults to apply after merging, got timeout %v", config.Timeout)
+	}
+}
+
+func TestLoadConfigs_ValidatesMergedConfig(t *testing.T) {
+	tmpDir := t.TempDir()
+	base := filepath.Join(tmpDir, "base.yaml")
+	override := filepath.Join(tmpDir, "override.yaml")
+	os.WriteFile(base, []byte(mergeBaseConfig), 0644)
+	os.WriteFile(override, []byte("tests:\n  - name: \"TCP\"\n    server: \"missing\"\n"), 0644)
+
+	if _, err := LoadConfigs(base, override); err == nil {
+		t.Error("Expected validation to reject an override naming an unknown host")
+	}
+}
+
+func TestLoadConfigs_EffectiveConfigRoundTrips(t *testing.T) {
+	tmpDir := t.TempDir()
+	base := filepath.Join(tmpDir, "base.yaml")
+	override := filepath.Join(tmpDir, "override.yaml")
+	os.WriteFile(base, []byte(mergeBaseConfig), 0644)
+	os.WriteFile(override, []byte(mergeOverrideConfig), 0644)
+
+	merged, err := LoadConfigs(base, override)
+	if err != nil {
+		t.Fatalf("LoadConfigs returned error: %v", err)
+	}
+	effective := filepath.Join(tmpDir, "effective.yaml")
+	if err := merged.SaveConfig(effective); err != nil {
+		t.Fatalf("SaveConfig returned error: %v", err)
+	}
+
+	config, err := LoadConfig(effective)
+	if err != nil {
+		t.Fatalf("Failed to reload effective config: %v", err)
+	}
+	if len(config.Tests) != 3 || config.Tests[2].Name != "Reverse" {
+		t.Fatalf("Expected the merged scenarios TCP, UDP and Reverse, got %d tests", len(config.Tests))
+	}
+	tcp := config.Tests[0]
+	if tcp.Config.Duration != 60*time.Second || tcp.Config.Args["parallel"] != 4 {
+		t.Errorf("Expected merged TCP duration 60s and parallel 4 inlined, got %v %v", tcp.Config.Duration, tcp.Config.Args)
+	}
+	if server := config.Hosts["server1"]; server.Runner.Port != 6201 || server.Runner.Args["json"] != true {
+		t.Errorf("Expected merged server1 runner settings inlined, got %+v", server.Runner)
+	}
+	if config.Timeout != 10*time.Minute {
+		t.Errorf("Expected the default timeout to be written, got %v", config.Timeout)
+	}
+}
+
+func TestSaveEffectiveConfig_RedactsSecrets(t *testing.T) {
+	config := &TestConfig{
+		Name: "secrets",
+		Hosts: map[string]*HostConfig{
+			"client1": {SSH: &ssh.Config{Host: "10.0.0.1", User: "u", Password: "hunter2",
+				ProxyJump: &ssh.Config{Host: "bastion", User: "jump", KeyPath: "k", KeyPassphrase: "open sesame"}}},
+		},
+	}
+	effective := filepath.Join(t.TempDir(), "effective.yaml")
+	if err := os.WriteFile(effective, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := config.SaveEffectiveConfig(effective); err != nil {
+		t.Fatalf("SaveEffectiveConfig returned error: %v", err)
+	}
+
+	data, err := os.ReadFile(effective)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{"hunter2", "open sesame"} {
+		if strings.Contains(string(data), secret) {
+			t.Errorf("Expected %q to be redacted, got:\n%s", secret, data)
+		}
+	}
+	if strings.Count(string(data), RedactedSecret) != 2 {
+		t.Errorf("Expected both secrets replaced by %s, got:\n%s", RedactedSecret, data)
+	}
+	if info, err := os.Stat(effective); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("Expected mode 0600, got %v (%v)", info.Mode().Perm(), err)
+	}
+	if config.Hosts["client1"].SSH.Password != "hunter2" || config.Hosts["client1"].SSH.ProxyJump.KeyPassphrase != "open sesame" {
+		t.Error("Expected the in-memory configuration to keep its secrets")
+	}
+}
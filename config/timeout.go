@@ -0,0 +1,58 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// TimeoutSlack is the time a scenario needs beyond its runner duration for
+// binary checks, server startup, and shutdown
+const TimeoutSlack = 15 * time.Second
+
+// ScenarioTimeout returns the timeout for one run of a scenario: its own
+// timeout if set, otherwise the global one
+func (c *TestConfig) ScenarioTimeout(test *TestScenario) time.Duration {
+	if test.Timeout > 0 {
+		return test.Timeout
+	}
+	return c.Timeout
+}
+
+// EffectiveDuration returns the longest runner duration any role of the
+// scenario runs for once host settings are merged in
+func (c *TestConfig) EffectiveDuration(test *TestScenario) time.Duration {
+	var longest time.Duration
+	for _, host := range []string{test.Client, test.Server, test.Intermediate} {
+		hostConfig := c.Hosts[host]
+		if hostConfig == nil {
+			continue
+		}
+		if duration := c.MergeRunnerConfig(hostConfig.Runner, test.Config).Duration; duration > longest {
+			longest = duration
+		}
+	}
+	return longest
+}
+
+// CheckTimeouts returns a warning for each scenario whose duration plus
+// TimeoutSlack does not fit in its timeout, so it would be cut off. With
+// autoExtend, such scenarios instead get a timeout just long enough.
+func (c *TestConfig) CheckTimeouts(autoExtend bool) []string {
+	var warnings []string
+	for i := range c.Tests {
+		test := &c.Tests[i]
+		needed := c.EffectiveDuration(test) + TimeoutSlack
+		timeout := c.ScenarioTimeout(test)
+		if needed <= timeout {
+			continue
+		}
+
+		if autoExtend {
+			test.Timeout = needed
+			warnings = append(warnings, fmt.Sprintf("test %s: extended timeout from %v to %v to fit its %v duration", test.Name, timeout, needed, needed-TimeoutSlack))
+			continue
+		}
+		warnings = append(warnings, fmt.Sprintf("test %s: duration %v plus %v startup slack exceeds the %v timeout; the test will be cut off (raise the timeout or use -auto-timeout)", test.Name, needed-TimeoutSlack, TimeoutSlack, timeout))
+	}
+	return warnings
+}
@@ -0,0 +1,57 @@
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"perf-runner/runner"
+)
+
+func newTimeoutConfig(duration time.Duration) *TestConfig {
+	return &TestConfig{
+		Timeout: 30 * time.Second,
+		Hosts: map[string]*HostConfig{
+			"client1": {},
+			"server1": {Runner: &runner.Config{Duration: 10 * time.Second}},
+		},
+		Tests: []TestScenario{
+			{Name: "long", Client: "client1", Server: "server1", Config: &runner.Config{Duration: duration}},
+			{Name: "host duration", Client: "client1", Server: "server1"},
+		},
+	}
+}
+
+func TestCheckTimeouts_WarnsWhenDurationExceedsTimeout(t *testing.T) {
+	config := newTimeoutConfig(60 * time.Second)
+
+	warnings := config.CheckTimeouts(false)
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "test long: duration 1m0s") {
+		t.Fatalf("Expected one warning for the 60s scenario, got %v", warnings)
+	}
+	if timeout := config.ScenarioTimeout(&config.Tests[0]); timeout != 30*time.Second {
+		t.Errorf("Expected the timeout to be left alone without auto-timeout, got %v", timeout)
+	}
+}
+
+func TestCheckTimeouts_AutoExtend(t *testing.T) {
+	config := newTimeoutConfig(60 * time.Second)
+
+	warnings := config.CheckTimeouts(true)
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "extended timeout") {
+		t.Fatalf("Expected one extension notice, got %v", warnings)
+	}
+	if timeout := config.ScenarioTimeout(&config.Tests[0]); timeout != 60*time.Second+TimeoutSlack {
+		t.Errorf("Expected an adjusted deadline of %v, got %v", 60*time.Second+TimeoutSlack, timeout)
+	}
+	if timeout := config.ScenarioTimeout(&config.Tests[1]); timeout != 30*time.Second {
+		t.Errorf("Expected a fitting scenario to keep the global timeout, got %v", timeout)
+	}
+}
+
+func TestEffectiveDuration_UsesLongestRole(t *testing.T) {
+	config := newTimeoutConfig(0)
+	if duration := config.EffectiveDuration(&config.Tests[1]); duration != 10*time.Second {
+		t.Errorf("Expected the server host's 10s duration, got %v", duration)
+	}
+}
@@ -2,10 +2,24 @@ package config
 
 import (
 	"fmt"
+	"net"
 	"os"
 	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"perf-runner/envinfo"
+	"perf-runner/runner"
+	"perf-runner/ssh"
 )
 
+// cpuListRegex matches a taskset CPU list such as "2-5,8"
+var cpuListRegex = regexp.MustCompile(`^\d+(-\d+)?(,\d+(-\d+)?)*$`)
+
+// interfaceNameRegex matches a network interface name such as ens1f0 or eth0.100
+var interfaceNameRegex = regexp.MustCompile(`^[A-Za-z0-9_.:@-]+$`)
+
 // Validator handles configuration validation
 type Validator struct{}
 
@@ -36,6 +50,40 @@ func (v *Validator) ValidateConfig(c *TestConfig) error {
 		return fmt.Errorf("at least one test scenario must be defined")
 	}
 	
+	switch c.ResolveHosts {
+	case "", ResolveOnController, ResolveOnHost:
+	default:
+		return fmt.Errorf("invalid resolve_hosts '%s' (must be controller or host)", c.ResolveHosts)
+	}
+	
+	if c.MaxConcurrentCommands < 0 {
+		return fmt.Errorf("max_concurrent_commands cannot be negative")
+	}
+	
+	if c.EnvRetries != nil && *c.EnvRetries < 0 {
+		return fmt.Errorf("env_retries cannot be negative")
+	}
+	
+	for _, key := range c.SysctlKeys {
+		if !envinfo.ValidSysctlKey(key) {
+			return fmt.Errorf("invalid sysctl_keys entry '%s' (must be a dotted sysctl name such as net.core.rmem_max)", key)
+		}
+	}
+	
+	if c.NodeStartupDelay != nil && *c.NodeStartupDelay < 0 {
+		return fmt.Errorf("node_startup_delay cannot be negative")
+	}
+	
+	if c.IntermediateCleanupTimeout < 0 {
+		return fmt.Errorf("intermediate_cleanup_timeout cannot be negative")
+	}
+	
+	for metric, percent := range c.Thresholds {
+		if percent < 0 {
+			return fmt.Errorf("threshold for %s cannot be negative", metric)
+		}
+	}
+	
 	// Validate hosts
 	for name, host := range c.Hosts {
 		if err := v.validateHost(name, host); err != nil {
@@ -43,6 +91,34 @@ func (v *Validator) ValidateConfig(c *TestConfig) error {
 		}
 	}
 	
+	// Validate host groups
+	for group, hosts := range c.HostGroups {
+		for _, host := range hosts {
+			if _, exists := c.Hosts[host]; !exists {
+				return fmt.Errorf("host group %s: host %s not found in hosts configuration", group, host)
+			}
+		}
+	}
+	
+	// Validate scenario groups
+	groupNames := make(map[string]bool)
+	for _, group := range c.Groups {
+		if group.Name == "" {
+			return fmt.Errorf("group name is required")
+		}
+		if groupNames[group.Name] {
+			return fmt.Errorf("duplicate group name: %s", group.Name)
+		}
+		groupNames[group.Name] = true
+		for _, commands := range []map[string][]string{group.Setup, group.Teardown} {
+			for host := range commands {
+				if _, exists := c.Hosts[host]; !exists {
+					return fmt.Errorf("group %s: host %s not found in hosts configuration", group.Name, host)
+				}
+			}
+		}
+	}
+	
 	// Validate test scenarios
 	for i, test := range c.Tests {
 		if err := v.validateTestScenario(c, i, &test); err != nil {
@@ -58,6 +134,24 @@ func (v *Validator) ValidateConfig(c *TestConfig) error {
 	return nil
 }
 
+// Warnings returns configuration choices that are valid but unsafe, such as
+// hosts that skip SSH host key verification
+func (v *Validator) Warnings(c *TestConfig) []string {
+	var names []string
+	for name, host := range c.Hosts {
+		if host != nil && host.SSH != nil && (host.SSH.InsecureHostKey || host.SSH.ProxyJump != nil && host.SSH.ProxyJump.InsecureHostKey) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	
+	var warnings []string
+	for _, name := range names {
+		warnings = append(warnings, fmt.Sprintf("host %s: insecure_host_key disables SSH host key verification; connections can be intercepted", name))
+	}
+	return warnings
+}
+
 // validateHost validates a single host configuration
 func (v *Validator) validateHost(name string, host *HostConfig) error {
 	if host == nil {
@@ -80,10 +174,40 @@ func (v *Validator) validateHost(name string, host *HostConfig) error {
 		return fmt.Errorf("host %s: either SSH key path or password is required", name)
 	}
 	
+	// The bastion authenticates on its own, so it needs a complete login too
+	if jump := host.SSH.ProxyJump; jump != nil {
+		if jump.Host == "" {
+			return fmt.Errorf("host %s: proxy_jump host is required", name)
+		}
+		if jump.User == "" {
+			return fmt.Errorf("host %s: proxy_jump user is required", name)
+		}
+		if jump.KeyPath == "" && jump.Password == "" {
+			return fmt.Errorf("host %s: proxy_jump requires either a key path or password", name)
+		}
+	}
+	
+	if host.SSH.MaxSessions < 0 {
+		return fmt.Errorf("host %s: max_sessions must not be negative", name)
+	}
+	if host.SSH.MaxSessions > 0 && host.SSH.MaxSessions < ssh.MinSessions {
+		return fmt.Errorf("host %s: max_sessions must be at least %d, since a command runs while a background role and the host's sampler hold sessions", name, ssh.MinSessions)
+	}
+	
 	if host.Role != "" && host.Role != "client" && host.Role != "server" && host.Role != "intermediate" {
 		return fmt.Errorf("host %s: invalid role %s, must be 'client', 'server', or 'intermediate'", name, host.Role)
 	}
 	
+	if host.Runner != nil {
+		if _, err := runner.ExpandTemplates(*host.Runner, runner.TemplateVars{}); err != nil {
+			return fmt.Errorf("host %s: %w", name, err)
+		}
+	}
+	
+	if host.Runner != nil && host.Runner.CPUAffinity != "" && !cpuListRegex.MatchString(host.Runner.CPUAffinity) {
+		return fmt.Errorf("host %s: invalid cpu_affinity '%s' (expected a CPU list such as 2-5,8)", name, host.Runner.CPUAffinity)
+	}
+	
 	return nil
 }
 
@@ -93,11 +217,22 @@ func (v *Validator) validateTestScenario(c *TestConfig, index int, test *TestSce
 		return fmt.Errorf("test %d: name is required", index)
 	}
 	
+	if len(test.Chain) > 0 {
+		if err := v.validateChain(c, test); err != nil {
+			return err
+		}
+	}
+	
 	if test.Client == "" {
 		return fmt.Errorf("test %s: client host is required", test.Name)
 	}
 	
-	if test.Server == "" {
+	if !c.RunsServer(test) {
+		// The runner explains why it cannot take the role
+		if err := v.validateSingleNode(c, test); err != nil {
+			return err
+		}
+	} else if test.Server == "" {
 		return fmt.Errorf("test %s: server host is required", test.Name)
 	}
 	
@@ -106,7 +241,7 @@ func (v *Validator) validateTestScenario(c *TestConfig, index int, test *TestSce
 		return fmt.Errorf("test %s: client host %s not found in hosts configuration", test.Name, test.Client)
 	}
 	
-	if _, exists := c.Hosts[test.Server]; !exists {
+	if _, exists := c.Hosts[test.Server]; test.Server != "" && !exists {
 		return fmt.Errorf("test %s: server host %s not found in hosts configuration", test.Name, test.Server)
 	}
 	
@@ -134,6 +269,251 @@ func (v *Validator) validateTestScenario(c *TestConfig, index int, test *TestSce
 		return fmt.Errorf("test %s: repeat count cannot be negative", test.Name)
 	}
 	
+	if test.Retries < 0 {
+		return fmt.Errorf("test %s: retries cannot be negative", test.Name)
+	}
+	
+	if test.Group != "" && c.GetGroup(test.Group) == nil {
+		return fmt.Errorf("test %s: group %s not found in groups configuration", test.Name, test.Group)
+	}
+	
+	for _, tag := range test.Tags {
+		if strings.TrimSpace(tag) == "" {
+			return fmt.Errorf("test %s: tags cannot be empty", test.Name)
+		}
+	}
+	
+	if test.Timeout < 0 {
+		return fmt.Errorf("test %s: timeout cannot be negative", test.Name)
+	}
+	
+	if test.ServerReadyTimeout < 0 || test.ServerReadyInterval < 0 {
+		return fmt.Errorf("test %s: server_ready_timeout and server_ready_interval cannot be negative", test.Name)
+	}
+	
+	if _, err := regexp.Compile(test.SuccessPattern); err != nil {
+		return fmt.Errorf("test %s: invalid success_pattern: %w", test.Name, err)
+	}
+	if _, err := regexp.Compile(test.FailurePattern); err != nil {
+		return fmt.Errorf("test %s: invalid failure_pattern: %w", test.Name, err)
+	}
+	
+	if test.DataPlaneSubnet != "" {
+		if _, _, err := net.ParseCIDR(test.DataPlaneSubnet); err != nil {
+			return fmt.Errorf("test %s: invalid data_plane_subnet: %w", test.Name, err)
+		}
+	}
+	
+	// Catch template mistakes such as {{.ServerIp}} before anything runs
+	if test.Config != nil {
+		if _, err := runner.ExpandTemplates(*test.Config, runner.TemplateVars{}); err != nil {
+			return fmt.Errorf("test %s: %w", test.Name, err)
+		}
+	}
+	
+	if test.Config != nil && test.Config.CPUAffinity != "" && !cpuListRegex.MatchString(test.Config.CPUAffinity) {
+		return fmt.Errorf("test %s: invalid cpu_affinity '%s' (expected a CPU list such as 2-5,8)", test.Name, test.Config.CPUAffinity)
+	}
+	
+	// Role commands hold their slots until the test ends, so a lower limit
+	// would leave the client waiting on a server that never exits. In-run
+	// probes bypass the limit, so no headroom is needed for them.
+	if c.MaxConcurrentCommands > 0 && c.MaxConcurrentCommands < test.ConcurrentRoles() {
+		return fmt.Errorf("test %s: runs %d role commands at once, more than max_concurrent_commands (%d)", test.Name, test.ConcurrentRoles(), c.MaxConcurrentCommands)
+	}
+	
+	for _, role := range test.EnvRoles {
+		switch role {
+		case "client", "server":
+		case "intermediate":
+			if test.Intermediate == "" {
+				return fmt.Errorf("test %s: env_roles lists intermediate but the test has no intermediate host", test.Name)
+			}
+		default:
+			return fmt.Errorf("test %s: invalid env_roles entry '%s' (must be client, server, or intermediate)", test.Name, role)
+		}
+	}
+	
+	if test.Autotune != nil {
+		if err := v.validateAutotune(test); err != nil {
+			return err
+		}
+	}
+	
+	if test.ClientStagger < 0 {
+		return fmt.Errorf("test %s: client_stagger cannot be negative", test.Name)
+	}
+	
+	if len(test.Clients) > 0 {
+		if err := v.validateFanOutClients(c, test); err != nil {
+			return err
+		}
+	}
+	
+	if len(test.FallbackHosts) > 0 {
+		if err := v.validateFallbackHosts(c, index, test); err != nil {
+			return err
+		}
+	}
+	
+	if err := v.validateQueueStats(test); err != nil {
+		return err
+	}
+	
+	for _, files := range []struct {
+		field     string
+		transfers []FileTransfer
+	}{{"pre_files", test.PreFiles}, {"post_files", test.PostFiles}} {
+		for _, transfer := range files.transfers {
+			if _, exists := c.Hosts[transfer.Host]; !exists {
+				return fmt.Errorf("test %s: %s host '%s' not found in hosts configuration", test.Name, files.field, transfer.Host)
+			}
+			if transfer.Local == "" || transfer.Remote == "" {
+				return fmt.Errorf("test %s: %s entries need both local and remote paths", test.Name, files.field)
+			}
+		}
+	}
+	
+	return nil
+}
+
+// validateSingleNode rejects the server and relay hosts of a scenario whose
+// runner runs on the client alone, with the runner's reason
+func (v *Validator) validateSingleNode(c *TestConfig, test *TestScenario) error {
+	r, err := runner.Create(c.GetRunner(test))
+	if err != nil {
+		return nil
+	}
+	roles := map[string]bool{"server": test.Server != "", "intermediate": len(test.Relays()) > 0}
+	for _, role := range []string{"server", "intermediate"} {
+		if !roles[role] {
+			continue
+		}
+		if err := r.Validate(runner.Config{Role: role}); err != nil {
+			return fmt.Errorf("test %s: %w", test.Name, err)
+		}
+		return fmt.Errorf("test %s: runner %s has no %s role", test.Name, r.Name(), role)
+	}
+	return nil
+}
+
+// validateQueueStats checks that queue_stats names hosts of the scenario and
+// interface names that are safe to pass to ethtool
+func (v *Validator) validateQueueStats(test *TestScenario) error {
+	participants := map[string]bool{test.Client: true, test.Server: true}
+	if test.Intermediate != "" {
+		participants[test.Intermediate] = true
+	}
+	for _, name := range test.Clients {
+		participants[name] = true
+	}
+	
+	for host, iface := range test.QueueStats {
+		if !participants[host] {
+			return fmt.Errorf("test %s: queue_stats host %s does not take part in the test", test.Name, host)
+		}
+		if !interfaceNameRegex.MatchString(iface) {
+			return fmt.Errorf("test %s: invalid queue_stats interface '%s' for host %s", test.Name, iface, host)
+		}
+	}
+	return nil
+}
+
+// validateChain checks that a chain names at least a client and a server,
+// each a distinct known host, and agrees with client, server, and intermediate
+func (v *Validator) validateChain(c *TestConfig, test *TestScenario) error {
+	chain := test.Chain
+	if len(chain) < 2 {
+		return fmt.Errorf("test %s: chain needs at least a client and a server host", test.Name)
+	}
+	
+	seen := make(map[string]bool)
+	for _, name := range chain {
+		if _, exists := c.Hosts[name]; !exists {
+			return fmt.Errorf("test %s: chain host %s not found in hosts configuration", test.Name, name)
+		}
+		if seen[name] {
+			return fmt.Errorf("test %s: chain host %s is listed more than once", test.Name, name)
+		}
+		seen[name] = true
+	}
+	
+	intermediate := ""
+	if len(chain) > 2 {
+		intermediate = chain[1]
+	}
+	if test.Client != chain[0] || test.Server != chain[len(chain)-1] || test.Intermediate != intermediate {
+		return fmt.Errorf("test %s: chain conflicts with client, server, or intermediate; use one or the other", test.Name)
+	}
+	return nil
+}
+
+// validateAutotune checks that an autotune sweep is well-formed
+func (v *Validator) validateAutotune(test *TestScenario) error {
+	autotune := test.Autotune
+	if autotune.Arg == "" {
+		return fmt.Errorf("test %s: autotune arg is required", test.Name)
+	}
+	if autotune.Start < 1 {
+		return fmt.Errorf("test %s: autotune start must be at least 1", test.Name)
+	}
+	if autotune.Max < autotune.Start {
+		return fmt.Errorf("test %s: autotune max must not be less than start", test.Name)
+	}
+	if autotune.Step < 0 || autotune.Tolerance < 0 {
+		return fmt.Errorf("test %s: autotune step and tolerance cannot be negative", test.Name)
+	}
+	return nil
+}
+
+// validateFallbackHosts checks that fallback hosts name known roles and that
+// the scenario remains valid once they are substituted
+func (v *Validator) validateFallbackHosts(c *TestConfig, index int, test *TestScenario) error {
+	for role := range test.FallbackHosts {
+		switch role {
+		case "client", "server":
+		case "intermediate":
+			if test.Intermediate == "" {
+				return fmt.Errorf("test %s: fallback_hosts has an intermediate entry but the test has no intermediate host", test.Name)
+			}
+		default:
+			return fmt.Errorf("test %s: invalid fallback_hosts role '%s' (must be client, server, or intermediate)", test.Name, role)
+		}
+	}
+	
+	if err := v.validateTestScenario(c, index, test.WithFallbackHosts()); err != nil {
+		return fmt.Errorf("fallback_hosts: %w", err)
+	}
+	
+	return nil
+}
+
+// validateFanOutClients checks the additional client hosts of a fan-out
+// scenario. The clients share one server, so its runner must serve several
+// clients at once.
+func (v *Validator) validateFanOutClients(c *TestConfig, test *TestScenario) error {
+	if test.Intermediate != "" {
+		return fmt.Errorf("test %s: clients cannot be combined with an intermediate host", test.Name)
+	}
+	
+	if r, err := runner.Create(c.GetRunner(test)); err == nil && !runner.ServesMultipleClients(r) {
+		return fmt.Errorf("test %s: the %s server serves one client at a time, so it cannot take fan-out clients", test.Name, r.Name())
+	}
+	
+	seen := map[string]bool{test.Client: true}
+	for _, name := range test.Clients {
+		if _, exists := c.Hosts[name]; !exists {
+			return fmt.Errorf("test %s: client host %s not found in hosts configuration", test.Name, name)
+		}
+		if name == test.Server {
+			return fmt.Errorf("test %s: client and server cannot be the same host", test.Name)
+		}
+		if seen[name] {
+			return fmt.Errorf("test %s: client host %s is listed more than once", test.Name, name)
+		}
+		seen[name] = true
+	}
+	
 	return nil
 }
 
@@ -1,8 +1,11 @@
 package config
 
 import (
+	"strings"
 	"testing"
+	"time"
 
+	"perf-runner/runner"
 	"perf-runner/ssh"
 )
 
@@ -129,4 +132,480 @@ func TestNewValidator(t *testing.T) {
 	if validator == nil {
 		t.Error("NewValidator should not return nil")
 	}
-}
\ No newline at end of file
+}
+
+func TestValidator_FallbackHosts(t *testing.T) {
+	validator := NewValidator()
+
+	newConfig := func(fallback map[string]string) *TestConfig {
+		hosts := make(map[string]*HostConfig)
+		for _, name := range []string{"client1", "server1", "server2"} {
+			hosts[name] = &HostConfig{SSH: &ssh.Config{Host: name, User: "testuser", KeyPath: "~/.ssh/id_rsa"}}
+		}
+		return &TestConfig{
+			Name:   "Fallback Config",
+			Runner: "iperf3",
+			Hosts:  hosts,
+			Tests: []TestScenario{
+				{Name: "Test 1", Client: "client1", Server: "server1", FallbackHosts: fallback},
+			},
+		}
+	}
+
+	tests := []struct {
+		name     string
+		fallback map[string]string
+		wantErr  bool
+	}{
+		{"valid server fallback", map[string]string{"server": "server2"}, false},
+		{"unknown host", map[string]string{"server": "server9"}, true},
+		{"unknown role", map[string]string{"switch": "server2"}, true},
+		{"intermediate without intermediate host", map[string]string{"intermediate": "server2"}, true},
+		{"fallback collides with client", map[string]string{"server": "client1"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validator.ValidateConfig(newConfig(tt.fallback))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidator_OutputPatterns(t *testing.T) {
+	validator := NewValidator()
+
+	cfg := &TestConfig{
+		Name:   "Pattern Config",
+		Runner: "iperf3",
+		Hosts: map[string]*HostConfig{
+			"client1": {SSH: &ssh.Config{Host: "192.168.1.101", User: "testuser", KeyPath: "~/.ssh/id_rsa"}},
+			"server1": {SSH: &ssh.Config{Host: "192.168.1.100", User: "testuser", KeyPath: "~/.ssh/id_rsa"}},
+		},
+		Tests: []TestScenario{
+			{Name: "Test 1", Client: "client1", Server: "server1", FailurePattern: `(?i)error`},
+		},
+	}
+	if err := validator.ValidateConfig(cfg); err != nil {
+		t.Fatalf("Expected valid failure_pattern, got %v", err)
+	}
+
+	cfg.Tests[0].SuccessPattern = `BW[`
+	if err := validator.ValidateConfig(cfg); err == nil {
+		t.Error("Expected error for invalid success_pattern")
+	}
+}
+
+func TestValidator_ResolveHosts(t *testing.T) {
+	validator := NewValidator()
+
+	for _, tt := range []struct {
+		mode    string
+		wantErr bool
+	}{
+		{"", false},
+		{ResolveOnController, false},
+		{ResolveOnHost, false},
+		{"dns", true},
+	} {
+		cfg := &TestConfig{
+			Name:         "Resolve Config",
+			Runner:       "iperf3",
+			ResolveHosts: tt.mode,
+			Hosts: map[string]*HostConfig{
+				"client1": {SSH: &ssh.Config{Host: "client1.lab", User: "testuser", KeyPath: "~/.ssh/id_rsa"}},
+				"server1": {SSH: &ssh.Config{Host: "server1.lab", User: "testuser", KeyPath: "~/.ssh/id_rsa"}},
+			},
+			Tests: []TestScenario{{Name: "Test 1", Client: "client1", Server: "server1"}},
+		}
+		if err := validator.ValidateConfig(cfg); (err != nil) != tt.wantErr {
+			t.Errorf("resolve_hosts %q: error = %v, wantErr %v", tt.mode, err, tt.wantErr)
+		}
+	}
+}
+
+func TestValidator_FanOutClients(t *testing.T) {
+	validator := NewValidator()
+
+	hosts := make(map[string]*HostConfig)
+	for _, name := range []string{"client1", "client2", "server1", "router1"} {
+		hosts[name] = &HostConfig{SSH: &ssh.Config{Host: name, User: "testuser", KeyPath: "~/.ssh/id_rsa"}}
+	}
+
+	tests := []struct {
+		name     string
+		scenario TestScenario
+		wantErr  bool
+	}{
+		{"valid fan-out", TestScenario{Clients: []string{"client2"}, ClientStagger: 10 * time.Millisecond}, false},
+		{"unknown client", TestScenario{Clients: []string{"client9"}}, true},
+		{"client is server", TestScenario{Clients: []string{"server1"}}, true},
+		{"duplicate client", TestScenario{Clients: []string{"client1"}}, true},
+		{"with intermediate", TestScenario{Clients: []string{"client2"}, Intermediate: "router1"}, true},
+		{"negative stagger", TestScenario{ClientStagger: -time.Millisecond}, true},
+		{"single-client server", TestScenario{Clients: []string{"client2"}, Runner: "iperf3"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scenario := tt.scenario
+			scenario.Name, scenario.Client, scenario.Server = "Test 1", "client1", "server1"
+			cfg := &TestConfig{Name: "Fan-out Config", Runner: "ethr", Hosts: hosts, Tests: []TestScenario{scenario}}
+			if err := validator.ValidateConfig(cfg); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidator_Autotune(t *testing.T) {
+	validator := NewValidator()
+	tests := []struct {
+		name     string
+		autotune AutotuneConfig
+		wantErr  bool
+	}{
+		{"valid", AutotuneConfig{Arg: "parallel", Start: 1, Max: 16}, false},
+		{"missing arg", AutotuneConfig{Start: 1, Max: 16}, true},
+		{"zero start", AutotuneConfig{Arg: "parallel", Max: 16}, true},
+		{"max below start", AutotuneConfig{Arg: "parallel", Start: 8, Max: 4}, true},
+		{"negative tolerance", AutotuneConfig{Arg: "parallel", Start: 1, Max: 4, Tolerance: -0.1}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			autotune := tt.autotune
+			err := validator.validateAutotune(&TestScenario{Name: "sweep", Autotune: &autotune})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateAutotune() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidator_MaxConcurrentCommands(t *testing.T) {
+	newConfig := func(limit int) *TestConfig {
+		host := func(addr string) *HostConfig {
+			return &HostConfig{SSH: &ssh.Config{Host: addr, User: "u", KeyPath: "k"}}
+		}
+		return &TestConfig{
+			Name:                  "limit",
+			Runner:                "ethr",
+			MaxConcurrentCommands: limit,
+			Hosts:                 map[string]*HostConfig{"c1": host("1"), "c2": host("2"), "s": host("3")},
+			Tests:                 []TestScenario{{Name: "incast", Client: "c1", Clients: []string{"c2"}, Server: "s"}},
+		}
+	}
+
+	validator := NewValidator()
+	for _, tt := range []struct {
+		limit   int
+		wantErr bool
+	}{{0, false}, {3, false}, {2, true}, {-1, true}} {
+		err := validator.ValidateConfig(newConfig(tt.limit))
+		if (err != nil) != tt.wantErr {
+			t.Errorf("max_concurrent_commands=%d: error = %v, wantErr %v", tt.limit, err, tt.wantErr)
+		}
+	}
+}
+
+func TestValidator_Thresholds(t *testing.T) {
+	cfg := &TestConfig{
+		Name:       "thresholds",
+		Runner:     "iperf3",
+		Hosts:      map[string]*HostConfig{"c": {SSH: &ssh.Config{Host: "1", User: "u", KeyPath: "k"}}, "s": {SSH: &ssh.Config{Host: "2", User: "u", KeyPath: "k"}}},
+		Tests:      []TestScenario{{Name: "tcp", Client: "c", Server: "s"}},
+		Thresholds: map[string]float64{"bandwidth_mbps": 5, "latency_avg_usec": 0},
+	}
+
+	validator := NewValidator()
+	if err := validator.ValidateConfig(cfg); err != nil {
+		t.Errorf("Expected valid thresholds, got %v", err)
+	}
+	cfg.Thresholds["bandwidth_mbps"] = -1
+	if err := validator.ValidateConfig(cfg); err == nil || !strings.Contains(err.Error(), "bandwidth_mbps") {
+		t.Errorf("Expected an error naming the negative threshold, got %v", err)
+	}
+}
+
+func TestValidator_SingleNodeRunner(t *testing.T) {
+	host := func(addr string) *HostConfig {
+		return &HostConfig{SSH: &ssh.Config{Host: addr, User: "u", KeyPath: "k"}}
+	}
+	cfg := &TestConfig{
+		Name:   "storage",
+		Runner: "fio",
+		Hosts:  map[string]*HostConfig{"c": host("1"), "s": host("2")},
+		Tests:  []TestScenario{{Name: "randread", Client: "c"}},
+	}
+
+	validator := NewValidator()
+	if err := validator.ValidateConfig(cfg); err != nil {
+		t.Errorf("Expected a fio scenario without a server to be valid, got %v", err)
+	}
+	if got := cfg.Tests[0].ConcurrentRoles(); got != 1 {
+		t.Errorf("Expected 1 concurrent role without a server, got %d", got)
+	}
+
+	cfg.Tests[0].Server = "s"
+	if err := validator.ValidateConfig(cfg); err == nil || !strings.Contains(err.Error(), "client host only") {
+		t.Errorf("Expected fio to reject the server, got %v", err)
+	}
+}
+
+func TestValidator_EnvRoles(t *testing.T) {
+	host := func(addr string) *HostConfig {
+		return &HostConfig{SSH: &ssh.Config{Host: addr, User: "u", KeyPath: "k"}}
+	}
+	validator := NewValidator()
+	for _, tt := range []struct {
+		roles   []string
+		wantErr bool
+	}{{nil, false}, {[]string{"server"}, false}, {[]string{"client", "server"}, false}, {[]string{"intermediate"}, true}, {[]string{"router"}, true}} {
+		config := &TestConfig{
+			Name:   "env roles",
+			Runner: "iperf3",
+			Hosts:  map[string]*HostConfig{"c": host("1"), "s": host("2")},
+			Tests:  []TestScenario{{Name: "env", Client: "c", Server: "s", EnvRoles: tt.roles}},
+		}
+		err := validator.ValidateConfig(config)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("env_roles=%v: error = %v, wantErr %v", tt.roles, err, tt.wantErr)
+		}
+	}
+}
+
+func TestValidator_Groups(t *testing.T) {
+	host := func(addr string) *HostConfig {
+		return &HostConfig{SSH: &ssh.Config{Host: addr, User: "u", KeyPath: "k"}}
+	}
+	validator := NewValidator()
+	for _, tt := range []struct {
+		name    string
+		groups  []ScenarioGroup
+		group   string
+		wantErr bool
+	}{
+		{"valid", []ScenarioGroup{{Name: "g", Setup: map[string][]string{"s": {"true"}}}}, "g", false},
+		{"unknown group", []ScenarioGroup{{Name: "g"}}, "h", true},
+		{"unknown host", []ScenarioGroup{{Name: "g", Teardown: map[string][]string{"x": {"true"}}}}, "g", true},
+		{"duplicate name", []ScenarioGroup{{Name: "g"}, {Name: "g"}}, "g", true},
+	} {
+		config := &TestConfig{
+			Name:   "groups",
+			Runner: "iperf3",
+			Hosts:  map[string]*HostConfig{"c": host("1"), "s": host("2")},
+			Groups: tt.groups,
+			Tests:  []TestScenario{{Name: "grouped", Client: "c", Server: "s", Group: tt.group}},
+		}
+		err := validator.ValidateConfig(config)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestValidator_ProxyJump(t *testing.T) {
+	validator := NewValidator()
+	for _, tt := range []struct {
+		name    string
+		jump    *ssh.Config
+		wantErr bool
+	}{
+		{"none", nil, false},
+		{"complete", &ssh.Config{Host: "bastion", User: "jump", KeyPath: "k"}, false},
+		{"missing host", &ssh.Config{User: "jump", KeyPath: "k"}, true},
+		{"missing user", &ssh.Config{Host: "bastion", Password: "p"}, true},
+		{"missing auth", &ssh.Config{Host: "bastion", User: "jump"}, true},
+	} {
+		client := &HostConfig{SSH: &ssh.Config{Host: "1", User: "u", KeyPath: "k", ProxyJump: tt.jump}}
+		config := &TestConfig{
+			Name:   "jump",
+			Runner: "iperf3",
+			Hosts:  map[string]*HostConfig{"c": client, "s": {SSH: &ssh.Config{Host: "2", User: "u", KeyPath: "k"}}},
+			Tests:  []TestScenario{{Name: "tcp", Client: "c", Server: "s"}},
+		}
+		err := validator.ValidateConfig(config)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestValidator_MaxSessions(t *testing.T) {
+	validator := NewValidator()
+	for _, tt := range []struct {
+		maxSessions int
+		wantErr     bool
+	}{
+		{0, false},
+		{4, false},
+		{3, false},
+		{2, true},
+		{-1, true},
+	} {
+		config := &TestConfig{
+			Name:   "sessions",
+			Runner: "iperf3",
+			Hosts: map[string]*HostConfig{
+				"c": {SSH: &ssh.Config{Host: "1", User: "u", KeyPath: "k", MaxSessions: tt.maxSessions}},
+				"s": {SSH: &ssh.Config{Host: "2", User: "u", KeyPath: "k"}},
+			},
+			Tests: []TestScenario{{Name: "tcp", Client: "c", Server: "s"}},
+		}
+		err := validator.ValidateConfig(config)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("max_sessions %d: error = %v, wantErr %v", tt.maxSessions, err, tt.wantErr)
+		}
+	}
+}
+
+func TestValidator_WarnsOnInsecureHostKey(t *testing.T) {
+	config := &TestConfig{
+		Hosts: map[string]*HostConfig{
+			"b": {SSH: &ssh.Config{Host: "2", InsecureHostKey: true}},
+			"a": {SSH: &ssh.Config{Host: "1", InsecureHostKey: true}},
+			"c": {SSH: &ssh.Config{Host: "3"}},
+		},
+	}
+	warnings := NewValidator().Warnings(config)
+	if len(warnings) != 2 || !strings.HasPrefix(warnings[0], "host a:") || !strings.HasPrefix(warnings[1], "host b:") {
+		t.Errorf("expected sorted warnings for hosts a and b, got %v", warnings)
+	}
+}
+
+func TestValidator_QueueStats(t *testing.T) {
+	host := func(addr string) *HostConfig {
+		return &HostConfig{SSH: &ssh.Config{Host: addr, User: "u", KeyPath: "k"}}
+	}
+	validator := NewValidator()
+	for _, tt := range []struct {
+		name    string
+		queues  map[string]string
+		wantErr bool
+	}{
+		{"valid", map[string]string{"s": "ens1f0"}, false},
+		{"host not in test", map[string]string{"other": "eth0"}, true},
+		{"unsafe interface", map[string]string{"s": "eth0; reboot"}, true},
+	} {
+		config := &TestConfig{
+			Name:   "queues",
+			Runner: "iperf3",
+			Hosts:  map[string]*HostConfig{"c": host("1"), "s": host("2"), "other": host("3")},
+			Tests:  []TestScenario{{Name: "rss", Client: "c", Server: "s", QueueStats: tt.queues}},
+		}
+		err := validator.ValidateConfig(config)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestValidator_Files(t *testing.T) {
+	host := func(addr string) *HostConfig {
+		return &HostConfig{SSH: &ssh.Config{Host: addr, User: "u", KeyPath: "k"}}
+	}
+	validator := NewValidator()
+	for _, tt := range []struct {
+		name    string
+		test    TestScenario
+		wantErr bool
+	}{
+		{"valid", TestScenario{PreFiles: []FileTransfer{{Host: "s", Local: "tune.sh", Remote: "/tmp/tune.sh"}}}, false},
+		{"unknown host", TestScenario{PostFiles: []FileTransfer{{Host: "x", Local: "out.pcap", Remote: "/tmp/out.pcap"}}}, true},
+		{"missing remote", TestScenario{PostFiles: []FileTransfer{{Host: "c", Local: "out.pcap"}}}, true},
+	} {
+		tt.test.Name, tt.test.Client, tt.test.Server = "files", "c", "s"
+		config := &TestConfig{
+			Name:   "files",
+			Runner: "iperf3",
+			Hosts:  map[string]*HostConfig{"c": host("1"), "s": host("2")},
+			Tests:  []TestScenario{tt.test},
+		}
+		err := validator.ValidateConfig(config)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestValidator_Chain(t *testing.T) {
+	host := func(addr string) *HostConfig {
+		return &HostConfig{SSH: &ssh.Config{Host: addr, User: "u", KeyPath: "k"}}
+	}
+	validator := NewValidator()
+	for _, tt := range []struct {
+		name    string
+		test    TestScenario
+		wantErr bool
+	}{
+		{"four hosts", TestScenario{Chain: []string{"a", "b", "c", "d"}}, false},
+		{"two hosts", TestScenario{Chain: []string{"a", "d"}}, false},
+		{"single host", TestScenario{Chain: []string{"a"}, Client: "a", Server: "d"}, true},
+		{"unknown host", TestScenario{Chain: []string{"a", "x", "d"}}, true},
+		{"repeated host", TestScenario{Chain: []string{"a", "b", "b", "d"}}, true},
+		{"conflicting server", TestScenario{Chain: []string{"a", "b", "c"}, Server: "d"}, true},
+	} {
+		test := tt.test
+		test.Name = tt.name
+		test.expandChain()
+		config := &TestConfig{
+			Name:   "chains",
+			Runner: "iperf3",
+			Hosts:  map[string]*HostConfig{"a": host("1"), "b": host("2"), "c": host("3"), "d": host("4")},
+			Tests:  []TestScenario{test},
+		}
+		err := validator.ValidateConfig(config)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestValidator_RejectsBadArgTemplate(t *testing.T) {
+	config := &TestConfig{
+		Name:   "templates",
+		Runner: "iperf3",
+		Hosts: map[string]*HostConfig{
+			"client": {SSH: &ssh.Config{Host: "1", User: "u", KeyPath: "k"}},
+			"server": {SSH: &ssh.Config{Host: "2", User: "u", KeyPath: "k"}},
+		},
+		Tests: []TestScenario{{
+			Name:   "typo",
+			Client: "client",
+			Server: "server",
+			Config: &runner.Config{Args: map[string]interface{}{"title": "{{.ServerIp}}"}},
+		}},
+	}
+	err := NewValidator().ValidateConfig(config)
+	if err == nil || !strings.Contains(err.Error(), "test typo") {
+		t.Errorf("Expected an error for the unknown template variable, got %v", err)
+	}
+}
+
+func TestValidator_SysctlKeys(t *testing.T) {
+	validator := NewValidator()
+	for _, tt := range []struct {
+		keys    []string
+		wantErr bool
+	}{
+		{nil, false},
+		{[]string{"vm.swappiness", "net.ipv4.conf.eth0.rp_filter"}, false},
+		{[]string{"net.core.rmem_max; reboot"}, true},
+		{[]string{""}, true},
+	} {
+		config := &TestConfig{
+			Name:       "sysctl",
+			Runner:     "iperf3",
+			SysctlKeys: tt.keys,
+			Hosts:      map[string]*HostConfig{"c": {SSH: &ssh.Config{Host: "1", User: "u", KeyPath: "k"}}, "s": {SSH: &ssh.Config{Host: "2", User: "u", KeyPath: "k"}}},
+			Tests:      []TestScenario{{Name: "tcp", Client: "c", Server: "s"}},
+		}
+		err := validator.ValidateConfig(config)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("sysctl_keys=%q: error = %v, wantErr %v", tt.keys, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package coordinator
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"perf-runner/runner"
+)
+
+// pidFileSeq distinguishes PID files of commands started in the same instant
+var pidFileSeq atomic.Int64
+
+// remotePIDFile returns a unique path on the remote host for a command's PID
+func remotePIDFile(role string) string {
+	return fmt.Sprintf("/tmp/perf-runner-%d-%d-%s.pid", time.Now().UnixNano(), pidFileSeq.Add(1), role)
+}
+
+// wrapWithAffinity pins command to cpus and records its PID in pidFile. The
+// login shell writes its own PID and then execs taskset, so the PID stays
+// the root of the benchmark's process tree.
+func wrapWithAffinity(command, cpus, pidFile string) string {
+	return fmt.Sprintf("echo $$ > %s; exec taskset -c %s sh -c %s", pidFile, cpus, shellQuote(command))
+}
+
+// affinityProbeCommand prints the Cpus_allowed_list of the newest leaf of the
+// process tree rooted at the PID in pidFile while that process runs, and
+// removes the file once it has exited. It prints nothing before the command
+// has started.
+func affinityProbeCommand(pidFile string) string {
+	return fmt.Sprintf(`if p=$(cat %[1]s 2>/dev/null) && [ -n "$p" ]; then `+
+		`if kill -0 "$p" 2>/dev/null; then while c=$(pgrep -n -P "$p"); do p=$c; done; grep Cpus_allowed_list /proc/$p/status; `+
+		`else rm -f %[1]s; fi; fi`, pidFile)
+}
+
+// parseCpusAllowedList extracts the CPU list from /proc/<pid>/status output
+func parseCpusAllowedList(output string) (string, bool) {
+	for _, line := range strings.Split(output, "\n") {
+		key, value, found := strings.Cut(line, ":")
+		if found && strings.TrimSpace(key) == "Cpus_allowed_list" {
+			if cpus := strings.TrimSpace(value); cpus != "" {
+				return cpus, true
+			}
+		}
+	}
+	return "", false
+}
+
+// affinityCollector keeps the CPU placement last read back for a pinned
+// role command
+type affinityCollector struct {
+	config  *runner.Config
+	host    string
+	pidFile string
+	cpus    string
+}
+
+// Command returns the placement read-back command
+func (c *affinityCollector) Command() string { return affinityProbeCommand(c.pidFile) }
+
+// Collect keeps the placement if the sample read one
+func (c *affinityCollector) Collect(at time.Time, output string) {
+	if cpus, ok := parseCpusAllowedList(output); ok {
+		c.cpus = cpus
+	}
+}
+
+// affinityMonitor reads back the CPU placement of every pinned role command
+// throughout the run. The last placement read wins, since a command only
+// settles on its CPUs once it has started its worker threads.
+type affinityMonitor struct {
+	collectors []*affinityCollector
+}
+
+// monitorAffinity assigns a PID file to each pinned role and registers a
+// collector reading its placement with the sampler of the role's host
+func monitorAffinity(samplers *hostSamplers, hosts []testHost) *affinityMonitor {
+	monitor := &affinityMonitor{}
+	for _, host := range hosts {
+		if host.config == nil || host.config.CPUAffinity == "" {
+			continue
+		}
+		collector := &affinityCollector{config: host.config, host: host.name, pidFile: remotePIDFile(host.config.Role)}
+		samplers.register(host, collector)
+		monitor.collectors = append(monitor.collectors, collector)
+	}
+	return monitor
+}
+
+// pidFile returns the PID file the monitor probes for a role command, or a
+// fresh one for a command it does not probe
+func (m *affinityMonitor) pidFile(config *runner.Config) string {
+	if m != nil {
+		for _, collector := range m.collectors {
+			if collector.config == config {
+				return collector.pidFile
+			}
+		}
+	}
+	return remotePIDFile(config.Role)
+}
+
+// record attaches actual_cpu_affinity to the metrics of each pinned role,
+// once sampling has stopped
+func (m *affinityMonitor) record(e *TestExecutor, result *TestResult) {
+	hostResults := roleResultsByHost(result)
+	for _, collector := range m.collectors {
+		roleResult := hostResults[collector.host]
+		if roleResult == nil {
+			continue
+		}
+		if collector.cpus == "" {
+			e.coordinator.logger.Printf("  Warning: could not read the CPU affinity of the %s command on %s", collector.config.Role, collector.host)
+			continue
+		}
+		if roleResult.Metrics == nil {
+			roleResult.Metrics = make(map[string]interface{})
+		}
+		roleResult.Metrics["actual_cpu_affinity"] = collector.cpus
+	}
+}
+
+// shellQuote quotes s as a single POSIX shell word
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'"'"'`) + "'"
+}
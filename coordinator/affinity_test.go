@@ -0,0 +1,87 @@
+package coordinator
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"perf-runner/config"
+	"perf-runner/runner"
+	"perf-runner/ssh"
+)
+
+const pinnedStatus = `Name:	iperf3
+State:	S (sleeping)
+Pid:	4242
+Cpus_allowed:	0c
+Cpus_allowed_list:	2-3
+Mems_allowed_list:	0
+`
+
+func TestParseCpusAllowedList(t *testing.T) {
+	if cpus, ok := parseCpusAllowedList(pinnedStatus); !ok || cpus != "2-3" {
+		t.Errorf("parseCpusAllowedList() = %q, %v; want 2-3, true", cpus, ok)
+	}
+	if _, ok := parseCpusAllowedList("grep: /proc/4242/status: No such file or directory"); ok {
+		t.Error("Expected no affinity from an error message")
+	}
+}
+
+func TestWrapWithAffinity(t *testing.T) {
+	got := wrapWithAffinity("A='x y' fake-client", "2-3", "/tmp/p.pid")
+	want := `echo $$ > /tmp/p.pid; exec taskset -c 2-3 sh -c 'A='"'"'x y'"'"' fake-client'`
+	if got != want {
+		t.Errorf("wrapWithAffinity() = %s, want %s", got, want)
+	}
+}
+
+func TestExecuteTest_RecordsActualCPUAffinity(t *testing.T) {
+	test := config.TestScenario{
+		Name:   "pinned",
+		Client: "client",
+		Server: "server",
+		Config: &runner.Config{CPUAffinity: "2-3"},
+	}
+
+	// The pinned client keeps running until its placement has been read
+	var once sync.Once
+	probed := make(chan struct{})
+	readBack := func() string {
+		once.Do(func() { close(probed) })
+		return pinnedStatus
+	}
+	client := &fakeHostClient{handler: samplingHandler(map[string]func() string{"Cpus_allowed_list": readBack}, func(ctx context.Context, command string) (*ssh.Result, error) {
+		select {
+		case <-probed:
+		case <-ctx.Done():
+		}
+		return &ssh.Result{Output: "done"}, nil
+	})}
+	coord := newTestCoordinator([]config.TestScenario{test}, map[string]*fakeHostClient{
+		"client": client,
+		"server": {handler: samplingHandler(map[string]func() string{"Cpus_allowed_list": inTurn(pinnedStatus)}, runForever(true))},
+	})
+	executor := newTestExecutor(coord)
+
+	result, err := executor.ExecuteTest(context.Background(), &test)
+	if err != nil {
+		t.Fatalf("ExecuteTest returned error: %v", err)
+	}
+
+	for role, roleResult := range map[string]*runner.Result{"client": result.ClientResult, "server": result.ServerResult} {
+		if got := roleResult.Metrics["actual_cpu_affinity"]; got != "2-3" {
+			t.Errorf("%s: expected actual_cpu_affinity 2-3, got %v", role, got)
+		}
+	}
+	if result.ClientCommand != "fake-client server" {
+		t.Errorf("Expected the recorded command to stay unwrapped, got %q", result.ClientCommand)
+	}
+	pinned := false
+	for _, command := range client.commands {
+		pinned = pinned || strings.Contains(command, "exec taskset -c 2-3 sh -c 'fake-client server'")
+	}
+	if !pinned {
+		t.Errorf("Expected the client command to be pinned with taskset, got %v", client.commands)
+	}
+}
@@ -0,0 +1,92 @@
+package coordinator
+
+import (
+	"strings"
+
+	"perf-runner/runner"
+)
+
+// maskedValue replaces the value of secret-looking environment variables
+const maskedValue = "****"
+
+// secretEnvKeys are substrings marking an environment variable as secret
+var secretEnvKeys = []string{"PASSWORD", "TOKEN"}
+
+// AuditRecord records exactly what one role ran and where, for auditing
+type AuditRecord struct {
+	Role    string            `json:"role"`
+	Host    string            `json:"host"`
+	Address string            `json:"address,omitempty"` // SSH address of the host
+	User    string            `json:"user,omitempty"`    // SSH user the command ran as
+	Command string            `json:"command"`
+	Env     map[string]string `json:"env,omitempty"`     // Effective environment, secrets masked
+	Version string            `json:"version,omitempty"` // Runner tool version on the host
+}
+
+// isSecretEnvKey reports whether an environment variable name looks like it
+// holds a secret, e.g. DB_PASSWORD or api_token
+func isSecretEnvKey(key string) bool {
+	upper := strings.ToUpper(key)
+	for _, marker := range secretEnvKeys {
+		if strings.Contains(upper, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// maskSecrets returns a copy of env with secret-looking values masked
+func maskSecrets(env map[string]string) map[string]string {
+	if env == nil {
+		return nil
+	}
+	masked := make(map[string]string, len(env))
+	for key, value := range env {
+		if isSecretEnvKey(key) {
+			value = maskedValue
+		}
+		masked[key] = value
+	}
+	return masked
+}
+
+// maskConfigSecrets returns a copy of config with secret-looking
+// environment values masked
+func maskConfigSecrets(config runner.Config) runner.Config {
+	config.Env = maskSecrets(config.Env)
+	config.ServerEnv = maskSecrets(config.ServerEnv)
+	config.ClientEnv = maskSecrets(config.ClientEnv)
+	return config
+}
+
+// displayCommand builds the command shown in logs and results, from the
+// masked environment so secrets in the env prefix stay hidden
+func displayCommand(r runner.Runner, config runner.Config) string {
+	return r.BuildCommand(maskConfigSecrets(config))
+}
+
+// auditRecords describes the invocation of every role. Commands are built
+// from the masked environment, so secrets in the env prefix stay hidden.
+func (e *TestExecutor) auditRecords(r runner.Runner, hosts []testHost, versions map[string]string) []AuditRecord {
+	records := make([]AuditRecord, 0, len(hosts))
+	for _, host := range hosts {
+		masked := maskConfigSecrets(*host.config)
+
+		record := AuditRecord{
+			Role:    host.role,
+			Host:    host.name,
+			Command: r.BuildCommand(masked),
+			Env:     masked.GetEffectiveEnv(),
+			Version: versions[host.name],
+		}
+		if hostConfig := e.coordinator.config.Hosts[host.name]; hostConfig != nil && hostConfig.SSH != nil {
+			record.Address = hostConfig.SSH.Host
+			record.User = hostConfig.SSH.User
+		}
+		if len(record.Env) == 0 {
+			record.Env = nil
+		}
+		records = append(records, record)
+	}
+	return records
+}
@@ -0,0 +1,131 @@
+package coordinator
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"testing"
+
+	"perf-runner/config"
+	"perf-runner/runner"
+)
+
+// envRunner prefixes its command with the effective environment, as the
+// real runners do
+type envRunner struct {
+	versionedRunner
+}
+
+func (r *envRunner) BuildCommand(config runner.Config) string {
+	env := config.GetEffectiveEnv()
+	keys := make([]string, 0, len(env))
+	for key := range env {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var prefix string
+	for _, key := range keys {
+		prefix += key + "=" + env[key] + " "
+	}
+	return prefix + r.versionedRunner.BuildCommand(config)
+}
+
+func TestMaskSecrets(t *testing.T) {
+	masked := maskSecrets(map[string]string{
+		"DB_PASSWORD": "hunter2",
+		"api_token":   "abc",
+		"MODE":        "fast",
+	})
+	if masked["DB_PASSWORD"] != maskedValue || masked["api_token"] != maskedValue {
+		t.Errorf("Expected secret values masked, got %v", masked)
+	}
+	if masked["MODE"] != "fast" {
+		t.Errorf("Expected other values kept, got %v", masked)
+	}
+	if maskSecrets(nil) != nil {
+		t.Error("Expected nil for a nil env")
+	}
+}
+
+func TestExecuteTest_RecordsAudit(t *testing.T) {
+	test := config.TestScenario{
+		Name:   "audit",
+		Client: "client",
+		Server: "server",
+		Config: &runner.Config{Env: map[string]string{"ACCESS_TOKEN": "s3cret", "MODE": "fast"}},
+	}
+	coord := newTestCoordinator([]config.TestScenario{test}, map[string]*fakeHostClient{
+		"client": {handler: versionHandler("iperf 3.16", succeed("done"))},
+		"server": {handler: versionHandler("iperf 3.16", runForever(true))},
+	})
+	coord.RegisterRunner("fake", &envRunner{})
+
+	result, err := newTestExecutor(coord).ExecuteTest(context.Background(), &test)
+	if err != nil {
+		t.Fatalf("ExecuteTest returned error: %v", err)
+	}
+	if len(result.Audit) != 2 {
+		t.Fatalf("Expected an audit record per role, got %+v", result.Audit)
+	}
+
+	var client *AuditRecord
+	for i := range result.Audit {
+		if result.Audit[i].Role == "client" {
+			client = &result.Audit[i]
+		}
+	}
+	if client == nil {
+		t.Fatalf("Expected a client audit record, got %+v", result.Audit)
+	}
+	if client.Host != "client" || client.Address != "client" || client.User != "test" || client.Version != "3.16" {
+		t.Errorf("Unexpected client audit record %+v", client)
+	}
+	if client.Command != "ACCESS_TOKEN=**** MODE=fast fake-client server" {
+		t.Errorf("Expected the masked client command, got %q", client.Command)
+	}
+	if client.Env["ACCESS_TOKEN"] != maskedValue || client.Env["MODE"] != "fast" {
+		t.Errorf("Expected the masked env, got %v", client.Env)
+	}
+	for _, record := range result.Audit {
+		if strings.Contains(record.Command, "s3cret") {
+			t.Errorf("Secret leaked into the %s audit command %q", record.Role, record.Command)
+		}
+	}
+	for _, command := range []string{result.ClientCommand, result.ServerCommand} {
+		if strings.Contains(command, "s3cret") || !strings.Contains(command, "ACCESS_TOKEN=****") {
+			t.Errorf("Expected the result command masked, got %q", command)
+		}
+	}
+	for _, node := range result.NodeResults {
+		if strings.Contains(node.Command, "s3cret") {
+			t.Errorf("Secret leaked into the %s node command %q", node.Role, node.Command)
+		}
+	}
+}
+
+func TestExecuteTest_RunsUnmaskedCommand(t *testing.T) {
+	test := config.TestScenario{
+		Name:   "audit",
+		Client: "client",
+		Server: "server",
+		Config: &runner.Config{Env: map[string]string{"ACCESS_TOKEN": "s3cret"}},
+	}
+	client := &fakeHostClient{handler: versionHandler("iperf 3.16", succeed("done"))}
+	coord := newTestCoordinator([]config.TestScenario{test}, map[string]*fakeHostClient{
+		"client": client,
+		"server": {handler: versionHandler("iperf 3.16", runForever(true))},
+	})
+	coord.RegisterRunner("fake", &envRunner{})
+
+	if _, err := newTestExecutor(coord).ExecuteTest(context.Background(), &test); err != nil {
+		t.Fatalf("ExecuteTest returned error: %v", err)
+	}
+	ran := false
+	for _, command := range client.commands {
+		ran = ran || strings.Contains(command, "ACCESS_TOKEN=s3cret fake-client")
+	}
+	if !ran {
+		t.Errorf("Expected the client to run with the real secret, got %v", client.commands)
+	}
+}
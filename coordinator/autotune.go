@@ -0,0 +1,69 @@
+package coordinator
+
+import (
+	"context"
+	"fmt"
+
+	"perf-runner/config"
+	"perf-runner/runner"
+)
+
+// runAutotune re-runs the scenario with the autotune arg rising from its
+// start value until the primary metric improves by no more than the
+// tolerance over the best run so far; for lower-is-better metrics such as
+// latency, improving means dropping. It returns the best run, annotated with
+// the sweep. When the gain is within tolerance the lower setting is kept,
+// since the extra load bought nothing.
+func (c *Coordinator) runAutotune(ctx context.Context, test *config.TestScenario) (*TestResult, error) {
+	autotune := test.Autotune
+	tolerance := autotune.Tolerance
+	if tolerance == 0 {
+		tolerance = config.DefaultAutotuneTolerance
+	}
+	
+	report := &AutotuneReport{Arg: autotune.Arg}
+	var best *TestResult
+	var bestMetric float64
+	
+	for value := autotune.Start; value <= autotune.Max; value = autotune.Next(value) {
+		c.logger.Printf("  Autotune: %s=%d", autotune.Arg, value)
+		result, err := c.runWithRetries(ctx, test.WithArg(autotune.Arg, value))
+		if err != nil || !result.Success {
+			if best == nil {
+				return result, err
+			}
+			best.Warnings = append(best.Warnings, fmt.Sprintf("autotune stopped: %s=%d failed: %s", autotune.Arg, value, failureReason(result, err)))
+			break
+		}
+		
+		metric, ok := result.PrimaryValue()
+		if !ok {
+			warning := fmt.Sprintf("autotune stopped: no %s reported at %s=%d", result.PrimaryMetric, autotune.Arg, value)
+			if best == nil {
+				result.Warnings = append(result.Warnings, warning)
+				return result, nil
+			}
+			best.Warnings = append(best.Warnings, warning)
+			break
+		}
+		report.Steps = append(report.Steps, AutotuneStep{Value: value, Metric: metric})
+		
+		if best != nil && !improves(result.PrimaryMetric, metric, bestMetric, tolerance) {
+			c.logger.Printf("  Autotune: %s plateaued at %s=%d", result.PrimaryMetric, autotune.Arg, report.Best)
+			break
+		}
+		best, bestMetric, report.Best = result, metric, value
+	}
+	
+	best.Autotune = report
+	return best, nil
+}
+
+// improves reports whether value beats best by more than the tolerance, in
+// the direction that is better for the metric
+func improves(metric string, value, best, tolerance float64) bool {
+	if runner.LowerIsBetter(metric) {
+		return value < best*(1-tolerance)
+	}
+	return value > best*(1+tolerance)
+}
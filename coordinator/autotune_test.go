@@ -0,0 +1,124 @@
+package coordinator
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+
+	"perf-runner/config"
+	"perf-runner/runner"
+	"perf-runner/ssh"
+)
+
+// streamsRunner passes the streams arg on the command line and reports the
+// client's "bw" output line as its primary metric, bandwidth_mbps unless
+// metric is set
+type streamsRunner struct {
+	fakeRunner
+	metric string
+}
+
+func (r *streamsRunner) PrimaryMetric() string {
+	if r.metric != "" {
+		return r.metric
+	}
+	return r.fakeRunner.PrimaryMetric()
+}
+
+func (r *streamsRunner) BuildCommand(config runner.Config) string {
+	return fmt.Sprintf("%s -P %v", r.fakeRunner.BuildCommand(config), config.GetEffectiveArgs()["streams"])
+}
+
+func (r *streamsRunner) ParseMetrics(result *runner.Result) error {
+	if value, err := strconv.ParseFloat(strings.TrimPrefix(strings.TrimSpace(result.Output), "bw "), 64); err == nil {
+		result.Metrics[r.PrimaryMetric()] = value
+	}
+	return nil
+}
+
+// plateauAtFour reports bandwidth that scales with streams up to 4 and then flattens
+func plateauAtFour(ctx context.Context, command string) (*ssh.Result, error) {
+	streams, _ := strconv.Atoi(command[strings.LastIndex(command, " ")+1:])
+	bw := map[int]float64{1: 2500, 2: 5000, 4: 9400, 8: 9450, 16: 9300}[streams]
+	return &ssh.Result{Output: fmt.Sprintf("bw %.0f", bw)}, nil
+}
+
+func TestRunTest_AutotuneStopsAtPlateau(t *testing.T) {
+	test := config.TestScenario{
+		Name:     "knee",
+		Client:   "client",
+		Server:   "server",
+		Autotune: &config.AutotuneConfig{Arg: "streams", Start: 1, Max: 16},
+	}
+	client := &fakeHostClient{handler: plateauAtFour}
+	coord := newTestCoordinator([]config.TestScenario{test}, map[string]*fakeHostClient{
+		"client": client,
+		"server": {handler: runForever(true)},
+	})
+	coord.RegisterRunner("fake", &streamsRunner{})
+
+	result, err := coord.RunTest(context.Background(), &test)
+	if err != nil {
+		t.Fatalf("RunTest returned error: %v", err)
+	}
+
+	if result.Autotune == nil || result.Autotune.Best != 4 {
+		t.Fatalf("Expected the best setting to be streams=4, got %+v", result.Autotune)
+	}
+	if value, _ := result.PrimaryValue(); value != 9400 {
+		t.Errorf("Expected the result of the streams=4 run, got %v", value)
+	}
+
+	var tried []int
+	for _, step := range result.Autotune.Steps {
+		tried = append(tried, step.Value)
+	}
+	if fmt.Sprint(tried) != "[1 2 4 8]" {
+		t.Errorf("Expected the sweep to stop after the plateau at 8, tried %v", tried)
+	}
+	if len(client.commands) != 4 || client.commands[3] != "fake-client server -P 8" {
+		t.Errorf("Unexpected client commands %v", client.commands)
+	}
+	if test.Config != nil {
+		t.Error("Expected the scenario's own config to be left untouched")
+	}
+}
+
+// latencyFloorAtFour reports latency that falls with streams up to 4 and then flattens
+func latencyFloorAtFour(ctx context.Context, command string) (*ssh.Result, error) {
+	streams, _ := strconv.Atoi(command[strings.LastIndex(command, " ")+1:])
+	latency := map[int]float64{1: 40, 2: 20, 4: 10, 8: 9.8, 16: 9.9}[streams]
+	return &ssh.Result{Output: fmt.Sprintf("bw %.1f", latency)}, nil
+}
+
+func TestRunTest_AutotuneLowerIsBetterMetric(t *testing.T) {
+	test := config.TestScenario{
+		Name:     "knee",
+		Client:   "client",
+		Server:   "server",
+		Autotune: &config.AutotuneConfig{Arg: "streams", Start: 1, Max: 16},
+	}
+	coord := newTestCoordinator([]config.TestScenario{test}, map[string]*fakeHostClient{
+		"client": {handler: latencyFloorAtFour},
+		"server": {handler: runForever(true)},
+	})
+	coord.RegisterRunner("fake", &streamsRunner{metric: "latency_avg_usec"})
+
+	result, err := coord.RunTest(context.Background(), &test)
+	if err != nil {
+		t.Fatalf("RunTest returned error: %v", err)
+	}
+
+	// Falling latency is an improvement; the sweep stops once it flattens
+	if result.Autotune == nil || result.Autotune.Best != 4 {
+		t.Fatalf("Expected the best setting to be streams=4, got %+v", result.Autotune)
+	}
+	if value, _ := result.PrimaryValue(); value != 10 {
+		t.Errorf("Expected the result of the streams=4 run, got %v", value)
+	}
+	if len(result.Autotune.Steps) != 4 {
+		t.Errorf("Expected the sweep to stop after the plateau at 8, got %+v", result.Autotune.Steps)
+	}
+}
@@ -0,0 +1,92 @@
+package coordinator
+
+import (
+	"sync"
+
+	"perf-runner/envinfo"
+)
+
+// hostCache remembers per-host checks that don't change during a run, so
+// scenarios sharing hosts don't repeat them
+type hostCache struct {
+	mu           sync.Mutex
+	validated    map[string]bool
+	environments map[string]*envinfo.EnvironmentInfo
+	versions     map[string]string
+}
+
+// newHostCache creates an empty host cache
+func newHostCache() *hostCache {
+	return &hostCache{
+		validated:    make(map[string]bool),
+		environments: make(map[string]*envinfo.EnvironmentInfo),
+		versions:     make(map[string]string),
+	}
+}
+
+// cacheKey identifies a host/runner (or host/binary) pair
+func cacheKey(host, name string) string {
+	return host + "/" + name
+}
+
+// isValidated reports whether binary was already found on host
+func (c *hostCache) isValidated(host, binary string) bool {
+	if c == nil {
+		return false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.validated[cacheKey(host, binary)]
+}
+
+// markValidated records that binary exists on host
+func (c *hostCache) markValidated(host, binary string) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.validated[cacheKey(host, binary)] = true
+}
+
+// environment returns the environment collected earlier for host/runner
+func (c *hostCache) environment(host, runnerName string) (*envinfo.EnvironmentInfo, bool) {
+	if c == nil {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	env, ok := c.environments[cacheKey(host, runnerName)]
+	return env, ok
+}
+
+// storeEnvironment records the environment collected for host/runner
+func (c *hostCache) storeEnvironment(host, runnerName string, env *envinfo.EnvironmentInfo) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.environments[cacheKey(host, runnerName)] = env
+}
+
+// toolVersion returns the version of binary detected earlier on host
+func (c *hostCache) toolVersion(host, binary string) (string, bool) {
+	if c == nil {
+		return "", false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	version, ok := c.versions[cacheKey(host, binary)]
+	return version, ok
+}
+
+// storeToolVersion records the version of binary detected on host
+func (c *hostCache) storeToolVersion(host, binary, version string) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.versions[cacheKey(host, binary)] = version
+}
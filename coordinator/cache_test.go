@@ -0,0 +1,53 @@
+package coordinator
+
+import (
+	"context"
+	"testing"
+
+	"perf-runner/config"
+)
+
+func TestRunAllTests_ValidatesSharedHostOnce(t *testing.T) {
+	for _, caching := range []bool{true, false} {
+		name := "cached"
+		wantProbes := 1
+		if !caching {
+			name = "no-cache"
+			wantProbes = 2
+		}
+
+		t.Run(name, func(t *testing.T) {
+			tests := []config.TestScenario{
+				{Name: "first", Client: "client", Server: "server"},
+				{Name: "second", Client: "client", Server: "server"},
+			}
+			clients := map[string]*fakeHostClient{
+				"client": {handler: succeed("done")},
+				"server": {handler: runForever(true)},
+			}
+			coord := newTestCoordinator(tests, clients)
+			coord.SetCaching(caching)
+
+			results, err := coord.RunAllTests(context.Background())
+			if err != nil {
+				t.Fatalf("RunAllTests returned error: %v", err)
+			}
+			if len(results) != 2 || !results[0].Success || !results[1].Success {
+				t.Fatalf("Expected two successful results, got %+v", results)
+			}
+
+			for host, client := range clients {
+				// The load check probes for uptime before every test
+				binaryChecks := 0
+				for _, probe := range client.probes {
+					if probe != "command -v uptime" {
+						binaryChecks++
+					}
+				}
+				if binaryChecks != wantProbes {
+					t.Errorf("Host %s: expected %d binary checks, got %v", host, wantProbes, client.probes)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package coordinator
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// carrierChangesCommand prints "<path>:<count>" for every interface's
+// carrier_changes counter, which the kernel bumps on each link up/down. Some
+// interfaces refuse the read, so grep's exit status is ignored.
+const carrierChangesCommand = "grep -H . /sys/class/net/*/carrier_changes 2>/dev/null; true"
+
+// parseCarrierChanges reads carrier_changes counters keyed by interface,
+// skipping loopback
+func parseCarrierChanges(output string) map[string]int64 {
+	counters := make(map[string]int64)
+	for _, line := range strings.Split(output, "\n") {
+		path, value, found := strings.Cut(strings.TrimSpace(line), ":")
+		if !found {
+			continue
+		}
+		iface := strings.TrimSuffix(strings.TrimPrefix(path, "/sys/class/net/"), "/carrier_changes")
+		if iface == path || iface == "lo" {
+			continue
+		}
+		if count, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64); err == nil {
+			counters[iface] = count
+		}
+	}
+	return counters
+}
+
+// monitorCarrierChanges samples the carrier_changes counters of each host
+// throughout the run
+func monitorCarrierChanges(samplers *hostSamplers, hosts []testHost) map[string]*deltaCollector {
+	return samplers.deltas(hosts, func(testHost) string { return carrierChangesCommand })
+}
+
+// carrierChanges returns the carrier_changes counters of each host at the
+// start and end of the run, keyed by host name. Hosts sampled fewer than
+// twice are left out.
+func carrierChanges(collectors map[string]*deltaCollector) (before, after map[string]map[string]int64) {
+	before = make(map[string]map[string]int64)
+	after = make(map[string]map[string]int64)
+	for name, collector := range collectors {
+		if collector.samples < 2 {
+			continue
+		}
+		before[name] = parseCarrierChanges(collector.first)
+		after[name] = parseCarrierChanges(collector.last)
+	}
+	return before, after
+}
+
+// recordCarrierTransitions attaches carrier_transitions, the number of link
+// up/down transitions over the test summed across the host's interfaces, to
+// the metrics of the role each host ran, and warns about any flapping link.
+// Interfaces that appeared or vanished during the test are ignored.
+func (e *TestExecutor) recordCarrierTransitions(result *TestResult, before, after map[string]map[string]int64) {
+	hostResults := roleResultsByHost(result)
+	hosts := make([]string, 0, len(before))
+	for name := range before {
+		hosts = append(hosts, name)
+	}
+	sort.Strings(hosts)
+
+	for _, name := range hosts {
+		end, ok := after[name]
+		roleResult := hostResults[name]
+		if !ok || roleResult == nil {
+			continue
+		}
+
+		ifaces := make([]string, 0, len(before[name]))
+		for iface := range before[name] {
+			ifaces = append(ifaces, iface)
+		}
+		sort.Strings(ifaces)
+
+		var transitions int64
+		for _, iface := range ifaces {
+			count, ok := end[iface]
+			if !ok {
+				continue
+			}
+			if delta := count - before[name][iface]; delta > 0 {
+				transitions += delta
+				e.addWarning(result, fmt.Sprintf("link %s on %s changed carrier state %d times during the test", iface, name, delta))
+			}
+		}
+
+		if roleResult.Metrics == nil {
+			roleResult.Metrics = make(map[string]interface{})
+		}
+		roleResult.Metrics["carrier_transitions"] = transitions
+	}
+}
@@ -0,0 +1,74 @@
+package coordinator
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"perf-runner/runner"
+)
+
+func TestParseCarrierChanges(t *testing.T) {
+	output := "/sys/class/net/eth0/carrier_changes:2\n" +
+		"/sys/class/net/ib0/carrier_changes:5\n" +
+		"/sys/class/net/lo/carrier_changes:0\n" +
+		"grep: /sys/class/net/bond0/carrier_changes: Invalid argument\n"
+
+	counters := parseCarrierChanges(output)
+	if len(counters) != 2 || counters["eth0"] != 2 || counters["ib0"] != 5 {
+		t.Errorf("Unexpected counters: %v", counters)
+	}
+}
+
+func TestRecordCarrierTransitions(t *testing.T) {
+	coord := newTestCoordinator(nil, nil)
+	executor := newTestExecutor(coord)
+	result := &TestResult{
+		Hosts:        map[string]string{"client": "gen", "server": "sink"},
+		ClientResult: &runner.Result{Metrics: map[string]interface{}{}},
+		ServerResult: &runner.Result{},
+	}
+	before := map[string]map[string]int64{
+		"gen":  {"eth0": 2, "ib0": 5},
+		"sink": {"ib0": 7},
+	}
+	after := map[string]map[string]int64{
+		"gen":  {"eth0": 2, "ib0": 9, "veth1": 1},
+		"sink": {"ib0": 7},
+	}
+
+	executor.recordCarrierTransitions(result, before, after)
+
+	if got := result.ClientResult.Metrics["carrier_transitions"]; got != int64(4) {
+		t.Errorf("Expected 4 client carrier transitions, got %v", got)
+	}
+	if got := result.ServerResult.Metrics["carrier_transitions"]; got != int64(0) {
+		t.Errorf("Expected 0 server carrier transitions, got %v", got)
+	}
+	if len(result.Warnings) != 1 || !strings.Contains(result.Warnings[0], "link ib0 on gen changed carrier state 4 times") {
+		t.Errorf("Expected one flap warning for ib0 on gen, got %v", result.Warnings)
+	}
+}
+
+func TestCarrierChanges_FirstAndLastSample(t *testing.T) {
+	collectors := map[string]*deltaCollector{
+		"client": {command: carrierChangesCommand},
+		"server": {command: carrierChangesCommand},
+	}
+	for _, count := range []int{4, 5, 6} {
+		collectors["client"].Collect(time.Now(), fmt.Sprintf("/sys/class/net/eth0/carrier_changes:%d", count))
+	}
+	collectors["server"].Collect(time.Now(), "/sys/class/net/eth0/carrier_changes:1")
+
+	before, after := carrierChanges(collectors)
+	if before["client"]["eth0"] != 4 || after["client"]["eth0"] != 6 {
+		t.Errorf("Expected eth0 to go from 4 to 6, got %v and %v", before, after)
+	}
+	if _, ok := before["server"]; ok {
+		t.Errorf("Expected no counters from a host sampled once, got %v", before["server"])
+	}
+	if !strings.HasSuffix(carrierChangesCommand, "2>/dev/null; true") {
+		t.Errorf("Expected grep's errors and exit status ignored, got %q", carrierChangesCommand)
+	}
+}
@@ -0,0 +1,154 @@
+package coordinator
+
+import (
+	"context"
+	"fmt"
+
+	"perf-runner/config"
+	"perf-runner/runner"
+)
+
+// relayNode is a host between the client and the server of a chain
+type relayNode struct {
+	name   string
+	host   *config.HostConfig
+	client HostClient
+	config *runner.Config
+	target string // address the previous hop connects to
+}
+
+// relayRole returns the TestResult.Hosts key of the i-th relay of a chain:
+// "intermediate" for the first, then "intermediate2", "intermediate3", ...
+func relayRole(i int) string {
+	if i == 0 {
+		return "intermediate"
+	}
+	return fmt.Sprintf("intermediate%d", i+1)
+}
+
+// relaysSucceeded reports whether every relay of the chain succeeded
+func relaysSucceeded(result *TestResult) bool {
+	for _, node := range result.NodeResults {
+		if node.Role == "intermediate" && !roleSucceeded(node.Result) {
+			return false
+		}
+	}
+	return roleSucceeded(result.IntermediateResult)
+}
+
+// executeChainTest coordinates the hosts of a chain. The server starts
+// first, then each relay from the server end toward the client so every hop
+// connects to one already running, and finally the client along with any
+// fan-out clients. A client/server test is a chain without relays; a
+// single-node runner's test, with a nil serverSSH, runs the client alone.
+func (e *TestExecutor) executeChainTest(
+	ctx context.Context,
+	r runner.Runner,
+	clientSSH, serverSSH HostClient,
+	clientConfig, serverConfig *runner.Config,
+	relays []*relayNode,
+	fanOut []fanOutClient,
+	result *TestResult,
+	test *config.TestScenario,
+) error {
+	// Build commands for display using runner's own method, secrets masked
+	if serverSSH != nil {
+		result.ServerCommand = displayCommand(r, *serverConfig)
+	}
+	result.ClientCommand = displayCommand(r, *clientConfig)
+	relayCommands := make([]string, len(relays))
+	for i, relay := range relays {
+		relayCommands[i] = displayCommand(r, *relay.config)
+	}
+	if len(relays) > 0 {
+		result.IntermediateCommand = relayCommands[0]
+	}
+
+	// Start server first
+	var server *backgroundRole
+	if serverSSH != nil {
+		e.coordinator.logger.Printf("  Starting server on %s", test.Server)
+		serverReady := newReadinessCheck(r, serverConfig, test)
+		server = e.startBackgroundRole(ctx, serverSSH, test.Server, r, serverConfig, serverReady.logFile())
+		defer e.stopRole(ctx, r, server, "server", test.Server)
+
+		// Wait for server to start
+		if err := e.waitForReady(ctx, serverSSH, test, "server", test.Server, serverReady); err != nil {
+			return err
+		}
+	}
+
+	// Start relays from the server end, each connecting to the next hop
+	running := make([]*backgroundRole, len(relays))
+	for i := len(relays) - 1; i >= 0; i-- {
+		e.coordinator.logger.Printf("  Starting intermediate node on %s", relays[i].name)
+		relayReady := newReadinessCheck(r, relays[i].config, test)
+		running[i] = e.startBackgroundRole(ctx, relays[i].client, relays[i].name, r, relays[i].config, relayReady.logFile())
+		defer e.stopRole(ctx, r, running[i], "intermediate", relays[i].name)
+
+		// Wait for the relay to establish its connection to the next hop
+		if err := e.waitForReady(ctx, relays[i].client, test, "intermediate", relays[i].name, relayReady); err != nil {
+			return err
+		}
+	}
+
+	// Start client, plus any fan-out clients
+	e.coordinator.logger.Printf("  Starting client on %s", test.Client)
+	var clientResult *runner.Result
+	var err error
+	if len(fanOut) > 0 {
+		clientResult, err = e.runFanOutClients(ctx, r, clientSSH, clientConfig, fanOut, test.ClientStagger, result)
+	} else {
+		clientResult, err = e.runRemoteCommand(ctx, clientSSH, result.Hosts["client"], r, clientConfig, "", "")
+	}
+	if err != nil {
+		return fmt.Errorf("client execution failed: %w", err)
+	}
+
+	result.ClientResult = clientResult
+
+	// Wait for server to complete, stopping a persistent one if it outlives the client
+	if server != nil {
+		serverResult, err := server.wait(ctx, e.serverGrace(r, test, clientResult))
+		if err != nil {
+			if result.Error == "" {
+				result.Error = roleErrorMessage("server", err)
+			}
+		} else {
+			result.ServerResult = serverResult
+			if serverResult.TerminatedByUs {
+				e.coordinator.logger.Printf("  Server on %s terminated after client completed", test.Server)
+			}
+		}
+	}
+
+	// Collect relay results, giving them a bit more time to clean up
+	relayResults := make([]*runner.Result, len(relays))
+	for i, relay := range relays {
+		relayResult, err := running[i].wait(ctx, e.shutdownGrace)
+		if err != nil {
+			if result.Error == "" {
+				result.Error = roleErrorMessage("intermediate", err)
+			}
+			continue
+		}
+		relayResults[i] = relayResult
+		if relayResult.TerminatedByUs {
+			e.coordinator.logger.Printf("  Intermediate node on %s terminated after client completed", relay.name)
+		}
+	}
+	if len(relays) > 0 {
+		result.IntermediateResult = relayResults[0]
+	}
+
+	// Record every hop in traffic order
+	result.NodeResults = append(result.NodeResults, NodeResult{Host: test.Client, Role: "client", Command: result.ClientCommand, Result: result.ClientResult})
+	for i, relay := range relays {
+		result.NodeResults = append(result.NodeResults, NodeResult{Host: relay.name, Role: "intermediate", Command: relayCommands[i], Result: relayResults[i]})
+	}
+	if server != nil {
+		result.NodeResults = append(result.NodeResults, NodeResult{Host: test.Server, Role: "server", Command: result.ServerCommand, Result: result.ServerResult})
+	}
+
+	return nil
+}
@@ -0,0 +1,157 @@
+package coordinator
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"perf-runner/config"
+	"perf-runner/envinfo"
+	"perf-runner/ssh"
+)
+
+// launchLog records the role commands in the order hosts received them
+type launchLog struct {
+	mu       sync.Mutex
+	commands []string
+}
+
+// handler logs each command before handing it to next
+func (l *launchLog) handler(next func(ctx context.Context, command string) (*ssh.Result, error)) func(ctx context.Context, command string) (*ssh.Result, error) {
+	return func(ctx context.Context, command string) (*ssh.Result, error) {
+		l.mu.Lock()
+		l.commands = append(l.commands, command)
+		l.mu.Unlock()
+		return next(ctx, command)
+	}
+}
+
+func TestExecuteTest_Chain(t *testing.T) {
+	test := config.TestScenario{Name: "chain", Chain: []string{"gen", "relay1", "relay2", "sink"}}
+	test.Client, test.Server, test.Intermediate = "gen", "sink", "relay1"
+
+	launches := &launchLog{}
+	coord := newTestCoordinator([]config.TestScenario{test}, map[string]*fakeHostClient{
+		"gen":    {handler: launches.handler(succeed("done"))},
+		"relay1": {handler: launches.handler(runForever(true))},
+		"relay2": {handler: launches.handler(runForever(true))},
+		"sink":   {handler: launches.handler(runForever(true))},
+	})
+
+	// Leave each background hop time to launch before the next starts
+	executor := newTestExecutor(coord)
+	executor.startupDelay = 20 * time.Millisecond
+
+	result, err := executor.ExecuteTest(context.Background(), &test)
+	if err != nil {
+		t.Fatalf("ExecuteTest returned error: %v", err)
+	}
+	if !result.Success {
+		t.Errorf("Expected the chain to succeed, got error %q", result.Error)
+	}
+
+	// Each hop starts after the one it connects to, and targets it
+	want := []string{"fake-server", "fake-intermediate sink", "fake-intermediate relay2", "fake-client relay1"}
+	if got := launchedCommands(launches.commands); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected launches %v, got %v", want, got)
+	}
+
+	var hosts, roles []string
+	for _, node := range result.NodeResults {
+		hosts = append(hosts, node.Host)
+		roles = append(roles, node.Role)
+		if node.Result == nil {
+			t.Errorf("Expected a result for %s", node.Host)
+		}
+	}
+	if !reflect.DeepEqual(hosts, test.Chain) {
+		t.Errorf("Expected node results in chain order, got %v", hosts)
+	}
+	if !reflect.DeepEqual(roles, []string{"client", "intermediate", "intermediate", "server"}) {
+		t.Errorf("Unexpected node roles %v", roles)
+	}
+	if result.Hosts["intermediate"] != "relay1" || result.Hosts["intermediate2"] != "relay2" {
+		t.Errorf("Expected both relays in hosts, got %v", result.Hosts)
+	}
+	if result.IntermediateResult != result.NodeResults[1].Result {
+		t.Error("Expected the first relay's result as the intermediate result")
+	}
+	for i, role := range []string{"intermediate", "intermediate2"} {
+		if want := commandHash(result.NodeResults[i+1].Command); result.CommandHashes[role] != want {
+			t.Errorf("Expected %s's command hash %s, got %v", role, want, result.CommandHashes)
+		}
+	}
+}
+
+func TestExecuteTest_IntermediateRunsAsChain(t *testing.T) {
+	test := config.TestScenario{Name: "relay", Client: "gen", Intermediate: "relay", Server: "sink"}
+	coord := newTestCoordinator([]config.TestScenario{test}, map[string]*fakeHostClient{
+		"gen":   {handler: succeed("done")},
+		"relay": {handler: runForever(true)},
+		"sink":  {handler: runForever(true)},
+	})
+
+	result, err := newTestExecutor(coord).ExecuteTest(context.Background(), &test)
+	if err != nil {
+		t.Fatalf("ExecuteTest returned error: %v", err)
+	}
+	if !result.Success || len(result.NodeResults) != 3 || result.NodeResults[1].Host != "relay" {
+		t.Errorf("Expected a three-node chain through relay, got success=%v nodes=%+v", result.Success, result.NodeResults)
+	}
+	if result.ClientCommand != "fake-client relay" || result.IntermediateCommand != "fake-intermediate sink" {
+		t.Errorf("Unexpected commands: client %q, intermediate %q", result.ClientCommand, result.IntermediateCommand)
+	}
+}
+
+func TestExecuteTest_ChainCollectsEveryEnvironment(t *testing.T) {
+	test := config.TestScenario{Name: "chain", Chain: []string{"gen", "relay1", "relay2", "sink"}}
+	test.Client, test.Server, test.Intermediate = "gen", "sink", "relay1"
+
+	// Hops run until stopped; the preflight probes around the run answer at once
+	hop := func(ctx context.Context, command string) (*ssh.Result, error) {
+		if strings.HasPrefix(command, "fake-") {
+			return runForever(true)(ctx, command)
+		}
+		return succeed("")(ctx, command)
+	}
+	coord := newTestCoordinator([]config.TestScenario{test}, map[string]*fakeHostClient{
+		"gen":    {handler: succeed("done")},
+		"relay1": {handler: hop},
+		"relay2": {handler: hop},
+		"sink":   {handler: hop},
+	})
+	coord.SetEnvironmentCollection(true)
+	for _, host := range test.Chain {
+		coord.cache.storeEnvironment(host, "fake", &envinfo.EnvironmentInfo{Hostname: host})
+	}
+
+	result, err := newTestExecutor(coord).ExecuteTest(context.Background(), &test)
+	if err != nil {
+		t.Fatalf("ExecuteTest returned error: %v", err)
+	}
+
+	env := result.EnvironmentInfo
+	if env == nil {
+		t.Fatal("Expected environment info")
+	}
+	for _, slot := range []struct {
+		name string
+		env  *envinfo.EnvironmentInfo
+		host string
+	}{
+		{"client", env.ClientEnv, "gen"},
+		{"intermediate", env.IntermediateEnv, "relay1"},
+		{"intermediate2", env.RelayEnvs["intermediate2"], "relay2"},
+		{"server", env.ServerEnv, "sink"},
+	} {
+		if slot.env == nil || slot.env.Hostname != slot.host {
+			t.Errorf("Expected %s environment from %s, got %+v", slot.name, slot.host, slot.env)
+		}
+	}
+	if len(env.RelayEnvs) != 1 {
+		t.Errorf("Expected only later relays in RelayEnvs, got %v", env.RelayEnvs)
+	}
+}
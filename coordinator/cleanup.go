@@ -0,0 +1,27 @@
+package coordinator
+
+import (
+	"context"
+
+	"perf-runner/envinfo"
+	"perf-runner/runner"
+)
+
+// cleanupRoles runs the runner's cleanup for each role on its host, if the
+// runner has one. Failures are logged rather than failing the test, since
+// the measurement itself is already complete.
+func (e *TestExecutor) cleanupRoles(ctx context.Context, r runner.Runner, hosts []testHost) {
+	cleaner, ok := r.(runner.Cleaner)
+	if !ok {
+		return
+	}
+
+	for _, host := range hosts {
+		if host.config == nil {
+			continue
+		}
+		if err := cleaner.Cleanup(ctx, envinfo.NewRemoteExecutor(host.client), *host.config); err != nil {
+			e.coordinator.logger.Printf("  Warning: cleanup on %s (%s) failed: %v", host.name, host.role, err)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package coordinator
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"perf-runner/config"
+	"perf-runner/runner"
+	"perf-runner/ssh"
+)
+
+// cleanupRunner is a fakeRunner that cleans up after every role
+type cleanupRunner struct {
+	fakeRunner
+}
+
+func (r *cleanupRunner) Cleanup(ctx context.Context, executor runner.CommandExecutor, config runner.Config) error {
+	_, err := executor.Execute(ctx, "cleanup-"+config.Role)
+	return err
+}
+
+func TestExecuteTest_CleanupRunsForEachRoleAfterFailure(t *testing.T) {
+	test := config.TestScenario{Name: "cleanup", Client: "client", Server: "server"}
+	client := &fakeHostClient{handler: func(ctx context.Context, command string) (*ssh.Result, error) {
+		if command == "cleanup-client" {
+			return &ssh.Result{}, nil
+		}
+		return &ssh.Result{ExitCode: 1, Error: "Process exited with status 1"}, fmt.Errorf("Process exited with status 1")
+	}}
+	server := &fakeHostClient{handler: func(ctx context.Context, command string) (*ssh.Result, error) {
+		if command == "cleanup-server" {
+			return &ssh.Result{}, nil
+		}
+		return runForever(true)(ctx, command)
+	}}
+	coord := newTestCoordinator([]config.TestScenario{test}, map[string]*fakeHostClient{
+		"client": client,
+		"server": server,
+	})
+	coord.RegisterRunner("fake", &cleanupRunner{})
+
+	result, err := newTestExecutor(coord).ExecuteTest(context.Background(), &test)
+	if err != nil {
+		t.Fatalf("ExecuteTest returned error: %v", err)
+	}
+	if result.Success {
+		t.Fatal("Expected the failing client to fail the test")
+	}
+
+	for name, host := range map[string]*fakeHostClient{"client": client, "server": server} {
+		if last := host.commands[len(host.commands)-1]; last != "cleanup-"+name {
+			t.Errorf("Expected cleanup to be the last command on %s, got %v", name, host.commands)
+		}
+	}
+}
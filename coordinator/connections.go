@@ -0,0 +1,196 @@
+package coordinator
+
+import (
+	"context"
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	"perf-runner/ssh"
+)
+
+// Connection events recorded per host
+const (
+	eventConnected       = "connected"
+	eventConnectFailed   = "connect_failed"
+	eventDropped         = "dropped"
+	eventReconnected     = "reconnected"
+	eventReconnectFailed = "reconnect_failed"
+)
+
+// ConnectionEvent is one change in the state of a host's SSH connection
+type ConnectionEvent struct {
+	Time  time.Time `json:"time"`
+	Event string    `json:"event"`
+	Error string    `json:"error,omitempty"`
+}
+
+// HostConnection summarizes the connection history of one host
+type HostConnection struct {
+	Host       string            `json:"host"`
+	Connected  bool              `json:"connected"`
+	Drops      int               `json:"drops"`
+	Reconnects int               `json:"reconnects"`
+	Events     []ConnectionEvent `json:"events"`
+}
+
+// reconnectable is implemented by host clients that can tell a dropped
+// connection from a failing command and connect again
+type reconnectable interface {
+	Alive() bool
+	Reconnect(ctx context.Context) error
+}
+
+// connectionTracker records the connection events of every host
+type connectionTracker struct {
+	mu    sync.Mutex
+	hosts map[string]*HostConnection
+	// locks serializes reconnects per host so concurrent failures reconnect once
+	locks map[string]*sync.Mutex
+}
+
+// newConnectionTracker returns an empty tracker
+func newConnectionTracker() *connectionTracker {
+	return &connectionTracker{
+		hosts: make(map[string]*HostConnection),
+		locks: make(map[string]*sync.Mutex),
+	}
+}
+
+// record appends an event to host's history and updates its counters
+func (t *connectionTracker) record(host, event string, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	conn, exists := t.hosts[host]
+	if !exists {
+		conn = &HostConnection{Host: host}
+		t.hosts[host] = conn
+	}
+	entry := ConnectionEvent{Time: time.Now(), Event: event}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	conn.Events = append(conn.Events, entry)
+
+	switch event {
+	case eventConnected:
+		conn.Connected = true
+	case eventDropped:
+		conn.Connected = false
+		conn.Drops++
+	case eventReconnected:
+		conn.Connected = true
+		conn.Reconnects++
+	}
+}
+
+// hostLock returns the mutex serializing reconnects to host
+func (t *connectionTracker) hostLock(host string) *sync.Mutex {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	lock, exists := t.locks[host]
+	if !exists {
+		lock = &sync.Mutex{}
+		t.locks[host] = lock
+	}
+	return lock
+}
+
+// report returns a copy of every host's history, sorted by host name
+func (t *connectionTracker) report() []HostConnection {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	report := make([]HostConnection, 0, len(t.hosts))
+	for _, conn := range t.hosts {
+		entry := *conn
+		entry.Events = append([]ConnectionEvent(nil), conn.Events...)
+		report = append(report, entry)
+	}
+	sort.Slice(report, func(i, j int) bool { return report[i].Host < report[j].Host })
+	return report
+}
+
+// trackedClient checks a host's connection whenever a command fails without
+// an exit status, reconnecting once the connection is found dropped. The
+// failed command itself is not retried.
+type trackedClient struct {
+	HostClient
+	host    string
+	tracker *connectionTracker
+}
+
+// ExecuteCommand runs the command, then recovers the connection if it dropped
+func (t *trackedClient) ExecuteCommand(ctx context.Context, command string) (*ssh.Result, error) {
+	return t.ExecuteCommandStream(ctx, command, nil)
+}
+
+// ExecuteCommandStream is ExecuteCommand, streaming the command's output if
+// the host's client can
+func (t *trackedClient) ExecuteCommandStream(ctx context.Context, command string, live io.Writer) (*ssh.Result, error) {
+	result, err := executeStream(ctx, t.HostClient, command, live)
+	// A non-zero exit status means the command ran, so the connection is fine
+	if err != nil && (result == nil || result.ExitCode == 0) && ctx.Err() == nil {
+		t.recover(ctx)
+	}
+	return result, err
+}
+
+// UploadFile copies a file to the host, then recovers the connection if the
+// copy failed and it dropped
+func (t *trackedClient) UploadFile(ctx context.Context, localPath, remotePath string) error {
+	transferer, ok := t.HostClient.(FileTransferer)
+	if !ok {
+		return errNoFileTransfers
+	}
+	err := transferer.UploadFile(ctx, localPath, remotePath)
+	if err != nil && ctx.Err() == nil {
+		t.recover(ctx)
+	}
+	return err
+}
+
+// DownloadFile copies a file from the host, then recovers the connection if
+// the copy failed and it dropped
+func (t *trackedClient) DownloadFile(ctx context.Context, remotePath, localPath string) error {
+	transferer, ok := t.HostClient.(FileTransferer)
+	if !ok {
+		return errNoFileTransfers
+	}
+	err := transferer.DownloadFile(ctx, remotePath, localPath)
+	if err != nil && ctx.Err() == nil {
+		t.recover(ctx)
+	}
+	return err
+}
+
+// recover reconnects the host if its connection no longer answers
+func (t *trackedClient) recover(ctx context.Context) {
+	client, ok := t.HostClient.(reconnectable)
+	if !ok {
+		return
+	}
+
+	lock := t.tracker.hostLock(t.host)
+	lock.Lock()
+	defer lock.Unlock()
+
+	if client.Alive() {
+		return
+	}
+	t.tracker.record(t.host, eventDropped, nil)
+	if err := client.Reconnect(ctx); err != nil {
+		t.tracker.record(t.host, eventReconnectFailed, err)
+		return
+	}
+	t.tracker.record(t.host, eventReconnected, nil)
+}
+
+// ConnectionReport returns the connection history of every host, sorted by
+// host name
+func (c *Coordinator) ConnectionReport() []HostConnection {
+	return c.connections.report()
+}
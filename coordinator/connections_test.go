@@ -0,0 +1,119 @@
+package coordinator
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"perf-runner/config"
+	"perf-runner/ssh"
+)
+
+// droppingClient is a fakeHostClient whose connection can drop and be
+// re-established, counting the keepalive checks made against it
+type droppingClient struct {
+	*fakeHostClient
+	mu         sync.Mutex
+	alive      bool
+	checks     int
+	reconnects int
+}
+
+func (d *droppingClient) Alive() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.checks++
+	return d.alive
+}
+
+func (d *droppingClient) Reconnect(ctx context.Context) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.alive = true
+	d.reconnects++
+	return nil
+}
+
+// drop marks the connection dead
+func (d *droppingClient) drop() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.alive = false
+}
+
+func TestRunAllTests_ReportsReconnect(t *testing.T) {
+	tests := []config.TestScenario{
+		{Name: "first", Client: "client1", Server: "server"},
+		{Name: "second", Client: "client1", Server: "server"},
+	}
+	coord := newTestCoordinator(tests, map[string]*fakeHostClient{
+		"client1": {},
+		"server":  {handler: runForever(true)},
+	})
+
+	client := &droppingClient{alive: true}
+	dropped := false
+	client.fakeHostClient = &fakeHostClient{handler: func(ctx context.Context, command string) (*ssh.Result, error) {
+		if strings.HasPrefix(command, "fake-client") && !dropped {
+			dropped = true
+			client.drop()
+			return nil, fmt.Errorf("failed to create session: EOF")
+		}
+		return &ssh.Result{Output: "done"}, nil
+	}}
+	coord.sshClients["client1"] = client
+	for name := range coord.sshClients {
+		coord.connections.record(name, eventConnected, nil)
+	}
+
+	results, err := coord.RunAllTests(context.Background())
+	if err != nil {
+		t.Fatalf("RunAllTests returned error: %v", err)
+	}
+	if results[0].Success {
+		t.Error("Expected the scenario that lost its connection to fail")
+	}
+	if !results[1].Success {
+		t.Errorf("Expected the scenario after the reconnect to pass, got error %q", results[1].Error)
+	}
+
+	report := coord.ConnectionReport()
+	if len(report) != 2 || report[0].Host != "client1" || report[1].Host != "server" {
+		t.Fatalf("Expected client1 and server in host order, got %+v", report)
+	}
+	if report[0].Reconnects != 1 || report[0].Drops != 1 || !report[0].Connected {
+		t.Errorf("Expected client1 to show one drop and reconnects: 1, got %+v", report[0])
+	}
+	var events []string
+	for _, event := range report[0].Events {
+		events = append(events, event.Event)
+	}
+	if got := strings.Join(events, ","); got != "connected,dropped,reconnected" {
+		t.Errorf("Expected connected,dropped,reconnected events, got %s", got)
+	}
+	if report[1].Reconnects != 0 || report[1].Drops != 0 {
+		t.Errorf("Expected server to keep its connection, got %+v", report[1])
+	}
+}
+
+func TestTrackedClient_IgnoresCommandFailures(t *testing.T) {
+	client := &droppingClient{alive: true, fakeHostClient: &fakeHostClient{
+		handler: func(ctx context.Context, command string) (*ssh.Result, error) {
+			return &ssh.Result{ExitCode: 1}, fmt.Errorf("Process exited with status 1")
+		},
+	}}
+	tracker := newConnectionTracker()
+	tracked := &trackedClient{HostClient: client, host: "server", tracker: tracker}
+
+	if _, err := tracked.ExecuteCommand(context.Background(), "false"); err == nil {
+		t.Fatal("Expected the command's error to be returned")
+	}
+	if client.checks != 0 || client.reconnects != 0 {
+		t.Errorf("Expected no connection check after a non-zero exit, got %d checks and %d reconnects", client.checks, client.reconnects)
+	}
+	if report := tracker.report(); len(report) != 0 {
+		t.Errorf("Expected no connection events, got %+v", report)
+	}
+}
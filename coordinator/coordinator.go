@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"strings"
 	"sync"
 	"time"
 
@@ -12,14 +13,38 @@ import (
 	"perf-runner/ssh"
 )
 
+// HostClient is the subset of the SSH client used to run commands on a host
+type HostClient interface {
+	ExecuteCommand(ctx context.Context, command string) (*ssh.Result, error)
+	Close() error
+}
+
 // Coordinator manages test execution across multiple hosts
 type Coordinator struct {
 	config    *config.TestConfig
 	runners   map[string]runner.Runner
-	sshClients map[string]*ssh.Client
+	sshClients map[string]HostClient
 	logger    *log.Logger
 	mu        sync.RWMutex
 	collectEnv bool
+	streamOutput bool
+	statusMu  sync.Mutex
+	status    Status
+	// cache holds per-host validation and environment results; nil disables caching
+	cache     *hostCache
+	// newExecutor creates the executor for each scenario; tests override it
+	newExecutor func(c *Coordinator) *TestExecutor
+	// newResolver creates the resolver used to pin host names; tests override it
+	newResolver func(mode string, via HostClient) Resolver
+	pins        addressPins
+	// limiter bounds concurrent remote commands; nil means unlimited
+	limiter     *commandLimiter
+	// connections records each host's connects, drops and reconnects
+	connections *connectionTracker
+	// skipUnreachable skips scenarios on hosts that failed to connect instead
+	// of failing ConnectHosts; unreachable holds each such host's error
+	skipUnreachable bool
+	unreachable     map[string]error
 }
 
 // NewCoordinator creates a new test coordinator
@@ -28,13 +53,21 @@ func NewCoordinator(cfg *config.TestConfig, logger *log.Logger) *Coordinator {
 		logger = log.Default()
 	}
 	
-	return &Coordinator{
+	coord := &Coordinator{
 		config:     cfg,
 		runners:    make(map[string]runner.Runner),
-		sshClients: make(map[string]*ssh.Client),
+		sshClients: make(map[string]HostClient),
 		logger:     logger,
 		collectEnv: false,
+		cache:      newHostCache(),
+		newExecutor: NewTestExecutor,
+		newResolver: newResolver,
+		connections: newConnectionTracker(),
 	}
+	if cfg.MaxConcurrentCommands > 0 {
+		coord.limiter = newCommandLimiter(cfg.MaxConcurrentCommands)
+	}
+	return coord
 }
 
 // SetEnvironmentCollection enables or disables environment information collection
@@ -42,6 +75,16 @@ func (c *Coordinator) SetEnvironmentCollection(enabled bool) {
 	c.collectEnv = enabled
 }
 
+// SetCaching enables or disables reuse of binary validation and environment
+// collection across scenarios that share hosts
+func (c *Coordinator) SetCaching(enabled bool) {
+	if enabled {
+		c.cache = newHostCache()
+	} else {
+		c.cache = nil
+	}
+}
+
 // RegisterRunner registers a runner implementation
 func (c *Coordinator) RegisterRunner(name string, r runner.Runner) {
 	c.mu.Lock()
@@ -49,40 +92,63 @@ func (c *Coordinator) RegisterRunner(name string, r runner.Runner) {
 	c.runners[name] = r
 }
 
-// ConnectHosts establishes SSH connections to all configured hosts
+// ConnectHosts establishes SSH connections to the hosts used by the selected
+// scenarios; other configured hosts are left alone. Any host that fails to
+// connect fails the call, unless unreachable hosts are skipped (see
+// SetSkipUnreachable). Failures are recorded in the connection report.
 func (c *Coordinator) ConnectHosts(ctx context.Context) error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 	
+	hosts := c.referencedHosts()
+	if unused := len(c.config.Hosts) - len(hosts); unused > 0 {
+		c.logger.Printf("Not connecting to %d hosts unused by the selected scenarios", unused)
+	}
+	
 	var wg sync.WaitGroup
-	errCh := make(chan error, len(c.config.Hosts))
+	var connectMu sync.Mutex
+	failed := make(map[string]error)
 	
-	for hostName, hostConfig := range c.config.Hosts {
+	for _, hostName := range hosts {
+		hostConfig, exists := c.config.Hosts[hostName]
+		if !exists {
+			continue
+		}
 		wg.Add(1)
 		go func(name string, cfg *config.HostConfig) {
 			defer wg.Done()
 			
 			client := ssh.NewClient(cfg.SSH)
 			if err := client.Connect(ctx); err != nil {
-				errCh <- fmt.Errorf("failed to connect to host %s: %w", name, err)
+				c.connections.record(name, eventConnectFailed, err)
+				connectMu.Lock()
+				failed[name] = err
+				connectMu.Unlock()
 				return
 			}
 			
+			connectMu.Lock()
 			c.sshClients[name] = client
+			connectMu.Unlock()
+			c.connections.record(name, eventConnected, nil)
 			c.logger.Printf("Connected to host %s (%s)", name, cfg.SSH.Host)
 		}(hostName, hostConfig)
 	}
 	
 	wg.Wait()
-	close(errCh)
 	
 	// Check for connection errors
+	c.unreachable = failed
 	var errors []error
-	for err := range errCh {
+	for _, name := range c.UnreachableHosts() {
+		err := fmt.Errorf("failed to connect to host %s: %w", name, failed[name])
+		if c.skipUnreachable {
+			c.logger.Printf("Warning: %v; skipping the scenarios that use it", err)
+		}
 		errors = append(errors, err)
 	}
 	
-	if len(errors) > 0 {
+	if len(errors) > 0 && !c.skipUnreachable {
 		return fmt.Errorf("connection errors: %v", errors)
 	}
 	
@@ -93,9 +159,49 @@ func (c *Coordinator) ConnectHosts(ctx context.Context) error {
 func (c *Coordinator) RunAllTests(ctx context.Context) ([]*TestResult, error) {
 	c.logger.Printf("Starting test execution for %d scenarios", len(c.config.Tests))
 	
+	totalTests := 0
+	for _, test := range c.config.Tests {
+		if test.Repeat > 1 {
+			totalTests += test.Repeat
+		} else {
+			totalTests++
+		}
+	}
+	c.updateStatus(func(status *Status) {
+		*status = Status{Name: c.config.Name, TotalTests: totalTests, StartTime: time.Now()}
+	})
+	defer c.updateStatus(func(status *Status) {
+		status.CurrentTest = ""
+		status.Done = true
+	})
+	
+	// Setup outcome of each group whose first scenario has been reached
+	groupSetups := make(map[string]error)
+	lastInGroup := groupBoundaries(c.config.Tests)
+	
 	var results []*TestResult
 	for i, test := range c.config.Tests {
 		c.logger.Printf("Running test %d/%d: %s", i+1, len(c.config.Tests), test.Name)
+		c.updateStatus(func(status *Status) { status.CurrentTest = test.Name })
+		
+		// Scenarios needing unreachable hosts are skipped before any setup
+		unreachable := c.unreachableHostsOf(&test)
+		if len(unreachable) > 0 {
+			c.logger.Printf("Skipping test %s: unreachable hosts %s", test.Name, strings.Join(unreachable, ", "))
+		}
+		
+		// Prepare the hosts once for the whole group
+		var groupErr error
+		if test.Group != "" && len(unreachable) == 0 {
+			if _, started := groupSetups[test.Group]; !started {
+				groupSetups[test.Group] = c.setupGroup(ctx, test.Group)
+			}
+			groupErr = groupSetups[test.Group]
+		}
+		
+		if test.Prewarm && groupErr == nil && len(unreachable) == 0 {
+			c.prewarm(ctx, &test)
+		}
 		
 		repeat := test.Repeat
 		if repeat <= 0 {
@@ -103,23 +209,43 @@ func (c *Coordinator) RunAllTests(ctx context.Context) ([]*TestResult, error) {
 		}
 		
 		for j := 0; j < repeat; j++ {
+			if len(unreachable) > 0 {
+				result := skippedResult(&test, unreachable)
+				results = append(results, result)
+				c.updateStatus(func(status *Status) { status.Results = append(status.Results, result) })
+				continue
+			}
 			if repeat > 1 {
 				c.logger.Printf("  Iteration %d/%d", j+1, repeat)
 			}
 			
-			result, err := c.RunTest(ctx, &test)
+			// Scenarios of a group whose setup failed fail without running
+			var result *TestResult
+			err := groupErr
+			if err == nil {
+				iteration := test
+				iteration.Iteration = j + 1
+				result, err = c.RunTest(ctx, &iteration)
+			}
 			if err != nil {
 				c.logger.Printf("Test %s failed: %v", test.Name, err)
 				result = &TestResult{
 					ScenarioName: test.Name,
 					Success:      false,
 					Error:        err.Error(),
+					ErrorClass:   string(classifyError(err)),
 					StartTime:    time.Now(),
 					EndTime:      time.Now(),
 				}
 			}
+			// A failed group setup, or an error that kept the scenario from
+			// running, is not the failure the scenario tests for
+			if groupErr == nil {
+				applyExpectFail(&test, result, err == nil && ctx.Err() == nil)
+			}
 			
 			results = append(results, result)
+			c.updateStatus(func(status *Status) { status.Results = append(status.Results, result) })
 			
 			// Delay between iterations
 			if j < repeat-1 && test.Delay > 0 {
@@ -127,15 +253,108 @@ func (c *Coordinator) RunAllTests(ctx context.Context) ([]*TestResult, error) {
 				time.Sleep(test.Delay)
 			}
 		}
+		
+		if _, started := groupSetups[test.Group]; started && lastInGroup[test.Group] == i {
+			c.teardownGroup(ctx, test.Group)
+		}
 	}
 	
 	return results, nil
 }
 
+// prewarm runs the scenario's throwaway warm-up test. Its result is only
+// logged; a failed prewarm does not stop the measured run.
+func (c *Coordinator) prewarm(ctx context.Context, test *config.TestScenario) {
+	c.logger.Printf("  Prewarming with a %v run (result discarded)", config.PrewarmDuration)
+	result, err := c.newExecutor(c).ExecuteTest(ctx, test.PrewarmScenario())
+	if err != nil || !result.Success {
+		c.logger.Printf("  Warning: prewarm run failed: %s", failureReason(result, err))
+	}
+}
+
 // RunTest executes a single test scenario
 func (c *Coordinator) RunTest(ctx context.Context, test *config.TestScenario) (*TestResult, error) {
-	executor := NewTestExecutor(c)
-	return executor.ExecuteTest(ctx, test)
+	if test.Autotune != nil {
+		return c.runAutotune(ctx, test)
+	}
+	
+	result, err := c.runWithRetries(ctx, test)
+	if len(test.FallbackHosts) == 0 || (err == nil && result.Success) || test.ExpectFail || ctx.Err() != nil {
+		return result, err
+	}
+	
+	primaryError := failureReason(result, err)
+	c.logger.Printf("  Test %s failed on primary hosts (%s), retrying on fallback hosts %v", test.Name, primaryError, test.FallbackHosts)
+	
+	fallbackResult, err := c.runWithRetries(ctx, test.WithFallbackHosts())
+	if err != nil {
+		return nil, fmt.Errorf("fallback retry failed: %w (primary: %s)", err, primaryError)
+	}
+	
+	fallbackResult.FallbackUsed = true
+	fallbackResult.Warnings = append(fallbackResult.Warnings, fmt.Sprintf("primary hosts failed, result is from fallback hosts: %s", primaryError))
+	return fallbackResult, nil
+}
+
+// runWithRetries runs a scenario, re-running it up to test.Retries times while
+// it fails with transient errors. Deterministic failures are returned at once.
+func (c *Coordinator) runWithRetries(ctx context.Context, test *config.TestScenario) (*TestResult, error) {
+	for attempt := 1; ; attempt++ {
+		result, err := c.newExecutor(c).ExecuteTest(ctx, test)
+		if err == nil && result.Success {
+			result.Attempts = attempt
+			return result, nil
+		}
+		
+		class := classifyFailure(result, err)
+		if err == nil {
+			result.Attempts = attempt
+			result.ErrorClass = string(class)
+		}
+		
+		if class != ErrorTransient || attempt > test.Retries || test.ExpectFail || ctx.Err() != nil {
+			return result, err
+		}
+		
+		c.logger.Printf("  Test %s failed with a transient error (%s), retrying (%d/%d)", test.Name, failureReason(result, err), attempt, test.Retries)
+	}
+}
+
+// applyExpectFail inverts the outcome of a scenario marked expect_fail. A
+// failure reported by the runner passes, keeping its error for reference; a
+// success fails. When the scenario did not run, as after an SSH failure, a
+// missing binary, or cancellation, its failure stands.
+func applyExpectFail(test *config.TestScenario, result *TestResult, ran bool) {
+	if !test.ExpectFail {
+		return
+	}
+	result.ExpectFail = true
+	if !ran {
+		return
+	}
+	if !result.Success {
+		result.Success = true
+		return
+	}
+	result.Success = false
+	result.Error = "expected to fail but succeeded"
+	result.ErrorClass = string(ErrorDeterministic)
+}
+
+// failureReason summarizes why a scenario failed
+func failureReason(result *TestResult, err error) string {
+	switch {
+	case err != nil:
+		return err.Error()
+	case result.Error != "":
+		return result.Error
+	case result.ClientResult == nil || !result.ClientResult.Success:
+		return "client failed"
+	case !roleSucceeded(result.ServerResult):
+		return "server failed"
+	default:
+		return "intermediate failed"
+	}
 }
 
 
@@ -150,5 +369,5 @@ func (c *Coordinator) Cleanup() {
 		}
 	}
 	
-	c.sshClients = make(map[string]*ssh.Client)
+	c.sshClients = make(map[string]HostClient)
 }
\ No newline at end of file
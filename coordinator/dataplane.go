@@ -0,0 +1,59 @@
+package coordinator
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+
+	"perf-runner/envinfo"
+)
+
+// dataPlaneAddress returns the host's interface address inside subnet, as
+// reported by the network envinfo module
+func (e *TestExecutor) dataPlaneAddress(ctx context.Context, hostName string, client HostClient, subnet *net.IPNet) (string, error) {
+	networkInfo, err := hostNetworkInfo(ctx, hostName, client)
+	if err != nil {
+		return "", err
+	}
+	
+	address, found := networkInfo.AddressInSubnet(subnet)
+	if !found {
+		return "", fmt.Errorf("host %s has no address in data_plane_subnet %s", hostName, subnet)
+	}
+	
+	e.coordinator.logger.Printf("  Using data-plane address %s for host %s", address, hostName)
+	return address, nil
+}
+
+// checkDataPlaneLinks fails when an interface holding the host's data-plane
+// address is not up (administratively DOWN or NO-CARRIER), which otherwise
+// surfaces as confusing connection timeouts mid-test
+func (e *TestExecutor) checkDataPlaneLinks(ctx context.Context, host testHost, subnet *net.IPNet) error {
+	networkInfo, err := hostNetworkInfo(ctx, host.name, host.client)
+	if err != nil {
+		return err
+	}
+	
+	for _, iface := range networkInfo.InterfacesInSubnet(subnet) {
+		if !iface.IsUp {
+			return fmt.Errorf("%s %s: data-plane interface %s (%s) is down; check 'ip link show %s' for DOWN or NO-CARRIER",
+				host.role, host.name, iface.Name, strings.Join(iface.IPAddresses, ", "), iface.Name)
+		}
+	}
+	return nil
+}
+
+// hostNetworkInfo lists a host's interfaces with the network envinfo module
+func hostNetworkInfo(ctx context.Context, hostName string, client HostClient) (*envinfo.NetworkInfo, error) {
+	data, err := envinfo.NewNetworkModule().Collect(ctx, envinfo.NewRemoteExecutor(client))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list interfaces on host %s: %w", hostName, err)
+	}
+	
+	networkInfo, ok := data.(*envinfo.NetworkInfo)
+	if !ok {
+		return nil, fmt.Errorf("unexpected network info from host %s", hostName)
+	}
+	return networkInfo, nil
+}
@@ -0,0 +1,126 @@
+package coordinator
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"perf-runner/config"
+	"perf-runner/ssh"
+)
+
+// multiHomedServer answers interface queries for a host with management,
+// data-plane, and loopback addresses, and otherwise runs like a server
+func multiHomedServer(ctx context.Context, command string) (*ssh.Result, error) {
+	return multiHomedHost("up")(ctx, command)
+}
+
+// multiHomedHost is multiHomedServer with every interface in the given operstate
+func multiHomedHost(operstate string) func(ctx context.Context, command string) (*ssh.Result, error) {
+	return func(ctx context.Context, command string) (*ssh.Result, error) {
+		switch {
+		case strings.HasPrefix(command, "ip link show"):
+			return &ssh.Result{Output: "lo\neth0\nib0\n"}, nil
+		case strings.HasPrefix(command, "ip addr show lo "):
+			return &ssh.Result{Output: "127.0.0.1/8\n"}, nil
+		case strings.HasPrefix(command, "ip addr show eth0 "):
+			return &ssh.Result{Output: "192.168.1.100/24\n"}, nil
+		case strings.HasPrefix(command, "ip addr show ib0 "):
+			return &ssh.Result{Output: "10.10.0.5/16\n10.20.0.5/16\n"}, nil
+		case strings.HasSuffix(command, "/operstate"):
+			return &ssh.Result{Output: operstate + "\n"}, nil
+		case strings.HasPrefix(command, "cat ") || strings.HasPrefix(command, "readlink "):
+			return &ssh.Result{}, nil
+		}
+		return runForever(true)(ctx, command)
+	}
+}
+
+func TestExecuteTest_DataPlaneSubnetPicksTarget(t *testing.T) {
+	test := config.TestScenario{
+		Name:            "data plane",
+		Client:          "client",
+		Server:          "server",
+		DataPlaneSubnet: "10.20.0.0/16",
+	}
+	coord := newTestCoordinator([]config.TestScenario{test}, map[string]*fakeHostClient{
+		"client": {handler: succeed("done")},
+		"server": {handler: multiHomedServer},
+	})
+
+	result, err := newTestExecutor(coord).ExecuteTest(context.Background(), &test)
+	if err != nil {
+		t.Fatalf("ExecuteTest returned error: %v", err)
+	}
+	if result.ClientCommand != "fake-client 10.20.0.5" {
+		t.Errorf("Expected client to target the data-plane address, got %q", result.ClientCommand)
+	}
+}
+
+func TestExecuteTest_DataPlaneSubnetWithoutMatch(t *testing.T) {
+	test := config.TestScenario{
+		Name:            "no match",
+		Client:          "client",
+		Server:          "server",
+		DataPlaneSubnet: "172.16.0.0/12",
+	}
+	coord := newTestCoordinator([]config.TestScenario{test}, map[string]*fakeHostClient{
+		"client": {handler: succeed("done")},
+		"server": {handler: multiHomedServer},
+	})
+
+	_, err := newTestExecutor(coord).ExecuteTest(context.Background(), &test)
+	if err == nil || !strings.Contains(err.Error(), "no address in data_plane_subnet") {
+		t.Errorf("Expected a no-matching-address error, got %v", err)
+	}
+}
+
+func TestExecuteTest_DataPlaneLinkDownAbortsScenario(t *testing.T) {
+	test := config.TestScenario{
+		Name:            "link down",
+		Client:          "client",
+		Server:          "server",
+		DataPlaneSubnet: "10.20.0.0/16",
+	}
+	client := &fakeHostClient{handler: succeed("done")}
+	server := &fakeHostClient{handler: multiHomedHost("down")}
+	coord := newTestCoordinator([]config.TestScenario{test}, map[string]*fakeHostClient{
+		"client": client,
+		"server": server,
+	})
+
+	_, err := newTestExecutor(coord).ExecuteTest(context.Background(), &test)
+	if err == nil || !strings.Contains(err.Error(), "data-plane interface ib0") || !strings.Contains(err.Error(), "is down") {
+		t.Fatalf("Expected a link-down error for ib0, got %v", err)
+	}
+	for _, command := range append(client.commands, server.commands...) {
+		if strings.HasPrefix(command, "fake-") {
+			t.Errorf("Expected no test command to be launched, got %q", command)
+		}
+	}
+}
+
+func TestExecuteTest_DataPlaneLinkStates(t *testing.T) {
+	for operstate, wantDown := range map[string]bool{
+		"unknown":        false,
+		"lowerlayerdown": true,
+	} {
+		t.Run(operstate, func(t *testing.T) {
+			test := config.TestScenario{
+				Name:            "link " + operstate,
+				Client:          "client",
+				Server:          "server",
+				DataPlaneSubnet: "10.20.0.0/16",
+			}
+			coord := newTestCoordinator([]config.TestScenario{test}, map[string]*fakeHostClient{
+				"client": {handler: succeed("done")},
+				"server": {handler: multiHomedHost(operstate)},
+			})
+
+			_, err := newTestExecutor(coord).ExecuteTest(context.Background(), &test)
+			if down := err != nil && strings.Contains(err.Error(), "is down"); down != wantDown {
+				t.Errorf("Expected link down: %v, got error %v", wantDown, err)
+			}
+		})
+	}
+}
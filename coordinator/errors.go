@@ -0,0 +1,93 @@
+package coordinator
+
+import (
+	"errors"
+	"strings"
+)
+
+// ErrorClass tells whether a failure is worth retrying
+type ErrorClass string
+
+const (
+	// ErrorTransient failures (dropped connections, timeouts) may pass on retry
+	ErrorTransient ErrorClass = "transient"
+	// ErrorDeterministic failures (missing binary, bad arguments) will fail again
+	ErrorDeterministic ErrorClass = "deterministic"
+)
+
+// ClassifiedError is an error that carries its retry class
+type ClassifiedError struct {
+	Class ErrorClass
+	Err   error
+}
+
+func (e *ClassifiedError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ClassifiedError) Unwrap() error {
+	return e.Err
+}
+
+// transientMarkers are message fragments of SSH and network errors that are
+// typically gone on the next attempt
+var transientMarkers = []string{
+	"connection reset",
+	"broken pipe",
+	"connection refused",
+	"i/o timeout",
+	"unexpected eof",
+	"use of closed network connection",
+	"failed to create session",
+	"not connected",
+	"handshake failed",
+}
+
+// classifyError returns the retry class of err. Errors that carry a class
+// keep it; otherwise the message is matched against known transient failures.
+// Anything unrecognized is deterministic so real failures are not masked.
+func classifyError(err error) ErrorClass {
+	var classified *ClassifiedError
+	if errors.As(err, &classified) {
+		return classified.Class
+	}
+	return classifyMessage(err.Error())
+}
+
+// classifyMessage classifies a failure from its message alone
+func classifyMessage(message string) ErrorClass {
+	lower := strings.ToLower(message)
+	for _, marker := range transientMarkers {
+		if strings.Contains(lower, marker) {
+			return ErrorTransient
+		}
+	}
+	return ErrorDeterministic
+}
+
+// classifyFailure classifies a failed scenario from the executor's error or,
+// failing that, from the errors recorded on its result
+func classifyFailure(result *TestResult, err error) ErrorClass {
+	if err != nil {
+		return classifyError(err)
+	}
+	
+	messages := []string{result.Error}
+	if result.ClientResult != nil {
+		messages = append(messages, result.ClientResult.Error)
+	}
+	if result.ServerResult != nil {
+		messages = append(messages, result.ServerResult.Error)
+	}
+	if result.IntermediateResult != nil {
+		messages = append(messages, result.IntermediateResult.Error)
+	}
+	// Relays further down a chain
+	for _, node := range result.NodeResults {
+		if node.Role == "intermediate" && node.Result != nil && node.Result != result.IntermediateResult {
+			messages = append(messages, node.Result.Error)
+		}
+	}
+	
+	return classifyMessage(strings.Join(messages, "\n"))
+}
@@ -0,0 +1,82 @@
+package coordinator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+
+	"perf-runner/config"
+	"perf-runner/ssh"
+)
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		err  error
+		want ErrorClass
+	}{
+		{fmt.Errorf("client execution failed: read tcp 10.0.0.1:22: connection reset by peer"), ErrorTransient},
+		{fmt.Errorf("failed to create session: EOF"), ErrorTransient},
+		{fmt.Errorf("wrapped: %w", &ClassifiedError{Class: ErrorDeterministic, Err: errors.New("connection reset but really missing")}), ErrorDeterministic},
+		{fmt.Errorf("iperf3 binary iperf3 not found on server host s1"), ErrorDeterministic},
+		{fmt.Errorf("Process exited with status 1"), ErrorDeterministic},
+	}
+
+	for _, tt := range tests {
+		if got := classifyError(tt.err); got != tt.want {
+			t.Errorf("classifyError(%q) = %s, want %s", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestRunTest_RetriesTransientFailures(t *testing.T) {
+	test := config.TestScenario{Name: "flaky link", Client: "client", Server: "server", Retries: 2}
+
+	// The first client run loses its SSH connection, the second succeeds
+	var runs int32
+	client := &fakeHostClient{handler: func(ctx context.Context, command string) (*ssh.Result, error) {
+		if atomic.AddInt32(&runs, 1) == 1 {
+			return nil, fmt.Errorf("read tcp 10.0.0.2:22: connection reset by peer")
+		}
+		return &ssh.Result{Output: "done"}, nil
+	}}
+	coord := newTestCoordinator([]config.TestScenario{test}, map[string]*fakeHostClient{
+		"client": client,
+		"server": {handler: runForever(true)},
+	})
+
+	result, err := coord.RunTest(context.Background(), &test)
+	if err != nil {
+		t.Fatalf("RunTest returned error: %v", err)
+	}
+	if !result.Success || result.Attempts != 2 {
+		t.Errorf("Expected success on attempt 2, got success=%v attempts=%d", result.Success, result.Attempts)
+	}
+}
+
+func TestRunTest_DoesNotRetryMissingBinary(t *testing.T) {
+	test := config.TestScenario{Name: "missing tool", Client: "client", Server: "server", Retries: 2}
+
+	server := &fakeHostClient{
+		handler: runForever(true),
+		probe: func(ctx context.Context, command string) (*ssh.Result, error) {
+			return &ssh.Result{ExitCode: 1, Error: "Process exited with status 1"}, fmt.Errorf("Process exited with status 1")
+		},
+	}
+	coord := newTestCoordinator([]config.TestScenario{test}, map[string]*fakeHostClient{
+		"client": {handler: succeed("done")},
+		"server": server,
+	})
+
+	_, err := coord.RunTest(context.Background(), &test)
+	if err == nil {
+		t.Fatal("Expected the missing binary to fail the scenario")
+	}
+	if classifyError(err) != ErrorDeterministic {
+		t.Errorf("Expected a deterministic error, got %v", err)
+	}
+	if len(server.probes) != 1 {
+		t.Errorf("Expected a single attempt, got %d binary checks", len(server.probes))
+	}
+}
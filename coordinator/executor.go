@@ -2,7 +2,13 @@ package coordinator
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"net"
+	"regexp"
+	"strings"
 	"time"
 
 	"perf-runner/config"
@@ -11,14 +17,226 @@ import (
 	"perf-runner/ssh"
 )
 
+const (
+	// defaultStartupDelay and defaultShutdownGrace apply when the config's
+	// node_startup_delay and intermediate_cleanup_timeout are not set
+	defaultStartupDelay  = 2 * time.Second
+	defaultShutdownGrace = 5 * time.Second
+	// oneShotServerGrace is the extra time a one-shot server has to exit
+	// after a successful client before it is stopped
+	oneShotServerGrace = 10 * time.Second
+	
+	// defaultEnvRetries is how many more times a failed environment
+	// collection is tried when env_retries is not set
+	defaultEnvRetries = 2
+	// defaultEnvRetryDelay is the wait before the first environment retry;
+	// it doubles with each further retry
+	defaultEnvRetryDelay = 1 * time.Second
+)
+
+// errTestTimedOut is returned when the scenario context expires while waiting for a role
+var errTestTimedOut = errors.New("test timed out")
+
 // TestExecutor handles execution of individual test scenarios
 type TestExecutor struct {
 	coordinator *Coordinator
+	
+	// startupDelay is how long to wait after starting a server/intermediate node
+	startupDelay time.Duration
+	// shutdownGrace is how long background roles may keep running after the
+	// client finishes before they are terminated deliberately
+	shutdownGrace time.Duration
+	// sampleInterval is how often hosts are sampled during a run
+	sampleInterval time.Duration
+	// envRetryDelay is the wait before retrying a failed environment collection
+	envRetryDelay time.Duration
+	// stopTimeout bounds the commands that reap background roles
+	stopTimeout time.Duration
+	// loadTimeout bounds reading the hosts' load before each test
+	loadTimeout time.Duration
+	// parser replaces the runner's ParseMetrics for the scenario being run;
+	// nil uses the runner's own
+	parser runner.Parser
+	// affinity reads back the CPU placement of the scenario's pinned roles
+	affinity *affinityMonitor
 }
 
-// NewTestExecutor creates a new test executor
+// NewTestExecutor creates a new test executor, taking the startup delay and
+// shutdown grace from the coordinator's config when set
 func NewTestExecutor(coord *Coordinator) *TestExecutor {
-	return &TestExecutor{coordinator: coord}
+	executor := &TestExecutor{
+		coordinator:    coord,
+		startupDelay:   defaultStartupDelay,
+		shutdownGrace:  defaultShutdownGrace,
+		sampleInterval: defaultSampleInterval,
+		envRetryDelay:  defaultEnvRetryDelay,
+		stopTimeout:    defaultStopTimeout,
+		loadTimeout:    defaultLoadTimeout,
+	}
+	if delay := coord.config.NodeStartupDelay; delay != nil {
+		executor.startupDelay = *delay
+	}
+	if grace := coord.config.IntermediateCleanupTimeout; grace > 0 {
+		executor.shutdownGrace = grace
+	}
+	return executor
+}
+
+// backgroundRole tracks a role command (server or intermediate) running in the
+// background so the executor can stop it once the client has finished
+type backgroundRole struct {
+	done    chan *runner.Result
+	err     chan error
+	cancel  context.CancelFunc
+	exited  chan struct{} // Closed once the role's command has returned
+	client  HostClient
+	config  *runner.Config
+	pidFile string // Remote file holding the PID of the role's command
+}
+
+// startBackgroundRole launches a role command under its own cancelable
+// context, recording its PID so the role can be reaped with stopRole
+func (e *TestExecutor) startBackgroundRole(ctx context.Context, client HostClient, host string, r runner.Runner, config *runner.Config, outputLog string) *backgroundRole {
+	roleCtx, cancel := context.WithCancel(ctx)
+	role := &backgroundRole{
+		done:    make(chan *runner.Result, 1),
+		err:     make(chan error, 1),
+		cancel:  cancel,
+		exited:  make(chan struct{}),
+		client:  client,
+		config:  config,
+		pidFile: remotePIDFile(config.Role),
+	}
+	
+	go func() {
+		defer close(role.exited)
+		roleResult, err := e.runRemoteCommand(roleCtx, client, host, r, config, outputLog, role.pidFile)
+		if err != nil {
+			role.err <- err
+			return
+		}
+		role.done <- roleResult
+	}()
+	
+	return role
+}
+
+// wait waits up to grace for the role to complete on its own. If it is still
+// running afterwards, it is terminated and its result is marked TerminatedByUs.
+// A non-positive grace waits for the role to exit until ctx expires.
+func (b *backgroundRole) wait(ctx context.Context, grace time.Duration) (*runner.Result, error) {
+	defer b.cancel()
+	
+	var expired <-chan time.Time
+	if grace > 0 {
+		expired = time.After(grace)
+	}
+	
+	select {
+	case roleResult := <-b.done:
+		return roleResult, nil
+	case err := <-b.err:
+		return nil, err
+	case <-ctx.Done():
+		return nil, errTestTimedOut
+	case <-expired:
+	}
+	
+	// Still running: stop it deliberately
+	b.cancel()
+	
+	select {
+	case roleResult := <-b.done:
+		roleResult.TerminatedByUs = true
+		return roleResult, nil
+	case <-b.err:
+		// The command was cut off before reporting an exit status
+	}
+	
+	now := time.Now()
+	return &runner.Result{
+		Error:          "terminated by coordinator after client completed",
+		ExitCode:       -1,
+		StartTime:      now,
+		EndTime:        now,
+		Metrics:        make(map[string]interface{}),
+		TerminatedByUs: true,
+	}, nil
+}
+
+// serverGrace returns how long the server may run after the client finishes.
+// A one-shot server exits on its own once its client is done, so it is given
+// longer to report, bounded by oneShotServerGrace plus the scenario's
+// readiness timeout. A client that failed may never have connected, leaving
+// a one-shot server waiting forever, so it is stopped like a persistent one.
+func (e *TestExecutor) serverGrace(r runner.Runner, test *config.TestScenario, clientResult *runner.Result) time.Duration {
+	if r.ServerMode() == runner.ServerOneShot && clientResult != nil && clientResult.Success {
+		return e.shutdownGrace + oneShotServerGrace + test.ServerReadyTimeout
+	}
+	return e.shutdownGrace
+}
+
+// roleSucceeded reports whether a non-client role result counts as successful.
+// A missing result or one we terminated deliberately is not a failure.
+func roleSucceeded(roleResult *runner.Result) bool {
+	return roleResult == nil || roleResult.Success || roleResult.TerminatedByUs
+}
+
+// applyOutputPatterns overrides role success using the scenario's
+// success_pattern and failure_pattern, for tools that exit 0 on error
+func applyOutputPatterns(test *config.TestScenario, result *TestResult) error {
+	if test.SuccessPattern == "" && test.FailurePattern == "" {
+		return nil
+	}
+	
+	var successRe, failureRe *regexp.Regexp
+	var err error
+	if test.SuccessPattern != "" {
+		if successRe, err = regexp.Compile(test.SuccessPattern); err != nil {
+			return fmt.Errorf("invalid success_pattern: %w", err)
+		}
+	}
+	if test.FailurePattern != "" {
+		if failureRe, err = regexp.Compile(test.FailurePattern); err != nil {
+			return fmt.Errorf("invalid failure_pattern: %w", err)
+		}
+	}
+	
+	if client := result.ClientResult; client != nil && successRe != nil {
+		client.Success = successRe.MatchString(client.Output)
+		if !client.Success && client.Error == "" {
+			client.Error = fmt.Sprintf("output did not match success_pattern %q", test.SuccessPattern)
+		}
+	}
+	
+	if failureRe != nil {
+		type roleOutput struct {
+			name   string
+			result *runner.Result
+		}
+		roles := []roleOutput{
+			{"client", result.ClientResult},
+			{"server", result.ServerResult},
+		}
+		for _, node := range result.NodeResults {
+			if node.Role == "intermediate" {
+				roles = append(roles, roleOutput{node.Role, node.Result})
+			}
+		}
+		for _, role := range roles {
+			if role.result == nil || !failureRe.MatchString(role.result.Output) {
+				continue
+			}
+			role.result.Success = false
+			role.result.Error = fmt.Sprintf("output matched failure_pattern %q", test.FailurePattern)
+			// A deliberately terminated role is otherwise treated as successful
+			if result.Error == "" {
+				result.Error = fmt.Sprintf("%s %s", role.name, role.result.Error)
+			}
+		}
+	}
+	
+	return nil
 }
 
 // ExecuteTest runs a single test scenario
@@ -28,254 +246,421 @@ func (e *TestExecutor) ExecuteTest(ctx context.Context, test *config.TestScenari
 	result := &TestResult{
 		ScenarioName: test.Name,
 		StartTime:    startTime,
+		Hosts: map[string]string{
+			"client": test.Client,
+		},
+	}
+	if test.Server != "" {
+		result.Hosts["server"] = test.Server
+	}
+	for i, name := range test.Relays() {
+		result.Hosts[relayRole(i)] = name
 	}
 	
 	// Get runner
-	r, exists := e.coordinator.runners[e.coordinator.config.Runner]
+	runnerName := e.coordinator.config.GetRunner(test)
+	r, exists := e.coordinator.runners[runnerName]
 	if !exists {
-		return nil, fmt.Errorf("runner %s not found", e.coordinator.config.Runner)
+		return nil, fmt.Errorf("runner %s not found", runnerName)
 	}
+	result.Runner = runnerName
+	result.ComparisonGroup = test.ComparisonGroup
+	
+	// A configured parser replaces the runner's built-in metric parsing
+	e.parser = nil
+	if parserName := e.coordinator.config.GetParser(test); parserName != "" {
+		parser, err := runner.GetParser(parserName)
+		if err != nil {
+			return nil, err
+		}
+		e.parser = parser
+	}
+	result.PrimaryMetric = r.PrimaryMetric()
+	
+	// Single-node runners, such as fio, run on the client alone
+	serverless := !r.SupportsRole("server")
 	
 	// Get host configurations
 	clientHost := e.coordinator.config.GetClientHost(test)
 	serverHost := e.coordinator.config.GetServerHost(test)
-	intermediateHost := e.coordinator.config.GetIntermediateHost(test)
 	
 	if clientHost == nil {
 		return nil, fmt.Errorf("client host %s not found", test.Client)
 	}
-	if serverHost == nil {
+	if serverHost == nil && !serverless {
 		return nil, fmt.Errorf("server host %s not found", test.Server)
 	}
 	
 	// Get SSH clients
-	clientSSH := e.coordinator.sshClients[test.Client]
-	serverSSH := e.coordinator.sshClients[test.Server]
-	var intermediateSSH *ssh.Client
+	clientSSH := e.coordinator.hostClient(test.Client)
+	var serverSSH HostClient
+	if !serverless {
+		serverSSH = e.coordinator.hostClient(test.Server)
+	}
 	
 	if clientSSH == nil {
 		return nil, fmt.Errorf("SSH client for host %s not connected", test.Client)
 	}
-	if serverSSH == nil {
+	if serverSSH == nil && !serverless {
 		return nil, fmt.Errorf("SSH client for host %s not connected", test.Server)
 	}
 	
-	// Check intermediate node if specified
-	if e.coordinator.config.HasIntermediateNode(test) {
-		if intermediateHost == nil {
-			return nil, fmt.Errorf("intermediate host %s not found", test.Intermediate)
+	// Hosts relaying traffic between client and server, in traffic order
+	var relays []*relayNode
+	for _, name := range test.Relays() {
+		relayHost := e.coordinator.config.Hosts[name]
+		if relayHost == nil {
+			return nil, fmt.Errorf("intermediate host %s not found", name)
 		}
-		intermediateSSH = e.coordinator.sshClients[test.Intermediate]
-		if intermediateSSH == nil {
-			return nil, fmt.Errorf("SSH client for intermediate host %s not connected", test.Intermediate)
+		relaySSH := e.coordinator.hostClient(name)
+		if relaySSH == nil {
+			return nil, fmt.Errorf("SSH client for intermediate host %s not connected", name)
 		}
+		relays = append(relays, &relayNode{name: name, host: relayHost, client: relaySSH, target: relayHost.SSH.Host})
 	}
 	
 	// Prepare runner configurations
-	serverConfig := e.coordinator.config.MergeRunnerConfig(serverHost.Runner, test.Config)
+	var serverRunnerConfig *runner.Config
+	var serverAddress string
+	if !serverless {
+		serverRunnerConfig, serverAddress = serverHost.Runner, serverHost.SSH.Host
+	}
+	serverConfig := e.coordinator.config.MergeRunnerConfig(serverRunnerConfig, test.Config)
 	serverConfig.Role = "server"
 	
 	clientConfig := e.coordinator.config.MergeRunnerConfig(clientHost.Runner, test.Config)
 	clientConfig.Role = "client"
 	
-	var intermediateConfig *runner.Config
+	// The client may reach the server through a forwarded port
+	serverConfig.Port = serverConfig.GetEffectivePort()
+	clientConfig.Port = clientConfig.GetEffectivePort()
 	
-	// Configure connection topology based on intermediate node presence
-	if e.coordinator.config.HasIntermediateNode(test) {
-		// 3-node topology: Client → Intermediate → Server
-		intermediateConfig = e.coordinator.config.MergeRunnerConfig(intermediateHost.Runner, test.Config)
-		intermediateConfig.Role = "intermediate"
-		
-		// Intermediate connects to server
-		intermediateConfig.Host = serverHost.SSH.Host
-		if intermediateConfig.TargetHost == "" {
-			intermediateConfig.TargetHost = serverHost.SSH.Host
+	// Create context with timeout, the scenario's own when it sets one
+	timeout := e.coordinator.config.ScenarioTimeout(test)
+	timeoutSource := "global"
+	if test.Timeout > 0 {
+		timeoutSource = "scenario"
+	}
+	e.coordinator.logger.Printf("  Timeout: %v (%s)", timeout, timeoutSource)
+	testCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	
+	// Addresses targeted by the client and relays: the SSH address, unless a
+	// data-plane subnet selects another interface
+	serverTarget := serverAddress
+	var subnet *net.IPNet
+	if test.DataPlaneSubnet != "" {
+		var err error
+		if _, subnet, err = net.ParseCIDR(test.DataPlaneSubnet); err != nil {
+			return nil, fmt.Errorf("invalid data_plane_subnet %s: %w", test.DataPlaneSubnet, err)
 		}
-		
-		// Client connects to intermediate
-		clientConfig.Host = intermediateHost.SSH.Host
-		if clientConfig.TargetHost == "" {
-			clientConfig.TargetHost = intermediateHost.SSH.Host
+		if !serverless {
+			if serverTarget, err = e.dataPlaneAddress(testCtx, test.Server, serverSSH, subnet); err != nil {
+				return nil, err
+			}
 		}
-	} else {
-		// 2-node topology: Client → Server (original behavior)
-		clientConfig.Host = serverHost.SSH.Host
-		if clientConfig.TargetHost == "" {
-			clientConfig.TargetHost = serverHost.SSH.Host
+		for _, relay := range relays {
+			if relay.target, err = e.dataPlaneAddress(testCtx, relay.name, relay.client, subnet); err != nil {
+				return nil, err
+			}
 		}
 	}
 	
-	// Create context with timeout
-	testCtx, cancel := context.WithTimeout(ctx, e.coordinator.config.Timeout)
-	defer cancel()
+	// Wire each hop to the next one toward the server:
+	// Client → relays... → Server
+	nextHost, nextTarget := serverAddress, serverTarget
+	for i := len(relays) - 1; i >= 0; i-- {
+		relay := relays[i]
+		relay.config = e.coordinator.config.MergeRunnerConfig(relay.host.Runner, test.Config)
+		relay.config.Role = "intermediate"
+		relay.config.Host = nextHost
+		if relay.config.TargetHost == "" {
+			relay.config.TargetHost = nextTarget
+		}
+		nextHost, nextTarget = relay.host.SSH.Host, relay.target
+	}
+	clientConfig.Host = nextHost
+	if clientConfig.TargetHost == "" {
+		clientConfig.TargetHost = nextTarget
+	}
 	
-	// Execute the test based on topology
-	if e.coordinator.config.HasIntermediateNode(test) {
-		// 3-node topology
-		if err := e.executeThreeNodeTest(testCtx, r, clientSSH, intermediateSSH, serverSSH, clientConfig, intermediateConfig, serverConfig, result, test); err != nil {
+	// Pin target names to one address for the whole run
+	if e.coordinator.config.ResolveHosts != "" {
+		var err error
+		if clientConfig.TargetHost, err = e.pinAddress(testCtx, clientConfig.TargetHost, clientSSH, result); err != nil {
 			return nil, err
 		}
-	} else {
-		// 2-node topology (original)
-		if err := e.executeClientServerTest(testCtx, r, clientSSH, serverSSH, clientConfig, serverConfig, result, test); err != nil {
-			return nil, err
+		for _, relay := range relays {
+			if relay.config.TargetHost, err = e.pinAddress(testCtx, relay.config.TargetHost, relay.client, result); err != nil {
+				return nil, err
+			}
 		}
 	}
 	
-	// Collect environment information if requested
-	if e.coordinator.collectEnv {
-		if err := e.collectEnvironmentInfo(testCtx, result, test, clientSSH, serverSSH, intermediateSSH); err != nil {
-			e.coordinator.logger.Printf("Warning: failed to collect environment info: %v", err)
+	// Expand {{.ServerIP}}, {{.ScenarioName}}, and {{.Iteration}} in args
+	vars := runner.TemplateVars{ServerIP: serverTarget, ScenarioName: test.Name, Iteration: test.Iteration}
+	if len(relays) > 0 {
+		vars.ServerIP = relays[len(relays)-1].config.TargetHost
+	} else if clientConfig.TargetHost != "" {
+		vars.ServerIP = clientConfig.TargetHost
+	}
+	if vars.Iteration == 0 {
+		vars.Iteration = 1
+	}
+	roleConfigs := []*runner.Config{clientConfig, serverConfig}
+	for _, relay := range relays {
+		roleConfigs = append(roleConfigs, relay.config)
+	}
+	for _, roleConfig := range roleConfigs {
+		expanded, err := runner.ExpandTemplates(*roleConfig, vars)
+		if err != nil {
+			return nil, &ClassifiedError{Class: ErrorDeterministic, Err: err}
 		}
+		*roleConfig = expanded
 	}
 	
-	result.EndTime = time.Now()
-	result.Duration = result.EndTime.Sub(result.StartTime)
-	result.Success = result.ClientResult != nil && result.ClientResult.Success && 
-		(result.ServerResult == nil || result.ServerResult.Success) &&
-		(result.IntermediateResult == nil || result.IntermediateResult.Success) &&
-		result.Error == ""
+	// Tools that flag the protocol on both ends fail silently on a mismatch
+	if err := runner.ProtocolMismatch(r, *clientConfig, *serverConfig); err != nil {
+		e.addWarning(result, fmt.Sprintf("protocol mismatch: %v", err))
+	}
 	
-	return result, nil
-}
-
-// executeClientServerTest handles the coordination between client and server
-func (e *TestExecutor) executeClientServerTest(
-	ctx context.Context,
-	r runner.Runner,
-	clientSSH, serverSSH *ssh.Client,
-	clientConfig, serverConfig *runner.Config,
-	result *TestResult,
-	test *config.TestScenario,
-) error {
-	// Build commands for display using runner's own method
-	result.ServerCommand = r.BuildCommand(*serverConfig)
-	result.ClientCommand = r.BuildCommand(*clientConfig)
-	
-	// Start server first
-	e.coordinator.logger.Printf("  Starting server on %s", test.Server)
-	serverDone := make(chan *runner.Result, 1)
-	serverErr := make(chan error, 1)
+	// Additional fan-out clients share the primary client's target, so the
+	// server must serve them all at once
+	if len(test.Clients) > 0 && !runner.ServesMultipleClients(r) {
+		return nil, &ClassifiedError{Class: ErrorDeterministic, Err: fmt.Errorf("the %s server serves one client at a time, so it cannot take fan-out clients", r.Name())}
+	}
+	fanOut, err := e.fanOutClients(test, clientConfig)
+	if err != nil {
+		return nil, err
+	}
 	
-	go func() {
-		serverResult, err := e.runRemoteCommand(ctx, serverSSH, r, serverConfig)
-		if err != nil {
-			serverErr <- err
-			return
+	// Resolve gid_index: auto to each host's RoCEv2 GID on its data-plane address
+	var gidHosts []gidHost
+	if !serverless {
+		gidHosts = append(gidHosts, gidHost{test.Server, serverSSH, serverConfig, serverTarget})
+	}
+	gidHosts = append(gidHosts, gidHost{test.Client, clientSSH, clientConfig, clientHost.SSH.Host})
+	for _, relay := range relays {
+		gidHosts = append(gidHosts, gidHost{relay.name, relay.client, relay.config, relay.target})
+	}
+	for _, c := range fanOut {
+		gidHosts = append(gidHosts, gidHost{c.name, c.client, c.config, e.coordinator.config.Hosts[c.name].SSH.Host})
+	}
+	for _, h := range gidHosts {
+		if err := e.resolveGIDIndex(testCtx, h, subnet); err != nil {
+			return nil, err
 		}
-		serverDone <- serverResult
-	}()
-	
-	// Wait a bit for server to start
-	time.Sleep(2 * time.Second)
+	}
 	
-	// Start client
-	e.coordinator.logger.Printf("  Starting client on %s", test.Client)
-	clientResult, err := e.runRemoteCommand(ctx, clientSSH, r, clientConfig)
-	if err != nil {
-		return fmt.Errorf("client execution failed: %w", err)
+	var hosts []testHost
+	if !serverless {
+		hosts = append(hosts, testHost{role: "server", name: test.Server, client: serverSSH, port: serverConfig.Port, config: serverConfig})
+	}
+	hosts = append(hosts, testHost{role: "client", name: test.Client, client: clientSSH, config: clientConfig})
+	for _, c := range fanOut {
+		hosts = append(hosts, testHost{role: "client", name: c.name, client: c.client, config: c.config})
+	}
+	for _, relay := range relays {
+		hosts = append(hosts, testHost{role: "intermediate", name: relay.name, client: relay.client, config: relay.config})
 	}
 	
-	result.ClientResult = clientResult
+	// Fail fast if the tool is missing anywhere rather than mid-test
+	if err := e.validateRemoteBinaries(testCtx, r, hosts); err != nil {
+		return nil, err
+	}
 	
-	// Wait for server to complete or timeout
-	select {
-	case serverResult := <-serverDone:
-		result.ServerResult = serverResult
-	case err := <-serverErr:
-		result.Error = fmt.Sprintf("server execution failed: %v", err)
-	case <-ctx.Done():
-		result.Error = "test timed out"
+	// Different tool versions on the two ends can skew numbers or break the protocol
+	if versions := e.detectToolVersions(testCtx, r, hosts); len(versions) > 0 {
+		result.ToolVersions = versions
+		if mismatch := versionMismatch(r.Name(), versions); mismatch != "" {
+			if test.StrictVersions {
+				return nil, &ClassifiedError{Class: ErrorDeterministic, Err: errors.New(mismatch)}
+			}
+			e.addWarning(result, mismatch)
+		}
 	}
 	
-	return nil
-}
-
-// executeThreeNodeTest handles the coordination between client, intermediate, and server
-func (e *TestExecutor) executeThreeNodeTest(
-	ctx context.Context,
-	r runner.Runner,
-	clientSSH, intermediateSSH, serverSSH *ssh.Client,
-	clientConfig, intermediateConfig, serverConfig *runner.Config,
-	result *TestResult,
-	test *config.TestScenario,
-) error {
-	// Build commands for display
-	result.ServerCommand = r.BuildCommand(*serverConfig)
-	result.ClientCommand = r.BuildCommand(*clientConfig)
-	result.IntermediateCommand = r.BuildCommand(*intermediateConfig)
-	
-	// Start server first
-	e.coordinator.logger.Printf("  Starting server on %s", test.Server)
-	serverDone := make(chan *runner.Result, 1)
-	serverErr := make(chan error, 1)
+	// Record what each role runs and where for auditing
+	result.Audit = e.auditRecords(r, hosts, result.ToolVersions)
 	
-	go func() {
-		serverResult, err := e.runRemoteCommand(ctx, serverSSH, r, serverConfig)
-		if err != nil {
-			serverErr <- err
-			return
+	// A data-plane link that is down fails in confusing ways, so abort early
+	if subnet != nil {
+		for _, host := range hosts {
+			if err := e.checkDataPlaneLinks(testCtx, host, subnet); err != nil {
+				return nil, err
+			}
 		}
-		serverDone <- serverResult
-	}()
+	}
 	
-	// Wait for server to start
-	time.Sleep(2 * time.Second)
+	// Background load skews any result, so it is recorded even without
+	// collect_env
+	e.recordLoad(testCtx, result, hosts)
 	
-	// Start intermediate node
-	e.coordinator.logger.Printf("  Starting intermediate node on %s", test.Intermediate)
-	intermediateDone := make(chan *runner.Result, 1)
-	intermediateErr := make(chan error, 1)
+	// Inspect participating hosts before launching anything
+	if e.coordinator.collectEnv {
+		e.runPreflightChecks(testCtx, result, hosts, subnet)
+	}
 	
-	go func() {
-		intermediateResult, err := e.runRemoteCommand(ctx, intermediateSSH, r, intermediateConfig)
-		if err != nil {
-			intermediateErr <- err
-			return
-		}
-		intermediateDone <- intermediateResult
-	}()
+	// Sample the hosts throughout the run, each over one session
+	samplers := e.newHostSamplers()
+	defer samplers.stop(ctx)
 	
-	// Wait for intermediate to establish connection to server
-	time.Sleep(2 * time.Second)
+	// Count link flaps over the run, which explain sporadic throughput dips
+	var carrier map[string]*deltaCollector
+	if e.coordinator.collectEnv {
+		carrier = monitorCarrierChanges(samplers, hosts)
+	}
 	
-	// Start client (connects to intermediate)
-	e.coordinator.logger.Printf("  Starting client on %s", test.Client)
-	clientResult, err := e.runRemoteCommand(ctx, clientSSH, r, clientConfig)
-	if err != nil {
-		return fmt.Errorf("client execution failed: %w", err)
+	// Sample per-queue RX counters to check how RSS spreads the load
+	var queues map[string]*deltaCollector
+	if len(test.QueueStats) > 0 {
+		queues = monitorQueueStats(samplers, hosts, test.QueueStats)
 	}
 	
-	result.ClientResult = clientResult
+	// Sample NUMA locality for runners sensitive to it
+	var numa map[string]*deltaCollector
+	if numaSampledRunners[r.Name()] {
+		numa = monitorNuma(samplers, hosts)
+	}
 	
-	// Wait for intermediate and server to complete or timeout
-	select {
-	case serverResult := <-serverDone:
-		result.ServerResult = serverResult
-	case err := <-serverErr:
-		result.Error = fmt.Sprintf("server execution failed: %v", err)
-	case <-ctx.Done():
-		result.Error = "test timed out"
+	// Sample thermal state; throttling invalidates results
+	var thermal *thermalMonitor
+	if test.ThermalCheck || test.StrictThermal {
+		thermal = monitorThermal(samplers, hosts)
 	}
 	
-	// Collect intermediate result
-	select {
-	case intermediateResult := <-intermediateDone:
-		result.IntermediateResult = intermediateResult
-	case err := <-intermediateErr:
-		if result.Error == "" {
-			result.Error = fmt.Sprintf("intermediate execution failed: %v", err)
+	// Check the congestion control the clients' connections use and where
+	// pinned roles actually run
+	congestion := monitorCongestionControl(samplers, hosts)
+	e.affinity = monitorAffinity(samplers, hosts)
+	
+	samplers.start(testCtx)
+	
+	// Copy files the scenario needs, such as tuning scripts, onto its hosts
+	if err := e.uploadFiles(testCtx, test); err != nil {
+		return nil, err
+	}
+	
+	// Remove state the tool leaves behind once every role has finished,
+	// even if the test failed
+	defer e.cleanupRoles(ctx, r, hosts)
+	
+	// Fetch the files the run produced, such as captures, even if it failed
+	defer e.downloadFiles(ctx, result, test)
+	
+	// Execute the test from the server end of the chain toward the client
+	if err := e.executeChainTest(testCtx, r, clientSSH, serverSSH, clientConfig, serverConfig, relays, fanOut, result, test); err != nil {
+		return nil, err
+	}
+	
+	result.CommandHashes = commandHashes(result)
+	
+	samplers.stop(testCtx)
+	if len(numa) > 0 {
+		before, after := e.numaSamples(numa)
+		recordNumaDeltas(result, before, after)
+	}
+	if len(carrier) > 0 {
+		before, after := carrierChanges(carrier)
+		e.recordCarrierTransitions(result, before, after)
+	}
+	if thermal != nil {
+		thermal.record(e, result, test.StrictThermal)
+	}
+	if len(queues) > 0 {
+		before, after := queueStats(queues)
+		e.recordQueueStats(result, test.QueueStats, before, after)
+	}
+	e.recordCongestionControls(result, congestion)
+	e.affinity.record(e, result)
+	
+	// Collect environment information if requested
+	if e.coordinator.collectEnv {
+		if err := e.collectEnvironmentInfo(testCtx, result, test, clientSSH, serverSSH, relays); err != nil {
+			e.coordinator.logger.Printf("Warning: failed to collect environment info: %v", err)
 		}
-	case <-time.After(5 * time.Second):
-		// Give intermediate a bit more time to clean up
-		e.coordinator.logger.Printf("  Warning: intermediate node did not complete within timeout")
 	}
 	
-	return nil
+	if err := applyOutputPatterns(test, result); err != nil {
+		return nil, err
+	}
+	
+	// A run that "succeeds" while measuring nothing usually means traffic
+	// never flowed (firewall, wrong target, link down)
+	if result.ClientResult != nil && result.ClientResult.Success {
+		if value, ok := result.PrimaryValue(); ok && value == 0 {
+			e.addWarning(result, fmt.Sprintf("client reported zero %s", result.PrimaryMetric))
+		}
+	}
+	
+	// A server stream limit can reject some of the requested parallel
+	// streams, lowering throughput without failing the run
+	if result.ClientResult != nil {
+		requested, _ := result.ClientResult.Metrics["streams_requested"].(int)
+		actual, ok := result.ClientResult.Metrics["streams_actual"].(int)
+		if ok && requested > 0 && actual != requested {
+			e.addWarning(result, fmt.Sprintf("client established %d of %d requested streams", actual, requested))
+		}
+	}
+	
+	result.EndTime = time.Now()
+	result.Duration = result.EndTime.Sub(result.StartTime)
+	result.Success = result.ClientResult != nil && result.ClientResult.Success && 
+		roleSucceeded(result.ServerResult) &&
+		relaysSucceeded(result) &&
+		fanOutSucceeded(test, result.ClientResults) &&
+		result.Error == ""
+	
+	return result, nil
+}
+
+// commandHashes returns a short hash of each role's command so runs whose
+// invocation silently changed are easy to spot. Every relay of a chain is
+// hashed under its role, e.g. "intermediate2".
+func commandHashes(result *TestResult) map[string]string {
+	hashes := make(map[string]string)
+	for role, command := range map[string]string{
+		"client": result.ClientCommand,
+		"server": result.ServerCommand,
+	} {
+		if command != "" {
+			hashes[role] = commandHash(command)
+		}
+	}
+	
+	relays := 0
+	for _, node := range result.NodeResults {
+		if node.Role != "intermediate" {
+			continue
+		}
+		if node.Command != "" {
+			hashes[relayRole(relays)] = commandHash(node.Command)
+		}
+		relays++
+	}
+	if relays == 0 && result.IntermediateCommand != "" {
+		hashes["intermediate"] = commandHash(result.IntermediateCommand)
+	}
+	return hashes
+}
+
+// commandHash returns the first 12 hex digits of the command's SHA-256
+func commandHash(command string) string {
+	sum := sha256.Sum256([]byte(command))
+	return hex.EncodeToString(sum[:])[:12]
+}
+
+// roleErrorMessage formats a background role failure for TestResult.Error
+func roleErrorMessage(role string, err error) string {
+	if errors.Is(err, errTestTimedOut) {
+		return err.Error()
+	}
+	return fmt.Sprintf("%s execution failed: %v", role, err)
 }
 
 // runRemoteCommand executes a runner command on a remote host via SSH
-func (e *TestExecutor) runRemoteCommand(ctx context.Context, sshClient *ssh.Client, r runner.Runner, config *runner.Config) (*runner.Result, error) {
+// A pidFile, if given, receives the PID of the command's process group.
+func (e *TestExecutor) runRemoteCommand(ctx context.Context, sshClient HostClient, host string, r runner.Runner, config *runner.Config, outputLog, pidFile string) (*runner.Result, error) {
 	// Validate configuration
 	if err := r.Validate(*config); err != nil {
 		return nil, fmt.Errorf("configuration validation failed: %w", err)
@@ -284,73 +669,188 @@ func (e *TestExecutor) runRemoteCommand(ctx context.Context, sshClient *ssh.Clie
 	// Build command for remote execution using runner's own method
 	command := r.BuildCommand(*config)
 	
-	// Display command before execution
-	e.coordinator.logger.Printf("  Executing command on %s: %s", config.Role, command)
+	// Display command before execution, secrets masked
+	e.coordinator.logger.Printf("  Executing command on %s: %s", config.Role, displayCommand(r, *config))
 	
-	// Execute command via SSH
-	sshResult, err := sshClient.ExecuteCommand(ctx, command)
-	if err != nil {
+	// Copy stdout to a file a readiness check can search while it runs
+	if outputLog != "" {
+		command = teeOutput(command, outputLog)
+	}
+	
+	// Pin to cpu_affinity, recording the PID the host's sampler reads the
+	// achieved placement back through
+	if config.CPUAffinity != "" {
+		command = wrapWithAffinity(command, config.CPUAffinity, e.affinity.pidFile(config))
+	}
+	
+	if pidFile != "" {
+		command = recordPID(command, pidFile)
+	}
+	
+	// Execute command via SSH. A command that ran but exited non-zero still
+	// returns a result; only a missing result is an execution failure.
+	startTime := time.Now()
+	sshResult, err := executeStream(ctx, sshClient, command, e.coordinator.roleOutputLog(host, config.Role))
+	if sshResult == nil {
 		return nil, fmt.Errorf("SSH command execution failed: %w", err)
 	}
 	
 	// Convert SSH result to runner result
 	runnerResult := &runner.Result{
-		Success:   sshResult.ExitCode == 0,
+		Success:   err == nil && sshResult.ExitCode == 0,
 		Output:    sshResult.Output,
 		Error:     sshResult.Error,
 		ExitCode:  sshResult.ExitCode,
-		StartTime: time.Now(), // Approximate
-		EndTime:   time.Now(), // Approximate
+		StartTime: startTime,
+		EndTime:   time.Now(),
 		Metrics:   make(map[string]interface{}),
 	}
 	
-	// Parse metrics from command output
-	if err := r.ParseMetrics(runnerResult); err != nil {
+	// The tool's own diagnostics explain a failure better than the exit status
+	if !runnerResult.Success {
+		if tail := stderrTail(sshResult.Stderr, stderrTailLines); tail != "" {
+			runnerResult.Error = errorWithStderr(tail, sshResult.Error)
+		}
+	}
+	
+	// Parse metrics from the stream the runner reads; the result keeps the
+	// combined output for display
+	parsed := *runnerResult
+	parsed.Output = streamOutput(r.OutputStream(), sshResult)
+	parse := r.ParseMetrics
+	if e.parser != nil {
+		parse = e.parser
+	}
+	if err := parse(&parsed); err != nil {
 		e.coordinator.logger.Printf("  Warning: failed to parse metrics: %v", err)
 		// Continue execution - metrics parsing failure shouldn't fail the test
 	}
+	runnerResult.Metrics = parsed.Metrics
 	
 	return runnerResult, nil
 }
 
-// collectEnvironmentInfo gathers environment information from all hosts
-func (e *TestExecutor) collectEnvironmentInfo(ctx context.Context, result *TestResult, test *config.TestScenario, clientSSH, serverSSH, intermediateSSH *ssh.Client) error {
+// stderrTailLines is how many trailing stderr lines a failure error quotes
+const stderrTailLines = 3
+
+// stderrTail returns the last n non-empty lines of stderr joined with "; "
+func stderrTail(stderr string, n int) string {
+	var lines []string
+	for _, line := range strings.Split(stderr, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "; ")
+}
+
+// errorWithStderr leads a failure error with the stderr tail, keeping the
+// SSH error (usually the exit status) for context
+func errorWithStderr(tail, sshError string) string {
+	if sshError == "" {
+		return tail
+	}
+	return fmt.Sprintf("%s (%s)", tail, sshError)
+}
+
+// streamOutput returns the requested stream of a command's output, falling
+// back to the combined output when the streams were not captured separately
+func streamOutput(stream runner.Stream, sshResult *ssh.Result) string {
+	if sshResult.Stdout == "" && sshResult.Stderr == "" {
+		return sshResult.Output
+	}
+	switch stream {
+	case runner.StreamStdout:
+		return sshResult.Stdout
+	case runner.StreamStderr:
+		return sshResult.Stderr
+	}
+	return sshResult.Output
+}
+
+// collectHostEnvironment collects a host's environment, reusing the result
+// from an earlier scenario in this run when caching is enabled
+func (e *TestExecutor) collectHostEnvironment(ctx context.Context, hostName string, client HostClient) (*envinfo.EnvironmentInfo, error) {
+	runnerName := e.coordinator.config.Runner
+	if envInfo, ok := e.coordinator.cache.environment(hostName, runnerName); ok {
+		return envInfo, nil
+	}
+	
+	// Collection is cheap next to the benchmark, so a flaky probe is retried
+	// on its own instead of failing or re-running the test
+	retries := defaultEnvRetries
+	if configured := e.coordinator.config.EnvRetries; configured != nil {
+		retries = *configured
+	}
+	delay := e.envRetryDelay
+	for attempt := 0; ; attempt++ {
+		envInfo, err := envinfo.NewCollector(client).Collect(ctx)
+		if err == nil {
+			e.coordinator.cache.storeEnvironment(hostName, runnerName, envInfo)
+			return envInfo, nil
+		}
+		if attempt >= retries || ctx.Err() != nil {
+			return nil, err
+		}
+		
+		e.coordinator.logger.Printf("  Environment collection on %s failed (%v), retrying (%d/%d)", hostName, err, attempt+1, retries)
+		select {
+		case <-ctx.Done():
+			return nil, err
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+// collectEnvironmentInfo gathers environment information from every host of
+// the topology: the client, the server, and each relay of a chain. Hosts
+// without a client are skipped.
+func (e *TestExecutor) collectEnvironmentInfo(ctx context.Context, result *TestResult, test *config.TestScenario, clientSSH, serverSSH HostClient, relays []*relayNode) error {
 	e.coordinator.logger.Printf("  Collecting environment information...")
 	
 	result.EnvironmentInfo = &EnvironmentData{}
 	
-	// Collect client environment
-	if clientSSH != nil {
-		collector := envinfo.NewCollector(clientSSH)
-		if envInfo, err := collector.Collect(ctx); err != nil {
-			e.coordinator.logger.Printf("  Warning: failed to collect client environment: %v", err)
-		} else {
-			result.EnvironmentInfo.ClientEnv = envInfo
-			e.coordinator.logger.Printf("  Collected client environment from %s", test.Client)
+	var collected []string
+	collect := func(role, envRole, host string, client HostClient) *envinfo.EnvironmentInfo {
+		if client == nil || !test.CollectsEnv(envRole) {
+			return nil
 		}
-	}
-	
-	// Collect server environment
-	if serverSSH != nil {
-		collector := envinfo.NewCollector(serverSSH)
-		if envInfo, err := collector.Collect(ctx); err != nil {
-			e.coordinator.logger.Printf("  Warning: failed to collect server environment: %v", err)
-		} else {
-			result.EnvironmentInfo.ServerEnv = envInfo
-			e.coordinator.logger.Printf("  Collected server environment from %s", test.Server)
+		envInfo, err := e.collectHostEnvironment(ctx, host, client)
+		if err != nil {
+			e.coordinator.logger.Printf("  Warning: failed to collect %s environment: %v", role, err)
+			return nil
 		}
+		e.coordinator.logger.Printf("  Collected %s environment from %s", role, host)
+		collected = append(collected, host)
+		return envInfo
 	}
 	
-	// Collect intermediate environment if applicable
-	if intermediateSSH != nil {
-		collector := envinfo.NewCollector(intermediateSSH)
-		if envInfo, err := collector.Collect(ctx); err != nil {
-			e.coordinator.logger.Printf("  Warning: failed to collect intermediate environment: %v", err)
-		} else {
+	result.EnvironmentInfo.ClientEnv = collect("client", "client", test.Client, clientSSH)
+	result.EnvironmentInfo.ServerEnv = collect("server", "server", test.Server, serverSSH)
+	
+	// Every relay of a chain collects under the "intermediate" env role
+	for i, relay := range relays {
+		role := relayRole(i)
+		envInfo := collect(role, "intermediate", relay.name, relay.client)
+		if envInfo == nil {
+			continue
+		}
+		if i == 0 {
 			result.EnvironmentInfo.IntermediateEnv = envInfo
-			e.coordinator.logger.Printf("  Collected intermediate environment from %s", test.Intermediate)
+			continue
+		}
+		if result.EnvironmentInfo.RelayEnvs == nil {
+			result.EnvironmentInfo.RelayEnvs = make(map[string]*envinfo.EnvironmentInfo)
 		}
+		result.EnvironmentInfo.RelayEnvs[role] = envInfo
 	}
 	
+	if len(collected) > 0 {
+		e.coordinator.logger.Printf("  Collected environment from %d hosts: %s", len(collected), strings.Join(collected, ", "))
+	}
 	return nil
 }
\ No newline at end of file
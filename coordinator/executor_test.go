@@ -0,0 +1,838 @@
+package coordinator
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"perf-runner/config"
+	"perf-runner/runner"
+	"perf-runner/ssh"
+)
+
+// fakeHostClient is a HostClient that answers commands from a handler function.
+// Binary checks ("command -v ...") go to probe instead and succeed if it is nil.
+type fakeHostClient struct {
+	mu       sync.Mutex
+	commands []string
+	probes   []string
+	handler  func(ctx context.Context, command string) (*ssh.Result, error)
+	probe    func(ctx context.Context, command string) (*ssh.Result, error)
+}
+
+func (f *fakeHostClient) ExecuteCommand(ctx context.Context, command string) (*ssh.Result, error) {
+	if strings.HasPrefix(command, "command -v ") {
+		f.mu.Lock()
+		f.probes = append(f.probes, command)
+		f.mu.Unlock()
+		if f.probe == nil {
+			// Hosts have no uptime unless a test says so, keeping the load
+			// check out of every other test's commands
+			if command == "command -v uptime" {
+				return &ssh.Result{ExitCode: 1}, fmt.Errorf("Process exited with status 1")
+			}
+			return &ssh.Result{Output: "/usr/bin/fake"}, nil
+		}
+		return f.probe(ctx, command)
+	}
+
+	f.mu.Lock()
+	f.commands = append(f.commands, command)
+	f.mu.Unlock()
+	return f.handler(ctx, command)
+}
+
+func (f *fakeHostClient) Close() error {
+	return nil
+}
+
+// succeed returns a handler that immediately reports success with the given output
+func succeed(output string) func(ctx context.Context, command string) (*ssh.Result, error) {
+	return func(ctx context.Context, command string) (*ssh.Result, error) {
+		return &ssh.Result{Output: output}, nil
+	}
+}
+
+// runForever returns a handler that blocks until cancelled, like a persistent server.
+// If exitOnSignal is set it reports the exit status a SIGTERM-killed process would.
+func runForever(exitOnSignal bool) func(ctx context.Context, command string) (*ssh.Result, error) {
+	return func(ctx context.Context, command string) (*ssh.Result, error) {
+		<-ctx.Done()
+		if exitOnSignal {
+			return &ssh.Result{ExitCode: 143, Error: "Process exited with status 143"}, fmt.Errorf("Process exited with status 143")
+		}
+		return nil, fmt.Errorf("command timed out: %w", ctx.Err())
+	}
+}
+
+// fakeRunner is a minimal runner that echoes the role, target, and port into the command
+type fakeRunner struct {
+	mode   runner.ServerMode
+	stream runner.Stream
+}
+
+func (r *fakeRunner) Validate(config runner.Config) error { return nil }
+func (r *fakeRunner) Name() string                        { return "fake" }
+func (r *fakeRunner) SupportsRole(role string) bool       { return true }
+func (r *fakeRunner) BuildCommand(config runner.Config) string {
+	cmd := "fake-" + config.Role
+	if config.TargetHost != "" {
+		cmd += " " + config.TargetHost
+	}
+	if config.Port > 0 {
+		cmd += fmt.Sprintf(" -p %d", config.Port)
+	}
+	return cmd
+}
+
+func (r *fakeRunner) ParseMetrics(result *runner.Result) error { return nil }
+func (r *fakeRunner) SetExecutablePath(path string)            {}
+func (r *fakeRunner) ExecutablePath() string                   { return "fake" }
+func (r *fakeRunner) ServerMode() runner.ServerMode            { return r.mode }
+func (r *fakeRunner) PrimaryMetric() string                    { return "bandwidth_mbps" }
+func (r *fakeRunner) OutputStream() runner.Stream              { return r.stream }
+
+// ServesMultipleClients reports that a one-shot fake server, like iperf3 -1,
+// serves a single client
+func (r *fakeRunner) ServesMultipleClients() bool { return r.mode != runner.ServerOneShot }
+
+// launchedCommands returns the role commands among commands, without the
+// PID recording of background roles and leaving out the commands that stop them
+func launchedCommands(commands []string) []string {
+	var launched []string
+	for _, command := range commands {
+		if strings.HasPrefix(command, "p=$(cat ") || isSamplerCommand(command) {
+			continue
+		}
+		if strings.HasPrefix(command, "echo $$ > ") {
+			_, command, _ = strings.Cut(command, "; ")
+		}
+		launched = append(launched, command)
+	}
+	return launched
+}
+
+// newTestCoordinator builds a coordinator wired to fake host clients
+func newTestCoordinator(tests []config.TestScenario, clients map[string]*fakeHostClient) *Coordinator {
+	cfg := &config.TestConfig{
+		Name:    "test",
+		Runner:  "fake",
+		Timeout: 10 * time.Second,
+		Hosts:   make(map[string]*config.HostConfig),
+		Tests:   tests,
+	}
+	coord := NewCoordinator(cfg, log.New(io.Discard, "", 0))
+	coord.RegisterRunner("fake", &fakeRunner{})
+	coord.newExecutor = newTestExecutor
+	for name, client := range clients {
+		cfg.Hosts[name] = &config.HostConfig{SSH: &ssh.Config{Host: name, User: "test"}}
+		coord.sshClients[name] = client
+	}
+	return coord
+}
+
+// newTestExecutor returns an executor without startup delays
+func newTestExecutor(coord *Coordinator) *TestExecutor {
+	executor := NewTestExecutor(coord)
+	executor.startupDelay = 0
+	executor.shutdownGrace = 20 * time.Millisecond
+	// Fake servers block on every command, including the stop command
+	executor.stopTimeout = 20 * time.Millisecond
+	executor.loadTimeout = 20 * time.Millisecond
+	executor.sampleInterval = 10 * time.Millisecond
+	return executor
+}
+
+func TestNewTestExecutor_ConfiguredDelays(t *testing.T) {
+	coord := newTestCoordinator(nil, nil)
+	executor := NewTestExecutor(coord)
+	if executor.startupDelay != defaultStartupDelay || executor.shutdownGrace != defaultShutdownGrace {
+		t.Errorf("Expected the default delays, got startup %v, grace %v", executor.startupDelay, executor.shutdownGrace)
+	}
+
+	delay := 500 * time.Millisecond
+	coord.config.NodeStartupDelay = &delay
+	coord.config.IntermediateCleanupTimeout = 30 * time.Second
+	executor = NewTestExecutor(coord)
+	if executor.startupDelay != 500*time.Millisecond || executor.shutdownGrace != 30*time.Second {
+		t.Errorf("Expected the configured delays, got startup %v, grace %v", executor.startupDelay, executor.shutdownGrace)
+	}
+
+	// An explicit zero turns the fixed wait off
+	delay = 0
+	executor = NewTestExecutor(coord)
+	if executor.startupDelay != 0 {
+		t.Errorf("Expected node_startup_delay: 0 to disable the wait, got %v", executor.startupDelay)
+	}
+}
+
+func TestExecuteTest_TerminatedServerDoesNotFail(t *testing.T) {
+	for _, exitOnSignal := range []bool{true, false} {
+		t.Run(fmt.Sprintf("exit_status_reported=%v", exitOnSignal), func(t *testing.T) {
+			test := config.TestScenario{Name: "persistent server", Client: "client", Server: "server"}
+			coord := newTestCoordinator([]config.TestScenario{test}, map[string]*fakeHostClient{
+				"client": {handler: succeed("done")},
+				"server": {handler: runForever(exitOnSignal)},
+			})
+
+			result, err := newTestExecutor(coord).ExecuteTest(context.Background(), &test)
+			if err != nil {
+				t.Fatalf("ExecuteTest returned error: %v", err)
+			}
+
+			if result.ServerResult == nil || !result.ServerResult.TerminatedByUs {
+				t.Fatalf("Expected server result marked terminated_by_us, got %+v", result.ServerResult)
+			}
+			if !result.Success {
+				t.Errorf("Deliberately terminated server should not fail the scenario: %+v", result)
+			}
+		})
+	}
+}
+
+func TestExecuteTest_ScenarioTimeoutOverridesGlobal(t *testing.T) {
+	test := config.TestScenario{Name: "quick", Client: "client", Server: "server", Timeout: 50 * time.Millisecond}
+	coord := newTestCoordinator([]config.TestScenario{test}, map[string]*fakeHostClient{
+		"client": {handler: runForever(false)},
+		"server": {handler: runForever(true)},
+	})
+	var logs strings.Builder
+	coord.logger = log.New(&logs, "", 0)
+
+	start := time.Now()
+	if _, err := newTestExecutor(coord).ExecuteTest(context.Background(), &test); err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("Expected the scenario to time out, got %v", err)
+	}
+	// The global timeout is 10s
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Expected the scenario timeout to apply, took %v", elapsed)
+	}
+	if !strings.Contains(logs.String(), "Timeout: 50ms (scenario)") {
+		t.Errorf("Expected the scenario timeout to be logged, got:\n%s", logs.String())
+	}
+}
+
+func TestExecuteTest_ServerFailureStillFails(t *testing.T) {
+	test := config.TestScenario{Name: "failing server", Client: "client", Server: "server"}
+	coord := newTestCoordinator([]config.TestScenario{test}, map[string]*fakeHostClient{
+		"client": {handler: succeed("done")},
+		"server": {handler: func(ctx context.Context, command string) (*ssh.Result, error) {
+			return &ssh.Result{ExitCode: 1, Error: "Process exited with status 1"}, fmt.Errorf("Process exited with status 1")
+		}},
+	})
+
+	result, err := newTestExecutor(coord).ExecuteTest(context.Background(), &test)
+	if err != nil {
+		t.Fatalf("ExecuteTest returned error: %v", err)
+	}
+
+	if result.ServerResult == nil || result.ServerResult.TerminatedByUs {
+		t.Fatalf("Expected a server result not marked terminated, got %+v", result.ServerResult)
+	}
+	if result.Success {
+		t.Error("A server that exits non-zero on its own should fail the scenario")
+	}
+}
+
+func TestExecuteTest_ClientNonZeroExitIsReported(t *testing.T) {
+	test := config.TestScenario{Name: "failing client", Client: "client", Server: "server"}
+	coord := newTestCoordinator([]config.TestScenario{test}, map[string]*fakeHostClient{
+		"client": {handler: func(ctx context.Context, command string) (*ssh.Result, error) {
+			return &ssh.Result{Output: "connect failed", ExitCode: 1, Error: "Process exited with status 1"}, fmt.Errorf("Process exited with status 1")
+		}},
+		"server": {handler: succeed("")},
+	})
+
+	result, err := newTestExecutor(coord).ExecuteTest(context.Background(), &test)
+	if err != nil {
+		t.Fatalf("ExecuteTest returned error: %v", err)
+	}
+
+	if result.ClientResult == nil || result.ClientResult.ExitCode != 1 {
+		t.Fatalf("Expected client result with exit code 1, got %+v", result.ClientResult)
+	}
+	if !strings.Contains(result.ClientResult.Output, "connect failed") {
+		t.Errorf("Expected client output to be preserved, got %q", result.ClientResult.Output)
+	}
+	if result.Success {
+		t.Error("Scenario should fail when the client exits non-zero")
+	}
+}
+
+func TestRunTest_RetriesOnFallbackHost(t *testing.T) {
+	test := config.TestScenario{
+		Name:          "flaky server",
+		Client:        "client",
+		Server:        "server",
+		FallbackHosts: map[string]string{"server": "server-alt"},
+	}
+	failing := &fakeHostClient{handler: func(ctx context.Context, command string) (*ssh.Result, error) {
+		return &ssh.Result{ExitCode: 1, Error: "Process exited with status 1"}, fmt.Errorf("Process exited with status 1")
+	}}
+	fallback := &fakeHostClient{handler: runForever(true)}
+	coord := newTestCoordinator([]config.TestScenario{test}, map[string]*fakeHostClient{
+		"client":     {handler: succeed("done")},
+		"server":     failing,
+		"server-alt": fallback,
+	})
+
+	result, err := coord.RunTest(context.Background(), &test)
+	if err != nil {
+		t.Fatalf("RunTest returned error: %v", err)
+	}
+
+	if !result.Success || !result.FallbackUsed {
+		t.Fatalf("Expected a successful fallback result, got success=%v fallback_used=%v", result.Success, result.FallbackUsed)
+	}
+	if result.Hosts["server"] != "server-alt" || result.Hosts["client"] != "client" {
+		t.Errorf("Expected the result to record the fallback server, got %v", result.Hosts)
+	}
+	primaryRuns, fallbackRuns := len(launchedCommands(failing.commands)), len(launchedCommands(fallback.commands))
+	if primaryRuns != 1 || fallbackRuns != 1 {
+		t.Errorf("Expected one run on each server, got primary=%d fallback=%d", primaryRuns, fallbackRuns)
+	}
+}
+
+func TestRunTest_NoFallbackWhenPrimarySucceeds(t *testing.T) {
+	test := config.TestScenario{
+		Name:          "healthy server",
+		Client:        "client",
+		Server:        "server",
+		FallbackHosts: map[string]string{"server": "server-alt"},
+	}
+	fallback := &fakeHostClient{handler: runForever(true)}
+	coord := newTestCoordinator([]config.TestScenario{test}, map[string]*fakeHostClient{
+		"client":     {handler: succeed("done")},
+		"server":     {handler: runForever(true)},
+		"server-alt": fallback,
+	})
+
+	result, err := coord.RunTest(context.Background(), &test)
+	if err != nil {
+		t.Fatalf("RunTest returned error: %v", err)
+	}
+
+	if !result.Success || result.FallbackUsed || result.Hosts["server"] != "server" {
+		t.Errorf("Expected the primary result, got success=%v fallback_used=%v hosts=%v", result.Success, result.FallbackUsed, result.Hosts)
+	}
+	if len(fallback.commands) != 0 {
+		t.Errorf("Fallback server should not run, got %v", fallback.commands)
+	}
+}
+
+func TestExecuteTest_OutputPatterns(t *testing.T) {
+	tests := []struct {
+		name           string
+		clientOutput   string
+		serverOutput   string
+		successPattern string
+		failurePattern string
+		wantSuccess    bool
+	}{
+		{name: "no patterns", clientOutput: "ERROR: device not found", wantSuccess: true},
+		{name: "failure pattern on exit 0", clientOutput: "ERROR: device not found", failurePattern: `(?i)error`, wantSuccess: false},
+		{name: "failure pattern not matched", clientOutput: "all good", failurePattern: `(?i)error`, wantSuccess: true},
+		{name: "failure pattern in server output", clientOutput: "all good", serverOutput: "error: port in use", failurePattern: `error`, wantSuccess: false},
+		{name: "success pattern matched", clientOutput: "BW average: 90.1", successPattern: `BW average`, wantSuccess: true},
+		{name: "success pattern missing", clientOutput: "nothing useful", successPattern: `BW average`, wantSuccess: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			test := config.TestScenario{
+				Name:           tt.name,
+				Client:         "client",
+				Server:         "server",
+				SuccessPattern: tt.successPattern,
+				FailurePattern: tt.failurePattern,
+			}
+			serverOutput := tt.serverOutput
+			coord := newTestCoordinator([]config.TestScenario{test}, map[string]*fakeHostClient{
+				"client": {handler: succeed(tt.clientOutput)},
+				"server": {handler: func(ctx context.Context, command string) (*ssh.Result, error) {
+					<-ctx.Done()
+					return &ssh.Result{Output: serverOutput, ExitCode: 143}, fmt.Errorf("Process exited with status 143")
+				}},
+			})
+
+			result, err := newTestExecutor(coord).ExecuteTest(context.Background(), &test)
+			if err != nil {
+				t.Fatalf("ExecuteTest returned error: %v", err)
+			}
+			if result.Success != tt.wantSuccess {
+				t.Errorf("Success = %v, want %v (error: %q, client: %+v)", result.Success, tt.wantSuccess, result.Error, result.ClientResult)
+			}
+		})
+	}
+}
+
+func TestExecuteTest_ServerModeWaitsOrKills(t *testing.T) {
+	// The server finishes on its own, but only after the shutdown grace period
+	slowServer := func(ctx context.Context, command string) (*ssh.Result, error) {
+		select {
+		case <-time.After(100 * time.Millisecond):
+			return &ssh.Result{Output: "server summary"}, nil
+		case <-ctx.Done():
+			return &ssh.Result{ExitCode: 143, Error: "Process exited with status 143"}, fmt.Errorf("Process exited with status 143")
+		}
+	}
+
+	tests := []struct {
+		name           string
+		mode           runner.ServerMode
+		wantTerminated bool
+		wantOutput     string
+	}{
+		{name: "one-shot server is waited for", mode: runner.ServerOneShot, wantOutput: "server summary"},
+		{name: "persistent server is killed", mode: runner.ServerPersistent, wantTerminated: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			test := config.TestScenario{Name: tt.name, Client: "client", Server: "server"}
+			coord := newTestCoordinator([]config.TestScenario{test}, map[string]*fakeHostClient{
+				"client": {handler: succeed("done")},
+				"server": {handler: slowServer},
+			})
+			coord.RegisterRunner("fake", &fakeRunner{mode: tt.mode})
+
+			result, err := newTestExecutor(coord).ExecuteTest(context.Background(), &test)
+			if err != nil {
+				t.Fatalf("ExecuteTest returned error: %v", err)
+			}
+			if !result.Success {
+				t.Errorf("Expected success, got %+v", result)
+			}
+			if result.ServerResult.TerminatedByUs != tt.wantTerminated {
+				t.Errorf("TerminatedByUs = %v, want %v", result.ServerResult.TerminatedByUs, tt.wantTerminated)
+			}
+			if result.ServerResult.Output != tt.wantOutput {
+				t.Errorf("Server output = %q, want %q", result.ServerResult.Output, tt.wantOutput)
+			}
+		})
+	}
+}
+
+func TestExecuteTest_StopsOneShotServerWhenClientFails(t *testing.T) {
+	// The client never reaches the server, so the one-shot server would wait forever
+	failing := func(ctx context.Context, command string) (*ssh.Result, error) {
+		return &ssh.Result{ExitCode: 1, Error: "connection refused"}, fmt.Errorf("Process exited with status 1")
+	}
+	test := config.TestScenario{Name: "unreached", Client: "client", Server: "server"}
+	coord := newTestCoordinator([]config.TestScenario{test}, map[string]*fakeHostClient{
+		"client": {handler: failing},
+		"server": {handler: runForever(true)},
+	})
+	coord.RegisterRunner("fake", &fakeRunner{mode: runner.ServerOneShot})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	result, err := newTestExecutor(coord).ExecuteTest(ctx, &test)
+	if ctx.Err() != nil {
+		t.Fatal("Expected the one-shot server to be stopped, but the test hung")
+	}
+	if err == nil && result.Success {
+		t.Errorf("Expected the failed client to fail the test, got %+v", result)
+	}
+	if err == nil && result.ServerResult != nil && !result.ServerResult.TerminatedByUs {
+		t.Errorf("Expected the server to be terminated, got %+v", result.ServerResult)
+	}
+}
+
+func TestExecuteTest_DistinctListenAndConnectPorts(t *testing.T) {
+	test := config.TestScenario{
+		Name:   "forwarded port",
+		Client: "client",
+		Server: "server",
+		Config: &runner.Config{Port: 5201, ConnectPort: 6201},
+	}
+	coord := newTestCoordinator([]config.TestScenario{test}, map[string]*fakeHostClient{
+		"client": {handler: succeed("done")},
+		"server": {handler: runForever(true)},
+	})
+
+	result, err := newTestExecutor(coord).ExecuteTest(context.Background(), &test)
+	if err != nil {
+		t.Fatalf("ExecuteTest returned error: %v", err)
+	}
+	if result.ServerCommand != "fake-server -p 5201" {
+		t.Errorf("Expected server to listen on 5201, got %q", result.ServerCommand)
+	}
+	if result.ClientCommand != "fake-client server -p 6201" {
+		t.Errorf("Expected client to connect to 6201, got %q", result.ClientCommand)
+	}
+}
+
+func TestExecuteTest_WarnsOnZeroPrimaryMetric(t *testing.T) {
+	test := config.TestScenario{Name: "zero", Client: "client", Server: "server"}
+	coord := newTestCoordinator([]config.TestScenario{test}, map[string]*fakeHostClient{
+		"client": {handler: succeed("done")},
+		"server": {handler: runForever(true)},
+	})
+	coord.RegisterRunner("fake", &zeroMetricRunner{})
+
+	result, err := newTestExecutor(coord).ExecuteTest(context.Background(), &test)
+	if err != nil {
+		t.Fatalf("ExecuteTest returned error: %v", err)
+	}
+	if result.PrimaryMetric != "bandwidth_mbps" {
+		t.Errorf("Expected primary metric bandwidth_mbps, got %q", result.PrimaryMetric)
+	}
+	if len(result.Warnings) != 1 || !strings.Contains(result.Warnings[0], "zero bandwidth_mbps") {
+		t.Errorf("Expected a zero-result warning, got %v", result.Warnings)
+	}
+}
+
+// zeroMetricRunner reports a primary metric of zero for every run
+type zeroMetricRunner struct {
+	fakeRunner
+}
+
+func (r *zeroMetricRunner) ParseMetrics(result *runner.Result) error {
+	result.Metrics["bandwidth_mbps"] = 0.0
+	return nil
+}
+
+func TestExecuteTest_WarnsOnMissingStreams(t *testing.T) {
+	test := config.TestScenario{Name: "streams", Client: "client", Server: "server"}
+	coord := newTestCoordinator([]config.TestScenario{test}, map[string]*fakeHostClient{
+		"client": {handler: succeed("done")},
+		"server": {handler: runForever(true)},
+	})
+	coord.RegisterRunner("fake", &partialStreamsRunner{})
+
+	result, err := newTestExecutor(coord).ExecuteTest(context.Background(), &test)
+	if err != nil {
+		t.Fatalf("ExecuteTest returned error: %v", err)
+	}
+	if len(result.Warnings) != 1 || !strings.Contains(result.Warnings[0], "established 3 of 8 requested streams") {
+		t.Errorf("Expected a stream mismatch warning, got %v", result.Warnings)
+	}
+}
+
+// partialStreamsRunner reports fewer established streams than requested
+type partialStreamsRunner struct {
+	fakeRunner
+}
+
+func (r *partialStreamsRunner) ParseMetrics(result *runner.Result) error {
+	result.Metrics["bandwidth_mbps"] = 100.0
+	result.Metrics["streams_requested"] = 8
+	result.Metrics["streams_actual"] = 3
+	return nil
+}
+
+func TestCommandHash(t *testing.T) {
+	a := commandHash("iperf3 -c 10.0.0.2 -t 10 -P 4")
+	b := commandHash("iperf3 -c 10.0.0.2 -t 10 -P 8")
+	if a == b {
+		t.Errorf("Expected different commands to hash differently, both got %s", a)
+	}
+	if again := commandHash("iperf3 -c 10.0.0.2 -t 10 -P 4"); again != a {
+		t.Errorf("Expected identical commands to hash the same, got %s and %s", a, again)
+	}
+	if len(a) != 12 {
+		t.Errorf("Expected a 12-character hash, got %q", a)
+	}
+}
+
+func TestCommandHash_StableAcrossBuilds(t *testing.T) {
+	args := map[string]interface{}{"parallel_streams": 4, "reverse": true, "bitrate": "10G", "window_size": "4M", "size": 65536, "iterations": 1000, "tx_depth": 128, "mtu": 4096}
+	for _, name := range []string{"iperf3", "ib_send_bw"} {
+		r, err := runner.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		config := runner.Config{Role: "client", Host: "10.0.0.2", Args: args}
+		first := commandHash(r.BuildCommand(config))
+		// Map order varies between iterations, so build it several times
+		for i := 0; i < 20; i++ {
+			if again := commandHash(r.BuildCommand(config)); again != first {
+				t.Fatalf("%s: expected the same config to hash the same, got %s and %s", name, first, again)
+			}
+		}
+	}
+}
+
+func TestExecuteTest_RecordsCommandHashes(t *testing.T) {
+	test := config.TestScenario{Name: "hashes", Client: "client", Server: "server"}
+	coord := newTestCoordinator([]config.TestScenario{test}, map[string]*fakeHostClient{
+		"client": {handler: succeed("done")},
+		"server": {handler: runForever(true)},
+	})
+
+	result, err := newTestExecutor(coord).ExecuteTest(context.Background(), &test)
+	if err != nil {
+		t.Fatalf("ExecuteTest returned error: %v", err)
+	}
+	if result.CommandHashes["client"] != commandHash(result.ClientCommand) || result.CommandHashes["server"] != commandHash(result.ServerCommand) {
+		t.Errorf("Unexpected command hashes %v", result.CommandHashes)
+	}
+	if _, ok := result.CommandHashes["intermediate"]; ok {
+		t.Error("Expected no hash for a role that did not run")
+	}
+}
+
+// streamRecordingRunner records the non-empty output handed to ParseMetrics
+type streamRecordingRunner struct {
+	fakeRunner
+	mu     sync.Mutex
+	parsed map[string]bool
+}
+
+func (r *streamRecordingRunner) ParseMetrics(result *runner.Result) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if result.Output != "" {
+		r.parsed[result.Output] = true
+	}
+	return nil
+}
+
+func TestExecuteTest_ParsesRunnerOutputStream(t *testing.T) {
+	streams := &ssh.Result{
+		Output: "stats\nEAL: noise\n",
+		Stdout: "stats\n",
+		Stderr: "EAL: noise\n",
+	}
+	tests := []struct {
+		stream runner.Stream
+		want   string
+	}{
+		{runner.StreamStdout, "stats\n"},
+		{runner.StreamStderr, "EAL: noise\n"},
+		{runner.StreamCombined, "stats\nEAL: noise\n"},
+	}
+
+	for _, tt := range tests {
+		test := config.TestScenario{Name: "streams", Client: "client", Server: "server"}
+		coord := newTestCoordinator([]config.TestScenario{test}, map[string]*fakeHostClient{
+			"client": {handler: func(ctx context.Context, command string) (*ssh.Result, error) {
+				result := *streams
+				return &result, nil
+			}},
+			"server": {handler: runForever(true)},
+		})
+		r := &streamRecordingRunner{fakeRunner: fakeRunner{stream: tt.stream}, parsed: make(map[string]bool)}
+		coord.RegisterRunner("fake", r)
+
+		result, err := newTestExecutor(coord).ExecuteTest(context.Background(), &test)
+		if err != nil {
+			t.Fatalf("ExecuteTest returned error: %v", err)
+		}
+		if !r.parsed[tt.want] || len(r.parsed) != 1 {
+			t.Errorf("stream %v: expected ParseMetrics to see %q, got %v", tt.stream, tt.want, r.parsed)
+		}
+		if result.ClientResult.Output != streams.Output {
+			t.Errorf("stream %v: expected the combined output to be kept, got %q", tt.stream, result.ClientResult.Output)
+		}
+	}
+}
+
+func TestStreamOutput_FallsBackToCombined(t *testing.T) {
+	combinedOnly := &ssh.Result{Output: "everything"}
+	if got := streamOutput(runner.StreamStdout, combinedOnly); got != "everything" {
+		t.Errorf("Expected combined output when streams were not captured, got %q", got)
+	}
+}
+
+func TestExecuteTest_FailureErrorQuotesStderrTail(t *testing.T) {
+	test := config.TestScenario{Name: "unreachable", Client: "client", Server: "server"}
+	coord := newTestCoordinator([]config.TestScenario{test}, map[string]*fakeHostClient{
+		"client": {handler: func(ctx context.Context, command string) (*ssh.Result, error) {
+			stderr := "resolving server\nwarning: no route cache\nretrying\nconnect failed: No route to host\n"
+			return &ssh.Result{
+				Output:   "Connecting to host server\n" + stderr,
+				Stdout:   "Connecting to host server\n",
+				Stderr:   stderr,
+				ExitCode: 1,
+				Error:    "Process exited with status 1",
+			}, fmt.Errorf("Process exited with status 1")
+		}},
+		"server": {handler: runForever(true)},
+	})
+
+	result, err := newTestExecutor(coord).ExecuteTest(context.Background(), &test)
+	if err != nil {
+		t.Fatalf("ExecuteTest returned error: %v", err)
+	}
+
+	want := "warning: no route cache; retrying; connect failed: No route to host (Process exited with status 1)"
+	if result.ClientResult.Error != want {
+		t.Errorf("Client error = %q, want %q", result.ClientResult.Error, want)
+	}
+}
+
+func TestStderrTail(t *testing.T) {
+	if got := stderrTail("\n  only line  \n\n", 3); got != "only line" {
+		t.Errorf("stderrTail() = %q, want %q", got, "only line")
+	}
+	if got := stderrTail("", 3); got != "" {
+		t.Errorf("Expected an empty tail for empty stderr, got %q", got)
+	}
+}
+
+func TestExecuteTest_EnvRolesLimitsCollection(t *testing.T) {
+	test := config.TestScenario{Name: "server env", Client: "client", Server: "server", EnvRoles: []string{"server"}}
+	coord := newTestCoordinator([]config.TestScenario{test}, map[string]*fakeHostClient{
+		"client": {handler: succeed("done")},
+		"server": {handler: func(ctx context.Context, command string) (*ssh.Result, error) {
+			if strings.HasPrefix(command, "fake-") {
+				return runForever(true)(ctx, command)
+			}
+			return &ssh.Result{Output: "server\n"}, nil
+		}},
+	})
+	coord.SetEnvironmentCollection(true)
+
+	result, err := newTestExecutor(coord).ExecuteTest(context.Background(), &test)
+	if err != nil {
+		t.Fatalf("ExecuteTest returned error: %v", err)
+	}
+	if result.EnvironmentInfo == nil {
+		t.Fatal("Expected environment info to be collected")
+	}
+	if result.EnvironmentInfo.ClientEnv != nil {
+		t.Error("Expected no client environment when env_roles lists only the server")
+	}
+	if result.EnvironmentInfo.ServerEnv == nil {
+		t.Error("Expected the server environment to be collected")
+	}
+}
+
+func TestExecuteTest_RetriesEnvironmentCollection(t *testing.T) {
+	noRetries := 0
+	tests := []struct {
+		name     string
+		retries  *int
+		attempts int
+	}{
+		{"default retries", nil, 2},
+		{"retries disabled", &noRetries, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			test := config.TestScenario{Name: "env retry", Client: "client", Server: "server"}
+
+			var mu sync.Mutex
+			runs, hostnames := 0, 0
+			client := func(ctx context.Context, command string) (*ssh.Result, error) {
+				mu.Lock()
+				defer mu.Unlock()
+				switch {
+				case strings.HasPrefix(command, "fake-client"):
+					runs++
+					return &ssh.Result{Output: "done"}, nil
+				case command == "hostname":
+					// The first environment probe fails as on a flaky session
+					hostnames++
+					if hostnames == 1 {
+						return nil, fmt.Errorf("session reset")
+					}
+					return &ssh.Result{Output: "client-host"}, nil
+				}
+				return &ssh.Result{}, nil
+			}
+			server := func(ctx context.Context, command string) (*ssh.Result, error) {
+				if strings.HasPrefix(command, "fake-server") {
+					return runForever(true)(ctx, command)
+				}
+				return &ssh.Result{}, nil
+			}
+			coord := newTestCoordinator([]config.TestScenario{test}, map[string]*fakeHostClient{
+				"client": {handler: client},
+				"server": {handler: server},
+			})
+			coord.config.EnvRetries = tt.retries
+			coord.SetEnvironmentCollection(true)
+			executor := newTestExecutor(coord)
+			executor.envRetryDelay = 0
+
+			result, err := executor.ExecuteTest(context.Background(), &test)
+			if err != nil {
+				t.Fatalf("ExecuteTest returned error: %v", err)
+			}
+			if runs != 1 || hostnames != tt.attempts {
+				t.Errorf("Expected one benchmark run and %d env attempts, got %d runs and %d attempts", tt.attempts, runs, hostnames)
+			}
+			if tt.attempts == 1 {
+				if result.EnvironmentInfo != nil && result.EnvironmentInfo.ClientEnv != nil {
+					t.Error("Expected no client environment without retries")
+				}
+				return
+			}
+			if result.EnvironmentInfo == nil || result.EnvironmentInfo.ClientEnv == nil {
+				t.Fatal("Expected the client environment after a retry")
+			}
+			if got := result.EnvironmentInfo.ClientEnv.Hostname; got != "client-host" {
+				t.Errorf("Expected hostname client-host, got %q", got)
+			}
+		})
+	}
+}
+
+// protocolRunner flags the protocol on both ends, as nuttcp does
+type protocolRunner struct {
+	fakeRunner
+}
+
+func (r *protocolRunner) ProtocolRoles() []string { return []string{"client", "server"} }
+
+func TestExecuteTest_WarnsOnProtocolMismatch(t *testing.T) {
+	test := config.TestScenario{
+		Name:   "udp",
+		Client: "client",
+		Server: "server",
+		Config: &runner.Config{ClientArgs: map[string]interface{}{"protocol": "udp"}},
+	}
+	coord := newTestCoordinator([]config.TestScenario{test}, map[string]*fakeHostClient{
+		"client": {handler: succeed("done")},
+		"server": {handler: runForever(true)},
+	})
+	coord.RegisterRunner("fake", &protocolRunner{})
+
+	result, err := newTestExecutor(coord).ExecuteTest(context.Background(), &test)
+	if err != nil {
+		t.Fatalf("ExecuteTest returned error: %v", err)
+	}
+	if len(result.Warnings) != 1 || !strings.Contains(result.Warnings[0], "client uses udp but server uses tcp") {
+		t.Errorf("Expected a protocol mismatch warning, got %v", result.Warnings)
+	}
+}
+
+// clientOnlyRunner is a fakeRunner without a server role, like fio
+type clientOnlyRunner struct {
+	fakeRunner
+}
+
+func (r *clientOnlyRunner) SupportsRole(role string) bool { return role == "client" }
+
+func TestExecuteTest_ClientOnlyRunner(t *testing.T) {
+	test := config.TestScenario{Name: "storage", Client: "client"}
+	client := &fakeHostClient{handler: succeed("done")}
+	coord := newTestCoordinator([]config.TestScenario{test}, map[string]*fakeHostClient{"client": client})
+	coord.RegisterRunner("fake", &clientOnlyRunner{})
+
+	result, err := newTestExecutor(coord).ExecuteTest(context.Background(), &test)
+	if err != nil {
+		t.Fatalf("ExecuteTest returned error: %v", err)
+	}
+	if !result.Success {
+		t.Fatalf("Expected the client alone to pass the test, got %+v", result)
+	}
+	if result.ServerResult != nil {
+		t.Errorf("Expected no server result, got %+v", result.ServerResult)
+	}
+	if got := launchedCommands(client.commands); len(got) != 1 || got[0] != "fake-client" {
+		t.Errorf("Expected only the client command, got %v", got)
+	}
+	if _, exists := result.Hosts["server"]; exists {
+		t.Errorf("Expected no server host recorded, got %v", result.Hosts)
+	}
+}
@@ -0,0 +1,91 @@
+package coordinator
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+
+	"perf-runner/config"
+	"perf-runner/ssh"
+)
+
+func TestRunAllTests_ExpectedFailurePasses(t *testing.T) {
+	test := config.TestScenario{Name: "blocked port", Client: "client", Server: "server", Retries: 2, ExpectFail: true}
+
+	// The client cannot connect; expect_fail must not retry it
+	var runs int32
+	client := &fakeHostClient{handler: func(ctx context.Context, command string) (*ssh.Result, error) {
+		atomic.AddInt32(&runs, 1)
+		return &ssh.Result{ExitCode: 1, Error: "connect failed: Connection refused"}, fmt.Errorf("Process exited with status 1")
+	}}
+	coord := newTestCoordinator([]config.TestScenario{test}, map[string]*fakeHostClient{
+		"client": client,
+		"server": {handler: runForever(true)},
+	})
+
+	results, err := coord.RunAllTests(context.Background())
+	if err != nil {
+		t.Fatalf("RunAllTests returned error: %v", err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("Expected 1 result, got %d", len(results))
+	}
+	result := results[0]
+	if !result.Success || !result.ExpectFail {
+		t.Errorf("Expected the failure to pass as expected, got success=%v expect_fail=%v", result.Success, result.ExpectFail)
+	}
+	if result.ClientResult == nil || result.ClientResult.Error == "" {
+		t.Error("Expected the client's error to be kept")
+	}
+	if got := atomic.LoadInt32(&runs); got != 1 {
+		t.Errorf("Expected 1 client run without retries, got %d", got)
+	}
+}
+
+func TestRunAllTests_UnexpectedSuccessFails(t *testing.T) {
+	test := config.TestScenario{Name: "blocked port", Client: "client", Server: "server", ExpectFail: true}
+	coord := newTestCoordinator([]config.TestScenario{test}, map[string]*fakeHostClient{
+		"client": {handler: succeed("done")},
+		"server": {handler: runForever(true)},
+	})
+
+	results, err := coord.RunAllTests(context.Background())
+	if err != nil {
+		t.Fatalf("RunAllTests returned error: %v", err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("Expected 1 result, got %d", len(results))
+	}
+	result := results[0]
+	if result.Success || !result.ExpectFail {
+		t.Errorf("Expected the unexpected success to fail, got success=%v expect_fail=%v", result.Success, result.ExpectFail)
+	}
+	if result.Error != "expected to fail but succeeded" {
+		t.Errorf("Unexpected error %q", result.Error)
+	}
+}
+
+func TestRunAllTests_ExpectFailKeepsInfrastructureErrors(t *testing.T) {
+	test := config.TestScenario{Name: "blocked port", Client: "client", Server: "server", ExpectFail: true}
+
+	// The command never ran, so the scenario tested nothing
+	client := &fakeHostClient{handler: func(ctx context.Context, command string) (*ssh.Result, error) {
+		return nil, fmt.Errorf("ssh: unexpected packet in response to channel open")
+	}}
+	coord := newTestCoordinator([]config.TestScenario{test}, map[string]*fakeHostClient{
+		"client": client,
+		"server": {handler: runForever(true)},
+	})
+
+	results, err := coord.RunAllTests(context.Background())
+	if err != nil {
+		t.Fatalf("RunAllTests returned error: %v", err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("Expected 1 result, got %d", len(results))
+	}
+	if result := results[0]; result.Success || !result.ExpectFail {
+		t.Errorf("Expected the SSH failure to fail the scenario, got success=%v expect_fail=%v", result.Success, result.ExpectFail)
+	}
+}
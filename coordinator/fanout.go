@@ -0,0 +1,116 @@
+package coordinator
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"perf-runner/config"
+	"perf-runner/runner"
+)
+
+// fanOutClient is an additional client host that runs against the same server
+// as the scenario's primary client
+type fanOutClient struct {
+	name   string
+	client HostClient
+	config *runner.Config
+}
+
+// fanOutClients prepares the additional clients of a fan-out scenario. Each
+// one uses its own host's runner config but targets the primary client's target.
+func (e *TestExecutor) fanOutClients(test *config.TestScenario, primary *runner.Config) ([]fanOutClient, error) {
+	clients := make([]fanOutClient, 0, len(test.Clients))
+	for _, name := range test.Clients {
+		host := e.coordinator.config.Hosts[name]
+		if host == nil {
+			return nil, fmt.Errorf("client host %s not found", name)
+		}
+		sshClient := e.coordinator.hostClient(name)
+		if sshClient == nil {
+			return nil, fmt.Errorf("SSH client for host %s not connected", name)
+		}
+
+		clientConfig := e.coordinator.config.MergeRunnerConfig(host.Runner, test.Config)
+		clientConfig.Role = "client"
+		clientConfig.Port = clientConfig.GetEffectivePort()
+		clientConfig.Host = primary.Host
+		if clientConfig.TargetHost == "" {
+			clientConfig.TargetHost = primary.TargetHost
+		}
+		clients = append(clients, fanOutClient{name: name, client: sshClient, config: clientConfig})
+	}
+	return clients, nil
+}
+
+// runFanOutClients launches the primary client and the additional clients
+// concurrently, stagger apart, and waits for all of them. The primary result
+// is returned; the others are recorded in result.ClientResults.
+func (e *TestExecutor) runFanOutClients(
+	ctx context.Context,
+	r runner.Runner,
+	primarySSH HostClient,
+	primaryConfig *runner.Config,
+	extra []fanOutClient,
+	stagger time.Duration,
+	result *TestResult,
+) (*runner.Result, error) {
+	var (
+		wg            sync.WaitGroup
+		primaryResult *runner.Result
+		primaryErr    error
+	)
+	extraResults := make([]*runner.Result, len(extra))
+	extraErrs := make([]error, len(extra))
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		primaryResult, primaryErr = e.runRemoteCommand(ctx, primarySSH, result.Hosts["client"], r, primaryConfig, "", "")
+	}()
+
+	for i, c := range extra {
+		if stagger > 0 {
+			select {
+			case <-time.After(stagger):
+			case <-ctx.Done():
+			}
+		}
+		if ctx.Err() != nil {
+			extraErrs[i] = fmt.Errorf("not started: %w", ctx.Err())
+			continue
+		}
+
+		e.coordinator.logger.Printf("  Starting client on %s", c.name)
+		wg.Add(1)
+		go func(i int, c fanOutClient) {
+			defer wg.Done()
+			extraResults[i], extraErrs[i] = e.runRemoteCommand(ctx, c.client, c.name, r, c.config, "", "")
+		}(i, c)
+	}
+	wg.Wait()
+
+	result.ClientResults = make(map[string]*runner.Result, len(extra))
+	for i, c := range extra {
+		if extraErrs[i] != nil {
+			if result.Error == "" {
+				result.Error = fmt.Sprintf("client %s execution failed: %v", c.name, extraErrs[i])
+			}
+			continue
+		}
+		result.ClientResults[c.name] = extraResults[i]
+	}
+
+	return primaryResult, primaryErr
+}
+
+// fanOutSucceeded reports whether every additional client ran and succeeded
+func fanOutSucceeded(test *config.TestScenario, results map[string]*runner.Result) bool {
+	for _, name := range test.Clients {
+		if r, ok := results[name]; !ok || r == nil || !r.Success {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,117 @@
+package coordinator
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"perf-runner/config"
+	"perf-runner/runner"
+	"perf-runner/ssh"
+)
+
+func TestExecuteTest_ClientStagger(t *testing.T) {
+	const stagger = 50 * time.Millisecond
+
+	var mu sync.Mutex
+	dispatched := make(map[string]time.Time)
+	clientHandler := func(name string) func(ctx context.Context, command string) (*ssh.Result, error) {
+		return func(ctx context.Context, command string) (*ssh.Result, error) {
+			mu.Lock()
+			dispatched[name] = time.Now()
+			mu.Unlock()
+			return &ssh.Result{Output: "done"}, nil
+		}
+	}
+
+	test := config.TestScenario{
+		Name:          "incast",
+		Client:        "client1",
+		Clients:       []string{"client2", "client3"},
+		Server:        "server",
+		ClientStagger: stagger,
+	}
+	coord := newTestCoordinator([]config.TestScenario{test}, map[string]*fakeHostClient{
+		"client1": {handler: clientHandler("client1")},
+		"client2": {handler: clientHandler("client2")},
+		"client3": {handler: clientHandler("client3")},
+		"server":  {handler: runForever(true)},
+	})
+
+	result, err := newTestExecutor(coord).ExecuteTest(context.Background(), &test)
+	if err != nil {
+		t.Fatalf("ExecuteTest returned error: %v", err)
+	}
+	if !result.Success {
+		t.Fatalf("Expected success, got error %q", result.Error)
+	}
+	if len(result.ClientResults) != 2 || result.ClientResults["client2"] == nil || result.ClientResults["client3"] == nil {
+		t.Fatalf("Expected results for client2 and client3, got %v", result.ClientResults)
+	}
+
+	order := []string{"client1", "client2", "client3"}
+	for i := 1; i < len(order); i++ {
+		gap := dispatched[order[i]].Sub(dispatched[order[i-1]])
+		if gap < stagger {
+			t.Errorf("Expected at least %v between %s and %s, got %v", stagger, order[i-1], order[i], gap)
+		}
+	}
+}
+
+func TestExecuteTest_FanOutClientFailureFailsScenario(t *testing.T) {
+	test := config.TestScenario{
+		Name:    "incast",
+		Client:  "client1",
+		Clients: []string{"client2"},
+		Server:  "server",
+	}
+	coord := newTestCoordinator([]config.TestScenario{test}, map[string]*fakeHostClient{
+		"client1": {handler: succeed("done")},
+		"client2": {handler: func(ctx context.Context, command string) (*ssh.Result, error) {
+			return &ssh.Result{ExitCode: 1, Error: "connection refused"}, nil
+		}},
+		"server": {handler: runForever(true)},
+	})
+
+	result, err := newTestExecutor(coord).ExecuteTest(context.Background(), &test)
+	if err != nil {
+		t.Fatalf("ExecuteTest returned error: %v", err)
+	}
+	if result.Success {
+		t.Error("Expected a failing fan-out client to fail the scenario")
+	}
+	if r := result.ClientResults["client2"]; r == nil || r.Success {
+		t.Errorf("Expected a failed result for client2, got %+v", r)
+	}
+}
+
+func TestExecuteTest_FanOutRejectsSingleClientServer(t *testing.T) {
+	test := config.TestScenario{
+		Name:    "incast",
+		Client:  "client1",
+		Clients: []string{"client2"},
+		Server:  "server",
+	}
+	clients := map[string]*fakeHostClient{
+		"client1": {handler: succeed("done")},
+		"client2": {handler: succeed("done")},
+		"server":  {handler: succeed("server summary")},
+	}
+	coord := newTestCoordinator([]config.TestScenario{test}, clients)
+	coord.RegisterRunner("fake", &fakeRunner{mode: runner.ServerOneShot})
+
+	_, err := newTestExecutor(coord).ExecuteTest(context.Background(), &test)
+	if err == nil || !strings.Contains(err.Error(), "one client at a time") {
+		t.Fatalf("Expected fan-out to a one-shot server to be rejected, got %v", err)
+	}
+	if classifyError(err) != ErrorDeterministic {
+		t.Errorf("Expected a deterministic error, got %v", classifyError(err))
+	}
+	for name, client := range clients {
+		if len(client.commands) > 0 {
+			t.Errorf("Expected nothing launched on %s, got %v", name, client.commands)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package coordinator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"perf-runner/config"
+)
+
+// FileTransferer is implemented by host clients that can copy files to and
+// from their host, such as ssh.Client
+type FileTransferer interface {
+	UploadFile(ctx context.Context, localPath, remotePath string) error
+	DownloadFile(ctx context.Context, remotePath, localPath string) error
+}
+
+// errNoFileTransfers is returned by client wrappers whose host client
+// cannot copy files
+var errNoFileTransfers = errors.New("does not support file transfers")
+
+// fileClient returns the file transfer side of a connected host's client,
+// tracking its connection and bounded by the global command limit like any
+// command
+func (c *Coordinator) fileClient(host string) (FileTransferer, error) {
+	client := c.hostClient(host)
+	if client == nil {
+		return nil, fmt.Errorf("SSH client for host %s not connected", host)
+	}
+	transferer, ok := client.(FileTransferer)
+	if !ok {
+		return nil, fmt.Errorf("host %s %w", host, errNoFileTransfers)
+	}
+	return transferer, nil
+}
+
+// transferFile copies one file in either direction
+func (c *Coordinator) transferFile(ctx context.Context, transfer config.FileTransfer, localPath string, upload bool) error {
+	client, err := c.fileClient(transfer.Host)
+	if err != nil {
+		return err
+	}
+	if upload {
+		err = client.UploadFile(ctx, localPath, transfer.Remote)
+	} else {
+		err = client.DownloadFile(ctx, transfer.Remote, localPath)
+	}
+	if errors.Is(err, errNoFileTransfers) {
+		return fmt.Errorf("host %s %w", transfer.Host, err)
+	}
+	return err
+}
+
+// uploadFiles copies the scenario's pre_files to their hosts. A missing
+// file would make the run meaningless, so the first failure aborts it.
+func (e *TestExecutor) uploadFiles(ctx context.Context, test *config.TestScenario) error {
+	for _, transfer := range test.PreFiles {
+		e.coordinator.logger.Printf("  Uploading %s to %s:%s", transfer.Local, transfer.Host, transfer.Remote)
+		if err := e.coordinator.transferFile(ctx, transfer, transfer.Local, true); err != nil {
+			return fmt.Errorf("pre_files: %w", err)
+		}
+	}
+	return nil
+}
+
+// downloadFiles copies the scenario's post_files back from their hosts.
+// Failures are warnings, since the measurement itself is complete.
+func (e *TestExecutor) downloadFiles(ctx context.Context, result *TestResult, test *config.TestScenario) {
+	for _, transfer := range test.PostFiles {
+		localPath := postFilePath(transfer.Local, test)
+		e.coordinator.logger.Printf("  Downloading %s:%s to %s", transfer.Host, transfer.Remote, localPath)
+		if err := e.coordinator.transferFile(ctx, transfer, localPath, false); err != nil {
+			e.addWarning(result, fmt.Sprintf("post_files: %v", err))
+		}
+	}
+}
+
+// postFilePath expands {scenario} and {iteration} in a post_files local path
+func postFilePath(path string, test *config.TestScenario) string {
+	scenario := strings.NewReplacer("/", "_", " ", "_").Replace(test.Name)
+	return strings.NewReplacer(
+		"{scenario}", scenario,
+		"{iteration}", strconv.Itoa(test.Iteration),
+	).Replace(path)
+}
@@ -0,0 +1,178 @@
+package coordinator
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"perf-runner/config"
+	"perf-runner/ssh"
+)
+
+// transferLog records file transfers and client launches in the order they happen
+type transferLog struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (l *transferLog) add(event string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.events = append(l.events, event)
+}
+
+// transferClient is a fakeHostClient that can also copy files
+type transferClient struct {
+	*fakeHostClient
+	log *transferLog
+	err error
+}
+
+func (c *transferClient) UploadFile(ctx context.Context, localPath, remotePath string) error {
+	c.log.add("upload " + localPath + " " + remotePath)
+	return c.err
+}
+
+func (c *transferClient) DownloadFile(ctx context.Context, remotePath, localPath string) error {
+	c.log.add("download " + remotePath + " " + localPath)
+	return c.err
+}
+
+// newTransferCoordinator wires client and server to transferClients sharing events
+func newTransferCoordinator(test config.TestScenario, events *transferLog, err error) *Coordinator {
+	coord := newTestCoordinator([]config.TestScenario{test}, map[string]*fakeHostClient{
+		"client": {},
+		"server": {},
+	})
+	launch := func(handler func(ctx context.Context, command string) (*ssh.Result, error)) *fakeHostClient {
+		return &fakeHostClient{handler: func(ctx context.Context, command string) (*ssh.Result, error) {
+			if launched := launchedCommands([]string{command}); len(launched) == 1 && strings.HasPrefix(launched[0], "fake-client") {
+				events.add("run " + launched[0])
+			}
+			return handler(ctx, command)
+		}}
+	}
+	coord.sshClients["client"] = &transferClient{fakeHostClient: launch(succeed("done")), log: events, err: err}
+	coord.sshClients["server"] = &transferClient{fakeHostClient: launch(runForever(true)), log: events, err: err}
+	return coord
+}
+
+func TestExecuteTest_TransfersFilesAroundRun(t *testing.T) {
+	test := config.TestScenario{
+		Name:      "capture run",
+		Client:    "client",
+		Server:    "server",
+		Iteration: 2,
+		PreFiles:  []config.FileTransfer{{Host: "server", Local: "tune.sh", Remote: "/tmp/tune.sh"}},
+		PostFiles: []config.FileTransfer{{Host: "client", Local: "captures/{scenario}-{iteration}.pcap", Remote: "/tmp/run.pcap"}},
+	}
+	events := &transferLog{}
+	coord := newTransferCoordinator(test, events, nil)
+
+	result, err := newTestExecutor(coord).ExecuteTest(context.Background(), &test)
+	if err != nil {
+		t.Fatalf("ExecuteTest returned error: %v", err)
+	}
+	if !result.Success {
+		t.Fatalf("Expected success, got error %q", result.Error)
+	}
+
+	want := []string{
+		"upload tune.sh /tmp/tune.sh",
+		"run fake-client server",
+		"download /tmp/run.pcap captures/capture_run-2.pcap",
+	}
+	if got := strings.Join(events.events, "\n"); got != strings.Join(want, "\n") {
+		t.Errorf("Expected:\n%s\ngot:\n%s", strings.Join(want, "\n"), got)
+	}
+}
+
+func TestExecuteTest_FileTransferFailures(t *testing.T) {
+	upload := config.TestScenario{
+		Name:     "upload",
+		Client:   "client",
+		Server:   "server",
+		PreFiles: []config.FileTransfer{{Host: "server", Local: "tune.sh", Remote: "/tmp/tune.sh"}},
+	}
+	events := &transferLog{}
+	coord := newTransferCoordinator(upload, events, fmt.Errorf("no such file"))
+	if _, err := newTestExecutor(coord).ExecuteTest(context.Background(), &upload); err == nil || !strings.Contains(err.Error(), "pre_files") {
+		t.Errorf("Expected a pre_files error, got %v", err)
+	}
+	for _, event := range events.events {
+		if strings.HasPrefix(event, "run ") {
+			t.Errorf("Expected the client not to launch after a failed upload, got %s", event)
+		}
+	}
+
+	download := config.TestScenario{
+		Name:      "download",
+		Client:    "client",
+		Server:    "server",
+		PostFiles: []config.FileTransfer{{Host: "client", Local: "run.pcap", Remote: "/tmp/run.pcap"}},
+	}
+	coord = newTransferCoordinator(download, &transferLog{}, fmt.Errorf("no such file"))
+	result, err := newTestExecutor(coord).ExecuteTest(context.Background(), &download)
+	if err != nil {
+		t.Fatalf("ExecuteTest returned error: %v", err)
+	}
+	if !result.Success {
+		t.Errorf("Expected a failed download to leave the run successful, got error %q", result.Error)
+	}
+	if len(result.Warnings) != 1 || !strings.Contains(result.Warnings[0], "post_files") {
+		t.Errorf("Expected one post_files warning, got %v", result.Warnings)
+	}
+}
+
+// droppingTransferClient is a droppingClient whose transfers fail while its
+// connection is down
+type droppingTransferClient struct {
+	*droppingClient
+}
+
+func (d *droppingTransferClient) UploadFile(ctx context.Context, localPath, remotePath string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if !d.alive {
+		return fmt.Errorf("connection lost")
+	}
+	return nil
+}
+
+func (d *droppingTransferClient) DownloadFile(ctx context.Context, remotePath, localPath string) error {
+	return d.UploadFile(ctx, localPath, remotePath)
+}
+
+func TestTransferFile_RecoversDroppedConnection(t *testing.T) {
+	coord := newTestCoordinator(nil, map[string]*fakeHostClient{"server": {}})
+	client := &droppingTransferClient{&droppingClient{fakeHostClient: &fakeHostClient{}}}
+	coord.sshClients["server"] = client
+	coord.limiter = newCommandLimiter(1)
+
+	transfer := config.FileTransfer{Host: "server", Local: "tune.sh", Remote: "/tmp/tune.sh"}
+	if err := coord.transferFile(context.Background(), transfer, transfer.Local, true); err == nil {
+		t.Fatal("Expected the upload over the dropped connection to fail")
+	}
+	if client.reconnects != 1 {
+		t.Errorf("Expected the dropped connection to be re-established, got %d reconnects", client.reconnects)
+	}
+	if err := coord.transferFile(context.Background(), transfer, transfer.Local, true); err != nil {
+		t.Errorf("Expected the upload to succeed after reconnecting, got %v", err)
+	}
+	if slots := len(coord.limiter.slots); slots != 0 {
+		t.Errorf("Expected every command slot released, got %d held", slots)
+	}
+}
+
+func TestTransferFile_UnsupportedClient(t *testing.T) {
+	coord := newTestCoordinator(nil, map[string]*fakeHostClient{"server": {}})
+	coord.limiter = newCommandLimiter(1)
+
+	transfer := config.FileTransfer{Host: "server", Local: "tune.sh", Remote: "/tmp/tune.sh"}
+	err := coord.transferFile(context.Background(), transfer, transfer.Local, true)
+	if err == nil || err.Error() != "host server does not support file transfers" {
+		t.Errorf("Expected an unsupported-transfer error, got %v", err)
+	}
+}
@@ -0,0 +1,102 @@
+package coordinator
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"perf-runner/config"
+)
+
+// ScenarioFilter selects scenarios to run. A scenario is selected when its
+// name matches any of the Names glob patterns and it carries any of the
+// Tags; an empty list places no constraint.
+type ScenarioFilter struct {
+	Names []string
+	Tags  []string
+}
+
+// IsEmpty reports whether the filter selects every scenario
+func (f ScenarioFilter) IsEmpty() bool {
+	return len(f.Names) == 0 && len(f.Tags) == 0
+}
+
+// Validate checks that every name pattern is a valid glob
+func (f ScenarioFilter) Validate() error {
+	for _, pattern := range f.Names {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid filter pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// Matches reports whether test is selected by the filter
+func (f ScenarioFilter) Matches(test *config.TestScenario) bool {
+	return f.matchesName(test.Name) && f.matchesTags(test.Tags)
+}
+
+func (f ScenarioFilter) matchesName(name string) bool {
+	if len(f.Names) == 0 {
+		return true
+	}
+	for _, pattern := range f.Names {
+		// Exact names also match, since matrix names contain brackets
+		if matched, _ := path.Match(pattern, name); matched || pattern == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (f ScenarioFilter) matchesTags(tags []string) bool {
+	if len(f.Tags) == 0 {
+		return true
+	}
+	for _, want := range f.Tags {
+		for _, tag := range tags {
+			if tag == want {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// String describes the filter for log and error messages
+func (f ScenarioFilter) String() string {
+	var parts []string
+	if len(f.Names) > 0 {
+		parts = append(parts, "name "+strings.Join(f.Names, ", "))
+	}
+	if len(f.Tags) > 0 {
+		parts = append(parts, "tag "+strings.Join(f.Tags, ", "))
+	}
+	return strings.Join(parts, " and ")
+}
+
+// SelectScenarios limits the scenarios RunAllTests executes to those matched
+// by filter. It fails, leaving the scenarios unchanged, when the filter is
+// invalid or matches nothing.
+func (c *Coordinator) SelectScenarios(filter ScenarioFilter) error {
+	if filter.IsEmpty() {
+		return nil
+	}
+	if err := filter.Validate(); err != nil {
+		return err
+	}
+
+	var selected []config.TestScenario
+	for i := range c.config.Tests {
+		if filter.Matches(&c.config.Tests[i]) {
+			selected = append(selected, c.config.Tests[i])
+		}
+	}
+	if len(selected) == 0 {
+		return fmt.Errorf("no scenarios match %s (%d scenarios configured)", filter, len(c.config.Tests))
+	}
+
+	c.logger.Printf("Selected %d of %d scenarios matching %s", len(selected), len(c.config.Tests), filter)
+	c.config.Tests = selected
+	return nil
+}
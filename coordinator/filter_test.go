@@ -0,0 +1,70 @@
+package coordinator
+
+import (
+	"strings"
+	"testing"
+
+	"perf-runner/config"
+)
+
+func filterTests() []config.TestScenario {
+	return []config.TestScenario{
+		{Name: "tcp-small", Client: "client1", Server: "server", Tags: []string{"tcp", "smoke"}},
+		{Name: "tcp-large", Client: "client1", Server: "server", Tags: []string{"tcp"}},
+		{Name: "udp-small", Client: "client1", Server: "server", Tags: []string{"udp", "smoke"}},
+		{Name: "rdma [size=64]", Client: "client1", Server: "server"},
+	}
+}
+
+func selectedNames(c *Coordinator) string {
+	var names []string
+	for _, test := range c.config.Tests {
+		names = append(names, test.Name)
+	}
+	return strings.Join(names, ",")
+}
+
+func TestSelectScenarios(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter ScenarioFilter
+		want   string
+	}{
+		{"no filter", ScenarioFilter{}, "tcp-small,tcp-large,udp-small,rdma [size=64]"},
+		{"name glob", ScenarioFilter{Names: []string{"tcp-*"}}, "tcp-small,tcp-large"},
+		{"any of several names", ScenarioFilter{Names: []string{"*-small", "tcp-large"}}, "tcp-small,tcp-large,udp-small"},
+		{"exact name with brackets", ScenarioFilter{Names: []string{"rdma [size=64]"}}, "rdma [size=64]"},
+		{"tag", ScenarioFilter{Tags: []string{"smoke"}}, "tcp-small,udp-small"},
+		{"tag is not a glob", ScenarioFilter{Tags: []string{"sm*"}}, ""},
+		{"name and tag", ScenarioFilter{Names: []string{"tcp-*"}, Tags: []string{"smoke"}}, "tcp-small"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			coord := newTestCoordinator(filterTests(), nil)
+			err := coord.SelectScenarios(tt.filter)
+			if tt.want == "" {
+				if err == nil || !strings.Contains(err.Error(), "no scenarios match") {
+					t.Fatalf("Expected a no-match error, got %v", err)
+				}
+				if got := len(coord.config.Tests); got != 4 {
+					t.Errorf("Expected scenarios to be left unchanged, got %d", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("SelectScenarios returned error: %v", err)
+			}
+			if got := selectedNames(coord); got != tt.want {
+				t.Errorf("Expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestSelectScenarios_InvalidPattern(t *testing.T) {
+	coord := newTestCoordinator(filterTests(), nil)
+	if err := coord.SelectScenarios(ScenarioFilter{Names: []string{"tcp-["}}); err == nil {
+		t.Error("Expected an error for a malformed glob pattern")
+	}
+}
@@ -0,0 +1,174 @@
+package coordinator
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"perf-runner/runner"
+)
+
+// gidIndexAuto is the gid_index arg value that asks for the RoCEv2 GID
+// matching the host's data-plane address
+const gidIndexAuto = "auto"
+
+// gidTableCommand prints one "device|port|index|gid|type|netdev" line per
+// GID table entry from sysfs
+const gidTableCommand = `for f in /sys/class/infiniband/*/ports/*/gids/*; do ` +
+	`[ -e "$f" ] || continue; ` +
+	`p=${f%/gids/*}; idx=${f##*/}; port=${p##*/}; dev=${p%/ports/*}; dev=${dev##*/}; ` +
+	`echo "$dev|$port|$idx|$(cat "$f" 2>/dev/null)|$(cat "$p/gid_attrs/types/$idx" 2>/dev/null)|$(cat "$p/gid_attrs/ndevs/$idx" 2>/dev/null)"; ` +
+	`done`
+
+// gidEntry is one populated entry of an RDMA port's GID table
+type gidEntry struct {
+	device  string
+	port    int
+	index   int
+	gid     net.IP
+	gidType string // "IB/RoCE v1" or "RoCE v2"
+	netdev  string
+}
+
+// parseGIDTable parses gidTableCommand output, skipping unpopulated entries
+func parseGIDTable(output string) []gidEntry {
+	var entries []gidEntry
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Split(strings.TrimSpace(line), "|")
+		if len(fields) != 6 {
+			continue
+		}
+		port, err1 := strconv.Atoi(fields[1])
+		index, err2 := strconv.Atoi(fields[2])
+		gid := parseGID(fields[3])
+		if err1 != nil || err2 != nil || gid == nil || gid.IsUnspecified() {
+			continue
+		}
+		entries = append(entries, gidEntry{
+			device:  fields[0],
+			port:    port,
+			index:   index,
+			gid:     gid,
+			gidType: strings.TrimSpace(fields[4]),
+			netdev:  strings.TrimSpace(fields[5]),
+		})
+	}
+	return entries
+}
+
+// parseGID parses a sysfs GID ("fe80:0000:...:0001", eight groups of four hex digits)
+func parseGID(s string) net.IP {
+	groups := strings.Split(strings.TrimSpace(s), ":")
+	if len(groups) != 8 {
+		return nil
+	}
+	ip := make(net.IP, net.IPv6len)
+	for i, group := range groups {
+		v, err := strconv.ParseUint(group, 16, 16)
+		if err != nil {
+			return nil
+		}
+		ip[2*i], ip[2*i+1] = byte(v>>8), byte(v)
+	}
+	return ip
+}
+
+// selectRoCEv2GID returns the RoCEv2 entry whose address satisfies match,
+// optionally restricted to one RDMA device. IPv4 addresses appear in the
+// table as IPv4-mapped GIDs, which net.IP compares equal to their IPv4 form.
+func selectRoCEv2GID(entries []gidEntry, device string, match func(net.IP) bool) (gidEntry, bool) {
+	for _, entry := range entries {
+		if device != "" && entry.device != device {
+			continue
+		}
+		if !strings.Contains(strings.ToLower(entry.gidType), "v2") {
+			continue
+		}
+		if match(entry.gid) {
+			return entry, true
+		}
+	}
+	return gidEntry{}, false
+}
+
+// gidHost is a host whose gid_index may need resolving. address is the
+// host's data-plane address when known, otherwise its SSH address, and is
+// only used without a data_plane_subnet.
+type gidHost struct {
+	name    string
+	client  HostClient
+	config  *runner.Config
+	address string
+}
+
+// gidAddressMatcher matches GIDs inside the data-plane subnet when one is
+// configured, otherwise the host's known address
+func gidAddressMatcher(subnet *net.IPNet, address string) (func(net.IP) bool, string) {
+	if subnet != nil {
+		return subnet.Contains, "data_plane_subnet " + subnet.String()
+	}
+	ip := net.ParseIP(address)
+	if ip == nil {
+		return func(net.IP) bool { return false }, "an unknown address (set data_plane_subnet)"
+	}
+	return ip.Equal, "address " + address
+}
+
+// resolveGIDIndex replaces gid_index: auto in the host's config with its
+// RoCEv2 GID index for the data-plane address
+func (e *TestExecutor) resolveGIDIndex(ctx context.Context, host gidHost, subnet *net.IPNet) error {
+	args := host.config.GetEffectiveArgs()
+	if value, _ := args["gid_index"].(string); value != gidIndexAuto {
+		return nil
+	}
+
+	sshResult, err := host.client.ExecuteCommand(ctx, gidTableCommand)
+	if err != nil || sshResult == nil {
+		return fmt.Errorf("failed to read GID table on host %s: %w", host.name, err)
+	}
+
+	// Without a subnet, match the host's own address, as the host resolves
+	// its name
+	address := host.address
+	if subnet == nil && address != "" && net.ParseIP(address) == nil {
+		resolved, err := remoteResolver{client: host.client}.Resolve(ctx, address)
+		if err != nil {
+			return fmt.Errorf("cannot find the RoCE v2 GID of host %s: failed to resolve %s (%v); set data_plane_subnet", host.name, address, err)
+		}
+		address = resolved
+	}
+
+	device, _ := args["ib_dev"].(string)
+	match, describe := gidAddressMatcher(subnet, address)
+	entry, found := selectRoCEv2GID(parseGIDTable(sshResult.Output), device, match)
+	if !found {
+		return fmt.Errorf("host %s has no RoCE v2 GID for %s", host.name, describe)
+	}
+
+	e.coordinator.logger.Printf("  Using GID index %d (%s port %d, %s) on host %s", entry.index, entry.device, entry.port, entry.gid, host.name)
+	setRoleArg(host.config, "gid_index", entry.index)
+	return nil
+}
+
+// setRoleArg sets an arg for the config's role without mutating maps that
+// may be shared with the scenario configuration
+func setRoleArg(config *runner.Config, key string, value interface{}) {
+	withArg := func(m map[string]interface{}) map[string]interface{} {
+		copied := make(map[string]interface{}, len(m)+1)
+		for k, v := range m {
+			copied[k] = v
+		}
+		copied[key] = value
+		return copied
+	}
+	switch config.Role {
+	case "server":
+		config.ServerArgs = withArg(config.ServerArgs)
+	case "client":
+		config.ClientArgs = withArg(config.ClientArgs)
+	default:
+		config.Args = withArg(config.Args)
+	}
+}
@@ -0,0 +1,132 @@
+package coordinator
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+
+	"perf-runner/config"
+	"perf-runner/runner"
+	"perf-runner/ssh"
+)
+
+// gidTable is gidTableCommand output from a ConnectX port with 10.0.0.2 and
+// 192.168.1.2 configured; entries come in v1/v2 pairs per address
+const gidTable = `mlx5_0|1|0|fe80:0000:0000:0000:0a0b:0cff:fe0d:0e0f|IB/RoCE v1|ens1f0
+mlx5_0|1|1|fe80:0000:0000:0000:0a0b:0cff:fe0d:0e0f|RoCE v2|ens1f0
+mlx5_0|1|2|0000:0000:0000:0000:0000:ffff:0a00:0002|IB/RoCE v1|ens1f0
+mlx5_0|1|3|0000:0000:0000:0000:0000:ffff:0a00:0002|RoCE v2|ens1f0
+mlx5_0|1|4|0000:0000:0000:0000:0000:0000:0000:0000||
+mlx5_1|1|2|0000:0000:0000:0000:0000:ffff:c0a8:0102|IB/RoCE v1|ens1f1
+mlx5_1|1|3|0000:0000:0000:0000:0000:ffff:c0a8:0102|RoCE v2|ens1f1
+`
+
+func TestParseGIDTable(t *testing.T) {
+	entries := parseGIDTable(gidTable)
+	if len(entries) != 6 {
+		t.Fatalf("Expected 6 populated entries, got %d", len(entries))
+	}
+	if e := entries[3]; e.device != "mlx5_0" || e.port != 1 || e.index != 3 || e.gidType != "RoCE v2" || !e.gid.Equal(net.ParseIP("10.0.0.2")) {
+		t.Errorf("Unexpected entry: %+v", e)
+	}
+}
+
+func TestSelectRoCEv2GID(t *testing.T) {
+	entries := parseGIDTable(gidTable)
+	_, subnet, _ := net.ParseCIDR("192.168.1.0/24")
+
+	tests := []struct {
+		name      string
+		device    string
+		match     func(net.IP) bool
+		wantIndex int
+		wantDev   string
+		wantFound bool
+	}{
+		{"exact IPv4 address", "", net.ParseIP("10.0.0.2").Equal, 3, "mlx5_0", true},
+		{"subnet", "", subnet.Contains, 3, "mlx5_1", true},
+		{"device filter excludes match", "mlx5_1", net.ParseIP("10.0.0.2").Equal, 0, "", false},
+		{"unknown address", "", net.ParseIP("10.0.0.9").Equal, 0, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry, found := selectRoCEv2GID(entries, tt.device, tt.match)
+			if found != tt.wantFound {
+				t.Fatalf("found = %v, want %v", found, tt.wantFound)
+			}
+			if found && (entry.index != tt.wantIndex || entry.device != tt.wantDev) {
+				t.Errorf("selected %s index %d, want %s index %d", entry.device, entry.index, tt.wantDev, tt.wantIndex)
+			}
+		})
+	}
+}
+
+func TestResolveGIDIndex_InjectsIndex(t *testing.T) {
+	client := &fakeHostClient{handler: succeed(gidTable)}
+	executor := newTestExecutor(newTestCoordinator(nil, nil))
+
+	config := &runner.Config{Role: "server", Args: map[string]interface{}{"gid_index": "auto"}}
+	host := gidHost{name: "server", client: client, config: config, address: "10.0.0.2"}
+	if err := executor.resolveGIDIndex(context.Background(), host, nil); err != nil {
+		t.Fatalf("resolveGIDIndex returned error: %v", err)
+	}
+
+	if cmd := runner.NewIbSendBwRunner("").BuildCommand(*config); !strings.Contains(cmd, " -x 3") {
+		t.Errorf("Expected -x 3 in %q", cmd)
+	}
+	if config.Args["gid_index"] != "auto" {
+		t.Error("Expected the shared args map to be left unchanged")
+	}
+
+	// Without an address or subnet the client's GID cannot be chosen
+	clientHost := gidHost{name: "client", client: client, config: &runner.Config{Role: "client", Args: map[string]interface{}{"gid_index": "auto"}}}
+	if err := executor.resolveGIDIndex(context.Background(), clientHost, nil); err == nil {
+		t.Error("Expected an error when the client's address is unknown")
+	}
+}
+
+// gidHandler answers the GID table read and the host's lookup of its own
+// name with address, and otherwise behaves like next
+func gidHandler(address string, next func(ctx context.Context, command string) (*ssh.Result, error)) func(ctx context.Context, command string) (*ssh.Result, error) {
+	return func(ctx context.Context, command string) (*ssh.Result, error) {
+		switch {
+		case command == gidTableCommand:
+			return &ssh.Result{Output: gidTable}, nil
+		case strings.HasPrefix(command, "getent hosts "):
+			return &ssh.Result{Output: address + " " + strings.TrimPrefix(command, "getent hosts ") + "\n"}, nil
+		}
+		return next(ctx, command)
+	}
+}
+
+func TestExecuteTest_ResolvesClientGIDFromSSHAddress(t *testing.T) {
+	test := config.TestScenario{
+		Name:   "roce",
+		Client: "client",
+		Server: "server",
+		Config: &runner.Config{Args: map[string]interface{}{"gid_index": "auto"}},
+	}
+	client := &fakeHostClient{handler: gidHandler("10.0.0.2", succeed("done"))}
+	coord := newTestCoordinator([]config.TestScenario{test}, map[string]*fakeHostClient{
+		"client": client,
+		"server": {handler: gidHandler("192.168.1.2", runForever(true))},
+	})
+
+	// Without data_plane_subnet, the client's GID matches its SSH address
+	result, err := newTestExecutor(coord).ExecuteTest(context.Background(), &test)
+	if err != nil {
+		t.Fatalf("ExecuteTest returned error: %v", err)
+	}
+	if !result.Success {
+		t.Errorf("Expected the test to succeed, got error %q", result.Error)
+	}
+	var lookedUp bool
+	for _, command := range client.commands {
+		lookedUp = lookedUp || command == "getent hosts client"
+	}
+	if !lookedUp {
+		t.Errorf("Expected the client to resolve its SSH address, ran %v", client.commands)
+	}
+}
@@ -0,0 +1,76 @@
+package coordinator
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"perf-runner/config"
+)
+
+// groupBoundaries returns the index of the last scenario of each group, after
+// which the group's teardown runs
+func groupBoundaries(tests []config.TestScenario) map[string]int {
+	last := make(map[string]int)
+	for i, test := range tests {
+		if test.Group != "" {
+			last[test.Group] = i
+		}
+	}
+	return last
+}
+
+// setupGroup prepares the hosts of a scenario group before its first scenario
+func (c *Coordinator) setupGroup(ctx context.Context, name string) error {
+	group := c.config.GetGroup(name)
+	if group == nil || len(group.Setup) == 0 {
+		return nil
+	}
+	c.logger.Printf("Setting up group %s", name)
+	return c.runGroupCommands(ctx, group.Name, "setup", group.Setup)
+}
+
+// teardownGroup undoes a group's host preparation after its last scenario.
+// A failure is only logged, since every scenario of the group has run.
+func (c *Coordinator) teardownGroup(ctx context.Context, name string) {
+	group := c.config.GetGroup(name)
+	if group == nil || len(group.Teardown) == 0 {
+		return
+	}
+	c.logger.Printf("Tearing down group %s", name)
+	if err := c.runGroupCommands(ctx, group.Name, "teardown", group.Teardown); err != nil {
+		c.logger.Printf("Warning: %v", err)
+	}
+}
+
+// runGroupCommands runs each host's commands in order, hosts in name order,
+// stopping at the first failure
+func (c *Coordinator) runGroupCommands(ctx context.Context, group, phase string, commands map[string][]string) error {
+	hosts := make([]string, 0, len(commands))
+	for host := range commands {
+		hosts = append(hosts, host)
+	}
+	sort.Strings(hosts)
+
+	for _, host := range hosts {
+		client := c.hostClient(host)
+		if client == nil {
+			return fmt.Errorf("group %s %s: SSH client for host %s not connected", group, phase, host)
+		}
+		for _, command := range commands[host] {
+			c.logger.Printf("  %s on %s: %s", phase, host, command)
+			sshResult, err := client.ExecuteCommand(ctx, command)
+			if err == nil {
+				continue
+			}
+			detail := err.Error()
+			if sshResult != nil {
+				if tail := stderrTail(sshResult.Stderr, stderrTailLines); tail != "" {
+					detail = errorWithStderr(tail, detail)
+				}
+			}
+			return fmt.Errorf("group %s %s failed on %s: %s: %s", group, phase, host, command, detail)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package coordinator
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"perf-runner/config"
+	"perf-runner/ssh"
+)
+
+// groupedTests returns three scenarios of group "tuned" followed by one outside it
+func groupedTests() []config.TestScenario {
+	return []config.TestScenario{
+		{Name: "first", Client: "client", Server: "server", Group: "tuned"},
+		{Name: "second", Client: "client", Server: "server", Group: "tuned"},
+		{Name: "third", Client: "client", Server: "server", Group: "tuned"},
+		{Name: "ungrouped", Client: "client", Server: "server"},
+	}
+}
+
+func TestRunAllTests_GroupSetupRunsOnce(t *testing.T) {
+	client := &fakeHostClient{handler: succeed("ok")}
+	coord := newTestCoordinator(groupedTests(), map[string]*fakeHostClient{
+		"client": client,
+		"server": {handler: runForever(true)},
+	})
+	coord.config.Groups = []config.ScenarioGroup{{
+		Name:     "tuned",
+		Setup:    map[string][]string{"client": {"setup-a", "setup-b"}},
+		Teardown: map[string][]string{"client": {"teardown"}},
+	}}
+
+	results, err := coord.RunAllTests(context.Background())
+	if err != nil {
+		t.Fatalf("RunAllTests returned error: %v", err)
+	}
+	for _, result := range results {
+		if !result.Success {
+			t.Errorf("Expected %s to succeed, got error %q", result.ScenarioName, result.Error)
+		}
+	}
+
+	expected := []string{
+		"setup-a", "setup-b",
+		"fake-client server", "fake-client server", "fake-client server",
+		"teardown",
+		"fake-client server",
+	}
+	if fmt.Sprint(client.commands) != fmt.Sprint(expected) {
+		t.Errorf("Expected client commands %v, got %v", expected, client.commands)
+	}
+}
+
+func TestRunAllTests_GroupSetupFailureFailsGroup(t *testing.T) {
+	client := &fakeHostClient{handler: func(ctx context.Context, command string) (*ssh.Result, error) {
+		if command == "setup" {
+			return &ssh.Result{ExitCode: 1, Stderr: "permission denied"}, fmt.Errorf("Process exited with status 1")
+		}
+		return &ssh.Result{Output: "ok"}, nil
+	}}
+	coord := newTestCoordinator(groupedTests(), map[string]*fakeHostClient{
+		"client": client,
+		"server": {handler: runForever(true)},
+	})
+	coord.config.Groups = []config.ScenarioGroup{{
+		Name:     "tuned",
+		Setup:    map[string][]string{"client": {"setup"}},
+		Teardown: map[string][]string{"client": {"teardown"}},
+	}}
+
+	results, err := coord.RunAllTests(context.Background())
+	if err != nil {
+		t.Fatalf("RunAllTests returned error: %v", err)
+	}
+	if len(results) != 4 {
+		t.Fatalf("Expected a result per scenario, got %d", len(results))
+	}
+	for _, result := range results[:3] {
+		if result.Success || !strings.Contains(result.Error, "permission denied") {
+			t.Errorf("Expected %s to fail with the setup error, got success=%v error %q", result.ScenarioName, result.Success, result.Error)
+		}
+	}
+	if !results[3].Success {
+		t.Errorf("Expected the ungrouped scenario to run, got error %q", results[3].Error)
+	}
+
+	expected := []string{"setup", "teardown", "fake-client server"}
+	if fmt.Sprint(client.commands) != fmt.Sprint(expected) {
+		t.Errorf("Expected client commands %v, got %v", expected, client.commands)
+	}
+}
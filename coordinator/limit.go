@@ -0,0 +1,111 @@
+package coordinator
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"perf-runner/ssh"
+)
+
+// commandLimiter bounds the number of remote commands in flight across all
+// hosts and scenarios, protecting shared bastions from session storms
+type commandLimiter struct {
+	slots chan struct{}
+}
+
+// newCommandLimiter returns a limiter allowing max concurrent commands
+func newCommandLimiter(max int) *commandLimiter {
+	return &commandLimiter{slots: make(chan struct{}, max)}
+}
+
+// acquire waits for a free slot; the caller must release it when done
+func (l *commandLimiter) acquire(ctx context.Context) error {
+	select {
+	case l.slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("waiting for a free command slot: %w", ctx.Err())
+	}
+}
+
+// release frees a slot taken by acquire
+func (l *commandLimiter) release() {
+	<-l.slots
+}
+
+// limitedClient runs a host's commands only while holding a limiter slot
+type limitedClient struct {
+	HostClient
+	limiter *commandLimiter
+}
+
+// ExecuteCommand waits for a free slot, then runs the command holding it
+func (l *limitedClient) ExecuteCommand(ctx context.Context, command string) (*ssh.Result, error) {
+	return l.ExecuteCommandStream(ctx, command, nil)
+}
+
+// ExecuteCommandStream waits for a free slot, then runs the command holding
+// it, streaming its output if the host's client can
+func (l *limitedClient) ExecuteCommandStream(ctx context.Context, command string, live io.Writer) (*ssh.Result, error) {
+	if err := l.limiter.acquire(ctx); err != nil {
+		return nil, err
+	}
+	defer l.limiter.release()
+	
+	return executeStream(ctx, l.HostClient, command, live)
+}
+
+// UploadFile waits for a free slot, then copies the file holding it
+func (l *limitedClient) UploadFile(ctx context.Context, localPath, remotePath string) error {
+	transferer, ok := l.HostClient.(FileTransferer)
+	if !ok {
+		return errNoFileTransfers
+	}
+	if err := l.limiter.acquire(ctx); err != nil {
+		return err
+	}
+	defer l.limiter.release()
+	
+	return transferer.UploadFile(ctx, localPath, remotePath)
+}
+
+// DownloadFile waits for a free slot, then copies the file holding it
+func (l *limitedClient) DownloadFile(ctx context.Context, remotePath, localPath string) error {
+	transferer, ok := l.HostClient.(FileTransferer)
+	if !ok {
+		return errNoFileTransfers
+	}
+	if err := l.limiter.acquire(ctx); err != nil {
+		return err
+	}
+	defer l.limiter.release()
+	
+	return transferer.DownloadFile(ctx, remotePath, localPath)
+}
+
+// probeClient returns the client an in-run probe, such as a host's sampler,
+// runs its commands through. Probes only run while roles do, at most one
+// sampler per host, so they bypass the global command limit: the roles hold
+// their slots for the whole run and would otherwise leave every probe
+// waiting until the client exits.
+func probeClient(client HostClient) HostClient {
+	if limited, ok := client.(*limitedClient); ok {
+		return limited.HostClient
+	}
+	return client
+}
+
+// hostClient returns the client of a connected host, tracking its connection
+// and bounded by the global command limit when one is configured
+func (c *Coordinator) hostClient(name string) HostClient {
+	client, exists := c.sshClients[name]
+	if !exists {
+		return client
+	}
+	client = &trackedClient{HostClient: client, host: name, tracker: c.connections}
+	if c.limiter == nil {
+		return client
+	}
+	return &limitedClient{HostClient: client, limiter: c.limiter}
+}
@@ -0,0 +1,174 @@
+package coordinator
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"perf-runner/config"
+	"perf-runner/runner"
+	"perf-runner/ssh"
+)
+
+// inFlightCounter tracks how many commands run at once across host clients
+type inFlightCounter struct {
+	mu      sync.Mutex
+	current int
+	peak    int
+}
+
+// countingClient reports every command it runs to a shared inFlightCounter,
+// except those of the hosts' samplers, which bypass the limit
+type countingClient struct {
+	HostClient
+	counter *inFlightCounter
+}
+
+func (c *countingClient) ExecuteCommand(ctx context.Context, command string) (*ssh.Result, error) {
+	if isSamplerCommand(command) {
+		return c.HostClient.ExecuteCommand(ctx, command)
+	}
+	c.counter.mu.Lock()
+	c.counter.current++
+	if c.counter.current > c.counter.peak {
+		c.counter.peak = c.counter.current
+	}
+	c.counter.mu.Unlock()
+	defer func() {
+		c.counter.mu.Lock()
+		c.counter.current--
+		c.counter.mu.Unlock()
+	}()
+
+	return c.HostClient.ExecuteCommand(ctx, command)
+}
+
+// slowSucceed succeeds after a short pause so concurrent commands overlap
+func slowSucceed(ctx context.Context, command string) (*ssh.Result, error) {
+	time.Sleep(5 * time.Millisecond)
+	return &ssh.Result{Output: "done"}, nil
+}
+
+// pinnedServer answers CPU affinity probes and otherwise runs like a server
+func pinnedServer(ctx context.Context, command string) (*ssh.Result, error) {
+	if strings.HasPrefix(command, "p=$(cat ") {
+		return slowSucceed(ctx, command)
+	}
+	return runForever(true)(ctx, command)
+}
+
+func TestRunAllTests_ConcurrencyLimit(t *testing.T) {
+	const limit = 3
+	// Pinned roles add a sampler reading their placement back on each host;
+	// samplers bypass the limit and are not counted
+	pinned := &runner.Config{CPUAffinity: "0-1"}
+	tests := []config.TestScenario{
+		{Name: "fan-out", Client: "client1", Clients: []string{"client2"}, Server: "server", Config: pinned, Repeat: 3},
+		{Name: "pair", Client: "client2", Server: "server", Config: pinned, Repeat: 3},
+	}
+	coord := newTestCoordinator(tests, map[string]*fakeHostClient{
+		"client1": {handler: slowSucceed, probe: slowSucceed},
+		"client2": {handler: slowSucceed, probe: slowSucceed},
+		"server":  {handler: pinnedServer, probe: slowSucceed},
+	})
+	coord.config.MaxConcurrentCommands = limit
+	coord.limiter = newCommandLimiter(limit)
+	coord.SetCaching(false)
+
+	counter := &inFlightCounter{}
+	for name, client := range coord.sshClients {
+		coord.sshClients[name] = &countingClient{HostClient: client, counter: counter}
+	}
+
+	results, err := coord.RunAllTests(context.Background())
+	if err != nil {
+		t.Fatalf("RunAllTests returned error: %v", err)
+	}
+	for _, result := range results {
+		if !result.Success {
+			t.Errorf("Expected %s to pass under the limit, got error %q", result.ScenarioName, result.Error)
+		}
+	}
+
+	if counter.peak > limit {
+		t.Errorf("Expected at most %d commands in flight, saw %d", limit, counter.peak)
+	}
+	if counter.peak < 2 {
+		t.Errorf("Expected commands to overlap, peak was %d", counter.peak)
+	}
+}
+
+func TestExecuteTest_ProbesRunAtRoleLimit(t *testing.T) {
+	test := config.TestScenario{
+		Name:         "probed",
+		Client:       "client",
+		Server:       "server",
+		ThermalCheck: true,
+		Config:       &runner.Config{CPUAffinity: "2-3", Args: map[string]interface{}{"congestion": "bbr"}},
+	}
+	// The client keeps its slot until its sampler has read its connections
+	// and placement
+	var once sync.Once
+	probed := make(chan struct{})
+	client := samplingHandler(map[string]func() string{
+		"thermal_zone": inTurn(thermalSample(50000, 0)),
+		"ss -tin ":     inTurn(ssBBROutput),
+		"Cpus_allowed_list": func() string {
+			once.Do(func() { close(probed) })
+			return pinnedStatus
+		},
+	}, func(ctx context.Context, command string) (*ssh.Result, error) {
+		select {
+		case <-probed:
+			return &ssh.Result{Output: "done"}, nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	})
+	server := samplingHandler(map[string]func() string{
+		"thermal_zone":      inTurn(thermalSample(50000, 0)),
+		"Cpus_allowed_list": inTurn(pinnedStatus),
+	}, runForever(true))
+	coord := newTestCoordinator([]config.TestScenario{test}, map[string]*fakeHostClient{
+		"client": {handler: client},
+		"server": {handler: server},
+	})
+	coord.config.MaxConcurrentCommands = test.ConcurrentRoles()
+	coord.limiter = newCommandLimiter(test.ConcurrentRoles())
+	executor := newTestExecutor(coord)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	result, err := executor.ExecuteTest(ctx, &test)
+	if err != nil {
+		t.Fatalf("ExecuteTest returned error: %v", err)
+	}
+	if !result.Success {
+		t.Fatalf("Expected success, got error %q", result.Error)
+	}
+	for role, roleResult := range map[string]*runner.Result{"client": result.ClientResult, "server": result.ServerResult} {
+		if got := roleResult.Metrics["actual_cpu_affinity"]; got != "2-3" {
+			t.Errorf("%s: expected actual_cpu_affinity 2-3, got %v", role, got)
+		}
+		if _, sampled := roleResult.Metrics["thermal_throttled"]; !sampled {
+			t.Errorf("%s: expected thermal samples, got metrics %v", role, roleResult.Metrics)
+		}
+	}
+	if got := result.ClientResult.Metrics["cc_used"]; got != "bbr" {
+		t.Errorf("Expected cc_used bbr, got %v", got)
+	}
+}
+
+func TestLimitedClient_GivesUpWhenCancelled(t *testing.T) {
+	limiter := newCommandLimiter(1)
+	limiter.slots <- struct{}{}
+	client := &limitedClient{HostClient: &fakeHostClient{handler: succeed("done")}, limiter: limiter}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := client.ExecuteCommand(ctx, "true"); err == nil {
+		t.Error("Expected an error while waiting for a slot after cancellation")
+	}
+}
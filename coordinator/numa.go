@@ -0,0 +1,124 @@
+package coordinator
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"perf-runner/runner"
+)
+
+// numaSampledRunners are the runners whose hosts get NUMA locality sampled
+// around the test. Cross-node memory access is a common DPDK bottleneck.
+var numaSampledRunners = map[string]bool{
+	"testpmd": true,
+}
+
+// numaCounters are system-wide NUMA allocation counters in MB
+type numaCounters struct {
+	localMB   float64
+	foreignMB float64
+}
+
+// parseNumastat reads the Local_Node and Numa_Foreign rows of `numastat -n`
+// output, taking the Total column (or the sum of nodes on single-node hosts,
+// where numastat omits it)
+func parseNumastat(output string) (numaCounters, error) {
+	var counters numaCounters
+	var foundLocal, foundForeign bool
+
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+
+		var target *float64
+		switch strings.ToLower(fields[0]) {
+		case "local_node":
+			target, foundLocal = &counters.localMB, true
+		case "numa_foreign":
+			target, foundForeign = &counters.foreignMB, true
+		default:
+			continue
+		}
+
+		value, err := strconv.ParseFloat(fields[len(fields)-1], 64)
+		if err != nil {
+			return numaCounters{}, fmt.Errorf("invalid numastat value in %q: %w", line, err)
+		}
+		*target = value
+	}
+
+	if !foundLocal || !foundForeign {
+		return numaCounters{}, fmt.Errorf("numastat output has no Local_Node/Numa_Foreign rows")
+	}
+	return counters, nil
+}
+
+// monitorNuma samples the NUMA counters of each host throughout the run
+func monitorNuma(samplers *hostSamplers, hosts []testHost) map[string]*deltaCollector {
+	// -n prints the numa_hit/foreign/local counters in MB; -m is meminfo only
+	return samplers.deltas(hosts, func(testHost) string { return "numastat -n" })
+}
+
+// numaSamples returns the NUMA counters of each host at the start and end of
+// the run, keyed by host name. Hosts sampled fewer than twice or without
+// numastat are left out.
+func (e *TestExecutor) numaSamples(collectors map[string]*deltaCollector) (before, after map[string]numaCounters) {
+	before = make(map[string]numaCounters)
+	after = make(map[string]numaCounters)
+	for name, collector := range collectors {
+		if collector.samples < 2 {
+			continue
+		}
+		start, err := parseNumastat(collector.first)
+		if err != nil {
+			e.coordinator.logger.Printf("  Warning: numastat unavailable on %s: %v", name, err)
+			continue
+		}
+		end, err := parseNumastat(collector.last)
+		if err != nil {
+			e.coordinator.logger.Printf("  Warning: failed to parse numastat on %s: %v", name, err)
+			continue
+		}
+		before[name], after[name] = start, end
+	}
+	return before, after
+}
+
+// roleResultsByHost maps each host of a test to the result of the role it ran
+func roleResultsByHost(result *TestResult) map[string]*runner.Result {
+	hostResults := map[string]*runner.Result{
+		result.Hosts["client"]:       result.ClientResult,
+		result.Hosts["server"]:       result.ServerResult,
+		result.Hosts["intermediate"]: result.IntermediateResult,
+	}
+	for name, clientResult := range result.ClientResults {
+		hostResults[name] = clientResult
+	}
+	for _, node := range result.NodeResults {
+		if node.Result != nil {
+			hostResults[node.Host] = node.Result
+		}
+	}
+	return hostResults
+}
+
+// recordNumaDeltas attaches numa_local_mb/numa_foreign_mb, the change in the
+// counters over the test, to the metrics of the role each host ran
+func recordNumaDeltas(result *TestResult, before, after map[string]numaCounters) {
+	hostResults := roleResultsByHost(result)
+	for name, start := range before {
+		end, ok := after[name]
+		roleResult := hostResults[name]
+		if !ok || roleResult == nil {
+			continue
+		}
+		if roleResult.Metrics == nil {
+			roleResult.Metrics = make(map[string]interface{})
+		}
+		roleResult.Metrics["numa_local_mb"] = end.localMB - start.localMB
+		roleResult.Metrics["numa_foreign_mb"] = end.foreignMB - start.foreignMB
+	}
+}
@@ -0,0 +1,77 @@
+package coordinator
+
+import (
+	"testing"
+
+	"perf-runner/runner"
+)
+
+const numastatTwoNode = `
+Per-node numastat info (in MBs):
+                          Node 0          Node 1           Total
+                 --------------- --------------- ---------------
+Numa_Hit               123456.25        98765.50       222221.75
+Numa_Miss                  12.00          345.00          357.00
+Numa_Foreign              345.00           12.00          357.00
+Interleave_Hit              1.50            1.25            2.75
+Local_Node             123400.00        98700.00       222100.00
+Other_Node                 68.25          410.50          478.75
+`
+
+const numastatOneNode = `
+Per-node numastat info (in MBs):
+                          Node 0
+                 ---------------
+Numa_Hit                 5120.00
+Numa_Miss                   0.00
+Numa_Foreign                0.00
+Interleave_Hit              0.50
+Local_Node               5119.00
+Other_Node                  1.00
+`
+
+func TestParseNumastat(t *testing.T) {
+	tests := []struct {
+		name        string
+		output      string
+		wantLocal   float64
+		wantForeign float64
+		wantErr     bool
+	}{
+		{"two nodes uses total", numastatTwoNode, 222100, 357, false},
+		{"single node", numastatOneNode, 5119, 0, false},
+		{"meminfo output", "Per-node system memory usage (in MBs):\nMemTotal 1024.00\n", 0, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			counters, err := parseNumastat(tt.output)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseNumastat() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if counters.localMB != tt.wantLocal || counters.foreignMB != tt.wantForeign {
+				t.Errorf("parseNumastat() = %+v, want local %v foreign %v", counters, tt.wantLocal, tt.wantForeign)
+			}
+		})
+	}
+}
+
+func TestRecordNumaDeltas(t *testing.T) {
+	result := &TestResult{
+		Hosts:              map[string]string{"client": "gen", "server": "sink", "intermediate": "dut"},
+		ClientResult:       &runner.Result{Metrics: map[string]interface{}{}},
+		IntermediateResult: &runner.Result{},
+	}
+	before := map[string]numaCounters{"dut": {localMB: 1000, foreignMB: 10}, "gen": {localMB: 50}}
+	after := map[string]numaCounters{"dut": {localMB: 1800, foreignMB: 410}}
+
+	recordNumaDeltas(result, before, after)
+
+	metrics := result.IntermediateResult.Metrics
+	if metrics["numa_local_mb"] != 800.0 || metrics["numa_foreign_mb"] != 400.0 {
+		t.Errorf("Unexpected intermediate NUMA deltas: %v", metrics)
+	}
+	if _, ok := result.ClientResult.Metrics["numa_local_mb"]; ok {
+		t.Error("Expected no delta for a host missing its second sample")
+	}
+}
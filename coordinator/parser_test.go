@@ -0,0 +1,62 @@
+package coordinator
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"perf-runner/config"
+	"perf-runner/runner"
+)
+
+// metricRunner is a fakeRunner whose built-in parser reports a fixed metric
+type metricRunner struct {
+	fakeRunner
+}
+
+func (r *metricRunner) ParseMetrics(result *runner.Result) error {
+	result.Metrics["bandwidth_mbps"] = 1.0
+	return nil
+}
+
+func TestExecuteTest_UsesConfiguredParser(t *testing.T) {
+	runner.RegisterParser("test_site_parser", func(result *runner.Result) error {
+		if strings.Contains(result.Output, "42 units") {
+			result.Metrics["bandwidth_mbps"] = 42.0
+		}
+		return nil
+	})
+
+	tests := []config.TestScenario{
+		{Name: "custom", Client: "client", Server: "server", Parser: "test_site_parser"},
+		{Name: "built-in", Client: "client", Server: "server"},
+	}
+	coord := newTestCoordinator(tests, map[string]*fakeHostClient{
+		"client": {handler: succeed("measured 42 units")},
+		"server": {handler: runForever(true)},
+	})
+	coord.RegisterRunner("fake", &metricRunner{})
+
+	want := []float64{42, 1}
+	for i := range tests {
+		result, err := newTestExecutor(coord).ExecuteTest(context.Background(), &tests[i])
+		if err != nil {
+			t.Fatalf("%s: ExecuteTest returned error: %v", tests[i].Name, err)
+		}
+		if got := result.ClientResult.Metrics["bandwidth_mbps"]; got != want[i] {
+			t.Errorf("%s: expected bandwidth_mbps %v, got %v", tests[i].Name, want[i], got)
+		}
+	}
+}
+
+func TestExecuteTest_UnknownParser(t *testing.T) {
+	test := config.TestScenario{Name: "missing", Client: "client", Server: "server", Parser: "no_such_parser"}
+	coord := newTestCoordinator([]config.TestScenario{test}, map[string]*fakeHostClient{
+		"client": {handler: succeed("done")},
+		"server": {handler: runForever(true)},
+	})
+
+	if _, err := newTestExecutor(coord).ExecuteTest(context.Background(), &test); err == nil || !strings.Contains(err.Error(), "no_such_parser") {
+		t.Errorf("Expected an error naming the unknown parser, got %v", err)
+	}
+}
@@ -0,0 +1,181 @@
+package coordinator
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+
+	"perf-runner/envinfo"
+	"perf-runner/runner"
+)
+
+// testHost identifies a host taking part in a test scenario
+type testHost struct {
+	role   string
+	name   string
+	client HostClient
+	port   int            // port the host accepts test traffic on, if any
+	config *runner.Config // the role's runner configuration
+}
+
+// runPreflightChecks inspects each participating host before the test starts
+// and records anything likely to distort results as a warning. subnet is the
+// scenario's data-plane subnet, or nil.
+func (e *TestExecutor) runPreflightChecks(ctx context.Context, result *TestResult, hosts []testHost, subnet *net.IPNet) {
+	for _, host := range hosts {
+		executor := envinfo.NewRemoteExecutor(host.client)
+		
+		pciInfo := e.checkPCILinks(ctx, result, host, executor)
+		
+		if pciInfo != nil && host.config != nil && host.config.CPUAffinity != "" {
+			e.checkNumaPlacement(ctx, result, host, executor, pciInfo, subnet)
+		}
+		
+		if host.port > 0 {
+			e.checkFirewall(ctx, result, host, executor)
+		}
+	}
+}
+
+// checkPCILinks warns about devices whose PCIe link trained below its
+// capability, which silently caps bandwidth. It returns the PCI information
+// for further checks, or nil if it could not be collected.
+func (e *TestExecutor) checkPCILinks(ctx context.Context, result *TestResult, host testHost, executor envinfo.CommandExecutor) *envinfo.PCIInfo {
+	pciModule := envinfo.NewPCIModule()
+	if !pciModule.IsAvailable(ctx, executor) {
+		return nil
+	}
+	data, err := pciModule.Collect(ctx, executor)
+	if err != nil {
+		e.coordinator.logger.Printf("  Warning: preflight PCI check failed on %s: %v", host.name, err)
+		return nil
+	}
+	pciInfo, ok := data.(*envinfo.PCIInfo)
+	if !ok {
+		return nil
+	}
+	for _, warning := range pciInfo.LinkWarnings() {
+		e.addWarning(result, fmt.Sprintf("%s %s: %s", host.role, host.name, warning))
+	}
+	return pciInfo
+}
+
+// checkNumaPlacement warns when the role's pinned cores are on a different
+// NUMA node than the NICs carrying the test, a common cause of poor DPDK
+// throughput
+func (e *TestExecutor) checkNumaPlacement(ctx context.Context, result *TestResult, host testHost, executor envinfo.CommandExecutor, pciInfo *envinfo.PCIInfo, subnet *net.IPNet) {
+	nics := e.testNICs(ctx, host, subnet)
+	if len(nics) == 0 {
+		return
+	}
+	
+	topologyModule := envinfo.NewTopologyModule()
+	if !topologyModule.IsAvailable(ctx, executor) {
+		return
+	}
+	data, err := topologyModule.Collect(ctx, executor)
+	if err != nil {
+		e.coordinator.logger.Printf("  Warning: preflight NUMA check failed on %s: %v", host.name, err)
+		return
+	}
+	if topology, ok := data.(*envinfo.TopologyInfo); ok {
+		if warning := envinfo.NumaPlacementWarning(pciInfo, topology, host.config.CPUAffinity, nics); warning != "" {
+			e.addWarning(result, fmt.Sprintf("%s %s: %s", host.role, host.name, warning))
+		}
+	}
+}
+
+// testNICs returns the PCI addresses of the host's interfaces carrying test
+// traffic: the one holding the role's bind_address, else those in the
+// data-plane subnet. It returns nil when neither identifies an interface.
+func (e *TestExecutor) testNICs(ctx context.Context, host testHost, subnet *net.IPNet) []string {
+	bindAddress, _ := host.config.GetEffectiveArgs()["bind_address"].(string)
+	if bindAddress == "" && subnet == nil {
+		return nil
+	}
+	networkInfo, err := hostNetworkInfo(ctx, host.name, host.client)
+	if err != nil {
+		e.coordinator.logger.Printf("  Warning: preflight NUMA check failed on %s: %v", host.name, err)
+		return nil
+	}
+	
+	interfaces := networkInfo.InterfacesWithAddress(bindAddress)
+	if bindAddress == "" {
+		interfaces = networkInfo.InterfacesInSubnet(subnet)
+	}
+	var nics []string
+	for _, iface := range interfaces {
+		if iface.PCIAddress != "" {
+			nics = append(nics, iface.PCIAddress)
+		}
+	}
+	return nics
+}
+
+// checkFirewall warns when the host's firewall appears to drop or reject
+// inbound traffic on the test port, a common cause of zero-throughput runs
+func (e *TestExecutor) checkFirewall(ctx context.Context, result *TestResult, host testHost, executor envinfo.CommandExecutor) {
+	firewallModule := envinfo.NewFirewallModule()
+	if !firewallModule.IsAvailable(ctx, executor) {
+		return
+	}
+	data, err := firewallModule.Collect(ctx, executor)
+	if err != nil {
+		e.coordinator.logger.Printf("  Warning: preflight firewall check failed on %s: %v", host.name, err)
+		return
+	}
+	if firewallInfo, ok := data.(*envinfo.FirewallInfo); ok {
+		if rule, blocked := firewallInfo.BlockingRule(host.port); blocked {
+			e.addWarning(result, fmt.Sprintf("%s %s: port %d appears to be blocked by firewall rule %q", host.role, host.name, host.port, rule))
+		}
+	}
+}
+
+// defaultLoadTimeout bounds reading the hosts' load, so a slow host delays
+// its test only briefly
+const defaultLoadTimeout = 10 * time.Second
+
+// recordLoad records the load of each host taking part in the test
+func (e *TestExecutor) recordLoad(ctx context.Context, result *TestResult, hosts []testHost) {
+	ctx, cancel := context.WithTimeout(ctx, e.loadTimeout)
+	defer cancel()
+	for _, host := range hosts {
+		if _, recorded := result.Load[host.name]; recorded {
+			continue
+		}
+		e.checkLoad(ctx, result, host, envinfo.NewRemoteExecutor(host.client))
+	}
+}
+
+// checkLoad records the host's load averages on the result and warns when
+// the 1-minute load exceeds its core count, as background work then competes
+// with the test for CPUs
+func (e *TestExecutor) checkLoad(ctx context.Context, result *TestResult, host testHost, executor envinfo.CommandExecutor) {
+	loadModule := envinfo.NewLoadModule()
+	if !loadModule.IsAvailable(ctx, executor) {
+		return
+	}
+	data, err := loadModule.Collect(ctx, executor)
+	if err != nil {
+		e.coordinator.logger.Printf("  Warning: preflight load check failed on %s: %v", host.name, err)
+		return
+	}
+	load, ok := data.(*envinfo.LoadInfo)
+	if !ok {
+		return
+	}
+	if result.Load == nil {
+		result.Load = make(map[string]*envinfo.LoadInfo)
+	}
+	result.Load[host.name] = load
+	if load.Overloaded() {
+		e.addWarning(result, fmt.Sprintf("%s %s: 1-minute load %.2f exceeds its %d cores", host.role, host.name, load.Load1, load.CPUs))
+	}
+}
+
+// addWarning records a non-fatal problem on the result and logs it
+func (e *TestExecutor) addWarning(result *TestResult, warning string) {
+	result.Warnings = append(result.Warnings, warning)
+	e.coordinator.logger.Printf("  Warning: %s", warning)
+}
@@ -0,0 +1,146 @@
+package coordinator
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+
+	"perf-runner/config"
+	"perf-runner/runner"
+	"perf-runner/ssh"
+)
+
+func TestRunPreflightChecks_WarnsOnBlockedPort(t *testing.T) {
+	ruleset := "-P INPUT ACCEPT\n-A INPUT -p tcp -m tcp --dport 5201 -j DROP\n"
+	server := &fakeHostClient{handler: func(ctx context.Context, command string) (*ssh.Result, error) {
+		if strings.HasPrefix(command, "iptables -S") {
+			return &ssh.Result{Output: ruleset}, nil
+		}
+		return &ssh.Result{}, nil
+	}}
+	coord := newTestCoordinator(nil, map[string]*fakeHostClient{"server": server})
+	executor := newTestExecutor(coord)
+
+	for _, tt := range []struct {
+		port int
+		warn bool
+	}{{5201, true}, {5202, false}} {
+		result := &TestResult{}
+		executor.runPreflightChecks(context.Background(), result, []testHost{
+			{role: "server", name: "server", client: server, port: tt.port},
+		}, nil)
+		if got := len(result.Warnings) == 1 && strings.Contains(result.Warnings[0], "blocked"); got != tt.warn {
+			t.Errorf("port %d: expected blocked warning %v, got warnings %v", tt.port, tt.warn, result.Warnings)
+		}
+	}
+}
+
+func TestRunPreflightChecks_WarnsOnCrossNumaAffinity(t *testing.T) {
+	lspci := "0000:3b:00.0 Ethernet controller: Intel Corporation Ethernet Controller E810-C\n\tNUMA node: 0\n\n" +
+		"0000:d8:00.0 Ethernet controller: Intel Corporation Ethernet Controller E810-C\n\tNUMA node: 1\n"
+	lscpu := "# CPU,Node\n0,0\n1,0\n2,1\n3,1\n"
+	server := &fakeHostClient{handler: func(ctx context.Context, command string) (*ssh.Result, error) {
+		switch {
+		case strings.HasPrefix(command, "lspci"):
+			return &ssh.Result{Output: lspci}, nil
+		case strings.HasPrefix(command, "lscpu"):
+			return &ssh.Result{Output: lscpu}, nil
+		case strings.HasPrefix(command, "ip link show"):
+			return &ssh.Result{Output: "eth0\neth1\n"}, nil
+		case strings.HasPrefix(command, "ip addr show eth0 "):
+			return &ssh.Result{Output: "192.168.1.5/24\n"}, nil
+		case strings.HasPrefix(command, "ip addr show eth1 "):
+			return &ssh.Result{Output: "10.0.0.5/24\n"}, nil
+		case strings.HasPrefix(command, "readlink /sys/class/net/eth0/device "):
+			return &ssh.Result{Output: "../../../0000:3b:00.0\n"}, nil
+		case strings.HasPrefix(command, "readlink /sys/class/net/eth1/device "):
+			return &ssh.Result{Output: "../../../0000:d8:00.0\n"}, nil
+		}
+		return &ssh.Result{}, nil
+	}}
+	coord := newTestCoordinator(nil, map[string]*fakeHostClient{"server": server})
+	executor := newTestExecutor(coord)
+	_, subnet, _ := net.ParseCIDR("192.168.1.0/24")
+
+	// The host has a NIC on each node, so only the test's NIC tells them apart
+	for _, tt := range []struct {
+		name   string
+		cpus   string
+		bind   string
+		subnet *net.IPNet
+		warn   bool
+	}{
+		{"bind address on node 1", "0-1", "10.0.0.5", nil, true},
+		{"bind address on node 1", "2-3", "10.0.0.5", nil, false},
+		{"subnet on node 0", "2-3", "", subnet, true},
+		{"subnet on node 0", "0-1", "", subnet, false},
+		{"bind address over subnet", "2-3", "10.0.0.5", subnet, false},
+		{"no test interface", "2-3", "", nil, false},
+		{"no affinity", "", "10.0.0.5", nil, false},
+	} {
+		result := &TestResult{}
+		config := &runner.Config{CPUAffinity: tt.cpus}
+		if tt.bind != "" {
+			config.Args = map[string]interface{}{"bind_address": tt.bind}
+		}
+		executor.runPreflightChecks(context.Background(), result, []testHost{
+			{role: "server", name: "server", client: server, config: config},
+		}, tt.subnet)
+		if got := len(result.Warnings) == 1 && strings.Contains(result.Warnings[0], "cross-NUMA"); got != tt.warn {
+			t.Errorf("%s, cpu_affinity %q: expected cross-NUMA warning %v, got warnings %v", tt.name, tt.cpus, tt.warn, result.Warnings)
+		}
+	}
+}
+
+func TestRecordLoad(t *testing.T) {
+	server := &fakeHostClient{probe: succeed("/usr/bin/uptime"), handler: func(ctx context.Context, command string) (*ssh.Result, error) {
+		switch command {
+		case "uptime":
+			return &ssh.Result{Output: " 10:14:03 up 12 days,  3:02,  2 users,  load average: 9.10, 4.00, 2.00\n"}, nil
+		case "nproc":
+			return &ssh.Result{Output: "8\n"}, nil
+		}
+		return &ssh.Result{}, nil
+	}}
+	coord := newTestCoordinator(nil, map[string]*fakeHostClient{"server": server})
+	executor := newTestExecutor(coord)
+
+	result := &TestResult{}
+	executor.recordLoad(context.Background(), result, []testHost{
+		{role: "server", name: "server", client: server},
+	})
+	load := result.Load["server"]
+	if load == nil || load.Load1 != 9.1 || load.CPUs != 8 {
+		t.Fatalf("Expected the server's load recorded, got %+v", load)
+	}
+	if len(result.Warnings) != 1 || !strings.Contains(result.Warnings[0], "exceeds its 8 cores") {
+		t.Errorf("Expected an overload warning, got %v", result.Warnings)
+	}
+}
+
+func TestExecuteTest_RecordsLoadWithoutCollectEnv(t *testing.T) {
+	test := config.TestScenario{Name: "load", Client: "client", Server: "server"}
+	uptime := func(next func(ctx context.Context, command string) (*ssh.Result, error)) func(ctx context.Context, command string) (*ssh.Result, error) {
+		return func(ctx context.Context, command string) (*ssh.Result, error) {
+			if command == "uptime" {
+				return &ssh.Result{Output: " 10:14:03 up 12 days,  3:02,  2 users,  load average: 0.50, 0.40, 0.30\n"}, nil
+			}
+			return next(ctx, command)
+		}
+	}
+	coord := newTestCoordinator([]config.TestScenario{test}, map[string]*fakeHostClient{
+		"client": {probe: succeed("/usr/bin/uptime"), handler: uptime(succeed("done"))},
+		"server": {probe: succeed("/usr/bin/uptime"), handler: uptime(runForever(true))},
+	})
+
+	result, err := newTestExecutor(coord).ExecuteTest(context.Background(), &test)
+	if err != nil {
+		t.Fatalf("ExecuteTest returned error: %v", err)
+	}
+	for _, host := range []string{"client", "server"} {
+		if load := result.Load[host]; load == nil || load.Load1 != 0.5 {
+			t.Errorf("Expected %s's load recorded without collect_env, got %+v", host, load)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package coordinator
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"perf-runner/config"
+	"perf-runner/runner"
+	"perf-runner/ssh"
+)
+
+// durationRunner renders the configured duration so prewarm commands can be told apart
+type durationRunner struct {
+	fakeRunner
+}
+
+func (r *durationRunner) BuildCommand(config runner.Config) string {
+	return r.fakeRunner.BuildCommand(config) + " -t " + config.Duration.String()
+}
+
+func TestRunAllTests_PrewarmResultDiscarded(t *testing.T) {
+	test := config.TestScenario{
+		Name:    "rdma",
+		Client:  "client",
+		Server:  "server",
+		Prewarm: true,
+		Config:  &runner.Config{Duration: 30 * time.Second},
+	}
+	client := &fakeHostClient{handler: func(ctx context.Context, command string) (*ssh.Result, error) {
+		if strings.HasSuffix(command, " -t 2s") {
+			return &ssh.Result{Output: "prewarm"}, nil
+		}
+		return &ssh.Result{Output: "measured"}, nil
+	}}
+	coord := newTestCoordinator([]config.TestScenario{test}, map[string]*fakeHostClient{
+		"client": client,
+		"server": {handler: runForever(true)},
+	})
+	coord.RegisterRunner("fake", &durationRunner{})
+
+	results, err := coord.RunAllTests(context.Background())
+	if err != nil {
+		t.Fatalf("RunAllTests returned error: %v", err)
+	}
+
+	if len(client.commands) != 2 {
+		t.Fatalf("Expected prewarm and measured client commands, got %v", client.commands)
+	}
+	if client.commands[0] != "fake-client server -t 2s" {
+		t.Errorf("Expected a 2s prewarm command first, got %q", client.commands[0])
+	}
+	if !strings.HasSuffix(client.commands[1], " -t 30s") {
+		t.Errorf("Expected the measured run to keep its duration, got %q", client.commands[1])
+	}
+
+	if len(results) != 1 {
+		t.Fatalf("Expected only the measured result, got %d", len(results))
+	}
+	if results[0].ScenarioName != "rdma" || results[0].ClientResult.Output != "measured" {
+		t.Errorf("Expected the measured result, got %s with output %q", results[0].ScenarioName, results[0].ClientResult.Output)
+	}
+	if status := coord.Status(); len(status.Results) != 1 {
+		t.Errorf("Expected the prewarm run to be left out of the status, got %d results", len(status.Results))
+	}
+}
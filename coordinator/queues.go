@@ -0,0 +1,72 @@
+package coordinator
+
+import (
+	"fmt"
+	"sort"
+
+	"perf-runner/envinfo"
+)
+
+// monitorQueueStats samples the per-queue RX packet counters of the
+// interface configured for each host in queues throughout the run
+func monitorQueueStats(samplers *hostSamplers, hosts []testHost, queues map[string]string) map[string]*deltaCollector {
+	return samplers.deltas(hosts, func(host testHost) string {
+		if iface, ok := queues[host.name]; ok {
+			return envinfo.QueueStatsCommand(iface)
+		}
+		return ""
+	})
+}
+
+// queueStats returns the per-queue RX packet counters of each host at the
+// start and end of the run, keyed by host name. Hosts sampled fewer than
+// twice or whose driver reports no per-queue counters are left out.
+func queueStats(collectors map[string]*deltaCollector) (before, after map[string]map[int]int64) {
+	before = make(map[string]map[int]int64)
+	after = make(map[string]map[int]int64)
+	for name, collector := range collectors {
+		if collector.samples < 2 {
+			continue
+		}
+		if counters := envinfo.ParseRxQueuePackets(collector.first); len(counters) > 0 {
+			before[name] = counters
+			after[name] = envinfo.ParseRxQueuePackets(collector.last)
+		}
+	}
+	return before, after
+}
+
+// recordQueueStats attaches rx_queue_<n>_packets, the packets each RX queue
+// received during the test, and rx_queue_imbalance, the busiest to idlest
+// queue ratio, to the metrics of the role each host ran. Queues that
+// received nothing are named in a warning instead of a ratio.
+func (e *TestExecutor) recordQueueStats(result *TestResult, queues map[string]string, before, after map[string]map[int]int64) {
+	hostResults := roleResultsByHost(result)
+	hosts := make([]string, 0, len(before))
+	for name := range before {
+		hosts = append(hosts, name)
+	}
+	sort.Strings(hosts)
+
+	for _, name := range hosts {
+		end, ok := after[name]
+		roleResult := hostResults[name]
+		if !ok || roleResult == nil {
+			continue
+		}
+
+		deltas := envinfo.QueueDeltas(before[name], end)
+		if roleResult.Metrics == nil {
+			roleResult.Metrics = make(map[string]interface{})
+		}
+		for queue, packets := range deltas {
+			roleResult.Metrics[fmt.Sprintf("rx_queue_%d_packets", queue)] = packets
+		}
+		if ratio, ok := envinfo.QueueImbalance(deltas); ok {
+			roleResult.Metrics["rx_queue_imbalance"] = ratio
+		}
+		if idle := envinfo.IdleQueues(deltas); len(idle) > 0 && len(idle) < len(deltas) {
+			e.addWarning(result, fmt.Sprintf("RX queues %v of %s on %s received no packets during the test", idle, queues[name], name))
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package coordinator
+
+import (
+	"strings"
+	"testing"
+
+	"perf-runner/runner"
+)
+
+func TestRecordQueueStats(t *testing.T) {
+	coord := newTestCoordinator(nil, nil)
+	executor := newTestExecutor(coord)
+	result := &TestResult{
+		Hosts:        map[string]string{"client": "gen", "server": "sink"},
+		ClientResult: &runner.Result{},
+		ServerResult: &runner.Result{},
+	}
+	queues := map[string]string{"gen": "ens1f0", "sink": "ens2f0"}
+	before := map[string]map[int]int64{
+		"gen":  {0: 100, 1: 100},
+		"sink": {0: 0, 1: 0, 2: 0},
+	}
+	after := map[string]map[int]int64{
+		"gen":  {0: 1100, 1: 3100},
+		"sink": {0: 500, 1: 700, 2: 0},
+	}
+
+	executor.recordQueueStats(result, queues, before, after)
+
+	client := result.ClientResult.Metrics
+	if client["rx_queue_0_packets"] != int64(1000) || client["rx_queue_1_packets"] != int64(3000) {
+		t.Errorf("Unexpected client queue deltas: %v", client)
+	}
+	if client["rx_queue_imbalance"] != 3.0 {
+		t.Errorf("Expected a 3.0 client imbalance, got %v", client["rx_queue_imbalance"])
+	}
+	if _, ok := result.ServerResult.Metrics["rx_queue_imbalance"]; ok {
+		t.Error("Expected no server imbalance ratio with an idle queue")
+	}
+	if len(result.Warnings) != 1 || !strings.Contains(result.Warnings[0], "RX queues [2] of ens2f0 on sink received no packets") {
+		t.Errorf("Expected one idle queue warning, got %v", result.Warnings)
+	}
+}
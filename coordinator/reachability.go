@@ -0,0 +1,117 @@
+package coordinator
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"perf-runner/config"
+)
+
+// SetSkipUnreachable chooses what a failed host connection does. By default
+// ConnectHosts fails on the first unreachable host; with skip enabled it
+// returns, and every scenario needing an unreachable host is skipped.
+func (c *Coordinator) SetSkipUnreachable(skip bool) {
+	c.skipUnreachable = skip
+}
+
+// UnreachableHosts returns the hosts ConnectHosts could not connect to, in
+// name order
+func (c *Coordinator) UnreachableHosts() []string {
+	hosts := make([]string, 0, len(c.unreachable))
+	for host := range c.unreachable {
+		hosts = append(hosts, host)
+	}
+	sort.Strings(hosts)
+	return hosts
+}
+
+// scenarioHosts returns every host a scenario needs: its own hosts and the
+// hosts its group prepares
+func (c *Coordinator) scenarioHosts(test *config.TestScenario) []string {
+	hosts := test.HostNames()
+	if group := c.config.GetGroup(test.Group); group != nil {
+		var groupHosts []string
+		for _, commands := range []map[string][]string{group.Setup, group.Teardown} {
+			for host := range commands {
+				groupHosts = append(groupHosts, host)
+			}
+		}
+		sort.Strings(groupHosts)
+		hosts = dedupe(append(hosts, groupHosts...))
+	}
+	return hosts
+}
+
+// referencedHosts returns the hosts used by the selected scenarios, including
+// their fallback hosts, in name order. Other configured hosts are not needed.
+func (c *Coordinator) referencedHosts() []string {
+	referenced := make(map[string]bool)
+	for i := range c.config.Tests {
+		test := &c.config.Tests[i]
+		for _, host := range c.scenarioHosts(test) {
+			referenced[host] = true
+		}
+		for _, host := range test.FallbackHosts {
+			referenced[host] = true
+		}
+	}
+
+	hosts := make([]string, 0, len(referenced))
+	for host := range referenced {
+		hosts = append(hosts, host)
+	}
+	sort.Strings(hosts)
+	return hosts
+}
+
+// unreachableHostsOf returns the scenario's hosts that could not be
+// connected, or nil if it can run. A scenario whose fallback hosts replace
+// every unreachable host runs, and falls back when its primary hosts fail.
+func (c *Coordinator) unreachableHostsOf(test *config.TestScenario) []string {
+	var unreachable []string
+	for _, host := range c.scenarioHosts(test) {
+		if _, failed := c.unreachable[host]; failed {
+			unreachable = append(unreachable, host)
+		}
+	}
+	if len(unreachable) == 0 || len(test.FallbackHosts) == 0 {
+		return unreachable
+	}
+
+	for _, host := range c.scenarioHosts(test.WithFallbackHosts()) {
+		if _, failed := c.unreachable[host]; failed {
+			return unreachable
+		}
+	}
+	return nil
+}
+
+// skippedResult is the result of a scenario not run because hosts it needs
+// are unreachable
+func skippedResult(test *config.TestScenario, unreachable []string) *TestResult {
+	now := time.Now()
+	return &TestResult{
+		ScenarioName: test.Name,
+		Success:      false,
+		Skipped:      true,
+		Error:        fmt.Sprintf("skipped: unreachable hosts: %s", strings.Join(unreachable, ", ")),
+		ErrorClass:   string(ErrorTransient),
+		StartTime:    now,
+		EndTime:      now,
+	}
+}
+
+// dedupe returns names without repeats, keeping the first of each
+func dedupe(names []string) []string {
+	var unique []string
+	seen := make(map[string]bool)
+	for _, name := range names {
+		if !seen[name] {
+			seen[name] = true
+			unique = append(unique, name)
+		}
+	}
+	return unique
+}
@@ -0,0 +1,78 @@
+package coordinator
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"reflect"
+	"strings"
+	"testing"
+
+	"perf-runner/config"
+	"perf-runner/ssh"
+)
+
+func TestConnectHosts_OnlyReferencedHosts(t *testing.T) {
+	// Without an authentication method every connect fails at once
+	host := func(addr string) *config.HostConfig {
+		return &config.HostConfig{SSH: &ssh.Config{Host: addr, User: "test"}}
+	}
+	cfg := &config.TestConfig{
+		Name:  "reachability",
+		Hosts: map[string]*config.HostConfig{"client": host("10.0.0.1"), "server": host("10.0.0.2"), "spare": host("10.0.0.3")},
+		Tests: []config.TestScenario{{Name: "tcp", Client: "client", Server: "server"}},
+	}
+
+	coord := NewCoordinator(cfg, log.New(io.Discard, "", 0))
+	err := coord.ConnectHosts(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "host client") || strings.Contains(err.Error(), "spare") {
+		t.Errorf("Expected errors for the used hosts only, got %v", err)
+	}
+
+	coord = NewCoordinator(cfg, log.New(io.Discard, "", 0))
+	coord.SetSkipUnreachable(true)
+	if err := coord.ConnectHosts(context.Background()); err != nil {
+		t.Fatalf("Expected unreachable hosts to be skipped, got %v", err)
+	}
+	if got := coord.UnreachableHosts(); !reflect.DeepEqual(got, []string{"client", "server"}) {
+		t.Errorf("UnreachableHosts = %v", got)
+	}
+	report := coord.ConnectionReport()
+	if len(report) != 2 || report[0].Connected || report[0].Events[0].Event != eventConnectFailed {
+		t.Errorf("Expected the failed connects in the connection report, got %+v", report)
+	}
+}
+
+func TestRunAllTests_SkipsScenariosOnUnreachableHosts(t *testing.T) {
+	tests := []config.TestScenario{
+		{Name: "down", Client: "client", Server: "down", Repeat: 2},
+		{Name: "up", Client: "client", Server: "server"},
+		{Name: "fallback", Client: "client", Server: "down", FallbackHosts: map[string]string{"server": "server"}},
+	}
+	server := &fakeHostClient{handler: runForever(true)}
+	coord := newTestCoordinator(tests, map[string]*fakeHostClient{
+		"client": {handler: succeed("ok")},
+		"server": server,
+	})
+	coord.unreachable = map[string]error{"down": fmt.Errorf("connection refused")}
+
+	results, err := coord.RunAllTests(context.Background())
+	if err != nil {
+		t.Fatalf("RunAllTests returned error: %v", err)
+	}
+	if len(results) != 4 {
+		t.Fatalf("Expected a result per iteration, got %d", len(results))
+	}
+	for _, result := range results[:2] {
+		if !result.Skipped || result.Success || !strings.Contains(result.Error, "unreachable hosts: down") {
+			t.Errorf("Expected the scenario on the down host skipped, got %+v", result)
+		}
+	}
+	if results[2].Skipped || !results[2].Success {
+		t.Errorf("Expected the scenario on reachable hosts to run, got %+v", results[2])
+	}
+	if results[3].Skipped || !results[3].FallbackUsed {
+		t.Errorf("Expected the scenario with a reachable fallback to run on it, got %+v", results[3])
+	}
+}
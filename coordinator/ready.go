@@ -0,0 +1,135 @@
+package coordinator
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"perf-runner/config"
+	"perf-runner/runner"
+)
+
+// defaultReadyPollInterval is how often a background role's host is polled
+// for its readiness when the scenario sets no server_ready_interval
+const defaultReadyPollInterval = 250 * time.Millisecond
+
+// defaultServerPorts are the ports runners' servers listen on when no port
+// is configured, for runners without their own readiness probe. Runners
+// without a TCP listener (testpmd) are not listed.
+var defaultServerPorts = map[string]int{
+	"iperf3":     5201,
+	"ib_send_bw": 18515,
+}
+
+// serverListenPort returns the TCP port the server will listen on, or 0 if
+// it is unknown
+func serverListenPort(r runner.Runner, serverConfig *runner.Config) int {
+	if serverConfig.Port > 0 {
+		return serverConfig.Port
+	}
+	return defaultServerPorts[r.Name()]
+}
+
+// readinessCheck is how the executor confirms that a background role is
+// ready before starting the roles that connect to it
+type readinessCheck struct {
+	command   string // Exits 0 once the role is ready
+	outputLog string // Remote file the role's stdout is copied to, or ""
+	notReady  string // Describes the timeout, e.g. "not listening on port 5201"
+}
+
+// newReadinessCheck returns how to confirm that the role started with
+// roleConfig is ready, or nil to wait startupDelay instead. Checks run only
+// with server_ready_timeout set. The runner's own probe is used when it has
+// one; otherwise a server is checked for its listen port.
+func newReadinessCheck(r runner.Runner, roleConfig *runner.Config, test *config.TestScenario) *readinessCheck {
+	if test.ServerReadyTimeout <= 0 {
+		return nil
+	}
+
+	if prober, ok := r.(runner.ReadinessProber); ok {
+		probe := prober.ReadinessProbe(*roleConfig)
+		switch {
+		case probe.LogPattern != "":
+			outputLog := remoteOutputLog(roleConfig.Role)
+			return &readinessCheck{
+				command:   fmt.Sprintf("grep -qE %s %s", shellQuote(probe.LogPattern), outputLog),
+				outputLog: outputLog,
+				notReady:  fmt.Sprintf("not ready (no output matching %q)", probe.LogPattern),
+			}
+		case probe.Command != "":
+			return &readinessCheck{
+				command:  probe.Command,
+				notReady: fmt.Sprintf("not ready (probe %q still failing)", probe.Command),
+			}
+		}
+	}
+
+	if roleConfig.Role == "server" {
+		if port := serverListenPort(r, roleConfig); port > 0 {
+			return &readinessCheck{
+				command:  runner.ListeningProbe(port).Command,
+				notReady: fmt.Sprintf("not listening on port %d", port),
+			}
+		}
+	}
+	return nil
+}
+
+// logFile returns the file the role's stdout must be copied to, or "" when
+// the check does not read it
+func (c *readinessCheck) logFile() string {
+	if c == nil {
+		return ""
+	}
+	return c.outputLog
+}
+
+// remoteOutputLog returns a unique path on the remote host for a copy of a
+// command's stdout
+func remoteOutputLog(role string) string {
+	return fmt.Sprintf("/tmp/perf-runner-%d-%d-%s.out", time.Now().UnixNano(), pidFileSeq.Add(1), role)
+}
+
+// teeOutput copies the stdout of command to outputLog while it runs, so a
+// readiness check can search it. The command's exit status is kept and the
+// files are removed once it exits.
+func teeOutput(command, outputLog string) string {
+	return fmt.Sprintf(`{ %[1]s; echo $? > %[2]s.status; } | tee %[2]s; s=$(cat %[2]s.status 2>/dev/null || echo 1); rm -f %[2]s %[2]s.status; exit $s`, command, outputLog)
+}
+
+// waitForReady waits until the role started on client on host is ready.
+// Without a check it waits for startupDelay; with one, the host is polled
+// every server_ready_interval until the check passes, failing the test if
+// it does not within server_ready_timeout.
+func (e *TestExecutor) waitForReady(ctx context.Context, client HostClient, test *config.TestScenario, role, host string, check *readinessCheck) error {
+	if check == nil {
+		select {
+		case <-ctx.Done():
+			return errTestTimedOut
+		case <-time.After(e.startupDelay):
+			return nil
+		}
+	}
+
+	interval := test.ServerReadyInterval
+	if interval <= 0 {
+		interval = defaultReadyPollInterval
+	}
+	readyCtx, cancel := context.WithTimeout(ctx, test.ServerReadyTimeout)
+	defer cancel()
+
+	for {
+		if _, err := client.ExecuteCommand(readyCtx, check.command); err == nil {
+			return nil
+		}
+		select {
+		case <-readyCtx.Done():
+			if ctx.Err() != nil {
+				return errTestTimedOut
+			}
+			return fmt.Errorf("%s on %s %s after %s", role, host, check.notReady, test.ServerReadyTimeout)
+		case <-time.After(interval):
+		}
+	}
+}
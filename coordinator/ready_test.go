@@ -0,0 +1,227 @@
+package coordinator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"perf-runner/config"
+	"perf-runner/runner"
+	"perf-runner/ssh"
+)
+
+// listeningServer returns a server handler whose port starts listening after
+// failures unsuccessful listen checks, counting every check in checks
+func listeningServer(failures int32, checks *atomic.Int32) func(ctx context.Context, command string) (*ssh.Result, error) {
+	return func(ctx context.Context, command string) (*ssh.Result, error) {
+		if strings.HasPrefix(command, "ss -ltn") {
+			if checks.Add(1) <= failures {
+				return &ssh.Result{ExitCode: 1}, fmt.Errorf("Process exited with status 1")
+			}
+			return &ssh.Result{}, nil
+		}
+		return runForever(true)(ctx, command)
+	}
+}
+
+func TestExecuteTest_WaitsForServerPort(t *testing.T) {
+	test := config.TestScenario{
+		Name:                "ready",
+		Client:              "client",
+		Server:              "server",
+		Config:              &runner.Config{Port: 5300},
+		ServerReadyTimeout:  5 * time.Second,
+		ServerReadyInterval: time.Millisecond,
+	}
+	var checks atomic.Int32
+	client := &fakeHostClient{handler: succeed("ok")}
+	coord := newTestCoordinator([]config.TestScenario{test}, map[string]*fakeHostClient{
+		"client": client,
+		"server": {handler: listeningServer(2, &checks)},
+	})
+
+	result, err := newTestExecutor(coord).ExecuteTest(context.Background(), &test)
+	if err != nil {
+		t.Fatalf("ExecuteTest returned error: %v", err)
+	}
+	if !result.Success {
+		t.Errorf("Expected success, got error %q", result.Error)
+	}
+	if got := checks.Load(); got != 3 {
+		t.Errorf("Expected the port to be checked until it listened (3 checks), got %d", got)
+	}
+	if len(client.commands) != 1 {
+		t.Errorf("Expected the client to run once, got %v", client.commands)
+	}
+}
+
+func TestExecuteTest_ServerNeverReady(t *testing.T) {
+	test := config.TestScenario{
+		Name:                "not ready",
+		Client:              "client",
+		Server:              "server",
+		Config:              &runner.Config{Port: 5300},
+		ServerReadyTimeout:  50 * time.Millisecond,
+		ServerReadyInterval: 5 * time.Millisecond,
+	}
+	var checks atomic.Int32
+	client := &fakeHostClient{handler: succeed("ok")}
+	coord := newTestCoordinator([]config.TestScenario{test}, map[string]*fakeHostClient{
+		"client": client,
+		"server": {handler: listeningServer(1<<30, &checks)},
+	})
+
+	_, err := newTestExecutor(coord).ExecuteTest(context.Background(), &test)
+	if err == nil || !strings.Contains(err.Error(), "not listening on port 5300") {
+		t.Fatalf("Expected a server readiness error, got %v", err)
+	}
+	if len(client.commands) != 0 {
+		t.Errorf("Expected the client not to start, got %v", client.commands)
+	}
+}
+
+func TestWaitForReady_StartupDelayHonorsContext(t *testing.T) {
+	executor := newTestExecutor(newTestCoordinator(nil, nil))
+	executor.startupDelay = time.Hour
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	err := executor.waitForReady(ctx, nil, &config.TestScenario{}, "server", "server", nil)
+	if !errors.Is(err, errTestTimedOut) {
+		t.Errorf("Expected errTestTimedOut, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected the wait to end with the context, took %v", elapsed)
+	}
+}
+
+func TestServerListenPort(t *testing.T) {
+	for _, tt := range []struct {
+		runner string
+		port   int
+		want   int
+	}{{"iperf3", 0, 5201}, {"iperf3", 6000, 6000}, {"ib_send_bw", 0, 18515}, {"testpmd", 0, 0}} {
+		r, err := runner.Create(tt.runner)
+		if err != nil {
+			t.Fatalf("Create(%s): %v", tt.runner, err)
+		}
+		if got := serverListenPort(r, &runner.Config{Port: tt.port}); got != tt.want {
+			t.Errorf("%s port %d: got %d, want %d", tt.runner, tt.port, got, tt.want)
+		}
+	}
+}
+
+// probedRunner is a fakeRunner with its own readiness probe
+type probedRunner struct {
+	fakeRunner
+	probe runner.ReadinessProbe
+}
+
+func (r *probedRunner) ReadinessProbe(config runner.Config) runner.ReadinessProbe {
+	return r.probe
+}
+
+func TestExecuteTest_PollsRunnerReadinessProbe(t *testing.T) {
+	test := config.TestScenario{
+		Name:                "probed",
+		Client:              "client",
+		Server:              "server",
+		ServerReadyTimeout:  5 * time.Second,
+		ServerReadyInterval: time.Millisecond,
+	}
+	var checks atomic.Int32
+	server := &fakeHostClient{handler: func(ctx context.Context, command string) (*ssh.Result, error) {
+		if command == "check-ready" {
+			if checks.Add(1) <= 2 {
+				return &ssh.Result{ExitCode: 1}, fmt.Errorf("Process exited with status 1")
+			}
+			return &ssh.Result{}, nil
+		}
+		return runForever(true)(ctx, command)
+	}}
+	coord := newTestCoordinator([]config.TestScenario{test}, map[string]*fakeHostClient{
+		"client": {handler: succeed("ok")},
+		"server": server,
+	})
+	coord.RegisterRunner("fake", &probedRunner{probe: runner.ReadinessProbe{Command: "check-ready"}})
+
+	result, err := newTestExecutor(coord).ExecuteTest(context.Background(), &test)
+	if err != nil {
+		t.Fatalf("ExecuteTest returned error: %v", err)
+	}
+	if !result.Success {
+		t.Errorf("Expected success, got error %q", result.Error)
+	}
+	if got := checks.Load(); got != 3 {
+		t.Errorf("Expected the runner's probe to be polled until it passed (3 checks), got %d", got)
+	}
+	for _, command := range server.commands {
+		if strings.HasPrefix(command, "ss -ltn") {
+			t.Errorf("Expected no generic port check, got %q", command)
+		}
+	}
+}
+
+func TestExecuteTest_WaitsForForwarderLogLine(t *testing.T) {
+	test := config.TestScenario{
+		Name:                "forwarder",
+		Client:              "gen",
+		Intermediate:        "relay",
+		Server:              "sink",
+		ServerReadyTimeout:  5 * time.Second,
+		ServerReadyInterval: time.Millisecond,
+	}
+
+	// Each background role is ready on its second look at its output log
+	var greps atomic.Int32
+	background := func(ctx context.Context, command string) (*ssh.Result, error) {
+		if strings.HasPrefix(command, "grep -qE 'packet forwarding' ") {
+			if greps.Add(1)%2 == 1 {
+				return &ssh.Result{ExitCode: 1}, fmt.Errorf("Process exited with status 1")
+			}
+			return &ssh.Result{}, nil
+		}
+		if strings.HasPrefix(command, "fake-") {
+			return runForever(true)(ctx, command)
+		}
+		return &ssh.Result{}, nil
+	}
+	relay := &fakeHostClient{handler: background}
+	coord := newTestCoordinator([]config.TestScenario{test}, map[string]*fakeHostClient{
+		"gen":   {handler: succeed("ok")},
+		"relay": relay,
+		"sink":  {handler: background},
+	})
+	coord.RegisterRunner("fake", &probedRunner{probe: runner.ReadinessProbe{LogPattern: "packet forwarding"}})
+
+	result, err := newTestExecutor(coord).ExecuteTest(context.Background(), &test)
+	if err != nil {
+		t.Fatalf("ExecuteTest returned error: %v", err)
+	}
+	if !result.Success {
+		t.Errorf("Expected success, got error %q", result.Error)
+	}
+	if got := greps.Load(); got != 4 {
+		t.Errorf("Expected the server's and relay's logs to be checked twice each, got %d checks", got)
+	}
+
+	// The relay's stdout is copied to the log its probe searches
+	var launched, probed string
+	for _, command := range launchedCommands(relay.commands) {
+		switch {
+		case strings.Contains(command, "fake-intermediate"):
+			launched = command
+		case strings.HasPrefix(command, "grep -qE"):
+			probed = command
+		}
+	}
+	logFile := probed[strings.LastIndex(probed, " ")+1:]
+	if !strings.HasPrefix(launched, "{ fake-intermediate sink; ") || !strings.Contains(launched, "| tee "+logFile+";") {
+		t.Errorf("Expected the relay command to copy its output to %s, got %q", logFile, launched)
+	}
+}
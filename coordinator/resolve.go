@@ -0,0 +1,96 @@
+package coordinator
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+
+	"perf-runner/config"
+)
+
+// Resolver maps a host name to the IP address commands should target
+type Resolver interface {
+	Resolve(ctx context.Context, name string) (string, error)
+}
+
+// controllerResolver resolves names with this machine's resolver
+type controllerResolver struct{}
+
+func (controllerResolver) Resolve(ctx context.Context, name string) (string, error) {
+	addrs, err := net.DefaultResolver.LookupHost(ctx, name)
+	if err != nil {
+		return "", err
+	}
+	if len(addrs) == 0 {
+		return "", fmt.Errorf("no addresses for %s", name)
+	}
+	return addrs[0], nil
+}
+
+// remoteResolver resolves names on a remote host, which may see different
+// DNS or /etc/hosts entries than the controller
+type remoteResolver struct {
+	client HostClient
+}
+
+func (r remoteResolver) Resolve(ctx context.Context, name string) (string, error) {
+	sshResult, err := r.client.ExecuteCommand(ctx, fmt.Sprintf("getent hosts %s", name))
+	if err != nil || sshResult == nil {
+		return "", fmt.Errorf("getent failed: %v", err)
+	}
+	// getent prints "<address> <names...>" per line
+	fields := strings.Fields(sshResult.Output)
+	if len(fields) == 0 || net.ParseIP(fields[0]) == nil {
+		return "", fmt.Errorf("no addresses for %s", name)
+	}
+	return fields[0], nil
+}
+
+// newResolver returns the resolver for a resolve_hosts mode; via is the host
+// that will connect to the resolved address
+func newResolver(mode string, via HostClient) Resolver {
+	if mode == config.ResolveOnHost {
+		return remoteResolver{client: via}
+	}
+	return controllerResolver{}
+}
+
+// addressPins holds the addresses host names were pinned to for this run
+type addressPins struct {
+	mu    sync.Mutex
+	addrs map[string]string
+}
+
+// pinAddress resolves name once per run and returns the pinned IP. IP
+// literals are returned unchanged. The pin is recorded on the result.
+func (e *TestExecutor) pinAddress(ctx context.Context, name string, via HostClient, result *TestResult) (string, error) {
+	if name == "" || net.ParseIP(name) != nil {
+		return name, nil
+	}
+	
+	pins := &e.coordinator.pins
+	pins.mu.Lock()
+	defer pins.mu.Unlock()
+	
+	address, ok := pins.addrs[name]
+	if !ok {
+		resolver := e.coordinator.newResolver(e.coordinator.config.ResolveHosts, via)
+		var err error
+		if address, err = resolver.Resolve(ctx, name); err != nil {
+			return "", fmt.Errorf("failed to resolve %s: %w", name, err)
+		}
+		if pins.addrs == nil {
+			pins.addrs = make(map[string]string)
+		}
+		pins.addrs[name] = address
+		e.coordinator.logger.Printf("  Pinned %s to %s for this run", name, address)
+	}
+	
+	if result.ResolvedAddresses == nil {
+		result.ResolvedAddresses = make(map[string]string)
+	}
+	result.ResolvedAddresses[name] = address
+	return address, nil
+}
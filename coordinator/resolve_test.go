@@ -0,0 +1,75 @@
+package coordinator
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"perf-runner/config"
+	"perf-runner/ssh"
+)
+
+// fakeResolver hands out a new address on every call, so repeated
+// resolution would be visible in the built commands
+type fakeResolver struct {
+	calls []string
+}
+
+func (r *fakeResolver) Resolve(ctx context.Context, name string) (string, error) {
+	r.calls = append(r.calls, name)
+	return fmt.Sprintf("10.0.0.%d", len(r.calls)), nil
+}
+
+func TestExecuteTest_PinsResolvedAddress(t *testing.T) {
+	tests := []config.TestScenario{
+		{Name: "first", Client: "client", Server: "server"},
+		{Name: "second", Client: "client", Server: "server"},
+	}
+	coord := newTestCoordinator(tests, map[string]*fakeHostClient{
+		"client": {handler: succeed("done")},
+		"server": {handler: runForever(true)},
+	})
+	coord.config.ResolveHosts = config.ResolveOnController
+
+	resolver := &fakeResolver{}
+	var modes []string
+	coord.newResolver = func(mode string, via HostClient) Resolver {
+		modes = append(modes, mode)
+		return resolver
+	}
+
+	results, err := coord.RunAllTests(context.Background())
+	if err != nil {
+		t.Fatalf("RunAllTests returned error: %v", err)
+	}
+
+	if len(resolver.calls) != 1 || resolver.calls[0] != "server" {
+		t.Errorf("Expected the server name to be resolved once, got %v", resolver.calls)
+	}
+	for _, result := range results {
+		if result.ClientCommand != "fake-client 10.0.0.1" {
+			t.Errorf("%s: expected the pinned address in the command, got %q", result.ScenarioName, result.ClientCommand)
+		}
+		if result.ResolvedAddresses["server"] != "10.0.0.1" {
+			t.Errorf("%s: expected the resolved address recorded, got %v", result.ScenarioName, result.ResolvedAddresses)
+		}
+	}
+	if len(modes) != 1 || modes[0] != config.ResolveOnController {
+		t.Errorf("Expected one controller resolver, got %v", modes)
+	}
+}
+
+func TestRemoteResolver(t *testing.T) {
+	client := &fakeHostClient{handler: func(ctx context.Context, command string) (*ssh.Result, error) {
+		if !strings.HasPrefix(command, "getent hosts ") {
+			t.Errorf("Unexpected command %q", command)
+		}
+		return &ssh.Result{Output: "192.168.50.7    server-dp.lab server-dp\n"}, nil
+	}}
+
+	address, err := remoteResolver{client: client}.Resolve(context.Background(), "server-dp")
+	if err != nil || address != "192.168.50.7" {
+		t.Errorf("Resolve() = %q, %v; want 192.168.50.7", address, err)
+	}
+}
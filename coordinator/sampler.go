@@ -0,0 +1,391 @@
+package coordinator
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"perf-runner/ssh"
+)
+
+// defaultSampleInterval is how often hosts are sampled during a run
+const defaultSampleInterval = 1 * time.Second
+
+// Markers the sampling loop prints to delimit samples, the output of each
+// collector within a sample, and the end of the loop: stoppedMarker when the
+// stop file ended it, expiredMarker when it reached its lifetime
+const (
+	sampleMarker    = "@@sample "
+	collectorMarker = "@@collector "
+	stoppedMarker   = "@@stopped"
+	expiredMarker   = "@@expired"
+)
+
+// SampleCollector is one metric source served by a Sampler, e.g. CPU
+// utilization or NIC counters. Command runs on the host once per sampling
+// interval and must not exit the shell; its output for each sample is passed
+// to Collect along with the host time the sample was taken, which is shared
+// by every collector.
+type SampleCollector interface {
+	Command() string
+	Collect(at time.Time, output string)
+}
+
+// Sampler runs the commands of all registered collectors for one host in a
+// single long-lived SSH session, instead of each collector polling over its
+// own sessions, so samples are taken together and session churn stays low.
+// The session streams its samples as they are taken and ends on its own once
+// it reaches its lifetime, which stays below the SSH command timeout; a
+// session that ends early is started again after an interval.
+type Sampler struct {
+	client     HostClient
+	interval   time.Duration
+	lifetime   time.Duration
+	collectors []SampleCollector
+	stopFile   string
+	cancel     context.CancelFunc
+	stopping   chan struct{}
+	done       chan struct{}
+	// samples and err are owned by the sampling goroutine until done is closed
+	samples int
+	err     error
+}
+
+// NewSampler creates a sampler that samples the host behind client every
+// interval, over sessions lasting at most lifetime
+func NewSampler(client HostClient, interval, lifetime time.Duration) *Sampler {
+	return &Sampler{client: client, interval: interval, lifetime: lifetime}
+}
+
+// Register adds a collector; collectors must be registered before Start
+func (s *Sampler) Register(collector SampleCollector) {
+	s.collectors = append(s.collectors, collector)
+}
+
+// Start launches the sampling loop on the host, which takes a first sample
+// right away. It does nothing if no collectors are registered.
+func (s *Sampler) Start(ctx context.Context) {
+	if len(s.collectors) == 0 || s.done != nil {
+		return
+	}
+
+	s.stopFile = fmt.Sprintf("/tmp/perf-runner-%d-%d-sampler.stop", time.Now().UnixNano(), pidFileSeq.Add(1))
+	loopCtx, cancel := context.WithCancel(ctx)
+	s.cancel = cancel
+	s.stopping = make(chan struct{})
+	s.done = make(chan struct{})
+	go s.run(loopCtx, s.loopCommand(), s.stopping, s.done)
+}
+
+// run keeps a sampling session going until one ends on the stop file. Once
+// the stop file is in place, a session that ended without seeing it is
+// followed by one more, which takes the final sample and removes the file.
+func (s *Sampler) run(ctx context.Context, command string, stopping <-chan struct{}, done chan<- struct{}) {
+	defer close(done)
+	for {
+		select {
+		case <-stopping:
+			s.session(ctx, command)
+			return
+		default:
+		}
+		if s.session(ctx, command) {
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-stopping:
+		case <-time.After(s.interval):
+		}
+	}
+}
+
+// session runs the sampling loop once, handing its samples to the
+// collectors as they arrive, and reports whether the stop file ended it
+func (s *Sampler) session(ctx context.Context, command string) bool {
+	parser := &sampleParser{collectors: s.collectors, collector: -1}
+	sshResult, err := executeStream(ctx, s.client, command, parser)
+	// Clients that cannot stream hand over the output once the loop ends
+	if !parser.streamed && sshResult != nil {
+		parser.Write([]byte(sshResult.Output))
+	}
+	parser.end()
+
+	s.samples += parser.samples
+	if err != nil && ctx.Err() == nil {
+		s.err = err
+	}
+	return parser.stopped
+}
+
+// Stop ends the sampling loop after a final sample and waits for its
+// remaining samples to reach the collectors. If the loop cannot be reached
+// before ctx expires, its session is abandoned and ends at its lifetime.
+func (s *Sampler) Stop(ctx context.Context) error {
+	if s.done == nil {
+		return nil
+	}
+	done := s.done
+	s.done = nil
+	defer s.cancel()
+
+	if _, err := s.client.ExecuteCommand(ctx, fmt.Sprintf("touch %s", s.stopFile)); err != nil {
+		s.cancel()
+		<-done
+		return fmt.Errorf("failed to stop sampler: %w", err)
+	}
+	close(s.stopping)
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		s.cancel()
+		<-done
+		return fmt.Errorf("sampler did not stop: %w", ctx.Err())
+	}
+	if s.samples == 0 && s.err != nil {
+		return fmt.Errorf("sampling failed: %w", s.err)
+	}
+	return nil
+}
+
+// loopCommand builds the composite loop: each iteration prints the host time,
+// then each collector's output under its index, until the stop file appears
+// or the lifetime is up
+func (s *Sampler) loopCommand() string {
+	var body strings.Builder
+	fmt.Fprintf(&body, `echo "%s$(date +%%s.%%N)"; `, sampleMarker)
+	for i, collector := range s.collectors {
+		fmt.Fprintf(&body, "echo '%s%d'; { %s; } 2>/dev/null; ", collectorMarker, i, collector.Command())
+	}
+	interval := strconv.FormatFloat(s.interval.Seconds(), 'f', -1, 64)
+	lifetime := int64(s.lifetime / time.Second)
+	if lifetime < 1 {
+		lifetime = 1
+	}
+	return fmt.Sprintf(`end=$(($(date +%%s) + %[4]d)); while :; do %[2]s`+
+		`if [ -e %[1]s ]; then rm -f %[1]s; echo '%[5]s'; break; fi; `+
+		`if [ "$(date +%%s)" -ge "$end" ]; then echo '%[6]s'; break; fi; sleep %[3]s; done`,
+		s.stopFile, body.String(), interval, lifetime, stoppedMarker, expiredMarker)
+}
+
+// sample is one iteration of the sampling loop
+type sample struct {
+	at      time.Time
+	outputs []string
+}
+
+// sampleParser splits the streamed output of the sampling loop into samples
+// and hands each to the collectors once it is complete, that is once the
+// next sample or the end of the loop follows it. A sample cut short, e.g.
+// because its session ended mid-sample, is dropped.
+type sampleParser struct {
+	collectors []SampleCollector
+	current    *sample
+	collector  int
+	lines      []string
+	partial    []byte // Start of a line not yet complete
+	// streamed is set once any output arrived
+	streamed bool
+	// samples counts the samples handed to the collectors
+	samples int
+	// stopped is set once the loop reports it found the stop file
+	stopped bool
+}
+
+// Write takes loop output, handling each line once it is complete
+func (p *sampleParser) Write(data []byte) (int, error) {
+	p.streamed = true
+	p.partial = append(p.partial, data...)
+	for {
+		end := bytes.IndexByte(p.partial, '\n')
+		if end < 0 {
+			break
+		}
+		p.line(strings.TrimRight(string(p.partial[:end]), "\r"))
+		p.partial = p.partial[end+1:]
+	}
+	return len(data), nil
+}
+
+// line handles one line of loop output
+func (p *sampleParser) line(line string) {
+	switch {
+	case strings.HasPrefix(line, sampleMarker):
+		p.complete()
+		if at, ok := parseSampleTime(strings.TrimPrefix(line, sampleMarker)); ok {
+			p.current = &sample{at: at, outputs: make([]string, len(p.collectors))}
+		}
+	case line == stoppedMarker || line == expiredMarker:
+		p.complete()
+		p.stopped = line == stoppedMarker
+	case strings.HasPrefix(line, collectorMarker):
+		p.flush()
+		index, err := strconv.Atoi(strings.TrimPrefix(line, collectorMarker))
+		if err != nil || index < 0 || index >= len(p.collectors) {
+			p.collector = -1
+			return
+		}
+		p.collector = index
+	default:
+		p.lines = append(p.lines, line)
+	}
+}
+
+// flush stores the lines gathered so far as the current collector's output
+func (p *sampleParser) flush() {
+	if p.current != nil && p.collector >= 0 {
+		p.current.outputs[p.collector] = strings.TrimRight(strings.Join(p.lines, "\n"), "\n")
+	}
+	p.lines = nil
+}
+
+// complete hands the current sample to the collectors if every collector's
+// output arrived, and starts over
+func (p *sampleParser) complete() {
+	p.flush()
+	if p.current != nil && p.collector == len(p.collectors)-1 {
+		for i, output := range p.current.outputs {
+			p.collectors[i].Collect(p.current.at, output)
+		}
+		p.samples++
+	}
+	p.current, p.collector = nil, -1
+}
+
+// end handles an unterminated last line and drops a sample still
+// incomplete when the session ended
+func (p *sampleParser) end() {
+	if len(p.partial) > 0 {
+		p.line(strings.TrimRight(string(p.partial), "\r"))
+		p.partial = nil
+	}
+	p.current, p.collector, p.lines = nil, -1, nil
+}
+
+// parseSampleTime parses the `date +%s.%N` output of a sample
+func parseSampleTime(value string) (time.Time, bool) {
+	secText, nsecText, _ := strings.Cut(strings.TrimSpace(value), ".")
+	sec, err := strconv.ParseInt(secText, 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	var nsec int64
+	if nsecText != "" {
+		// %N is zero-padded to nine digits
+		if nsec, err = strconv.ParseInt(nsecText, 10, 64); err != nil {
+			return time.Time{}, false
+		}
+	}
+	return time.Unix(sec, nsec), true
+}
+
+// deltaCollector keeps the output of the first and last samples of a
+// counter command, for metrics reported as the change over the run
+type deltaCollector struct {
+	command string
+	first   string
+	last    string
+	samples int
+}
+
+// Command returns the counter command
+func (c *deltaCollector) Command() string { return c.command }
+
+// Collect keeps the output if it is the first or, so far, the last sample
+func (c *deltaCollector) Collect(at time.Time, output string) {
+	if c.samples == 0 {
+		c.first = output
+	}
+	c.last = output
+	c.samples++
+}
+
+// hostSamplers holds the Sampler of each host of a test, so every collector
+// sampling a host during the run shares the host's one sampling session
+type hostSamplers struct {
+	executor *TestExecutor
+	samplers map[string]*Sampler
+	names    []string
+}
+
+// newHostSamplers creates a set of samplers with no collectors
+func (e *TestExecutor) newHostSamplers() *hostSamplers {
+	return &hostSamplers{executor: e, samplers: make(map[string]*Sampler)}
+}
+
+// register adds collector to the sampler of host. The sampler runs outside
+// the global command limit, like every in-run probe.
+func (h *hostSamplers) register(host testHost, collector SampleCollector) {
+	sampler, exists := h.samplers[host.name]
+	if !exists {
+		sampler = NewSampler(probeClient(host.client), h.executor.sampleInterval, h.executor.samplerLifetime(host.name))
+		h.samplers[host.name] = sampler
+		h.names = append(h.names, host.name)
+	}
+	sampler.Register(collector)
+}
+
+// deltas registers a deltaCollector for the command returned for each host,
+// once per host, and returns the collectors keyed by host name. Hosts with
+// no command are skipped.
+func (h *hostSamplers) deltas(hosts []testHost, command func(host testHost) string) map[string]*deltaCollector {
+	collectors := make(map[string]*deltaCollector)
+	for _, host := range hosts {
+		if _, registered := collectors[host.name]; registered {
+			continue
+		}
+		if cmd := command(host); cmd != "" {
+			collectors[host.name] = &deltaCollector{command: cmd}
+			h.register(host, collectors[host.name])
+		}
+	}
+	return collectors
+}
+
+// start starts sampling every host with collectors
+func (h *hostSamplers) start(ctx context.Context) {
+	for _, name := range h.names {
+		h.samplers[name].Start(ctx)
+	}
+}
+
+// stop ends sampling on every host at once, giving each sampler the stop
+// timeout plus one interval to take its final sample. Calling it again does
+// nothing.
+func (h *hostSamplers) stop(ctx context.Context) {
+	errs := make([]error, len(h.names))
+	var wg sync.WaitGroup
+	for i, name := range h.names {
+		wg.Add(1)
+		go func(i int, sampler *Sampler) {
+			defer wg.Done()
+			stopCtx, cancel := context.WithTimeout(ctx, h.executor.stopTimeout+h.executor.sampleInterval)
+			defer cancel()
+			errs[i] = sampler.Stop(stopCtx)
+		}(i, h.samplers[name])
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			h.executor.coordinator.logger.Printf("  Warning: failed to sample %s: %v", h.names[i], err)
+		}
+	}
+}
+
+// samplerLifetime bounds a sampling session on a host to half its SSH
+// command timeout, so the session ends on its own before it would be cut off
+func (e *TestExecutor) samplerLifetime(name string) time.Duration {
+	timeout := ssh.DefaultCommandTimeout
+	if host := e.coordinator.config.Hosts[name]; host != nil && host.SSH != nil && host.SSH.CommandTimeout > 0 {
+		timeout = host.SSH.CommandTimeout
+	}
+	return timeout / 2
+}
@@ -0,0 +1,286 @@
+package coordinator
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"perf-runner/ssh"
+)
+
+// recordingCollector keeps every sample it is handed
+type recordingCollector struct {
+	command string
+	times   []time.Time
+	outputs []string
+}
+
+func (c *recordingCollector) Command() string { return c.command }
+
+func (c *recordingCollector) Collect(at time.Time, output string) {
+	c.times = append(c.times, at)
+	c.outputs = append(c.outputs, output)
+}
+
+// isSamplerCommand reports whether command is a sampling loop or the command
+// stopping one
+func isSamplerCommand(command string) bool {
+	return strings.HasPrefix(command, "end=$((") || strings.HasSuffix(command, "-sampler.stop")
+}
+
+// samplingHandler answers each session of the sampling loop with one sample
+// and then ends it: on the stop marker once the loop's stop file has been
+// touched, or else on the expiry marker, so the sampler starts another. Each
+// collector's command is answered by the entry of answers whose key it
+// contains. Other commands go to next.
+func samplingHandler(answers map[string]func() string, next func(ctx context.Context, command string) (*ssh.Result, error)) func(ctx context.Context, command string) (*ssh.Result, error) {
+	var mu sync.Mutex
+	stopped := false
+	taken := 0
+	return func(ctx context.Context, command string) (*ssh.Result, error) {
+		if !isSamplerCommand(command) {
+			return next(ctx, command)
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		if strings.HasPrefix(command, "touch ") {
+			stopped = true
+			return &ssh.Result{}, nil
+		}
+
+		taken++
+		var output strings.Builder
+		fmt.Fprintf(&output, "%s%d.000000000\n", sampleMarker, 1700000000+taken)
+		for i, collector := range strings.Split(command, "echo '"+collectorMarker)[1:] {
+			fmt.Fprintf(&output, "%s%d\n", collectorMarker, i)
+			for key, answer := range answers {
+				if strings.Contains(collector, key) {
+					fmt.Fprintf(&output, "%s\n", answer())
+					break
+				}
+			}
+		}
+		if stopped {
+			output.WriteString(stoppedMarker)
+		} else {
+			output.WriteString(expiredMarker)
+		}
+		return &ssh.Result{Output: output.String()}, nil
+	}
+}
+
+// inTurn returns outputs one per call, repeating the last one
+func inTurn(outputs ...string) func() string {
+	taken := 0
+	return func() string {
+		output := outputs[len(outputs)-1]
+		if taken < len(outputs) {
+			output = outputs[taken]
+		}
+		taken++
+		return output
+	}
+}
+
+// streamingLoopHost runs the sampling loop like a real host: it streams a
+// sample line by line every interval until the stop file is touched, then
+// streams a final sample and the stop marker
+type streamingLoopHost struct {
+	*fakeHostClient
+	samples  []string
+	interval time.Duration
+	sessions []string
+	streamed chan struct{}
+	stopped  chan struct{}
+}
+
+func newStreamingLoopHost(interval time.Duration, samples ...string) *streamingLoopHost {
+	host := &streamingLoopHost{samples: samples, interval: interval, streamed: make(chan struct{}), stopped: make(chan struct{})}
+	host.fakeHostClient = &fakeHostClient{handler: func(ctx context.Context, command string) (*ssh.Result, error) {
+		if strings.HasPrefix(command, "touch ") {
+			close(host.stopped)
+		}
+		return &ssh.Result{}, nil
+	}}
+	return host
+}
+
+func (h *streamingLoopHost) ExecuteCommandStream(ctx context.Context, command string, live io.Writer) (*ssh.Result, error) {
+	h.sessions = append(h.sessions, command)
+	write := func(sample int, output string) {
+		fmt.Fprintf(live, "%s%d.000000001\n", sampleMarker, 1700000000+sample)
+		for _, line := range strings.Split(output, "\n") {
+			fmt.Fprintf(live, "%s\n", line)
+		}
+	}
+
+	last := len(h.samples) - 1
+	for i, output := range h.samples[:last] {
+		write(i, output)
+		time.Sleep(h.interval)
+	}
+	close(h.streamed)
+	select {
+	case <-h.stopped:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	write(last, h.samples[last])
+	fmt.Fprintf(live, "%s\n", stoppedMarker)
+	return &ssh.Result{}, nil
+}
+
+func TestSampler_OneCommandServesAllCollectors(t *testing.T) {
+	host := newStreamingLoopHost(10*time.Millisecond,
+		"@@collector 0\ncpu 10\n@@collector 1\neth0 100\neth1 200",
+		"@@collector 0\ncpu 20\n@@collector 1\neth0 150\neth1 250",
+		"@@collector 0\ncpu 30\n@@collector 1\neth0 180\neth1 290",
+	)
+	cpu := &recordingCollector{command: "head -1 /proc/stat"}
+	nic := &recordingCollector{command: "cat /proc/net/dev"}
+	sampler := NewSampler(host, 10*time.Millisecond, time.Minute)
+	sampler.Register(cpu)
+	sampler.Register(nic)
+
+	sampler.Start(context.Background())
+	<-host.streamed
+	if err := sampler.Stop(context.Background()); err != nil {
+		t.Fatalf("Stop returned error: %v", err)
+	}
+
+	// Every sample of the run came from one looping session
+	if len(host.sessions) != 1 {
+		t.Fatalf("Expected one sampling session, got %d: %q", len(host.sessions), host.sessions)
+	}
+	for _, want := range []string{"head -1 /proc/stat", "cat /proc/net/dev", "while :", "sleep 0.01", "+ 60))", "-sampler.stop"} {
+		if !strings.Contains(host.sessions[0], want) {
+			t.Errorf("Expected the sampling loop to contain %q, got %q", want, host.sessions[0])
+		}
+	}
+
+	if len(cpu.outputs) != 3 || cpu.outputs[1] != "cpu 20" || cpu.outputs[2] != "cpu 30" {
+		t.Errorf("Unexpected cpu samples: %q", cpu.outputs)
+	}
+	if len(nic.outputs) != 3 || nic.outputs[0] != "eth0 100\neth1 200" {
+		t.Errorf("Unexpected nic samples: %q", nic.outputs)
+	}
+	for i := range cpu.times {
+		if !cpu.times[i].Equal(nic.times[i]) {
+			t.Errorf("Sample %d: collectors got different times %v and %v", i, cpu.times[i], nic.times[i])
+		}
+	}
+	if want := time.Unix(1700000001, 1); len(cpu.times) == 3 && !cpu.times[1].Equal(want) {
+		t.Errorf("Expected second sample at %v, got %v", want, cpu.times[1])
+	}
+}
+
+func TestSampler_RestartsExpiredSession(t *testing.T) {
+	// A session that ends without the stop marker is started again; one cut
+	// short mid-sample loses that sample only
+	var mu sync.Mutex
+	sessions := 0
+	host := &fakeHostClient{handler: func(ctx context.Context, command string) (*ssh.Result, error) {
+		if strings.HasPrefix(command, "touch ") {
+			return &ssh.Result{}, nil
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		sessions++
+		switch sessions {
+		case 1:
+			return &ssh.Result{Output: "@@sample 1700000000.000000001\n@@collector 0\ncpu 10\n" + expiredMarker}, nil
+		case 2:
+			return nil, fmt.Errorf("session cut off")
+		case 3:
+			return &ssh.Result{Output: "@@sample 1700000002.000000001\n@@collector 0\n"}, nil
+		}
+		return &ssh.Result{Output: "@@sample 1700000003.000000001\n@@collector 0\ncpu 40\n" + stoppedMarker}, nil
+	}}
+	cpu := &recordingCollector{command: "head -1 /proc/stat"}
+	sampler := NewSampler(host, time.Millisecond, time.Minute)
+	sampler.Register(cpu)
+
+	sampler.Start(context.Background())
+	if err := sampler.Stop(context.Background()); err != nil {
+		t.Fatalf("Stop returned error: %v", err)
+	}
+	// Stop may come before the sampler reached the last session, but the
+	// first sample and the final one are always taken
+	if len(cpu.outputs) == 0 || cpu.outputs[0] != "cpu 10" || cpu.outputs[len(cpu.outputs)-1] == "" {
+		t.Errorf("Unexpected cpu samples: %q", cpu.outputs)
+	}
+	if !strings.HasPrefix(host.commands[len(host.commands)-1], "end=$((") {
+		t.Errorf("Expected a session to take the final sample after Stop, got %q", host.commands)
+	}
+}
+
+func TestSampler_NoCollectorsStartsNothing(t *testing.T) {
+	host := &fakeHostClient{handler: succeed("")}
+	sampler := NewSampler(host, time.Second, time.Minute)
+
+	sampler.Start(context.Background())
+	if err := sampler.Stop(context.Background()); err != nil {
+		t.Fatalf("Stop returned error: %v", err)
+	}
+	if len(host.commands) != 0 {
+		t.Errorf("Expected no commands without collectors, got %v", host.commands)
+	}
+}
+
+func TestSampler_LoopCommandRunsInShell(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+
+	for _, tt := range []struct {
+		name       string
+		lifetime   time.Duration
+		touch      bool
+		wantMarker string
+	}{
+		{"stopped by the stop file", time.Minute, true, stoppedMarker},
+		{"ended at its lifetime", time.Second, false, expiredMarker},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			first := &recordingCollector{command: "echo one"}
+			second := &recordingCollector{command: "echo two; false"}
+			sampler := NewSampler(nil, 10*time.Millisecond, tt.lifetime)
+			sampler.Register(first)
+			sampler.Register(second)
+			sampler.stopFile = filepath.Join(t.TempDir(), "sampler.stop")
+
+			cmd := exec.Command("sh", "-c", sampler.loopCommand())
+			parser := &sampleParser{collectors: sampler.collectors}
+			cmd.Stdout = parser
+			if err := cmd.Start(); err != nil {
+				t.Fatalf("Failed to start the loop: %v", err)
+			}
+			if tt.touch {
+				time.Sleep(50 * time.Millisecond)
+				if err := os.WriteFile(sampler.stopFile, nil, 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := cmd.Wait(); err != nil {
+				t.Fatalf("Loop failed: %v", err)
+			}
+
+			if parser.stopped != tt.touch {
+				t.Errorf("Expected stopped %v after %s", tt.touch, tt.wantMarker)
+			}
+			if parser.samples < 2 || len(first.outputs) != parser.samples || second.outputs[0] != "two" {
+				t.Errorf("Expected several complete samples, got %q and %q", first.outputs, second.outputs)
+			}
+			if _, err := os.Stat(sampler.stopFile); !os.IsNotExist(err) {
+				t.Errorf("Expected the loop to leave no stop file, got %v", err)
+			}
+		})
+	}
+}
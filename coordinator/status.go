@@ -0,0 +1,30 @@
+package coordinator
+
+import "time"
+
+// Status is a snapshot of a run's progress
+type Status struct {
+	Name        string        `json:"name"`
+	TotalTests  int           `json:"total_tests"`
+	CurrentTest string        `json:"current_test,omitempty"`
+	StartTime   time.Time     `json:"start_time"`
+	Done        bool          `json:"done"`
+	Results     []*TestResult `json:"results"`
+}
+
+// Status returns a snapshot of the current run's progress
+func (c *Coordinator) Status() Status {
+	c.statusMu.Lock()
+	defer c.statusMu.Unlock()
+	
+	status := c.status
+	status.Results = append([]*TestResult(nil), c.status.Results...)
+	return status
+}
+
+// updateStatus applies fn to the run status under its lock
+func (c *Coordinator) updateStatus(fn func(status *Status)) {
+	c.statusMu.Lock()
+	defer c.statusMu.Unlock()
+	fn(&c.status)
+}
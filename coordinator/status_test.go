@@ -0,0 +1,31 @@
+package coordinator
+
+import (
+	"context"
+	"testing"
+
+	"perf-runner/config"
+)
+
+func TestRunAllTests_UpdatesStatus(t *testing.T) {
+	tests := []config.TestScenario{
+		{Name: "first", Client: "client", Server: "server", Repeat: 2},
+		{Name: "second", Client: "client", Server: "server"},
+	}
+	coord := newTestCoordinator(tests, map[string]*fakeHostClient{
+		"client": {handler: succeed("done")},
+		"server": {handler: runForever(true)},
+	})
+
+	if _, err := coord.RunAllTests(context.Background()); err != nil {
+		t.Fatalf("RunAllTests returned error: %v", err)
+	}
+
+	status := coord.Status()
+	if !status.Done || status.CurrentTest != "" {
+		t.Errorf("Expected a finished status, got done=%v current=%q", status.Done, status.CurrentTest)
+	}
+	if status.TotalTests != 3 || len(status.Results) != 3 {
+		t.Errorf("Expected 3/3 results, got %d/%d", len(status.Results), status.TotalTests)
+	}
+}
@@ -0,0 +1,58 @@
+package coordinator
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"perf-runner/runner"
+)
+
+// defaultStopTimeout bounds the commands that reap a background role, which
+// run even after the test's own deadline has passed
+const defaultStopTimeout = 10 * time.Second
+
+// recordPID makes command write the PID of its login shell to pidFile before
+// running. The shell leads the session's process group, so the PID also
+// identifies every process the command starts.
+func recordPID(command, pidFile string) string {
+	return fmt.Sprintf("echo $$ > %s; %s", pidFile, command)
+}
+
+// stopProcessGroupCommand terminates the process group whose leader's PID is
+// in pidFile and removes the file. It succeeds if the group already exited
+// or never started.
+func stopProcessGroupCommand(pidFile string) string {
+	return fmt.Sprintf(`p=$(cat %[1]s 2>/dev/null); rm -f %[1]s; [ -n "$p" ] || exit 0; `+
+		`kill -0 -- -"$p" 2>/dev/null || exit 0; kill -TERM -- -"$p"`, pidFile)
+}
+
+// stopRole reaps a background role however the test ended: it cancels the
+// role's session, terminates the role's process group on its host, then
+// runs the runner's stop command if it has one. Closing the SSH session
+// alone can leave the tool running. Failures are logged as warnings.
+func (e *TestExecutor) stopRole(ctx context.Context, r runner.Runner, role *backgroundRole, name, host string) {
+	role.cancel()
+
+	stopCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), e.stopTimeout)
+	defer cancel()
+
+	// Stop only after the launch has returned, so a command still being
+	// started cannot outlive the stop
+	select {
+	case <-role.exited:
+	case <-stopCtx.Done():
+	}
+
+	commands := []string{stopProcessGroupCommand(role.pidFile)}
+	if stopper, ok := r.(runner.Stopper); ok {
+		if command := stopper.BuildStopCommand(*role.config); command != "" {
+			commands = append(commands, command)
+		}
+	}
+	for _, command := range commands {
+		if _, err := role.client.ExecuteCommand(stopCtx, command); err != nil {
+			e.coordinator.logger.Printf("  Warning: failed to stop %s on %s: %v", name, host, err)
+		}
+	}
+}
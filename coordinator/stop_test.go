@@ -0,0 +1,89 @@
+package coordinator
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"testing"
+
+	"perf-runner/config"
+	"perf-runner/runner"
+	"perf-runner/ssh"
+)
+
+// stoppingRunner is a fakeRunner with its own stop command
+type stoppingRunner struct {
+	fakeRunner
+}
+
+func (r *stoppingRunner) BuildStopCommand(config runner.Config) string {
+	return "stop-" + config.Role
+}
+
+// serverAnswering runs role commands forever but answers stop commands
+func serverAnswering(stop func(command string) (*ssh.Result, error)) func(ctx context.Context, command string) (*ssh.Result, error) {
+	return func(ctx context.Context, command string) (*ssh.Result, error) {
+		if strings.HasPrefix(command, "p=$(cat ") || strings.HasPrefix(command, "stop-") {
+			return stop(command)
+		}
+		return runForever(true)(ctx, command)
+	}
+}
+
+func TestExecuteTest_StopsServerAfterClientFails(t *testing.T) {
+	test := config.TestScenario{Name: "early error", Client: "client", Server: "server"}
+	server := &fakeHostClient{handler: serverAnswering(func(command string) (*ssh.Result, error) {
+		return &ssh.Result{}, nil
+	})}
+	coord := newTestCoordinator([]config.TestScenario{test}, map[string]*fakeHostClient{
+		"client": {handler: func(ctx context.Context, command string) (*ssh.Result, error) {
+			return nil, fmt.Errorf("failed to create session: EOF")
+		}},
+		"server": server,
+	})
+	coord.RegisterRunner("fake", &stoppingRunner{})
+
+	if _, err := newTestExecutor(coord).ExecuteTest(context.Background(), &test); err == nil {
+		t.Fatal("Expected the client failure to be returned")
+	}
+
+	if len(server.commands) != 3 {
+		t.Fatalf("Expected the server to be launched and then stopped twice, got %q", server.commands)
+	}
+	launch, stopGroup, stopTool := server.commands[0], server.commands[1], server.commands[2]
+	pidFile := strings.TrimPrefix(strings.SplitN(launch, ";", 2)[0], "echo $$ > ")
+	if !strings.HasSuffix(launch, "; fake-server") {
+		t.Errorf("Expected the server command to record its PID, got %q", launch)
+	}
+	if !strings.Contains(stopGroup, "cat "+pidFile+" ") || !strings.Contains(stopGroup, `kill -TERM -- -"$p"`) {
+		t.Errorf("Expected the process group recorded in %s to be terminated, got %q", pidFile, stopGroup)
+	}
+	if stopTool != "stop-server" {
+		t.Errorf("Expected the runner's stop command last, got %q", stopTool)
+	}
+}
+
+func TestExecuteTest_LogsStopFailures(t *testing.T) {
+	test := config.TestScenario{Name: "unreapable", Client: "client", Server: "server"}
+	coord := newTestCoordinator([]config.TestScenario{test}, map[string]*fakeHostClient{
+		"client": {handler: succeed("done")},
+		"server": {handler: serverAnswering(func(command string) (*ssh.Result, error) {
+			return &ssh.Result{ExitCode: 1}, fmt.Errorf("Process exited with status 1")
+		})},
+	})
+	var logs bytes.Buffer
+	coord.logger = log.New(&logs, "", 0)
+
+	result, err := newTestExecutor(coord).ExecuteTest(context.Background(), &test)
+	if err != nil {
+		t.Fatalf("ExecuteTest returned error: %v", err)
+	}
+	if !result.Success {
+		t.Errorf("Expected a failed stop not to fail the test, got error %q", result.Error)
+	}
+	if !strings.Contains(logs.String(), "Warning: failed to stop server on server") {
+		t.Errorf("Expected the stop failure to be logged as a warning, got:\n%s", logs.String())
+	}
+}
@@ -0,0 +1,52 @@
+package coordinator
+
+import (
+	"context"
+	"io"
+	"log"
+	"strings"
+
+	"perf-runner/ssh"
+)
+
+// StreamingClient is implemented by host clients that can pass a command's
+// output on line by line while it runs, such as ssh.Client
+type StreamingClient interface {
+	ExecuteCommandStream(ctx context.Context, command string, live io.Writer) (*ssh.Result, error)
+}
+
+// executeStream runs command on client, writing its output to live as it
+// arrives. Clients that cannot stream, or a nil live, only capture it.
+func executeStream(ctx context.Context, client HostClient, command string, live io.Writer) (*ssh.Result, error) {
+	if streaming, ok := client.(StreamingClient); ok && live != nil {
+		return streaming.ExecuteCommandStream(ctx, command, live)
+	}
+	return client.ExecuteCommand(ctx, command)
+}
+
+// logWriter logs every line written to it behind a prefix naming its source
+type logWriter struct {
+	logger *log.Logger
+	prefix string
+}
+
+func (w *logWriter) Write(p []byte) (int, error) {
+	w.logger.Printf("%s%s", w.prefix, strings.TrimRight(string(p), "\r\n"))
+	return len(p), nil
+}
+
+// SetOutputStreaming enables or disables logging role output line by line
+// as it arrives, prefixed with the host and role, instead of only once the
+// command has finished
+func (c *Coordinator) SetOutputStreaming(enabled bool) {
+	c.streamOutput = enabled
+}
+
+// roleOutputLog returns where a role's live output goes, or nil when output
+// streaming is off
+func (c *Coordinator) roleOutputLog(host, role string) io.Writer {
+	if !c.streamOutput {
+		return nil
+	}
+	return &logWriter{logger: c.logger, prefix: "  [" + host + " " + role + "] "}
+}
@@ -0,0 +1,67 @@
+package coordinator
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log"
+	"strings"
+	"testing"
+
+	"perf-runner/config"
+	"perf-runner/ssh"
+)
+
+// streamingHostClient is a fakeHostClient that also writes each command's
+// output to the live writer line by line, like ssh.Client
+type streamingHostClient struct {
+	*fakeHostClient
+	streamed int
+}
+
+func (s *streamingHostClient) ExecuteCommandStream(ctx context.Context, command string, live io.Writer) (*ssh.Result, error) {
+	result, err := s.fakeHostClient.ExecuteCommand(ctx, command)
+	if result != nil && live != nil {
+		s.streamed++
+		for _, line := range strings.SplitAfter(result.Output, "\n") {
+			if line != "" {
+				live.Write([]byte(line))
+			}
+		}
+	}
+	return result, err
+}
+
+func TestExecuteTest_StreamsRoleOutput(t *testing.T) {
+	for _, enabled := range []bool{true, false} {
+		test := config.TestScenario{Name: "live", Client: "client", Server: "server"}
+		coord := newTestCoordinator([]config.TestScenario{test}, map[string]*fakeHostClient{
+			"server": {handler: runForever(true)},
+		})
+		coord.config.Hosts["client"] = &config.HostConfig{SSH: &ssh.Config{Host: "client", User: "test"}}
+		client := &streamingHostClient{fakeHostClient: &fakeHostClient{handler: succeed("interval 1\ninterval 2\n")}}
+		coord.sshClients["client"] = client
+		// Streaming passes through the command limit
+		coord.limiter = newCommandLimiter(4)
+
+		var logs bytes.Buffer
+		coord.logger = log.New(&logs, "", 0)
+		coord.SetOutputStreaming(enabled)
+
+		result, err := newTestExecutor(coord).ExecuteTest(context.Background(), &test)
+		if err != nil {
+			t.Fatalf("ExecuteTest returned error: %v", err)
+		}
+		if result.ClientResult.Output != "interval 1\ninterval 2\n" {
+			t.Errorf("Expected the full output to be captured, got %q", result.ClientResult.Output)
+		}
+
+		streamed := strings.Contains(logs.String(), "  [client client] interval 1\n  [client client] interval 2\n")
+		if streamed != enabled {
+			t.Errorf("streaming=%v: expected streamed lines %v, got logs:\n%s", enabled, enabled, logs.String())
+		}
+		if enabled && client.streamed != 1 {
+			t.Errorf("Expected only the role command to be streamed, got %d streamed commands", client.streamed)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package coordinator
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"perf-runner/runner"
+)
+
+// congestionAlgorithms lists the Linux TCP congestion control names `ss -ti` may report
+var congestionAlgorithms = map[string]bool{
+	"reno": true, "cubic": true, "bbr": true, "bbr2": true, "bbr3": true,
+	"dctcp": true, "htcp": true, "vegas": true, "westwood": true, "bic": true,
+	"highspeed": true, "hybla": true, "illinois": true, "lp": true,
+	"scalable": true, "veno": true, "yeah": true, "cdg": true, "nv": true,
+}
+
+// requestedCongestionControl returns the congestion control the client asked for, if any
+func requestedCongestionControl(config *runner.Config) string {
+	if congestion, ok := config.GetEffectiveArgs()["congestion"].(string); ok {
+		return strings.ToLower(congestion)
+	}
+	return ""
+}
+
+// parseCongestionControl extracts the congestion control algorithm of each
+// connection listed in `ss -ti` output
+func parseCongestionControl(output string) []string {
+	var algorithms []string
+	for _, line := range strings.Split(output, "\n") {
+		for _, field := range strings.Fields(line) {
+			if congestionAlgorithms[field] {
+				algorithms = append(algorithms, field)
+				break
+			}
+		}
+	}
+	return algorithms
+}
+
+// congestionCollector gathers the congestion control algorithms a client's
+// connections use over the run, in the order first seen
+type congestionCollector struct {
+	command   string
+	requested string
+	observed  []string
+	seen      map[string]bool
+}
+
+// Command returns the connection listing command
+func (c *congestionCollector) Command() string { return c.command }
+
+// Collect records the algorithms of the connections in one `ss -ti` sample
+func (c *congestionCollector) Collect(at time.Time, output string) {
+	for _, algorithm := range parseCongestionControl(output) {
+		if !c.seen[algorithm] {
+			c.seen[algorithm] = true
+			c.observed = append(c.observed, algorithm)
+		}
+	}
+}
+
+// monitorCongestionControl samples the connections of each client that
+// requests a congestion control algorithm throughout the run
+func monitorCongestionControl(samplers *hostSamplers, hosts []testHost) map[string]*congestionCollector {
+	collectors := make(map[string]*congestionCollector)
+	for _, host := range hosts {
+		requested := requestedCongestionControl(host.config)
+		if _, registered := collectors[host.name]; host.role != "client" || requested == "" || registered {
+			continue
+		}
+		
+		targetHost := host.config.TargetHost
+		if targetHost == "" {
+			targetHost = host.config.Host
+		}
+		collector := &congestionCollector{
+			command:   fmt.Sprintf("ss -tin state established dst %s", targetHost),
+			requested: requested,
+			seen:      make(map[string]bool),
+		}
+		samplers.register(host, collector)
+		collectors[host.name] = collector
+	}
+	return collectors
+}
+
+// recordCongestionControls records the congestion control observed on each
+// sampled client, once sampling has stopped
+func (e *TestExecutor) recordCongestionControls(result *TestResult, collectors map[string]*congestionCollector) {
+	hostResults := roleResultsByHost(result)
+	names := make([]string, 0, len(collectors))
+	for name := range collectors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	
+	for _, name := range names {
+		clientResult := hostResults[name]
+		if clientResult == nil {
+			continue
+		}
+		if clientResult.Metrics == nil {
+			clientResult.Metrics = make(map[string]interface{})
+		}
+		e.recordCongestionControl(result, clientResult, collectors[name].requested, collectors[name].observed)
+	}
+}
+
+// recordCongestionControl stores the observed algorithm in the client metrics
+// and warns when it differs from the requested one. The iperf3 control
+// connection may use the system default, so any data connection using the
+// requested algorithm counts as a match.
+func (e *TestExecutor) recordCongestionControl(result *TestResult, clientResult *runner.Result, requested string, observed []string) {
+	if len(observed) == 0 {
+		return
+	}
+	
+	used := observed[0]
+	for _, algorithm := range observed {
+		if algorithm == requested {
+			used = algorithm
+			break
+		}
+	}
+	
+	clientResult.Metrics["cc_used"] = used
+	if used != requested {
+		clientResult.Metrics["cc_mismatch"] = true
+		e.addWarning(result, fmt.Sprintf("requested congestion control %s but the kernel used %s", requested, used))
+	}
+}
@@ -0,0 +1,110 @@
+package coordinator
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+
+	"perf-runner/config"
+	"perf-runner/runner"
+	"perf-runner/ssh"
+)
+
+const ssBBROutput = `Recv-Q Send-Q Local Address:Port  Peer Address:Port Process
+0      0      10.0.0.2:41234      10.0.0.1:5201
+	 ts sack cubic wscale:7,7 rto:204 rtt:0.15/0.05 ato:40 mss:1448 pmtu:1500 rcvmss:536 advmss:1448 cwnd:10 bytes_sent:37 bytes_acked:38 segs_out:6 segs_in:4 send 772.3Mbps
+0      2896   10.0.0.2:41236      10.0.0.1:5201
+	 ts sack bbr wscale:7,7 rto:201 rtt:0.42/0.1 mss:1448 pmtu:1500 rcvmss:536 advmss:1448 bbr:(bw:9.4Gbps,mrtt:0.03,pacing_gain:1,cwnd_gain:2) cwnd:344 bytes_sent:11876372488 send 9.5Gbps
+`
+
+func TestParseCongestionControl(t *testing.T) {
+	got := parseCongestionControl(ssBBROutput)
+	want := []string{"cubic", "bbr"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseCongestionControl() = %v, want %v", got, want)
+	}
+
+	if got := parseCongestionControl("Recv-Q Send-Q Local Address:Port  Peer Address:Port Process\n"); len(got) != 0 {
+		t.Errorf("Expected no algorithms without connections, got %v", got)
+	}
+}
+
+func TestRequestedCongestionControl(t *testing.T) {
+	clientConfig := &runner.Config{
+		Role:       "client",
+		ClientArgs: map[string]interface{}{"congestion": "BBR"},
+	}
+	if got := requestedCongestionControl(clientConfig); got != "bbr" {
+		t.Errorf("requestedCongestionControl() = %q, want %q", got, "bbr")
+	}
+
+	if got := requestedCongestionControl(&runner.Config{Role: "client"}); got != "" {
+		t.Errorf("Expected no congestion control requested, got %q", got)
+	}
+}
+
+// ssHandler answers ss in the sampling loop with the given output and lets
+// the client command finish only once its connections have been sampled
+func ssHandler(ssOutput string) func(ctx context.Context, command string) (*ssh.Result, error) {
+	var once sync.Once
+	sampled := make(chan struct{})
+	listConnections := func() string {
+		once.Do(func() { close(sampled) })
+		return ssOutput
+	}
+	return samplingHandler(map[string]func() string{"ss -tin ": listConnections}, func(ctx context.Context, command string) (*ssh.Result, error) {
+		select {
+		case <-sampled:
+		case <-ctx.Done():
+		}
+		return &ssh.Result{Output: "done"}, nil
+	})
+}
+
+func TestExecuteTest_RecordsCongestionControl(t *testing.T) {
+	tests := []struct {
+		name         string
+		requested    string
+		wantUsed     string
+		wantMismatch bool
+	}{
+		{name: "match", requested: "bbr", wantUsed: "bbr"},
+		{name: "mismatch", requested: "dctcp", wantUsed: "cubic", wantMismatch: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			test := config.TestScenario{
+				Name:   "cc " + tt.name,
+				Client: "client",
+				Server: "server",
+				Config: &runner.Config{Args: map[string]interface{}{"congestion": tt.requested}},
+			}
+			coord := newTestCoordinator([]config.TestScenario{test}, map[string]*fakeHostClient{
+				"client": {handler: ssHandler(ssBBROutput)},
+				"server": {handler: runForever(true)},
+			})
+			executor := newTestExecutor(coord)
+
+			result, err := executor.ExecuteTest(context.Background(), &test)
+			if err != nil {
+				t.Fatalf("ExecuteTest returned error: %v", err)
+			}
+			if result.ClientResult == nil {
+				t.Fatal("Expected a client result")
+			}
+
+			if used := result.ClientResult.Metrics["cc_used"]; used != tt.wantUsed {
+				t.Errorf("cc_used = %v, want %s", used, tt.wantUsed)
+			}
+			_, mismatch := result.ClientResult.Metrics["cc_mismatch"]
+			if mismatch != tt.wantMismatch {
+				t.Errorf("cc_mismatch present = %v, want %v", mismatch, tt.wantMismatch)
+			}
+			if tt.wantMismatch && len(result.Warnings) == 0 {
+				t.Error("Expected a warning for the congestion control mismatch")
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package coordinator
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"perf-runner/config"
+	"perf-runner/runner"
+)
+
+// labelRunner appends its "label" arg to the fake command
+type labelRunner struct {
+	fakeRunner
+}
+
+func (r *labelRunner) BuildCommand(config runner.Config) string {
+	return fmt.Sprintf("%s --label %v", r.fakeRunner.BuildCommand(config), config.Args["label"])
+}
+
+func TestExecuteTest_ExpandsServerIP(t *testing.T) {
+	test := config.TestScenario{
+		Name:   "templated",
+		Client: "client",
+		Server: "server",
+		Config: &runner.Config{Args: map[string]interface{}{"label": "{{.ServerIP}}"}},
+	}
+	coord := newTestCoordinator([]config.TestScenario{test}, map[string]*fakeHostClient{
+		"client": {handler: succeed("done")},
+		"server": {handler: runForever(true)},
+	})
+	coord.RegisterRunner("fake", &labelRunner{})
+
+	result, err := newTestExecutor(coord).ExecuteTest(context.Background(), &test)
+	if err != nil {
+		t.Fatalf("ExecuteTest returned error: %v", err)
+	}
+	if result.ClientCommand != "fake-client server --label server" {
+		t.Errorf("Expected ServerIP expanded to the target, got %q", result.ClientCommand)
+	}
+}
+
+func TestRunAllTests_ExpandsIteration(t *testing.T) {
+	tests := []config.TestScenario{{
+		Name:   "templated",
+		Client: "client",
+		Server: "server",
+		Repeat: 2,
+		Config: &runner.Config{Args: map[string]interface{}{"label": "{{.ScenarioName}}-{{.Iteration}}"}},
+	}}
+	coord := newTestCoordinator(tests, map[string]*fakeHostClient{
+		"client": {handler: succeed("done")},
+		"server": {handler: runForever(true)},
+	})
+	coord.RegisterRunner("fake", &labelRunner{})
+
+	results, err := coord.RunAllTests(context.Background())
+	if err != nil {
+		t.Fatalf("RunAllTests returned error: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("Expected 2 results, got %d", len(results))
+	}
+	for i, result := range results {
+		want := fmt.Sprintf("fake-client server --label templated-%d", i+1)
+		if result.ClientCommand != want {
+			t.Errorf("Run %d: expected %q, got %q", i+1, want, result.ClientCommand)
+		}
+	}
+}
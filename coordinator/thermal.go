@@ -0,0 +1,153 @@
+package coordinator
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// thermalCommand prints "<path>:<value>" for every thermal zone temperature
+// (millidegrees Celsius), every CPU's package throttle counter, which the
+// kernel bumps each time the package is throttled for heat, and the package
+// each CPU belongs to. Every CPU of a package reports the same counter.
+const thermalCommand = "grep -H . /sys/class/thermal/thermal_zone*/temp " +
+	"/sys/devices/system/cpu/cpu*/thermal_throttle/package_throttle_count " +
+	"/sys/devices/system/cpu/cpu*/topology/physical_package_id"
+
+// thermalCollector tracks the hottest temperature and the growth of the
+// package throttle counters over the samples of one host. Counters are keyed
+// by CPU directory.
+type thermalCollector struct {
+	samples       int
+	maxTempMilliC int64
+	firstThrottle map[string]int64
+	lastThrottle  map[string]int64
+	packageOf     map[string]string
+}
+
+// newThermalCollector creates a collector with no samples
+func newThermalCollector() *thermalCollector {
+	return &thermalCollector{
+		maxTempMilliC: -1,
+		firstThrottle: make(map[string]int64),
+		lastThrottle:  make(map[string]int64),
+		packageOf:     make(map[string]string),
+	}
+}
+
+// Command returns the thermal sampling command
+func (c *thermalCollector) Command() string { return thermalCommand }
+
+// Collect records one sample of thermalCommand output
+func (c *thermalCollector) Collect(at time.Time, output string) {
+	c.samples++
+	for _, line := range strings.Split(output, "\n") {
+		path, value, found := strings.Cut(strings.TrimSpace(line), ":")
+		if !found {
+			continue
+		}
+		count, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+		if err != nil {
+			continue
+		}
+
+		switch {
+		case strings.HasSuffix(path, "/temp"):
+			if count > c.maxTempMilliC {
+				c.maxTempMilliC = count
+			}
+		case strings.HasSuffix(path, "/thermal_throttle/package_throttle_count"):
+			cpu := strings.TrimSuffix(path, "/thermal_throttle/package_throttle_count")
+			if _, seen := c.firstThrottle[cpu]; !seen {
+				c.firstThrottle[cpu] = count
+			}
+			c.lastThrottle[cpu] = count
+		case strings.HasSuffix(path, "/topology/physical_package_id"):
+			c.packageOf[strings.TrimSuffix(path, "/topology/physical_package_id")] = strconv.FormatInt(count, 10)
+		}
+	}
+}
+
+// throttleEvents returns how many package throttle events occurred between
+// the first and last sample, summed across packages. The CPUs of a package
+// share its counter, so each package is counted once; a CPU with no known
+// package counts as its own.
+func (c *thermalCollector) throttleEvents() int64 {
+	packageEvents := make(map[string]int64)
+	for cpu, last := range c.lastThrottle {
+		pkg, known := c.packageOf[cpu]
+		if !known {
+			pkg = cpu
+		}
+		if delta := last - c.firstThrottle[cpu]; delta > packageEvents[pkg] {
+			packageEvents[pkg] = delta
+		}
+	}
+
+	var events int64
+	for _, delta := range packageEvents {
+		events += delta
+	}
+	return events
+}
+
+// thermalMonitor samples the thermal state of every host during a run
+type thermalMonitor struct {
+	collectors map[string]*thermalCollector
+}
+
+// monitorThermal registers a thermal collector with the sampler of each host
+func monitorThermal(samplers *hostSamplers, hosts []testHost) *thermalMonitor {
+	monitor := &thermalMonitor{collectors: make(map[string]*thermalCollector)}
+	for _, host := range hosts {
+		if _, registered := monitor.collectors[host.name]; registered {
+			continue
+		}
+		collector := newThermalCollector()
+		samplers.register(host, collector)
+		monitor.collectors[host.name] = collector
+	}
+	return monitor
+}
+
+// record attaches thermal_throttled, throttle_events, and max_temp_c to the
+// metrics of the role each host ran, once sampling has stopped. A throttled
+// host gets a warning, or fails the scenario when strict is set.
+func (m *thermalMonitor) record(e *TestExecutor, result *TestResult, strict bool) {
+	hostResults := roleResultsByHost(result)
+	names := make([]string, 0, len(m.collectors))
+	for name := range m.collectors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		collector := m.collectors[name]
+		roleResult := hostResults[name]
+		if collector.samples < 2 || roleResult == nil {
+			continue
+		}
+
+		events := collector.throttleEvents()
+		if roleResult.Metrics == nil {
+			roleResult.Metrics = make(map[string]interface{})
+		}
+		roleResult.Metrics["thermal_throttled"] = events > 0
+		roleResult.Metrics["throttle_events"] = events
+		if collector.maxTempMilliC >= 0 {
+			roleResult.Metrics["max_temp_c"] = float64(collector.maxTempMilliC) / 1000
+		}
+		if events == 0 {
+			continue
+		}
+
+		message := fmt.Sprintf("CPUs on %s were thermally throttled during the test (%d package throttle events)", name, events)
+		if strict && result.Error == "" {
+			result.Error = message
+			continue
+		}
+		e.addWarning(result, message)
+	}
+}
@@ -0,0 +1,88 @@
+package coordinator
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"perf-runner/config"
+	"perf-runner/ssh"
+)
+
+// thermalSample renders one sample of thermalCommand output for two
+// packages of two CPUs each, every package at throttleCount
+func thermalSample(tempMilliC, throttleCount int64) string {
+	var output strings.Builder
+	fmt.Fprintf(&output, "/sys/class/thermal/thermal_zone0/temp:%d\n", tempMilliC)
+	for cpu := 0; cpu < 4; cpu++ {
+		fmt.Fprintf(&output, "/sys/devices/system/cpu/cpu%d/thermal_throttle/package_throttle_count:%d\n", cpu, throttleCount)
+	}
+	for cpu := 0; cpu < 4; cpu++ {
+		fmt.Fprintf(&output, "/sys/devices/system/cpu/cpu%d/topology/physical_package_id:%d\n", cpu, cpu/2)
+	}
+	return output.String()
+}
+
+func TestThermalCollector_RisingThrottleCount(t *testing.T) {
+	collector := newThermalCollector()
+	collector.Collect(time.Now(), thermalSample(65000, 10))
+	collector.Collect(time.Now(), thermalSample(92500, 12))
+	collector.Collect(time.Now(), thermalSample(90000, 13))
+
+	if events := collector.throttleEvents(); events != 6 {
+		t.Errorf("Expected 6 throttle events across two packages, got %d", events)
+	}
+	if collector.maxTempMilliC != 92500 {
+		t.Errorf("Expected a 92500 max temperature, got %d", collector.maxTempMilliC)
+	}
+}
+
+// thermalHandler answers the sampling loop with thermal samples in turn,
+// repeating the last one, and otherwise behaves like next
+func thermalHandler(samples []string, next func(ctx context.Context, command string) (*ssh.Result, error)) func(ctx context.Context, command string) (*ssh.Result, error) {
+	return samplingHandler(map[string]func() string{"thermal_zone": inTurn(samples...)}, next)
+}
+
+func TestExecuteTest_ThermalThrottling(t *testing.T) {
+	for _, strict := range []bool{false, true} {
+		t.Run(fmt.Sprintf("strict=%v", strict), func(t *testing.T) {
+			test := config.TestScenario{Name: "thermal", Client: "client", Server: "server", ThermalCheck: true, StrictThermal: strict}
+			throttling := []string{thermalSample(70000, 4), thermalSample(95000, 9)}
+			steady := []string{thermalSample(50000, 0), thermalSample(51000, 0)}
+			coord := newTestCoordinator([]config.TestScenario{test}, map[string]*fakeHostClient{
+				"client": {handler: thermalHandler(throttling, succeed("done"))},
+				"server": {handler: thermalHandler(steady, runForever(true))},
+			})
+			executor := newTestExecutor(coord)
+
+			result, err := executor.ExecuteTest(context.Background(), &test)
+			if err != nil {
+				t.Fatalf("ExecuteTest returned error: %v", err)
+			}
+			if result.ClientResult.Metrics["thermal_throttled"] != true || result.ClientResult.Metrics["throttle_events"] != int64(10) {
+				t.Errorf("Expected the client flagged as throttled, got %v", result.ClientResult.Metrics)
+			}
+			if result.ClientResult.Metrics["max_temp_c"] != 95.0 {
+				t.Errorf("Expected a 95.0 max temperature, got %v", result.ClientResult.Metrics["max_temp_c"])
+			}
+			if result.ServerResult.Metrics["thermal_throttled"] != false {
+				t.Errorf("Expected the server not throttled, got %v", result.ServerResult.Metrics)
+			}
+
+			if strict {
+				if result.Success || !strings.Contains(result.Error, "thermally throttled") {
+					t.Errorf("Expected strict_thermal to fail the test, got success=%v error=%q", result.Success, result.Error)
+				}
+				return
+			}
+			if !result.Success {
+				t.Errorf("Expected throttling only to warn, got error %q", result.Error)
+			}
+			if len(result.Warnings) != 1 || !strings.Contains(result.Warnings[0], "CPUs on client were thermally throttled") {
+				t.Errorf("Expected a throttling warning, got %v", result.Warnings)
+			}
+		})
+	}
+}
@@ -10,18 +10,58 @@ import (
 // TestResult represents the result of a complete test scenario
 type TestResult struct {
 	ScenarioName       string           `json:"scenario_name"`
+	Runner             string           `json:"runner,omitempty"`
+	ComparisonGroup    string           `json:"comparison_group,omitempty"`
 	Success            bool             `json:"success"`
+	Skipped            bool             `json:"skipped,omitempty"` // Not run because hosts it needs were unreachable
 	StartTime          time.Time        `json:"start_time"`
 	EndTime            time.Time        `json:"end_time"`
 	Duration           time.Duration    `json:"duration"`
 	ClientResult       *runner.Result   `json:"client_result,omitempty"`
 	ServerResult       *runner.Result   `json:"server_result,omitempty"`
 	IntermediateResult *runner.Result   `json:"intermediate_result,omitempty"`
+	ClientResults      map[string]*runner.Result `json:"client_results,omitempty"` // Additional fan-out clients by host
+	NodeResults        []NodeResult     `json:"node_results,omitempty"` // Every host of the chain in traffic order, client first
 	ClientCommand      string           `json:"client_command,omitempty"`
 	ServerCommand      string           `json:"server_command,omitempty"`
 	IntermediateCommand string          `json:"intermediate_command,omitempty"`
+	CommandHashes      map[string]string `json:"command_hashes,omitempty"` // Short hash of each role's command, for diffing runs
+	ToolVersions       map[string]string `json:"tool_versions,omitempty"` // Runner tool version detected on each host
+	Audit              []AuditRecord    `json:"audit,omitempty"`         // Per-role invocation details for auditing
 	Error              string           `json:"error,omitempty"`
+	Warnings           []string         `json:"warnings,omitempty"`
+	Hosts              map[string]string `json:"hosts,omitempty"`
+	ResolvedAddresses  map[string]string `json:"resolved_addresses,omitempty"`
+	FallbackUsed       bool             `json:"fallback_used,omitempty"`
+	Attempts           int              `json:"attempts,omitempty"`
+	ErrorClass         string           `json:"error_class,omitempty"`
+	PrimaryMetric      string           `json:"primary_metric,omitempty"` // Runner's headline client metric key
+	Autotune           *AutotuneReport  `json:"autotune,omitempty"`
 	EnvironmentInfo    *EnvironmentData `json:"environment_info,omitempty"`
+	Load               map[string]*envinfo.LoadInfo `json:"load,omitempty"` // Load of each host at test start
+	ExpectFail         bool             `json:"expect_fail,omitempty"` // Success is inverted: the scenario was expected to fail
+}
+
+// NodeResult is the outcome of one host of a test's chain
+type NodeResult struct {
+	Host    string         `json:"host"`
+	Role    string         `json:"role"`
+	Command string         `json:"command,omitempty"`
+	Result  *runner.Result `json:"result,omitempty"`
+}
+
+// AutotuneReport records an autotune sweep: the primary metric at each value
+// tried and the value that performed best
+type AutotuneReport struct {
+	Arg   string         `json:"arg"`
+	Best  int            `json:"best"`
+	Steps []AutotuneStep `json:"steps"`
+}
+
+// AutotuneStep is one autotune run
+type AutotuneStep struct {
+	Value  int     `json:"value"`
+	Metric float64 `json:"metric"`
 }
 
 // EnvironmentData contains environment information for all hosts in the test
@@ -29,4 +69,24 @@ type EnvironmentData struct {
 	ClientEnv       *envinfo.EnvironmentInfo `json:"client,omitempty"`
 	ServerEnv       *envinfo.EnvironmentInfo `json:"server,omitempty"`
 	IntermediateEnv *envinfo.EnvironmentInfo `json:"intermediate,omitempty"`
+	
+	// RelayEnvs holds the relays of a chain after the first, keyed by
+	// role, e.g. "intermediate2"
+	RelayEnvs map[string]*envinfo.EnvironmentInfo `json:"relays,omitempty"`
+}
+
+// PrimaryValue returns the client's primary metric as a number, if present
+func (r *TestResult) PrimaryValue() (float64, bool) {
+	if r.PrimaryMetric == "" || r.ClientResult == nil {
+		return 0, false
+	}
+	switch v := r.ClientResult.Metrics[r.PrimaryMetric].(type) {
+	case float64:
+		return v, true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	}
+	return 0, false
 }
\ No newline at end of file
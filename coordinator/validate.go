@@ -0,0 +1,140 @@
+package coordinator
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"golang.org/x/sync/errgroup"
+
+	"perf-runner/config"
+	"perf-runner/runner"
+)
+
+// maxParallelValidations bounds concurrent binary checks so long host chains
+// don't open a burst of SSH sessions at once
+const maxParallelValidations = 8
+
+// validateRemoteBinaries checks that the programs each host runs for its role
+// are available. Hosts are checked concurrently and the first failure is
+// returned. Binaries already validated on a host in this run are skipped.
+func (e *TestExecutor) validateRemoteBinaries(ctx context.Context, r runner.Runner, hosts []testHost) error {
+	group, groupCtx := errgroup.WithContext(ctx)
+	group.SetLimit(maxParallelValidations)
+	
+	cache := e.coordinator.cache
+	for _, host := range hosts {
+		host := host
+		for _, binary := range runner.Executables(r, host.runnerConfig()) {
+			binary := binary
+			if cache.isValidated(host.name, binary) {
+				continue
+			}
+			group.Go(func() error {
+				sshResult, err := host.client.ExecuteCommand(groupCtx, fmt.Sprintf("command -v %s", binary))
+				if err != nil || sshResult == nil || sshResult.ExitCode != 0 {
+					// A sibling's failure cancels us; report only the real error
+					if groupCtx.Err() != nil && ctx.Err() == nil {
+						return groupCtx.Err()
+					}
+					// No exit status means the check itself never ran
+					if sshResult == nil {
+						return fmt.Errorf("failed to check for %s on %s host %s: %w", binary, host.role, host.name, err)
+					}
+					return &ClassifiedError{
+						Class: ErrorDeterministic,
+						Err:   fmt.Errorf("%s binary %s not found on %s host %s", r.Name(), binary, host.role, host.name),
+					}
+				}
+				cache.markValidated(host.name, binary)
+				return nil
+			})
+		}
+	}
+	
+	return group.Wait()
+}
+
+// runnerConfig returns the host's runner configuration, or one carrying only
+// its role when none was built
+func (h testHost) runnerConfig() runner.Config {
+	if h.config != nil {
+		return *h.config
+	}
+	return runner.Config{Role: h.role}
+}
+
+// SetupCheck is the outcome of one check made by ValidateSetup
+type SetupCheck struct {
+	Host  string
+	Check string // What was checked, e.g. "connect" or "iperf3 server binary iperf3"
+	Err   error  // nil if the check passed
+}
+
+// ValidateSetup checks, without running anything, that the selected
+// scenarios could start: each host they use is connected and has the binary
+// of every role it plays. Call it after ConnectHosts with unreachable hosts
+// skipped, so that every host is reported. Checks are returned by host.
+func (c *Coordinator) ValidateSetup(ctx context.Context) []SetupCheck {
+	var checks []SetupCheck
+	for _, host := range c.referencedHosts() {
+		checks = append(checks, SetupCheck{Host: host, Check: "connect", Err: c.unreachable[host]})
+	}
+	
+	executor := c.newExecutor(c)
+	checked := make(map[string]bool)
+	for i := range c.config.Tests {
+		test := &c.config.Tests[i]
+		scenarios := []*config.TestScenario{test}
+		if len(test.FallbackHosts) > 0 {
+			scenarios = append(scenarios, test.WithFallbackHosts())
+		}
+		
+		runnerName := c.config.GetRunner(test)
+		r, exists := c.runners[runnerName]
+		if !exists {
+			checks = append(checks, SetupCheck{Check: "runner " + runnerName, Err: fmt.Errorf("runner %s not found", runnerName)})
+			continue
+		}
+		
+		for _, scenario := range scenarios {
+			for _, host := range c.roleHosts(r, scenario) {
+				if h := c.config.Hosts[host.name]; h != nil {
+					host.config = c.config.MergeRunnerConfig(h.Runner, scenario.Config)
+					host.config.Role = host.role
+				}
+				binaries := strings.Join(runner.Executables(r, host.runnerConfig()), " and ")
+				key := fmt.Sprintf("%s/%s/%s", host.name, host.role, binaries)
+				if checked[key] || host.client == nil {
+					continue
+				}
+				checked[key] = true
+				checks = append(checks, SetupCheck{
+					Host:  host.name,
+					Check: fmt.Sprintf("%s %s binary %s", r.Name(), host.role, binaries),
+					Err:   executor.validateRemoteBinaries(ctx, r, []testHost{host}),
+				})
+			}
+		}
+	}
+	
+	sort.SliceStable(checks, func(i, j int) bool { return checks[i].Host < checks[j].Host })
+	return checks
+}
+
+// roleHosts returns the hosts of a scenario with the role each plays. Hosts
+// that are not connected have a nil client.
+func (c *Coordinator) roleHosts(r runner.Runner, test *config.TestScenario) []testHost {
+	var hosts []testHost
+	if test.Server != "" && r.SupportsRole("server") {
+		hosts = append(hosts, testHost{role: "server", name: test.Server, client: c.hostClient(test.Server)})
+	}
+	for _, name := range append([]string{test.Client}, test.Clients...) {
+		hosts = append(hosts, testHost{role: "client", name: name, client: c.hostClient(name)})
+	}
+	for _, name := range test.Relays() {
+		hosts = append(hosts, testHost{role: "intermediate", name: name, client: c.hostClient(name)})
+	}
+	return hosts
+}
@@ -0,0 +1,155 @@
+package coordinator
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"perf-runner/config"
+	"perf-runner/runner"
+	"perf-runner/ssh"
+)
+
+func TestValidateRemoteBinaries_RunsConcurrently(t *testing.T) {
+	const hostCount = 4
+
+	// Each probe blocks until every host has started its probe, so a serial
+	// implementation would never get past the first host
+	var started sync.WaitGroup
+	started.Add(hostCount)
+	allStarted := make(chan struct{})
+	go func() {
+		started.Wait()
+		close(allStarted)
+	}()
+
+	probe := func(ctx context.Context, command string) (*ssh.Result, error) {
+		started.Done()
+		select {
+		case <-allStarted:
+			return &ssh.Result{Output: "/usr/bin/fake"}, nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	var hosts []testHost
+	clients := make(map[string]*fakeHostClient)
+	for i := 0; i < hostCount; i++ {
+		name := fmt.Sprintf("host%d", i)
+		clients[name] = &fakeHostClient{probe: probe}
+		hosts = append(hosts, testHost{role: "client", name: name, client: clients[name]})
+	}
+
+	executor := newTestExecutor(newTestCoordinator(nil, clients))
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	if err := executor.validateRemoteBinaries(ctx, &fakeRunner{}, hosts); err != nil {
+		t.Fatalf("Expected validations to run concurrently and succeed, got: %v", err)
+	}
+	for name, client := range clients {
+		if len(client.probes) != 1 || client.probes[0] != "command -v fake" {
+			t.Errorf("Host %s: expected one binary check, got %v", name, client.probes)
+		}
+	}
+}
+
+func TestValidateRemoteBinaries_SurfacesMissingBinary(t *testing.T) {
+	missing := func(ctx context.Context, command string) (*ssh.Result, error) {
+		return &ssh.Result{ExitCode: 1, Error: "Process exited with status 1"}, fmt.Errorf("Process exited with status 1")
+	}
+	// The healthy host stays blocked until the failure cancels it
+	slow := func(ctx context.Context, command string) (*ssh.Result, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+
+	clients := map[string]*fakeHostClient{
+		"server": {probe: slow},
+		"client": {probe: missing},
+	}
+	hosts := []testHost{
+		{role: "server", name: "server", client: clients["server"]},
+		{role: "client", name: "client", client: clients["client"]},
+	}
+
+	executor := newTestExecutor(newTestCoordinator(nil, clients))
+	err := executor.validateRemoteBinaries(context.Background(), &fakeRunner{}, hosts)
+	if err == nil {
+		t.Fatal("Expected an error for the missing binary")
+	}
+	if !strings.Contains(err.Error(), "client host client") || strings.Contains(err.Error(), "canceled") {
+		t.Errorf("Expected the missing-binary error from the client host, got: %v", err)
+	}
+}
+
+func TestValidateRemoteBinaries_ChecksEveryProgramOfTheRole(t *testing.T) {
+	clients := map[string]*fakeHostClient{
+		"server": {},
+		"client": {},
+		"plain":  {},
+	}
+	hosts := []testHost{
+		{role: "server", name: "server", client: clients["server"], config: &runner.Config{Role: "server", Args: map[string]interface{}{"server_command": "nginx -g 'daemon off;'"}}},
+		{role: "client", name: "client", client: clients["client"], config: &runner.Config{Role: "client", Args: map[string]interface{}{"ttfb_script": "/opt/wrk-ttfb.py"}}},
+		{role: "client", name: "plain", client: clients["plain"], config: &runner.Config{Role: "client"}},
+	}
+
+	executor := newTestExecutor(newTestCoordinator(nil, clients))
+	if err := executor.validateRemoteBinaries(context.Background(), runner.NewWrkRunner(""), hosts); err != nil {
+		t.Fatalf("validateRemoteBinaries returned error: %v", err)
+	}
+
+	want := map[string]string{
+		"server": "command -v nginx",
+		"client": "command -v python3,command -v wrk",
+		"plain":  "command -v wrk",
+	}
+	for name, client := range clients {
+		probes := append([]string(nil), client.probes...)
+		sort.Strings(probes)
+		if got := strings.Join(probes, ","); got != want[name] {
+			t.Errorf("Host %s: expected probes %q, got %q", name, want[name], got)
+		}
+	}
+}
+
+func TestValidateSetup_ReportsEveryHost(t *testing.T) {
+	missing := func(ctx context.Context, command string) (*ssh.Result, error) {
+		return &ssh.Result{ExitCode: 1, Error: "Process exited with status 1"}, fmt.Errorf("Process exited with status 1")
+	}
+	clients := map[string]*fakeHostClient{
+		"server": {},
+		"client": {probe: missing},
+	}
+	tests := []config.TestScenario{
+		{Name: "a", Client: "client", Server: "server"},
+		{Name: "b", Client: "down", Server: "server"},
+	}
+	coord := newTestCoordinator(tests, clients)
+	coord.config.Hosts["down"] = &config.HostConfig{SSH: &ssh.Config{Host: "down", User: "test"}}
+	coord.unreachable = map[string]error{"down": fmt.Errorf("connection refused")}
+
+	var got []string
+	for _, check := range coord.ValidateSetup(context.Background()) {
+		got = append(got, fmt.Sprintf("%s %s %v", check.Host, check.Check, check.Err != nil))
+	}
+	want := []string{
+		"client connect false",
+		"client fake client binary fake true",
+		"down connect true",
+		"server connect false",
+		"server fake server binary fake false",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Expected checks:\n%s\ngot:\n%s", strings.Join(want, "\n"), strings.Join(got, "\n"))
+	}
+	if probes := len(clients["server"].probes); probes != 1 {
+		t.Errorf("Expected the shared server checked once, got %d probes", probes)
+	}
+}
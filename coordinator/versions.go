@@ -0,0 +1,83 @@
+package coordinator
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"perf-runner/runner"
+)
+
+// versionRegex matches a dotted version number in a tool's version banner
+var versionRegex = regexp.MustCompile(`\d+(?:\.\d+)+`)
+
+// parseToolVersion extracts the version from the first line of a version
+// banner, e.g. "3.9" from "iperf 3.9 (cJSON 1.7.13)". Without a dotted
+// number the whole line is the version.
+func parseToolVersion(output string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(output), "\n")
+	if version := versionRegex.FindString(line); version != "" {
+		return version
+	}
+	return strings.TrimSpace(line)
+}
+
+// detectToolVersions returns the version of the runner's tool on each client
+// and server host, keyed by host name. Intermediate hosts only relay traffic
+// and are skipped. Hosts whose version cannot be read are left out.
+func (e *TestExecutor) detectToolVersions(ctx context.Context, r runner.Runner, hosts []testHost) map[string]string {
+	versioned, ok := r.(runner.Versioned)
+	if !ok {
+		return nil
+	}
+
+	cache := e.coordinator.cache
+	binary := r.ExecutablePath()
+	versions := make(map[string]string)
+	for _, host := range hosts {
+		if host.role == "intermediate" {
+			continue
+		}
+		if _, seen := versions[host.name]; seen {
+			continue
+		}
+		if version, ok := cache.toolVersion(host.name, binary); ok {
+			versions[host.name] = version
+			continue
+		}
+
+		sshResult, err := host.client.ExecuteCommand(ctx, versioned.VersionCommand())
+		if err != nil || sshResult == nil {
+			e.coordinator.logger.Printf("  Warning: could not read the %s version on %s: %v", r.Name(), host.name, err)
+			continue
+		}
+		if version := parseToolVersion(sshResult.Output); version != "" {
+			versions[host.name] = version
+			cache.storeToolVersion(host.name, binary, version)
+		}
+	}
+	return versions
+}
+
+// versionMismatch describes the versions in use when hosts run different
+// versions of the tool, or returns "" when they agree
+func versionMismatch(toolName string, versions map[string]string) string {
+	distinct := make(map[string]bool)
+	hosts := make([]string, 0, len(versions))
+	for host, version := range versions {
+		distinct[version] = true
+		hosts = append(hosts, host)
+	}
+	if len(distinct) < 2 {
+		return ""
+	}
+
+	sort.Strings(hosts)
+	parts := make([]string, len(hosts))
+	for i, host := range hosts {
+		parts[i] = fmt.Sprintf("%s %s", host, versions[host])
+	}
+	return fmt.Sprintf("%s versions differ across hosts: %s", toolName, strings.Join(parts, ", "))
+}
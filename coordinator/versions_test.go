@@ -0,0 +1,103 @@
+package coordinator
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"perf-runner/config"
+	"perf-runner/ssh"
+)
+
+// versionedRunner reports its version through "fake --version"
+type versionedRunner struct {
+	fakeRunner
+}
+
+func (r *versionedRunner) VersionCommand() string { return "fake --version" }
+
+// versionHandler answers the version command with banner and otherwise
+// behaves like next
+func versionHandler(banner string, next func(ctx context.Context, command string) (*ssh.Result, error)) func(ctx context.Context, command string) (*ssh.Result, error) {
+	return func(ctx context.Context, command string) (*ssh.Result, error) {
+		if command == "fake --version" {
+			return &ssh.Result{Output: banner + "\nLinux host 6.1.0 x86_64\n"}, nil
+		}
+		return next(ctx, command)
+	}
+}
+
+func TestExecuteTest_WarnsOnToolVersionMismatch(t *testing.T) {
+	test := config.TestScenario{Name: "versions", Client: "client", Server: "server"}
+	coord := newTestCoordinator([]config.TestScenario{test}, map[string]*fakeHostClient{
+		"client": {handler: versionHandler("iperf 3.9 (cJSON 1.7.13)", succeed("done"))},
+		"server": {handler: versionHandler("iperf 3.16 (cJSON 1.7.15)", runForever(true))},
+	})
+	coord.RegisterRunner("fake", &versionedRunner{})
+
+	result, err := newTestExecutor(coord).ExecuteTest(context.Background(), &test)
+	if err != nil {
+		t.Fatalf("ExecuteTest returned error: %v", err)
+	}
+	if !result.Success {
+		t.Errorf("Expected a version mismatch not to fail the test, got error %q", result.Error)
+	}
+	if result.ToolVersions["client"] != "3.9" || result.ToolVersions["server"] != "3.16" {
+		t.Errorf("Expected both versions recorded, got %v", result.ToolVersions)
+	}
+	if len(result.Warnings) != 1 || result.Warnings[0] != "fake versions differ across hosts: client 3.9, server 3.16" {
+		t.Errorf("Expected a version mismatch warning, got %v", result.Warnings)
+	}
+}
+
+func TestExecuteTest_StrictVersionsFails(t *testing.T) {
+	test := config.TestScenario{Name: "versions", Client: "client", Server: "server", StrictVersions: true}
+	client := &fakeHostClient{handler: versionHandler("iperf 3.9", succeed("done"))}
+	coord := newTestCoordinator([]config.TestScenario{test}, map[string]*fakeHostClient{
+		"client": client,
+		"server": {handler: versionHandler("iperf 3.16", runForever(true))},
+	})
+	coord.RegisterRunner("fake", &versionedRunner{})
+
+	_, err := newTestExecutor(coord).ExecuteTest(context.Background(), &test)
+	if err == nil || !strings.Contains(err.Error(), "versions differ") {
+		t.Fatalf("Expected a version mismatch error, got %v", err)
+	}
+	if classifyError(err) != ErrorDeterministic {
+		t.Errorf("Expected a deterministic error, got %s", classifyError(err))
+	}
+	if len(client.commands) != 1 {
+		t.Errorf("Expected only the version check on the client, got %v", client.commands)
+	}
+}
+
+func TestExecuteTest_MatchingToolVersions(t *testing.T) {
+	test := config.TestScenario{Name: "versions", Client: "client", Server: "server", StrictVersions: true}
+	coord := newTestCoordinator([]config.TestScenario{test}, map[string]*fakeHostClient{
+		"client": {handler: versionHandler("iperf 3.16", succeed("done"))},
+		"server": {handler: versionHandler("iperf 3.16", runForever(true))},
+	})
+	coord.RegisterRunner("fake", &versionedRunner{})
+
+	result, err := newTestExecutor(coord).ExecuteTest(context.Background(), &test)
+	if err != nil {
+		t.Fatalf("ExecuteTest returned error: %v", err)
+	}
+	if len(result.Warnings) != 0 {
+		t.Errorf("Expected no warnings for matching versions, got %v", result.Warnings)
+	}
+}
+
+func TestParseToolVersion(t *testing.T) {
+	tests := map[string]string{
+		"iperf 3.9 (cJSON 1.7.13)\nLinux client1": "3.9",
+		"Version: 5.96":    "5.96",
+		"  custom-build\n": "custom-build",
+		"":                 "",
+	}
+	for output, want := range tests {
+		if got := parseToolVersion(output); got != want {
+			t.Errorf("parseToolVersion(%q) = %q, want %q", output, got, want)
+		}
+	}
+}
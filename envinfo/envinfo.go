@@ -27,14 +27,19 @@ type EnvironmentInfo struct {
 }
 
 
+// RemoteCommandRunner is implemented by clients that run commands on a remote host
+type RemoteCommandRunner interface {
+	ExecuteCommand(ctx context.Context, command string) (*ssh.Result, error)
+}
+
 // Collector handles environment information collection
 type Collector struct {
-	sshClient *ssh.Client
+	sshClient RemoteCommandRunner
 	local     bool
 }
 
 // NewCollector creates a new environment information collector
-func NewCollector(sshClient *ssh.Client) *Collector {
+func NewCollector(sshClient RemoteCommandRunner) *Collector {
 	return &Collector{
 		sshClient: sshClient,
 		local:     sshClient == nil,
@@ -230,7 +235,7 @@ func (c *Collector) collectRemoteNetworkInfo(ctx context.Context, env *Environme
 		// Check if interface is up
 		stateOutput, err := c.executeCommand(ctx, fmt.Sprintf("cat /sys/class/net/%s/operstate", ifaceName))
 		if err == nil {
-			netInterface.IsUp = strings.TrimSpace(stateOutput) == "up"
+			netInterface.IsUp = operstateUp(stateOutput)
 		}
 
 		// Try to get speed (may not be available for all interfaces)
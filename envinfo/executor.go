@@ -4,8 +4,6 @@ import (
 	"context"
 	"fmt"
 	"os/exec"
-
-	"perf-runner/ssh"
 )
 
 // LocalExecutor executes commands on the local system
@@ -28,11 +26,11 @@ func (e *LocalExecutor) Execute(ctx context.Context, command string) (string, er
 
 // RemoteExecutor executes commands on a remote system via SSH
 type RemoteExecutor struct {
-	sshClient *ssh.Client
+	sshClient RemoteCommandRunner
 }
 
 // NewRemoteExecutor creates a new remote command executor
-func NewRemoteExecutor(sshClient *ssh.Client) *RemoteExecutor {
+func NewRemoteExecutor(sshClient RemoteCommandRunner) *RemoteExecutor {
 	return &RemoteExecutor{
 		sshClient: sshClient,
 	}
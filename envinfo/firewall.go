@@ -0,0 +1,249 @@
+package envinfo
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// FirewallInfo summarizes the packet filter state of a host
+type FirewallInfo struct {
+	Backends       []string          `json:"backends"`                  // iptables, nftables, firewalld
+	FirewalldState string            `json:"firewalld_state,omitempty"` // "running" or "not running"
+	Policies       map[string]string `json:"policies,omitempty"`        // iptables chain -> default policy
+	Rules          []FirewallRule    `json:"rules,omitempty"`           // iptables rules in evaluation order
+	NftRuleCount   int               `json:"nft_rule_count,omitempty"`
+	NftBlocked     []FirewallRule    `json:"nft_blocked,omitempty"` // nftables input rules that drop or reject a port
+}
+
+// FirewallRule is a single filter rule reduced to the fields that decide
+// whether test traffic gets through
+type FirewallRule struct {
+	Chain    string `json:"chain"`
+	Protocol string `json:"protocol,omitempty"`
+	Ports    string `json:"ports,omitempty"` // as written: "5201", "5201:5210", "22,80"
+	Target   string `json:"target"`
+	// Conditional rules also match on source, interface, or state, so they
+	// cannot be said to apply to all test traffic
+	Conditional bool   `json:"conditional,omitempty"`
+	Raw         string `json:"raw"`
+}
+
+var nftDportRegex = regexp.MustCompile(`(tcp|udp)\s+dport\s+(\{[^}]*\}|\S+)`)
+
+// FirewallModule collects iptables, nftables, and firewalld state
+type FirewallModule struct{}
+
+// NewFirewallModule creates a new firewall information module
+func NewFirewallModule() *FirewallModule {
+	return &FirewallModule{}
+}
+
+// Name returns the module name
+func (m *FirewallModule) Name() string {
+	return "firewall"
+}
+
+// Description returns the module description
+func (m *FirewallModule) Description() string {
+	return "Summarizes iptables/nftables rules and firewalld state"
+}
+
+// IsAvailable checks if the module can run
+func (m *FirewallModule) IsAvailable(ctx context.Context, executor CommandExecutor) bool {
+	_, err := executor.Execute(ctx, "command -v iptables || command -v nft || command -v firewall-cmd")
+	return err == nil
+}
+
+// Collect gathers firewall information
+func (m *FirewallModule) Collect(ctx context.Context, executor CommandExecutor) (interface{}, error) {
+	info := &FirewallInfo{}
+
+	// Listing rules usually needs root; fall back to passwordless sudo
+	if output, err := executor.Execute(ctx, "iptables -S 2>/dev/null || sudo -n iptables -S 2>/dev/null"); err == nil && strings.TrimSpace(output) != "" {
+		info.Backends = append(info.Backends, "iptables")
+		info.Policies, info.Rules = parseIptablesRules(output)
+	}
+
+	if output, err := executor.Execute(ctx, "nft list ruleset 2>/dev/null || sudo -n nft list ruleset 2>/dev/null"); err == nil && strings.TrimSpace(output) != "" {
+		info.Backends = append(info.Backends, "nftables")
+		info.NftRuleCount, info.NftBlocked = parseNftRuleset(output)
+	}
+
+	if output, err := executor.Execute(ctx, "firewall-cmd --state 2>&1"); err == nil || strings.Contains(output, "not running") {
+		if state := strings.TrimSpace(output); state != "" {
+			info.Backends = append(info.Backends, "firewalld")
+			info.FirewalldState = state
+		}
+	}
+
+	if len(info.Backends) == 0 {
+		return nil, fmt.Errorf("no firewall state could be read (missing tools or permissions)")
+	}
+
+	return info, nil
+}
+
+// parseIptablesRules parses `iptables -S` output into chain policies and rules
+func parseIptablesRules(output string) (map[string]string, []FirewallRule) {
+	policies := make(map[string]string)
+	var rules []FirewallRule
+
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+
+		switch fields[0] {
+		case "-P":
+			if len(fields) >= 3 {
+				policies[fields[1]] = fields[2]
+			}
+		case "-A":
+			rule := FirewallRule{Chain: fields[1], Raw: line}
+			for i := 2; i < len(fields); i++ {
+				arg := fields[i]
+				var value string
+				if i+1 < len(fields) {
+					value = fields[i+1]
+				}
+				switch arg {
+				case "-p":
+					rule.Protocol = value
+					i++
+				case "--dport", "--dports", "--destination-port":
+					rule.Ports = value
+					i++
+				case "-j":
+					rule.Target = value
+					i++
+				case "-s", "-i", "--state", "--ctstate", "--src-range", "!":
+					rule.Conditional = true
+				}
+			}
+			rules = append(rules, rule)
+		}
+	}
+
+	return policies, rules
+}
+
+// parseNftRuleset counts rules in `nft list ruleset` output and returns the
+// ones in input-hooked chains that drop or reject specific destination ports
+func parseNftRuleset(output string) (int, []FirewallRule) {
+	var (
+		count   int
+		blocked []FirewallRule
+		chain   string
+		isInput bool
+	)
+
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(line, "chain "):
+			chain = strings.TrimSuffix(strings.TrimSpace(strings.TrimPrefix(line, "chain ")), " {")
+			isInput = false
+		case strings.HasPrefix(line, "type ") && strings.Contains(line, "hook input"):
+			isInput = true
+		case line == "" || line == "}" || strings.HasPrefix(line, "table ") || strings.HasPrefix(line, "type "):
+		default:
+			count++
+			if !isInput {
+				continue
+			}
+			target := ""
+			if strings.HasSuffix(line, " drop") || line == "drop" {
+				target = "DROP"
+			} else if strings.Contains(line, " reject") {
+				target = "REJECT"
+			}
+			if target == "" {
+				continue
+			}
+			if match := nftDportRegex.FindStringSubmatch(line); match != nil {
+				ports := strings.Trim(match[2], "{} ")
+				ports = strings.ReplaceAll(strings.ReplaceAll(ports, " ", ""), "-", ":")
+				blocked = append(blocked, FirewallRule{
+					Chain:       chain,
+					Protocol:    match[1],
+					Ports:       ports,
+					Target:      target,
+					Conditional: strings.Contains(line, "saddr") || strings.Contains(line, "iifname"),
+					Raw:         line,
+				})
+			}
+		}
+	}
+
+	return count, blocked
+}
+
+// BlockingRule reports the first rule that would drop or reject inbound
+// traffic to the given TCP/UDP port, following iptables first-match order.
+// Conditional rules are skipped, so a "blocked" verdict is a strong hint but
+// an empty one is not a guarantee.
+func (info *FirewallInfo) BlockingRule(port int) (string, bool) {
+	if info == nil || port <= 0 {
+		return "", false
+	}
+
+	for _, rule := range info.Rules {
+		if rule.Chain != "INPUT" || rule.Conditional || !rule.coversPort(port) {
+			continue
+		}
+		switch rule.Target {
+		case "ACCEPT":
+			return "", false
+		case "DROP", "REJECT":
+			return rule.Raw, true
+		case "LOG":
+		default:
+			// Jumps into user chains (ufw, firewalld, docker) are not followed
+			return "", false
+		}
+	}
+
+	if policy := info.Policies["INPUT"]; policy == "DROP" || policy == "REJECT" {
+		return "-P INPUT " + policy, true
+	}
+
+	for _, rule := range info.NftBlocked {
+		if !rule.Conditional && rule.coversPort(port) {
+			return rule.Raw, true
+		}
+	}
+
+	return "", false
+}
+
+// coversPort reports whether the rule applies to the port. A rule without
+// a port match applies to every port.
+func (r FirewallRule) coversPort(port int) bool {
+	if r.Ports == "" {
+		return true
+	}
+	for _, part := range strings.Split(r.Ports, ",") {
+		low, high, isRange := strings.Cut(part, ":")
+		if !isRange {
+			high = low
+		}
+		lo, err1 := strconv.Atoi(low)
+		hi, err2 := strconv.Atoi(high)
+		if err1 == nil && err2 == nil && port >= lo && port <= hi {
+			return true
+		}
+	}
+	return false
+}
+
+// Auto-register this module
+func init() {
+	RegisterModule("firewall", func() Module {
+		return NewFirewallModule()
+	})
+}
@@ -0,0 +1,95 @@
+package envinfo
+
+import "testing"
+
+const iptablesRuleset = `-P INPUT ACCEPT
+-P FORWARD DROP
+-P OUTPUT ACCEPT
+-A INPUT -i lo -j ACCEPT
+-A INPUT -m conntrack --ctstate RELATED,ESTABLISHED -j ACCEPT
+-A INPUT -p tcp -m tcp --dport 22 -j ACCEPT
+-A INPUT -p tcp -m multiport --dports 5201:5210,18515 -j DROP
+-A INPUT -p udp -m udp --dport 4791 -j REJECT --reject-with icmp-port-unreachable
+-A FORWARD -p tcp -m tcp --dport 8080 -j DROP`
+
+func TestParseIptablesRules(t *testing.T) {
+	policies, rules := parseIptablesRules(iptablesRuleset)
+
+	if policies["INPUT"] != "ACCEPT" || policies["FORWARD"] != "DROP" {
+		t.Errorf("Unexpected policies: %v", policies)
+	}
+	if len(rules) != 6 {
+		t.Fatalf("Expected 6 rules, got %d", len(rules))
+	}
+
+	drop := rules[3]
+	if drop.Chain != "INPUT" || drop.Protocol != "tcp" || drop.Ports != "5201:5210,18515" || drop.Target != "DROP" || drop.Conditional {
+		t.Errorf("Unexpected DROP rule: %+v", drop)
+	}
+	if !rules[0].Conditional || !rules[1].Conditional {
+		t.Errorf("Expected interface and state rules to be conditional: %+v %+v", rules[0], rules[1])
+	}
+}
+
+func TestFirewallInfo_BlockingRule(t *testing.T) {
+	policies, rules := parseIptablesRules(iptablesRuleset)
+	info := &FirewallInfo{Policies: policies, Rules: rules}
+
+	tests := []struct {
+		port    int
+		blocked bool
+	}{
+		{5201, true},
+		{5210, true},
+		{18515, true},
+		{4791, true},
+		{22, false},
+		{5211, false},
+		{8080, false}, // dropped in FORWARD, not INPUT
+	}
+	for _, tt := range tests {
+		rule, blocked := info.BlockingRule(tt.port)
+		if blocked != tt.blocked {
+			t.Errorf("BlockingRule(%d) = %q, %v; want blocked %v", tt.port, rule, blocked, tt.blocked)
+		}
+	}
+
+	// A DROP policy blocks anything not explicitly accepted
+	info.Policies["INPUT"] = "DROP"
+	if _, blocked := info.BlockingRule(5211); !blocked {
+		t.Error("Expected INPUT policy DROP to block port 5211")
+	}
+	if _, blocked := info.BlockingRule(22); blocked {
+		t.Error("Expected explicitly accepted port 22 not to be blocked")
+	}
+}
+
+func TestParseNftRuleset(t *testing.T) {
+	ruleset := `table inet filter {
+	chain input {
+		type filter hook input priority filter; policy accept;
+		iif "lo" accept
+		tcp dport { 5201, 5202 } drop
+		udp dport 4791 reject
+	}
+	chain forward {
+		type filter hook forward priority filter; policy drop;
+		tcp dport 8080 drop
+	}
+}`
+	count, blocked := parseNftRuleset(ruleset)
+	if count != 4 {
+		t.Errorf("Expected 4 rules, got %d", count)
+	}
+	if len(blocked) != 2 {
+		t.Fatalf("Expected 2 blocking input rules, got %+v", blocked)
+	}
+
+	info := &FirewallInfo{NftBlocked: blocked}
+	if _, ok := info.BlockingRule(5202); !ok {
+		t.Error("Expected nftables drop to block port 5202")
+	}
+	if _, ok := info.BlockingRule(8080); ok {
+		t.Error("Expected forward-chain drop not to block port 8080")
+	}
+}
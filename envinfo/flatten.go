@@ -0,0 +1,68 @@
+package envinfo
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// Modules returns the environment keyed by the names of the modules that
+// collect the same data, so it can be handled like modular environment data
+func (env *EnvironmentInfo) Modules() map[string]interface{} {
+	return map[string]interface{}{
+		"system": SystemInfo{
+			Hostname:      env.Hostname,
+			KernelVersion: env.KernelVersion,
+			OSInfo:        env.OSInfo,
+			Architecture:  env.Architecture,
+			Timestamp:     env.Timestamp,
+		},
+		"cpu":      env.CPUInfo,
+		"memory":   env.MemoryInfo,
+		"network":  NetworkInfo{Interfaces: env.NetworkInterfaces},
+		"software": env.SoftwareVersions,
+	}
+}
+
+// FlattenModules flattens module data into dotted keys using the JSON field
+// names, e.g. "cpu.model" or "network.interfaces.0.name" (list entries are
+// keyed by index). Empty objects and lists produce no keys.
+func FlattenModules(modules map[string]interface{}) (map[string]string, error) {
+	data, err := json.Marshal(modules)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode module data: %w", err)
+	}
+
+	// Decode numbers as json.Number so large counters keep their digits
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var tree map[string]interface{}
+	if err := decoder.Decode(&tree); err != nil {
+		return nil, fmt.Errorf("failed to decode module data: %w", err)
+	}
+
+	flat := make(map[string]string)
+	for name, value := range tree {
+		flattenValue(flat, name, value)
+	}
+	return flat, nil
+}
+
+// flattenValue adds value to flat under key, descending into objects and lists
+func flattenValue(flat map[string]string, key string, value interface{}) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for field, nested := range v {
+			flattenValue(flat, key+"."+field, nested)
+		}
+	case []interface{}:
+		for i, nested := range v {
+			flattenValue(flat, key+"."+strconv.Itoa(i), nested)
+		}
+	case nil:
+		flat[key] = ""
+	default:
+		flat[key] = fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,38 @@
+package envinfo
+
+import "testing"
+
+func TestFlattenModules_NestedFieldsBecomeDottedKeys(t *testing.T) {
+	env := &EnvironmentInfo{
+		Hostname: "server1",
+		CPUInfo:  CPUInfo{Model: "Xeon", Cores: 32},
+		NetworkInterfaces: []NetworkInterface{
+			{Name: "ens1f0", IPAddresses: []string{"10.0.0.1/24"}, MTU: 9000, IsUp: true},
+		},
+		SoftwareVersions: SoftwareVersions{Iperf3: "iperf 3.16"},
+	}
+
+	flat, err := FlattenModules(env.Modules())
+	if err != nil {
+		t.Fatalf("FlattenModules returned error: %v", err)
+	}
+
+	want := map[string]string{
+		"system.hostname":                     "server1",
+		"cpu.model":                           "Xeon",
+		"cpu.cores":                           "32",
+		"network.interfaces.0.name":           "ens1f0",
+		"network.interfaces.0.ip_addresses.0": "10.0.0.1/24",
+		"network.interfaces.0.mtu":            "9000",
+		"network.interfaces.0.is_up":          "true",
+		"software.iperf3":                     "iperf 3.16",
+	}
+	for key, value := range want {
+		if flat[key] != value {
+			t.Errorf("%s = %q, want %q", key, flat[key], value)
+		}
+	}
+	if _, ok := flat["cpu"]; ok {
+		t.Error("Expected nested objects to be flattened, not stored whole")
+	}
+}
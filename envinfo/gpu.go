@@ -0,0 +1,125 @@
+package envinfo
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"strings"
+)
+
+// nvidiaSMIQuery lists each NVIDIA GPU as "name, memory.total, driver_version"
+const nvidiaSMIQuery = "nvidia-smi --query-gpu=name,memory.total,driver_version --format=csv,noheader"
+
+// rocmSMIQuery reports AMD GPUs as JSON keyed by card, plus a "system" entry
+const rocmSMIQuery = "rocm-smi --showproductname --showmeminfo vram --showdriverversion --json"
+
+// GPUDevice describes one GPU of a host
+type GPUDevice struct {
+	Vendor string `json:"vendor"`
+	Model  string `json:"model"`
+	Memory string `json:"memory"`
+	Driver string `json:"driver"`
+}
+
+// GPUModule collects the GPUs present on a host from nvidia-smi or rocm-smi
+type GPUModule struct{}
+
+// NewGPUModule creates a new GPU information module
+func NewGPUModule() *GPUModule {
+	return &GPUModule{}
+}
+
+// Name returns the module name
+func (m *GPUModule) Name() string {
+	return "gpu"
+}
+
+// Description returns the module description
+func (m *GPUModule) Description() string {
+	return "Collects GPU model, memory, and driver version (nvidia-smi, rocm-smi)"
+}
+
+// IsAvailable checks if the module can run; hosts without a GPU tool
+// report unavailable
+func (m *GPUModule) IsAvailable(ctx context.Context, executor CommandExecutor) bool {
+	_, err := executor.Execute(ctx, "command -v nvidia-smi || command -v rocm-smi")
+	return err == nil
+}
+
+// Collect gathers GPU information. A tool that finds no devices, e.g.
+// nvidia-smi on a host whose driver is loaded but GPU is missing, yields
+// an empty list rather than an error.
+func (m *GPUModule) Collect(ctx context.Context, executor CommandExecutor) (interface{}, error) {
+	gpus := []GPUDevice{}
+	if output, err := executor.Execute(ctx, nvidiaSMIQuery+" 2>/dev/null"); err == nil {
+		gpus = append(gpus, parseNvidiaSMI(output)...)
+	}
+	if output, err := executor.Execute(ctx, rocmSMIQuery+" 2>/dev/null"); err == nil {
+		gpus = append(gpus, parseRocmSMI(output)...)
+	}
+	return gpus, nil
+}
+
+// parseNvidiaSMI parses nvidia-smi CSV query output, one GPU per line
+func parseNvidiaSMI(output string) []GPUDevice {
+	var gpus []GPUDevice
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Split(line, ",")
+		if len(fields) != 3 {
+			continue
+		}
+		gpus = append(gpus, GPUDevice{
+			Vendor: "nvidia",
+			Model:  strings.TrimSpace(fields[0]),
+			Memory: strings.TrimSpace(fields[1]),
+			Driver: strings.TrimSpace(fields[2]),
+		})
+	}
+	return gpus
+}
+
+// parseRocmSMI parses rocm-smi JSON output. Cards are listed in card order;
+// the driver version is reported once for the whole system.
+func parseRocmSMI(output string) []GPUDevice {
+	var cards map[string]map[string]string
+	if err := json.Unmarshal([]byte(output), &cards); err != nil {
+		return nil
+	}
+
+	driver := cards["system"]["Driver version"]
+	names := make([]string, 0, len(cards))
+	for name := range cards {
+		if strings.HasPrefix(name, "card") {
+			names = append(names, name)
+		}
+	}
+	// Order card2 before card10
+	sort.Slice(names, func(i, j int) bool {
+		if len(names[i]) != len(names[j]) {
+			return len(names[i]) < len(names[j])
+		}
+		return names[i] < names[j]
+	})
+
+	var gpus []GPUDevice
+	for _, name := range names {
+		card := cards[name]
+		model := card["Card Series"]
+		if model == "" {
+			model = card["Card series"]
+		}
+		memory := card["VRAM Total Memory (B)"]
+		if memory != "" {
+			memory += " B"
+		}
+		gpus = append(gpus, GPUDevice{Vendor: "amd", Model: model, Memory: memory, Driver: driver})
+	}
+	return gpus
+}
+
+// Auto-register this module
+func init() {
+	RegisterModule("gpu", func() Module {
+		return NewGPUModule()
+	})
+}
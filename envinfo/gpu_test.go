@@ -0,0 +1,62 @@
+package envinfo
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+// scriptedExecutor answers commands from a map; other commands fail
+type scriptedExecutor map[string]string
+
+func (e scriptedExecutor) Execute(ctx context.Context, command string) (string, error) {
+	if output, ok := e[command]; ok {
+		return output, nil
+	}
+	return "", fmt.Errorf("command not found: %s", command)
+}
+
+func TestParseNvidiaSMI(t *testing.T) {
+	output := "NVIDIA A100-PCIE-40GB, 40960 MiB, 535.104.05\nNVIDIA A100-PCIE-40GB, 40960 MiB, 535.104.05\n"
+	want := GPUDevice{Vendor: "nvidia", Model: "NVIDIA A100-PCIE-40GB", Memory: "40960 MiB", Driver: "535.104.05"}
+
+	gpus := parseNvidiaSMI(output)
+	if len(gpus) != 2 || gpus[0] != want || gpus[1] != want {
+		t.Errorf("Expected two %+v, got %+v", want, gpus)
+	}
+	if gpus := parseNvidiaSMI("No devices were found\n"); len(gpus) != 0 {
+		t.Errorf("Expected no GPUs, got %+v", gpus)
+	}
+}
+
+func TestParseRocmSMI(t *testing.T) {
+	output := `{"card10": {"Card Series": "MI210", "VRAM Total Memory (B)": "68702699520"},
+"card2": {"Card Series": "MI250X", "VRAM Total Memory (B)": "68702699520"},
+"system": {"Driver version": "6.3.6"}}`
+
+	gpus := parseRocmSMI(output)
+	want := []GPUDevice{
+		{Vendor: "amd", Model: "MI250X", Memory: "68702699520 B", Driver: "6.3.6"},
+		{Vendor: "amd", Model: "MI210", Memory: "68702699520 B", Driver: "6.3.6"},
+	}
+	if !reflect.DeepEqual(gpus, want) {
+		t.Errorf("Expected %+v, got %+v", want, gpus)
+	}
+}
+
+func TestGPUModule_NoGPU(t *testing.T) {
+	module := NewGPUModule()
+	executor := scriptedExecutor{}
+
+	if module.IsAvailable(context.Background(), executor) {
+		t.Error("Expected the module to be unavailable without nvidia-smi or rocm-smi")
+	}
+	data, err := module.Collect(context.Background(), executor)
+	if err != nil {
+		t.Fatalf("Collect returned error: %v", err)
+	}
+	if gpus, ok := data.([]GPUDevice); !ok || len(gpus) != 0 {
+		t.Errorf("Expected an empty GPU list, got %#v", data)
+	}
+}
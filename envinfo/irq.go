@@ -0,0 +1,265 @@
+package envinfo
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Auto-register the irq module
+func init() {
+	RegisterModule("irq", func() Module { return NewIRQModule() })
+}
+
+// IRQInfo maps each network interface with interrupts to its queues and the
+// CPUs serving them
+type IRQInfo struct {
+	Interfaces map[string]*NICQueueInfo `json:"interfaces"`
+}
+
+// NICQueueInfo is the queue layout and interrupt placement of one interface.
+// RPS/XPS masks are hex CPU masks as in sysfs, listed only when set.
+type NICQueueInfo struct {
+	RxQueues int               `json:"rx_queues"`
+	TxQueues int               `json:"tx_queues"`
+	RPSCPUs  map[string]string `json:"rps_cpus,omitempty"` // rx-N -> CPUs that steer its packets
+	XPSCPUs  map[string]string `json:"xps_cpus,omitempty"` // tx-N -> CPUs that send on it
+	IRQs     []NICInterrupt    `json:"irqs,omitempty"`
+}
+
+// NICInterrupt is one interrupt of an interface, usually one queue's
+type NICInterrupt struct {
+	IRQ      int    `json:"irq"`
+	Name     string `json:"name"`
+	Affinity string `json:"affinity,omitempty"` // CPU list from smp_affinity_list, e.g. "0-3"
+	Count    int64  `json:"count"`              // Interrupts taken, summed over CPUs
+}
+
+// irqCommand prints /proc/interrupts, then the interface queues, their RPS
+// and XPS masks, each interface's device and MSI interrupts, and the
+// affinity of every IRQ, in sections. Missing files are skipped.
+const irqCommand = `echo '== interrupts'; cat /proc/interrupts
+echo '== queues'; ls -d /sys/class/net/*/queues/* 2>/dev/null
+echo '== queue_cpus'; grep -s -H . /sys/class/net/*/queues/*/rps_cpus /sys/class/net/*/queues/*/xps_cpus
+echo '== devices'; for d in /sys/class/net/*/device; do [ -e "$d" ] && echo "$d $(readlink "$d")"; done
+echo '== msi_irqs'; ls -d /sys/class/net/*/device/msi_irqs/* 2>/dev/null
+echo '== affinity'; grep -s -H . /proc/irq/*/smp_affinity_list
+true`
+
+// IRQModule collects the RX/TX queues of each network interface, the CPU
+// affinity of its interrupts, and its RPS/XPS masks
+type IRQModule struct{}
+
+// NewIRQModule creates a new IRQ affinity module
+func NewIRQModule() *IRQModule {
+	return &IRQModule{}
+}
+
+// Name returns the module name
+func (m *IRQModule) Name() string {
+	return "irq"
+}
+
+// Description returns the module description
+func (m *IRQModule) Description() string {
+	return "Collects NIC RX/TX queue counts, interrupt CPU affinity, and RPS/XPS masks per interface"
+}
+
+// IsAvailable checks if the module can run, which needs Linux's /proc/interrupts
+func (m *IRQModule) IsAvailable(ctx context.Context, executor CommandExecutor) bool {
+	_, err := executor.Execute(ctx, "test -r /proc/interrupts")
+	return err == nil
+}
+
+// Collect gathers the queue and interrupt layout of every interface in one
+// command
+func (m *IRQModule) Collect(ctx context.Context, executor CommandExecutor) (interface{}, error) {
+	output, err := executor.Execute(ctx, irqCommand)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read interrupts: %w", err)
+	}
+	return parseIRQOutput(output), nil
+}
+
+// procInterrupt is one line of /proc/interrupts
+type procInterrupt struct {
+	irq   int
+	name  string
+	count int64
+}
+
+// parseIRQOutput builds the per-interface layout from irqCommand's output.
+// An interrupt belongs to an interface when it is one of the interface's
+// MSI interrupts, or its name contains the interface name (eth0-TxRx-0) or
+// starts with the interface's device name (virtio0-input.0).
+// Interfaces without interrupts, such as lo or veth, are left out.
+func parseIRQOutput(output string) *IRQInfo {
+	info := &IRQInfo{Interfaces: make(map[string]*NICQueueInfo)}
+	var interrupts []procInterrupt
+	queues := make(map[string]*NICQueueInfo)
+	devices := make(map[string]string)
+	msi := make(map[string]map[int]bool)
+	affinity := make(map[int]string)
+
+	queueInfo := func(iface string) *NICQueueInfo {
+		if queues[iface] == nil {
+			queues[iface] = &NICQueueInfo{}
+		}
+		return queues[iface]
+	}
+
+	section := ""
+	cpus := 0
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "== ") {
+			section = strings.TrimPrefix(line, "== ")
+			continue
+		}
+		if line == "" {
+			continue
+		}
+
+		switch section {
+		case "interrupts":
+			fields := strings.Fields(line)
+			if cpus == 0 {
+				// The header names one column per CPU
+				cpus = len(fields)
+				continue
+			}
+			if interrupt, ok := parseProcInterrupt(fields, cpus); ok {
+				interrupts = append(interrupts, interrupt)
+			}
+		case "queues":
+			iface, queue, ok := netSysfsQueue(line)
+			if !ok {
+				continue
+			}
+			q := queueInfo(iface)
+			if strings.HasPrefix(queue, "rx-") {
+				q.RxQueues++
+			} else if strings.HasPrefix(queue, "tx-") {
+				q.TxQueues++
+			}
+		case "queue_cpus":
+			file, mask, found := strings.Cut(line, ":")
+			iface, queue, ok := netSysfsQueue(path.Dir(file))
+			if !found || !ok || strings.Trim(mask, "0,") == "" {
+				continue
+			}
+			q := queueInfo(iface)
+			if path.Base(file) == "rps_cpus" {
+				if q.RPSCPUs == nil {
+					q.RPSCPUs = make(map[string]string)
+				}
+				q.RPSCPUs[queue] = mask
+			} else {
+				if q.XPSCPUs == nil {
+					q.XPSCPUs = make(map[string]string)
+				}
+				q.XPSCPUs[queue] = mask
+			}
+		case "devices":
+			link, target, found := strings.Cut(line, " ")
+			if found {
+				devices[netSysfsInterface(link)] = path.Base(target)
+			}
+		case "msi_irqs":
+			irq, err := strconv.Atoi(path.Base(line))
+			if err != nil {
+				continue
+			}
+			iface := netSysfsInterface(line)
+			if msi[iface] == nil {
+				msi[iface] = make(map[int]bool)
+			}
+			msi[iface][irq] = true
+		case "affinity":
+			file, cpuList, found := strings.Cut(line, ":")
+			if irq, err := strconv.Atoi(path.Base(path.Dir(file))); found && err == nil {
+				affinity[irq] = cpuList
+			}
+		}
+	}
+
+	ifaces := make([]string, 0, len(queues))
+	for iface := range queues {
+		ifaces = append(ifaces, iface)
+	}
+	sort.Strings(ifaces)
+	for _, iface := range ifaces {
+		q := queues[iface]
+		for _, interrupt := range interrupts {
+			if msi[iface][interrupt.irq] || irqNamesInterface(interrupt.name, iface, devices[iface]) {
+				q.IRQs = append(q.IRQs, NICInterrupt{
+					IRQ:      interrupt.irq,
+					Name:     interrupt.name,
+					Affinity: affinity[interrupt.irq],
+					Count:    interrupt.count,
+				})
+			}
+		}
+		if len(q.IRQs) > 0 {
+			info.Interfaces[iface] = q
+		}
+	}
+	return info
+}
+
+// parseProcInterrupt parses the fields of a numbered /proc/interrupts line:
+// the IRQ, one count per CPU, then the chip, hardware IRQ, and name columns,
+// whose last field is the name. Lines such as NMI or LOC are skipped.
+func parseProcInterrupt(fields []string, cpus int) (procInterrupt, bool) {
+	if len(fields) < cpus+2 {
+		return procInterrupt{}, false
+	}
+	irq, err := strconv.Atoi(strings.TrimSuffix(fields[0], ":"))
+	if err != nil {
+		return procInterrupt{}, false
+	}
+	interrupt := procInterrupt{irq: irq, name: fields[len(fields)-1]}
+	for _, field := range fields[1 : cpus+1] {
+		count, err := strconv.ParseInt(field, 10, 64)
+		if err != nil {
+			return procInterrupt{}, false
+		}
+		interrupt.count += count
+	}
+	return interrupt, true
+}
+
+// irqNamesInterface reports whether an interrupt name refers to iface, as a
+// dash-separated token (eth0-TxRx-0, i40e-eth0-TxRx-0), or to its device
+// (virtio0-input.0)
+func irqNamesInterface(name, iface, device string) bool {
+	if device != "" && strings.HasPrefix(name, device+"-") {
+		return true
+	}
+	for _, token := range strings.FieldsFunc(name, func(r rune) bool { return r == '-' || r == '@' }) {
+		if token == iface {
+			return true
+		}
+	}
+	return false
+}
+
+// netSysfsQueue splits a path such as /sys/class/net/eth0/queues/rx-0 into
+// the interface and queue names
+func netSysfsQueue(queuePath string) (string, string, bool) {
+	dir, queue := path.Split(queuePath)
+	if path.Base(dir) != "queues" {
+		return "", "", false
+	}
+	return path.Base(path.Dir(path.Clean(dir))), queue, true
+}
+
+// netSysfsInterface returns the interface of a path under /sys/class/net
+func netSysfsInterface(sysfsPath string) string {
+	rest := strings.TrimPrefix(sysfsPath, "/sys/class/net/")
+	iface, _, _ := strings.Cut(rest, "/")
+	return iface
+}
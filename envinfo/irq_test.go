@@ -0,0 +1,106 @@
+package envinfo
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+const irqSample = `== interrupts
+            CPU0       CPU1       CPU2       CPU3
+   0:         44          0          0          0   IO-APIC   2-edge      timer
+  45:     102400          0          0         12   IR-PCI-MSI 1572864-edge      mlx5_async0@pci:0000:03:00.0
+  46:          0     500000          0          0   IR-PCI-MSI 1572865-edge      mlx5_comp0@pci:0000:03:00.0
+  47:          0          0     400000          0   IR-PCI-MSI 1572866-edge      mlx5_comp1@pci:0000:03:00.0
+  50:       2000       3000          0          0   IR-PCI-MSI 524288-edge      i40e-eth2-TxRx-0
+  60:          7          0          0          0   PCI-MSI 49153-edge      virtio0-input.0
+  61:          0          9          0          0   PCI-MSI 49154-edge      virtio0-output.0
+ NMI:          0          0          0          0   Non-maskable interrupts
+== queues
+/sys/class/net/ens1f0/queues/rx-0
+/sys/class/net/ens1f0/queues/rx-1
+/sys/class/net/ens1f0/queues/tx-0
+/sys/class/net/ens1f0/queues/tx-1
+/sys/class/net/eth2/queues/rx-0
+/sys/class/net/eth2/queues/tx-0
+/sys/class/net/eth0/queues/rx-0
+/sys/class/net/eth0/queues/tx-0
+/sys/class/net/lo/queues/rx-0
+/sys/class/net/lo/queues/tx-0
+== queue_cpus
+/sys/class/net/ens1f0/queues/rx-0/rps_cpus:0
+/sys/class/net/ens1f0/queues/rx-1/rps_cpus:c
+/sys/class/net/ens1f0/queues/tx-0/xps_cpus:00000000,00000001
+/sys/class/net/ens1f0/queues/tx-1/xps_cpus:00000000,00000002
+== devices
+/sys/class/net/ens1f0/device ../../../0000:03:00.0
+/sys/class/net/eth2/device ../../../0000:5e:00.0
+/sys/class/net/eth0/device ../../../virtio0
+== msi_irqs
+/sys/class/net/ens1f0/device/msi_irqs/45
+/sys/class/net/ens1f0/device/msi_irqs/46
+/sys/class/net/ens1f0/device/msi_irqs/47
+== affinity
+/proc/irq/45/smp_affinity_list:0-3
+/proc/irq/46/smp_affinity_list:1
+/proc/irq/47/smp_affinity_list:2
+/proc/irq/50/smp_affinity_list:0-1
+/proc/irq/60/smp_affinity_list:0
+`
+
+func TestParseIRQOutput(t *testing.T) {
+	info := parseIRQOutput(irqSample)
+
+	want := &IRQInfo{Interfaces: map[string]*NICQueueInfo{
+		"ens1f0": {
+			RxQueues: 2,
+			TxQueues: 2,
+			RPSCPUs:  map[string]string{"rx-1": "c"},
+			XPSCPUs:  map[string]string{"tx-0": "00000000,00000001", "tx-1": "00000000,00000002"},
+			IRQs: []NICInterrupt{
+				{IRQ: 45, Name: "mlx5_async0@pci:0000:03:00.0", Affinity: "0-3", Count: 102412},
+				{IRQ: 46, Name: "mlx5_comp0@pci:0000:03:00.0", Affinity: "1", Count: 500000},
+				{IRQ: 47, Name: "mlx5_comp1@pci:0000:03:00.0", Affinity: "2", Count: 400000},
+			},
+		},
+		"eth2": {
+			RxQueues: 1,
+			TxQueues: 1,
+			IRQs:     []NICInterrupt{{IRQ: 50, Name: "i40e-eth2-TxRx-0", Affinity: "0-1", Count: 5000}},
+		},
+		"eth0": {
+			RxQueues: 1,
+			TxQueues: 1,
+			IRQs: []NICInterrupt{
+				{IRQ: 60, Name: "virtio0-input.0", Affinity: "0", Count: 7},
+				{IRQ: 61, Name: "virtio0-output.0", Count: 9},
+			},
+		},
+	}}
+	for iface, queues := range want.Interfaces {
+		if got := info.Interfaces[iface]; !reflect.DeepEqual(got, queues) {
+			t.Errorf("%s = %+v, want %+v", iface, got, queues)
+		}
+	}
+	if _, exists := info.Interfaces["lo"]; exists || len(info.Interfaces) != len(want.Interfaces) {
+		t.Errorf("Expected only interfaces with interrupts, got %v", info.Interfaces)
+	}
+}
+
+func TestIRQModule_Collect(t *testing.T) {
+	executor := scriptedExecutor{"test -r /proc/interrupts": "", irqCommand: irqSample}
+	module := NewIRQModule()
+	if !module.IsAvailable(context.Background(), executor) {
+		t.Fatal("Expected the module to be available with /proc/interrupts")
+	}
+	data, err := module.Collect(context.Background(), executor)
+	if err != nil {
+		t.Fatalf("Collect returned error: %v", err)
+	}
+	if info := data.(*IRQInfo); info.Interfaces["ens1f0"].RxQueues != 2 {
+		t.Errorf("Unexpected collected data: %+v", info.Interfaces["ens1f0"])
+	}
+	if module.IsAvailable(context.Background(), scriptedExecutor{}) {
+		t.Error("Expected the module to be unavailable without /proc/interrupts")
+	}
+}
@@ -0,0 +1,99 @@
+package envinfo
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// uptimeLoadRegex matches the load averages of uptime, e.g.
+// "load average: 0.52, 0.58, 0.59" (Linux) or "load averages: 1.90 2.01 2.12"
+var uptimeLoadRegex = regexp.MustCompile(`load averages?:\s*([\d.]+),?\s+([\d.]+),?\s+([\d.]+)`)
+
+// LoadInfo is a host's load averages and boot time, context for runs that
+// shared the host with other work
+type LoadInfo struct {
+	Load1    float64 `json:"load_1m"`
+	Load5    float64 `json:"load_5m"`
+	Load15   float64 `json:"load_15m"`
+	CPUs     int     `json:"cpus,omitempty"`
+	BootTime string  `json:"boot_time,omitempty"`
+}
+
+// Overloaded reports whether the 1-minute load exceeds the core count,
+// meaning runnable work already queues for CPUs
+func (l *LoadInfo) Overloaded() bool {
+	return l.CPUs > 0 && l.Load1 > float64(l.CPUs)
+}
+
+// LoadModule collects load averages and boot time
+type LoadModule struct{}
+
+// NewLoadModule creates a new load information module
+func NewLoadModule() *LoadModule {
+	return &LoadModule{}
+}
+
+// Name returns the module name
+func (m *LoadModule) Name() string {
+	return "load"
+}
+
+// Description returns the module description
+func (m *LoadModule) Description() string {
+	return "Collects load averages, core count, and boot time"
+}
+
+// IsAvailable checks if the module can run
+func (m *LoadModule) IsAvailable(ctx context.Context, executor CommandExecutor) bool {
+	_, err := executor.Execute(ctx, "command -v uptime")
+	return err == nil
+}
+
+// Collect gathers load information
+func (m *LoadModule) Collect(ctx context.Context, executor CommandExecutor) (interface{}, error) {
+	output, err := executor.Execute(ctx, "uptime")
+	if err != nil {
+		return nil, fmt.Errorf("failed to run uptime: %w", err)
+	}
+	info, err := ParseUptime(output)
+	if err != nil {
+		return nil, err
+	}
+
+	// Core count and boot time are best effort; uptime -s needs procps
+	if output, err := executor.Execute(ctx, "nproc"); err == nil {
+		info.CPUs, _ = strconv.Atoi(strings.TrimSpace(output))
+	}
+	if output, err := executor.Execute(ctx, "uptime -s 2>/dev/null"); err == nil {
+		info.BootTime = strings.TrimSpace(output)
+	}
+	return info, nil
+}
+
+// ParseUptime extracts the load averages from an uptime line
+func ParseUptime(output string) (*LoadInfo, error) {
+	matches := uptimeLoadRegex.FindStringSubmatch(output)
+	if matches == nil {
+		return nil, fmt.Errorf("no load averages in uptime output %q", strings.TrimSpace(output))
+	}
+
+	var loads [3]float64
+	for i, value := range matches[1:] {
+		load, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid load average %q: %w", value, err)
+		}
+		loads[i] = load
+	}
+	return &LoadInfo{Load1: loads[0], Load5: loads[1], Load15: loads[2]}, nil
+}
+
+// Auto-register this module
+func init() {
+	RegisterModule("load", func() Module {
+		return NewLoadModule()
+	})
+}
@@ -0,0 +1,40 @@
+package envinfo
+
+import "testing"
+
+func TestParseUptime(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   LoadInfo
+	}{
+		{"linux", " 10:14:03 up 12 days,  3:02,  2 users,  load average: 0.52, 0.58, 0.59\n", LoadInfo{Load1: 0.52, Load5: 0.58, Load15: 0.59}},
+		{"busybox", "10:14:03 up 1 min,  load average: 12.00, 6.10, 2.31", LoadInfo{Load1: 12, Load5: 6.1, Load15: 2.31}},
+		{"bsd", "10:14  up 3 days, 21:09, 2 users, load averages: 1.90 2.01 2.12", LoadInfo{Load1: 1.9, Load5: 2.01, Load15: 2.12}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info, err := ParseUptime(tt.output)
+			if err != nil {
+				t.Fatalf("ParseUptime returned error: %v", err)
+			}
+			if *info != tt.want {
+				t.Errorf("ParseUptime() = %+v, want %+v", *info, tt.want)
+			}
+		})
+	}
+
+	if _, err := ParseUptime("uptime: command not found"); err == nil {
+		t.Error("Expected an error without load averages")
+	}
+}
+
+func TestLoadInfo_Overloaded(t *testing.T) {
+	if !(&LoadInfo{Load1: 9, CPUs: 8}).Overloaded() {
+		t.Error("Expected a load of 9 on 8 cores to be overloaded")
+	}
+	if (&LoadInfo{Load1: 7.5, CPUs: 8}).Overloaded() || (&LoadInfo{Load1: 9}).Overloaded() {
+		t.Error("Expected no overload below the core count or without one")
+	}
+}
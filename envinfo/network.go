@@ -4,6 +4,9 @@ import (
 	"context"
 	"fmt"
 	"net"
+	"os"
+	"path"
+	"path/filepath"
 	"strconv"
 	"strings"
 )
@@ -17,6 +20,7 @@ type NetworkInterface struct {
 	IsUp         bool     `json:"is_up"`
 	Speed        string   `json:"speed,omitempty"`
 	Driver       string   `json:"driver,omitempty"`
+	PCIAddress   string   `json:"pci_address,omitempty"` // Backing device, e.g. 0000:3b:00.0
 }
 
 // NetworkInfo represents all network information
@@ -52,8 +56,10 @@ func (m *NetworkModule) IsAvailable(ctx context.Context, executor CommandExecuto
 func (m *NetworkModule) Collect(ctx context.Context, executor CommandExecutor) (interface{}, error) {
 	info := &NetworkInfo{}
 
-	// Try to collect using local Go net package first (more reliable)
-	if localInterfaces, err := m.collectLocalInterfaces(); err == nil && len(localInterfaces) > 0 {
+	// Use the local Go net package when inspecting this machine (more reliable);
+	// remote hosts must be queried through the executor
+	_, isLocal := executor.(*LocalExecutor)
+	if localInterfaces, err := m.collectLocalInterfaces(); isLocal && err == nil && len(localInterfaces) > 0 {
 		info.Interfaces = localInterfaces
 	} else {
 		// Fallback to remote/command-based collection
@@ -80,6 +86,9 @@ func (m *NetworkModule) collectLocalInterfaces() ([]NetworkInterface, error) {
 			MTU:        iface.MTU,
 			IsUp:       iface.Flags&net.FlagUp != 0,
 		}
+		if device, err := os.Readlink(filepath.Join("/sys/class/net", iface.Name, "device")); err == nil {
+			netInterface.PCIAddress = filepath.Base(device)
+		}
 
 		// Get IP addresses
 		addrs, err := iface.Addrs()
@@ -137,7 +146,7 @@ func (m *NetworkModule) collectRemoteInterfaces(ctx context.Context, executor Co
 
 		// Check if interface is up
 		if stateOutput, err := executor.Execute(ctx, fmt.Sprintf("cat /sys/class/net/%s/operstate", ifaceName)); err == nil {
-			netInterface.IsUp = strings.TrimSpace(stateOutput) == "up"
+			netInterface.IsUp = operstateUp(stateOutput)
 		}
 
 		// Try to get speed (may not be available for all interfaces)
@@ -153,11 +162,87 @@ func (m *NetworkModule) collectRemoteInterfaces(ctx context.Context, executor Co
 			}
 		}
 
+		// The device link names the PCI address, e.g. ../../../0000:3b:00.0
+		if deviceOutput, err := executor.Execute(ctx, fmt.Sprintf("readlink /sys/class/net/%s/device 2>/dev/null", ifaceName)); err == nil {
+			if device := strings.TrimSpace(deviceOutput); device != "" {
+				netInterface.PCIAddress = path.Base(device)
+			}
+		}
+
 		result = append(result, netInterface)
 	}
 
 	return result, nil
 }
+// AddressInSubnet returns the first interface address inside subnet, without
+// its prefix length
+func (info *NetworkInfo) AddressInSubnet(subnet *net.IPNet) (string, bool) {
+	for _, iface := range info.Interfaces {
+		if ip, found := iface.addressInSubnet(subnet); found {
+			return ip.String(), true
+		}
+	}
+	return "", false
+}
+
+// InterfacesInSubnet returns the interfaces with an address inside subnet
+func (info *NetworkInfo) InterfacesInSubnet(subnet *net.IPNet) []NetworkInterface {
+	var matched []NetworkInterface
+	for _, iface := range info.Interfaces {
+		if _, found := iface.addressInSubnet(subnet); found {
+			matched = append(matched, iface)
+		}
+	}
+	return matched
+}
+
+// InterfacesWithAddress returns the interfaces holding the IP address
+func (info *NetworkInfo) InterfacesWithAddress(address string) []NetworkInterface {
+	ip := net.ParseIP(address)
+	if ip == nil {
+		return nil
+	}
+	var matched []NetworkInterface
+	for _, iface := range info.Interfaces {
+		for _, addr := range iface.IPAddresses {
+			ifaceIP := net.ParseIP(addr)
+			if ifaceIP == nil {
+				ifaceIP, _, _ = net.ParseCIDR(addr)
+			}
+			if ip.Equal(ifaceIP) {
+				matched = append(matched, iface)
+				break
+			}
+		}
+	}
+	return matched
+}
+
+// addressInSubnet returns the interface's first address inside subnet
+func (iface *NetworkInterface) addressInSubnet(subnet *net.IPNet) (net.IP, bool) {
+	for _, addr := range iface.IPAddresses {
+		ip := net.ParseIP(addr)
+		if ip == nil {
+			ip, _, _ = net.ParseCIDR(addr)
+		}
+		if ip != nil && subnet.Contains(ip) {
+			return ip, true
+		}
+	}
+	return nil, false
+}
+
+// operstateUp reports whether an operstate lets the link carry traffic.
+// Only down and lowerlayerdown rule it out: many virtual NICs and some
+// drivers always report unknown.
+func operstateUp(operstate string) bool {
+	switch strings.TrimSpace(operstate) {
+	case "down", "lowerlayerdown":
+		return false
+	}
+	return true
+}
+
 // Auto-register this module
 func init() {
 	RegisterModule("network", func() Module {
@@ -0,0 +1,84 @@
+package envinfo
+
+import (
+	"net"
+	"testing"
+)
+
+func TestNetworkInfo_AddressInSubnet(t *testing.T) {
+	info := &NetworkInfo{
+		Interfaces: []NetworkInterface{
+			{Name: "lo", IPAddresses: []string{"127.0.0.1/8", "::1/128"}},
+			{Name: "eth0", IPAddresses: []string{"192.168.1.100/24"}},
+			{Name: "ib0", IPAddresses: []string{"10.0.0.1/24", "fd00::1/64"}},
+		},
+	}
+
+	tests := []struct {
+		subnet string
+		want   string
+		found  bool
+	}{
+		{"10.0.0.0/24", "10.0.0.1", true},
+		{"192.168.0.0/16", "192.168.1.100", true},
+		{"fd00::/64", "fd00::1", true},
+		{"172.16.0.0/12", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.subnet, func(t *testing.T) {
+			_, subnet, err := net.ParseCIDR(tt.subnet)
+			if err != nil {
+				t.Fatalf("bad test subnet: %v", err)
+			}
+			got, found := info.AddressInSubnet(subnet)
+			if got != tt.want || found != tt.found {
+				t.Errorf("AddressInSubnet(%s) = %q, %v; want %q, %v", tt.subnet, got, found, tt.want, tt.found)
+			}
+		})
+	}
+}
+
+func TestNetworkInfo_InterfacesWithAddress(t *testing.T) {
+	info := &NetworkInfo{
+		Interfaces: []NetworkInterface{
+			{Name: "eth0", IPAddresses: []string{"192.168.1.100/24"}},
+			{Name: "ib0", IPAddresses: []string{"10.0.0.1/24", "fd00::1/64"}},
+		},
+	}
+
+	tests := []struct {
+		address string
+		want    string
+	}{
+		{"10.0.0.1", "ib0"},
+		{"fd00::1", "ib0"},
+		{"192.168.1.100", "eth0"},
+		{"10.0.0.2", ""},
+		{"not-an-ip", ""},
+	}
+
+	for _, tt := range tests {
+		var got string
+		if matched := info.InterfacesWithAddress(tt.address); len(matched) == 1 {
+			got = matched[0].Name
+		}
+		if got != tt.want {
+			t.Errorf("InterfacesWithAddress(%s) = %q, want %q", tt.address, got, tt.want)
+		}
+	}
+}
+
+func TestOperstateUp(t *testing.T) {
+	for state, want := range map[string]bool{
+		"up\n":             true,
+		"unknown\n":        true,
+		"dormant\n":        true,
+		"down\n":           false,
+		"lowerlayerdown\n": false,
+	} {
+		if got := operstateUp(state); got != want {
+			t.Errorf("operstateUp(%q) = %v, want %v", state, got, want)
+		}
+	}
+}
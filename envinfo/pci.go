@@ -0,0 +1,237 @@
+package envinfo
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// PCIInfo represents PCI device information
+type PCIInfo struct {
+	Devices []PCIDevice `json:"devices"`
+}
+
+// PCIDevice represents a single PCI device. The address is the full
+// domain:bus:device.function (BDF), as used in DPDK's allow_pci.
+type PCIDevice struct {
+	Address     string         `json:"address"`
+	Class       string         `json:"class"`
+	ClassID     string         `json:"class_id,omitempty"`
+	VendorID    string         `json:"vendor_id,omitempty"`
+	DeviceID    string         `json:"device_id,omitempty"`
+	Description string         `json:"description"`
+	Driver      string         `json:"driver,omitempty"` // Kernel driver the device is bound to, e.g. vfio-pci
+	Link        *PCIeLinkState `json:"link,omitempty"`
+	NUMANode    *int           `json:"numa_node,omitempty"` // Only on multi-node hosts
+}
+
+// PCIeLinkState compares the negotiated PCIe link against what the device supports
+type PCIeLinkState struct {
+	CapableSpeed    string `json:"capable_speed"`
+	CapableWidth    string `json:"capable_width"`
+	NegotiatedSpeed string `json:"negotiated_speed"`
+	NegotiatedWidth string `json:"negotiated_width"`
+	Downgraded      bool   `json:"downgraded"`
+}
+
+// linkClasses are the PCI device classes whose link state affects test results
+var linkClasses = []string{
+	"Ethernet controller",
+	"Network controller",
+	"Infiniband controller",
+	"VGA compatible controller",
+	"3D controller",
+	"Display controller",
+}
+
+var (
+	// With -nn the class is followed by its ID, e.g. "Ethernet controller [0200]"
+	pciHeaderRegex = regexp.MustCompile(`^([0-9a-fA-F:.]+)\s+([^:\[]+?)(?:\s+\[([0-9a-fA-F]{4})\])?:\s*(.*)$`)
+	// The last bracketed pair of a -nn description is vendor:device
+	pciIDsRegex    = regexp.MustCompile(`\s*\[([0-9a-fA-F]{4}):([0-9a-fA-F]{4})\]`)
+	linkSpeedRegex = regexp.MustCompile(`Speed\s+([0-9.]+GT/s)`)
+	linkWidthRegex = regexp.MustCompile(`Width\s+(x\d+)`)
+)
+
+// PCIModule collects PCI device information
+type PCIModule struct{}
+
+// NewPCIModule creates a new PCI information module
+func NewPCIModule() *PCIModule {
+	return &PCIModule{}
+}
+
+// Name returns the module name
+func (m *PCIModule) Name() string {
+	return "pci"
+}
+
+// Description returns the module description
+func (m *PCIModule) Description() string {
+	return "Collects PCI addresses, IDs, drivers, and PCIe link width/speed for network, RDMA, and GPU devices"
+}
+
+// IsAvailable checks if the module can run
+func (m *PCIModule) IsAvailable(ctx context.Context, executor CommandExecutor) bool {
+	_, err := executor.Execute(ctx, "command -v lspci")
+	return err == nil
+}
+
+// Collect gathers PCI device information
+func (m *PCIModule) Collect(ctx context.Context, executor CommandExecutor) (interface{}, error) {
+	// Link capabilities are only visible with -vv (and usually root); -D
+	// adds the PCI domain, -nn the numeric IDs, and -k the bound driver
+	output, err := executor.Execute(ctx, "lspci -Dnnvvk 2>/dev/null")
+	if err != nil {
+		return nil, fmt.Errorf("failed to run lspci: %w", err)
+	}
+
+	return &PCIInfo{Devices: parseLspciVerbose(output)}, nil
+}
+
+// parseLspciVerbose extracts network/RDMA/GPU devices and their link state
+// from `lspci -vv` output. The IDs and driver are filled in when the output
+// also has -nn and -k.
+func parseLspciVerbose(output string) []PCIDevice {
+	var devices []PCIDevice
+	var current *PCIDevice
+	var link PCIeLinkState
+	hasLink := false
+
+	flush := func() {
+		if current == nil {
+			return
+		}
+		if hasLink {
+			state := link
+			state.Downgraded = state.NegotiatedSpeed != state.CapableSpeed || state.NegotiatedWidth != state.CapableWidth
+			current.Link = &state
+		}
+		devices = append(devices, *current)
+		current = nil
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		// Device header lines start in column zero
+		if line != "" && line[0] != ' ' && line[0] != '\t' {
+			flush()
+			matches := pciHeaderRegex.FindStringSubmatch(line)
+			if len(matches) < 5 || !isLinkClass(matches[2]) {
+				continue
+			}
+			current = &PCIDevice{
+				Address:     matches[1],
+				Class:       matches[2],
+				ClassID:     strings.ToLower(matches[3]),
+				Description: strings.TrimSpace(matches[4]),
+			}
+			if ids := pciIDsRegex.FindAllStringSubmatchIndex(current.Description, -1); len(ids) > 0 {
+				last := ids[len(ids)-1]
+				current.VendorID = strings.ToLower(current.Description[last[2]:last[3]])
+				current.DeviceID = strings.ToLower(current.Description[last[4]:last[5]])
+				current.Description = strings.TrimSpace(current.Description[:last[0]] + current.Description[last[1]:])
+			}
+			link = PCIeLinkState{}
+			hasLink = false
+			continue
+		}
+
+		if current == nil {
+			continue
+		}
+
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(trimmed, "LnkCap:"):
+			link.CapableSpeed = firstSubmatch(linkSpeedRegex, trimmed)
+			link.CapableWidth = firstSubmatch(linkWidthRegex, trimmed)
+			hasLink = true
+		case strings.HasPrefix(trimmed, "LnkSta:"):
+			link.NegotiatedSpeed = firstSubmatch(linkSpeedRegex, trimmed)
+			link.NegotiatedWidth = firstSubmatch(linkWidthRegex, trimmed)
+			hasLink = true
+		case strings.HasPrefix(trimmed, "Kernel driver in use:"):
+			current.Driver = strings.TrimSpace(strings.TrimPrefix(trimmed, "Kernel driver in use:"))
+		case strings.HasPrefix(trimmed, "NUMA node:"):
+			if node, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(trimmed, "NUMA node:"))); err == nil {
+				current.NUMANode = &node
+			}
+		}
+	}
+	flush()
+
+	return devices
+}
+
+// isLinkClass reports whether the device class is one whose link we track
+func isLinkClass(class string) bool {
+	for _, c := range linkClasses {
+		if class == c {
+			return true
+		}
+	}
+	return false
+}
+
+// firstSubmatch returns the first capture group of re in s, or an empty string
+func firstSubmatch(re *regexp.Regexp, s string) string {
+	if matches := re.FindStringSubmatch(s); len(matches) > 1 {
+		return matches[1]
+	}
+	return ""
+}
+
+// nicClasses are the PCI device classes of network adapters
+var nicClasses = map[string]bool{
+	"Ethernet controller":   true,
+	"Network controller":    true,
+	"Infiniband controller": true,
+}
+
+// NICNumaNodes returns the network adapters among addresses on each NUMA
+// node. Adapters without a reported node are left out.
+func (info *PCIInfo) NICNumaNodes(addresses []string) map[int][]string {
+	wanted := make(map[string]bool)
+	for _, address := range addresses {
+		wanted[address] = true
+	}
+	nodes := make(map[int][]string)
+	for _, device := range info.Devices {
+		if wanted[device.Address] && nicClasses[device.Class] && device.NUMANode != nil {
+			nodes[*device.NUMANode] = append(nodes[*device.NUMANode], device.Address)
+		}
+	}
+	for _, addresses := range nodes {
+		sort.Strings(addresses)
+	}
+	return nodes
+}
+
+// LinkWarnings returns a warning for each device whose link trained below its capability
+func (info *PCIInfo) LinkWarnings() []string {
+	var warnings []string
+	for _, device := range info.Devices {
+		if device.Link == nil || !device.Link.Downgraded {
+			continue
+		}
+		warnings = append(warnings, fmt.Sprintf("PCIe link for %s (%s) is downgraded: %s %s negotiated, %s %s capable",
+			device.Address, device.Description,
+			device.Link.NegotiatedSpeed, device.Link.NegotiatedWidth,
+			device.Link.CapableSpeed, device.Link.CapableWidth))
+	}
+	return warnings
+}
+
+// Auto-register this module
+func init() {
+	RegisterModule("pci", func() Module {
+		return NewPCIModule()
+	})
+}
@@ -0,0 +1,99 @@
+package envinfo
+
+import (
+	"strings"
+	"testing"
+)
+
+const lspciVerboseSample = `00:00.0 Host bridge: Intel Corporation Sky Lake-E DMI3 Registers (rev 07)
+	Subsystem: Intel Corporation Device 0000
+	Control: I/O- Mem- BusMaster- SpecCycle- MemWINV- VGASnoop- ParErr- Stepping- SERR- FastB2B- DisINTx-
+
+3b:00.0 Ethernet controller: Mellanox Technologies MT28800 Family [ConnectX-5 Ex]
+	Subsystem: Mellanox Technologies Device 0008
+	Capabilities: [60] Express (v2) Endpoint, MSI 00
+		DevCap:	MaxPayload 512 bytes, PhantFunc 0, Latency L0s unlimited, L1 unlimited
+		LnkCap:	Port #0, Speed 16GT/s, Width x16, ASPM not supported
+			ClockPM- Surprise- LLActRep- BwNot- ASPMOptComp+
+		LnkSta:	Speed 8GT/s (downgraded), Width x8 (downgraded)
+			TrErr- Train- SlotClk+ DLActive- BWMgmt- ABWMgmt-
+	NUMA node: 0
+
+af:00.0 3D controller: NVIDIA Corporation GA100 [A100 PCIe 40GB] (rev a1)
+	Capabilities: [68] Express (v2) Endpoint, MSI 00
+		LnkCap:	Port #0, Speed 16GT/s, Width x16, ASPM not supported
+		LnkSta:	Speed 16GT/s, Width x16
+`
+
+func TestParseLspciVerbose(t *testing.T) {
+	devices := parseLspciVerbose(lspciVerboseSample)
+
+	if len(devices) != 2 {
+		t.Fatalf("Expected 2 network/GPU devices (host bridge excluded), got %d: %+v", len(devices), devices)
+	}
+
+	nic := devices[0]
+	if nic.Address != "3b:00.0" || nic.Class != "Ethernet controller" {
+		t.Errorf("Unexpected NIC identity: %+v", nic)
+	}
+	if nic.Link == nil {
+		t.Fatal("Expected NIC link state to be parsed")
+	}
+	if nic.Link.CapableSpeed != "16GT/s" || nic.Link.CapableWidth != "x16" {
+		t.Errorf("Unexpected capable link: %+v", nic.Link)
+	}
+	if nic.Link.NegotiatedSpeed != "8GT/s" || nic.Link.NegotiatedWidth != "x8" {
+		t.Errorf("Unexpected negotiated link: %+v", nic.Link)
+	}
+	if !nic.Link.Downgraded {
+		t.Error("Expected NIC link to be marked downgraded")
+	}
+	if nic.NUMANode == nil || *nic.NUMANode != 0 {
+		t.Errorf("Expected NIC on NUMA node 0, got %v", nic.NUMANode)
+	}
+
+	gpu := devices[1]
+	if gpu.Class != "3D controller" || gpu.Link == nil || gpu.Link.Downgraded {
+		t.Errorf("Expected healthy GPU link, got %+v (link %+v)", gpu, gpu.Link)
+	}
+}
+
+func TestPCIInfo_LinkWarnings(t *testing.T) {
+	info := &PCIInfo{Devices: parseLspciVerbose(lspciVerboseSample)}
+
+	warnings := info.LinkWarnings()
+	if len(warnings) != 1 {
+		t.Fatalf("Expected 1 warning, got %d: %v", len(warnings), warnings)
+	}
+	if !strings.Contains(warnings[0], "3b:00.0") || !strings.Contains(warnings[0], "8GT/s x8") {
+		t.Errorf("Warning does not describe the downgraded link: %s", warnings[0])
+	}
+}
+
+const lspciNumericSample = `0000:3b:00.0 Ethernet controller [0200]: Mellanox Technologies MT28800 Family [ConnectX-5 Ex] [15b3:1019]
+	Subsystem: Mellanox Technologies Device [15b3:0008]
+	Kernel driver in use: vfio-pci
+	Kernel modules: mlx5_core
+
+0000:af:00.0 3D controller [0302]: NVIDIA Corporation GA100 [A100 PCIe 40GB] [10de:20f1] (rev a1)
+	Kernel driver in use: nvidia
+`
+
+func TestParseLspciVerbose_NumericIDsAndDriver(t *testing.T) {
+	devices := parseLspciVerbose(lspciNumericSample)
+	if len(devices) != 2 {
+		t.Fatalf("Expected 2 devices, got %d: %+v", len(devices), devices)
+	}
+
+	want := []PCIDevice{
+		{Address: "0000:3b:00.0", Class: "Ethernet controller", ClassID: "0200", VendorID: "15b3", DeviceID: "1019",
+			Description: "Mellanox Technologies MT28800 Family [ConnectX-5 Ex]", Driver: "vfio-pci"},
+		{Address: "0000:af:00.0", Class: "3D controller", ClassID: "0302", VendorID: "10de", DeviceID: "20f1",
+			Description: "NVIDIA Corporation GA100 [A100 PCIe 40GB] (rev a1)", Driver: "nvidia"},
+	}
+	for i, device := range devices {
+		if device != want[i] {
+			t.Errorf("Device %d = %+v, want %+v", i, device, want[i])
+		}
+	}
+}
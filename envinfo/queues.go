@@ -0,0 +1,86 @@
+package envinfo
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// rxQueuePacketsRegex matches the per-queue receive packet counters of
+// `ethtool -S`, which drivers name differently: rx_queue_0_packets (ixgbe,
+// virtio), rx0_packets (mlx5) or rx-0.packets (i40e)
+var rxQueuePacketsRegex = regexp.MustCompile(`^rx(?:_queue_|-)?(\d+)[._]packets$`)
+
+// QueueStatsCommand returns the command printing the NIC statistics of iface
+func QueueStatsCommand(iface string) string {
+	return fmt.Sprintf("ethtool -S %s", iface)
+}
+
+// ParseRxQueuePackets reads the received packets of each RX queue from
+// `ethtool -S` output, keyed by queue number
+func ParseRxQueuePackets(output string) map[int]int64 {
+	queues := make(map[int]int64)
+	for _, line := range strings.Split(output, "\n") {
+		key, value, found := strings.Cut(line, ":")
+		if !found {
+			continue
+		}
+		match := rxQueuePacketsRegex.FindStringSubmatch(strings.TrimSpace(key))
+		if match == nil {
+			continue
+		}
+		queue, _ := strconv.Atoi(match[1])
+		if packets, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64); err == nil {
+			queues[queue] = packets
+		}
+	}
+	return queues
+}
+
+// QueueDeltas returns the packets each queue received between two samples.
+// Queues missing from either sample are left out.
+func QueueDeltas(before, after map[int]int64) map[int]int64 {
+	deltas := make(map[int]int64)
+	for queue, start := range before {
+		if end, ok := after[queue]; ok {
+			deltas[queue] = end - start
+		}
+	}
+	return deltas
+}
+
+// QueueImbalance returns the ratio of the busiest to the idlest queue's
+// packets, 1.0 for a perfectly even spread. It returns false when fewer than
+// two queues were sampled or a queue received nothing.
+func QueueImbalance(deltas map[int]int64) (float64, bool) {
+	if len(deltas) < 2 {
+		return 0, false
+	}
+	var min, max int64 = -1, 0
+	for _, packets := range deltas {
+		if min < 0 || packets < min {
+			min = packets
+		}
+		if packets > max {
+			max = packets
+		}
+	}
+	if min <= 0 {
+		return 0, false
+	}
+	return float64(max) / float64(min), true
+}
+
+// IdleQueues returns the queues that received no packets, in order
+func IdleQueues(deltas map[int]int64) []int {
+	var idle []int
+	for queue, packets := range deltas {
+		if packets <= 0 {
+			idle = append(idle, queue)
+		}
+	}
+	sort.Ints(idle)
+	return idle
+}
@@ -0,0 +1,44 @@
+package envinfo
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseRxQueuePackets(t *testing.T) {
+	output := `NIC statistics:
+     rx_packets: 3000
+     tx_packets: 2000
+     rx_queue_0_packets: 1000
+     rx_queue_0_bytes: 64000
+     rx_queue_1_packets: 2000
+     tx_queue_0_packets: 2000
+     rx-2.packets: 5
+     rx3_packets: 7
+`
+	want := map[int]int64{0: 1000, 1: 2000, 2: 5, 3: 7}
+	if got := ParseRxQueuePackets(output); !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseRxQueuePackets() = %v, want %v", got, want)
+	}
+}
+
+func TestQueueImbalance(t *testing.T) {
+	deltas := QueueDeltas(
+		map[int]int64{0: 100, 1: 100, 2: 100, 3: 100},
+		map[int]int64{0: 1100, 1: 4100, 2: 2100, 3: 1100},
+	)
+	if ratio, ok := QueueImbalance(deltas); !ok || ratio != 4 {
+		t.Errorf("Expected a 4.0 imbalance without idle queues, got %v, %v", ratio, ok)
+	}
+
+	deltas[3] = 0
+	if _, ok := QueueImbalance(deltas); ok {
+		t.Error("Expected no ratio with an idle queue")
+	}
+	if idle := IdleQueues(deltas); !reflect.DeepEqual(idle, []int{3}) {
+		t.Errorf("Expected queue 3 idle, got %v", idle)
+	}
+	if _, ok := QueueImbalance(map[int]int64{0: 10}); ok {
+		t.Error("Expected no ratio for a single queue")
+	}
+}
@@ -0,0 +1,174 @@
+package envinfo
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// rdmaSysfsCommand prints "device port|state|link_layer|rate" for every port
+// under /sys/class/infiniband, e.g. "mlx5_0 1|4: ACTIVE|InfiniBand|100 Gb/sec (4X EDR)"
+const rdmaSysfsCommand = `for p in /sys/class/infiniband/*/ports/*; do [ -d "$p" ] || continue; ` +
+	`d=${p#/sys/class/infiniband/}; ` +
+	`echo "${d%%/*} ${p##*/}|$(cat $p/state 2>/dev/null)|$(cat $p/link_layer 2>/dev/null)|$(cat $p/rate 2>/dev/null)"; done`
+
+// RDMAInfo lists the RDMA devices (HCAs) of a host
+type RDMAInfo struct {
+	Devices []RDMADevice `json:"devices"`
+}
+
+// RDMADevice is one HCA, named as ib_dev expects, e.g. "mlx5_0"
+type RDMADevice struct {
+	Name            string     `json:"name"`
+	FirmwareVersion string     `json:"fw_ver,omitempty"`
+	Ports           []RDMAPort `json:"ports"`
+}
+
+// RDMAPort is the state of one port of an HCA
+type RDMAPort struct {
+	Port      int    `json:"port"`
+	State     string `json:"state"`                // e.g. "ACTIVE" or "DOWN"
+	LinkLayer string `json:"link_layer,omitempty"` // "InfiniBand" or "Ethernet" (RoCE)
+	Rate      string `json:"rate,omitempty"`       // e.g. "100 Gb/sec (4X EDR)"; sysfs only
+}
+
+// RDMAModule collects RDMA devices and their port states
+type RDMAModule struct{}
+
+// NewRDMAModule creates a new RDMA device module
+func NewRDMAModule() *RDMAModule {
+	return &RDMAModule{}
+}
+
+// Name returns the module name
+func (m *RDMAModule) Name() string {
+	return "rdma"
+}
+
+// Description returns the module description
+func (m *RDMAModule) Description() string {
+	return "Collects RDMA devices with port state, link layer, and rate"
+}
+
+// IsAvailable checks if the module can run
+func (m *RDMAModule) IsAvailable(ctx context.Context, executor CommandExecutor) bool {
+	_, err := executor.Execute(ctx, "test -d /sys/class/infiniband || command -v ibv_devinfo")
+	return err == nil
+}
+
+// Collect gathers RDMA device information. sysfs is read first since it
+// also reports link rates; ibv_devinfo is the fallback.
+func (m *RDMAModule) Collect(ctx context.Context, executor CommandExecutor) (interface{}, error) {
+	if output, err := executor.Execute(ctx, rdmaSysfsCommand); err == nil {
+		if devices := parseRDMASysfs(output); len(devices) > 0 {
+			return &RDMAInfo{Devices: devices}, nil
+		}
+	}
+
+	output, err := executor.Execute(ctx, "ibv_devinfo 2>/dev/null")
+	if err != nil {
+		return nil, fmt.Errorf("failed to run ibv_devinfo: %w", err)
+	}
+	return &RDMAInfo{Devices: parseIbvDevinfo(output)}, nil
+}
+
+// parseRDMASysfs parses the output of rdmaSysfsCommand, grouping ports by
+// device. Devices are sorted by name and ports by number.
+func parseRDMASysfs(output string) []RDMADevice {
+	ports := make(map[string][]RDMAPort)
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Split(line, "|")
+		if len(fields) != 4 {
+			continue
+		}
+		device, portText, found := strings.Cut(fields[0], " ")
+		port, err := strconv.Atoi(portText)
+		if !found || err != nil {
+			continue
+		}
+		ports[device] = append(ports[device], RDMAPort{
+			Port:      port,
+			State:     rdmaPortState(fields[1]),
+			LinkLayer: strings.TrimSpace(fields[2]),
+			Rate:      strings.TrimSpace(fields[3]),
+		})
+	}
+
+	names := make([]string, 0, len(ports))
+	for name := range ports {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	devices := make([]RDMADevice, 0, len(names))
+	for _, name := range names {
+		devicePorts := ports[name]
+		sort.Slice(devicePorts, func(i, j int) bool { return devicePorts[i].Port < devicePorts[j].Port })
+		devices = append(devices, RDMADevice{Name: name, Ports: devicePorts})
+	}
+	return devices
+}
+
+// parseIbvDevinfo parses ibv_devinfo output, which lists each HCA under an
+// "hca_id:" line followed by its "port:" sections
+func parseIbvDevinfo(output string) []RDMADevice {
+	var devices []RDMADevice
+	var device *RDMADevice
+	var port *RDMAPort
+
+	for _, line := range strings.Split(output, "\n") {
+		key, value, found := strings.Cut(strings.TrimSpace(line), ":")
+		if !found {
+			continue
+		}
+		value = strings.TrimSpace(value)
+
+		switch key {
+		case "hca_id":
+			devices = append(devices, RDMADevice{Name: value})
+			device, port = &devices[len(devices)-1], nil
+		case "fw_ver":
+			if device != nil {
+				device.FirmwareVersion = value
+			}
+		case "port":
+			number, err := strconv.Atoi(value)
+			if device == nil || err != nil {
+				continue
+			}
+			device.Ports = append(device.Ports, RDMAPort{Port: number})
+			port = &device.Ports[len(device.Ports)-1]
+		case "state":
+			if port != nil {
+				port.State = rdmaPortState(value)
+			}
+		case "link_layer":
+			if port != nil {
+				port.LinkLayer = value
+			}
+		}
+	}
+	return devices
+}
+
+// rdmaPortState normalizes "4: ACTIVE" (sysfs) and "PORT_ACTIVE (4)"
+// (ibv_devinfo) to "ACTIVE"
+func rdmaPortState(state string) string {
+	state = strings.TrimSpace(state)
+	if _, name, found := strings.Cut(state, ":"); found {
+		state = strings.TrimSpace(name)
+	}
+	if name, _, found := strings.Cut(state, " ("); found {
+		state = name
+	}
+	return strings.TrimPrefix(state, "PORT_")
+}
+
+// Auto-register this module
+func init() {
+	RegisterModule("rdma", func() Module {
+		return NewRDMAModule()
+	})
+}
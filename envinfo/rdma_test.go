@@ -0,0 +1,64 @@
+package envinfo
+
+import (
+	"reflect"
+	"testing"
+)
+
+const ibvDevinfoSample = `hca_id:	mlx5_0
+	transport:			InfiniBand (0)
+	fw_ver:				16.35.2000
+	node_guid:			b859:9f03:00d4:1a2e
+	phys_port_cnt:			1
+		port:	1
+			state:			PORT_ACTIVE (4)
+			max_mtu:		4096 (5)
+			active_mtu:		4096 (5)
+			link_layer:		InfiniBand
+
+hca_id:	mlx5_1
+	transport:			InfiniBand (0)
+	fw_ver:				16.35.2000
+	phys_port_cnt:			2
+		port:	1
+			state:			PORT_ACTIVE (4)
+			link_layer:		Ethernet
+
+		port:	2
+			state:			PORT_DOWN (1)
+			link_layer:		Ethernet
+`
+
+func TestParseIbvDevinfo(t *testing.T) {
+	want := []RDMADevice{
+		{Name: "mlx5_0", FirmwareVersion: "16.35.2000", Ports: []RDMAPort{
+			{Port: 1, State: "ACTIVE", LinkLayer: "InfiniBand"},
+		}},
+		{Name: "mlx5_1", FirmwareVersion: "16.35.2000", Ports: []RDMAPort{
+			{Port: 1, State: "ACTIVE", LinkLayer: "Ethernet"},
+			{Port: 2, State: "DOWN", LinkLayer: "Ethernet"},
+		}},
+	}
+	if devices := parseIbvDevinfo(ibvDevinfoSample); !reflect.DeepEqual(devices, want) {
+		t.Errorf("parseIbvDevinfo() = %+v, want %+v", devices, want)
+	}
+}
+
+func TestParseRDMASysfs(t *testing.T) {
+	output := "mlx5_1 2|1: DOWN|Ethernet|40 Gb/sec (4X QDR)\n" +
+		"mlx5_0 1|4: ACTIVE|InfiniBand|100 Gb/sec (4X EDR)\n" +
+		"mlx5_1 1|4: ACTIVE|Ethernet|100 Gb/sec (2X HDR)\n"
+
+	want := []RDMADevice{
+		{Name: "mlx5_0", Ports: []RDMAPort{
+			{Port: 1, State: "ACTIVE", LinkLayer: "InfiniBand", Rate: "100 Gb/sec (4X EDR)"},
+		}},
+		{Name: "mlx5_1", Ports: []RDMAPort{
+			{Port: 1, State: "ACTIVE", LinkLayer: "Ethernet", Rate: "100 Gb/sec (2X HDR)"},
+			{Port: 2, State: "DOWN", LinkLayer: "Ethernet", Rate: "40 Gb/sec (4X QDR)"},
+		}},
+	}
+	if devices := parseRDMASysfs(output); !reflect.DeepEqual(devices, want) {
+		t.Errorf("parseRDMASysfs() = %+v, want %+v", devices, want)
+	}
+}
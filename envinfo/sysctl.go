@@ -0,0 +1,165 @@
+package envinfo
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// DefaultSysctlKeys are the kernel parameters that most often explain a
+// difference in network throughput or latency between two hosts
+var DefaultSysctlKeys = []string{
+	"net.core.rmem_max",
+	"net.core.wmem_max",
+	"net.core.rmem_default",
+	"net.core.wmem_default",
+	"net.core.netdev_max_backlog",
+	"net.core.netdev_budget",
+	"net.core.somaxconn",
+	"net.core.busy_poll",
+	"net.core.busy_read",
+	"net.core.default_qdisc",
+	"net.ipv4.tcp_rmem",
+	"net.ipv4.tcp_wmem",
+	"net.ipv4.tcp_mem",
+	"net.ipv4.tcp_congestion_control",
+	"net.ipv4.tcp_mtu_probing",
+	"net.ipv4.tcp_timestamps",
+	"net.ipv4.tcp_sack",
+	"net.ipv4.tcp_window_scaling",
+	"net.ipv4.tcp_low_latency",
+	"net.ipv4.udp_mem",
+}
+
+// Auto-register the sysctl module
+func init() {
+	RegisterSysctlModule(nil)
+}
+
+// RegisterSysctlModule registers the sysctl module reading keys, or
+// DefaultSysctlKeys when none are given, replacing any earlier registration.
+// It is how the configured sysctl_keys reach the modules of a registry.
+func RegisterSysctlModule(keys []string) {
+	keys = append([]string(nil), keys...)
+	RegisterModule("sysctl", func() Module { return NewSysctlModule(keys...) })
+}
+
+// SysctlModule reads kernel tuning parameters, returned as a map of sysctl
+// key to value
+type SysctlModule struct {
+	keys []string
+}
+
+// NewSysctlModule creates a sysctl module reading keys, or DefaultSysctlKeys
+// when none are given
+func NewSysctlModule(keys ...string) *SysctlModule {
+	m := &SysctlModule{}
+	m.SetKeys(keys)
+	return m
+}
+
+// SetKeys replaces the keys the module reads; an empty list restores
+// DefaultSysctlKeys
+func (m *SysctlModule) SetKeys(keys []string) {
+	if len(keys) == 0 {
+		keys = DefaultSysctlKeys
+	}
+	m.keys = append([]string(nil), keys...)
+}
+
+// Keys returns the keys the module reads
+func (m *SysctlModule) Keys() []string {
+	return append([]string(nil), m.keys...)
+}
+
+// Name returns the module name
+func (m *SysctlModule) Name() string {
+	return "sysctl"
+}
+
+// Description returns the module description
+func (m *SysctlModule) Description() string {
+	return "Reads kernel tuning parameters such as socket buffer limits and TCP settings"
+}
+
+// IsAvailable checks if the module can run
+func (m *SysctlModule) IsAvailable(ctx context.Context, executor CommandExecutor) bool {
+	_, err := executor.Execute(ctx, "command -v sysctl || test -d /proc/sys")
+	return err == nil
+}
+
+// Collect reads every key in one command. sysctl -e skips keys the kernel
+// does not have; without sysctl the values are read from /proc/sys.
+func (m *SysctlModule) Collect(ctx context.Context, executor CommandExecutor) (interface{}, error) {
+	for _, key := range m.keys {
+		if !ValidSysctlKey(key) {
+			return nil, fmt.Errorf("invalid sysctl key %q", key)
+		}
+	}
+
+	keys := strings.Join(m.keys, " ")
+	if output, err := executor.Execute(ctx, "sysctl -e "+keys+" 2>/dev/null"); err == nil {
+		return parseSysctlOutput(output), nil
+	}
+
+	paths := make([]string, len(m.keys))
+	for i, key := range m.keys {
+		paths[i] = sysctlPath(key)
+	}
+	// grep exits non-zero when some files are missing, yet still prints the rest
+	output, _ := executor.Execute(ctx, "grep -s -H . "+strings.Join(paths, " "))
+	return parseProcSysOutput(output), nil
+}
+
+// ValidSysctlKey reports whether key is a dotted sysctl name safe to pass to
+// a shell, e.g. net.ipv4.tcp_rmem or net.ipv4.conf.eth0.rp_filter
+func ValidSysctlKey(key string) bool {
+	if key == "" {
+		return false
+	}
+	for _, c := range key {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '.' || c == '_' || c == '-':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// sysctlPath returns the /proc/sys file of a dotted sysctl key
+func sysctlPath(key string) string {
+	return "/proc/sys/" + strings.ReplaceAll(key, ".", "/")
+}
+
+// parseSysctlOutput reads the "key = value" lines of sysctl. Multi-field
+// values such as tcp_rmem are joined by single spaces.
+func parseSysctlOutput(output string) map[string]string {
+	values := make(map[string]string)
+	for _, line := range strings.Split(output, "\n") {
+		key, value, found := strings.Cut(line, "=")
+		if !found {
+			continue
+		}
+		if key = strings.TrimSpace(key); key != "" {
+			values[key] = strings.Join(strings.Fields(value), " ")
+		}
+	}
+	return values
+}
+
+// parseProcSysOutput reads the "path:value" lines of grep -H over /proc/sys
+// files, keyed by the dotted sysctl name
+func parseProcSysOutput(output string) map[string]string {
+	values := make(map[string]string)
+	for _, line := range strings.Split(output, "\n") {
+		path, value, found := strings.Cut(line, ":")
+		key, isSysctl := strings.CutPrefix(path, "/proc/sys/")
+		if !found || !isSysctl {
+			continue
+		}
+		values[strings.ReplaceAll(key, "/", ".")] = strings.Join(strings.Fields(value), " ")
+	}
+	return values
+}
@@ -0,0 +1,80 @@
+package envinfo
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestParseSysctlOutput(t *testing.T) {
+	output := "net.core.rmem_max = 212992\nnet.ipv4.tcp_rmem = 4096\t131072\t6291456\nnet.ipv4.tcp_congestion_control = bbr\n\n"
+	want := map[string]string{
+		"net.core.rmem_max":               "212992",
+		"net.ipv4.tcp_rmem":               "4096 131072 6291456",
+		"net.ipv4.tcp_congestion_control": "bbr",
+	}
+	if got := parseSysctlOutput(output); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseSysctlOutput() = %v, want %v", got, want)
+	}
+}
+
+func TestSysctlModule_ProcSysFallback(t *testing.T) {
+	module := NewSysctlModule("net.core.rmem_max", "net.ipv4.tcp_wmem", "net.core.busy_poll")
+	executor := scriptedExecutor{
+		"grep -s -H . /proc/sys/net/core/rmem_max /proc/sys/net/ipv4/tcp_wmem /proc/sys/net/core/busy_poll": "/proc/sys/net/core/rmem_max:212992\n/proc/sys/net/ipv4/tcp_wmem:4096\t16384\t4194304\n",
+	}
+
+	data, err := module.Collect(context.Background(), executor)
+	if err != nil {
+		t.Fatalf("Collect returned error: %v", err)
+	}
+	want := map[string]string{
+		"net.core.rmem_max": "212992",
+		"net.ipv4.tcp_wmem": "4096 16384 4194304",
+	}
+	if !reflect.DeepEqual(data, want) {
+		t.Errorf("Collect() = %v, want %v", data, want)
+	}
+}
+
+func TestSysctlModule_Keys(t *testing.T) {
+	module := NewSysctlModule()
+	if !reflect.DeepEqual(module.Keys(), DefaultSysctlKeys) {
+		t.Errorf("Expected the default keys, got %v", module.Keys())
+	}
+
+	module.SetKeys([]string{"vm.swappiness"})
+	executor := scriptedExecutor{"sysctl -e vm.swappiness 2>/dev/null": "vm.swappiness = 10\n"}
+	data, err := module.Collect(context.Background(), executor)
+	if err != nil {
+		t.Fatalf("Collect returned error: %v", err)
+	}
+	if want := map[string]string{"vm.swappiness": "10"}; !reflect.DeepEqual(data, want) {
+		t.Errorf("Collect() = %v, want %v", data, want)
+	}
+
+	module.SetKeys([]string{"net.core.rmem_max; reboot"})
+	if _, err := module.Collect(context.Background(), executor); err == nil {
+		t.Error("Expected an error for a key that is not a sysctl name")
+	}
+}
+
+func TestRegisterSysctlModule(t *testing.T) {
+	t.Cleanup(func() { RegisterSysctlModule(nil) })
+
+	keys := []string{"vm.swappiness"}
+	RegisterSysctlModule(keys)
+	keys[0] = "changed"
+
+	registry, err := GetDefaultRegistry(nil)
+	if err != nil {
+		t.Fatalf("GetDefaultRegistry returned error: %v", err)
+	}
+	module, ok := registry.GetModule("sysctl")
+	if !ok {
+		t.Fatal("Expected the sysctl module to be registered")
+	}
+	if got := module.(*SysctlModule).Keys(); !reflect.DeepEqual(got, []string{"vm.swappiness"}) {
+		t.Errorf("Expected the registered keys, got %v", got)
+	}
+}
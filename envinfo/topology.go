@@ -0,0 +1,164 @@
+package envinfo
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// TopologyInfo maps each logical CPU to its NUMA node
+type TopologyInfo struct {
+	CPUNodes map[int]int `json:"cpu_nodes"`
+}
+
+// TopologyModule collects the CPU to NUMA node mapping
+type TopologyModule struct{}
+
+// NewTopologyModule creates a new CPU topology module
+func NewTopologyModule() *TopologyModule {
+	return &TopologyModule{}
+}
+
+// Name returns the module name
+func (m *TopologyModule) Name() string {
+	return "topology"
+}
+
+// Description returns the module description
+func (m *TopologyModule) Description() string {
+	return "Collects the NUMA node of each logical CPU"
+}
+
+// IsAvailable checks if the module can run
+func (m *TopologyModule) IsAvailable(ctx context.Context, executor CommandExecutor) bool {
+	_, err := executor.Execute(ctx, "command -v lscpu")
+	return err == nil
+}
+
+// Collect gathers the CPU topology
+func (m *TopologyModule) Collect(ctx context.Context, executor CommandExecutor) (interface{}, error) {
+	output, err := executor.Execute(ctx, "lscpu -p=CPU,NODE")
+	if err != nil {
+		return nil, fmt.Errorf("failed to run lscpu: %w", err)
+	}
+
+	return &TopologyInfo{CPUNodes: parseLscpuNodes(output)}, nil
+}
+
+// parseLscpuNodes parses `lscpu -p=CPU,NODE` output ("cpu,node" lines after
+// "#" comments). CPUs without a node, as on single-node hosts, are left out.
+func parseLscpuNodes(output string) map[int]int {
+	nodes := make(map[int]int)
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		cpuText, nodeText, found := strings.Cut(line, ",")
+		if !found {
+			continue
+		}
+		cpu, err := strconv.Atoi(cpuText)
+		if err != nil {
+			continue
+		}
+		node, err := strconv.Atoi(nodeText)
+		if err != nil {
+			continue
+		}
+		nodes[cpu] = node
+	}
+	return nodes
+}
+
+// NodesOf returns the NUMA nodes, sorted, of the CPUs in a cpu_affinity list
+// such as "2-5,8"
+func (info *TopologyInfo) NodesOf(cpuList string) ([]int, error) {
+	cpus, err := ParseCPUList(cpuList)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[int]bool)
+	var nodes []int
+	for _, cpu := range cpus {
+		node, ok := info.CPUNodes[cpu]
+		if ok && !seen[node] {
+			seen[node] = true
+			nodes = append(nodes, node)
+		}
+	}
+	sort.Ints(nodes)
+	return nodes, nil
+}
+
+// ParseCPUList expands a CPU list such as "2-5,8" into its CPU numbers
+func ParseCPUList(list string) ([]int, error) {
+	var cpus []int
+	for _, part := range strings.Split(list, ",") {
+		first, last, isRange := strings.Cut(strings.TrimSpace(part), "-")
+		start, err := strconv.Atoi(first)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CPU list %q", list)
+		}
+		end := start
+		if isRange {
+			if end, err = strconv.Atoi(last); err != nil || end < start {
+				return nil, fmt.Errorf("invalid CPU list %q", list)
+			}
+		}
+		for cpu := start; cpu <= end; cpu++ {
+			cpus = append(cpus, cpu)
+		}
+	}
+	return cpus, nil
+}
+
+// NumaPlacementWarning cross-checks pinned cores against the NUMA nodes of
+// the network adapters carrying the test, given by their PCI addresses in
+// nics. It returns a warning when some of the cores in cpuList sit on a node
+// without one of those NICs, so traffic would cross the interconnect, or ""
+// when placement looks right or cannot be determined.
+func NumaPlacementWarning(pci *PCIInfo, topology *TopologyInfo, cpuList string, nics []string) string {
+	nicNodes := pci.NICNumaNodes(nics)
+	if len(nicNodes) == 0 || len(topology.CPUNodes) == 0 {
+		return ""
+	}
+	coreNodes, err := topology.NodesOf(cpuList)
+	if err != nil {
+		return ""
+	}
+
+	var remote []string
+	for _, node := range coreNodes {
+		if _, hasNIC := nicNodes[node]; !hasNIC {
+			remote = append(remote, strconv.Itoa(node))
+		}
+	}
+	if len(remote) == 0 {
+		return ""
+	}
+
+	var placement []string
+	nodes := make([]int, 0, len(nicNodes))
+	for node := range nicNodes {
+		nodes = append(nodes, node)
+	}
+	sort.Ints(nodes)
+	for _, node := range nodes {
+		placement = append(placement, fmt.Sprintf("%s on node %d", strings.Join(nicNodes[node], ", "), node))
+	}
+	return fmt.Sprintf("cpu_affinity %s uses NUMA node %s but the test NICs are %s (cross-NUMA placement)",
+		cpuList, strings.Join(remote, ", "), strings.Join(placement, "; "))
+}
+
+// Auto-register this module
+func init() {
+	RegisterModule("topology", func() Module {
+		return NewTopologyModule()
+	})
+}
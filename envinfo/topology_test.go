@@ -0,0 +1,83 @@
+package envinfo
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// lscpuNodesSample is `lscpu -p=CPU,NODE` output of a two-node host with
+// CPUs 0-3 on node 0 and 4-7 on node 1
+const lscpuNodesSample = `# The following is the parsable format, which can be fed to other
+# programs. Each different item in every column has an unique ID
+# starting from zero.
+# CPU,Node
+0,0
+1,0
+2,0
+3,0
+4,1
+5,1
+6,1
+7,1
+`
+
+func TestParseLscpuNodes(t *testing.T) {
+	nodes := parseLscpuNodes(lscpuNodesSample)
+	if len(nodes) != 8 || nodes[3] != 0 || nodes[4] != 1 {
+		t.Errorf("Unexpected CPU nodes: %v", nodes)
+	}
+
+	// Single-node hosts print no node
+	if nodes := parseLscpuNodes("# CPU,Node\n0,\n1,\n"); len(nodes) != 0 {
+		t.Errorf("Expected CPUs without a node to be left out, got %v", nodes)
+	}
+}
+
+func TestParseCPUList(t *testing.T) {
+	cpus, err := ParseCPUList("2-5,8")
+	if err != nil {
+		t.Fatalf("ParseCPUList failed: %v", err)
+	}
+	if want := []int{2, 3, 4, 5, 8}; !reflect.DeepEqual(cpus, want) {
+		t.Errorf("Expected %v, got %v", want, cpus)
+	}
+
+	for _, invalid := range []string{"", "a", "5-2", "1,,2"} {
+		if _, err := ParseCPUList(invalid); err == nil {
+			t.Errorf("Expected an error for %q", invalid)
+		}
+	}
+}
+
+func TestNumaPlacementWarning(t *testing.T) {
+	node := func(n int) *int { return &n }
+	topology := &TopologyInfo{CPUNodes: parseLscpuNodes(lscpuNodesSample)}
+	pci := &PCIInfo{Devices: []PCIDevice{
+		{Address: "3b:00.0", Class: "Ethernet controller", NUMANode: node(0)},
+		{Address: "af:00.0", Class: "3D controller", NUMANode: node(1)},
+		{Address: "d8:00.0", Class: "Infiniband controller", NUMANode: node(1)},
+	}}
+
+	warning := NumaPlacementWarning(pci, topology, "4-5", []string{"3b:00.0"})
+	if !strings.Contains(warning, "NUMA node 1") || !strings.Contains(warning, "3b:00.0 on node 0") {
+		t.Errorf("Expected a cross-NUMA warning for cores on node 1, got %q", warning)
+	}
+	if warning := NumaPlacementWarning(pci, topology, "1-2", []string{"3b:00.0"}); warning != "" {
+		t.Errorf("Expected no warning for cores on the NIC's node, got %q", warning)
+	}
+
+	// A NIC on the cores' node does not help when the test uses another one
+	warning = NumaPlacementWarning(pci, topology, "1-2", []string{"d8:00.0"})
+	if !strings.Contains(warning, "NUMA node 0") || !strings.Contains(warning, "d8:00.0 on node 1") {
+		t.Errorf("Expected a cross-NUMA warning against the test NIC, got %q", warning)
+	}
+
+	// Without NUMA information there is nothing to compare
+	if warning := NumaPlacementWarning(&PCIInfo{}, topology, "4-5", []string{"3b:00.0"}); warning != "" {
+		t.Errorf("Expected no warning without NIC nodes, got %q", warning)
+	}
+	if warning := NumaPlacementWarning(pci, topology, "4-5", nil); warning != "" {
+		t.Errorf("Expected no warning without test NICs, got %q", warning)
+	}
+}
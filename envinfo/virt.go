@@ -0,0 +1,109 @@
+package envinfo
+
+import (
+	"context"
+	"strings"
+)
+
+// VirtBareMetal is reported when no hypervisor or container is detected
+const VirtBareMetal = "bare-metal"
+
+// VirtInfo describes the virtualization context a host runs in
+type VirtInfo struct {
+	VirtType  string `json:"virt_type"`
+	Container bool   `json:"container"`
+	Source    string `json:"source"`
+}
+
+// containerMarkers maps /proc/1/cgroup path fragments to container runtimes
+var containerMarkers = []struct {
+	marker   string
+	virtType string
+}{
+	{"docker", "docker"},
+	{"kubepods", "kubernetes"},
+	{"libpod", "podman"},
+	{"lxc", "lxc"},
+	{"containerd", "containerd"},
+}
+
+// systemdContainerTypes are systemd-detect-virt results that denote containers
+var systemdContainerTypes = map[string]bool{
+	"docker": true, "podman": true, "lxc": true, "lxc-libvirt": true,
+	"systemd-nspawn": true, "openvz": true, "rkt": true, "wsl": true,
+	"proot": true, "pouch": true, "container-other": true,
+}
+
+// VirtModule detects whether the host is a container, a VM, or bare metal
+type VirtModule struct{}
+
+// NewVirtModule creates a new virtualization detection module
+func NewVirtModule() *VirtModule {
+	return &VirtModule{}
+}
+
+// Name returns the module name
+func (m *VirtModule) Name() string {
+	return "virt"
+}
+
+// Description returns the module description
+func (m *VirtModule) Description() string {
+	return "Detects virtualization context (kvm, docker, bare-metal, ...)"
+}
+
+// IsAvailable checks if the module can run
+func (m *VirtModule) IsAvailable(ctx context.Context, executor CommandExecutor) bool {
+	// Falls back to /proc inspection when systemd-detect-virt is missing
+	return true
+}
+
+// Collect gathers virtualization information
+func (m *VirtModule) Collect(ctx context.Context, executor CommandExecutor) (interface{}, error) {
+	// Each probe is best effort; missing tools or files just yield empty input
+	detectVirt, _ := executor.Execute(ctx, "systemd-detect-virt 2>/dev/null || true")
+	cgroup, _ := executor.Execute(ctx, "cat /proc/1/cgroup 2>/dev/null || true")
+	cpuFlags, _ := executor.Execute(ctx, "grep -m1 '^flags' /proc/cpuinfo 2>/dev/null || true")
+
+	return detectVirtualization(detectVirt, cgroup, cpuFlags), nil
+}
+
+// detectVirtualization combines systemd-detect-virt output, /proc/1/cgroup, and
+// the /proc/cpuinfo flags line into a virtualization verdict. systemd-detect-virt
+// is trusted first; the cgroup and hypervisor flag are fallbacks for minimal images.
+func detectVirtualization(detectVirt, cgroup, cpuFlags string) *VirtInfo {
+	if virtType := strings.TrimSpace(detectVirt); virtType != "" {
+		if virtType == "none" {
+			return &VirtInfo{VirtType: VirtBareMetal, Source: "systemd-detect-virt"}
+		}
+		return &VirtInfo{
+			VirtType:  virtType,
+			Container: systemdContainerTypes[virtType],
+			Source:    "systemd-detect-virt",
+		}
+	}
+
+	for _, line := range strings.Split(cgroup, "\n") {
+		for _, m := range containerMarkers {
+			if strings.Contains(line, m.marker) {
+				return &VirtInfo{VirtType: m.virtType, Container: true, Source: "/proc/1/cgroup"}
+			}
+		}
+	}
+
+	for _, flag := range strings.Fields(cpuFlags) {
+		if flag == "hypervisor" {
+			// The flag says "virtual machine" but not which hypervisor
+			return &VirtInfo{VirtType: "vm", Source: "/proc/cpuinfo"}
+		}
+	}
+
+	return &VirtInfo{VirtType: VirtBareMetal, Source: "/proc/cpuinfo"}
+}
+
+// Auto-register this module
+func init() {
+	RegisterModule("virt", func() Module {
+		return NewVirtModule()
+	})
+}
@@ -0,0 +1,39 @@
+package envinfo
+
+import "testing"
+
+const (
+	dockerCgroup = `12:pids:/docker/3f1c2a9b8e7d
+11:memory:/docker/3f1c2a9b8e7d
+0::/docker/3f1c2a9b8e7d`
+	hostCgroup = `0::/init.scope`
+	hostFlags  = "flags\t\t: fpu vme de pse tsc msr pae mce cx8 apic sep mtrr avx2"
+	guestFlags = "flags\t\t: fpu vme de pse tsc msr pae mce cx8 apic sep mtrr hypervisor avx2"
+)
+
+func TestDetectVirtualization(t *testing.T) {
+	tests := []struct {
+		name          string
+		detectVirt    string
+		cgroup        string
+		cpuFlags      string
+		wantType      string
+		wantContainer bool
+	}{
+		{name: "systemd reports docker", detectVirt: "docker\n", cgroup: dockerCgroup, cpuFlags: hostFlags, wantType: "docker", wantContainer: true},
+		{name: "systemd reports kvm", detectVirt: "kvm\n", cgroup: hostCgroup, cpuFlags: guestFlags, wantType: "kvm"},
+		{name: "systemd reports none", detectVirt: "none\n", cgroup: hostCgroup, cpuFlags: hostFlags, wantType: VirtBareMetal},
+		{name: "docker from cgroup", cgroup: dockerCgroup, cpuFlags: hostFlags, wantType: "docker", wantContainer: true},
+		{name: "vm from hypervisor flag", cgroup: hostCgroup, cpuFlags: guestFlags, wantType: "vm"},
+		{name: "bare metal without systemd", cgroup: hostCgroup, cpuFlags: hostFlags, wantType: VirtBareMetal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info := detectVirtualization(tt.detectVirt, tt.cgroup, tt.cpuFlags)
+			if info.VirtType != tt.wantType || info.Container != tt.wantContainer {
+				t.Errorf("detectVirtualization() = %+v, want type %q container %v", info, tt.wantType, tt.wantContainer)
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+#!/usr/bin/env python3
+"""Time-to-first-byte probe for the wrk runner.
+
+Sends GET requests for URL one at a time and times each one from sending the
+request until the first byte of the response body arrives, then reads the
+rest of the body. Each request is the real, full request the load run makes;
+the connection is opened before the timer starts, so connect time is not
+included. The result is printed in the form the wrk runner parses as ttfb_*
+metrics.
+
+Copy this file to the client host and point the ttfb_script arg at it.
+
+Usage: wrk-ttfb.py URL [REQUESTS]
+"""
+
+import http.client
+import sys
+import time
+import urllib.parse
+
+
+def main():
+    url = urllib.parse.urlsplit(sys.argv[1])
+    requests = int(sys.argv[2]) if len(sys.argv) > 2 else 100
+    path = url.path or "/"
+    if url.query:
+        path += "?" + url.query
+
+    samples = []
+    for _ in range(requests):
+        conn = http.client.HTTPConnection(url.hostname, url.port or 80, timeout=30)
+        conn.connect()
+        start = time.perf_counter()
+        conn.request("GET", path)
+        response = conn.getresponse()
+        response.read(1)
+        samples.append((time.perf_counter() - start) * 1e3)
+        response.read()
+        conn.close()
+
+    samples.sort()
+    print("TTFB Distribution")
+    for p in (50, 75, 90, 99):
+        # Nearest-rank percentile
+        rank = max(1, -(-len(samples) * p // 100))
+        print("  %d%%  %.2fms" % (p, samples[rank - 1]))
+    print("TTFB Avg: %.2fms" % (sum(samples) / len(samples)))
+
+
+if __name__ == "__main__":
+    main()
@@ -3,8 +3,13 @@ module perf-runner
 go 1.21
 
 require (
+	github.com/pkg/sftp v1.13.6
 	golang.org/x/crypto v0.19.0
+	golang.org/x/sync v0.6.0
 	gopkg.in/yaml.v3 v3.0.1
 )
 
-require golang.org/x/sys v0.17.0 // indirect
+require (
+	github.com/kr/fs v0.1.0 // indirect
+	golang.org/x/sys v0.17.0 // indirect
+)
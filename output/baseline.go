@@ -0,0 +1,221 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"perf-runner/coordinator"
+	"perf-runner/runner"
+)
+
+// Baseline holds the mean client metrics of each scenario of a previous run,
+// keyed by scenario name and then metric
+type Baseline map[string]map[string]float64
+
+// BaselineComparison compares a run's client metrics to a baseline
+type BaselineComparison struct {
+	Path string        `json:"baseline"`
+	Rows []BaselineRow `json:"rows"`
+	// Scenarios of the run missing from the baseline, and the reverse
+	New     []string `json:"new_scenarios,omitempty"`
+	Missing []string `json:"missing_scenarios,omitempty"`
+}
+
+// BaselineRow is one metric of one scenario against the baseline. A
+// positive delta means the metric grew; whether that is a regression
+// depends on the metric, as latencies regress when they grow.
+type BaselineRow struct {
+	Scenario     string   `json:"scenario"`
+	Metric       string   `json:"metric"`
+	Baseline     float64  `json:"baseline"`
+	Current      float64  `json:"current"`
+	DeltaPercent float64  `json:"delta_percent"`
+	Threshold    *float64 `json:"threshold,omitempty"` // Allowed regression in percent
+	Regressed    bool     `json:"regressed,omitempty"`
+}
+
+// LoadBaseline reads a results file written with -json (or -output-format
+// json) as the baseline to compare a run against
+func LoadBaseline(path string) (Baseline, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open baseline %s: %w", path, err)
+	}
+	defer file.Close()
+
+	baseline, err := ParseBaseline(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read baseline %s: %w", path, err)
+	}
+	return baseline, nil
+}
+
+// ParseBaseline reads JSON results output as a baseline. Only successful
+// results count; the metrics of a scenario run several times are averaged.
+func ParseBaseline(r io.Reader) (Baseline, error) {
+	var report struct {
+		Results []struct {
+			ScenarioName string `json:"scenario_name"`
+			Success      bool   `json:"success"`
+			ClientResult *struct {
+				Metrics map[string]interface{} `json:"metrics"`
+			} `json:"client_result"`
+		} `json:"results"`
+	}
+	if err := json.NewDecoder(r).Decode(&report); err != nil {
+		return nil, err
+	}
+
+	baseline := make(Baseline)
+	counts := make(map[string]map[string]int)
+	for _, result := range report.Results {
+		if _, seen := baseline[result.ScenarioName]; !seen {
+			baseline[result.ScenarioName] = make(map[string]float64)
+			counts[result.ScenarioName] = make(map[string]int)
+		}
+		if !result.Success || result.ClientResult == nil {
+			continue
+		}
+		addMeans(baseline[result.ScenarioName], counts[result.ScenarioName], result.ClientResult.Metrics)
+	}
+	return baseline, nil
+}
+
+// addMeans folds the numeric metrics into running means
+func addMeans(means map[string]float64, counts map[string]int, metrics map[string]interface{}) {
+	for key, value := range metrics {
+		number, ok := metricFloat(value)
+		if !ok {
+			continue
+		}
+		counts[key]++
+		means[key] += (number - means[key]) / float64(counts[key])
+	}
+}
+
+// CompareBaseline compares the mean client metrics of each scenario to the
+// baseline. Every metric with a threshold is compared, plus the scenario's
+// primary metric; thresholds give the regression, in percent, allowed for a
+// metric. Metrics the baseline lacks, or reported as 0 there, are skipped.
+func CompareBaseline(baseline Baseline, results []*coordinator.TestResult, thresholds map[string]float64) *BaselineComparison {
+	comparison := &BaselineComparison{}
+	var scenarios []string
+	current := make(Baseline)
+	counts := make(map[string]map[string]int)
+	primary := make(map[string]string)
+
+	for _, result := range results {
+		name := result.ScenarioName
+		if _, seen := current[name]; !seen {
+			scenarios = append(scenarios, name)
+			current[name] = make(map[string]float64)
+			counts[name] = make(map[string]int)
+		}
+		if result.PrimaryMetric != "" {
+			primary[name] = result.PrimaryMetric
+		}
+		if result.Success && result.ClientResult != nil {
+			addMeans(current[name], counts[name], result.ClientResult.Metrics)
+		}
+	}
+
+	for _, name := range scenarios {
+		before, inBaseline := baseline[name]
+		if !inBaseline {
+			comparison.New = append(comparison.New, name)
+			continue
+		}
+
+		metrics := make([]string, 0, len(thresholds)+1)
+		for metric := range thresholds {
+			metrics = append(metrics, metric)
+		}
+		if _, hasThreshold := thresholds[primary[name]]; primary[name] != "" && !hasThreshold {
+			metrics = append(metrics, primary[name])
+		}
+		sort.Strings(metrics)
+
+		for _, metric := range metrics {
+			old, inBefore := before[metric]
+			value, inCurrent := current[name][metric]
+			if !inBefore || !inCurrent || old == 0 {
+				continue
+			}
+			row := BaselineRow{
+				Scenario:     name,
+				Metric:       metric,
+				Baseline:     old,
+				Current:      value,
+				DeltaPercent: (value - old) / old * 100,
+			}
+			if threshold, ok := thresholds[metric]; ok {
+				row.Threshold = &threshold
+				regression := -row.DeltaPercent
+				if runner.LowerIsBetter(metric) {
+					regression = row.DeltaPercent
+				}
+				row.Regressed = regression > threshold
+			}
+			comparison.Rows = append(comparison.Rows, row)
+		}
+	}
+
+	for name := range baseline {
+		if _, inRun := current[name]; !inRun {
+			comparison.Missing = append(comparison.Missing, name)
+		}
+	}
+	sort.Strings(comparison.Missing)
+
+	return comparison
+}
+
+// Regressions counts the metrics that regressed beyond their threshold
+func (c *BaselineComparison) Regressions() int {
+	if c == nil {
+		return 0
+	}
+	count := 0
+	for _, row := range c.Rows {
+		if row.Regressed {
+			count++
+		}
+	}
+	return count
+}
+
+// writeBaselineComparison renders the comparison as an aligned text table,
+// marking the rows that regressed beyond their threshold
+func (f *Formatter) writeBaselineComparison(w io.Writer, comparison *BaselineComparison) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join([]string{"Scenario", "Metric", "Baseline", "Current", "Delta", "Status"}, "\t"))
+	for _, row := range comparison.Rows {
+		status := "-"
+		switch {
+		case row.Regressed:
+			status = fmt.Sprintf("REGRESSION (allowed %g%%)", *row.Threshold)
+			if f.color {
+				status = ansiRed + status + ansiReset
+			}
+		case row.Threshold != nil:
+			status = "ok"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%.2f\t%.2f\t%+.1f%%\t%s\n", row.Scenario, row.Metric, row.Baseline, row.Current, row.DeltaPercent, status)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if len(comparison.New) > 0 {
+		fmt.Fprintf(w, "Not in baseline: %s\n", strings.Join(comparison.New, ", "))
+	}
+	if len(comparison.Missing) > 0 {
+		fmt.Fprintf(w, "Missing from this run: %s\n", strings.Join(comparison.Missing, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"perf-runner/coordinator"
+	"perf-runner/runner"
+)
+
+func baselineResult(name string, metrics map[string]interface{}) *coordinator.TestResult {
+	return &coordinator.TestResult{
+		ScenarioName:  name,
+		Success:       true,
+		PrimaryMetric: "bandwidth_mbps",
+		ClientResult:  &runner.Result{Success: true, Metrics: metrics},
+	}
+}
+
+func TestParseBaseline_RoundTripsJSONOutput(t *testing.T) {
+	results := []*coordinator.TestResult{
+		baselineResult("tcp", map[string]interface{}{"bandwidth_mbps": 900.0, "retransmits": 4}),
+		baselineResult("tcp", map[string]interface{}{"bandwidth_mbps": 1100.0, "retransmits": 6}),
+		{ScenarioName: "udp", Success: false},
+	}
+	var buf bytes.Buffer
+	formatter := NewFormatter(true)
+	formatter.SetOutput(&buf)
+	if err := formatter.OutputResults(results, time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	baseline, err := ParseBaseline(&buf)
+	if err != nil {
+		t.Fatalf("ParseBaseline returned error: %v", err)
+	}
+	if got := baseline["tcp"]["bandwidth_mbps"]; got != 1000 {
+		t.Errorf("Expected repeats averaged to 1000, got %v", got)
+	}
+	if got := baseline["tcp"]["retransmits"]; got != 5 {
+		t.Errorf("Expected integer metrics averaged to 5, got %v", got)
+	}
+	if metrics, ok := baseline["udp"]; !ok || len(metrics) != 0 {
+		t.Errorf("Expected the failed scenario present without metrics, got %v, %v", metrics, ok)
+	}
+}
+
+func TestCompareBaseline(t *testing.T) {
+	baseline := Baseline{
+		"tcp":     {"bandwidth_mbps": 1000, "latency_avg_usec": 50},
+		"udp":     {"bandwidth_mbps": 500},
+		"removed": {"bandwidth_mbps": 100},
+	}
+	results := []*coordinator.TestResult{
+		baselineResult("tcp", map[string]interface{}{"bandwidth_mbps": 940.0, "latency_avg_usec": 60.0}),
+		baselineResult("udp", map[string]interface{}{"bandwidth_mbps": 490.0}),
+		baselineResult("added", map[string]interface{}{"bandwidth_mbps": 10.0}),
+	}
+	thresholds := map[string]float64{"bandwidth_mbps": 5, "latency_avg_usec": 10}
+
+	comparison := CompareBaseline(baseline, results, thresholds)
+	if len(comparison.Rows) != 3 {
+		t.Fatalf("Expected 3 rows, got %+v", comparison.Rows)
+	}
+
+	tcpBandwidth, tcpLatency, udpBandwidth := comparison.Rows[0], comparison.Rows[1], comparison.Rows[2]
+	if tcpBandwidth.Metric != "bandwidth_mbps" || !tcpBandwidth.Regressed || tcpBandwidth.DeltaPercent != -6 {
+		t.Errorf("Expected a 6%% bandwidth drop to regress, got %+v", tcpBandwidth)
+	}
+	if tcpLatency.Metric != "latency_avg_usec" || !tcpLatency.Regressed {
+		t.Errorf("Expected a 20%% latency rise to regress, got %+v", tcpLatency)
+	}
+	if udpBandwidth.Scenario != "udp" || udpBandwidth.Regressed {
+		t.Errorf("Expected a 2%% bandwidth drop within threshold, got %+v", udpBandwidth)
+	}
+	if comparison.Regressions() != 2 {
+		t.Errorf("Expected 2 regressions, got %d", comparison.Regressions())
+	}
+	if len(comparison.New) != 1 || comparison.New[0] != "added" {
+		t.Errorf("Expected the added scenario reported as new, got %v", comparison.New)
+	}
+	if len(comparison.Missing) != 1 || comparison.Missing[0] != "removed" {
+		t.Errorf("Expected the removed scenario reported as missing, got %v", comparison.Missing)
+	}
+}
+
+func TestCompareBaseline_PrimaryMetricWithoutThreshold(t *testing.T) {
+	baseline := Baseline{"tcp": {"bandwidth_mbps": 1000}}
+	results := []*coordinator.TestResult{baselineResult("tcp", map[string]interface{}{"bandwidth_mbps": 500.0})}
+
+	comparison := CompareBaseline(baseline, results, nil)
+	if len(comparison.Rows) != 1 || comparison.Rows[0].Threshold != nil || comparison.Rows[0].Regressed {
+		t.Errorf("Expected the primary metric compared without failing, got %+v", comparison.Rows)
+	}
+	if comparison.Regressions() != 0 {
+		t.Errorf("Expected no regressions without thresholds, got %d", comparison.Regressions())
+	}
+}
+
+func TestCompareBaseline_LowerIsBetterMetrics(t *testing.T) {
+	baseline := Baseline{"udp": {"packet_loss_pct": 1, "retransmits": 100, "tcp_lat_usec": 10, "bandwidth_mbps": 1000}}
+	results := []*coordinator.TestResult{
+		baselineResult("udp", map[string]interface{}{"packet_loss_pct": 2.0, "retransmits": 50, "tcp_lat_usec": 12.0, "bandwidth_mbps": 1000.0}),
+	}
+	thresholds := map[string]float64{"packet_loss_pct": 10, "retransmits": 10, "tcp_lat_usec": 10}
+
+	regressed := make(map[string]bool)
+	for _, row := range CompareBaseline(baseline, results, thresholds).Rows {
+		regressed[row.Metric] = row.Regressed
+	}
+	if !regressed["packet_loss_pct"] {
+		t.Error("Expected doubled packet loss to regress")
+	}
+	if regressed["retransmits"] {
+		t.Error("Expected halved retransmits not to regress")
+	}
+	if !regressed["tcp_lat_usec"] {
+		t.Error("Expected a 20% rise in tcp_lat_usec to regress")
+	}
+}
+
+func TestFormatter_BaselineComparison(t *testing.T) {
+	results := []*coordinator.TestResult{baselineResult("tcp", map[string]interface{}{"bandwidth_mbps": 900.0})}
+	comparison := CompareBaseline(Baseline{"tcp": {"bandwidth_mbps": 1000}}, results, map[string]float64{"bandwidth_mbps": 5})
+	comparison.Path = "base.json"
+
+	var text bytes.Buffer
+	formatter := NewFormatter(false)
+	formatter.SetOutput(&text)
+	formatter.SetBaseline(comparison)
+	if err := formatter.OutputResults(results, time.Second); err != nil {
+		t.Fatal(err)
+	}
+	out := text.String()
+	if !strings.Contains(out, "=== Baseline Comparison (base.json) ===") || !strings.Contains(out, "-10.0%") || !strings.Contains(out, "REGRESSION (allowed 5%)") {
+		t.Errorf("Expected the regressing row annotated, got:\n%s", out)
+	}
+
+	var data bytes.Buffer
+	formatter.SetFormat(FormatJSON)
+	formatter.SetOutput(&data)
+	if err := formatter.OutputResults(results, time.Second); err != nil {
+		t.Fatal(err)
+	}
+	var decoded struct {
+		BaselineComparison BaselineComparison `json:"baseline_comparison"`
+	}
+	if err := json.Unmarshal(data.Bytes(), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if rows := decoded.BaselineComparison.Rows; len(rows) != 1 || !rows[0].Regressed {
+		t.Errorf("Expected the regression in JSON output, got %+v", decoded.BaselineComparison)
+	}
+}
@@ -0,0 +1,91 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"perf-runner/coordinator"
+)
+
+// comparisonMatrix holds the primary metric of each runner (columns) for each
+// comparison group (rows)
+type comparisonMatrix struct {
+	Runners []string          `json:"runners"`
+	Metrics map[string]string `json:"metrics"` // Primary metric key per runner
+	Rows    []comparisonRow   `json:"rows"`
+}
+
+// comparisonRow is one comparison group's mean primary metric per runner.
+// Runners without a successful result in the group are absent.
+type comparisonRow struct {
+	Group  string             `json:"group"`
+	Values map[string]float64 `json:"values"`
+}
+
+// newComparisonMatrix builds the matrix from successful results tagged with a
+// comparison group. Groups and runners keep the order they first appear in;
+// repeated results for one runner in a group are averaged.
+func newComparisonMatrix(results []*coordinator.TestResult) *comparisonMatrix {
+	matrix := &comparisonMatrix{Metrics: make(map[string]string)}
+	rowIndex := make(map[string]int)
+	counts := make(map[string]map[string]int)
+
+	for _, result := range results {
+		if result.ComparisonGroup == "" || !result.Success {
+			continue
+		}
+		value, ok := result.PrimaryValue()
+		if !ok {
+			continue
+		}
+
+		if _, seen := matrix.Metrics[result.Runner]; !seen {
+			matrix.Runners = append(matrix.Runners, result.Runner)
+			matrix.Metrics[result.Runner] = result.PrimaryMetric
+		}
+
+		i, seen := rowIndex[result.ComparisonGroup]
+		if !seen {
+			i = len(matrix.Rows)
+			rowIndex[result.ComparisonGroup] = i
+			matrix.Rows = append(matrix.Rows, comparisonRow{Group: result.ComparisonGroup, Values: make(map[string]float64)})
+			counts[result.ComparisonGroup] = make(map[string]int)
+		}
+
+		// Running mean over repeats
+		row := matrix.Rows[i]
+		n := counts[row.Group][result.Runner] + 1
+		counts[row.Group][result.Runner] = n
+		row.Values[result.Runner] += (value - row.Values[result.Runner]) / float64(n)
+	}
+
+	return matrix
+}
+
+// writeComparisonTable renders the matrix as an aligned text table with one
+// column per runner, headed by the runner and its primary metric
+func writeComparisonTable(w io.Writer, matrix *comparisonMatrix) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	header := []string{"Group"}
+	for _, name := range matrix.Runners {
+		header = append(header, fmt.Sprintf("%s (%s)", name, matrix.Metrics[name]))
+	}
+	fmt.Fprintln(tw, strings.Join(header, "\t"))
+
+	for _, row := range matrix.Rows {
+		cells := []string{row.Group}
+		for _, name := range matrix.Runners {
+			if value, ok := row.Values[name]; ok {
+				cells = append(cells, fmt.Sprintf("%.2f", value))
+			} else {
+				cells = append(cells, "-")
+			}
+		}
+		fmt.Fprintln(tw, strings.Join(cells, "\t"))
+	}
+
+	return tw.Flush()
+}
@@ -0,0 +1,83 @@
+package output
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"perf-runner/coordinator"
+	"perf-runner/runner"
+)
+
+func comparisonResult(group, runnerName, metric string, value float64) *coordinator.TestResult {
+	return &coordinator.TestResult{
+		ScenarioName:    runnerName + " on " + group,
+		Success:         true,
+		Runner:          runnerName,
+		ComparisonGroup: group,
+		PrimaryMetric:   metric,
+		ClientResult:    &runner.Result{Success: true, Metrics: map[string]interface{}{metric: value}},
+	}
+}
+
+func TestComparisonMatrix_OneGroupThreeRunners(t *testing.T) {
+	results := []*coordinator.TestResult{
+		comparisonResult("link-a", "iperf3", "bandwidth_mbps", 9400),
+		comparisonResult("link-a", "ib_send_bw", "bandwidth_average_mbps", 11800),
+		comparisonResult("link-a", "wrk", "requests_per_sec", 52000),
+		{ScenarioName: "untagged", Success: true, Runner: "iperf3", PrimaryMetric: "bandwidth_mbps",
+			ClientResult: &runner.Result{Metrics: map[string]interface{}{"bandwidth_mbps": 1.0}}},
+	}
+
+	matrix := newComparisonMatrix(results)
+	if len(matrix.Rows) != 1 {
+		t.Fatalf("Expected 1 row, got %d", len(matrix.Rows))
+	}
+	if got := strings.Join(matrix.Runners, ","); got != "iperf3,ib_send_bw,wrk" {
+		t.Errorf("Expected runner columns in order of appearance, got %s", got)
+	}
+	row := matrix.Rows[0]
+	if row.Group != "link-a" || len(row.Values) != 3 {
+		t.Fatalf("Expected a 1x3 row for link-a, got %+v", row)
+	}
+	if row.Values["ib_send_bw"] != 11800 {
+		t.Errorf("Expected ib_send_bw value 11800, got %v", row.Values["ib_send_bw"])
+	}
+
+	var buf bytes.Buffer
+	if err := writeComparisonTable(&buf, matrix); err != nil {
+		t.Fatalf("writeComparisonTable returned error: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected header and one row, got:\n%s", buf.String())
+	}
+	if !strings.Contains(lines[0], "ib_send_bw (bandwidth_average_mbps)") {
+		t.Errorf("Expected runner and metric in header, got %q", lines[0])
+	}
+	if fields := strings.Fields(lines[1]); len(fields) != 4 || fields[3] != "52000.00" {
+		t.Errorf("Unexpected row %q", lines[1])
+	}
+}
+
+func TestComparisonMatrix_AveragesRepeatsAndSkipsFailures(t *testing.T) {
+	failed := comparisonResult("link-a", "iperf3", "bandwidth_mbps", 1)
+	failed.Success = false
+	results := []*coordinator.TestResult{
+		comparisonResult("link-a", "iperf3", "bandwidth_mbps", 9000),
+		comparisonResult("link-a", "iperf3", "bandwidth_mbps", 9400),
+		failed,
+		comparisonResult("link-b", "ib_send_bw", "bandwidth_average_mbps", 11800),
+	}
+
+	matrix := newComparisonMatrix(results)
+	if len(matrix.Rows) != 2 {
+		t.Fatalf("Expected 2 rows, got %d", len(matrix.Rows))
+	}
+	if v := matrix.Rows[0].Values["iperf3"]; v != 9200 {
+		t.Errorf("Expected mean 9200, got %v", v)
+	}
+	if _, ok := matrix.Rows[1].Values["iperf3"]; ok {
+		t.Error("Expected no iperf3 value for link-b")
+	}
+}
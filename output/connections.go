@@ -0,0 +1,31 @@
+package output
+
+import (
+	"fmt"
+	"io"
+
+	"perf-runner/coordinator"
+)
+
+// writeConnections writes one line per host with its connection state and
+// counters, followed by the events of hosts whose connection ever dropped or
+// never came up
+func writeConnections(w io.Writer, report []coordinator.HostConnection) {
+	for _, conn := range report {
+		state := "connected"
+		if !conn.Connected {
+			state = "disconnected"
+		}
+		fmt.Fprintf(w, "%s: %s (drops: %d, reconnects: %d)\n", conn.Host, state, conn.Drops, conn.Reconnects)
+		if conn.Drops == 0 && conn.Connected {
+			continue
+		}
+		for _, event := range conn.Events {
+			if event.Error != "" {
+				fmt.Fprintf(w, "  %s %s: %s\n", event.Time.Format("15:04:05"), event.Event, event.Error)
+			} else {
+				fmt.Fprintf(w, "  %s %s\n", event.Time.Format("15:04:05"), event.Event)
+			}
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package output
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+
+	"perf-runner/coordinator"
+)
+
+// intervalCSVHeader lists the columns of the per-interval time-series CSV
+var intervalCSVHeader = []string{"scenario", "stream", "interval_start", "interval_end", "bits_per_second"}
+
+// WriteIntervalCSV writes one row per stream per reporting interval for every
+// result whose client captured interval metrics. With a bandwidth unit (see
+// SetBandwidthUnit) the last column is converted to it and named after it,
+// e.g. bandwidth_gbps. It returns the number of data rows.
+func WriteIntervalCSV(w io.Writer, results []*coordinator.TestResult, unit string) (int, error) {
+	header := intervalCSVHeader
+	if unit != "" {
+		header = append(header[:len(header)-1:len(header)-1], "bandwidth_"+unit)
+	}
+	
+	writer := csv.NewWriter(w)
+	if err := writer.Write(header); err != nil {
+		return 0, err
+	}
+	
+	rows := 0
+	for _, result := range results {
+		if result.ClientResult == nil {
+			continue
+		}
+		intervals, ok := result.ClientResult.Metrics["intervals"].([]map[string]interface{})
+		if !ok {
+			continue
+		}
+		
+		for _, interval := range intervals {
+			record := []string{
+				result.ScenarioName,
+				formatCSVValue(interval["stream"]),
+				formatCSVValue(interval["start"]),
+				formatCSVValue(interval["end"]),
+				formatCSVBandwidth(interval["bits_per_second"], unit),
+			}
+			if err := writer.Write(record); err != nil {
+				return rows, err
+			}
+			rows++
+		}
+	}
+	
+	writer.Flush()
+	return rows, writer.Error()
+}
+
+// formatCSVValue renders a metric value without exponent notation
+func formatCSVValue(value interface{}) string {
+	switch v := value.(type) {
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case nil:
+		return ""
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// formatCSVBandwidth renders a bits per second value in unit, or unchanged
+// when no unit is selected
+func formatCSVBandwidth(value interface{}, unit string) string {
+	bps, ok := metricFloat(value)
+	if unit == "" || !ok {
+		return formatCSVValue(value)
+	}
+	return formatCSVValue(bps / bandwidthUnits[unit].bitsPerUnit)
+}
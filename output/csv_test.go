@@ -0,0 +1,87 @@
+package output
+
+import (
+	"bytes"
+	"encoding/csv"
+	"testing"
+
+	"perf-runner/coordinator"
+	"perf-runner/runner"
+)
+
+func TestWriteIntervalCSV(t *testing.T) {
+	intervals := func(streams, count int) []map[string]interface{} {
+		var rows []map[string]interface{}
+		for i := 0; i < count; i++ {
+			for s := 0; s < streams; s++ {
+				rows = append(rows, map[string]interface{}{
+					"stream":          5 + 2*s,
+					"start":           float64(i),
+					"end":             float64(i + 1),
+					"bits_per_second": 9.4e9,
+				})
+			}
+		}
+		return rows
+	}
+
+	results := []*coordinator.TestResult{
+		{
+			ScenarioName: "two streams",
+			ClientResult: &runner.Result{Metrics: map[string]interface{}{"intervals": intervals(2, 3)}},
+		},
+		{
+			ScenarioName: "one stream",
+			ClientResult: &runner.Result{Metrics: map[string]interface{}{"intervals": intervals(1, 2)}},
+		},
+		{ScenarioName: "rdma", ClientResult: &runner.Result{Metrics: map[string]interface{}{"bandwidth_mbps": 1.0}}},
+		{ScenarioName: "failed"},
+	}
+
+	var buf bytes.Buffer
+	rows, err := WriteIntervalCSV(&buf, results, "")
+	if err != nil {
+		t.Fatalf("WriteIntervalCSV failed: %v", err)
+	}
+	if rows != 8 {
+		t.Errorf("Expected 8 data rows, got %d", rows)
+	}
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("Output is not valid CSV: %v", err)
+	}
+	if len(records) != 9 {
+		t.Fatalf("Expected header + 8 rows, got %d records", len(records))
+	}
+	if got := records[0]; len(got) != 5 || got[0] != "scenario" || got[4] != "bits_per_second" {
+		t.Errorf("Unexpected header: %v", got)
+	}
+	if got := records[2]; got[0] != "two streams" || got[1] != "7" || got[2] != "0" || got[3] != "1" || got[4] != "9400000000" {
+		t.Errorf("Unexpected row: %v", got)
+	}
+}
+
+func TestWriteIntervalCSV_BandwidthUnit(t *testing.T) {
+	results := []*coordinator.TestResult{{
+		ScenarioName: "tcp",
+		ClientResult: &runner.Result{Metrics: map[string]interface{}{"intervals": []map[string]interface{}{
+			{"stream": 5, "start": 0.0, "end": 1.0, "bits_per_second": 1e9},
+		}}},
+	}}
+
+	var buf bytes.Buffer
+	if _, err := WriteIntervalCSV(&buf, results, "MBps"); err != nil {
+		t.Fatalf("WriteIntervalCSV failed: %v", err)
+	}
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("Output is not valid CSV: %v", err)
+	}
+	if records[0][4] != "bandwidth_MBps" || records[1][4] != "125" {
+		t.Errorf("Expected 1e9 bps as 125 MBps, got header %v row %v", records[0], records[1])
+	}
+	if intervalCSVHeader[4] != "bits_per_second" {
+		t.Error("Expected the shared header to be left unchanged")
+	}
+}
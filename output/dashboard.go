@@ -0,0 +1,42 @@
+package output
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"perf-runner/coordinator"
+)
+
+// NewDashboardHandler serves a live HTML dashboard at "/" and the run status
+// as JSON at "/status". The page polls the status endpoint and reloads when
+// another scenario completes.
+func NewDashboardHandler(status func() coordinator.Status) http.Handler {
+	mux := http.NewServeMux()
+	
+	mux.HandleFunc("/status", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(status()); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+	
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		
+		current := status()
+		data := newReportData(current.Name, current.TotalTests, current.Results)
+		data.Current = current.CurrentTest
+		data.Done = current.Done
+		data.Live = true
+		
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if err := renderHTMLReport(w, data); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+	
+	return mux
+}
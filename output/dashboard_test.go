@@ -0,0 +1,85 @@
+package output
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"perf-runner/coordinator"
+	"perf-runner/runner"
+)
+
+func TestDashboard_ServesCompletedScenarios(t *testing.T) {
+	status := coordinator.Status{
+		Name:       "nightly",
+		TotalTests: 2,
+		Done:       true,
+		Results: []*coordinator.TestResult{
+			{
+				ScenarioName:  "tcp single stream",
+				Success:       true,
+				ClientResult:  &runner.Result{Success: true, Metrics: map[string]interface{}{"bandwidth_gbps": 9.41}},
+				PrimaryMetric: "bandwidth_gbps",
+			},
+			{ScenarioName: "tcp <parallel>", Success: false, Error: "client failed"},
+		},
+	}
+
+	server := httptest.NewServer(NewDashboardHandler(func() coordinator.Status { return status }))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/")
+	if err != nil {
+		t.Fatalf("GET / failed: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	page := string(body)
+
+	if count := strings.Count(page, `<tr class="scenario">`); count != 2 {
+		t.Errorf("Expected 2 scenario rows, got %d:\n%s", count, page)
+	}
+	for _, want := range []string{"tcp single stream", "bandwidth_gbps: 9.41", "tcp &lt;parallel&gt;", "client failed", "Completed: 2/2"} {
+		if !strings.Contains(page, want) {
+			t.Errorf("Expected page to contain %q", want)
+		}
+	}
+	if strings.Contains(page, "setInterval") {
+		t.Error("A finished run should not keep polling")
+	}
+
+	resp, err = http.Get(server.URL + "/status")
+	if err != nil {
+		t.Fatalf("GET /status failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var decoded coordinator.Status
+	if err := json.NewDecoder(resp.Body).Decode(&decoded); err != nil {
+		t.Fatalf("Failed to decode status: %v", err)
+	}
+	if !decoded.Done || len(decoded.Results) != 2 {
+		t.Errorf("Unexpected status: done=%v results=%d", decoded.Done, len(decoded.Results))
+	}
+}
+
+func TestDashboard_PollsWhileRunning(t *testing.T) {
+	status := coordinator.Status{Name: "nightly", TotalTests: 3, CurrentTest: "rdma"}
+
+	server := httptest.NewServer(NewDashboardHandler(func() coordinator.Status { return status }))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/")
+	if err != nil {
+		t.Fatalf("GET / failed: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	if !strings.Contains(string(body), "setInterval") || !strings.Contains(string(body), "Running: rdma") {
+		t.Errorf("Expected a polling page showing the running scenario:\n%s", body)
+	}
+}
@@ -0,0 +1,99 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"perf-runner/coordinator"
+	"perf-runner/envinfo"
+)
+
+// hostEnvironment is one host's collected environment, flattened to sorted
+// "module.key" entries
+type hostEnvironment struct {
+	Host    string
+	Entries []envEntry
+}
+
+// envEntry is one flattened environment value
+type envEntry struct {
+	Key   string
+	Value string
+}
+
+// WriteEnvFlat writes each host's collected environment as sorted
+// "host.module.key=value" lines, e.g. "server1.cpu.model=Xeon", which are
+// easier to grep and diff than the nested JSON. Hosts appear in the order
+// they were first seen; later results for the same host are skipped. It
+// returns the number of hosts written.
+func WriteEnvFlat(w io.Writer, results []*coordinator.TestResult) (int, error) {
+	hosts, err := hostEnvironments(results)
+	for written, host := range hosts {
+		for _, entry := range host.Entries {
+			// Keep multi-line values (e.g. version banners) on one line
+			value := strings.ReplaceAll(entry.Value, "\n", `\n`)
+			if _, err := fmt.Fprintf(w, "%s.%s=%s\n", host.Host, entry.Key, value); err != nil {
+				return written, err
+			}
+		}
+	}
+	return len(hosts), err
+}
+
+// hostEnvironments flattens the environment of every host that has one, in
+// the order hosts were first seen; later results for the same host are
+// skipped. On error it returns the hosts flattened so far.
+func hostEnvironments(results []*coordinator.TestResult) ([]hostEnvironment, error) {
+	var hosts []hostEnvironment
+	written := make(map[string]bool)
+	for _, result := range results {
+		if result.EnvironmentInfo == nil {
+			continue
+		}
+
+		roles := []struct {
+			role string
+			env  *envinfo.EnvironmentInfo
+		}{
+			{"client", result.EnvironmentInfo.ClientEnv},
+			{"server", result.EnvironmentInfo.ServerEnv},
+			{"intermediate", result.EnvironmentInfo.IntermediateEnv},
+		}
+		relayRoles := make([]string, 0, len(result.EnvironmentInfo.RelayEnvs))
+		for role := range result.EnvironmentInfo.RelayEnvs {
+			relayRoles = append(relayRoles, role)
+		}
+		sort.Strings(relayRoles)
+		for _, role := range relayRoles {
+			roles = append(roles, struct {
+				role string
+				env  *envinfo.EnvironmentInfo
+			}{role, result.EnvironmentInfo.RelayEnvs[role]})
+		}
+		for _, r := range roles {
+			host := result.Hosts[r.role]
+			if r.env == nil || host == "" || written[host] {
+				continue
+			}
+			written[host] = true
+
+			flat, err := envinfo.FlattenModules(r.env.Modules())
+			if err != nil {
+				return hosts, fmt.Errorf("host %s: %w", host, err)
+			}
+			keys := make([]string, 0, len(flat))
+			for key := range flat {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			entries := make([]envEntry, 0, len(keys))
+			for _, key := range keys {
+				entries = append(entries, envEntry{Key: key, Value: flat[key]})
+			}
+			hosts = append(hosts, hostEnvironment{Host: host, Entries: entries})
+		}
+	}
+	return hosts, nil
+}
@@ -0,0 +1,38 @@
+package output
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"perf-runner/coordinator"
+	"perf-runner/envinfo"
+)
+
+func TestWriteEnvFlat(t *testing.T) {
+	server := &envinfo.EnvironmentInfo{Hostname: "srv", CPUInfo: envinfo.CPUInfo{Model: "Xeon"}, OSInfo: "Ubuntu\n24.04"}
+	results := []*coordinator.TestResult{
+		{Hosts: map[string]string{"client": "client1", "server": "server1"}, EnvironmentInfo: &coordinator.EnvironmentData{ServerEnv: server}},
+		{Hosts: map[string]string{"client": "client1", "server": "server1"}, EnvironmentInfo: &coordinator.EnvironmentData{ServerEnv: server}},
+		{Hosts: map[string]string{"server": "server2"}},
+	}
+
+	var buf bytes.Buffer
+	hosts, err := WriteEnvFlat(&buf, results)
+	if err != nil {
+		t.Fatalf("WriteEnvFlat returned error: %v", err)
+	}
+	if hosts != 1 {
+		t.Errorf("Expected 1 host written once, got %d", hosts)
+	}
+
+	out := buf.String()
+	for _, line := range []string{"server1.cpu.model=Xeon\n", "server1.system.hostname=srv\n", "server1.system.os_info=Ubuntu\\n24.04\n"} {
+		if !strings.Contains(out, line) {
+			t.Errorf("Expected line %q in output:\n%s", line, out)
+		}
+	}
+	if strings.Count(out, "server1.cpu.model=") != 1 {
+		t.Error("Expected a host shared by several results to be written once")
+	}
+}
@@ -3,7 +3,9 @@ package output
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
+	"sort"
 	"strings"
 	"time"
 
@@ -11,22 +13,171 @@ import (
 	"perf-runner/runner"
 )
 
+// ANSI escape sequences used for colored text output
+const (
+	ansiGreen = "\033[32m"
+	ansiRed   = "\033[31m"
+	ansiReset = "\033[0m"
+)
+
+// Color modes accepted by ResolveColorMode
+const (
+	ColorAuto   = "auto"
+	ColorAlways = "always"
+	ColorNever  = "never"
+)
+
+// Result formats accepted by ResolveFormat
+const (
+	FormatText       = "text"
+	FormatJSON       = "json"
+	FormatHTML       = "html"
+	FormatPrometheus = "prometheus"
+)
+
 // Formatter handles result output formatting
 type Formatter struct {
-	jsonOutput bool
+	format       string
+	title        string
+	color        bool
+	comparison   bool
+	sizeTables   bool
+	failuresOnly bool
+	bwUnit       string
+	connections  []coordinator.HostConnection
+	baseline     *BaselineComparison
+	out          io.Writer
 }
 
 // NewFormatter creates a new output formatter
 func NewFormatter(jsonOutput bool) *Formatter {
+	format := FormatText
+	if jsonOutput {
+		format = FormatJSON
+	}
 	return &Formatter{
-		jsonOutput: jsonOutput,
+		format: format,
+		title:  "Test Results",
+		out:    os.Stdout,
 	}
 }
 
+// SetFormat selects the result format, one of those accepted by ResolveFormat
+func (f *Formatter) SetFormat(format string) {
+	f.format = format
+}
+
+// SetTitle sets the heading of the HTML report
+func (f *Formatter) SetTitle(title string) {
+	f.title = title
+}
+
+// SetOutput sets where results are written (stdout by default)
+func (f *Formatter) SetOutput(w io.Writer) {
+	f.out = w
+}
+
+// SetColor enables or disables ANSI colors in text output
+func (f *Formatter) SetColor(enabled bool) {
+	f.color = enabled
+}
+
+// SetComparison adds the comparison matrix of scenarios tagged with a
+// comparison_group to the output
+func (f *Formatter) SetComparison(enabled bool) {
+	f.comparison = enabled
+}
+
+// SetSizeTables adds a table of the primary metric by message size for
+// comparison groups and sweeps that vary the message size
+func (f *Formatter) SetSizeTables(enabled bool) {
+	f.sizeTables = enabled
+}
+
+// SetFailuresOnly limits the per-scenario details in text output to failed
+// scenarios; the summary still counts every scenario, and JSON output is
+// unaffected
+func (f *Formatter) SetFailuresOnly(enabled bool) {
+	f.failuresOnly = enabled
+}
+
+// SetBandwidthUnit shows bandwidth metrics in text output in unit (mbps,
+// gbps, MBps, or GBps), converted from their canonical bits per second.
+// An empty unit shows metrics as the runner reported them.
+func (f *Formatter) SetBandwidthUnit(unit string) {
+	f.bwUnit = unit
+}
+
+// SetConnections adds the per-host connection report, as returned by
+// Coordinator.ConnectionReport, to the output. A nil report is omitted.
+func (f *Formatter) SetConnections(report []coordinator.HostConnection) {
+	f.connections = report
+}
+
+// SetBaseline adds the comparison against a baseline run, as returned by
+// CompareBaseline, to text and JSON output. A nil comparison is omitted.
+func (f *Formatter) SetBaseline(comparison *BaselineComparison) {
+	f.baseline = comparison
+}
+
+// ResolveColorMode decides whether to use color for the given mode.
+// In auto mode color is used only when out is a terminal and NO_COLOR is unset.
+func ResolveColorMode(mode string, out *os.File) (bool, error) {
+	switch mode {
+	case ColorAlways:
+		return true, nil
+	case ColorNever:
+		return false, nil
+	case ColorAuto, "":
+		if os.Getenv("NO_COLOR") != "" {
+			return false, nil
+		}
+		return isTerminal(out), nil
+	default:
+		return false, fmt.Errorf("invalid color mode %q, must be '%s', '%s', or '%s'", mode, ColorAuto, ColorAlways, ColorNever)
+	}
+}
+
+// ResolveFormat returns the result format selected by format, where -json
+// is shorthand for the json format. An empty format means text.
+func ResolveFormat(format string, jsonOutput bool) (string, error) {
+	switch format {
+	case "":
+		if jsonOutput {
+			return FormatJSON, nil
+		}
+		return FormatText, nil
+	case FormatText, FormatJSON, FormatHTML, FormatPrometheus:
+		if jsonOutput && format != FormatJSON {
+			return "", fmt.Errorf("-json conflicts with output format %q", format)
+		}
+		return format, nil
+	default:
+		return "", fmt.Errorf("invalid output format %q, must be '%s', '%s', '%s', or '%s'", format, FormatText, FormatJSON, FormatHTML, FormatPrometheus)
+	}
+}
+
+// isTerminal reports whether the file is a character device such as a TTY
+func isTerminal(out *os.File) bool {
+	if out == nil {
+		return false
+	}
+	info, err := out.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
 // OutputResults outputs test results in the requested format
 func (f *Formatter) OutputResults(results []*coordinator.TestResult, totalDuration time.Duration) error {
-	if f.jsonOutput {
+	switch f.format {
+	case FormatJSON:
 		return f.outputJSON(results, totalDuration)
+	case FormatHTML:
+		return f.outputHTML(results, totalDuration)
+	case FormatPrometheus:
+		return f.outputPrometheus(results, totalDuration)
 	}
 	return f.outputText(results, totalDuration)
 }
@@ -50,10 +201,49 @@ func (f *Formatter) outputJSON(results []*coordinator.TestResult, totalDuration
 		if result.ServerCommand != "" {
 			enhancedResult["server_command"] = result.ServerCommand
 		}
+		if len(result.CommandHashes) > 0 {
+			enhancedResult["command_hashes"] = result.CommandHashes
+		}
+		if len(result.ToolVersions) > 0 {
+			enhancedResult["tool_versions"] = result.ToolVersions
+		}
+		if len(result.Audit) > 0 {
+			enhancedResult["audit"] = result.Audit
+		}
 		
 		if result.Error != "" {
 			enhancedResult["error"] = result.Error
 		}
+		if len(result.Warnings) > 0 {
+			enhancedResult["warnings"] = result.Warnings
+		}
+		if len(result.Hosts) > 0 {
+			enhancedResult["hosts"] = result.Hosts
+		}
+		if len(result.ResolvedAddresses) > 0 {
+			enhancedResult["resolved_addresses"] = result.ResolvedAddresses
+		}
+		if result.Skipped {
+			enhancedResult["skipped"] = true
+		}
+		if result.FallbackUsed {
+			enhancedResult["fallback_used"] = true
+		}
+		if result.Attempts > 1 {
+			enhancedResult["attempts"] = result.Attempts
+		}
+		if result.Autotune != nil {
+			enhancedResult["autotune"] = result.Autotune
+		}
+		if result.ErrorClass != "" {
+			enhancedResult["error_class"] = result.ErrorClass
+		}
+		if result.ExpectFail {
+			enhancedResult["expect_fail"] = true
+		}
+		if len(result.Load) > 0 {
+			enhancedResult["load"] = result.Load
+		}
 		
 		if result.ClientResult != nil {
 			clientInfo := map[string]interface{}{
@@ -91,10 +281,56 @@ func (f *Formatter) outputJSON(results []*coordinator.TestResult, totalDuration
 			if len(result.ServerResult.Metrics) > 0 {
 				serverInfo["metrics"] = result.ServerResult.Metrics
 			}
+			if result.ServerResult.TerminatedByUs {
+				serverInfo["terminated_by_us"] = true
+			}
 			
 			enhancedResult["server_result"] = serverInfo
 		}
 		
+		if len(result.ClientResults) > 0 {
+			fanOutInfo := make(map[string]interface{}, len(result.ClientResults))
+			for host, clientResult := range result.ClientResults {
+				info := map[string]interface{}{
+					"success":   clientResult.Success,
+					"exit_code": clientResult.ExitCode,
+				}
+				if clientResult.Error != "" {
+					info["error"] = clientResult.Error
+				}
+				if len(clientResult.Metrics) > 0 {
+					info["metrics"] = clientResult.Metrics
+				}
+				fanOutInfo[host] = info
+			}
+			enhancedResult["client_results"] = fanOutInfo
+		}
+		
+		if len(result.NodeResults) > 0 {
+			nodes := make([]map[string]interface{}, 0, len(result.NodeResults))
+			for _, node := range result.NodeResults {
+				info := map[string]interface{}{
+					"host": node.Host,
+					"role": node.Role,
+				}
+				if node.Command != "" {
+					info["command"] = node.Command
+				}
+				if node.Result != nil {
+					info["success"] = node.Result.Success
+					info["exit_code"] = node.Result.ExitCode
+					if node.Result.Error != "" {
+						info["error"] = node.Result.Error
+					}
+					if len(node.Result.Metrics) > 0 {
+						info["metrics"] = node.Result.Metrics
+					}
+				}
+				nodes = append(nodes, info)
+			}
+			enhancedResult["node_results"] = nodes
+		}
+		
 		enhancedResults[i] = enhancedResult
 	}
 	
@@ -103,112 +339,338 @@ func (f *Formatter) outputJSON(results []*coordinator.TestResult, totalDuration
 		"total_tests":    len(results),
 		"passed":         f.countPassed(results),
 		"failed":         f.countFailed(results),
+		"skipped":        countSkipped(results),
 		"results":        enhancedResults,
 	}
+	if rankings := rankByMetric(results); len(rankings) > 0 {
+		best := make([]map[string]interface{}, len(rankings))
+		worst := make([]map[string]interface{}, len(rankings))
+		for i, ranking := range rankings {
+			best[i] = summaryEntry(ranking.best)
+			worst[i] = summaryEntry(ranking.worst)
+		}
+		output["best"] = best
+		output["worst"] = worst
+	}
+	if f.comparison {
+		output["comparison"] = newComparisonMatrix(results)
+	}
+	if f.sizeTables {
+		output["size_tables"] = newSizeTables(results)
+	}
+	if f.connections != nil {
+		output["connections"] = f.connections
+	}
+	if f.baseline != nil {
+		output["baseline_comparison"] = f.baseline
+	}
 	
-	encoder := json.NewEncoder(os.Stdout)
+	encoder := json.NewEncoder(f.out)
 	encoder.SetIndent("", "  ")
 	return encoder.Encode(output)
 }
 
 // outputText outputs results in human-readable text format
 func (f *Formatter) outputText(results []*coordinator.TestResult, totalDuration time.Duration) error {
-	fmt.Printf("\n=== Test Results ===\n")
-	fmt.Printf("Total Duration: %v\n", totalDuration)
-	fmt.Printf("Total Tests: %d\n", len(results))
-	fmt.Printf("Passed: %d\n", f.countPassed(results))
-	fmt.Printf("Failed: %d\n", f.countFailed(results))
-	fmt.Println()
+	fmt.Fprintf(f.out, "\n=== Test Results ===\n")
+	fmt.Fprintf(f.out, "Total Duration: %v\n", totalDuration)
+	fmt.Fprintf(f.out, "Total Tests: %d\n", len(results))
+	fmt.Fprintf(f.out, "Passed: %d\n", f.countPassed(results))
+	fmt.Fprintf(f.out, "Failed: %d\n", f.countFailed(results))
+	if skipped := countSkipped(results); skipped > 0 {
+		fmt.Fprintf(f.out, "Skipped (hosts unreachable): %d of the failed\n", skipped)
+	}
+	for _, ranking := range rankByMetric(results) {
+		bestValue, _ := ranking.best.PrimaryValue()
+		worstValue, _ := ranking.worst.PrimaryValue()
+		fmt.Fprintf(f.out, "Best: %s (%s: %s)\n", ranking.best.ScenarioName, ranking.metric, f.formatSummaryValue(ranking.best, bestValue))
+		fmt.Fprintf(f.out, "Worst: %s (%s: %s)\n", ranking.worst.ScenarioName, ranking.metric, f.formatSummaryValue(ranking.worst, worstValue))
+	}
+	fmt.Fprintln(f.out)
+	
+	if f.comparison {
+		fmt.Fprintf(f.out, "=== Comparison ===\n")
+		if err := writeComparisonTable(f.out, newComparisonMatrix(results)); err != nil {
+			return err
+		}
+		fmt.Fprintln(f.out)
+	}
+	
+	if f.sizeTables {
+		if tables := newSizeTables(results); len(tables) > 0 {
+			fmt.Fprintf(f.out, "=== By Message Size ===\n")
+			if err := writeSizeTables(f.out, tables); err != nil {
+				return err
+			}
+			fmt.Fprintln(f.out)
+		}
+	}
+	
+	if f.baseline != nil {
+		fmt.Fprintf(f.out, "=== Baseline Comparison (%s) ===\n", f.baseline.Path)
+		if err := f.writeBaselineComparison(f.out, f.baseline); err != nil {
+			return err
+		}
+		fmt.Fprintln(f.out)
+	}
+	
+	if f.connections != nil {
+		fmt.Fprintf(f.out, "=== Connections ===\n")
+		writeConnections(f.out, f.connections)
+		fmt.Fprintln(f.out)
+	}
 	
 	for i, result := range results {
-		fmt.Printf("%d. %s\n", i+1, result.ScenarioName)
-		fmt.Printf("   Status: %s\n", f.getStatusString(result.Success))
-		fmt.Printf("   Duration: %v\n", result.Duration)
+		if f.failuresOnly && result.Success {
+			continue
+		}
+		
+		fmt.Fprintf(f.out, "%d. %s\n", i+1, result.ScenarioName)
+		status := f.getStatusString(result.Success)
+		if label := expectFailLabel(result); label != "" {
+			status += " (" + label + ")"
+		}
+		if result.Skipped {
+			status += " (skipped)"
+		}
+		fmt.Fprintf(f.out, "   Status: %s\n", status)
+		fmt.Fprintf(f.out, "   Duration: %v\n", result.Duration)
 		
 		if result.Error != "" {
-			fmt.Printf("   Error: %s\n", result.Error)
+			fmt.Fprintf(f.out, "   Error: %s\n", result.Error)
+		}
+		if result.Attempts > 1 {
+			fmt.Fprintf(f.out, "   Attempts: %d\n", result.Attempts)
+		}
+		if result.Autotune != nil {
+			fmt.Fprintf(f.out, "   Autotune: best %s=%d\n", result.Autotune.Arg, result.Autotune.Best)
+			for _, step := range result.Autotune.Steps {
+				fmt.Fprintf(f.out, "     %s=%d: %s %.2f\n", result.Autotune.Arg, step.Value, result.PrimaryMetric, step.Metric)
+			}
+		}
+		for _, warning := range result.Warnings {
+			fmt.Fprintf(f.out, "   Warning: %s\n", warning)
+		}
+		if result.FallbackUsed {
+			fmt.Fprintf(f.out, "   Fallback Hosts: client=%s server=%s\n", result.Hosts["client"], result.Hosts["server"])
 		}
 		
 		if result.ClientResult != nil {
-			fmt.Printf("   Client: %s\n", f.getStatusString(result.ClientResult.Success))
+			fmt.Fprintf(f.out, "   Client: %s\n", f.getStatusString(result.ClientResult.Success))
 			
 			// Show client command
 			if result.ClientCommand != "" {
-				fmt.Printf("   Client Command: %s\n", result.ClientCommand)
+				fmt.Fprintf(f.out, "   Client Command: %s\n", result.ClientCommand)
 			}
 			
 			// Always show client output if available
 			if result.ClientResult.Output != "" {
-				fmt.Printf("   Client Output:\n")
+				fmt.Fprintf(f.out, "   Client Output:\n")
 				lines := strings.Split(result.ClientResult.Output, "\n")
 				for _, line := range lines {
 					if strings.TrimSpace(line) != "" {
-						fmt.Printf("     %s\n", line)
+						fmt.Fprintf(f.out, "     %s\n", line)
 					}
 				}
 			}
 			
 			// Show metrics for successful runs
 			if result.ClientResult.Success && len(result.ClientResult.Metrics) > 0 {
-				fmt.Printf("   Client Metrics:\n")
+				fmt.Fprintf(f.out, "   Client Metrics:\n")
 				for k, v := range result.ClientResult.Metrics {
-					fmt.Printf("     %s: %v\n", k, v)
+					// Row-valued metrics (sizes, intervals) are too long to inline
+					if rows, ok := v.([]map[string]interface{}); ok {
+						fmt.Fprintf(f.out, "     %s: %d rows\n", k, len(rows))
+						continue
+					}
+					if text, show := f.formatMetric(result.ClientResult.Metrics, k, v); show {
+						fmt.Fprintf(f.out, "     %s: %s\n", k, text)
+					}
 				}
 			}
 			
 			// Show detailed error info for failed runs
 			if !result.ClientResult.Success {
 				if result.ClientResult.Error != "" {
-					fmt.Printf("   Client Error: %s\n", result.ClientResult.Error)
+					fmt.Fprintf(f.out, "   Client Error: %s\n", result.ClientResult.Error)
 				}
 				if result.ClientResult.ExitCode != 0 {
-					fmt.Printf("   Client Exit Code: %d\n", result.ClientResult.ExitCode)
+					fmt.Fprintf(f.out, "   Client Exit Code: %d\n", result.ClientResult.ExitCode)
 				}
 			}
 		}
 		
+		for _, host := range sortedKeys(result.ClientResults) {
+			clientResult := result.ClientResults[host]
+			fmt.Fprintf(f.out, "   Client %s: %s\n", host, f.getStatusString(clientResult.Success))
+			if !clientResult.Success && clientResult.Error != "" {
+				fmt.Fprintf(f.out, "   Client %s Error: %s\n", host, clientResult.Error)
+			}
+		}
+		
 		if result.ServerResult != nil {
-			fmt.Printf("   Server: %s\n", f.getStatusString(result.ServerResult.Success))
+			if result.ServerResult.TerminatedByUs {
+				fmt.Fprintf(f.out, "   Server: %s (terminated after client completed)\n", f.getStatusString(true))
+			} else {
+				fmt.Fprintf(f.out, "   Server: %s\n", f.getStatusString(result.ServerResult.Success))
+			}
 			
 			// Show server command
 			if result.ServerCommand != "" {
-				fmt.Printf("   Server Command: %s\n", result.ServerCommand)
+				fmt.Fprintf(f.out, "   Server Command: %s\n", result.ServerCommand)
 			}
 			
 			// Always show server output if available
 			if result.ServerResult.Output != "" {
-				fmt.Printf("   Server Output:\n")
+				fmt.Fprintf(f.out, "   Server Output:\n")
 				lines := strings.Split(result.ServerResult.Output, "\n")
 				for _, line := range lines {
 					if strings.TrimSpace(line) != "" {
-						fmt.Printf("     %s\n", line)
+						fmt.Fprintf(f.out, "     %s\n", line)
 					}
 				}
 			}
 			
 			// Show detailed error info for failed runs
-			if !result.ServerResult.Success {
+			if !result.ServerResult.Success && !result.ServerResult.TerminatedByUs {
 				if result.ServerResult.Error != "" {
-					fmt.Printf("   Server Error: %s\n", result.ServerResult.Error)
+					fmt.Fprintf(f.out, "   Server Error: %s\n", result.ServerResult.Error)
 				}
 				if result.ServerResult.ExitCode != 0 {
-					fmt.Printf("   Server Exit Code: %d\n", result.ServerResult.ExitCode)
+					fmt.Fprintf(f.out, "   Server Exit Code: %d\n", result.ServerResult.ExitCode)
 				}
 			}
 		}
 		
-		fmt.Println()
+		fmt.Fprintln(f.out)
 	}
 	
 	return nil
 }
 
-// getStatusString returns a colored status string
+// formatMetric renders a client metric for text output. With a bandwidth
+// unit selected, bandwidth metrics are converted to it, and unit variants
+// such as bandwidth_mbps are hidden when the canonical _bps metric exists,
+// since they would repeat it. show is false for hidden metrics.
+func (f *Formatter) formatMetric(metrics map[string]interface{}, key string, value interface{}) (text string, show bool) {
+	if f.bwUnit == "" {
+		return fmt.Sprint(value), true
+	}
+	base, scale, isBandwidth := bandwidthMetric(key)
+	number, isNumber := metricFloat(value)
+	if !isBandwidth || !isNumber {
+		return fmt.Sprint(value), true
+	}
+	if _, hasCanonical := metrics[base+"_bps"]; hasCanonical && scale != 1 {
+		return "", false
+	}
+	return formatBandwidth(number*scale, f.bwUnit), true
+}
+
+// formatSummaryValue renders a result's primary metric value for the
+// summary, converting bandwidth metrics to the selected unit. The canonical
+// _bps metric is converted when the result has one, since unit variants may
+// not hold what their suffix says: perftest's bandwidth_average_mbps is in
+// MB/s, not Mbit/s.
+func (f *Formatter) formatSummaryValue(result *coordinator.TestResult, value float64) string {
+	base, scale, isBandwidth := bandwidthMetric(result.PrimaryMetric)
+	if !isBandwidth || f.bwUnit == "" {
+		return fmt.Sprintf("%.2f", value)
+	}
+	if result.ClientResult != nil {
+		if bps, ok := metricFloat(result.ClientResult.Metrics[base+"_bps"]); ok {
+			return formatBandwidth(bps, f.bwUnit)
+		}
+	}
+	return formatBandwidth(value*scale, f.bwUnit)
+}
+
+// metricRanking is the best and worst successful result among those with
+// the same primary metric
+type metricRanking struct {
+	metric                string
+	best, worst           *coordinator.TestResult
+	bestValue, worstValue float64
+}
+
+// rankByMetric returns the best and worst successful result for each primary
+// metric, in the order the metrics first appear. Results are only ranked
+// against others reporting the same metric, since e.g. a bandwidth and a
+// latency are not comparable. Higher is better except for latency metrics.
+func rankByMetric(results []*coordinator.TestResult) []*metricRanking {
+	var rankings []*metricRanking
+	byMetric := make(map[string]*metricRanking)
+	for _, result := range results {
+		if !result.Success {
+			continue
+		}
+		value, ok := result.PrimaryValue()
+		if !ok {
+			continue
+		}
+		if runner.LowerIsBetter(result.PrimaryMetric) {
+			value = -value
+		}
+		
+		ranking := byMetric[result.PrimaryMetric]
+		if ranking == nil {
+			ranking = &metricRanking{metric: result.PrimaryMetric}
+			byMetric[result.PrimaryMetric] = ranking
+			rankings = append(rankings, ranking)
+		}
+		if ranking.best == nil || value > ranking.bestValue {
+			ranking.best, ranking.bestValue = result, value
+		}
+		if ranking.worst == nil || value < ranking.worstValue {
+			ranking.worst, ranking.worstValue = result, value
+		}
+	}
+	return rankings
+}
+
+// summaryEntry describes a best/worst result for JSON output
+func summaryEntry(result *coordinator.TestResult) map[string]interface{} {
+	value, _ := result.PrimaryValue()
+	return map[string]interface{}{
+		"scenario_name": result.ScenarioName,
+		"metric":        result.PrimaryMetric,
+		"value":         value,
+	}
+}
+
+// sortedKeys returns the keys of a fan-out result map in a stable order
+func sortedKeys(results map[string]*runner.Result) []string {
+	keys := make([]string, 0, len(results))
+	for key := range results {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// getStatusString returns a status string, colored when color is enabled
 func (f *Formatter) getStatusString(success bool) string {
+	status, color := "✗ FAIL", ansiRed
 	if success {
-		return "✓ PASS"
+		status, color = "✓ PASS", ansiGreen
+	}
+	if !f.color {
+		return status
+	}
+	return color + status + ansiReset
+}
+
+// expectFailLabel describes the outcome of an expect_fail scenario, or
+// returns "" for ordinary scenarios
+func expectFailLabel(result *coordinator.TestResult) string {
+	switch {
+	case !result.ExpectFail:
+		return ""
+	case result.Success:
+		return "failed as expected"
+	default:
+		return "unexpectedly succeeded"
 	}
-	return "✗ FAIL"
 }
 
 // countPassed counts the number of passed tests
@@ -233,22 +695,33 @@ func (f *Formatter) countFailed(results []*coordinator.TestResult) int {
 	return count
 }
 
+// countSkipped counts the tests not run because their hosts were unreachable
+func countSkipped(results []*coordinator.TestResult) int {
+	count := 0
+	for _, result := range results {
+		if result.Skipped {
+			count++
+		}
+	}
+	return count
+}
+
 // outputCommandDetails outputs detailed failure information for a command
 func (f *Formatter) outputCommandDetails(role string, result *runner.Result) {
 	if result.Error != "" {
-		fmt.Printf("     %s Error: %s\n", role, result.Error)
+		fmt.Fprintf(f.out, "     %s Error: %s\n", role, result.Error)
 	}
 	
 	if result.ExitCode != 0 {
-		fmt.Printf("     %s Exit Code: %d\n", role, result.ExitCode)
+		fmt.Fprintf(f.out, "     %s Exit Code: %d\n", role, result.ExitCode)
 	}
 	
 	if result.Output != "" {
-		fmt.Printf("     %s Output:\n", role)
+		fmt.Fprintf(f.out, "     %s Output:\n", role)
 		lines := strings.Split(result.Output, "\n")
 		for _, line := range lines {
 			if strings.TrimSpace(line) != "" {
-				fmt.Printf("       %s\n", line)
+				fmt.Fprintf(f.out, "       %s\n", line)
 			}
 		}
 	}
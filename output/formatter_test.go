@@ -0,0 +1,448 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	"perf-runner/coordinator"
+	"perf-runner/runner"
+)
+
+func TestFormatter_StatusStringColor(t *testing.T) {
+	formatter := NewFormatter(false)
+
+	for _, success := range []bool{true, false} {
+		if status := formatter.getStatusString(success); strings.Contains(status, "\033[") {
+			t.Errorf("Expected no ANSI codes with color off, got %q", status)
+		}
+	}
+
+	formatter.SetColor(true)
+
+	if status := formatter.getStatusString(true); !strings.HasPrefix(status, ansiGreen) || !strings.HasSuffix(status, ansiReset) {
+		t.Errorf("Expected green PASS with color on, got %q", status)
+	}
+	if status := formatter.getStatusString(false); !strings.HasPrefix(status, ansiRed) || !strings.HasSuffix(status, ansiReset) {
+		t.Errorf("Expected red FAIL with color on, got %q", status)
+	}
+}
+
+func TestResolveColorMode(t *testing.T) {
+	// A regular file is never a terminal
+	file, err := os.CreateTemp(t.TempDir(), "out")
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	defer file.Close()
+
+	tests := []struct {
+		mode     string
+		expected bool
+		wantErr  bool
+	}{
+		{ColorAlways, true, false},
+		{ColorNever, false, false},
+		{ColorAuto, false, false},
+		{"", false, false},
+		{"rainbow", false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			enabled, err := ResolveColorMode(tt.mode, file)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ResolveColorMode(%q) error = %v, wantErr %v", tt.mode, err, tt.wantErr)
+			}
+			if enabled != tt.expected {
+				t.Errorf("ResolveColorMode(%q) = %v, expected %v", tt.mode, enabled, tt.expected)
+			}
+		})
+	}
+}
+
+func TestResolveFormat(t *testing.T) {
+	tests := []struct {
+		format   string
+		json     bool
+		expected string
+		wantErr  bool
+	}{
+		{"", false, FormatText, false},
+		{"", true, FormatJSON, false},
+		{FormatHTML, false, FormatHTML, false},
+		{FormatJSON, true, FormatJSON, false},
+		{FormatHTML, true, "", true},
+		{"pdf", false, "", true},
+	}
+
+	for _, tt := range tests {
+		format, err := ResolveFormat(tt.format, tt.json)
+		if (err != nil) != tt.wantErr {
+			t.Fatalf("ResolveFormat(%q, %v) error = %v, wantErr %v", tt.format, tt.json, err, tt.wantErr)
+		}
+		if format != tt.expected {
+			t.Errorf("ResolveFormat(%q, %v) = %q, expected %q", tt.format, tt.json, format, tt.expected)
+		}
+	}
+}
+
+func TestRankByMetric(t *testing.T) {
+	withMetric := func(name string, success bool, value float64) *coordinator.TestResult {
+		return &coordinator.TestResult{
+			ScenarioName:  name,
+			Success:       success,
+			PrimaryMetric: "bandwidth_mbps",
+			ClientResult:  &runner.Result{Success: success, Metrics: map[string]interface{}{"bandwidth_mbps": value}},
+		}
+	}
+	results := []*coordinator.TestResult{
+		withMetric("slow", true, 900),
+		withMetric("fast", true, 9400),
+		withMetric("failed", false, 20000),
+		{ScenarioName: "no metrics", Success: true},
+	}
+
+	rankings := rankByMetric(results)
+	if len(rankings) != 1 {
+		t.Fatalf("Expected one ranking, got %d", len(rankings))
+	}
+	if best := rankings[0].best; best.ScenarioName != "fast" {
+		t.Errorf("Expected best to be fast, got %+v", best)
+	}
+	if worst := rankings[0].worst; worst.ScenarioName != "slow" {
+		t.Errorf("Expected worst to be slow, got %+v", worst)
+	}
+
+	if rankings := rankByMetric(results[2:]); len(rankings) != 0 {
+		t.Errorf("Expected no rankings without successful metrics, got %v", rankings)
+	}
+}
+
+func TestRankByMetric_LowerLatencyIsBetter(t *testing.T) {
+	withLatency := func(name string, value float64) *coordinator.TestResult {
+		return &coordinator.TestResult{
+			ScenarioName:  name,
+			Success:       true,
+			PrimaryMetric: "latency_p50_usec",
+			ClientResult:  &runner.Result{Success: true, Metrics: map[string]interface{}{"latency_p50_usec": value}},
+		}
+	}
+
+	rankings := rankByMetric([]*coordinator.TestResult{withLatency("slow", 4.2), withLatency("fast", 1.1)})
+	if len(rankings) != 1 || rankings[0].best.ScenarioName != "fast" || rankings[0].worst.ScenarioName != "slow" {
+		t.Errorf("Expected fast best and slow worst, got %+v", rankings)
+	}
+}
+
+func TestRankByMetric_SeparatesMetrics(t *testing.T) {
+	result := func(name, metric string, value float64) *coordinator.TestResult {
+		return &coordinator.TestResult{
+			ScenarioName:  name,
+			Success:       true,
+			PrimaryMetric: metric,
+			ClientResult:  &runner.Result{Success: true, Metrics: map[string]interface{}{metric: value}},
+		}
+	}
+	results := []*coordinator.TestResult{
+		result("tcp", "bandwidth_mbps", 9400),
+		result("lat", "latency_avg_usec", 3.5),
+		result("tcp-1", "bandwidth_mbps", 900),
+		result("lat-slow", "latency_avg_usec", 12),
+	}
+
+	// A 3.5us latency must not be ranked as the worst bandwidth
+	rankings := rankByMetric(results)
+	if len(rankings) != 2 {
+		t.Fatalf("Expected one ranking per metric, got %d", len(rankings))
+	}
+	for i, want := range []struct{ metric, best, worst string }{
+		{"bandwidth_mbps", "tcp", "tcp-1"},
+		{"latency_avg_usec", "lat", "lat-slow"},
+	} {
+		got := rankings[i]
+		if got.metric != want.metric || got.best.ScenarioName != want.best || got.worst.ScenarioName != want.worst {
+			t.Errorf("Ranking %d: expected %s best %s worst %s, got %s best %s worst %s",
+				i, want.metric, want.best, want.worst, got.metric, got.best.ScenarioName, got.worst.ScenarioName)
+		}
+	}
+
+	var out bytes.Buffer
+	formatter := NewFormatter(false)
+	formatter.SetOutput(&out)
+	if err := formatter.OutputResults(results, 0); err != nil {
+		t.Fatalf("OutputResults returned error: %v", err)
+	}
+	for _, line := range []string{"Worst: tcp-1 (bandwidth_mbps: 900.00)", "Best: lat (latency_avg_usec: 3.50)"} {
+		if !strings.Contains(out.String(), line) {
+			t.Errorf("Expected output to contain %q, got:\n%s", line, out.String())
+		}
+	}
+}
+
+func TestFormatter_FailuresOnly(t *testing.T) {
+	results := []*coordinator.TestResult{
+		{ScenarioName: "tcp passed", Success: true, ClientResult: &runner.Result{Success: true, Output: "all good"}},
+		{ScenarioName: "udp failed", Success: false, Error: "client failed", ClientResult: &runner.Result{Error: "connect failed"}},
+		{ScenarioName: "rdma passed", Success: true},
+	}
+
+	var out bytes.Buffer
+	formatter := NewFormatter(false)
+	formatter.SetOutput(&out)
+	formatter.SetFailuresOnly(true)
+	if err := formatter.OutputResults(results, 0); err != nil {
+		t.Fatalf("OutputResults returned error: %v", err)
+	}
+
+	text := out.String()
+	for _, want := range []string{"Total Tests: 3", "Passed: 2", "Failed: 1", "2. udp failed", "Client Error: connect failed"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, text)
+		}
+	}
+	for _, omitted := range []string{"tcp passed", "rdma passed", "all good"} {
+		if strings.Contains(text, omitted) {
+			t.Errorf("Expected passing scenario details to be omitted, found %q in:\n%s", omitted, text)
+		}
+	}
+
+	out.Reset()
+	jsonFormatter := NewFormatter(true)
+	jsonFormatter.SetOutput(&out)
+	jsonFormatter.SetFailuresOnly(true)
+	if err := jsonFormatter.OutputResults(results, 0); err != nil {
+		t.Fatalf("OutputResults returned error: %v", err)
+	}
+	if !strings.Contains(out.String(), "tcp passed") {
+		t.Error("Expected JSON output to keep passing scenarios")
+	}
+}
+
+func TestFormatter_LabelsExpectedFailures(t *testing.T) {
+	results := []*coordinator.TestResult{
+		{ScenarioName: "blocked port", Success: true, ExpectFail: true, Error: "connection refused"},
+		{ScenarioName: "open port", Success: false, ExpectFail: true, Error: "expected to fail but succeeded"},
+	}
+
+	var out bytes.Buffer
+	formatter := NewFormatter(false)
+	formatter.SetOutput(&out)
+	if err := formatter.OutputResults(results, 0); err != nil {
+		t.Fatalf("OutputResults returned error: %v", err)
+	}
+
+	text := out.String()
+	for _, want := range []string{"Status: ✓ PASS (failed as expected)", "Status: ✗ FAIL (unexpectedly succeeded)"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, text)
+		}
+	}
+}
+
+func TestFormatter_Connections(t *testing.T) {
+	report := []coordinator.HostConnection{
+		{Host: "client1", Connected: true, Drops: 1, Reconnects: 1, Events: []coordinator.ConnectionEvent{
+			{Event: "connected"}, {Event: "dropped"}, {Event: "reconnected"},
+		}},
+		{Host: "server1", Connected: true, Events: []coordinator.ConnectionEvent{{Event: "connected"}}},
+	}
+
+	var out bytes.Buffer
+	formatter := NewFormatter(true)
+	formatter.SetOutput(&out)
+	formatter.SetConnections(report)
+	if err := formatter.OutputResults(nil, 0); err != nil {
+		t.Fatalf("OutputResults returned error: %v", err)
+	}
+
+	var decoded struct {
+		Connections []struct {
+			Host       string `json:"host"`
+			Reconnects int    `json:"reconnects"`
+		} `json:"connections"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &decoded); err != nil {
+		t.Fatalf("Failed to decode JSON output: %v", err)
+	}
+	if len(decoded.Connections) != 2 || decoded.Connections[0].Host != "client1" || decoded.Connections[0].Reconnects != 1 {
+		t.Errorf("Expected client1 with reconnects: 1 under connections, got %+v", decoded.Connections)
+	}
+
+	out.Reset()
+	formatter = NewFormatter(false)
+	formatter.SetOutput(&out)
+	formatter.SetConnections(report)
+	if err := formatter.OutputResults(nil, 0); err != nil {
+		t.Fatalf("OutputResults returned error: %v", err)
+	}
+	text := out.String()
+	for _, want := range []string{"=== Connections ===", "client1: connected (drops: 1, reconnects: 1)", "server1: connected (drops: 0, reconnects: 0)"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, text)
+		}
+	}
+}
+
+func TestFormatter_BandwidthUnit(t *testing.T) {
+	results := []*coordinator.TestResult{{
+		ScenarioName:  "tcp",
+		Success:       true,
+		PrimaryMetric: "bandwidth_mbps",
+		ClientResult: &runner.Result{Success: true, Metrics: map[string]interface{}{
+			"bandwidth_bps":  1e9,
+			"bandwidth_mbps": 1000.0,
+			"bandwidth_gbps": 1.0,
+			"retransmits":    3,
+		}},
+	}}
+
+	for unit, want := range map[string]string{"gbps": "1.00 Gbps", "MBps": "125.00 MBps", "mbps": "1000.00 Mbps", "GBps": "0.12 GBps"} {
+		var out bytes.Buffer
+		formatter := NewFormatter(false)
+		formatter.SetOutput(&out)
+		formatter.SetBandwidthUnit(unit)
+		if err := formatter.OutputResults(results, 0); err != nil {
+			t.Fatalf("OutputResults returned error: %v", err)
+		}
+
+		text := out.String()
+		for _, line := range []string{"bandwidth_bps: " + want, "Best: tcp (bandwidth_mbps: " + want + ")", "retransmits: 3"} {
+			if !strings.Contains(text, line) {
+				t.Errorf("%s: expected output to contain %q, got:\n%s", unit, line, text)
+			}
+		}
+		if strings.Contains(text, "     bandwidth_gbps:") || strings.Contains(text, "     bandwidth_mbps:") {
+			t.Errorf("%s: expected unit variants of bandwidth_bps to be hidden, got:\n%s", unit, text)
+		}
+	}
+}
+
+func TestFormatter_PerftestBandwidthUnit(t *testing.T) {
+	// perftest reports bandwidth_average_mbps in MB/s; its _bps sibling
+	// holds the value in bits per second
+	results := []*coordinator.TestResult{{
+		ScenarioName:  "rdma",
+		Runner:        "ib_send_bw",
+		Success:       true,
+		PrimaryMetric: "bandwidth_average_mbps",
+		ClientResult: &runner.Result{Success: true, Metrics: map[string]interface{}{
+			"bandwidth_average_mbps": 12000.0,
+			"bandwidth_average_bps":  12000.0 * 1e6 * 8,
+		}},
+	}}
+
+	var out bytes.Buffer
+	formatter := NewFormatter(false)
+	formatter.SetOutput(&out)
+	formatter.SetBandwidthUnit("gbps")
+	if err := formatter.OutputResults(results, 0); err != nil {
+		t.Fatalf("OutputResults returned error: %v", err)
+	}
+	if want := "Best: rdma (bandwidth_average_mbps: 96.00 Gbps)"; !strings.Contains(out.String(), want) {
+		t.Errorf("Expected output to contain %q, got:\n%s", want, out.String())
+	}
+
+	out.Reset()
+	if err := formatter.WriteMarkdown(&out, "RDMA", results, 0); err != nil {
+		t.Fatalf("WriteMarkdown returned error: %v", err)
+	}
+	if want := "| bandwidth_average_mbps: 96.00 Gbps |"; !strings.Contains(out.String(), want) {
+		t.Errorf("Expected Markdown to contain %q, got:\n%s", want, out.String())
+	}
+}
+
+// jsonResults renders results as JSON and returns the decoded per-result objects
+func jsonResults(t *testing.T, results []*coordinator.TestResult) []map[string]interface{} {
+	t.Helper()
+	var out bytes.Buffer
+	formatter := NewFormatter(false)
+	formatter.SetFormat(FormatJSON)
+	formatter.SetOutput(&out)
+	if err := formatter.OutputResults(results, 0); err != nil {
+		t.Fatalf("OutputResults returned error: %v", err)
+	}
+	var decoded struct {
+		Results []map[string]interface{} `json:"results"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &decoded); err != nil {
+		t.Fatalf("Invalid JSON output: %v\n%s", err, out.String())
+	}
+	if len(decoded.Results) != len(results) {
+		t.Fatalf("Expected %d results, got %d", len(results), len(decoded.Results))
+	}
+	return decoded.Results
+}
+
+func TestFormatter_JSONSkipped(t *testing.T) {
+	decoded := jsonResults(t, []*coordinator.TestResult{
+		{ScenarioName: "ran", Success: true},
+		{ScenarioName: "skipped", Skipped: true, Error: "hosts unreachable: server"},
+	})
+	if _, present := decoded[0]["skipped"]; present {
+		t.Errorf("Expected no skipped key on a scenario that ran, got %v", decoded[0])
+	}
+	if decoded[1]["skipped"] != true {
+		t.Errorf("Expected skipped: true on the skipped scenario, got %v", decoded[1])
+	}
+}
+
+func TestFormatter_JSONToolVersions(t *testing.T) {
+	decoded := jsonResults(t, []*coordinator.TestResult{{
+		ScenarioName: "tcp",
+		Success:      true,
+		ToolVersions: map[string]string{"client": "3.16", "server": "3.9"},
+	}})
+	versions, ok := decoded[0]["tool_versions"].(map[string]interface{})
+	if !ok || versions["client"] != "3.16" || versions["server"] != "3.9" {
+		t.Errorf("Expected each host's tool version in JSON, got %v", decoded[0]["tool_versions"])
+	}
+}
+
+func TestFormatter_JSONResolvedAddresses(t *testing.T) {
+	decoded := jsonResults(t, []*coordinator.TestResult{{
+		ScenarioName:      "tcp",
+		Success:           true,
+		ResolvedAddresses: map[string]string{"server": "10.0.0.1"},
+	}})
+	addresses, ok := decoded[0]["resolved_addresses"].(map[string]interface{})
+	if !ok || addresses["server"] != "10.0.0.1" {
+		t.Errorf("Expected the pinned addresses in JSON, got %v", decoded[0]["resolved_addresses"])
+	}
+}
+
+func TestFormatter_JSONNodeResults(t *testing.T) {
+	decoded := jsonResults(t, []*coordinator.TestResult{{
+		ScenarioName: "chain",
+		Success:      true,
+		NodeResults: []coordinator.NodeResult{
+			{Host: "gen", Role: "client", Command: "fake-client relay1", Result: &runner.Result{Success: true}},
+			{Host: "relay1", Role: "intermediate", Command: "fake-intermediate relay2", Result: &runner.Result{Success: true}},
+			{Host: "relay2", Role: "intermediate", Command: "fake-intermediate sink", Result: &runner.Result{Success: false, ExitCode: 1, Error: "exit status 1"}},
+			{Host: "sink", Role: "server", Command: "fake-server", Result: &runner.Result{Success: true}},
+		},
+	}})
+	nodes, ok := decoded[0]["node_results"].([]interface{})
+	if !ok || len(nodes) != 4 {
+		t.Fatalf("Expected four node results in JSON, got %v", decoded[0]["node_results"])
+	}
+	relay, _ := nodes[2].(map[string]interface{})
+	if relay["host"] != "relay2" || relay["role"] != "intermediate" || relay["command"] != "fake-intermediate sink" || relay["success"] != false || relay["error"] != "exit status 1" {
+		t.Errorf("Unexpected second relay node %v", relay)
+	}
+}
+
+func TestValidateBandwidthUnit(t *testing.T) {
+	for _, unit := range []string{"", "mbps", "gbps", "MBps", "GBps"} {
+		if err := ValidateBandwidthUnit(unit); err != nil {
+			t.Errorf("%q: unexpected error %v", unit, err)
+		}
+	}
+	for _, unit := range []string{"Gbps", "kbps", "bytes"} {
+		if err := ValidateBandwidthUnit(unit); err == nil {
+			t.Errorf("%q: expected an error", unit)
+		}
+	}
+}
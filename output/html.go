@@ -0,0 +1,316 @@
+package output
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"perf-runner/coordinator"
+	"perf-runner/runner"
+)
+
+// reportData is the data rendered by the HTML report template
+type reportData struct {
+	Title   string
+	Total   int
+	Passed  int
+	Failed  int
+	Current string
+	Done    bool
+	Live    bool
+	Rows    []reportRow
+	
+	// Duration is the total run time, shown once the run is complete
+	Duration time.Duration
+	
+	// Environment lists each host's collected environment once
+	Environment []hostEnvironment
+	
+	// Timeline holds a Gantt bar per timed scenario, so overlapping runs
+	// and the hosts they shared stand out
+	Timeline []ganttBar
+}
+
+// reportRow is one scenario in the HTML report
+type reportRow struct {
+	Name          string
+	Success       bool
+	Label         string // Outcome of an expect_fail scenario
+	Duration      time.Duration
+	PrimaryMetric string
+	Error         string
+	Roles         []reportRole
+}
+
+// reportRole is one role of a scenario in the HTML report, with its
+// command, metrics, and output collapsed under the scenario
+type reportRole struct {
+	Name    string
+	Success bool
+	Command string
+	Metrics []envEntry
+	Output  string
+	Error   string
+}
+
+// Layout of the timeline SVG, in pixels
+const (
+	ganttLabelWidth = 200
+	ganttChartWidth = 600
+	ganttRowHeight  = 20
+)
+
+// ganttBar is one scenario in the timeline, positioned in SVG coordinates
+type ganttBar struct {
+	Name    string
+	Hosts   string
+	Success bool
+	X       float64
+	Y       int
+	Width   float64
+}
+
+var htmlReportTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"ganttWidth":  func() int { return ganttLabelWidth + ganttChartWidth },
+	"ganttHeight": func(bars []ganttBar) int { return len(bars) * ganttRowHeight },
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #ccc; padding: 4px 10px; text-align: left; vertical-align: top; }
+.pass { color: #1a7f37; font-weight: bold; }
+.fail { color: #cf222e; font-weight: bold; }
+.badge { color: #fff; border-radius: 3px; padding: 1px 6px; font-size: 0.85em; }
+.badge.pass { background: #1a7f37; }
+.badge.fail { background: #cf222e; }
+tr.details > td { border-top: none; background: #f6f8fa; }
+h4 { margin: 0.8em 0 0.3em; }
+pre { background: #fff; border: 1px solid #ddd; padding: 6px; overflow-x: auto; max-height: 30em; }
+summary { cursor: pointer; }
+rect.pass { fill: #1a7f37; }
+rect.fail { fill: #cf222e; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<p>Completed: {{len .Rows}}/{{.Total}} &middot; Passed: {{.Passed}} &middot; Failed: {{.Failed}}{{if .Current}} &middot; Running: {{.Current}}{{end}}{{if .Duration}} &middot; Duration: {{.Duration}}{{end}}</p>
+<table>
+<tr><th>Scenario</th><th>Status</th><th>Duration</th><th>Primary Metric</th><th>Error</th></tr>
+{{range .Rows}}<tr class="scenario"><td>{{.Name}}</td><td>{{if .Success}}<span class="badge pass">PASS</span>{{else}}<span class="badge fail">FAIL</span>{{end}}{{with .Label}} ({{.}}){{end}}</td><td>{{.Duration}}</td><td>{{.PrimaryMetric}}</td><td>{{.Error}}</td></tr>
+{{with .Roles}}<tr class="details"><td colspan="5"><details><summary>Roles</summary>
+{{range .}}<h4>{{.Name}} {{if .Success}}<span class="badge pass">PASS</span>{{else}}<span class="badge fail">FAIL</span>{{end}}</h4>
+{{with .Error}}<p class="fail">{{.}}</p>
+{{end}}{{with .Metrics}}<table>
+{{range .}}<tr><td>{{.Key}}</td><td>{{.Value}}</td></tr>
+{{end}}</table>
+{{end}}{{with .Command}}<details><summary>Command</summary><pre>{{.}}</pre></details>
+{{end}}{{with .Output}}<details><summary>Output</summary><pre>{{.}}</pre></details>
+{{end}}{{end}}</details></td></tr>
+{{end}}{{end}}</table>
+{{with .Environment}}<h2>Environment</h2>
+{{range .}}<details><summary>{{.Host}}</summary><table>
+{{range .Entries}}<tr><td>{{.Key}}</td><td><pre>{{.Value}}</pre></td></tr>
+{{end}}</table></details>
+{{end}}{{end}}{{with .Timeline}}<h2>Timeline</h2>
+<svg xmlns="http://www.w3.org/2000/svg" width="{{ganttWidth}}" height="{{ganttHeight .}}">
+{{range .}}<text x="0" y="{{.Y}}" dy="14" font-size="12">{{.Name}}</text>
+<rect class="bar {{if .Success}}pass{{else}}fail{{end}}" x="{{printf "%.1f" .X}}" y="{{.Y}}" width="{{printf "%.1f" .Width}}" height="16"><title>{{.Name}} on {{.Hosts}}</title></rect>
+{{end}}</svg>
+{{end}}{{if and .Live (not .Done)}}<script>
+var completed = {{len .Rows}};
+setInterval(function() {
+  fetch("status").then(function(r) { return r.json(); }).then(function(s) {
+    if ((s.results || []).length !== completed || s.done) { location.reload(); }
+  });
+}, 2000);
+</script>{{end}}
+</body>
+</html>
+`))
+
+// newReportData builds the template data for a set of results
+func newReportData(title string, total int, results []*coordinator.TestResult) reportData {
+	data := reportData{Title: title, Total: total}
+	for _, result := range results {
+		if result.Success {
+			data.Passed++
+		} else {
+			data.Failed++
+		}
+		data.Rows = append(data.Rows, reportRow{
+			Name:          result.ScenarioName,
+			Success:       result.Success,
+			Label:         expectFailLabel(result),
+			Duration:      result.Duration,
+			PrimaryMetric: primaryMetric(result),
+			Error:         result.Error,
+			Roles:         reportRoles(result),
+		})
+	}
+	data.Timeline = newTimeline(results)
+	// The environment is informational, so a host that cannot be flattened
+	// only ends the list
+	data.Environment, _ = hostEnvironments(results)
+	return data
+}
+
+// reportRoles renders the roles of a scenario that produced a result
+func reportRoles(result *coordinator.TestResult) []reportRole {
+	var roles []reportRole
+	for _, role := range roleResults(result) {
+		name := role.Role
+		if role.Host != "" {
+			name = fmt.Sprintf("%s (%s)", role.Role, role.Host)
+		}
+		roles = append(roles, newReportRole(name, role.Command, role.Result))
+	}
+	return roles
+}
+
+// roleResult is the result of one role of a scenario
+type roleResult struct {
+	Role    string
+	Host    string // Set for chains and fan-out clients
+	Command string
+	Result  *runner.Result
+}
+
+// roleResults lists the roles of a scenario that produced a result: every
+// host of the chain in traffic order when known, then any fan-out clients
+func roleResults(result *coordinator.TestResult) []roleResult {
+	var roles []roleResult
+	if len(result.NodeResults) > 0 {
+		for _, node := range result.NodeResults {
+			if node.Result != nil {
+				roles = append(roles, roleResult{Role: node.Role, Host: node.Host, Command: node.Command, Result: node.Result})
+			}
+		}
+	} else {
+		for _, role := range []roleResult{
+			{Role: "client", Command: result.ClientCommand, Result: result.ClientResult},
+			{Role: "intermediate", Command: result.IntermediateCommand, Result: result.IntermediateResult},
+			{Role: "server", Command: result.ServerCommand, Result: result.ServerResult},
+		} {
+			if role.Result != nil {
+				roles = append(roles, role)
+			}
+		}
+	}
+	for _, host := range sortedKeys(result.ClientResults) {
+		roles = append(roles, roleResult{Role: "client", Host: host, Result: result.ClientResults[host]})
+	}
+	return roles
+}
+
+// newReportRole renders one role's result, with metrics sorted by key
+func newReportRole(name, command string, result *runner.Result) reportRole {
+	role := reportRole{
+		Name:    name,
+		Success: result.Success || result.TerminatedByUs,
+		Command: command,
+		Output:  result.Output,
+		Error:   result.Error,
+	}
+	keys := make([]string, 0, len(result.Metrics))
+	for key := range result.Metrics {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		role.Metrics = append(role.Metrics, envEntry{Key: key, Value: formatReportValue(result.Metrics[key])})
+	}
+	return role
+}
+
+// formatReportValue renders a metric value for the HTML report; row-valued
+// metrics (sizes, intervals) are summarized by their row count
+func formatReportValue(value interface{}) string {
+	switch v := value.(type) {
+	case []map[string]interface{}:
+		return fmt.Sprintf("%d rows", len(v))
+	case float64:
+		return fmt.Sprintf("%.2f", v)
+	}
+	return fmt.Sprint(value)
+}
+
+// newTimeline lays out a bar per scenario from its start to its end time,
+// scaled so the whole run spans the chart. Scenarios without timing are
+// left out, and no timeline is drawn if nothing took measurable time.
+func newTimeline(results []*coordinator.TestResult) []ganttBar {
+	var timed []*coordinator.TestResult
+	var first, last time.Time
+	for _, result := range results {
+		if result.StartTime.IsZero() || result.EndTime.Before(result.StartTime) {
+			continue
+		}
+		if first.IsZero() || result.StartTime.Before(first) {
+			first = result.StartTime
+		}
+		if result.EndTime.After(last) {
+			last = result.EndTime
+		}
+		timed = append(timed, result)
+	}
+	span := last.Sub(first)
+	if span <= 0 {
+		return nil
+	}
+	
+	scale := float64(ganttChartWidth) / float64(span)
+	bars := make([]ganttBar, 0, len(timed))
+	for i, result := range timed {
+		bars = append(bars, ganttBar{
+			Name:    result.ScenarioName,
+			Hosts:   hostSet(result.Hosts),
+			Success: result.Success,
+			X:       ganttLabelWidth + float64(result.StartTime.Sub(first))*scale,
+			Y:       i * ganttRowHeight,
+			Width:   float64(result.EndTime.Sub(result.StartTime)) * scale,
+		})
+	}
+	return bars
+}
+
+// hostSet lists the distinct hosts of a scenario's roles, sorted
+func hostSet(hosts map[string]string) string {
+	seen := make(map[string]bool, len(hosts))
+	var names []string
+	for _, host := range hosts {
+		if host != "" && !seen[host] {
+			seen[host] = true
+			names = append(names, host)
+		}
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// renderHTMLReport writes the HTML report for data to w
+func renderHTMLReport(w io.Writer, data reportData) error {
+	return htmlReportTemplate.Execute(w, data)
+}
+
+// outputHTML writes a self-contained HTML report, with inline styles and no
+// external resources, so the file can be shared as is
+func (f *Formatter) outputHTML(results []*coordinator.TestResult, totalDuration time.Duration) error {
+	data := newReportData(f.title, len(results), results)
+	data.Duration = totalDuration
+	data.Done = true
+	return renderHTMLReport(f.out, data)
+}
+
+// primaryMetric returns the scenario's headline client metric formatted as "key: value"
+func primaryMetric(result *coordinator.TestResult) string {
+	if value, ok := result.PrimaryValue(); ok {
+		return fmt.Sprintf("%s: %.2f", result.PrimaryMetric, value)
+	}
+	return ""
+}
@@ -0,0 +1,125 @@
+package output
+
+import (
+	"bytes"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"perf-runner/coordinator"
+	"perf-runner/envinfo"
+	"perf-runner/runner"
+)
+
+func TestHTMLReport_Timeline(t *testing.T) {
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	results := []*coordinator.TestResult{
+		{
+			ScenarioName: "long",
+			Success:      true,
+			StartTime:    start,
+			EndTime:      start.Add(40 * time.Second),
+			Hosts:        map[string]string{"client": "c1", "server": "s1"},
+		},
+		{
+			ScenarioName: "short",
+			StartTime:    start.Add(20 * time.Second),
+			EndTime:      start.Add(30 * time.Second),
+			Hosts:        map[string]string{"client": "c2", "server": "s1"},
+		},
+		{ScenarioName: "untimed"},
+	}
+
+	var buf bytes.Buffer
+	if err := renderHTMLReport(&buf, newReportData("nightly", 3, results)); err != nil {
+		t.Fatalf("renderHTMLReport returned error: %v", err)
+	}
+	page := buf.String()
+
+	bars := regexp.MustCompile(`<rect class="bar (pass|fail)" x="([\d.]+)" y="\d+" width="([\d.]+)" height="16"><title>([^<]*)</title>`).FindAllStringSubmatch(page, -1)
+	if len(bars) != 2 {
+		t.Fatalf("Expected a bar per timed scenario, got %d:\n%s", len(bars), page)
+	}
+	if bars[0][4] != "long on c1, s1" || bars[1][4] != "short on c2, s1" {
+		t.Errorf("Expected bars titled with their hosts, got %q and %q", bars[0][4], bars[1][4])
+	}
+	if bars[0][1] != "pass" || bars[1][1] != "fail" {
+		t.Errorf("Expected bars classed by outcome, got %s and %s", bars[0][1], bars[1][1])
+	}
+
+	x0, _ := strconv.ParseFloat(bars[0][2], 64)
+	x1, _ := strconv.ParseFloat(bars[1][2], 64)
+	w0, _ := strconv.ParseFloat(bars[0][3], 64)
+	w1, _ := strconv.ParseFloat(bars[1][3], 64)
+	if w0 != 4*w1 {
+		t.Errorf("Expected the 40s bar 4x the 10s bar, got widths %.1f and %.1f", w0, w1)
+	}
+	if x1-x0 != w0/2 {
+		t.Errorf("Expected the second bar to start halfway along the first, got x %.1f and %.1f", x0, x1)
+	}
+}
+
+func TestHTMLReport_NoTimelineWithoutTiming(t *testing.T) {
+	var buf bytes.Buffer
+	results := []*coordinator.TestResult{{ScenarioName: "untimed"}}
+	if err := renderHTMLReport(&buf, newReportData("nightly", 1, results)); err != nil {
+		t.Fatalf("renderHTMLReport returned error: %v", err)
+	}
+	if bytes.Contains(buf.Bytes(), []byte("<svg")) {
+		t.Errorf("Expected no timeline without timing, got:\n%s", buf.String())
+	}
+}
+
+func TestFormatter_HTML(t *testing.T) {
+	results := []*coordinator.TestResult{
+		{
+			ScenarioName:  "tcp",
+			Success:       true,
+			ClientCommand: "iperf3 -c 10.0.0.2 -J",
+			ServerCommand: "iperf3 -s",
+			ClientResult:  &runner.Result{Success: true, Output: "<json>", Metrics: map[string]interface{}{"bandwidth_mbps": 9410.5, "retransmits": 3}},
+			ServerResult:  &runner.Result{TerminatedByUs: true},
+			Hosts:         map[string]string{"client": "c1", "server": "s1"},
+			EnvironmentInfo: &coordinator.EnvironmentData{
+				ServerEnv: &envinfo.EnvironmentInfo{Hostname: "srv", CPUInfo: envinfo.CPUInfo{Model: "Xeon"}},
+			},
+		},
+		{ScenarioName: "rdma", Error: "client failed"},
+	}
+
+	var buf bytes.Buffer
+	formatter := NewFormatter(false)
+	formatter.SetFormat(FormatHTML)
+	formatter.SetTitle("nightly")
+	formatter.SetOutput(&buf)
+	if err := formatter.OutputResults(results, time.Minute); err != nil {
+		t.Fatalf("OutputResults returned error: %v", err)
+	}
+	page := buf.String()
+
+	for _, want := range []string{
+		"<title>nightly</title>",
+		`<span class="badge pass">PASS</span>`,
+		`<span class="badge fail">FAIL</span>`,
+		"Duration: 1m0s",
+		"<summary>Command</summary><pre>iperf3 -c 10.0.0.2 -J</pre>",
+		"<summary>Output</summary><pre>&lt;json&gt;</pre>",
+		"<tr><td>bandwidth_mbps</td><td>9410.50</td></tr>",
+		"<tr><td>retransmits</td><td>3</td></tr>",
+		"<summary>s1</summary>",
+		"<tr><td>cpu.model</td><td><pre>Xeon</pre></td></tr>",
+	} {
+		if !strings.Contains(page, want) {
+			t.Errorf("Expected report to contain %q:\n%s", want, page)
+		}
+	}
+	if strings.Count(page, `<tr class="details">`) != 1 {
+		t.Errorf("Expected role details only for the scenario with results:\n%s", page)
+	}
+	// The report is emailed, so it must not load anything
+	if strings.Contains(page, "<script") || strings.Contains(page, "<link") {
+		t.Errorf("Expected a self-contained report:\n%s", page)
+	}
+}
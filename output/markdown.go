@@ -0,0 +1,115 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"perf-runner/coordinator"
+)
+
+// markdownCell escapes a value for a Markdown table cell, where "|" would
+// end the cell and a newline the row
+func markdownCell(value string) string {
+	value = strings.ReplaceAll(value, "|", `\|`)
+	return strings.Join(strings.Fields(value), " ")
+}
+
+// writeMarkdownRow writes one Markdown table row
+func writeMarkdownRow(w io.Writer, cells ...string) error {
+	for i, cell := range cells {
+		cells[i] = markdownCell(cell)
+	}
+	_, err := fmt.Fprintf(w, "| %s |\n", strings.Join(cells, " | "))
+	return err
+}
+
+// writeMarkdownHeader writes a Markdown table header and its separator row
+func writeMarkdownHeader(w io.Writer, columns ...string) error {
+	if err := writeMarkdownRow(w, columns...); err != nil {
+		return err
+	}
+	separators := make([]string, len(columns))
+	for i := range separators {
+		separators[i] = "---"
+	}
+	_, err := fmt.Fprintf(w, "|%s|\n", strings.Join(separators, "|"))
+	return err
+}
+
+// WriteMarkdown writes the results as a Markdown document for pasting into
+// pull requests and wikis: a summary table with one row per scenario, then a
+// table of each scenario's client metrics. Metrics are selected and bandwidths
+// converted as in text output (see SetBandwidthUnit).
+func (f *Formatter) WriteMarkdown(w io.Writer, title string, results []*coordinator.TestResult, totalDuration time.Duration) error {
+	if _, err := fmt.Fprintf(w, "# %s\n\n", title); err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "%d scenarios, %d passed, %d failed, in %v.\n\n",
+		len(results), f.countPassed(results), f.countFailed(results), totalDuration.Round(time.Millisecond))
+
+	fmt.Fprintf(w, "## Summary\n\n")
+	if err := writeMarkdownHeader(w, "#", "Scenario", "Status", "Duration", "Primary Metric", "Error"); err != nil {
+		return err
+	}
+	for i, result := range results {
+		status := "PASS"
+		if !result.Success {
+			status = "FAIL"
+		}
+		if label := expectFailLabel(result); label != "" {
+			status += " (" + label + ")"
+		}
+		primary := ""
+		if value, ok := result.PrimaryValue(); ok {
+			primary = fmt.Sprintf("%s: %s", result.PrimaryMetric, f.formatSummaryValue(result, value))
+		}
+		err := writeMarkdownRow(w, fmt.Sprint(i+1), result.ScenarioName, status, result.Duration.Round(time.Millisecond).String(), primary, result.Error)
+		if err != nil {
+			return err
+		}
+	}
+
+	fmt.Fprintf(w, "\n## Metrics\n")
+	for i, result := range results {
+		if result.ClientResult == nil || len(result.ClientResult.Metrics) == 0 {
+			continue
+		}
+		fmt.Fprintf(w, "\n### %d. %s\n\n", i+1, markdownCell(result.ScenarioName))
+		if err := f.writeMarkdownMetrics(w, result.ClientResult.Metrics); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeMarkdownMetrics writes a metric/value table of client metrics in key order
+func (f *Formatter) writeMarkdownMetrics(w io.Writer, metrics map[string]interface{}) error {
+	keys := make([]string, 0, len(metrics))
+	for key := range metrics {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	if err := writeMarkdownHeader(w, "Metric", "Value"); err != nil {
+		return err
+	}
+	for _, key := range keys {
+		value := metrics[key]
+		// Row-valued metrics (sizes, intervals) are too long for a cell
+		if rows, ok := value.([]map[string]interface{}); ok {
+			if err := writeMarkdownRow(w, key, fmt.Sprintf("%d rows", len(rows))); err != nil {
+				return err
+			}
+			continue
+		}
+		if text, show := f.formatMetric(metrics, key, value); show {
+			if err := writeMarkdownRow(w, key, text); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package output
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"perf-runner/coordinator"
+	"perf-runner/runner"
+)
+
+func TestWriteMarkdown(t *testing.T) {
+	results := []*coordinator.TestResult{
+		{
+			ScenarioName:  "TCP | 4 streams",
+			Success:       true,
+			Duration:      10 * time.Second,
+			PrimaryMetric: "bandwidth_mbps",
+			ClientResult: &runner.Result{Success: true, Metrics: map[string]interface{}{
+				"bandwidth_bps":  9.4e9,
+				"bandwidth_mbps": 9400.0,
+				"retransmits":    3,
+			}},
+		},
+		{ScenarioName: "UDP", Success: false, Error: "client failed:\nconnection refused"},
+	}
+
+	var out bytes.Buffer
+	formatter := NewFormatter(false)
+	formatter.SetBandwidthUnit("gbps")
+	if err := formatter.WriteMarkdown(&out, "Nightly", results, 12*time.Second); err != nil {
+		t.Fatalf("WriteMarkdown returned error: %v", err)
+	}
+	text := out.String()
+
+	if !strings.HasPrefix(text, "# Nightly\n") {
+		t.Errorf("Expected a title heading, got:\n%s", text)
+	}
+
+	// Every table line is a complete row with the header's cell count
+	var summary []string
+	inSummary := false
+	for _, line := range strings.Split(text, "\n") {
+		switch {
+		case line == "## Summary":
+			inSummary = true
+		case strings.HasPrefix(line, "## "):
+			inSummary = false
+		case strings.HasPrefix(line, "|"):
+			if !strings.HasSuffix(line, "|") {
+				t.Errorf("Unterminated table row %q", line)
+			}
+			if inSummary {
+				summary = append(summary, line)
+			}
+		}
+	}
+	if len(summary) != 2+len(results) {
+		t.Fatalf("Expected a header, a separator, and one row per scenario, got:\n%s", strings.Join(summary, "\n"))
+	}
+	if summary[0] != "| # | Scenario | Status | Duration | Primary Metric | Error |" || summary[1] != "|---|---|---|---|---|---|" {
+		t.Errorf("Unexpected summary header:\n%s\n%s", summary[0], summary[1])
+	}
+	cells := func(row string) int { return strings.Count(strings.ReplaceAll(row, `\|`, ""), "|") }
+	for _, row := range summary[2:] {
+		if cells(row) != cells(summary[0]) {
+			t.Errorf("Row %q has a different cell count than the header", row)
+		}
+	}
+
+	for _, want := range []string{
+		`| 1 | TCP \| 4 streams | PASS | 10s | bandwidth_mbps: 9.40 Gbps |  |`,
+		"| 2 | UDP | FAIL | 0s |  | client failed: connection refused |",
+		"| Metric | Value |",
+		"| bandwidth_bps | 9.40 Gbps |",
+		"| retransmits | 3 |",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Expected Markdown to contain %q, got:\n%s", want, text)
+		}
+	}
+	if strings.Contains(text, "| bandwidth_mbps |") {
+		t.Error("Expected the unit variant of the canonical bandwidth metric to be hidden")
+	}
+}
@@ -0,0 +1,156 @@
+package output
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"perf-runner/coordinator"
+	"perf-runner/runner"
+)
+
+// OpenSearchExporter indexes results into OpenSearch or Elasticsearch through
+// the bulk API, one document per result
+type OpenSearchExporter struct {
+	URL    string
+	Index  string
+	Client *http.Client
+	// Labels are added to every document, e.g. the configuration name
+	Labels map[string]string
+}
+
+// openSearchDocument is the indexed form of one result. Metrics are flattened
+// to "role.metric" keys so every scalar is its own field.
+type openSearchDocument struct {
+	Timestamp       time.Time              `json:"@timestamp"`
+	Scenario        string                 `json:"scenario"`
+	Runner          string                 `json:"runner,omitempty"`
+	Success         bool                   `json:"success"`
+	DurationSeconds float64                `json:"duration_seconds"`
+	Error           string                 `json:"error,omitempty"`
+	Metrics         map[string]interface{} `json:"metrics,omitempty"`
+	Labels          map[string]string      `json:"labels,omitempty"`
+}
+
+// WriteBulk writes results as an NDJSON bulk request body: an index action
+// line followed by the document line for each result
+func (e *OpenSearchExporter) WriteBulk(w io.Writer, results []*coordinator.TestResult) error {
+	action, err := json.Marshal(map[string]interface{}{"index": map[string]string{"_index": e.Index}})
+	if err != nil {
+		return err
+	}
+
+	for _, result := range results {
+		document, err := json.Marshal(e.document(result))
+		if err != nil {
+			return fmt.Errorf("failed to encode result %s: %w", result.ScenarioName, err)
+		}
+		for _, line := range [][]byte{action, document} {
+			if _, err := w.Write(append(line, '\n')); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Export posts results to the bulk endpoint of e.URL. It fails if the
+// request fails or OpenSearch reports an error for any document.
+func (e *OpenSearchExporter) Export(ctx context.Context, results []*coordinator.TestResult) error {
+	if len(results) == 0 {
+		return nil
+	}
+
+	var body bytes.Buffer
+	if err := e.WriteBulk(&body, results); err != nil {
+		return err
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimRight(e.URL, "/")+"/_bulk", &body)
+	if err != nil {
+		return fmt.Errorf("invalid OpenSearch URL: %w", err)
+	}
+	request.Header.Set("Content-Type", "application/x-ndjson")
+
+	response, err := e.Client.Do(request)
+	if err != nil {
+		return fmt.Errorf("OpenSearch bulk request failed: %w", err)
+	}
+	defer response.Body.Close()
+
+	responseBody, _ := io.ReadAll(io.LimitReader(response.Body, 1<<20))
+	if response.StatusCode/100 != 2 {
+		return fmt.Errorf("OpenSearch bulk request failed: %s: %s", response.Status, strings.TrimSpace(string(responseBody)))
+	}
+
+	// A bulk request succeeds as a whole even when single documents fail
+	var bulkResponse struct {
+		Errors bool `json:"errors"`
+	}
+	if err := json.Unmarshal(responseBody, &bulkResponse); err != nil {
+		return fmt.Errorf("unexpected OpenSearch bulk response: %w", err)
+	}
+	if bulkResponse.Errors {
+		return fmt.Errorf("OpenSearch rejected some documents: %s", strings.TrimSpace(string(responseBody)))
+	}
+	return nil
+}
+
+// document converts a result into its indexed form
+func (e *OpenSearchExporter) document(result *coordinator.TestResult) openSearchDocument {
+	labels := make(map[string]string, len(e.Labels)+len(result.Hosts)+1)
+	for key, value := range e.Labels {
+		labels[key] = value
+	}
+	for role, host := range result.Hosts {
+		labels[role] = host
+	}
+	if result.ComparisonGroup != "" {
+		labels["comparison_group"] = result.ComparisonGroup
+	}
+
+	metrics := make(map[string]interface{})
+	addScalarMetrics(metrics, "client", result.ClientResult)
+	addScalarMetrics(metrics, "server", result.ServerResult)
+	addScalarMetrics(metrics, "intermediate", result.IntermediateResult)
+	hosts := make([]string, 0, len(result.ClientResults))
+	for host := range result.ClientResults {
+		hosts = append(hosts, host)
+	}
+	sort.Strings(hosts)
+	for _, host := range hosts {
+		addScalarMetrics(metrics, "client."+host, result.ClientResults[host])
+	}
+
+	return openSearchDocument{
+		Timestamp:       result.StartTime,
+		Scenario:        result.ScenarioName,
+		Runner:          result.Runner,
+		Success:         result.Success,
+		DurationSeconds: result.Duration.Seconds(),
+		Error:           result.Error,
+		Metrics:         metrics,
+		Labels:          labels,
+	}
+}
+
+// addScalarMetrics copies a role's numeric, string, and boolean metrics into
+// metrics under "prefix.name". Nested values such as per-interval samples are
+// left out to keep the index mapping flat.
+func addScalarMetrics(metrics map[string]interface{}, prefix string, roleResult *runner.Result) {
+	if roleResult == nil {
+		return
+	}
+	for name, value := range roleResult.Metrics {
+		switch value.(type) {
+		case float64, float32, int, int64, int32, uint64, uint32, string, bool:
+			metrics[prefix+"."+name] = value
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package output
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"perf-runner/coordinator"
+	"perf-runner/runner"
+)
+
+// openSearchResults returns two results, the second with a fan-out client
+func openSearchResults() []*coordinator.TestResult {
+	start := time.Date(2024, 1, 2, 10, 0, 0, 0, time.UTC)
+	return []*coordinator.TestResult{
+		{
+			ScenarioName: "tcp",
+			Runner:       "iperf3",
+			Success:      true,
+			StartTime:    start,
+			Duration:     12 * time.Second,
+			Hosts:        map[string]string{"client": "client1", "server": "server1"},
+			ClientResult: &runner.Result{Metrics: map[string]interface{}{
+				"bandwidth_mbps": 9400.5,
+				"retransmits":    3,
+				"intervals":      []map[string]interface{}{{"stream": 5}},
+			}},
+		},
+		{
+			ScenarioName:  "incast",
+			Runner:        "iperf3",
+			Error:         "client execution failed",
+			StartTime:     start.Add(time.Minute),
+			Hosts:         map[string]string{"client": "client1", "server": "server1"},
+			ClientResult:  &runner.Result{Metrics: map[string]interface{}{"bandwidth_mbps": 100.0}},
+			ClientResults: map[string]*runner.Result{"client2": {Metrics: map[string]interface{}{"bandwidth_mbps": 90.0}}},
+		},
+	}
+}
+
+func TestOpenSearchExporter_WriteBulk(t *testing.T) {
+	exporter := &OpenSearchExporter{Index: "perf", Labels: map[string]string{"config": "nightly"}}
+	var buf bytes.Buffer
+	if err := exporter.WriteBulk(&buf, openSearchResults()); err != nil {
+		t.Fatalf("WriteBulk returned error: %v", err)
+	}
+
+	if !strings.HasSuffix(buf.String(), "\n") {
+		t.Error("Expected the bulk body to end with a newline")
+	}
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("Expected an action and a document line per result, got %d lines:\n%s", len(lines), buf.String())
+	}
+
+	for i, line := range lines {
+		var decoded map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &decoded); err != nil {
+			t.Fatalf("Line %d is not JSON: %v", i, err)
+		}
+		_, isAction := decoded["index"]
+		if isAction != (i%2 == 0) {
+			t.Errorf("Expected lines to alternate action and document, line %d: %s", i, line)
+		}
+	}
+	if lines[0] != `{"index":{"_index":"perf"}}` {
+		t.Errorf("Unexpected action line: %s", lines[0])
+	}
+
+	var document map[string]interface{}
+	json.Unmarshal([]byte(lines[1]), &document)
+	if document["scenario"] != "tcp" || document["@timestamp"] != "2024-01-02T10:00:00Z" || document["duration_seconds"] != 12.0 {
+		t.Errorf("Unexpected document fields: %s", lines[1])
+	}
+	metrics := document["metrics"].(map[string]interface{})
+	if metrics["client.bandwidth_mbps"] != 9400.5 || metrics["client.retransmits"] != 3.0 {
+		t.Errorf("Expected flattened client metrics, got %v", metrics)
+	}
+	if _, nested := metrics["client.intervals"]; nested {
+		t.Error("Expected per-interval samples to be left out")
+	}
+	labels := document["labels"].(map[string]interface{})
+	if labels["config"] != "nightly" || labels["server"] != "server1" {
+		t.Errorf("Expected config and host labels, got %v", labels)
+	}
+
+	json.Unmarshal([]byte(lines[3]), &document)
+	if metrics := document["metrics"].(map[string]interface{}); metrics["client.client2.bandwidth_mbps"] != 90.0 {
+		t.Errorf("Expected fan-out client metrics keyed by host, got %v", metrics)
+	}
+}
+
+func TestOpenSearchExporter_Export(t *testing.T) {
+	var path, contentType, body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path, contentType = r.URL.Path, r.Header.Get("Content-Type")
+		data, _ := io.ReadAll(r.Body)
+		body = string(data)
+		w.Write([]byte(`{"took": 3, "errors": false, "items": []}`))
+	}))
+	defer server.Close()
+
+	exporter := &OpenSearchExporter{URL: server.URL + "/", Index: "perf", Client: server.Client()}
+	if err := exporter.Export(context.Background(), openSearchResults()); err != nil {
+		t.Fatalf("Export returned error: %v", err)
+	}
+	if path != "/_bulk" || contentType != "application/x-ndjson" {
+		t.Errorf("Expected a POST to /_bulk as NDJSON, got %s (%s)", path, contentType)
+	}
+	if strings.Count(body, "\n") != 4 {
+		t.Errorf("Expected 4 bulk lines, got:\n%s", body)
+	}
+}
+
+func TestOpenSearchExporter_ExportReportsRejectedDocuments(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"errors": true, "items": [{"index": {"status": 400}}]}`))
+	}))
+	defer server.Close()
+
+	exporter := &OpenSearchExporter{URL: server.URL, Index: "perf", Client: server.Client()}
+	err := exporter.Export(context.Background(), openSearchResults())
+	if err == nil || !strings.Contains(err.Error(), "rejected") {
+		t.Errorf("Expected an error for rejected documents, got %v", err)
+	}
+}
@@ -0,0 +1,104 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"perf-runner/coordinator"
+)
+
+// prometheusPrefix starts the name of every exported metric
+const prometheusPrefix = "perf_runner_"
+
+// invalidMetricNameChars matches characters not allowed in a Prometheus metric name
+var invalidMetricNameChars = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+
+// labelEscaper escapes a label value for the Prometheus text format
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// prometheusSample is one line of the exposition: labels and value of a metric
+type prometheusSample struct {
+	labels string
+	value  float64
+}
+
+// outputPrometheus writes results in the Prometheus text exposition format
+func (f *Formatter) outputPrometheus(results []*coordinator.TestResult, totalDuration time.Duration) error {
+	return WritePrometheus(f.out, results)
+}
+
+// WritePrometheus writes results in the Prometheus text exposition format,
+// e.g. for node_exporter's textfile collector. Each numeric metric of every
+// role becomes a gauge named after the metric, labeled with the scenario and
+// role (and host for chains and fan-out clients); perf_runner_test_success
+// is 1 for each passed scenario and 0 otherwise. A scenario run several
+// times is further labeled with its iteration.
+func WritePrometheus(w io.Writer, results []*coordinator.TestResult) error {
+	families := make(map[string][]prometheusSample)
+	add := func(metric string, labels []string, value float64) {
+		name := prometheusPrefix + invalidMetricNameChars.ReplaceAllString(metric, "_")
+		families[name] = append(families[name], prometheusSample{labels: strings.Join(labels, ","), value: value})
+	}
+
+	runs := make(map[string]int)
+	for _, result := range results {
+		runs[result.ScenarioName]++
+	}
+	iterations := make(map[string]int)
+	for _, result := range results {
+		scenario := []string{prometheusLabel("scenario", result.ScenarioName)}
+		if runs[result.ScenarioName] > 1 {
+			iterations[result.ScenarioName]++
+			scenario = append(scenario, prometheusLabel("iteration", strconv.Itoa(iterations[result.ScenarioName])))
+		}
+
+		success := 0.0
+		if result.Success {
+			success = 1
+		}
+		add("test_success", scenario, success)
+
+		for _, role := range roleResults(result) {
+			labels := append(append([]string(nil), scenario...), prometheusLabel("role", role.Role))
+			if role.Host != "" {
+				labels = append(labels, prometheusLabel("host", role.Host))
+			}
+			// Non-numeric metrics, such as per-size rows, are not exported
+			for key, value := range role.Result.Metrics {
+				if number, ok := metricFloat(value); ok {
+					add(key, labels, number)
+				}
+			}
+		}
+	}
+
+	// The text format requires the samples of a family to be contiguous;
+	// within a family they keep the order of the results
+	names := make([]string, 0, len(families))
+	for name := range families {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if _, err := fmt.Fprintf(w, "# TYPE %s gauge\n", name); err != nil {
+			return err
+		}
+		for _, sample := range families[name] {
+			if _, err := fmt.Fprintf(w, "%s{%s} %s\n", name, sample.labels, strconv.FormatFloat(sample.value, 'g', -1, 64)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// prometheusLabel formats a label pair, escaping the value and replacing
+// invalid UTF-8
+func prometheusLabel(name, value string) string {
+	return fmt.Sprintf(`%s="%s"`, name, labelEscaper.Replace(strings.ToValidUTF8(value, "?")))
+}
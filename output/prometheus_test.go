@@ -0,0 +1,57 @@
+package output
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"perf-runner/coordinator"
+	"perf-runner/runner"
+)
+
+func TestWritePrometheus(t *testing.T) {
+	results := []*coordinator.TestResult{
+		{
+			ScenarioName: `tcp "jumbo"`,
+			Success:      true,
+			ClientResult: &runner.Result{Metrics: map[string]interface{}{
+				"bandwidth_mbps": 934.5,
+				"retransmits":    int64(3),
+				"sizes":          []map[string]interface{}{{"bytes": 64}},
+			}},
+			ServerResult:  &runner.Result{Metrics: map[string]interface{}{"bandwidth_mbps": 930.0}},
+			ClientResults: map[string]*runner.Result{"c2": {Metrics: map[string]interface{}{"bandwidth_mbps": 900.0}}},
+		},
+		{ScenarioName: "udp", Success: true},
+		{ScenarioName: "udp", Success: false},
+	}
+
+	var buf bytes.Buffer
+	if err := WritePrometheus(&buf, results); err != nil {
+		t.Fatalf("WritePrometheus returned error: %v", err)
+	}
+
+	want := `# TYPE perf_runner_bandwidth_mbps gauge
+perf_runner_bandwidth_mbps{scenario="tcp \"jumbo\"",role="client"} 934.5
+perf_runner_bandwidth_mbps{scenario="tcp \"jumbo\"",role="server"} 930
+perf_runner_bandwidth_mbps{scenario="tcp \"jumbo\"",role="client",host="c2"} 900
+# TYPE perf_runner_retransmits gauge
+perf_runner_retransmits{scenario="tcp \"jumbo\"",role="client"} 3
+# TYPE perf_runner_test_success gauge
+perf_runner_test_success{scenario="tcp \"jumbo\""} 1
+perf_runner_test_success{scenario="udp",iteration="1"} 1
+perf_runner_test_success{scenario="udp",iteration="2"} 0
+`
+	if got := buf.String(); got != want {
+		t.Errorf("Expected:\n%s\ngot:\n%s", want, got)
+	}
+}
+
+func TestPrometheusLabel(t *testing.T) {
+	if got := prometheusLabel("scenario", "a\\b\nc\xff"); got != `scenario="a\\b\nc?"` {
+		t.Errorf("Expected an escaped label value, got %s", got)
+	}
+	if strings.Contains(invalidMetricNameChars.ReplaceAllString("rtt.ms-p99", "_"), ".") {
+		t.Error("Expected invalid metric name characters to be replaced")
+	}
+}
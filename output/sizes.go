@@ -0,0 +1,111 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+
+	"perf-runner/coordinator"
+)
+
+// sizeTable is one runner's primary metric over message size within a
+// comparison group, e.g. ib_send_bw bandwidth for 64 B to 64 KiB messages
+type sizeTable struct {
+	Group  string    `json:"group"`
+	Runner string    `json:"runner"`
+	Metric string    `json:"metric"`
+	Rows   []sizeRow `json:"rows"`
+}
+
+// sizeRow is the mean primary metric at one message size
+type sizeRow struct {
+	Bytes int64   `json:"bytes"`
+	Value float64 `json:"value"`
+}
+
+// newSizeTables builds a table for each comparison group and runner whose
+// successful results cover more than one message size, taken from the
+// client's "bytes" metric. A single result holding a size sweep (perftest
+// -a) contributes every size; untagged sweeps are grouped by scenario name.
+// Tables keep the order their groups first appear in, rows are sorted by
+// size, and repeated sizes are averaged.
+func newSizeTables(results []*coordinator.TestResult) []sizeTable {
+	type tableKey struct{ group, runner string }
+	var keys []tableKey
+	tables := make(map[tableKey]*sizeTable)
+	sums := make(map[tableKey]map[int64][2]float64) // size -> {sum, count}
+
+	add := func(key tableKey, metric string, bytes int64, value float64) {
+		if _, seen := tables[key]; !seen {
+			keys = append(keys, key)
+			tables[key] = &sizeTable{Group: key.group, Runner: key.runner, Metric: metric}
+			sums[key] = make(map[int64][2]float64)
+		}
+		sum := sums[key][bytes]
+		sums[key][bytes] = [2]float64{sum[0] + value, sum[1] + 1}
+	}
+
+	for _, result := range results {
+		if !result.Success || result.ClientResult == nil || result.PrimaryMetric == "" {
+			continue
+		}
+		metrics := result.ClientResult.Metrics
+
+		if sweep, ok := metrics["sizes"].([]map[string]interface{}); ok {
+			group := result.ComparisonGroup
+			if group == "" {
+				group = result.ScenarioName
+			}
+			for _, size := range sweep {
+				bytes, hasBytes := size["bytes"].(int64)
+				value, hasValue := size[result.PrimaryMetric].(float64)
+				if hasBytes && hasValue {
+					add(tableKey{group, result.Runner}, result.PrimaryMetric, bytes, value)
+				}
+			}
+			continue
+		}
+
+		bytes, hasBytes := metrics["bytes"].(int64)
+		value, hasValue := result.PrimaryValue()
+		if result.ComparisonGroup != "" && hasBytes && hasValue {
+			add(tableKey{result.ComparisonGroup, result.Runner}, result.PrimaryMetric, bytes, value)
+		}
+	}
+
+	var out []sizeTable
+	for _, key := range keys {
+		if len(sums[key]) < 2 {
+			continue
+		}
+		table := tables[key]
+		for bytes, sum := range sums[key] {
+			table.Rows = append(table.Rows, sizeRow{Bytes: bytes, Value: sum[0] / sum[1]})
+		}
+		sort.Slice(table.Rows, func(i, j int) bool { return table.Rows[i].Bytes < table.Rows[j].Bytes })
+		out = append(out, *table)
+	}
+	return out
+}
+
+// writeSizeTables renders each table under a "group (runner)" heading as
+// aligned size and metric columns
+func writeSizeTables(w io.Writer, tables []sizeTable) error {
+	for i, table := range tables {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintf(w, "%s (%s)\n", table.Group, table.Runner)
+
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintf(tw, "Bytes\t%s\n", table.Metric)
+		for _, row := range table.Rows {
+			fmt.Fprintf(tw, "%d\t%.2f\n", row.Bytes, row.Value)
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package output
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"perf-runner/coordinator"
+	"perf-runner/runner"
+)
+
+func sizeResult(group string, size int64, value float64) *coordinator.TestResult {
+	result := comparisonResult(group, "ib_send_bw", "bandwidth_average_mbps", value)
+	result.ClientResult.Metrics["bytes"] = size
+	return result
+}
+
+func TestSizeTables_OrdersBySize(t *testing.T) {
+	results := []*coordinator.TestResult{
+		sizeResult("sweep", 65536, 11800),
+		sizeResult("sweep", 64, 420),
+		sizeResult("sweep", 4096, 9100),
+		sizeResult("single", 64, 400),
+	}
+
+	tables := newSizeTables(results)
+	if len(tables) != 1 {
+		t.Fatalf("Expected one table (a single size is not a sweep), got %+v", tables)
+	}
+	table := tables[0]
+	if table.Group != "sweep" || table.Runner != "ib_send_bw" || table.Metric != "bandwidth_average_mbps" {
+		t.Errorf("Unexpected table identity: %+v", table)
+	}
+
+	var buf bytes.Buffer
+	if err := writeSizeTables(&buf, tables); err != nil {
+		t.Fatalf("writeSizeTables returned error: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	want := [][]string{
+		{"sweep", "(ib_send_bw)"},
+		{"Bytes", "bandwidth_average_mbps"},
+		{"64", "420.00"},
+		{"4096", "9100.00"},
+		{"65536", "11800.00"},
+	}
+	if len(lines) != len(want) {
+		t.Fatalf("Expected %d lines, got:\n%s", len(want), buf.String())
+	}
+	for i, fields := range want {
+		if got := strings.Join(strings.Fields(lines[i]), " "); got != strings.Join(fields, " ") {
+			t.Errorf("Line %d = %q, want %q", i, got, strings.Join(fields, " "))
+		}
+	}
+}
+
+func TestSizeTables_SweepResult(t *testing.T) {
+	result := &coordinator.TestResult{
+		ScenarioName:  "all sizes",
+		Success:       true,
+		Runner:        "ib_send_bw",
+		PrimaryMetric: "bandwidth_average_mbps",
+		ClientResult: &runner.Result{Success: true, Metrics: map[string]interface{}{
+			"bandwidth_average_mbps": 11800.0,
+			"sizes": []map[string]interface{}{
+				{"bytes": int64(2), "bandwidth_average_mbps": 12.5},
+				{"bytes": int64(8388608), "bandwidth_average_mbps": 11800.0},
+			},
+		}},
+	}
+
+	tables := newSizeTables([]*coordinator.TestResult{result})
+	if len(tables) != 1 || tables[0].Group != "all sizes" || len(tables[0].Rows) != 2 {
+		t.Fatalf("Expected a two-row table for the sweep, got %+v", tables)
+	}
+	if tables[0].Rows[0].Bytes != 2 || tables[0].Rows[0].Value != 12.5 {
+		t.Errorf("Unexpected first row %+v", tables[0].Rows[0])
+	}
+}
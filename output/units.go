@@ -0,0 +1,75 @@
+package output
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// bandwidthUnit is a display unit for bandwidth metrics
+type bandwidthUnit struct {
+	label       string
+	bitsPerUnit float64
+}
+
+// bandwidthUnits are the units accepted by -bw-unit. Lowercase "b" means
+// bits and uppercase "B" means bytes, so the names are case-sensitive.
+var bandwidthUnits = map[string]bandwidthUnit{
+	"mbps": {"Mbps", 1e6},
+	"gbps": {"Gbps", 1e9},
+	"MBps": {"MBps", 8e6},
+	"GBps": {"GBps", 8e9},
+}
+
+// bandwidthSuffixes maps the unit suffix of a bandwidth metric key to the
+// bits per second one unit of the metric stands for
+var bandwidthSuffixes = map[string]float64{
+	"_bps":  1,
+	"_kbps": 1e3,
+	"_mbps": 1e6,
+	"_gbps": 1e9,
+}
+
+// ValidateBandwidthUnit checks that unit is empty or a known bandwidth unit
+func ValidateBandwidthUnit(unit string) error {
+	if _, ok := bandwidthUnits[unit]; ok || unit == "" {
+		return nil
+	}
+	names := make([]string, 0, len(bandwidthUnits))
+	for name := range bandwidthUnits {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return fmt.Errorf("invalid bandwidth unit %q (must be one of %s)", unit, strings.Join(names, ", "))
+}
+
+// formatBandwidth renders bps in unit with two decimals, e.g. "1.00 Gbps"
+func formatBandwidth(bps float64, unit string) string {
+	u := bandwidthUnits[unit]
+	return fmt.Sprintf("%.2f %s", bps/u.bitsPerUnit, u.label)
+}
+
+// bandwidthMetric splits a bandwidth metric key such as "bandwidth_mbps"
+// into its base ("bandwidth") and the bits per second of one unit. ok is
+// false for metrics that are not bandwidths.
+func bandwidthMetric(key string) (base string, scale float64, ok bool) {
+	for suffix, scale := range bandwidthSuffixes {
+		if base, found := strings.CutSuffix(key, suffix); found {
+			return base, scale, true
+		}
+	}
+	return "", 0, false
+}
+
+// metricFloat returns a numeric metric value as a float64
+func metricFloat(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	}
+	return 0, false
+}
@@ -0,0 +1,79 @@
+package runner
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// autoWindowSize is the window_size value that sizes the TCP window from
+// the link's bandwidth-delay product
+const autoWindowSize = "auto"
+
+// rateMultipliers maps a link rate suffix to bits per second (decimal, as
+// iperf3 uses for bitrates)
+var rateMultipliers = map[byte]float64{
+	'K': 1e3,
+	'M': 1e6,
+	'G': 1e9,
+	'T': 1e12,
+}
+
+// ParseLinkRate parses a link rate such as "10G", "25Gbps" or "100M" into
+// bits per second
+func ParseLinkRate(value string) (float64, error) {
+	text := strings.TrimSuffix(strings.ToUpper(strings.TrimSpace(value)), "BPS")
+	if text == "" {
+		return 0, fmt.Errorf("empty link rate")
+	}
+
+	multiplier := 1.0
+	if m, ok := rateMultipliers[text[len(text)-1]]; ok {
+		multiplier = m
+		text = text[:len(text)-1]
+	}
+	rate, err := strconv.ParseFloat(text, 64)
+	if err != nil || rate <= 0 {
+		return 0, fmt.Errorf("invalid link rate %q (expected e.g. 10G or 100M)", value)
+	}
+	return rate * multiplier, nil
+}
+
+// BandwidthDelayProduct returns the bytes in flight on a link of rate bits
+// per second with the given round-trip time, the least window that keeps
+// the link full
+func BandwidthDelayProduct(rate float64, rtt time.Duration) int64 {
+	return int64(math.Ceil(rate * rtt.Seconds() / 8))
+}
+
+// RecommendedWindowSize returns the bandwidth-delay product as an iperf3
+// window size, rounded up to whole KiB, e.g. "1221K" for 10G at 1ms
+func RecommendedWindowSize(rate float64, rtt time.Duration) string {
+	kib := (BandwidthDelayProduct(rate, rtt) + 1023) / 1024
+	if kib < 1 {
+		kib = 1
+	}
+	return fmt.Sprintf("%dK", kib)
+}
+
+// resolveAutoWindowSize computes the window for window_size: auto from the
+// rtt (e.g. "1ms") and link_rate (e.g. "10G") args
+func resolveAutoWindowSize(args map[string]interface{}) (string, error) {
+	rttText, _ := args["rtt"].(string)
+	rateText, _ := args["link_rate"].(string)
+	if rttText == "" || rateText == "" {
+		return "", fmt.Errorf("window_size: auto requires rtt and link_rate args")
+	}
+
+	rtt, err := time.ParseDuration(rttText)
+	if err != nil || rtt <= 0 {
+		return "", fmt.Errorf("invalid rtt %q (expected a duration such as 1ms)", rttText)
+	}
+	rate, err := ParseLinkRate(rateText)
+	if err != nil {
+		return "", err
+	}
+	return RecommendedWindowSize(rate, rtt), nil
+}
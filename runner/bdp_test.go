@@ -0,0 +1,65 @@
+package runner
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBandwidthDelayProduct_10GAt1ms(t *testing.T) {
+	rate, err := ParseLinkRate("10G")
+	if err != nil {
+		t.Fatalf("ParseLinkRate: %v", err)
+	}
+	if bdp := BandwidthDelayProduct(rate, time.Millisecond); bdp != 1250000 {
+		t.Errorf("Expected a 1250000 byte BDP, got %d", bdp)
+	}
+	if window := RecommendedWindowSize(rate, time.Millisecond); window != "1221K" {
+		t.Errorf("Expected a 1221K window, got %s", window)
+	}
+}
+
+func TestParseLinkRate(t *testing.T) {
+	for _, tt := range []struct {
+		value string
+		want  float64
+	}{
+		{"10G", 10e9},
+		{"25Gbps", 25e9},
+		{"100m", 100e6},
+		{"1000000", 1e6},
+	} {
+		got, err := ParseLinkRate(tt.value)
+		if err != nil || got != tt.want {
+			t.Errorf("ParseLinkRate(%q) = %v, %v; want %v", tt.value, got, err, tt.want)
+		}
+	}
+	for _, value := range []string{"", "fast", "-1G"} {
+		if _, err := ParseLinkRate(value); err == nil {
+			t.Errorf("ParseLinkRate(%q): expected an error", value)
+		}
+	}
+}
+
+func TestIperf3Runner_AutoWindowSize(t *testing.T) {
+	r := NewIperf3Runner("")
+	config := Config{
+		Role: "client",
+		Host: "10.0.0.2",
+		Args: map[string]interface{}{"window_size": "auto", "rtt": "1ms", "link_rate": "10G"},
+	}
+	if err := r.Validate(config); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	if cmd := r.BuildCommand(config); !strings.Contains(cmd, " -w 1221K") {
+		t.Errorf("Expected the BDP window in %q", cmd)
+	}
+
+	delete(config.Args, "rtt")
+	if err := r.Validate(config); err == nil {
+		t.Error("Expected window_size: auto without rtt to fail validation")
+	}
+	if cmd := r.BuildCommand(config); strings.Contains(cmd, "-w") {
+		t.Errorf("Expected no window without rtt, got %q", cmd)
+	}
+}
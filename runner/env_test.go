@@ -116,7 +116,7 @@ func TestRunnerEnvironmentVariableIntegration(t *testing.T) {
 					"NUMA_POLICY":     "preferred",
 				},
 			},
-			expected: "LD_LIBRARY_PATH=/usr/local/lib NUMA_POLICY=preferred iperf3 -s -p 5201 -t 30 -J",
+			expected: "LD_LIBRARY_PATH=/usr/local/lib NUMA_POLICY=preferred iperf3 -s -1 -p 5201 -t 30 -J",
 		},
 		{
 			name:   "no environment variables",
@@ -0,0 +1,378 @@
+package runner
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Auto-register the ethr runner
+func init() {
+	Register("ethr", func() Runner {
+		return NewEthrRunner("")
+	})
+}
+
+// ethrTestTypes maps ethr's -t test types to the protocols they support
+var ethrTestTypes = map[string][]string{
+	"b": {ProtocolTCP, ProtocolUDP}, // bandwidth
+	"c": {ProtocolTCP},              // connections/s
+	"p": {ProtocolUDP},              // packets/s
+	"l": {ProtocolTCP},              // latency
+}
+
+// ethrRateMetrics maps the rate column of ethr's result table to a metric
+var ethrRateMetrics = map[string]string{
+	"Bits/s": "bandwidth_bps",
+	"Conn/s": "connections_per_sec",
+	"Pkts/s": "packets_per_sec",
+}
+
+// ethrLatencyMetrics names the columns of ethr's latency table, in order
+var ethrLatencyMetrics = []string{
+	"latency_avg_usec", "latency_min_usec", "latency_p50_usec", "latency_p90_usec", "latency_p95_usec",
+	"latency_p99_usec", "latency_p99_9_usec", "latency_p99_99_usec", "latency_max_usec",
+}
+
+// Rows of ethr's result table, e.g. "[SUM]     TCP      000-001 sec    18.78G"
+var (
+	ethrHeaderRegex = regexp.MustCompile(`^\[\s*ID\s*\]\s+Protocol\s+Interval\s+(\S+)`)
+	ethrRowRegex    = regexp.MustCompile(`^\[\s*(SUM|\d+)\s*\]\s+\S+\s+(\d+)-(\d+)\s+sec\s+(\d+\.?\d*)([KMGT]?)`)
+)
+
+// EthrRunner implements the Runner interface for ethr, which measures TCP
+// and UDP bandwidth, connections/s, packets/s, and latency with one binary.
+// The server (ethr -s) serves every test type until it is stopped.
+type EthrRunner struct {
+	executablePath string
+}
+
+// NewEthrRunner creates a new ethr runner
+func NewEthrRunner(executablePath string) *EthrRunner {
+	if executablePath == "" {
+		executablePath = "ethr"
+	}
+	return &EthrRunner{
+		executablePath: executablePath,
+	}
+}
+
+// Name returns the name of the runner
+func (r *EthrRunner) Name() string {
+	return "ethr"
+}
+
+// Description summarizes the runner for listings
+func (r *EthrRunner) Description() string {
+	return "Measures TCP/UDP bandwidth, connections/s, packets/s, and latency with ethr"
+}
+
+// SetExecutablePath sets the custom executable path for this runner
+func (r *EthrRunner) SetExecutablePath(path string) {
+	r.executablePath = path
+}
+
+// ExecutablePath returns the executable invoked on remote hosts
+func (r *EthrRunner) ExecutablePath() string {
+	return r.executablePath
+}
+
+// VersionCommand prints the first line of ethr's usage, which names its version
+func (r *EthrRunner) VersionCommand() string {
+	return fmt.Sprintf("%s -h 2>&1 | head -1", r.executablePath)
+}
+
+// ServerMode reports that the server must be stopped after the test
+func (r *EthrRunner) ServerMode() ServerMode {
+	return ServerPersistent
+}
+
+// ServesMultipleClients reports that one ethr server serves many clients
+func (r *EthrRunner) ServesMultipleClients() bool {
+	return true
+}
+
+// PrimaryMetric reports bandwidth in Mbps
+func (r *EthrRunner) PrimaryMetric() string {
+	return "bandwidth_mbps"
+}
+
+// OutputStream parses stdout, where ethr prints its result tables
+func (r *EthrRunner) OutputStream() Stream {
+	return StreamStdout
+}
+
+// SupportsRole returns true if the runner supports the given role
+func (r *EthrRunner) SupportsRole(role string) bool {
+	return role == "client" || role == "server"
+}
+
+// Validate checks if the configuration is valid for ethr
+func (r *EthrRunner) Validate(config Config) error {
+	if !r.SupportsRole(config.Role) {
+		return fmt.Errorf("unsupported role: %s", config.Role)
+	}
+
+	if config.Role == "client" && config.TargetHost == "" && config.Host == "" {
+		return fmt.Errorf("target_host or host is required for client role")
+	}
+
+	if err := validateProtocol(config); err != nil {
+		return err
+	}
+
+	testType := ethrTestType(config)
+	protocols, valid := ethrTestTypes[testType]
+	if !valid {
+		return fmt.Errorf("invalid test_type '%s' (must be b, c, p, or l)", testType)
+	}
+	if protocol := config.Protocol(); !containsString(protocols, protocol) {
+		return fmt.Errorf("test_type '%s' does not support protocol %s (use %s)", testType, protocol, strings.Join(protocols, " or "))
+	}
+
+	if streams, ok := config.GetEffectiveArgs()["parallel_streams"].(int); ok && streams <= 0 {
+		return fmt.Errorf("parallel_streams must be greater than 0")
+	}
+
+	if config.Port < 0 || config.Port > 65535 {
+		return fmt.Errorf("port must be between 0 and 65535")
+	}
+
+	return nil
+}
+
+// ethrTestType returns the test_type arg, or "b" (bandwidth) when none is set
+func ethrTestType(config Config) string {
+	testType, _ := config.GetEffectiveArgs()["test_type"].(string)
+	if testType == "" {
+		return "b"
+	}
+	return testType
+}
+
+// containsString reports whether values contains value
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// BuildCommand constructs the full command line for remote execution
+func (r *EthrRunner) BuildCommand(config Config) string {
+	envPrefix := buildEnvPrefix(config)
+	effectiveArgs := config.GetEffectiveArgs()
+
+	cmd := r.executablePath
+	if config.Role == "server" {
+		cmd += " -s"
+		if config.Port > 0 {
+			cmd += fmt.Sprintf(" -port %d", config.Port)
+		}
+		return envPrefix + cmd
+	}
+
+	targetHost := config.TargetHost
+	if targetHost == "" {
+		targetHost = config.Host
+	}
+	cmd += fmt.Sprintf(" -c %s -p %s", targetHost, config.Protocol())
+	if config.Duration > 0 {
+		cmd += fmt.Sprintf(" -d %ds", int(config.Duration.Seconds()))
+	}
+	cmd += " -t " + ethrTestType(config)
+
+	if config.Port > 0 {
+		cmd += fmt.Sprintf(" -port %d", config.Port)
+	}
+	if streams, ok := effectiveArgs["parallel_streams"].(int); ok && streams > 0 {
+		cmd += fmt.Sprintf(" -n %d", streams)
+	}
+	if length, ok := effectiveArgs["buffer_length"].(string); ok && length != "" {
+		cmd += " -l " + length
+	}
+	if bitrate, ok := effectiveArgs["bitrate"].(string); ok && bitrate != "" {
+		cmd += " -b " + bitrate
+	}
+	if reverse, ok := effectiveArgs["reverse"].(bool); ok && reverse {
+		cmd += " -r"
+	}
+
+	return envPrefix + cmd
+}
+
+// ParseMetrics extracts the client's results from ethr's tables. Rates are
+// averaged over the per-second intervals, each the [SUM] row of the interval
+// or, for a single stream, its only row; a final row spanning the whole test
+// is used instead when ethr prints one. Latency columns are averaged over
+// the reporting periods, except min and max, which are the extremes.
+func (r *EthrRunner) ParseMetrics(result *Result) error {
+	if result == nil {
+		return fmt.Errorf("result cannot be nil")
+	}
+
+	if result.Metrics == nil {
+		result.Metrics = make(map[string]interface{})
+	}
+
+	rates := make(map[string][]ethrInterval)
+	var latencyRows [][]float64
+	column := ""
+	inLatencyTable := false
+	for _, line := range strings.Split(result.Output, "\n") {
+		line = strings.TrimSpace(line)
+		if match := ethrHeaderRegex.FindStringSubmatch(line); match != nil {
+			column = match[1]
+			continue
+		}
+		if strings.HasPrefix(line, "Avg") && strings.HasSuffix(line, "Max") {
+			inLatencyTable = true
+			continue
+		}
+		if inLatencyTable {
+			inLatencyTable = false
+			if row, ok := parseEthrLatencyRow(line); ok {
+				latencyRows = append(latencyRows, row)
+			}
+			continue
+		}
+
+		metric, known := ethrRateMetrics[column]
+		match := ethrRowRegex.FindStringSubmatch(line)
+		if !known || match == nil {
+			continue
+		}
+		start, _ := strconv.Atoi(match[2])
+		end, _ := strconv.Atoi(match[3])
+		value, err := strconv.ParseFloat(match[4], 64)
+		if err != nil {
+			continue
+		}
+		value *= ethrUnitScale(match[5])
+		rates[metric] = addEthrRow(rates[metric], start, end, match[1] == "SUM", value)
+	}
+
+	for metric, intervals := range rates {
+		value := ethrRate(intervals)
+		result.Metrics[metric] = value
+		if metric == "bandwidth_bps" {
+			result.Metrics["bandwidth_mbps"] = value / 1e6
+			result.Metrics["bandwidth_gbps"] = value / 1e9
+		}
+	}
+
+	if len(latencyRows) > 0 {
+		for i, metric := range ethrLatencyMetrics {
+			value := latencyRows[0][i]
+			for _, row := range latencyRows[1:] {
+				switch metric {
+				case "latency_min_usec":
+					value = math.Min(value, row[i])
+				case "latency_max_usec":
+					value = math.Max(value, row[i])
+				default:
+					value += row[i]
+				}
+			}
+			if metric != "latency_min_usec" && metric != "latency_max_usec" {
+				value /= float64(len(latencyRows))
+			}
+			result.Metrics[metric] = value
+		}
+	}
+
+	return nil
+}
+
+// ethrInterval is the total rate of one reporting interval
+type ethrInterval struct {
+	start, end int
+	value      float64
+	sum        bool
+}
+
+// addEthrRow adds a stream's row to its interval's total. A [SUM] row
+// replaces the per-stream rows of its interval.
+func addEthrRow(intervals []ethrInterval, start, end int, sum bool, value float64) []ethrInterval {
+	for i := range intervals {
+		interval := &intervals[i]
+		if interval.start != start || interval.end != end {
+			continue
+		}
+		switch {
+		case sum:
+			interval.value, interval.sum = value, true
+		case !interval.sum:
+			interval.value += value
+		}
+		return intervals
+	}
+	return append(intervals, ethrInterval{start: start, end: end, value: value, sum: sum})
+}
+
+// ethrRate returns the whole-test rate: the longest interval's if it spans
+// more than the others, else the mean over the intervals
+func ethrRate(intervals []ethrInterval) float64 {
+	longest := intervals[0]
+	total := 0.0
+	for _, interval := range intervals {
+		if interval.end-interval.start > longest.end-longest.start {
+			longest = interval
+		}
+		total += interval.value
+	}
+	if len(intervals) > 1 && longest.end-longest.start > intervals[0].end-intervals[0].start {
+		return longest.value
+	}
+	return total / float64(len(intervals))
+}
+
+// ethrUnitScale returns the multiplier of ethr's K/M/G/T rate suffixes
+func ethrUnitScale(suffix string) float64 {
+	switch suffix {
+	case "K":
+		return 1e3
+	case "M":
+		return 1e6
+	case "G":
+		return 1e9
+	case "T":
+		return 1e12
+	}
+	return 1
+}
+
+// parseEthrLatencyRow parses a row of ethr's latency table, such as
+// "95.35us 61.12us ...", into microseconds
+func parseEthrLatencyRow(line string) ([]float64, bool) {
+	fields := strings.Fields(line)
+	if len(fields) != len(ethrLatencyMetrics) {
+		return nil, false
+	}
+	row := make([]float64, len(fields))
+	for i, field := range fields {
+		number := strings.TrimRight(field, "nuµms")
+		value, err := strconv.ParseFloat(number, 64)
+		if err != nil {
+			return nil, false
+		}
+		switch field[len(number):] {
+		case "ns":
+			value /= 1e3
+		case "us", "µs":
+			// already microseconds
+		case "ms":
+			value *= 1e3
+		case "s":
+			value *= 1e6
+		default:
+			return nil, false
+		}
+		row[i] = value
+	}
+	return row, true
+}
@@ -0,0 +1,146 @@
+package runner
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+const ethrBandwidthSample = `Ethr: Comprehensive Network Performance Measurement Tool (Version: v1.0.0)
+Using destination: 10.0.0.2, ip: 10.0.0.2, port: 8888
+[  5] local 10.0.0.1 port 50960 connected to 10.0.0.2 port 8888
+[  6] local 10.0.0.1 port 50962 connected to 10.0.0.2 port 8888
+- - - - - - - - - - - - - - - - - - - - - - -
+[ ID ]   Protocol    Interval      Bits/s
+[  5]     TCP      000-001 sec     4.60G
+[  6]     TCP      000-001 sec     4.40G
+[SUM]     TCP      000-001 sec     9.00G
+- - - - - - - - - - - - - - - - - - - - - - -
+[  5]     TCP      001-002 sec     4.70G
+[  6]     TCP      001-002 sec     4.70G
+[SUM]     TCP      001-002 sec     9.40G
+Ethr done, duration: 2s.
+`
+
+func TestEthrRunner_BuildCommand(t *testing.T) {
+	r := NewEthrRunner("")
+	config := Config{
+		Role:       "client",
+		TargetHost: "10.0.0.2",
+		Port:       9999,
+		Duration:   10 * time.Second,
+		Args:       map[string]interface{}{"protocol": "udp", "test_type": "p", "parallel_streams": 4, "bitrate": "1G"},
+	}
+	want := "ethr -c 10.0.0.2 -p udp -d 10s -t p -port 9999 -n 4 -b 1G"
+	if got := r.BuildCommand(config); got != want {
+		t.Errorf("client command = %q, want %q", got, want)
+	}
+
+	config.Args = nil
+	if got, want := r.BuildCommand(config), "ethr -c 10.0.0.2 -p tcp -d 10s -t b -port 9999"; got != want {
+		t.Errorf("default client command = %q, want %q", got, want)
+	}
+
+	config.Role = "server"
+	if got, want := r.BuildCommand(config), "ethr -s -port 9999"; got != want {
+		t.Errorf("server command = %q, want %q", got, want)
+	}
+}
+
+func TestEthrRunner_Validate(t *testing.T) {
+	r := NewEthrRunner("")
+	tests := []struct {
+		name    string
+		config  Config
+		wantErr bool
+	}{
+		{"client", Config{Role: "client", TargetHost: "h"}, false},
+		{"server", Config{Role: "server"}, false},
+		{"connections", Config{Role: "client", Host: "h", Args: map[string]interface{}{"test_type": "c"}}, false},
+		{"no target", Config{Role: "client"}, true},
+		{"bad protocol", Config{Role: "client", Host: "h", Args: map[string]interface{}{"protocol": "sctp"}}, true},
+		{"bad test type", Config{Role: "client", Host: "h", Args: map[string]interface{}{"test_type": "x"}}, true},
+		{"udp latency", Config{Role: "client", Host: "h", Args: map[string]interface{}{"protocol": "udp", "test_type": "l"}}, true},
+		{"intermediate", Config{Role: "intermediate"}, true},
+	}
+	for _, tt := range tests {
+		if err := r.Validate(tt.config); (err != nil) != tt.wantErr {
+			t.Errorf("%s: error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestEthrRunner_ParseMetrics_Bandwidth(t *testing.T) {
+	result := &Result{Output: ethrBandwidthSample}
+	if err := NewEthrRunner("").ParseMetrics(result); err != nil {
+		t.Fatalf("ParseMetrics returned error: %v", err)
+	}
+	if got := result.Metrics["bandwidth_bps"]; got != 9.2e9 {
+		t.Errorf("Expected the mean of the [SUM] rows, got %v", got)
+	}
+	if got := result.Metrics["bandwidth_mbps"]; got != 9200.0 {
+		t.Errorf("bandwidth_mbps = %v, want 9200", got)
+	}
+}
+
+func TestEthrRunner_ParseMetrics_SingleStreamWithSummary(t *testing.T) {
+	output := `[ ID ]   Protocol    Interval      Bits/s
+[  5]     TCP      000-001 sec     9.00G
+[  5]     TCP      001-002 sec     9.60G
+[  5]     TCP      002-003 sec     9.60G
+- - - - - - - - - - - - - - - - - - - - - - -
+[ ID ]   Protocol    Interval      Bits/s
+[SUM]     TCP      000-003 sec     9.35G
+`
+	result := &Result{Output: output}
+	if err := NewEthrRunner("").ParseMetrics(result); err != nil {
+		t.Fatalf("ParseMetrics returned error: %v", err)
+	}
+	if got := result.Metrics["bandwidth_bps"]; got != 9.35e9 {
+		t.Errorf("Expected the whole-test summary row, got %v", got)
+	}
+}
+
+func TestEthrRunner_ParseMetrics_ConnectionsPerSecond(t *testing.T) {
+	output := `[ ID ]   Protocol    Interval      Conn/s
+[SUM]     TCP      000-001 sec     12.50K
+[SUM]     TCP      001-002 sec     13.50K
+`
+	result := &Result{Output: output}
+	if err := NewEthrRunner("").ParseMetrics(result); err != nil {
+		t.Fatalf("ParseMetrics returned error: %v", err)
+	}
+	if got := result.Metrics["connections_per_sec"]; got != 13000.0 {
+		t.Errorf("connections_per_sec = %v, want 13000", got)
+	}
+	if _, exists := result.Metrics["bandwidth_bps"]; exists {
+		t.Error("Expected no bandwidth from a connections/s test")
+	}
+}
+
+func TestEthrRunner_ParseMetrics_Latency(t *testing.T) {
+	output := `-----------------------------------------------------------------------------------------
+    Avg       Min       50%       90%       95%       99%     99.9%    99.99%       Max
+  90.00us   60.00us   88.00us  110.00us  120.00us  170.00us  430.00us  650.00us  800.00us
+-----------------------------------------------------------------------------------------
+    Avg       Min       50%       90%       95%       99%     99.9%    99.99%       Max
+ 110.00us   55.00us   92.00us  118.00us  130.00us  190.00us  440.00us  660.00us    1.20ms
+`
+	result := &Result{Output: output}
+	if err := NewEthrRunner("").ParseMetrics(result); err != nil {
+		t.Fatalf("ParseMetrics returned error: %v", err)
+	}
+	want := map[string]float64{
+		"latency_avg_usec":   100,
+		"latency_min_usec":   55,
+		"latency_p99_usec":   180,
+		"latency_p99_9_usec": 435,
+		"latency_max_usec":   1200,
+	}
+	for key, value := range want {
+		got, ok := result.Metrics[key].(float64)
+		if !ok || math.Abs(got-value) > 1e-9 {
+			t.Errorf("%s = %v, want %v", key, result.Metrics[key], value)
+		}
+	}
+}
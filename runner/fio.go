@@ -0,0 +1,227 @@
+package runner
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Auto-register the fio runner
+func init() {
+	Register("fio", func() Runner {
+		return NewFioRunner("")
+	})
+}
+
+// fioRWModes are the I/O patterns accepted by fio's --rw
+var fioRWModes = map[string]bool{
+	"read": true, "write": true, "trim": true,
+	"randread": true, "randwrite": true, "randtrim": true,
+	"rw": true, "readwrite": true, "randrw": true, "trimwrite": true,
+}
+
+// fioReport is the part of fio's JSON output the runner reads
+type fioReport struct {
+	Jobs []struct {
+		Read  fioStats `json:"read"`
+		Write fioStats `json:"write"`
+	} `json:"jobs"`
+}
+
+// fioStats are one direction's results of a job. Bandwidth is in KiB/s and
+// latencies in nanoseconds.
+type fioStats struct {
+	IOPS  float64 `json:"iops"`
+	BW    float64 `json:"bw"`
+	LatNs struct {
+		Mean float64 `json:"mean"`
+	} `json:"lat_ns"`
+	ClatNs struct {
+		Percentile map[string]float64 `json:"percentile"`
+	} `json:"clat_ns"`
+}
+
+// FioRunner implements the Runner interface for fio, a storage benchmark.
+// fio runs on the client host alone against a local file or block device,
+// such as an NVMe-over-fabrics namespace, so the runner has no server role.
+type FioRunner struct {
+	executablePath string
+}
+
+// NewFioRunner creates a new fio runner
+func NewFioRunner(executablePath string) *FioRunner {
+	if executablePath == "" {
+		executablePath = "fio"
+	}
+	return &FioRunner{
+		executablePath: executablePath,
+	}
+}
+
+// Name returns the name of the runner
+func (r *FioRunner) Name() string {
+	return "fio"
+}
+
+// Description summarizes the runner for listings
+func (r *FioRunner) Description() string {
+	return "Measures storage IOPS, bandwidth, and latency with fio (client only)"
+}
+
+// SetExecutablePath sets the custom executable path for this runner
+func (r *FioRunner) SetExecutablePath(path string) {
+	r.executablePath = path
+}
+
+// ExecutablePath returns the executable invoked on remote hosts
+func (r *FioRunner) ExecutablePath() string {
+	return r.executablePath
+}
+
+// VersionCommand prints fio's version, e.g. "fio-3.28"
+func (r *FioRunner) VersionCommand() string {
+	return r.executablePath + " --version"
+}
+
+// ServerMode is unused, as fio has no server
+func (r *FioRunner) ServerMode() ServerMode {
+	return ServerOneShot
+}
+
+// PrimaryMetric reports the total IOPS of reads and writes
+func (r *FioRunner) PrimaryMetric() string {
+	return "iops"
+}
+
+// OutputStream parses stdout, where fio prints its JSON report
+func (r *FioRunner) OutputStream() Stream {
+	return StreamStdout
+}
+
+// SupportsRole returns true only for the client, the host fio runs on
+func (r *FioRunner) SupportsRole(role string) bool {
+	return role == "client"
+}
+
+// Validate checks if the configuration is valid for fio
+func (r *FioRunner) Validate(config Config) error {
+	if !r.SupportsRole(config.Role) {
+		return fmt.Errorf("fio runs on the client host only and has no %s role; remove the scenario's %s", config.Role, config.Role)
+	}
+
+	effectiveArgs := config.GetEffectiveArgs()
+	if filename, _ := effectiveArgs["filename"].(string); filename == "" {
+		return fmt.Errorf("filename is required, e.g. /dev/nvme1n1")
+	}
+	if rw, exists := effectiveArgs["rw"]; exists {
+		if mode, ok := rw.(string); !ok || !fioRWModes[mode] {
+			return fmt.Errorf("invalid rw %v, e.g. randread, randwrite, or randrw", rw)
+		}
+	}
+	for _, key := range []string{"iodepth", "numjobs", "runtime"} {
+		if value, ok := effectiveArgs[key].(int); ok && value <= 0 {
+			return fmt.Errorf("%s must be greater than 0", key)
+		}
+	}
+
+	return nil
+}
+
+// BuildCommand constructs the full command line for remote execution. The
+// runtime arg, or else the duration, makes the job time based; jobs are
+// reported as one group.
+func (r *FioRunner) BuildCommand(config Config) string {
+	envPrefix := buildEnvPrefix(config)
+	effectiveArgs := config.GetEffectiveArgs()
+
+	cmd := r.executablePath + " --name=perf-runner"
+	if filename, ok := effectiveArgs["filename"].(string); ok && filename != "" {
+		cmd += " --filename=" + filename
+	}
+	for _, key := range []string{"rw", "bs", "iodepth", "numjobs", "ioengine", "size"} {
+		if value, exists := effectiveArgs[key]; exists {
+			cmd += fmt.Sprintf(" --%s=%v", key, value)
+		}
+	}
+	if direct, ok := effectiveArgs["direct"].(bool); ok && direct {
+		cmd += " --direct=1"
+	}
+
+	runtime, _ := effectiveArgs["runtime"].(int)
+	if runtime <= 0 && config.Duration > 0 {
+		runtime = int(config.Duration.Seconds())
+	}
+	if runtime > 0 {
+		cmd += fmt.Sprintf(" --runtime=%d --time_based", runtime)
+	}
+
+	return envPrefix + cmd + " --group_reporting --output-format=json"
+}
+
+// ParseMetrics extracts IOPS, bandwidth, and latency per direction from
+// fio's JSON report, summing IOPS and bandwidth over jobs and keeping the
+// worst latency. Directions without I/O are left out. Completion latency
+// percentiles become latency_<dir>_pN_usec, e.g. latency_read_p99_9_usec.
+func (r *FioRunner) ParseMetrics(result *Result) error {
+	if result == nil {
+		return fmt.Errorf("result cannot be nil")
+	}
+
+	if result.Metrics == nil {
+		result.Metrics = make(map[string]interface{})
+	}
+
+	start := strings.Index(result.Output, "{")
+	end := strings.LastIndex(result.Output, "}")
+	if start == -1 || end < start {
+		return fmt.Errorf("no fio JSON report in output")
+	}
+	var report fioReport
+	if err := json.Unmarshal([]byte(result.Output[start:end+1]), &report); err != nil {
+		return fmt.Errorf("failed to decode fio JSON report: %w", err)
+	}
+
+	var totalIOPS, totalBW float64
+	for _, direction := range []string{"read", "write"} {
+		var iops, bw float64
+		latencies := make(map[string]float64)
+		for _, job := range report.Jobs {
+			stats := job.Read
+			if direction == "write" {
+				stats = job.Write
+			}
+			iops += stats.IOPS
+			bw += stats.BW
+			if stats.IOPS == 0 {
+				continue
+			}
+			latencies["latency_"+direction+"_avg_usec"] = stats.LatNs.Mean / 1e3
+			for percentile, ns := range stats.ClatNs.Percentile {
+				value, err := strconv.ParseFloat(percentile, 64)
+				if err != nil {
+					continue
+				}
+				name := strings.ReplaceAll(strconv.FormatFloat(value, 'f', -1, 64), ".", "_")
+				latencies["latency_"+direction+"_p"+name+"_usec"] = ns / 1e3
+			}
+		}
+		if iops == 0 && bw == 0 {
+			continue
+		}
+
+		result.Metrics[direction+"_iops"] = iops
+		result.Metrics[direction+"_bw_kib_s"] = bw
+		for key, usec := range latencies {
+			if existing, ok := result.Metrics[key].(float64); !ok || usec > existing {
+				result.Metrics[key] = usec
+			}
+		}
+		totalIOPS += iops
+		totalBW += bw
+	}
+	result.Metrics["iops"] = totalIOPS
+	result.Metrics["bw_kib_s"] = totalBW
+
+	return nil
+}
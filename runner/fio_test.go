@@ -0,0 +1,117 @@
+package runner
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const fioSample = `note: both iodepth >= 1 and synchronous I/O engine are selected
+{
+  "fio version" : "fio-3.28",
+  "jobs" : [
+    {
+      "jobname" : "perf-runner",
+      "read" : {
+        "bw" : 409600,
+        "iops" : 102400.5,
+        "lat_ns" : { "mean" : 310250.0 },
+        "clat_ns" : { "percentile" : { "50.000000" : 301056, "99.000000" : 610304, "99.900000" : 1003520 } }
+      },
+      "write" : {
+        "bw" : 102400,
+        "iops" : 25600.0,
+        "lat_ns" : { "mean" : 150000.0 },
+        "clat_ns" : { "percentile" : { "99.000000" : 250880 } }
+      }
+    },
+    {
+      "jobname" : "perf-runner",
+      "read" : {
+        "bw" : 409600,
+        "iops" : 102399.5,
+        "lat_ns" : { "mean" : 320500.0 },
+        "clat_ns" : { "percentile" : { "99.000000" : 700416 } }
+      },
+      "write" : { "bw" : 0, "iops" : 0 }
+    }
+  ]
+}
+`
+
+func TestFioRunner_BuildCommand(t *testing.T) {
+	r := NewFioRunner("")
+	config := Config{
+		Role:     "client",
+		Duration: 30 * time.Second,
+		Args:     map[string]interface{}{"filename": "/dev/nvme1n1", "rw": "randread", "bs": "4k", "iodepth": 32, "direct": true},
+	}
+	want := "fio --name=perf-runner --filename=/dev/nvme1n1 --rw=randread --bs=4k --iodepth=32 --direct=1 --runtime=30 --time_based --group_reporting --output-format=json"
+	if got := r.BuildCommand(config); got != want {
+		t.Errorf("BuildCommand = %q, want %q", got, want)
+	}
+
+	config.Args["runtime"] = 10
+	if got := r.BuildCommand(config); !strings.Contains(got, "--runtime=10 --time_based") {
+		t.Errorf("Expected the runtime arg to override the duration, got %q", got)
+	}
+}
+
+func TestFioRunner_Validate(t *testing.T) {
+	r := NewFioRunner("")
+	tests := []struct {
+		name    string
+		config  Config
+		wantErr bool
+	}{
+		{"client", Config{Role: "client", Args: map[string]interface{}{"filename": "/tmp/fio", "rw": "randrw"}}, false},
+		{"no filename", Config{Role: "client"}, true},
+		{"bad rw", Config{Role: "client", Args: map[string]interface{}{"filename": "/tmp/fio", "rw": "sideways"}}, true},
+		{"zero iodepth", Config{Role: "client", Args: map[string]interface{}{"filename": "/tmp/fio", "iodepth": 0}}, true},
+		{"server", Config{Role: "server"}, true},
+		{"intermediate", Config{Role: "intermediate"}, true},
+	}
+	for _, tt := range tests {
+		if err := r.Validate(tt.config); (err != nil) != tt.wantErr {
+			t.Errorf("%s: error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+
+	if err := r.Validate(Config{Role: "server"}); err == nil || !strings.Contains(err.Error(), "remove the scenario's server") {
+		t.Errorf("Expected the server error to say how to fix the scenario, got %v", err)
+	}
+}
+
+func TestFioRunner_ParseMetrics(t *testing.T) {
+	result := &Result{Output: fioSample}
+	if err := NewFioRunner("").ParseMetrics(result); err != nil {
+		t.Fatalf("ParseMetrics returned error: %v", err)
+	}
+
+	want := map[string]interface{}{
+		"read_iops":               204800.0,
+		"read_bw_kib_s":           819200.0,
+		"write_iops":              25600.0,
+		"write_bw_kib_s":          102400.0,
+		"iops":                    230400.0,
+		"bw_kib_s":                921600.0,
+		"latency_read_avg_usec":   320.5,
+		"latency_read_p50_usec":   301.056,
+		"latency_read_p99_usec":   700.416,
+		"latency_read_p99_9_usec": 1003.52,
+		"latency_write_avg_usec":  150.0,
+		"latency_write_p99_usec":  250.88,
+	}
+	for key, value := range want {
+		if result.Metrics[key] != value {
+			t.Errorf("%s = %v, want %v", key, result.Metrics[key], value)
+		}
+	}
+}
+
+func TestFioRunner_ParseMetrics_NoReport(t *testing.T) {
+	result := &Result{Output: "fio: failed to open /dev/nvme1n1: Permission denied\n"}
+	if err := NewFioRunner("").ParseMetrics(result); err == nil {
+		t.Error("Expected an error without a JSON report")
+	}
+}
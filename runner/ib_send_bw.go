@@ -34,11 +34,41 @@ func (r *IbSendBwRunner) Name() string {
 	return "ib_send_bw"
 }
 
+// Description summarizes the runner for listings
+func (r *IbSendBwRunner) Description() string {
+	return "Measures InfiniBand/RoCE send bandwidth with perftest ib_send_bw"
+}
+
 // SetExecutablePath sets the custom executable path for this runner
 func (r *IbSendBwRunner) SetExecutablePath(path string) {
 	r.executablePath = path
 }
 
+// ExecutablePath returns the executable invoked on remote hosts
+func (r *IbSendBwRunner) ExecutablePath() string {
+	return r.executablePath
+}
+
+// VersionCommand prints the ib_send_bw version banner
+func (r *IbSendBwRunner) VersionCommand() string {
+	return fmt.Sprintf("%s --version 2>&1 | head -1", r.executablePath)
+}
+
+// ServerMode reports that ib_send_bw servers exit once their iterations complete
+func (r *IbSendBwRunner) ServerMode() ServerMode {
+	return ServerOneShot
+}
+
+// PrimaryMetric reports the average bandwidth of the largest message size
+func (r *IbSendBwRunner) PrimaryMetric() string {
+	return "bandwidth_average_mbps"
+}
+
+// OutputStream parses combined output; perftest versions differ in where they print
+func (r *IbSendBwRunner) OutputStream() Stream {
+	return StreamCombined
+}
+
 // SupportsRole returns true if the runner supports the given role
 func (r *IbSendBwRunner) SupportsRole(role string) bool {
 	return role == "client" || role == "server" || role == "intermediate"
@@ -64,7 +94,7 @@ func (r *IbSendBwRunner) Validate(config Config) error {
 		}
 	}
 	
-	return nil
+	return validatePerftestArgs(config)
 }
 
 
@@ -101,118 +131,85 @@ func (r *IbSendBwRunner) BuildCommand(config Config) string {
 	}
 	// Server mode doesn't need a host argument
 	
-	// Port (if specified)
-	if config.Port > 0 {
-		cmd += fmt.Sprintf(" -p %d", config.Port)
+	// Message size range - perftest has no min/max size flags, so run one
+	// test per power-of-two size, each on its own port
+	if sizes, ok := sweepSizes(config); ok {
+		return buildSizeSweep(envPrefix+appendPerftestFlags(cmd, withoutPort(config)), sizes, config)
 	}
 	
-	// Duration (if specified) - ib_send_bw uses -D flag
-	if config.Duration > 0 {
-		cmd += fmt.Sprintf(" -D %d", int(config.Duration.Seconds()))
+	cmd = appendPerftestFlags(cmd, config)
+
+	return envPrefix + cmd
+}
+
+// ReadinessProbe waits for the server's port, or every port of a size
+// sweep, to be listening
+func (r *IbSendBwRunner) ReadinessProbe(config Config) ReadinessProbe {
+	return perftestReadinessProbe(config)
+}
+
+// expandSizeRange converts a [min, max] size_range argument into the list of
+// message sizes to test: powers of two from min up to max, always including max
+func expandSizeRange(value interface{}) ([]int, error) {
+	bounds, ok := value.([]interface{})
+	if !ok || len(bounds) != 2 {
+		return nil, fmt.Errorf("size_range must be a list of two sizes [min, max]")
 	}
 	
-	// Additional arguments from config (use effective args based on role)
-	effectiveArgs := config.GetEffectiveArgs()
-	for key, value := range effectiveArgs {
-		switch key {
-		case "size":
-			// Message size in bytes
-			if size, ok := value.(int); ok {
-				cmd += fmt.Sprintf(" -s %d", size)
-			} else if sizeStr, ok := value.(string); ok {
-				cmd += fmt.Sprintf(" -s %s", sizeStr)
-			}
-		case "iterations":
-			// Number of iterations
-			if iter, ok := value.(int); ok {
-				cmd += fmt.Sprintf(" -n %d", iter)
-			}
-		case "tx_depth":
-			// Send queue depth
-			if depth, ok := value.(int); ok {
-				cmd += fmt.Sprintf(" -t %d", depth)
-			}
-		case "rx_depth":
-			// Receive queue depth  
-			if depth, ok := value.(int); ok {
-				cmd += fmt.Sprintf(" -r %d", depth)
-			}
-		case "mtu":
-			// MTU size
-			if mtu, ok := value.(int); ok {
-				cmd += fmt.Sprintf(" -m %d", mtu)
-			}
-		case "qp":
-			// Number of QPs
-			if qp, ok := value.(int); ok {
-				cmd += fmt.Sprintf(" -q %d", qp)
-			}
-		case "connection":
-			// Connection type (RC/UC/UD)
-			if conn, ok := value.(string); ok {
-				cmd += fmt.Sprintf(" -c %s", conn)
-			}
-		case "inline":
-			// Inline size
-			if inline, ok := value.(int); ok {
-				cmd += fmt.Sprintf(" -I %d", inline)
-			}
-		case "ib_dev":
-			// InfiniBand device
-			if dev, ok := value.(string); ok {
-				cmd += fmt.Sprintf(" -d %s", dev)
-			}
-		case "gid_index":
-			// GID index
-			if gid, ok := value.(int); ok {
-				cmd += fmt.Sprintf(" -x %d", gid)
-			}
-		case "sl":
-			// Service level
-			if sl, ok := value.(int); ok {
-				cmd += fmt.Sprintf(" -S %d", sl)
-			}
-		case "cpu_freq":
-			// CPU frequency for cycles calculation
-			if freq, ok := value.(float64); ok {
-				cmd += fmt.Sprintf(" -F %.2f", freq)
-			}
-		case "use_event":
-			// Use event completion
-			if useEvent, ok := value.(bool); ok && useEvent {
-				cmd += " -e"
-			}
-		case "bidirectional":
-			// Bidirectional test
-			if bidir, ok := value.(bool); ok && bidir {
-				cmd += " -b"
-			}
-		case "report_cycles":
-			// Report CPU cycles
-			if cycles, ok := value.(bool); ok && cycles {
-				cmd += " -C"
-			}
-		case "report_histogram":
-			// Report latency histogram
-			if hist, ok := value.(bool); ok && hist {
-				cmd += " -H"
-			}
-		case "odp":
-			// Use On Demand Paging
-			if odp, ok := value.(bool); ok && odp {
-				cmd += " -o"
-			}
-		case "report_gbits":
-			// Report in Gb/sec instead of MB/sec
-			if gbits, ok := value.(bool); ok && gbits {
-				cmd += " -R"
-			}
-		}
+	minSize, minOk := bounds[0].(int)
+	maxSize, maxOk := bounds[1].(int)
+	if !minOk || !maxOk {
+		return nil, fmt.Errorf("size_range values must be integers")
+	}
+	if minSize <= 0 || maxSize < minSize {
+		return nil, fmt.Errorf("size_range must satisfy 0 < min <= max, got [%d, %d]", minSize, maxSize)
 	}
+	
+	var sizes []int
+	for size := minSize; size < maxSize; size *= 2 {
+		sizes = append(sizes, size)
+	}
+	sizes = append(sizes, maxSize)
+	
+	return sizes, nil
+}
 
-	return envPrefix + cmd
+// sweepSizes returns the message sizes of the config's size_range sweep,
+// if it has one
+func sweepSizes(config Config) ([]int, bool) {
+	sizeRange, exists := config.GetEffectiveArgs()["size_range"]
+	if !exists {
+		return nil, false
+	}
+	sizes, err := expandSizeRange(sizeRange)
+	return sizes, err == nil
+}
+
+// withoutPort returns config without its port, for commands that choose the
+// port themselves
+func withoutPort(config Config) Config {
+	config.Port = 0
+	return config
 }
 
+// buildSizeSweep wraps a command in a shell loop that runs it once per
+// message size. Each size uses its own port, counting up from the
+// configured one. Listening roles start a listener for every size at once
+// and wait for them all, so the client, which runs the sizes in order,
+// never races a listener that is still starting.
+func buildSizeSweep(cmd string, sizes []int, config Config) string {
+	sizeList := make([]string, len(sizes))
+	for i, size := range sizes {
+		sizeList[i] = strconv.Itoa(size)
+	}
+	
+	body := fmt.Sprintf("%s -s $s -p $p", cmd)
+	if config.Role == "client" {
+		return fmt.Sprintf("p=%d; for s in %s; do %s; p=$((p+1)); done", perftestPort(config), strings.Join(sizeList, " "), body)
+	}
+	return fmt.Sprintf(`p=%d; pids=; for s in %s; do %s & pids="$pids $!"; p=$((p+1)); done; r=0; for pid in $pids; do wait $pid || r=1; done; [ $r -eq 0 ]`,
+		perftestPort(config), strings.Join(sizeList, " "), body)
+}
 
 // ParseMetrics extracts performance metrics from ib_send_bw output
 func (r *IbSendBwRunner) ParseMetrics(result *Result) error {
@@ -226,25 +223,38 @@ func (r *IbSendBwRunner) ParseMetrics(result *Result) error {
 	output := result.Output
 	lines := strings.Split(output, "\n")
 	
-	// Look for the results table
+	// Look for the results table(s). A size sweep (-a or size_range) produces
+	// one row per message size, possibly spread over several tables.
+	var rows []string
+	tableFound := false
 	for i, line := range lines {
 		// ib_send_bw typically outputs a table with headers like:
 		// #bytes     #iterations    BW peak[MB/sec]    BW average[MB/sec]   MsgRate[Mpps]
 		if strings.Contains(line, "#bytes") && strings.Contains(line, "BW") {
-			// Parse the data line (usually the next line)
-			if i+1 < len(lines) {
-				dataLine := strings.TrimSpace(lines[i+1])
-				if dataLine != "" && !strings.HasPrefix(dataLine, "#") {
-					r.parseResultLine(dataLine, result)
-				}
-			}
-			break
+			tableFound = true
+			rows = append(rows, collectResultRows(lines[i+1:])...)
+			continue
 		}
 		
 		// Also look for single result lines (some versions output differently)
-		if strings.Contains(line, "MB/sec") || strings.Contains(line, "Gb/sec") {
-			r.parseResultLine(line, result)
+		if !tableFound && (strings.Contains(line, "MB/sec") || strings.Contains(line, "Gb/sec")) {
+			r.parseResultLine(line, result.Metrics)
+		}
+	}
+	
+	if len(rows) > 0 {
+		// Top-level metrics come from the last (largest) message size
+		r.parseResultLine(rows[len(rows)-1], result.Metrics)
+	}
+	
+	if len(rows) > 1 {
+		sizes := make([]map[string]interface{}, 0, len(rows))
+		for _, row := range rows {
+			sizeMetrics := make(map[string]interface{})
+			r.parseResultLine(row, sizeMetrics)
+			sizes = append(sizes, sizeMetrics)
 		}
+		result.Metrics["sizes"] = sizes
 	}
 	
 	// Parse additional information
@@ -294,8 +304,25 @@ func (r *IbSendBwRunner) ParseMetrics(result *Result) error {
 	return nil
 }
 
+// collectResultRows returns the data rows following a results table header,
+// stopping at the first line that does not start with a message size
+func collectResultRows(lines []string) []string {
+	var rows []string
+	for _, line := range lines {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			break
+		}
+		if _, err := strconv.ParseInt(fields[0], 10, 64); err != nil {
+			break
+		}
+		rows = append(rows, strings.TrimSpace(line))
+	}
+	return rows
+}
+
 // parseResultLine parses a result line containing bandwidth measurements
-func (r *IbSendBwRunner) parseResultLine(line string, result *Result) {
+func (r *IbSendBwRunner) parseResultLine(line string, metrics map[string]interface{}) {
 	// Split by whitespace
 	fields := strings.Fields(line)
 	
@@ -307,31 +334,31 @@ func (r *IbSendBwRunner) parseResultLine(line string, result *Result) {
 				case 0:
 					// Usually bytes
 					if value > 0 {
-						result.Metrics["bytes"] = int64(value)
+						metrics["bytes"] = int64(value)
 					}
 				case 1:
 					// Usually iterations
 					if value > 0 {
-						result.Metrics["iterations"] = int64(value)
+						metrics["iterations"] = int64(value)
 					}
 				case 2:
 					// Usually BW peak
 					if value > 0 {
-						result.Metrics["bandwidth_peak_mbps"] = value
+						metrics["bandwidth_peak_mbps"] = value
 						// Convert to bits per second
-						result.Metrics["bandwidth_peak_bps"] = value * 1e6 * 8
+						metrics["bandwidth_peak_bps"] = value * 1e6 * 8
 					}
 				case 3:
 					// Usually BW average
 					if value > 0 {
-						result.Metrics["bandwidth_average_mbps"] = value
-						result.Metrics["bandwidth_average_bps"] = value * 1e6 * 8
+						metrics["bandwidth_average_mbps"] = value
+						metrics["bandwidth_average_bps"] = value * 1e6 * 8
 					}
 				case 4:
 					// Usually message rate
 					if value > 0 {
-						result.Metrics["message_rate_mpps"] = value
-						result.Metrics["message_rate_pps"] = value * 1e6
+						metrics["message_rate_mpps"] = value
+						metrics["message_rate_pps"] = value * 1e6
 					}
 				}
 			}
@@ -345,16 +372,16 @@ func (r *IbSendBwRunner) parseResultLine(line string, result *Result) {
 			unit := matches[2]
 			switch unit {
 			case "MB/sec":
-				result.Metrics["bandwidth_mbps"] = bw
-				result.Metrics["bandwidth_bps"] = bw * 1e6 * 8
+				metrics["bandwidth_mbps"] = bw
+				metrics["bandwidth_bps"] = bw * 1e6 * 8
 			case "GB/sec":
-				result.Metrics["bandwidth_gbps"] = bw
-				result.Metrics["bandwidth_bps"] = bw * 1e9 * 8
+				metrics["bandwidth_gbps"] = bw
+				metrics["bandwidth_bps"] = bw * 1e9 * 8
 			case "Gb/sec":
-				result.Metrics["bandwidth_gbps"] = bw
-				result.Metrics["bandwidth_bps"] = bw * 1e9
+				metrics["bandwidth_gbps"] = bw
+				metrics["bandwidth_bps"] = bw * 1e9
 			}
-			result.Metrics["bandwidth_readable"] = matches[0]
+			metrics["bandwidth_readable"] = matches[0]
 		}
 	}
 	
@@ -365,13 +392,13 @@ func (r *IbSendBwRunner) parseResultLine(line string, result *Result) {
 			unit := matches[2]
 			switch unit {
 			case "Mpps":
-				result.Metrics["message_rate_mpps"] = rate
-				result.Metrics["message_rate_pps"] = rate * 1e6
+				metrics["message_rate_mpps"] = rate
+				metrics["message_rate_pps"] = rate * 1e6
 			case "Kpps":
-				result.Metrics["message_rate_kpps"] = rate
-				result.Metrics["message_rate_pps"] = rate * 1e3
+				metrics["message_rate_kpps"] = rate
+				metrics["message_rate_pps"] = rate * 1e3
 			case "pps":
-				result.Metrics["message_rate_pps"] = rate
+				metrics["message_rate_pps"] = rate
 			}
 		}
 	}
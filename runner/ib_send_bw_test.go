@@ -488,4 +488,145 @@ func TestIbSendBwRunner_ParseMetrics_ErrorCases(t *testing.T) {
 			}
 		})
 	}
-}
\ No newline at end of file
+}
+
+func TestIbSendBwRunner_ParseMetrics_SizeSweep(t *testing.T) {
+	runner := NewIbSendBwRunner("")
+
+	output := `---------------------------------------------------------------------------------------
+ #bytes     #iterations    BW peak[MB/sec]    BW average[MB/sec]   MsgRate[Mpps]
+ 2          1000             9.19               9.06               4.750528
+ 4          1000             18.38              18.27              4.789139
+ 8          1000             36.77              36.54              4.789478
+ 8388608    1000             11538.91           11538.46           0.001375
+---------------------------------------------------------------------------------------`
+
+	result := &Result{Output: output}
+	if err := runner.ParseMetrics(result); err != nil {
+		t.Fatalf("ParseMetrics returned error: %v", err)
+	}
+
+	sizes, ok := result.Metrics["sizes"].([]map[string]interface{})
+	if !ok {
+		t.Fatalf("Expected sizes to be []map[string]interface{}, got %T", result.Metrics["sizes"])
+	}
+	if len(sizes) != 4 {
+		t.Fatalf("Expected 4 size rows, got %d", len(sizes))
+	}
+
+	expected := []struct {
+		bytes   int64
+		avgMbps float64
+	}{
+		{2, 9.06},
+		{4, 18.27},
+		{8, 36.54},
+		{8388608, 11538.46},
+	}
+	for i, exp := range expected {
+		if sizes[i]["bytes"] != exp.bytes {
+			t.Errorf("Row %d: expected bytes %d, got %v", i, exp.bytes, sizes[i]["bytes"])
+		}
+		if sizes[i]["bandwidth_average_mbps"] != exp.avgMbps {
+			t.Errorf("Row %d: expected bandwidth_average_mbps %v, got %v", i, exp.avgMbps, sizes[i]["bandwidth_average_mbps"])
+		}
+	}
+
+	// Top-level metrics reflect the largest message size
+	if result.Metrics["bytes"] != int64(8388608) {
+		t.Errorf("Expected top-level bytes 8388608, got %v", result.Metrics["bytes"])
+	}
+}
+
+func TestIbSendBwRunner_ParseMetrics_SizeRangeTables(t *testing.T) {
+	runner := NewIbSendBwRunner("")
+
+	// size_range runs one test per size, each printing its own table
+	output := ` #bytes     #iterations    BW peak[MB/sec]    BW average[MB/sec]   MsgRate[Mpps]
+ 1024       1000             5000.00            4900.00            4.785156
+---------------------------------------------------------------------------------------
+ #bytes     #iterations    BW peak[MB/sec]    BW average[MB/sec]   MsgRate[Mpps]
+ 2048       1000             8000.00            7900.00            3.857422
+---------------------------------------------------------------------------------------`
+
+	result := &Result{Output: output}
+	if err := runner.ParseMetrics(result); err != nil {
+		t.Fatalf("ParseMetrics returned error: %v", err)
+	}
+
+	sizes, ok := result.Metrics["sizes"].([]map[string]interface{})
+	if !ok || len(sizes) != 2 {
+		t.Fatalf("Expected 2 size rows, got %v", result.Metrics["sizes"])
+	}
+	if sizes[0]["bytes"] != int64(1024) || sizes[1]["bytes"] != int64(2048) {
+		t.Errorf("Unexpected size order: %v, %v", sizes[0]["bytes"], sizes[1]["bytes"])
+	}
+}
+
+func TestIbSendBwRunner_SizeSweepArgs(t *testing.T) {
+	runner := NewIbSendBwRunner("")
+
+	t.Run("size_all", func(t *testing.T) {
+		cmd := runner.BuildCommand(Config{
+			Role: "server",
+			Args: map[string]interface{}{"size_all": true},
+		})
+		if cmd != "ib_send_bw -a" {
+			t.Errorf("Expected 'ib_send_bw -a', got %q", cmd)
+		}
+	})
+
+	t.Run("size_range server", func(t *testing.T) {
+		cmd := runner.BuildCommand(Config{
+			Role: "server",
+			Args: map[string]interface{}{"size_range": []interface{}{1024, 5000}},
+		})
+		expected := `p=18515; pids=; for s in 1024 2048 4096 5000; do ib_send_bw -s $s -p $p & pids="$pids $!"; p=$((p+1)); done; ` +
+			`r=0; for pid in $pids; do wait $pid || r=1; done; [ $r -eq 0 ]`
+		if cmd != expected {
+			t.Errorf("Expected %q, got %q", expected, cmd)
+		}
+	})
+
+	t.Run("size_range client", func(t *testing.T) {
+		cmd := runner.BuildCommand(Config{
+			Role:       "client",
+			TargetHost: "10.0.0.1",
+			Port:       7000,
+			Args:       map[string]interface{}{"size_range": []interface{}{4096, 8192}},
+		})
+		expected := "p=7000; for s in 4096 8192; do ib_send_bw 10.0.0.1 -s $s -p $p; p=$((p+1)); done"
+		if cmd != expected {
+			t.Errorf("Expected %q, got %q", expected, cmd)
+		}
+	})
+
+	t.Run("size_range readiness", func(t *testing.T) {
+		probe := runner.ReadinessProbe(Config{
+			Role: "server",
+			Port: 7000,
+			Args: map[string]interface{}{"size_range": []interface{}{4096, 8192}},
+		})
+		expected := "ss -ltn | grep -q ':7000[[:space:]]' && ss -ltn | grep -q ':7001[[:space:]]'"
+		if probe.Command != expected {
+			t.Errorf("Expected probe %q, got %q", expected, probe.Command)
+		}
+	})
+
+	invalid := []struct {
+		name string
+		args map[string]interface{}
+	}{
+		{"not a list", map[string]interface{}{"size_range": 1024}},
+		{"min greater than max", map[string]interface{}{"size_range": []interface{}{8192, 1024}}},
+		{"combined with size", map[string]interface{}{"size_range": []interface{}{1024, 2048}, "size": 4096}},
+		{"combined with size_all", map[string]interface{}{"size_range": []interface{}{1024, 2048}, "size_all": true}},
+	}
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := runner.Validate(Config{Role: "server", Args: tt.args}); err == nil {
+				t.Errorf("Expected validation error for %s", tt.name)
+			}
+		})
+	}
+}
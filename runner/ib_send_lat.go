@@ -0,0 +1,209 @@
+package runner
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Auto-register the ib_send_lat runner
+func init() {
+	Register("ib_send_lat", func() Runner {
+		return NewIbSendLatRunner("")
+	})
+}
+
+// latencyColumns maps perftest latency table columns, with the unit suffix
+// removed, to metric keys. perftest's "typical" latency is the median.
+var latencyColumns = map[string]string{
+	"#bytes":           "bytes",
+	"#iterations":      "iterations",
+	"t_min":            "latency_min_usec",
+	"t_max":            "latency_max_usec",
+	"t_typical":        "latency_p50_usec",
+	"t_avg":            "latency_avg_usec",
+	"t_stdev":          "latency_stdev_usec",
+	"99% percentile":   "latency_p99_usec",
+	"99.9% percentile": "latency_p99_9_usec",
+}
+
+// IbSendLatRunner implements the Runner interface for perftest's ib_send_lat.
+// It takes the same args as ib_send_bw.
+type IbSendLatRunner struct {
+	executablePath string
+}
+
+// NewIbSendLatRunner creates a new ib_send_lat runner
+func NewIbSendLatRunner(executablePath string) *IbSendLatRunner {
+	if executablePath == "" {
+		executablePath = "ib_send_lat"
+	}
+	return &IbSendLatRunner{
+		executablePath: executablePath,
+	}
+}
+
+// Name returns the name of the runner
+func (r *IbSendLatRunner) Name() string {
+	return "ib_send_lat"
+}
+
+// Description summarizes the runner for listings
+func (r *IbSendLatRunner) Description() string {
+	return "Measures InfiniBand/RoCE send latency percentiles with perftest ib_send_lat"
+}
+
+// SetExecutablePath sets the custom executable path for this runner
+func (r *IbSendLatRunner) SetExecutablePath(path string) {
+	r.executablePath = path
+}
+
+// ExecutablePath returns the executable invoked on remote hosts
+func (r *IbSendLatRunner) ExecutablePath() string {
+	return r.executablePath
+}
+
+// VersionCommand prints the ib_send_lat version banner
+func (r *IbSendLatRunner) VersionCommand() string {
+	return fmt.Sprintf("%s --version 2>&1 | head -1", r.executablePath)
+}
+
+// ServerMode reports that ib_send_lat servers exit once their iterations complete
+func (r *IbSendLatRunner) ServerMode() ServerMode {
+	return ServerOneShot
+}
+
+// PrimaryMetric reports the median latency of the largest message size
+func (r *IbSendLatRunner) PrimaryMetric() string {
+	return "latency_p50_usec"
+}
+
+// OutputStream parses combined output; perftest versions differ in where they print
+func (r *IbSendLatRunner) OutputStream() Stream {
+	return StreamCombined
+}
+
+// SupportsRole returns true if the runner supports the given role
+func (r *IbSendLatRunner) SupportsRole(role string) bool {
+	return role == "client" || role == "server"
+}
+
+// Validate checks if the configuration is valid for ib_send_lat
+func (r *IbSendLatRunner) Validate(config Config) error {
+	if !r.SupportsRole(config.Role) {
+		return fmt.Errorf("unsupported role: %s", config.Role)
+	}
+
+	if config.Role == "client" && config.TargetHost == "" && config.Host == "" {
+		return fmt.Errorf("target_host or host is required for client role")
+	}
+
+	return validatePerftestArgs(config)
+}
+
+// BuildCommand constructs the full command line for remote execution
+func (r *IbSendLatRunner) BuildCommand(config Config) string {
+	envPrefix := buildEnvPrefix(config)
+
+	cmd := r.executablePath
+	if config.Role == "client" {
+		targetHost := config.TargetHost
+		if targetHost == "" {
+			targetHost = config.Host
+		}
+		if targetHost != "" {
+			cmd += " " + targetHost
+		}
+	}
+
+	if sizes, ok := sweepSizes(config); ok {
+		return buildSizeSweep(envPrefix+appendPerftestFlags(cmd, withoutPort(config)), sizes, config)
+	}
+
+	return envPrefix + appendPerftestFlags(cmd, config)
+}
+
+// ReadinessProbe waits for the server's port, or every port of a size
+// sweep, to be listening
+func (r *IbSendLatRunner) ReadinessProbe(config Config) ReadinessProbe {
+	return perftestReadinessProbe(config)
+}
+
+// ParseMetrics extracts latency statistics from the ib_send_lat results
+// table. Top-level metrics come from the last (largest) message size; a size
+// sweep also lists every size under "sizes".
+func (r *IbSendLatRunner) ParseMetrics(result *Result) error {
+	if result == nil {
+		return fmt.Errorf("result cannot be nil")
+	}
+
+	if result.Metrics == nil {
+		result.Metrics = make(map[string]interface{})
+	}
+
+	var rows []map[string]interface{}
+	lines := strings.Split(result.Output, "\n")
+	for i, line := range lines {
+		if !strings.Contains(line, "#bytes") || !strings.Contains(line, "t_min") {
+			continue
+		}
+		columns := latencyHeaderColumns(line)
+		for _, row := range collectResultRows(lines[i+1:]) {
+			rows = append(rows, parseLatencyRow(columns, row))
+		}
+	}
+
+	if len(rows) == 0 {
+		return nil
+	}
+	for key, value := range rows[len(rows)-1] {
+		result.Metrics[key] = value
+	}
+	if len(rows) > 1 {
+		result.Metrics["sizes"] = rows
+	}
+
+	return nil
+}
+
+// latencyHeaderColumns splits a latency table header into column names,
+// e.g. "t_min" or "99% percentile", with the "[usec]" unit removed
+func latencyHeaderColumns(header string) []string {
+	var columns []string
+	fields := strings.Fields(header)
+	for i := 0; i < len(fields); i++ {
+		name := fields[i]
+		// Percentile columns span two words: "99% percentile[usec]"
+		if strings.HasSuffix(name, "%") && i+1 < len(fields) {
+			name += " " + fields[i+1]
+			i++
+		}
+		columns = append(columns, strings.TrimSuffix(name, "[usec]"))
+	}
+	return columns
+}
+
+// parseLatencyRow maps the values of a latency table row to metric keys by
+// column; unknown columns are skipped
+func parseLatencyRow(columns []string, row string) map[string]interface{} {
+	metrics := make(map[string]interface{})
+	for i, field := range strings.Fields(row) {
+		if i >= len(columns) {
+			break
+		}
+		key, known := latencyColumns[columns[i]]
+		if !known {
+			continue
+		}
+		if key == "bytes" || key == "iterations" {
+			if value, err := strconv.ParseInt(field, 10, 64); err == nil {
+				metrics[key] = value
+			}
+			continue
+		}
+		if value, err := strconv.ParseFloat(field, 64); err == nil {
+			metrics[key] = value
+		}
+	}
+	return metrics
+}
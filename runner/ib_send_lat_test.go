@@ -0,0 +1,76 @@
+package runner
+
+import (
+	"strings"
+	"testing"
+)
+
+const ibSendLatSample = `---------------------------------------------------------------------------------------
+                    Send Latency Test
+ Dual-port       : OFF		Device         : mlx5_0
+ Number of qps   : 1		Transport type : IB
+ Connection type : RC		Using SRQ      : OFF
+ TX depth        : 1
+ Mtu             : 4096[B]
+ Link type       : IB
+ Max inline data : 236[B]
+ rdma_cm QPs	 : OFF
+ Data ex. method : Ethernet
+---------------------------------------------------------------------------------------
+ local address: LID 0x03 QPN 0x0148 PSN 0x7e3e1a
+ remote address: LID 0x04 QPN 0x0148 PSN 0x3b1f2c
+---------------------------------------------------------------------------------------
+ #bytes #iterations    t_min[usec]    t_max[usec]  t_typical[usec]    t_avg[usec]    t_stdev[usec]   99% percentile[usec]   99.9% percentile[usec] 
+ 2       1000          0.95           4.32         0.98     	       0.99        	0.05   		1.12    		4.32   
+ 4096    1000          2.41           7.80         2.47     	       2.49        	0.06   		2.71    		7.80   
+---------------------------------------------------------------------------------------
+`
+
+func TestIbSendLatRunner_ParseMetrics(t *testing.T) {
+	result := &Result{Output: ibSendLatSample}
+	if err := NewIbSendLatRunner("").ParseMetrics(result); err != nil {
+		t.Fatalf("ParseMetrics returned error: %v", err)
+	}
+
+	want := map[string]interface{}{
+		"bytes":              int64(4096),
+		"iterations":         int64(1000),
+		"latency_min_usec":   2.41,
+		"latency_max_usec":   7.80,
+		"latency_p50_usec":   2.47,
+		"latency_avg_usec":   2.49,
+		"latency_stdev_usec": 0.06,
+		"latency_p99_usec":   2.71,
+		"latency_p99_9_usec": 7.80,
+	}
+	for key, value := range want {
+		if result.Metrics[key] != value {
+			t.Errorf("%s = %v, want %v", key, result.Metrics[key], value)
+		}
+	}
+
+	sizes, ok := result.Metrics["sizes"].([]map[string]interface{})
+	if !ok || len(sizes) != 2 || sizes[0]["bytes"] != int64(2) || sizes[0]["latency_p99_usec"] != 1.12 {
+		t.Errorf("Expected both sizes listed, got %v", result.Metrics["sizes"])
+	}
+}
+
+func TestIbSendLatRunner_BuildCommand(t *testing.T) {
+	r := NewIbSendLatRunner("")
+	config := Config{
+		Role:       "client",
+		TargetHost: "10.0.0.2",
+		Port:       18515,
+		Args:       map[string]interface{}{"ib_dev": "mlx5_0"},
+	}
+
+	cmd := r.BuildCommand(config)
+	if !strings.HasPrefix(cmd, "ib_send_lat 10.0.0.2 -p 18515") || !strings.Contains(cmd, " -d mlx5_0") {
+		t.Errorf("Unexpected client command %q", cmd)
+	}
+
+	config.Role = "server"
+	if cmd := r.BuildCommand(config); cmd != "ib_send_lat -p 18515 -d mlx5_0" {
+		t.Errorf("Unexpected server command %q", cmd)
+	}
+}
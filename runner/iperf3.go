@@ -1,6 +1,8 @@
 package runner
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"strconv"
 	"strings"
@@ -33,11 +35,51 @@ func (r *Iperf3Runner) Name() string {
 	return "iperf3"
 }
 
+// Description summarizes the runner for listings
+func (r *Iperf3Runner) Description() string {
+	return "Measures TCP/UDP throughput with iperf3"
+}
+
 // SetExecutablePath sets the custom executable path for this runner
 func (r *Iperf3Runner) SetExecutablePath(path string) {
 	r.executablePath = path
 }
 
+// ExecutablePath returns the executable invoked on remote hosts
+func (r *Iperf3Runner) ExecutablePath() string {
+	return r.executablePath
+}
+
+// VersionCommand prints the iperf3 version banner
+func (r *Iperf3Runner) VersionCommand() string {
+	return fmt.Sprintf("%s --version 2>&1 | head -1", r.executablePath)
+}
+
+// ServerMode reports that the iperf3 server exits after one test (it runs with -1)
+func (r *Iperf3Runner) ServerMode() ServerMode {
+	return ServerOneShot
+}
+
+// PrimaryMetric reports the client bandwidth in megabits per second
+func (r *Iperf3Runner) PrimaryMetric() string {
+	return "bandwidth_mbps"
+}
+
+// OutputStream parses combined output; errors and the summary may land on either stream
+func (r *Iperf3Runner) OutputStream() Stream {
+	return StreamCombined
+}
+
+// ReadinessProbe waits for the server's port to be listening; iperf3
+// accepts no client before then
+func (r *Iperf3Runner) ReadinessProbe(config Config) ReadinessProbe {
+	port := config.Port
+	if port <= 0 {
+		port = 5201 // Default iperf3 port
+	}
+	return ListeningProbe(port)
+}
+
 // SupportsRole returns true if the runner supports the given role
 func (r *Iperf3Runner) SupportsRole(role string) bool {
 	return role == "client" || role == "server" || role == "intermediate"
@@ -73,6 +115,17 @@ func (r *Iperf3Runner) Validate(config Config) error {
 		}
 	}
 	
+	if err := validateProtocol(config); err != nil {
+		return err
+	}
+	
+	// window_size: auto is sized from the rtt and link_rate args
+	if window, _ := effectiveArgs["window_size"].(string); window == autoWindowSize {
+		if _, err := resolveAutoWindowSize(effectiveArgs); err != nil {
+			return err
+		}
+	}
+	
 	// Validate port if specified
 	if config.Port < 0 || config.Port > 65535 {
 		return fmt.Errorf("port must be between 0 and 65535")
@@ -90,7 +143,8 @@ func (r *Iperf3Runner) BuildCommand(config Config) string {
 	
 	// Set role (server, client, or intermediate)
 	if config.Role == "server" {
-		cmd += " -s"
+		// Exit after one test so the coordinator can wait for the server's result
+		cmd += " -s -1"
 	} else if config.Role == "client" {
 		// Client mode - determine target host
 		targetHost := config.TargetHost
@@ -137,14 +191,20 @@ func (r *Iperf3Runner) BuildCommand(config Config) string {
 	
 	// Additional arguments from config (use effective args based on role)
 	effectiveArgs := config.GetEffectiveArgs()
-	for key, value := range effectiveArgs {
+	for _, key := range sortedArgKeys(effectiveArgs) {
+		value := effectiveArgs[key]
 		switch key {
 		case "parallel_streams":
 			if streams, ok := value.(int); ok && streams > 0 {
 				cmd += fmt.Sprintf(" -P %d", streams)
 			}
 		case "window_size":
-			if window, ok := value.(string); ok && window != "" {
+			window, ok := value.(string)
+			if ok && window == autoWindowSize {
+				// Invalid rtt or link_rate is reported by Validate
+				window, _ = resolveAutoWindowSize(effectiveArgs)
+			}
+			if ok && window != "" {
 				cmd += fmt.Sprintf(" -w %s", window)
 			}
 		case "reverse":
@@ -160,7 +220,8 @@ func (r *Iperf3Runner) BuildCommand(config Config) string {
 				cmd += fmt.Sprintf(" -i %d", interval)
 			}
 		case "protocol":
-			if protocol, ok := value.(string); ok && strings.ToLower(protocol) == "udp" {
+			// The server follows the client's protocol
+			if UsesProtocolFlag(r, config.Role) && config.Protocol() == ProtocolUDP {
 				cmd += " -u"
 			}
 		case "ipv6":
@@ -187,12 +248,37 @@ func (r *Iperf3Runner) BuildCommand(config Config) string {
 			if verbose, ok := value.(bool); ok && verbose {
 				cmd += " -V"
 			}
+		case "congestion":
+			if congestion, ok := value.(string); ok && congestion != "" {
+				cmd += fmt.Sprintf(" -C %s", congestion)
+			}
 		}
 	}
 
 	return envPrefix + cmd
 }
 
+// Cleanup stops socat relay children of the intermediate role. socat forks a
+// child per connection, and children still forwarding when the listener is
+// stopped would otherwise keep holding the connection to the server.
+func (r *Iperf3Runner) Cleanup(ctx context.Context, executor CommandExecutor, config Config) error {
+	if config.Role != "intermediate" {
+		return nil
+	}
+	
+	listenPort := config.Port
+	if listenPort <= 0 {
+		listenPort = 5201 // Default iperf3 port
+	}
+	
+	// pkill exits 1 when nothing matched, which is the usual case
+	command := fmt.Sprintf("pkill -f '^socat TCP-LISTEN:%d,fork' || true", listenPort)
+	if _, err := executor.Execute(ctx, command); err != nil {
+		return fmt.Errorf("failed to stop socat relays on port %d: %w", listenPort, err)
+	}
+	return nil
+}
+
 // ParseMetrics extracts performance metrics from iperf3 JSON output
 func (r *Iperf3Runner) ParseMetrics(result *Result) error {
 	if result == nil {
@@ -209,48 +295,159 @@ func (r *Iperf3Runner) ParseMetrics(result *Result) error {
 	if strings.Contains(output, `"start"`) && strings.Contains(output, `"end"`) {
 		// JSON output detected - parse key metrics
 		r.parseJSONMetrics(result, output)
-	} else {
-		// Text output fallback - parse basic metrics
-		r.parseTextMetrics(result, output)
 	}
 	
+	// Text output fallback - parse basic metrics. Any text lines mixed into
+	// JSON output only fill gaps; JSON-derived metrics always win.
+	textMetrics := make(map[string]interface{})
+	r.parseTextMetrics(textMetrics, output)
+	mergeMetrics(result.Metrics, textMetrics, "iperf3 text output")
+	
 	return nil
 }
 
 // parseJSONMetrics extracts metrics from iperf3 JSON output
 func (r *Iperf3Runner) parseJSONMetrics(result *Result, output string) {
-	// Extract bits per second from sum_received or sum_sent
-	// First try sum_received (for client output), then sum_sent
-	if bps := r.extractNumericValue(output, `"bits_per_second"`); bps > 0 {
+	report, ok := decodeIperf3Report(output)
+	if !ok {
+		return
+	}
+	
+	// Report both directions; the headline bandwidth is the sender's total,
+	// or the receiver's in a reverse (-R) test, where the client receives
+	sent, received := report.End.SumSent, report.End.SumReceived
+	if sent == nil && received == nil {
+		// UDP reports of older iperf3 versions only have a combined sum
+		sent = report.End.Sum
+	}
+	if sent != nil {
+		result.Metrics["bandwidth_sent_bps"] = sent.BitsPerSecond
+	}
+	if received != nil {
+		result.Metrics["bandwidth_received_bps"] = received.BitsPerSecond
+	}
+	
+	headline := sent
+	if headline == nil || (report.Start.TestStart.Reverse != 0 && received != nil) {
+		headline = received
+	}
+	if headline != nil && headline.BitsPerSecond > 0 {
+		bps := headline.BitsPerSecond
 		result.Metrics["bandwidth_bps"] = bps
 		result.Metrics["bandwidth_mbps"] = bps / 1e6
 		result.Metrics["bandwidth_gbps"] = bps / 1e9
 	}
 	
-	// Extract retransmits if present
-	if strings.Contains(output, `"retransmits"`) {
-		if retrans := r.extractNumericValue(output, `"retransmits"`); retrans >= 0 {
-			result.Metrics["retransmits"] = int(retrans)
-		}
+	// Only the sending side counts retransmits
+	if sent != nil && sent.Retransmits != nil {
+		result.Metrics["retransmits"] = *sent.Retransmits
 	}
 	
 	// Extract parallel streams
-	if strings.Contains(output, `"streams"`) {
-		if streams := r.extractNumericValue(output, `"streams"`); streams > 0 {
-			result.Metrics["parallel_streams"] = int(streams)
-		}
+	if streams := report.Start.TestStart.NumStreams; streams > 0 {
+		result.Metrics["parallel_streams"] = streams
+	} else if streams := len(report.End.Streams); streams > 0 {
+		result.Metrics["parallel_streams"] = streams
+	}
+	
+	// num_streams is what -P asked for, while end.streams lists the streams
+	// that were established; a server stream limit can make them differ
+	if requested := report.Start.TestStart.NumStreams; requested > 0 {
+		result.Metrics["streams_requested"] = requested
+		result.Metrics["streams_actual"] = len(report.End.Streams)
+	}
+	
+	// Keep per-interval, per-stream samples for time-series output
+	if intervals := report.intervalRows(); len(intervals) > 0 {
+		result.Metrics["intervals"] = intervals
 	}
 	
 	// Extract actual test duration
-	if strings.Contains(output, `"duration"`) {
-		if duration := r.extractNumericValue(output, `"duration"`); duration > 0 {
-			result.Metrics["actual_duration"] = duration
+	if headline != nil && headline.Seconds > 0 {
+		result.Metrics["actual_duration"] = headline.Seconds
+	} else if duration := report.Start.TestStart.Duration; duration > 0 {
+		result.Metrics["actual_duration"] = duration
+	}
+}
+
+// iperf3Report mirrors the parts of iperf3 JSON output (-J) the runner reads
+type iperf3Report struct {
+	Start struct {
+		TestStart struct {
+			Protocol   string  `json:"protocol"`
+			NumStreams int     `json:"num_streams"`
+			Duration   float64 `json:"duration"`
+			Reverse    int     `json:"reverse"`
+		} `json:"test_start"`
+	} `json:"start"`
+	Intervals []struct {
+		Streams []iperf3StreamSample `json:"streams"`
+	} `json:"intervals"`
+	End struct {
+		Streams     []json.RawMessage `json:"streams"`
+		SumSent     *iperf3Sum        `json:"sum_sent"`
+		SumReceived *iperf3Sum        `json:"sum_received"`
+		Sum         *iperf3Sum        `json:"sum"`
+	} `json:"end"`
+}
+
+// iperf3StreamSample is one stream's throughput over one reporting interval.
+// Retransmits are only reported by a TCP sender.
+type iperf3StreamSample struct {
+	Socket        int     `json:"socket"`
+	Start         float64 `json:"start"`
+	End           float64 `json:"end"`
+	BitsPerSecond float64 `json:"bits_per_second"`
+	Retransmits   *int    `json:"retransmits"`
+}
+
+// iperf3Sum is a whole-test total for one direction
+type iperf3Sum struct {
+	Seconds       float64 `json:"seconds"`
+	Bytes         int64   `json:"bytes"`
+	BitsPerSecond float64 `json:"bits_per_second"`
+	Retransmits   *int    `json:"retransmits"`
+}
+
+// decodeIperf3Report decodes the JSON report in output, ignoring any text
+// around it
+func decodeIperf3Report(output string) (*iperf3Report, bool) {
+	start := strings.Index(output, "{")
+	end := strings.LastIndex(output, "}")
+	if start == -1 || end < start {
+		return nil, false
+	}
+	
+	var report iperf3Report
+	if err := json.Unmarshal([]byte(output[start:end+1]), &report); err != nil {
+		return nil, false
+	}
+	return &report, true
+}
+
+// intervalRows returns one row per stream per reporting interval, or nil if
+// the report has no intervals. Rows of a TCP sender include retransmits.
+func (report *iperf3Report) intervalRows() []map[string]interface{} {
+	var rows []map[string]interface{}
+	for _, interval := range report.Intervals {
+		for _, stream := range interval.Streams {
+			row := map[string]interface{}{
+				"stream":          stream.Socket,
+				"start":           stream.Start,
+				"end":             stream.End,
+				"bits_per_second": stream.BitsPerSecond,
+			}
+			if stream.Retransmits != nil {
+				row["retransmits"] = *stream.Retransmits
+			}
+			rows = append(rows, row)
 		}
 	}
+	return rows
 }
 
 // parseTextMetrics extracts basic metrics from iperf3 text output
-func (r *Iperf3Runner) parseTextMetrics(result *Result, output string) {
+func (r *Iperf3Runner) parseTextMetrics(metrics map[string]interface{}, output string) {
 	lines := strings.Split(output, "\n")
 	
 	for _, line := range lines {
@@ -260,9 +457,9 @@ func (r *Iperf3Runner) parseTextMetrics(result *Result, output string) {
 			for i, field := range fields {
 				if field == "Mbits/sec" && i > 0 {
 					if bw, err := strconv.ParseFloat(fields[i-1], 64); err == nil {
-						result.Metrics["bandwidth_mbps"] = bw
-						result.Metrics["bandwidth_bps"] = bw * 1e6
-						result.Metrics["bandwidth_gbps"] = bw / 1e3
+						metrics["bandwidth_mbps"] = bw
+						metrics["bandwidth_bps"] = bw * 1e6
+						metrics["bandwidth_gbps"] = bw / 1e3
 					}
 					break
 				}
@@ -272,9 +469,9 @@ func (r *Iperf3Runner) parseTextMetrics(result *Result, output string) {
 			for i, field := range fields {
 				if field == "Gbits/sec" && i > 0 {
 					if bw, err := strconv.ParseFloat(fields[i-1], 64); err == nil {
-						result.Metrics["bandwidth_gbps"] = bw
-						result.Metrics["bandwidth_bps"] = bw * 1e9
-						result.Metrics["bandwidth_mbps"] = bw * 1e3
+						metrics["bandwidth_gbps"] = bw
+						metrics["bandwidth_bps"] = bw * 1e9
+						metrics["bandwidth_mbps"] = bw * 1e3
 					}
 					break
 				}
@@ -289,7 +486,7 @@ func (r *Iperf3Runner) parseTextMetrics(result *Result, output string) {
 				if (field == "Mbits/sec" || field == "Gbits/sec") && i+1 < len(fields) {
 					// Next field after bandwidth unit might be retransmits
 					if retrans, err := strconv.Atoi(fields[i+1]); err == nil && retrans >= 0 {
-						result.Metrics["retransmits"] = retrans
+						metrics["retransmits"] = retrans
 						break
 					}
 				}
@@ -297,45 +494,3 @@ func (r *Iperf3Runner) parseTextMetrics(result *Result, output string) {
 		}
 	}
 }
-
-// extractNumericValue extracts a numeric value associated with a JSON key
-func (r *Iperf3Runner) extractNumericValue(text, key string) float64 {
-	// Simple JSON value extraction - look for "key": value pattern
-	keyPattern := `"` + strings.Trim(key, `"`) + `"`
-	keyIndex := strings.Index(text, keyPattern)
-	if keyIndex == -1 {
-		return -1
-	}
-	
-	// Find the colon after the key
-	colonIndex := strings.Index(text[keyIndex:], ":")
-	if colonIndex == -1 {
-		return -1
-	}
-	
-	// Extract the value part
-	valueStart := keyIndex + colonIndex + 1
-	valueText := strings.TrimSpace(text[valueStart:])
-	
-	// Find the end of the value (comma, closing brace, or newline)
-	var valueEnd int
-	for i, char := range valueText {
-		if char == ',' || char == '}' || char == '\n' || char == '\r' {
-			valueEnd = i
-			break
-		}
-	}
-	
-	if valueEnd == 0 {
-		valueEnd = len(valueText)
-	}
-	
-	valueStr := strings.TrimSpace(valueText[:valueEnd])
-	
-	// Parse the numeric value
-	if value, err := strconv.ParseFloat(valueStr, 64); err == nil {
-		return value
-	}
-	
-	return -1
-}
\ No newline at end of file
@@ -1,6 +1,8 @@
 package runner
 
 import (
+	"bytes"
+	"log"
 	"strings"
 	"testing"
 	"time"
@@ -247,6 +249,17 @@ func TestIperf3Runner_BuildCommand(t *testing.T) {
 			},
 			expected: []string{"-s", "-J", "-4"},
 		},
+		{
+			name: "congestion control",
+			config: Config{
+				Role: "client",
+				Host: "192.168.1.100",
+				Args: map[string]interface{}{
+					"congestion": "bbr",
+				},
+			},
+			expected: []string{"-c 192.168.1.100", "-C bbr"},
+		},
 	}
 
 	for _, tt := range tests {
@@ -291,13 +304,15 @@ func TestIperf3Runner_ParseMetrics_JSON(t *testing.T) {
 				"end": {
 					"sum_sent": {"bits_per_second": 1234567890},
 					"sum_received": {"bits_per_second": 987654321},
-					"streams": 4
+					"streams": [{}, {}, {}, {}]
 				}
 			}`,
 			expectedMetrics: map[string]interface{}{
-				"bandwidth_bps":  1234567890.0,  // First found value is sum_sent
+				"bandwidth_bps":  1234567890.0,  // sum_sent unless the test is reversed
 				"bandwidth_mbps": 1234.56789,
 				"bandwidth_gbps": 1.23456789,
+				"bandwidth_sent_bps":     1234567890.0,
+				"bandwidth_received_bps": 987654321.0,
 				"parallel_streams": 4,
 			},
 		},
@@ -316,6 +331,7 @@ func TestIperf3Runner_ParseMetrics_JSON(t *testing.T) {
 				"bandwidth_bps":  5000000000.0,
 				"bandwidth_mbps": 5000.0,
 				"bandwidth_gbps": 5.0,
+				"bandwidth_sent_bps": 5000000000.0,
 				"retransmits":    42,
 			},
 		},
@@ -571,4 +587,299 @@ func TestIperf3Runner_AutoRegistration(t *testing.T) {
 	if runnerInstance.Name() != "iperf3" {
 		t.Errorf("Expected runner name 'iperf3', got: %s", runnerInstance.Name())
 	}
-}
\ No newline at end of file
+}
+const iperf3MultiStreamOutput = `{
+	"start": {"connected": [{"socket": 5}, {"socket": 7}]},
+	"intervals": [
+		{
+			"streams": [
+				{"socket": 5, "start": 0, "end": 1.000041, "seconds": 1.000041, "bytes": 587202560, "bits_per_second": 4697426869.5},
+				{"socket": 7, "start": 0, "end": 1.000041, "seconds": 1.000041, "bytes": 576716800, "bits_per_second": 4613545030.1}
+			],
+			"sum": {"start": 0, "end": 1.000041, "bits_per_second": 9310971899.6}
+		},
+		{
+			"streams": [
+				{"socket": 5, "start": 1.000041, "end": 2.000038, "seconds": 0.999997, "bytes": 590348288, "bits_per_second": 4722800472.2},
+				{"socket": 7, "start": 1.000041, "end": 2.000038, "seconds": 0.999997, "bytes": 583008256, "bits_per_second": 4664080060.4}
+			],
+			"sum": {"start": 1.000041, "end": 2.000038, "bits_per_second": 9386880532.6}
+		},
+		{
+			"streams": [
+				{"socket": 5, "start": 2.000038, "end": 3.000046, "seconds": 1.000008, "bytes": 589299712, "bits_per_second": 4714359980.6},
+				{"socket": 7, "start": 2.000038, "end": 3.000046, "seconds": 1.000008, "bytes": 581959680, "bits_per_second": 4655640218.5}
+			],
+			"sum": {"start": 2.000038, "end": 3.000046, "bits_per_second": 9370000199.1}
+		}
+	],
+	"end": {
+		"sum_sent": {"bits_per_second": 9355950877.1, "retransmits": 0},
+		"sum_received": {"bits_per_second": 9350000000.0}
+	}
+}`
+
+func TestIperf3Runner_ParseMetrics_Intervals(t *testing.T) {
+	result := &Result{Output: iperf3MultiStreamOutput}
+	if err := NewIperf3Runner("").ParseMetrics(result); err != nil {
+		t.Fatalf("ParseMetrics failed: %v", err)
+	}
+
+	intervals, ok := result.Metrics["intervals"].([]map[string]interface{})
+	if !ok {
+		t.Fatalf("Expected intervals to be []map[string]interface{}, got %T", result.Metrics["intervals"])
+	}
+	if len(intervals) != 6 {
+		t.Fatalf("Expected 3 intervals x 2 streams = 6 rows, got %d", len(intervals))
+	}
+
+	last := intervals[5]
+	if last["stream"] != 7 || last["start"] != 2.000038 || last["end"] != 3.000046 || last["bits_per_second"] != 4655640218.5 {
+		t.Errorf("Unexpected last interval row: %v", last)
+	}
+}
+
+func TestIperf3Runner_ParseMetrics_IntervalRetransmits(t *testing.T) {
+	output := `{
+	"start": {"test_start": {"protocol": "TCP", "num_streams": 1}},
+	"intervals": [
+		{"streams": [{"socket": 5, "start": 0, "end": 1.000052, "bits_per_second": 9413286442.1, "retransmits": 12}]},
+		{"streams": [{"socket": 5, "start": 1.000052, "end": 2.000041, "bits_per_second": 9428514893.8, "retransmits": 0}]},
+		{"streams": [{"socket": 5, "start": 2.000041, "end": 3.000038, "bits_per_second": 8914026475.3, "retransmits": 47}]}
+	],
+	"end": {"sum_sent": {"seconds": 3.000038, "bits_per_second": 9251942603.7, "retransmits": 59}}
+}`
+	result := &Result{Output: output}
+	if err := NewIperf3Runner("").ParseMetrics(result); err != nil {
+		t.Fatalf("ParseMetrics failed: %v", err)
+	}
+
+	intervals, ok := result.Metrics["intervals"].([]map[string]interface{})
+	if !ok || len(intervals) != 3 {
+		t.Fatalf("Expected 3 interval rows, got %v", result.Metrics["intervals"])
+	}
+	want := []struct {
+		start, end, bps float64
+		retransmits     int
+	}{
+		{0, 1.000052, 9413286442.1, 12},
+		{1.000052, 2.000041, 9428514893.8, 0},
+		{2.000041, 3.000038, 8914026475.3, 47},
+	}
+	for i, w := range want {
+		row := intervals[i]
+		if row["start"] != w.start || row["end"] != w.end || row["bits_per_second"] != w.bps || row["retransmits"] != w.retransmits {
+			t.Errorf("interval %d = %v, want %+v", i, row, w)
+		}
+	}
+
+	// The summary metrics are still reported alongside the intervals
+	if result.Metrics["retransmits"] != 59 || result.Metrics["bandwidth_bps"] != 9251942603.7 {
+		t.Errorf("Unexpected summary metrics: %v", result.Metrics)
+	}
+
+	// Receiver-side rows carry no retransmits
+	result = &Result{Output: iperf3MultiStreamOutput}
+	if err := NewIperf3Runner("").ParseMetrics(result); err != nil {
+		t.Fatalf("ParseMetrics failed: %v", err)
+	}
+	if _, exists := result.Metrics["intervals"].([]map[string]interface{})[0]["retransmits"]; exists {
+		t.Error("Expected no retransmits in rows without them")
+	}
+}
+
+func TestIperf3Runner_ParseMetrics_TextHasNoIntervals(t *testing.T) {
+	output := `[  5]   0.00-10.00  sec  1.09 GBytes   934 Mbits/sec   15             sender
+[  5]   0.00-10.00  sec  1.09 GBytes   933 Mbits/sec                  receiver`
+	result := &Result{Output: output}
+	if err := NewIperf3Runner("").ParseMetrics(result); err != nil {
+		t.Fatalf("ParseMetrics failed: %v", err)
+	}
+	if _, ok := result.Metrics["bandwidth_mbps"]; !ok {
+		t.Errorf("Expected the text fallback to report bandwidth, got %v", result.Metrics)
+	}
+	if _, ok := result.Metrics["intervals"]; ok {
+		t.Errorf("Expected no intervals from text output, got %v", result.Metrics["intervals"])
+	}
+}
+
+func TestIperf3Runner_ParseMetrics_NoIntervals(t *testing.T) {
+	result := &Result{Output: `{"start": {}, "intervals": [], "end": {"sum_sent": {"bits_per_second": 100}}}`}
+	if err := NewIperf3Runner("").ParseMetrics(result); err != nil {
+		t.Fatalf("ParseMetrics failed: %v", err)
+	}
+	if _, ok := result.Metrics["intervals"]; ok {
+		t.Errorf("Expected no intervals metric, got %v", result.Metrics["intervals"])
+	}
+}
+
+func TestIperf3Runner_ParseMetrics_JSONWinsOverText(t *testing.T) {
+	var logs bytes.Buffer
+	SetDebugLogger(log.New(&logs, "", 0))
+	defer SetDebugLogger(nil)
+
+	// Text summary lines captured alongside the JSON report
+	output := `[  5]   0.00-10.00  sec  1.09 GBytes   934 Mbits/sec   15             sender
+{
+	"start": {},
+	"end": {"sum_sent": {"bits_per_second": 5000000000, "retransmits": 42}}
+}`
+	result := &Result{Output: output}
+	if err := NewIperf3Runner("").ParseMetrics(result); err != nil {
+		t.Fatalf("ParseMetrics failed: %v", err)
+	}
+
+	if result.Metrics["bandwidth_mbps"] != 5000.0 || result.Metrics["bandwidth_gbps"] != 5.0 {
+		t.Errorf("Expected JSON bandwidth to win, got %v Mbps / %v Gbps", result.Metrics["bandwidth_mbps"], result.Metrics["bandwidth_gbps"])
+	}
+	if result.Metrics["retransmits"] != 42 {
+		t.Errorf("Expected JSON retransmits 42 to win, got %v", result.Metrics["retransmits"])
+	}
+	if !strings.Contains(logs.String(), "metric bandwidth_mbps: keeping 5000, ignoring 934 from iperf3 text output") {
+		t.Errorf("Expected a debug log for the conflicting bandwidth, got %q", logs.String())
+	}
+}
+
+func TestIperf3Runner_ParseMetrics_TextFillsJSONGaps(t *testing.T) {
+	output := `{"start": {}, "end": {"sum_sent": {"bits_per_second": 5000000000}}}
+[  5]   0.00-10.00  sec  5.82 GBytes   5.00 Gbits/sec   15             sender`
+	result := &Result{Output: output}
+	if err := NewIperf3Runner("").ParseMetrics(result); err != nil {
+		t.Fatalf("ParseMetrics failed: %v", err)
+	}
+
+	if result.Metrics["bandwidth_bps"] != 5000000000.0 {
+		t.Errorf("Expected JSON bandwidth, got %v", result.Metrics["bandwidth_bps"])
+	}
+	if result.Metrics["retransmits"] != 15 {
+		t.Errorf("Expected text retransmits to fill the gap, got %v", result.Metrics["retransmits"])
+	}
+}
+
+// iperf3FullReport is complete `iperf3 -c ... -P 2 -t 3 -J` output
+const iperf3FullReport = `{
+	"start": {
+		"connected": [
+			{"socket": 5, "local_host": "10.0.0.1", "local_port": 43210, "remote_host": "10.0.0.2", "remote_port": 5201},
+			{"socket": 7, "local_host": "10.0.0.1", "local_port": 43212, "remote_host": "10.0.0.2", "remote_port": 5201}
+		],
+		"version": "iperf 3.9",
+		"system_info": "Linux client1 5.15.0-91-generic #101-Ubuntu SMP x86_64",
+		"timestamp": {"time": "Tue, 02 Jan 2024 10:00:00 GMT", "timesecs": 1704189600},
+		"connecting_to": {"host": "10.0.0.2", "port": 5201},
+		"cookie": "wq2lhbuj3hsdqqbb7nuxevmcydiynqsh3k6n",
+		"tcp_mss_default": 8948,
+		"sock_bufsize": 0,
+		"sndbuf_actual": 16384,
+		"rcvbuf_actual": 131072,
+		"test_start": {"protocol": "TCP", "num_streams": 2, "blksize": 131072, "omit": 0, "duration": 3, "bytes": 0, "blocks": 0, "reverse": 0, "tos": 0}
+	},
+	"intervals": [
+		{
+			"streams": [
+				{"socket": 5, "start": 0, "end": 1.000041, "seconds": 1.000041, "bytes": 587202560, "bits_per_second": 4697426869.5, "retransmits": 0, "snd_cwnd": 3148800, "rtt": 215, "rttvar": 31, "pmtu": 9000, "omitted": false, "sender": true},
+				{"socket": 7, "start": 0, "end": 1.000041, "seconds": 1.000041, "bytes": 576716800, "bits_per_second": 4613545030.1, "retransmits": 2, "snd_cwnd": 2953216, "rtt": 230, "rttvar": 40, "pmtu": 9000, "omitted": false, "sender": true}
+			],
+			"sum": {"start": 0, "end": 1.000041, "seconds": 1.000041, "bytes": 1163919360, "bits_per_second": 9310971899.6, "retransmits": 2, "omitted": false, "sender": true}
+		},
+		{
+			"streams": [
+				{"socket": 5, "start": 1.000041, "end": 2.000038, "seconds": 0.999997, "bytes": 590348288, "bits_per_second": 4722800472.2, "retransmits": 0, "snd_cwnd": 3148800, "rtt": 210, "rttvar": 28, "pmtu": 9000, "omitted": false, "sender": true},
+				{"socket": 7, "start": 1.000041, "end": 2.000038, "seconds": 0.999997, "bytes": 583008256, "bits_per_second": 4664080060.4, "retransmits": 0, "snd_cwnd": 2953216, "rtt": 228, "rttvar": 35, "pmtu": 9000, "omitted": false, "sender": true}
+			],
+			"sum": {"start": 1.000041, "end": 2.000038, "seconds": 0.999997, "bytes": 1173356544, "bits_per_second": 9386880532.6, "retransmits": 0, "omitted": false, "sender": true}
+		},
+		{
+			"streams": [
+				{"socket": 5, "start": 2.000038, "end": 3.000046, "seconds": 1.000008, "bytes": 589299712, "bits_per_second": 4714359980.6, "retransmits": 1, "snd_cwnd": 3148800, "rtt": 212, "rttvar": 30, "pmtu": 9000, "omitted": false, "sender": true},
+				{"socket": 7, "start": 2.000038, "end": 3.000046, "seconds": 1.000008, "bytes": 581959680, "bits_per_second": 4655640218.5, "retransmits": 0, "snd_cwnd": 2953216, "rtt": 226, "rttvar": 33, "pmtu": 9000, "omitted": false, "sender": true}
+			],
+			"sum": {"start": 2.000038, "end": 3.000046, "seconds": 1.000008, "bytes": 1171259392, "bits_per_second": 9370000199.1, "retransmits": 1, "omitted": false, "sender": true}
+		}
+	],
+	"end": {
+		"streams": [
+			{
+				"sender": {"socket": 5, "start": 0, "end": 3.000046, "seconds": 3.000046, "bytes": 1766850560, "bits_per_second": 4711511200.3, "retransmits": 1, "max_snd_cwnd": 3148800, "max_rtt": 215, "min_rtt": 210, "mean_rtt": 212, "sender": true},
+				"receiver": {"socket": 5, "start": 0, "end": 3.000312, "seconds": 3.000046, "bytes": 1765801984, "bits_per_second": 4708301511.0, "sender": true}
+			},
+			{
+				"sender": {"socket": 7, "start": 0, "end": 3.000046, "seconds": 3.000046, "bytes": 1741684736, "bits_per_second": 4644439676.8, "retransmits": 2, "max_snd_cwnd": 2953216, "max_rtt": 230, "min_rtt": 226, "mean_rtt": 228, "sender": true},
+				"receiver": {"socket": 7, "start": 0, "end": 3.000312, "seconds": 3.000046, "bytes": 1740636160, "bits_per_second": 4641698489.0, "sender": true}
+			}
+		],
+		"sum_sent": {"start": 0, "end": 3.000046, "seconds": 3.000046, "bytes": 3508535296, "bits_per_second": 9355950877.1, "retransmits": 3, "sender": true},
+		"sum_received": {"start": 0, "end": 3.000312, "seconds": 3.000312, "bytes": 3506438144, "bits_per_second": 9350000000.0, "sender": true},
+		"cpu_utilization_percent": {"host_total": 38.6, "host_user": 0.9, "host_system": 37.7, "remote_total": 45.1, "remote_user": 1.4, "remote_system": 43.7},
+		"sender_tcp_congestion": "cubic",
+		"receiver_tcp_congestion": "cubic"
+	}
+}`
+
+func TestIperf3Runner_ParseMetrics_FullReport(t *testing.T) {
+	tests := []struct {
+		name         string
+		output       string
+		bandwidthBps float64
+		duration     float64
+	}{
+		{"forward reports sum_sent", iperf3FullReport, 9355950877.1, 3.000046},
+		{"reverse reports sum_received", strings.Replace(iperf3FullReport, `"reverse": 0`, `"reverse": 1`, 1), 9350000000.0, 3.000312},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := &Result{Output: tt.output}
+			if err := NewIperf3Runner("").ParseMetrics(result); err != nil {
+				t.Fatalf("ParseMetrics failed: %v", err)
+			}
+
+			expected := map[string]interface{}{
+				"bandwidth_bps":          tt.bandwidthBps,
+				"bandwidth_sent_bps":     9355950877.1,
+				"bandwidth_received_bps": 9350000000.0,
+				"retransmits":            3,
+				"parallel_streams":       2,
+				"streams_requested":      2,
+				"streams_actual":         2,
+				"actual_duration":        tt.duration,
+			}
+			for key, want := range expected {
+				if got := result.Metrics[key]; got != want {
+					t.Errorf("Metric %s: expected %v, got %v", key, want, got)
+				}
+			}
+			if got := result.Metrics["bandwidth_gbps"]; got != tt.bandwidthBps/1e9 {
+				t.Errorf("Expected bandwidth_gbps %v, got %v", tt.bandwidthBps/1e9, got)
+			}
+			if intervals, ok := result.Metrics["intervals"].([]map[string]interface{}); !ok || len(intervals) != 6 {
+				t.Errorf("Expected 3 intervals x 2 streams = 6 rows, got %v", result.Metrics["intervals"])
+			}
+		})
+	}
+}
+
+func TestIperf3Runner_ParseMetrics_FewerStreamsThanRequested(t *testing.T) {
+	output := strings.Replace(iperf3FullReport, `"num_streams": 2`, `"num_streams": 4`, 1)
+	result := &Result{Output: output}
+	if err := NewIperf3Runner("").ParseMetrics(result); err != nil {
+		t.Fatalf("ParseMetrics failed: %v", err)
+	}
+
+	if result.Metrics["streams_requested"] != 4 {
+		t.Errorf("Expected streams_requested 4, got %v", result.Metrics["streams_requested"])
+	}
+	if result.Metrics["streams_actual"] != 2 {
+		t.Errorf("Expected streams_actual 2, got %v", result.Metrics["streams_actual"])
+	}
+}
+
+func TestIperf3Runner_ReadinessProbe(t *testing.T) {
+	r := NewIperf3Runner("")
+	if probe := r.ReadinessProbe(Config{Role: "server"}); probe.Command != "ss -ltn | grep -q ':5201[[:space:]]'" {
+		t.Errorf("Expected the default port to be probed, got %+v", probe)
+	}
+	if probe := r.ReadinessProbe(Config{Role: "server", Port: 6000}); !strings.Contains(probe.Command, ":6000[") {
+		t.Errorf("Expected the configured port to be probed, got %+v", probe)
+	}
+}
@@ -0,0 +1,65 @@
+package runner
+
+import (
+	"log"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Metrics for which a smaller value is better: latencies, round-trip and
+// response times, and counts of lost, dropped, retransmitted, or failed
+// packets and requests. All other metrics are better when larger.
+var (
+	lowerIsBetterPrefixes = []string{"latency_", "rtt_", "ttfb_", "jitter_"}
+	lowerIsBetterMarkers  = []string{"_lat_", "loss", "lost", "drop", "retrans", "error", "non_2xx"}
+)
+
+// LowerIsBetter reports whether smaller values of metric are better, as for
+// latencies (latency_avg_usec, tcp_lat_usec), packet loss (packet_loss_pct,
+// lost_packets), retransmits, and dropped messages
+func LowerIsBetter(metric string) bool {
+	for _, prefix := range lowerIsBetterPrefixes {
+		if strings.HasPrefix(metric, prefix) {
+			return true
+		}
+	}
+	for _, marker := range lowerIsBetterMarkers {
+		if strings.Contains(metric, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// debugLogger receives parser diagnostics; nil discards them
+var debugLogger *log.Logger
+
+// SetDebugLogger sets the logger for parser diagnostics such as metrics
+// found by more than one parse pass; nil disables them
+func SetDebugLogger(logger *log.Logger) {
+	debugLogger = logger
+}
+
+// mergeMetrics adds the metrics of a lower-precedence parse pass (e.g. a
+// text fallback) to metrics. Keys already present keep their value, so the
+// result does not depend on which pass happens to run last; a conflicting
+// value is reported to the debug logger.
+func mergeMetrics(metrics, fallback map[string]interface{}, source string) {
+	keys := make([]string, 0, len(fallback))
+	for key := range fallback {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	
+	for _, key := range keys {
+		existing, exists := metrics[key]
+		if !exists {
+			metrics[key] = fallback[key]
+			continue
+		}
+		if debugLogger != nil && !reflect.DeepEqual(existing, fallback[key]) {
+			debugLogger.Printf("metric %s: keeping %v, ignoring %v from %s", key, existing, fallback[key], source)
+		}
+	}
+}
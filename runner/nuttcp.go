@@ -0,0 +1,198 @@
+package runner
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Auto-register the nuttcp runner
+func init() {
+	Register("nuttcp", func() Runner {
+		return NewNuttcpRunner("")
+	})
+}
+
+// nuttcpMetrics maps the fields of nuttcp's -fparse output to metric keys
+var nuttcpMetrics = map[string]string{
+	"rate_Mbps":    "bandwidth_mbps",
+	"megabytes":    "transferred_mb",
+	"real_seconds": "duration_sec",
+	"retrans":      "retransmits",
+	"rtt":          "rtt_ms",
+	"drop":         "lost_packets",
+	"pkt":          "packets",
+	"data_loss":    "loss_percent",
+}
+
+// NuttcpRunner implements the Runner interface for nuttcp. The server runs
+// as a one-shot receiver (nuttcp -1) that exits after the client's test.
+type NuttcpRunner struct {
+	executablePath string
+}
+
+// NewNuttcpRunner creates a new nuttcp runner
+func NewNuttcpRunner(executablePath string) *NuttcpRunner {
+	if executablePath == "" {
+		executablePath = "nuttcp"
+	}
+	return &NuttcpRunner{
+		executablePath: executablePath,
+	}
+}
+
+// Name returns the name of the runner
+func (r *NuttcpRunner) Name() string {
+	return "nuttcp"
+}
+
+// Description summarizes the runner for listings
+func (r *NuttcpRunner) Description() string {
+	return "Measures TCP/UDP throughput, retransmits, and loss with nuttcp"
+}
+
+// SetExecutablePath sets the custom executable path for this runner
+func (r *NuttcpRunner) SetExecutablePath(path string) {
+	r.executablePath = path
+}
+
+// ExecutablePath returns the executable invoked on remote hosts
+func (r *NuttcpRunner) ExecutablePath() string {
+	return r.executablePath
+}
+
+// VersionCommand prints nuttcp's version, e.g. "nuttcp-8.2.2"
+func (r *NuttcpRunner) VersionCommand() string {
+	return r.executablePath + " -V"
+}
+
+// ServerMode reports that the one-shot server exits after one test
+func (r *NuttcpRunner) ServerMode() ServerMode {
+	return ServerOneShot
+}
+
+// PrimaryMetric reports throughput in Mbps
+func (r *NuttcpRunner) PrimaryMetric() string {
+	return "bandwidth_mbps"
+}
+
+// OutputStream parses stdout, where -fparse results are printed
+func (r *NuttcpRunner) OutputStream() Stream {
+	return StreamStdout
+}
+
+// ProtocolRoles reports that both ends must agree on UDP
+func (r *NuttcpRunner) ProtocolRoles() []string {
+	return []string{"client", "server"}
+}
+
+// SupportsRole returns true if the runner supports the given role
+func (r *NuttcpRunner) SupportsRole(role string) bool {
+	return role == "client" || role == "server"
+}
+
+// Validate checks if the configuration is valid for nuttcp
+func (r *NuttcpRunner) Validate(config Config) error {
+	if !r.SupportsRole(config.Role) {
+		return fmt.Errorf("unsupported role: %s", config.Role)
+	}
+
+	if config.Role == "client" && config.TargetHost == "" && config.Host == "" {
+		return fmt.Errorf("target_host or host is required for client role")
+	}
+
+	if err := validateProtocol(config); err != nil {
+		return err
+	}
+
+	if streams, ok := config.GetEffectiveArgs()["parallel_streams"].(int); ok && streams <= 0 {
+		return fmt.Errorf("parallel_streams must be greater than 0")
+	}
+
+	if config.Port < 0 || config.Port > 65535 {
+		return fmt.Errorf("port must be between 0 and 65535")
+	}
+
+	return nil
+}
+
+// BuildCommand constructs the full command line for remote execution.
+// nuttcp takes option values attached to the flag, e.g. -T10.
+func (r *NuttcpRunner) BuildCommand(config Config) string {
+	envPrefix := buildEnvPrefix(config)
+	effectiveArgs := config.GetEffectiveArgs()
+
+	cmd := r.executablePath
+	if config.Role == "server" {
+		cmd += " -1"
+	} else {
+		cmd += " -fparse"
+		if config.Duration > 0 {
+			cmd += fmt.Sprintf(" -T%d", int(config.Duration.Seconds()))
+		}
+	}
+
+	if UsesProtocolFlag(r, config.Role) && config.Protocol() == ProtocolUDP {
+		cmd += " -u"
+	}
+
+	// Port is nuttcp's control port; data flows on the next one
+	if config.Port > 0 {
+		cmd += fmt.Sprintf(" -P%d", config.Port)
+	}
+
+	if config.Role == "client" {
+		if streams, ok := effectiveArgs["parallel_streams"].(int); ok && streams > 0 {
+			cmd += fmt.Sprintf(" -N%d", streams)
+		}
+		if bitrate, ok := effectiveArgs["bitrate"].(string); ok && bitrate != "" {
+			cmd += fmt.Sprintf(" -R%s", bitrate)
+		}
+		if window, ok := effectiveArgs["window_size"].(string); ok && window != "" {
+			cmd += fmt.Sprintf(" -w%s", window)
+		}
+		if buffer, ok := effectiveArgs["buffer_length"].(string); ok && buffer != "" {
+			cmd += fmt.Sprintf(" -l%s", buffer)
+		}
+		if reverse, ok := effectiveArgs["reverse"].(bool); ok && reverse {
+			cmd += " -r"
+		}
+
+		targetHost := config.TargetHost
+		if targetHost == "" {
+			targetHost = config.Host
+		}
+		cmd += " " + targetHost
+	}
+
+	return envPrefix + cmd
+}
+
+// ParseMetrics extracts the key=value fields of nuttcp's -fparse summary
+func (r *NuttcpRunner) ParseMetrics(result *Result) error {
+	if result == nil {
+		return fmt.Errorf("result cannot be nil")
+	}
+
+	if result.Metrics == nil {
+		result.Metrics = make(map[string]interface{})
+	}
+
+	for _, line := range strings.Split(result.Output, "\n") {
+		if !strings.Contains(line, "rate_Mbps=") {
+			continue
+		}
+		for _, field := range strings.Fields(line) {
+			key, value, found := strings.Cut(field, "=")
+			metric, known := nuttcpMetrics[key]
+			if !found || !known {
+				continue
+			}
+			if number, err := strconv.ParseFloat(value, 64); err == nil {
+				result.Metrics[metric] = number
+			}
+		}
+	}
+
+	return nil
+}
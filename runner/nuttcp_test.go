@@ -0,0 +1,47 @@
+package runner
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNuttcpRunner_BuildCommand(t *testing.T) {
+	r := NewNuttcpRunner("")
+	config := Config{
+		Duration:   10 * time.Second,
+		Port:       5000,
+		TargetHost: "10.0.0.2",
+		Args:       map[string]interface{}{"parallel_streams": 4, "bitrate": "1g"},
+	}
+
+	config.Role = "client"
+	if got, want := r.BuildCommand(config), "nuttcp -fparse -T10 -P5000 -N4 -R1g 10.0.0.2"; got != want {
+		t.Errorf("client command = %q, want %q", got, want)
+	}
+	config.Role = "server"
+	if got, want := r.BuildCommand(config), "nuttcp -1 -P5000"; got != want {
+		t.Errorf("server command = %q, want %q", got, want)
+	}
+}
+
+func TestNuttcpRunner_ParseMetrics(t *testing.T) {
+	result := &Result{Output: "megabytes=1192.8281 real_seconds=10.00 rate_Mbps=1000.5418 tx_cpu=3 rx_cpu=24 drop=12 pkt=89000 data_loss=0.01348\n"}
+	if err := NewNuttcpRunner("").ParseMetrics(result); err != nil {
+		t.Fatalf("ParseMetrics returned error: %v", err)
+	}
+
+	want := map[string]float64{
+		"bandwidth_mbps": 1000.5418,
+		"transferred_mb": 1192.8281,
+		"lost_packets":   12,
+		"loss_percent":   0.01348,
+	}
+	for key, value := range want {
+		if result.Metrics[key] != value {
+			t.Errorf("%s = %v, want %v", key, result.Metrics[key], value)
+		}
+	}
+	if _, ok := result.Metrics["tx_cpu"]; ok {
+		t.Error("Expected unmapped fields to be skipped")
+	}
+}
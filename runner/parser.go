@@ -0,0 +1,84 @@
+package runner
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Parser extracts performance metrics from a command's output into
+// result.Metrics, like Runner.ParseMetrics. Registered parsers let a site
+// parse a tool's output its own way (custom patches, locale) without
+// changing the runner.
+type Parser func(result *Result) error
+
+// ParserRegistry holds named metric parsers
+type ParserRegistry struct {
+	parsers map[string]Parser
+	mu      sync.RWMutex
+}
+
+// NewParserRegistry creates an empty parser registry
+func NewParserRegistry() *ParserRegistry {
+	return &ParserRegistry{parsers: make(map[string]Parser)}
+}
+
+// Register adds a parser under name, replacing any parser already registered
+// under it
+func (p *ParserRegistry) Register(name string, parser Parser) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.parsers[name] = parser
+}
+
+// Get returns the parser registered under name
+func (p *ParserRegistry) Get(name string) (Parser, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	parser, exists := p.parsers[name]
+	if !exists {
+		return nil, fmt.Errorf("parser %s not found", name)
+	}
+	return parser, nil
+}
+
+// Names returns the names of all registered parsers, sorted
+func (p *ParserRegistry) Names() []string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	names := make([]string, 0, len(p.parsers))
+	for name := range p.parsers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+var globalParsers = NewParserRegistry()
+
+// RegisterParser adds a parser to the global parser registry. Every runner's
+// built-in ParseMetrics is registered under the runner's name, so a custom
+// parser can start from the default one.
+func RegisterParser(name string, parser Parser) {
+	globalParsers.Register(name, parser)
+}
+
+// GetParser returns a parser from the global parser registry
+func GetParser(name string) (Parser, error) {
+	return globalParsers.Get(name)
+}
+
+// GetRegisteredParsers returns the names of all globally registered parsers
+func GetRegisteredParsers() []string {
+	return globalParsers.Names()
+}
+
+// defaultParser returns a parser running the built-in ParseMetrics of the
+// runner factory creates
+func defaultParser(factory func() Runner) Parser {
+	return func(result *Result) error {
+		return factory().ParseMetrics(result)
+	}
+}
@@ -0,0 +1,50 @@
+package runner
+
+import (
+	"testing"
+)
+
+func TestParserRegistry_DefaultParsers(t *testing.T) {
+	for _, name := range GetRegistered() {
+		if _, err := GetParser(name); err != nil {
+			t.Errorf("Expected runner %s to register its built-in parser: %v", name, err)
+		}
+	}
+
+	parser, err := GetParser("ib_send_bw")
+	if err != nil {
+		t.Fatalf("GetParser returned error: %v", err)
+	}
+	output := "#bytes     #iterations    BW peak[MB/sec]    BW average[MB/sec]   MsgRate[Mpps]\n" +
+		" 65536      1000           12345.67           12000.50             0.18"
+	result := &Result{Output: output}
+	if err := parser(result); err != nil {
+		t.Fatalf("Default parser returned error: %v", err)
+	}
+	if result.Metrics["bandwidth_average_mbps"] != 12000.50 {
+		t.Errorf("Expected the default parser to behave like ParseMetrics, got %v", result.Metrics)
+	}
+}
+
+func TestParserRegistry_Register(t *testing.T) {
+	registry := NewParserRegistry()
+	registry.Register("site", func(result *Result) error {
+		result.Metrics["custom"] = true
+		return nil
+	})
+
+	if _, err := registry.Get("other"); err == nil {
+		t.Error("Expected an error for an unregistered parser")
+	}
+	parser, err := registry.Get("site")
+	if err != nil {
+		t.Fatalf("Get returned error: %v", err)
+	}
+	result := &Result{Metrics: make(map[string]interface{})}
+	if err := parser(result); err != nil || result.Metrics["custom"] != true {
+		t.Errorf("Expected the registered parser to run, got %v (err %v)", result.Metrics, err)
+	}
+	if names := registry.Names(); len(names) != 1 || names[0] != "site" {
+		t.Errorf("Expected [site], got %v", names)
+	}
+}
@@ -0,0 +1,173 @@
+package runner
+
+import (
+	"fmt"
+	"strings"
+)
+
+// perftestDefaultPort is the port perftest servers listen on without -p
+const perftestDefaultPort = 18515
+
+// perftestPort returns the port the perftest server listens on, or the
+// first port of a size sweep
+func perftestPort(config Config) int {
+	if config.Port > 0 {
+		return config.Port
+	}
+	return perftestDefaultPort
+}
+
+// perftestReadinessProbe waits for the server's port to be listening, or
+// for every port of a size_range sweep
+func perftestReadinessProbe(config Config) ReadinessProbe {
+	port := perftestPort(config)
+	sizes, ok := sweepSizes(config)
+	if !ok {
+		return ListeningProbe(port)
+	}
+	probes := make([]string, len(sizes))
+	for i := range sizes {
+		probes[i] = ListeningProbe(port + i).Command
+	}
+	return ReadinessProbe{Command: strings.Join(probes, " && ")}
+}
+
+// appendPerftestFlags appends the flags shared by the perftest tools
+// (ib_send_bw, ib_send_lat, ...) to cmd: port, duration, and the perftest
+// args of the config's role
+func appendPerftestFlags(cmd string, config Config) string {
+	// Port (if specified)
+	if config.Port > 0 {
+		cmd += fmt.Sprintf(" -p %d", config.Port)
+	}
+
+	// Duration (if specified) - perftest uses -D flag
+	if config.Duration > 0 {
+		cmd += fmt.Sprintf(" -D %d", int(config.Duration.Seconds()))
+	}
+
+	// Additional arguments from config (use effective args based on role)
+	effectiveArgs := config.GetEffectiveArgs()
+	for _, key := range sortedArgKeys(effectiveArgs) {
+		value := effectiveArgs[key]
+		switch key {
+		case "size":
+			// Message size in bytes
+			if size, ok := value.(int); ok {
+				cmd += fmt.Sprintf(" -s %d", size)
+			} else if sizeStr, ok := value.(string); ok {
+				cmd += fmt.Sprintf(" -s %s", sizeStr)
+			}
+		case "size_all":
+			// Sweep all message sizes from 2 to 2^23 bytes
+			if sizeAll, ok := value.(bool); ok && sizeAll {
+				cmd += " -a"
+			}
+		case "iterations":
+			// Number of iterations
+			if iter, ok := value.(int); ok {
+				cmd += fmt.Sprintf(" -n %d", iter)
+			}
+		case "tx_depth":
+			// Send queue depth
+			if depth, ok := value.(int); ok {
+				cmd += fmt.Sprintf(" -t %d", depth)
+			}
+		case "rx_depth":
+			// Receive queue depth
+			if depth, ok := value.(int); ok {
+				cmd += fmt.Sprintf(" -r %d", depth)
+			}
+		case "mtu":
+			// MTU size
+			if mtu, ok := value.(int); ok {
+				cmd += fmt.Sprintf(" -m %d", mtu)
+			}
+		case "qp":
+			// Number of QPs
+			if qp, ok := value.(int); ok {
+				cmd += fmt.Sprintf(" -q %d", qp)
+			}
+		case "connection":
+			// Connection type (RC/UC/UD)
+			if conn, ok := value.(string); ok {
+				cmd += fmt.Sprintf(" -c %s", conn)
+			}
+		case "inline":
+			// Inline size
+			if inline, ok := value.(int); ok {
+				cmd += fmt.Sprintf(" -I %d", inline)
+			}
+		case "ib_dev":
+			// InfiniBand device
+			if dev, ok := value.(string); ok {
+				cmd += fmt.Sprintf(" -d %s", dev)
+			}
+		case "gid_index":
+			// GID index
+			if gid, ok := value.(int); ok {
+				cmd += fmt.Sprintf(" -x %d", gid)
+			}
+		case "sl":
+			// Service level
+			if sl, ok := value.(int); ok {
+				cmd += fmt.Sprintf(" -S %d", sl)
+			}
+		case "cpu_freq":
+			// CPU frequency for cycles calculation
+			if freq, ok := value.(float64); ok {
+				cmd += fmt.Sprintf(" -F %.2f", freq)
+			}
+		case "use_event":
+			// Use event completion
+			if useEvent, ok := value.(bool); ok && useEvent {
+				cmd += " -e"
+			}
+		case "bidirectional":
+			// Bidirectional test
+			if bidir, ok := value.(bool); ok && bidir {
+				cmd += " -b"
+			}
+		case "report_cycles":
+			// Report CPU cycles
+			if cycles, ok := value.(bool); ok && cycles {
+				cmd += " -C"
+			}
+		case "report_histogram":
+			// Report latency histogram
+			if hist, ok := value.(bool); ok && hist {
+				cmd += " -H"
+			}
+		case "odp":
+			// Use On Demand Paging
+			if odp, ok := value.(bool); ok && odp {
+				cmd += " -o"
+			}
+		case "report_gbits":
+			// Report in Gb/sec instead of MB/sec
+			if gbits, ok := value.(bool); ok && gbits {
+				cmd += " -R"
+			}
+		}
+	}
+
+	return cmd
+}
+
+// validatePerftestArgs checks the message size sweep settings shared by the
+// perftest tools
+func validatePerftestArgs(config Config) error {
+	effectiveArgs := config.GetEffectiveArgs()
+	if sizeRange, exists := effectiveArgs["size_range"]; exists {
+		if _, err := expandSizeRange(sizeRange); err != nil {
+			return err
+		}
+		if _, hasSize := effectiveArgs["size"]; hasSize {
+			return fmt.Errorf("size_range cannot be combined with size")
+		}
+		if sizeAll, ok := effectiveArgs["size_all"].(bool); ok && sizeAll {
+			return fmt.Errorf("size_range cannot be combined with size_all")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,223 @@
+package runner
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+// Auto-register the ping runner
+func init() {
+	Register("ping", func() Runner {
+		return NewPingRunner("")
+	})
+}
+
+const (
+	defaultPingCount    = 10
+	defaultPingInterval = 1.0
+)
+
+// Summary lines of iputils ping; busybox prints "round-trip" without mdev
+var (
+	pingRTTRegex  = regexp.MustCompile(`(?:rtt|round-trip) min/avg/max(/mdev)? = (\d+\.?\d*)/(\d+\.?\d*)/(\d+\.?\d*)(?:/(\d+\.?\d*))? ms`)
+	pingLossRegex = regexp.MustCompile(`(\d+) packets transmitted, (\d+) (?:packets )?received,.*?(\d+\.?\d*)% packet loss`)
+)
+
+// PingRunner implements the Runner interface for ping, a quick RTT sanity
+// check before heavier benchmarks. The client pings the server host; the
+// server role needs no program and exits immediately.
+type PingRunner struct {
+	executablePath string
+}
+
+// NewPingRunner creates a new ping runner
+func NewPingRunner(executablePath string) *PingRunner {
+	if executablePath == "" {
+		executablePath = "ping"
+	}
+	return &PingRunner{
+		executablePath: executablePath,
+	}
+}
+
+// Name returns the name of the runner
+func (r *PingRunner) Name() string {
+	return "ping"
+}
+
+// Description summarizes the runner for listings
+func (r *PingRunner) Description() string {
+	return "Measures ICMP round-trip time and packet loss with ping"
+}
+
+// SetExecutablePath sets the custom executable path for this runner
+func (r *PingRunner) SetExecutablePath(path string) {
+	r.executablePath = path
+}
+
+// ExecutablePath returns the executable invoked on remote hosts
+func (r *PingRunner) ExecutablePath() string {
+	return r.executablePath
+}
+
+// ExecutablesFor returns the shell's true for the server, which only
+// needs to answer ICMP echo requests, and ping plus awk, which judges the
+// packet loss, for the client
+func (r *PingRunner) ExecutablesFor(config Config) []string {
+	if config.Role == "server" {
+		return []string{"true"}
+	}
+	return []string{r.executablePath, "awk"}
+}
+
+// VersionCommand prints the ping version banner
+func (r *PingRunner) VersionCommand() string {
+	return fmt.Sprintf("%s -V 2>&1 | head -1", r.executablePath)
+}
+
+// ServerMode reports that the no-op server exits on its own
+func (r *PingRunner) ServerMode() ServerMode {
+	return ServerOneShot
+}
+
+// ServesMultipleClients reports that any number of clients may ping the
+// server, which runs nothing
+func (r *PingRunner) ServesMultipleClients() bool {
+	return true
+}
+
+// PrimaryMetric reports the average round-trip time
+func (r *PingRunner) PrimaryMetric() string {
+	return "rtt_avg_ms"
+}
+
+// OutputStream parses stdout, where ping prints its summary
+func (r *PingRunner) OutputStream() Stream {
+	return StreamStdout
+}
+
+// SupportsRole returns true if the runner supports the given role
+func (r *PingRunner) SupportsRole(role string) bool {
+	return role == "client" || role == "server"
+}
+
+// Validate checks if the configuration is valid for ping
+func (r *PingRunner) Validate(config Config) error {
+	if !r.SupportsRole(config.Role) {
+		return fmt.Errorf("unsupported role: %s", config.Role)
+	}
+
+	if config.Role == "client" && config.TargetHost == "" && config.Host == "" {
+		return fmt.Errorf("target_host or host is required for client role")
+	}
+
+	effectiveArgs := config.GetEffectiveArgs()
+	if count, ok := effectiveArgs["count"].(int); ok && count <= 0 {
+		return fmt.Errorf("count must be greater than 0")
+	}
+	if value, exists := effectiveArgs["interval"]; exists {
+		if interval, ok := pingNumber(value); !ok || interval <= 0 {
+			return fmt.Errorf("interval must be a number of seconds greater than 0, got %v", value)
+		}
+	}
+	if value, exists := effectiveArgs["max_loss_pct"]; exists {
+		if maxLoss, ok := pingNumber(value); !ok || maxLoss < 0 || maxLoss > 100 {
+			return fmt.Errorf("max_loss_pct must be a percentage between 0 and 100, got %v", value)
+		}
+	}
+
+	return nil
+}
+
+// BuildCommand constructs the full command line for remote execution. The
+// run lasts count × interval seconds; duration and port are not used.
+// Packet loss is a measurement, not a failure: the run fails only when no
+// reply arrives or the loss exceeds max_loss_pct.
+func (r *PingRunner) BuildCommand(config Config) string {
+	envPrefix := buildEnvPrefix(config)
+	if config.Role == "server" {
+		return envPrefix + "true"
+	}
+
+	effectiveArgs := config.GetEffectiveArgs()
+	count := defaultPingCount
+	if value, ok := effectiveArgs["count"].(int); ok && value > 0 {
+		count = value
+	}
+	interval := defaultPingInterval
+	if value, ok := pingNumber(effectiveArgs["interval"]); ok && value > 0 {
+		interval = value
+	}
+
+	targetHost := config.TargetHost
+	if targetHost == "" {
+		targetHost = config.Host
+	}
+
+	cmd := fmt.Sprintf("%s -c %d -i %s", r.executablePath, count, strconv.FormatFloat(interval, 'f', -1, 64))
+	if size, ok := effectiveArgs["size"].(int); ok && size > 0 {
+		cmd += fmt.Sprintf(" -s %d", size)
+	}
+	maxLoss := 100.0
+	if value, ok := pingNumber(effectiveArgs["max_loss_pct"]); ok {
+		maxLoss = value
+	}
+	return envPrefix + cmd + " " + targetHost + " | " + pingLossCheck(maxLoss)
+}
+
+// pingLossCheck returns an awk filter that passes ping's output through and
+// sets the exit status from its summary line, whose fourth field is the
+// reply count in both iputils and busybox. ping's own status cannot be
+// relied on, as implementations differ on partial loss.
+func pingLossCheck(maxLoss float64) string {
+	return fmt.Sprintf(`awk -v max=%s '{ print } / packets transmitted, / { received = $4; for (i = 5; i <= NF; i++) if ($i ~ /%%$/) loss = $i + 0; summary = 1 } END { exit !(summary && received > 0 && loss <= max) }'`,
+		strconv.FormatFloat(maxLoss, 'f', -1, 64))
+}
+
+// pingNumber returns a numeric arg, given as a number or a numeric string
+func pingNumber(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case int:
+		return float64(v), true
+	case float64:
+		return v, true
+	case string:
+		interval, err := strconv.ParseFloat(v, 64)
+		return interval, err == nil
+	}
+	return 0, false
+}
+
+// ParseMetrics extracts the round-trip summary and packet loss of a run
+func (r *PingRunner) ParseMetrics(result *Result) error {
+	if result == nil {
+		return fmt.Errorf("result cannot be nil")
+	}
+
+	if result.Metrics == nil {
+		result.Metrics = make(map[string]interface{})
+	}
+
+	if match := pingRTTRegex.FindStringSubmatch(result.Output); match != nil {
+		for i, key := range []string{"rtt_min_ms", "rtt_avg_ms", "rtt_max_ms", "rtt_mdev_ms"} {
+			if value, err := strconv.ParseFloat(match[i+2], 64); err == nil {
+				result.Metrics[key] = value
+			}
+		}
+	}
+
+	if match := pingLossRegex.FindStringSubmatch(result.Output); match != nil {
+		if transmitted, err := strconv.ParseInt(match[1], 10, 64); err == nil {
+			result.Metrics["packets_transmitted"] = transmitted
+		}
+		if received, err := strconv.ParseInt(match[2], 10, 64); err == nil {
+			result.Metrics["packets_received"] = received
+		}
+		if loss, err := strconv.ParseFloat(match[3], 64); err == nil {
+			result.Metrics["packet_loss_pct"] = loss
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,134 @@
+package runner
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+const pingSample = `PING 10.0.0.2 (10.0.0.2) 56(84) bytes of data.
+64 bytes from 10.0.0.2: icmp_seq=1 ttl=64 time=0.052 ms
+64 bytes from 10.0.0.2: icmp_seq=2 ttl=64 time=0.048 ms
+
+--- 10.0.0.2 ping statistics ---
+10 packets transmitted, 9 received, 10% packet loss, time 9213ms
+rtt min/avg/max/mdev = 0.041/0.050/0.063/0.006 ms
+`
+
+func TestPingRunner_BuildCommand(t *testing.T) {
+	r := NewPingRunner("")
+	config := Config{Role: "client", TargetHost: "10.0.0.2"}
+	if got, want := r.BuildCommand(config), "ping -c 10 -i 1 10.0.0.2 | "+pingLossCheck(100); got != want {
+		t.Errorf("default client command = %q, want %q", got, want)
+	}
+
+	config.Args = map[string]interface{}{"count": 100, "interval": 0.2, "size": 1472, "max_loss_pct": 5}
+	if got, want := r.BuildCommand(config), "ping -c 100 -i 0.2 -s 1472 10.0.0.2 | "+pingLossCheck(5); got != want {
+		t.Errorf("client command = %q, want %q", got, want)
+	}
+	if got := Executables(r, config); strings.Join(got, " ") != "ping awk" {
+		t.Errorf("Expected the client to need ping and awk, got %v", got)
+	}
+
+	config.Role = "server"
+	if got := r.BuildCommand(config); got != "true" {
+		t.Errorf("Expected a no-op server, got %q", got)
+	}
+	if got := Executables(r, config); len(got) != 1 || got[0] != "true" {
+		t.Errorf("Expected the server to need only true, got %v", got)
+	}
+}
+
+func TestPingRunner_Validate(t *testing.T) {
+	r := NewPingRunner("")
+	tests := []struct {
+		name    string
+		config  Config
+		wantErr bool
+	}{
+		{"client", Config{Role: "client", TargetHost: "h", Args: map[string]interface{}{"interval": "0.5"}}, false},
+		{"server", Config{Role: "server"}, false},
+		{"no target", Config{Role: "client"}, true},
+		{"zero count", Config{Role: "client", Host: "h", Args: map[string]interface{}{"count": 0}}, true},
+		{"bad interval", Config{Role: "client", Host: "h", Args: map[string]interface{}{"interval": "fast"}}, true},
+		{"max loss", Config{Role: "client", Host: "h", Args: map[string]interface{}{"max_loss_pct": 2.5}}, false},
+		{"max loss above 100", Config{Role: "client", Host: "h", Args: map[string]interface{}{"max_loss_pct": 150}}, true},
+		{"intermediate", Config{Role: "intermediate"}, true},
+	}
+	for _, tt := range tests {
+		if err := r.Validate(tt.config); (err != nil) != tt.wantErr {
+			t.Errorf("%s: error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestPingLossCheck(t *testing.T) {
+	if _, err := exec.LookPath("awk"); err != nil {
+		t.Skip("awk not installed")
+	}
+	busybox := "10 packets transmitted, 10 packets received, 0% packet loss\n"
+	noReply := "10 packets transmitted, 0 received, +10 errors, 100% packet loss, time 9213ms\n"
+	tests := []struct {
+		name    string
+		output  string
+		maxLoss float64
+		pass    bool
+	}{
+		{"partial loss", pingSample, 100, true},
+		{"within threshold", pingSample, 10, true},
+		{"above threshold", pingSample, 5, false},
+		{"busybox", busybox, 0, true},
+		{"no reply", noReply, 100, false},
+		{"no summary", "ping: unknown host h\n", 100, false},
+	}
+	for _, tt := range tests {
+		cmd := exec.Command("sh", "-c", `printf '%s' "$PING_OUTPUT" | `+pingLossCheck(tt.maxLoss))
+		cmd.Env = append(cmd.Environ(), "PING_OUTPUT="+tt.output)
+		output, err := cmd.Output()
+		if pass := err == nil; pass != tt.pass {
+			t.Errorf("%s: passed = %v, want %v", tt.name, pass, tt.pass)
+		}
+		if string(output) != tt.output {
+			t.Errorf("%s: Expected the output passed through, got %q", tt.name, output)
+		}
+	}
+}
+
+func TestPingRunner_ParseMetrics(t *testing.T) {
+	result := &Result{Output: pingSample}
+	if err := NewPingRunner("").ParseMetrics(result); err != nil {
+		t.Fatalf("ParseMetrics returned error: %v", err)
+	}
+
+	want := map[string]interface{}{
+		"rtt_min_ms":          0.041,
+		"rtt_avg_ms":          0.050,
+		"rtt_max_ms":          0.063,
+		"rtt_mdev_ms":         0.006,
+		"packets_transmitted": int64(10),
+		"packets_received":    int64(9),
+		"packet_loss_pct":     10.0,
+	}
+	for key, value := range want {
+		if result.Metrics[key] != value {
+			t.Errorf("%s = %v, want %v", key, result.Metrics[key], value)
+		}
+	}
+}
+
+func TestPingRunner_ParseMetrics_Busybox(t *testing.T) {
+	output := `--- 10.0.0.2 ping statistics ---
+4 packets transmitted, 4 packets received, 0% packet loss
+round-trip min/avg/max = 0.120/0.210/0.390 ms
+`
+	result := &Result{Output: output}
+	if err := NewPingRunner("").ParseMetrics(result); err != nil {
+		t.Fatalf("ParseMetrics returned error: %v", err)
+	}
+	if result.Metrics["rtt_avg_ms"] != 0.21 || result.Metrics["packet_loss_pct"] != 0.0 {
+		t.Errorf("Unexpected metrics: %v", result.Metrics)
+	}
+	if _, exists := result.Metrics["rtt_mdev_ms"]; exists {
+		t.Error("Expected no mdev from busybox output")
+	}
+}
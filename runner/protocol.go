@@ -0,0 +1,69 @@
+package runner
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Transport protocols selected by the "protocol" arg
+const (
+	ProtocolTCP = "tcp"
+	ProtocolUDP = "udp"
+)
+
+// ProtocolSided is implemented by runners whose tools must be told the
+// protocol on other roles than the client, e.g. a UDP receiver that has to
+// listen for datagrams. Runners without it flag the protocol on the client
+// only, as iperf3 does, whose server follows the client's choice.
+type ProtocolSided interface {
+	// ProtocolRoles returns the roles whose commands carry the protocol flag
+	ProtocolRoles() []string
+}
+
+// Protocol returns the effective "protocol" arg for the config's role,
+// lowercased, or ProtocolTCP when none is set
+func (c *Config) Protocol() string {
+	protocol, _ := c.GetEffectiveArgs()["protocol"].(string)
+	if protocol == "" {
+		return ProtocolTCP
+	}
+	return strings.ToLower(protocol)
+}
+
+// validateProtocol checks that the config's protocol is TCP or UDP
+func validateProtocol(config Config) error {
+	switch protocol := config.Protocol(); protocol {
+	case ProtocolTCP, ProtocolUDP:
+		return nil
+	default:
+		return fmt.Errorf("invalid protocol '%s' (must be tcp or udp)", protocol)
+	}
+}
+
+// UsesProtocolFlag reports whether r's command for role carries the
+// protocol flag
+func UsesProtocolFlag(r Runner, role string) bool {
+	sided, ok := r.(ProtocolSided)
+	if !ok {
+		return role == "client"
+	}
+	for _, flagged := range sided.ProtocolRoles() {
+		if flagged == role {
+			return true
+		}
+	}
+	return false
+}
+
+// ProtocolMismatch returns an error when r flags the protocol on both the
+// client and the server but their configs select different protocols, which
+// leaves the server listening for traffic the client never sends
+func ProtocolMismatch(r Runner, client, server Config) error {
+	if !UsesProtocolFlag(r, "client") || !UsesProtocolFlag(r, "server") {
+		return nil
+	}
+	if clientProtocol, serverProtocol := client.Protocol(), server.Protocol(); clientProtocol != serverProtocol {
+		return fmt.Errorf("client uses %s but server uses %s", clientProtocol, serverProtocol)
+	}
+	return nil
+}
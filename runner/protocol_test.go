@@ -0,0 +1,52 @@
+package runner
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestProtocolFlagSides(t *testing.T) {
+	args := map[string]interface{}{"protocol": "UDP"}
+	tests := []struct {
+		runner     Runner
+		wantServer bool
+	}{
+		{NewIperf3Runner(""), false},
+		{NewNuttcpRunner(""), true},
+	}
+
+	for _, tt := range tests {
+		client := tt.runner.BuildCommand(Config{Role: "client", TargetHost: "10.0.0.2", Args: args})
+		server := tt.runner.BuildCommand(Config{Role: "server", Args: args})
+
+		if !strings.Contains(client, " -u") {
+			t.Errorf("%s: expected -u on the client, got %q", tt.runner.Name(), client)
+		}
+		if strings.Contains(server, " -u") != tt.wantServer {
+			t.Errorf("%s: expected -u on the server %v, got %q", tt.runner.Name(), tt.wantServer, server)
+		}
+	}
+}
+
+func TestProtocolMismatch(t *testing.T) {
+	client := Config{Role: "client", ClientArgs: map[string]interface{}{"protocol": "udp"}}
+	server := Config{Role: "server"}
+
+	if err := ProtocolMismatch(NewNuttcpRunner(""), client, server); err == nil {
+		t.Error("Expected a mismatch when only the nuttcp client uses UDP")
+	}
+	if err := ProtocolMismatch(NewIperf3Runner(""), client, server); err != nil {
+		t.Errorf("Expected no mismatch for iperf3, whose server follows the client: %v", err)
+	}
+	server.ServerArgs = map[string]interface{}{"protocol": "udp"}
+	if err := ProtocolMismatch(NewNuttcpRunner(""), client, server); err != nil {
+		t.Errorf("Expected no mismatch when both ends use UDP: %v", err)
+	}
+}
+
+func TestValidateProtocol(t *testing.T) {
+	config := Config{Role: "client", TargetHost: "h", Args: map[string]interface{}{"protocol": "sctp"}}
+	if err := NewIperf3Runner("").Validate(config); err == nil {
+		t.Error("Expected an error for an unknown protocol")
+	}
+}
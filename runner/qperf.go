@@ -0,0 +1,247 @@
+package runner
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Auto-register the qperf runner
+func init() {
+	Register("qperf", func() Runner {
+		return NewQperfRunner("")
+	})
+}
+
+// qperfBandwidthUnits converts qperf's bandwidth units to megabits per
+// second. qperf reports bytes with decimal prefixes, or bits with -ub.
+var qperfBandwidthUnits = map[string]float64{
+	"bytes/sec": 8e-6,
+	"KB/sec":    8e-3,
+	"MB/sec":    8,
+	"GB/sec":    8e3,
+	"TB/sec":    8e6,
+	"bits/sec":  1e-6,
+	"Kb/sec":    1e-3,
+	"Mb/sec":    1,
+	"Gb/sec":    1e3,
+	"Tb/sec":    1e6,
+}
+
+// qperfTimeUnits converts qperf's latency units to microseconds
+var qperfTimeUnits = map[string]float64{
+	"ns":  1e-3,
+	"us":  1,
+	"ms":  1e3,
+	"sec": 1e6,
+}
+
+// qperfRateUnits converts qperf's message rate units to messages per second
+var qperfRateUnits = map[string]float64{
+	"/sec":  1,
+	"K/sec": 1e3,
+	"M/sec": 1e6,
+	"G/sec": 1e9,
+}
+
+// QperfRunner implements the Runner interface for qperf, which measures
+// TCP, UDP, and RDMA bandwidth and latency. The client names the tests to
+// run, e.g. tcp_bw or rc_lat, and the server serves any of them.
+type QperfRunner struct {
+	executablePath string
+}
+
+// NewQperfRunner creates a new qperf runner
+func NewQperfRunner(executablePath string) *QperfRunner {
+	if executablePath == "" {
+		executablePath = "qperf"
+	}
+	return &QperfRunner{
+		executablePath: executablePath,
+	}
+}
+
+// Name returns the name of the runner
+func (r *QperfRunner) Name() string {
+	return "qperf"
+}
+
+// Description summarizes the runner for listings
+func (r *QperfRunner) Description() string {
+	return "Measures TCP/UDP/RDMA bandwidth and latency with qperf"
+}
+
+// SetExecutablePath sets the custom executable path for this runner
+func (r *QperfRunner) SetExecutablePath(path string) {
+	r.executablePath = path
+}
+
+// ExecutablePath returns the executable invoked on remote hosts
+func (r *QperfRunner) ExecutablePath() string {
+	return r.executablePath
+}
+
+// VersionCommand prints qperf's version, e.g. "qperf 0.4.11"
+func (r *QperfRunner) VersionCommand() string {
+	return r.executablePath + " --version"
+}
+
+// ServerMode reports that the qperf server keeps serving until it is stopped
+func (r *QperfRunner) ServerMode() ServerMode {
+	return ServerPersistent
+}
+
+// PrimaryMetric reports TCP bandwidth, present when tcp_bw is among the tests
+func (r *QperfRunner) PrimaryMetric() string {
+	return "tcp_bw_mbps"
+}
+
+// OutputStream parses stdout, where qperf prints its results
+func (r *QperfRunner) OutputStream() Stream {
+	return StreamStdout
+}
+
+// SupportsRole returns true if the runner supports the given role
+func (r *QperfRunner) SupportsRole(role string) bool {
+	return role == "client" || role == "server"
+}
+
+// Validate checks if the configuration is valid for qperf
+func (r *QperfRunner) Validate(config Config) error {
+	if !r.SupportsRole(config.Role) {
+		return fmt.Errorf("unsupported role: %s", config.Role)
+	}
+
+	if config.Role == "client" {
+		if config.TargetHost == "" && config.Host == "" {
+			return fmt.Errorf("target_host or host is required for client role")
+		}
+		tests, err := qperfTests(config.GetEffectiveArgs()["tests"])
+		if err != nil {
+			return err
+		}
+		if len(tests) == 0 {
+			return fmt.Errorf("tests is required for client role, e.g. [tcp_bw, tcp_lat]")
+		}
+	}
+
+	if config.Port < 0 || config.Port > 65535 {
+		return fmt.Errorf("port must be between 0 and 65535")
+	}
+
+	return nil
+}
+
+// BuildCommand constructs the full command line for remote execution. The
+// port is qperf's listen port, which both ends must agree on.
+func (r *QperfRunner) BuildCommand(config Config) string {
+	envPrefix := buildEnvPrefix(config)
+	effectiveArgs := config.GetEffectiveArgs()
+
+	cmd := r.executablePath
+	if config.Port > 0 {
+		cmd += fmt.Sprintf(" --listen_port %d", config.Port)
+	}
+
+	if config.Role == "client" {
+		if config.Duration > 0 {
+			cmd += fmt.Sprintf(" --time %d", int(config.Duration.Seconds()))
+		}
+		if size, ok := effectiveArgs["msg_size"].(string); ok && size != "" {
+			cmd += " --msg_size " + size
+		} else if size, ok := effectiveArgs["msg_size"].(int); ok && size > 0 {
+			cmd += fmt.Sprintf(" --msg_size %d", size)
+		}
+		if verbose, ok := effectiveArgs["verbose"].(bool); ok && verbose {
+			cmd += " -v"
+		}
+
+		targetHost := config.TargetHost
+		if targetHost == "" {
+			targetHost = config.Host
+		}
+		tests, _ := qperfTests(effectiveArgs["tests"])
+		cmd += " " + targetHost + " " + strings.Join(tests, " ")
+	}
+
+	return envPrefix + cmd
+}
+
+// qperfTests returns the test names of the tests arg, given as a list or a
+// space- or comma-separated string
+func qperfTests(value interface{}) ([]string, error) {
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return strings.FieldsFunc(v, func(c rune) bool { return c == ',' || c == ' ' }), nil
+	case []interface{}:
+		tests := make([]string, 0, len(v))
+		for _, item := range v {
+			name, ok := item.(string)
+			if !ok || name == "" {
+				return nil, fmt.Errorf("tests must be a list of test names, got %v", item)
+			}
+			tests = append(tests, name)
+		}
+		return tests, nil
+	case []string:
+		return v, nil
+	}
+	return nil, fmt.Errorf("tests must be a list of test names, got %T", value)
+}
+
+// ParseMetrics extracts the "key = value unit" lines qperf prints under each
+// test's name. Metrics are named after the test: bw becomes <test>_mbps,
+// latency <test>_usec, and msg_rate <test>_msg_rate; with -v the send_bw and
+// recv_bw lines become <test>_send_bw_mbps and <test>_recv_bw_mbps.
+func (r *QperfRunner) ParseMetrics(result *Result) error {
+	if result == nil {
+		return fmt.Errorf("result cannot be nil")
+	}
+
+	if result.Metrics == nil {
+		result.Metrics = make(map[string]interface{})
+	}
+
+	test := ""
+	for _, line := range strings.Split(result.Output, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if name, isHeader := strings.CutSuffix(trimmed, ":"); isHeader && !strings.Contains(name, " ") {
+			test = name
+			continue
+		}
+
+		key, value, found := strings.Cut(trimmed, "=")
+		fields := strings.Fields(value)
+		if !found || test == "" || len(fields) != 2 {
+			continue
+		}
+		number, err := strconv.ParseFloat(fields[0], 64)
+		if err != nil {
+			continue
+		}
+
+		key = strings.TrimSpace(key)
+		switch {
+		case key == "bw":
+			if scale, known := qperfBandwidthUnits[fields[1]]; known {
+				result.Metrics[test+"_mbps"] = number * scale
+			}
+		case key == "send_bw" || key == "recv_bw":
+			if scale, known := qperfBandwidthUnits[fields[1]]; known {
+				result.Metrics[test+"_"+key+"_mbps"] = number * scale
+			}
+		case key == "latency":
+			if scale, known := qperfTimeUnits[fields[1]]; known {
+				result.Metrics[test+"_usec"] = number * scale
+			}
+		case key == "msg_rate":
+			if scale, known := qperfRateUnits[fields[1]]; known {
+				result.Metrics[test+"_msg_rate"] = number * scale
+			}
+		}
+	}
+
+	return nil
+}
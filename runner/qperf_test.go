@@ -0,0 +1,83 @@
+package runner
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestQperfRunner_BuildCommand(t *testing.T) {
+	r := NewQperfRunner("")
+	config := Config{
+		Duration:   10 * time.Second,
+		Port:       19765,
+		TargetHost: "10.0.0.2",
+		Args:       map[string]interface{}{"tests": []interface{}{"tcp_bw", "tcp_lat"}, "msg_size": "64K"},
+	}
+
+	config.Role = "client"
+	if got, want := r.BuildCommand(config), "qperf --listen_port 19765 --time 10 --msg_size 64K 10.0.0.2 tcp_bw tcp_lat"; got != want {
+		t.Errorf("client command = %q, want %q", got, want)
+	}
+	config.Role = "server"
+	if got, want := r.BuildCommand(config), "qperf --listen_port 19765"; got != want {
+		t.Errorf("server command = %q, want %q", got, want)
+	}
+}
+
+func TestQperfRunner_Validate(t *testing.T) {
+	r := NewQperfRunner("")
+	tests := []struct {
+		name    string
+		config  Config
+		wantErr bool
+	}{
+		{"client with tests", Config{Role: "client", TargetHost: "h", Args: map[string]interface{}{"tests": "rc_bw,rc_lat"}}, false},
+		{"client without target", Config{Role: "client", Args: map[string]interface{}{"tests": []interface{}{"tcp_bw"}}}, true},
+		{"client without tests", Config{Role: "client", TargetHost: "h"}, true},
+		{"client with malformed tests", Config{Role: "client", TargetHost: "h", Args: map[string]interface{}{"tests": 3}}, true},
+		{"server needs no tests", Config{Role: "server"}, false},
+	}
+	for _, tt := range tests {
+		if err := r.Validate(tt.config); (err != nil) != tt.wantErr {
+			t.Errorf("%s: error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestQperfRunner_ParseMetrics(t *testing.T) {
+	result := &Result{Output: `tcp_bw:
+    bw        =  1.18 GB/sec
+    msg_rate  =    18 K/sec
+tcp_lat:
+    latency  =  26.4 us
+rc_lat:
+    latency  =  1.2 us
+rc_bw:
+    bw       =  3.2 GB/sec
+    send_bw  =  3.2 GB/sec
+    recv_bw  =  3.1 GB/sec
+`}
+	if err := NewQperfRunner("").ParseMetrics(result); err != nil {
+		t.Fatalf("ParseMetrics returned error: %v", err)
+	}
+
+	want := map[string]float64{
+		"tcp_bw_mbps":        9440,
+		"tcp_bw_msg_rate":    18000,
+		"tcp_lat_usec":       26.4,
+		"rc_lat_usec":        1.2,
+		"rc_bw_mbps":         25600,
+		"rc_bw_send_bw_mbps": 25600,
+		"rc_bw_recv_bw_mbps": 24800,
+	}
+	if len(result.Metrics) != len(want) {
+		t.Errorf("Expected %d metrics, got %v", len(want), result.Metrics)
+	}
+	for key, value := range want {
+		got, _ := result.Metrics[key].(float64)
+		if math.Abs(got-value) > 1e-6 {
+			t.Errorf("%s = %v, want %v", key, result.Metrics[key], value)
+		}
+	}
+}
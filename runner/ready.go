@@ -0,0 +1,29 @@
+package runner
+
+import "fmt"
+
+// ReadinessProbe tells how to confirm that a server or forwarder has started
+// and can carry traffic. At most one field is set; a zero probe means the
+// runner cannot tell.
+type ReadinessProbe struct {
+	// Command runs on the role's host and exits 0 once the role is ready
+	Command string
+
+	// LogPattern is an extended regular expression that a line of the
+	// role's stdout matches once the role is ready
+	LogPattern string
+}
+
+// ReadinessProber is implemented by runners that know how to tell when
+// their server or forwarder is ready, instead of the generic check that the
+// server's port is listening
+type ReadinessProber interface {
+	// ReadinessProbe returns the probe for the role started with config
+	ReadinessProbe(config Config) ReadinessProbe
+}
+
+// ListeningProbe returns a probe that succeeds once a TCP socket is
+// listening on port
+func ListeningProbe(port int) ReadinessProbe {
+	return ReadinessProbe{Command: fmt.Sprintf("ss -ltn | grep -q ':%d[[:space:]]'", port)}
+}
@@ -1,6 +1,7 @@
 package runner
 
 import (
+	"context"
 	"fmt"
 	"sort"
 	"strings"
@@ -30,6 +31,13 @@ type Config struct {
 	Host       string                 `yaml:"host"`        // SSH host or general host identifier
 	TargetHost string                 `yaml:"target_host"` // Specific target IP for client connections
 	Port       int                    `yaml:"port"`
+	
+	// Role-specific ports for NATed/forwarded topologies (default to Port)
+	ListenPort  int                   `yaml:"listen_port,omitempty"`  // Port the server listens on
+	ConnectPort int                   `yaml:"connect_port,omitempty"` // Port the client connects to
+	
+	// CPU list the command is pinned to with taskset -c (e.g. "2-5,8")
+	CPUAffinity string                `yaml:"cpu_affinity,omitempty"`
 }
 
 // Result represents the result of a test execution
@@ -42,8 +50,35 @@ type Result struct {
 	Metrics    map[string]interface{}   `json:"metrics,omitempty"`
 	StartTime  time.Time                `json:"start_time"`
 	EndTime    time.Time                `json:"end_time"`
+	
+	// TerminatedByUs is set when the coordinator deliberately stopped the command
+	// (e.g. a server still running after the client finished); its exit code is
+	// then not treated as a failure
+	TerminatedByUs bool                 `json:"terminated_by_us,omitempty"`
 }
 
+// ServerMode describes how a runner's server finishes
+type ServerMode int
+
+const (
+	// ServerPersistent servers keep running until the coordinator stops them
+	ServerPersistent ServerMode = iota
+	// ServerOneShot servers exit on their own once the client's test completes
+	ServerOneShot
+)
+
+// Stream selects which output stream a runner parses metrics from
+type Stream int
+
+const (
+	// StreamCombined parses stdout and stderr interleaved
+	StreamCombined Stream = iota
+	// StreamStdout parses stdout only
+	StreamStdout
+	// StreamStderr parses stderr only
+	StreamStderr
+)
+
 // Runner interface defines the contract for test program runners
 type Runner interface {
 	// Validate checks if the configuration is valid for this runner
@@ -63,6 +98,92 @@ type Runner interface {
 	
 	// SetExecutablePath sets the custom executable path for this runner
 	SetExecutablePath(path string)
+	
+	// ExecutablePath returns the executable invoked on remote hosts
+	ExecutablePath() string
+	
+	// ServerMode reports whether the server exits after one test or must be stopped
+	ServerMode() ServerMode
+	
+	// PrimaryMetric returns the key of the runner's headline client metric,
+	// used in summaries and to detect runs that measured nothing
+	PrimaryMetric() string
+	
+	// OutputStream returns the stream ParseMetrics should read
+	OutputStream() Stream
+}
+
+// CommandExecutor runs a command on the host a role ran on
+type CommandExecutor interface {
+	Execute(ctx context.Context, command string) (string, error)
+}
+
+// Cleaner is implemented by runners whose tools leave state behind, such as
+// runtime files, sockets, or lock files, that would break the next run
+type Cleaner interface {
+	// Cleanup removes what the role described by config left on its host.
+	// It is called after the role has finished, whether or not it succeeded.
+	Cleanup(ctx context.Context, executor CommandExecutor, config Config) error
+}
+
+// Stopper is implemented by runners whose tools can leave processes behind
+// that are not in the role command's process group, such as daemons. The
+// coordinator stops each background role's process group itself once the
+// role is done; the stop command runs on the role's host afterwards.
+type Stopper interface {
+	// BuildStopCommand returns a command that stops what the role described
+	// by config started, or "" if nothing beyond its process group is left
+	BuildStopCommand(config Config) string
+}
+
+// Versioned is implemented by runners that can report the version of their
+// tool, so hosts running different versions can be detected
+type Versioned interface {
+	// VersionCommand returns a command whose first output line holds the
+	// tool's version
+	VersionCommand() string
+}
+
+// Described is implemented by runners that describe themselves in one line
+// for listings such as -list-runners
+type Described interface {
+	// Description returns a one-line summary of what the runner measures
+	Description() string
+}
+
+// RoleExecutable is implemented by runners that launch a different program,
+// or more than one, depending on the role's configuration, such as the HTTP
+// server behind a wrk client
+type RoleExecutable interface {
+	// ExecutablesFor returns the programs run for config.Role, or nil for ExecutablePath
+	ExecutablesFor(config Config) []string
+}
+
+// Executables returns the programs r launches on a host with config's role
+func Executables(r Runner, config Config) []string {
+	if re, ok := r.(RoleExecutable); ok {
+		if paths := re.ExecutablesFor(config); len(paths) > 0 {
+			return paths
+		}
+	}
+	return []string{r.ExecutablePath()}
+}
+
+// MultiClientServer is implemented by runners whose server serves several
+// clients at once, as the clients of a fan-out scenario share one server
+type MultiClientServer interface {
+	// ServesMultipleClients reports whether the server accepts concurrent clients
+	ServesMultipleClients() bool
+}
+
+// ServesMultipleClients reports whether r's server accepts concurrent
+// clients. Runners that do not say, such as iperf3 or the perftest tools,
+// are assumed to serve one client at a time.
+func ServesMultipleClients(r Runner) bool {
+	if m, ok := r.(MultiClientServer); ok {
+		return m.ServesMultipleClients()
+	}
+	return false
 }
 
 // Registry holds all registered runners
@@ -75,11 +196,13 @@ var globalRegistry = &Registry{
 	runners: make(map[string]func() Runner),
 }
 
-// Register adds a runner factory to the global registry
+// Register adds a runner factory to the global registry, and its built-in
+// ParseMetrics to the parser registry under the same name
 func Register(name string, factory func() Runner) {
 	globalRegistry.mu.Lock()
 	defer globalRegistry.mu.Unlock()
 	globalRegistry.runners[name] = factory
+	RegisterParser(name, defaultParser(factory))
 }
 
 // Create creates a new runner instance by name
@@ -149,6 +272,17 @@ func buildEnvPrefix(config Config) string {
 	return strings.Join(envParts, " ") + " "
 }
 
+// sortedArgKeys returns the keys of args in order, so commands built from
+// them are the same on every run
+func sortedArgKeys(args map[string]interface{}) []string {
+	keys := make([]string, 0, len(args))
+	for key := range args {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
 // GetEffectiveArgs returns the effective arguments for the given role
 // Role-specific args (ServerArgs/ClientArgs) take precedence over general Args
 func (c *Config) GetEffectiveArgs() map[string]interface{} {
@@ -174,6 +308,18 @@ func (c *Config) GetEffectiveArgs() map[string]interface{} {
 	return effective
 }
 
+// GetEffectivePort returns the port for the given role
+// The server listens on ListenPort and the client connects to ConnectPort; both default to Port
+func (c *Config) GetEffectivePort() int {
+	switch {
+	case c.Role == "server" && c.ListenPort > 0:
+		return c.ListenPort
+	case c.Role == "client" && c.ConnectPort > 0:
+		return c.ConnectPort
+	}
+	return c.Port
+}
+
 // GetEffectiveEnv returns the effective environment variables for the given role
 // Role-specific env (ServerEnv/ClientEnv) take precedence over general Env
 func (c *Config) GetEffectiveEnv() map[string]string {
@@ -236,6 +236,28 @@ func TestConfig_Validation(t *testing.T) {
 	}
 }
 
+func TestConfig_GetEffectivePort(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   Config
+		expected int
+	}{
+		{"server defaults to port", Config{Role: "server", Port: 5201}, 5201},
+		{"client defaults to port", Config{Role: "client", Port: 5201}, 5201},
+		{"server uses listen port", Config{Role: "server", Port: 5201, ListenPort: 5301, ConnectPort: 6201}, 5301},
+		{"client uses connect port", Config{Role: "client", Port: 5201, ListenPort: 5301, ConnectPort: 6201}, 6201},
+		{"intermediate ignores role ports", Config{Role: "intermediate", Port: 5201, ConnectPort: 6201}, 5201},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.config.GetEffectivePort(); got != tt.expected {
+				t.Errorf("Expected port %d, got %d", tt.expected, got)
+			}
+		})
+	}
+}
+
 func TestResult_Structure(t *testing.T) {
 	result := &Result{
 		Success:   true,
@@ -329,6 +351,22 @@ func (r *TestRunner) SetExecutablePath(path string) {
 	// TestRunner doesn't need to do anything with the path
 }
 
+func (r *TestRunner) ExecutablePath() string {
+	return "test_command"
+}
+
+func (r *TestRunner) ServerMode() ServerMode {
+	return ServerPersistent
+}
+
+func (r *TestRunner) PrimaryMetric() string {
+	return "test_metric"
+}
+
+func (r *TestRunner) OutputStream() Stream {
+	return StreamCombined
+}
+
 func TestRunner_Interface(t *testing.T) {
 	// Test that TestRunner implements Runner interface
 	var runner Runner = &TestRunner{name: "test"}
@@ -403,4 +441,83 @@ func TestIbSendBwRunner_AutoRegistration(t *testing.T) {
 	if err := runner.ParseMetrics(result); err != nil {
 		t.Errorf("ParseMetrics should not return error: %v", err)
 	}
-}
\ No newline at end of file
+}
+
+func TestRunners_ServerMode(t *testing.T) {
+	tests := []struct {
+		runner Runner
+		want   ServerMode
+	}{
+		{NewIperf3Runner(""), ServerOneShot},
+		{NewIbSendBwRunner(""), ServerOneShot},
+		{NewTestpmdRunner(""), ServerPersistent},
+		{NewWrkRunner(""), ServerPersistent},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.runner.Name(), func(t *testing.T) {
+			if got := tt.runner.ServerMode(); got != tt.want {
+				t.Errorf("ServerMode() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunners_PrimaryMetric(t *testing.T) {
+	tests := []struct {
+		runner Runner
+		want   string
+	}{
+		{NewIperf3Runner(""), "bandwidth_mbps"},
+		{NewIbSendBwRunner(""), "bandwidth_average_mbps"},
+		{NewTestpmdRunner(""), "throughput_pps"},
+		{NewWrkRunner(""), "requests_per_sec"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.runner.Name(), func(t *testing.T) {
+			if got := tt.runner.PrimaryMetric(); got != tt.want {
+				t.Errorf("PrimaryMetric() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunners_OutputStream(t *testing.T) {
+	tests := []struct {
+		runner Runner
+		want   Stream
+	}{
+		{NewIperf3Runner(""), StreamCombined},
+		{NewIbSendBwRunner(""), StreamCombined},
+		{NewTestpmdRunner(""), StreamStdout},
+		{NewWrkRunner(""), StreamStdout},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.runner.Name(), func(t *testing.T) {
+			if got := tt.runner.OutputStream(); got != tt.want {
+				t.Errorf("OutputStream() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLowerIsBetter(t *testing.T) {
+	for metric, want := range map[string]bool{
+		"latency_avg_usec": true,
+		"rtt_avg_ms":       true,
+		"tcp_lat_usec":     true,
+		"packet_loss_pct":  true,
+		"lost_packets":     true,
+		"retransmits":      true,
+		"dropped_messages": true,
+		"bandwidth_mbps":   false,
+		"requests_per_sec": false,
+		"iops":             false,
+	} {
+		if got := LowerIsBetter(metric); got != want {
+			t.Errorf("LowerIsBetter(%q) = %v, want %v", metric, got, want)
+		}
+	}
+}
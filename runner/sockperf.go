@@ -0,0 +1,196 @@
+package runner
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Auto-register the sockperf runner
+func init() {
+	Register("sockperf", func() Runner {
+		return NewSockperfRunner("")
+	})
+}
+
+// Lines of sockperf's ping-pong summary, each prefixed with "sockperf: "
+var (
+	sockperfPercentileRegex   = regexp.MustCompile(`percentile\s+(\d+\.?\d*)\s*=\s*(\d+\.?\d*)`)
+	sockperfObservationsRegex = regexp.MustCompile(`Total\s+(\d+)\s+observations`)
+	sockperfAverageRegex      = regexp.MustCompile(`avg-latency=(\d+\.?\d*)`)
+	sockperfExtremeRegex      = regexp.MustCompile(`<(MIN|MAX)> observation\s*=\s*(\d+\.?\d*)`)
+	sockperfDroppedRegex      = regexp.MustCompile(`# dropped messages\s*=\s*(\d+)`)
+)
+
+// SockperfRunner implements the Runner interface for sockperf. The client
+// runs ping-pong against a sockperf server, which measures UDP latency
+// under the load set by the message rate.
+type SockperfRunner struct {
+	executablePath string
+}
+
+// NewSockperfRunner creates a new sockperf runner
+func NewSockperfRunner(executablePath string) *SockperfRunner {
+	if executablePath == "" {
+		executablePath = "sockperf"
+	}
+	return &SockperfRunner{
+		executablePath: executablePath,
+	}
+}
+
+// Name returns the name of the runner
+func (r *SockperfRunner) Name() string {
+	return "sockperf"
+}
+
+// Description summarizes the runner for listings
+func (r *SockperfRunner) Description() string {
+	return "Measures UDP latency percentiles under load with sockperf ping-pong"
+}
+
+// SetExecutablePath sets the custom executable path for this runner
+func (r *SockperfRunner) SetExecutablePath(path string) {
+	r.executablePath = path
+}
+
+// ExecutablePath returns the executable invoked on remote hosts
+func (r *SockperfRunner) ExecutablePath() string {
+	return r.executablePath
+}
+
+// VersionCommand prints the sockperf version banner
+func (r *SockperfRunner) VersionCommand() string {
+	return fmt.Sprintf("%s --version 2>&1 | head -1", r.executablePath)
+}
+
+// ServerMode reports that the sockperf server keeps serving until it is stopped
+func (r *SockperfRunner) ServerMode() ServerMode {
+	return ServerPersistent
+}
+
+// PrimaryMetric reports the median latency
+func (r *SockperfRunner) PrimaryMetric() string {
+	return "latency_p50_usec"
+}
+
+// OutputStream parses combined output; sockperf versions differ in where they print
+func (r *SockperfRunner) OutputStream() Stream {
+	return StreamCombined
+}
+
+// SupportsRole returns true if the runner supports the given role
+func (r *SockperfRunner) SupportsRole(role string) bool {
+	return role == "client" || role == "server"
+}
+
+// Validate checks if the configuration is valid for sockperf
+func (r *SockperfRunner) Validate(config Config) error {
+	if !r.SupportsRole(config.Role) {
+		return fmt.Errorf("unsupported role: %s", config.Role)
+	}
+
+	if config.Role == "client" && config.TargetHost == "" && config.Host == "" {
+		return fmt.Errorf("target_host or host is required for client role")
+	}
+
+	effectiveArgs := config.GetEffectiveArgs()
+	if size, ok := effectiveArgs["msg_size"].(int); ok && size <= 0 {
+		return fmt.Errorf("msg_size must be greater than 0")
+	}
+	switch mps := effectiveArgs["mps"].(type) {
+	case int:
+		if mps <= 0 {
+			return fmt.Errorf("mps must be greater than 0")
+		}
+	case string:
+		if _, err := strconv.Atoi(mps); err != nil && mps != "max" {
+			return fmt.Errorf("mps must be a number or \"max\", got %q", mps)
+		}
+	}
+
+	if config.Port < 0 || config.Port > 65535 {
+		return fmt.Errorf("port must be between 0 and 65535")
+	}
+
+	return nil
+}
+
+// BuildCommand constructs the full command line for remote execution
+func (r *SockperfRunner) BuildCommand(config Config) string {
+	envPrefix := buildEnvPrefix(config)
+	effectiveArgs := config.GetEffectiveArgs()
+
+	cmd := r.executablePath
+	if config.Role == "server" {
+		cmd += " server"
+		if config.Port > 0 {
+			cmd += fmt.Sprintf(" -p %d", config.Port)
+		}
+		return envPrefix + cmd
+	}
+
+	targetHost := config.TargetHost
+	if targetHost == "" {
+		targetHost = config.Host
+	}
+	cmd += " ping-pong -i " + targetHost
+	if config.Port > 0 {
+		cmd += fmt.Sprintf(" -p %d", config.Port)
+	}
+	if config.Duration > 0 {
+		cmd += fmt.Sprintf(" -t %d", int(config.Duration.Seconds()))
+	}
+	if size, ok := effectiveArgs["msg_size"].(int); ok && size > 0 {
+		cmd += fmt.Sprintf(" -m %d", size)
+	}
+	if mps, exists := effectiveArgs["mps"]; exists {
+		cmd += fmt.Sprintf(" --mps %v", mps)
+	}
+
+	return envPrefix + cmd
+}
+
+// ParseMetrics extracts the observation count and latency summary of a
+// ping-pong run. Every reported percentile becomes a metric, e.g.
+// "percentile 99.900" becomes latency_p99_9_usec.
+func (r *SockperfRunner) ParseMetrics(result *Result) error {
+	if result == nil {
+		return fmt.Errorf("result cannot be nil")
+	}
+
+	if result.Metrics == nil {
+		result.Metrics = make(map[string]interface{})
+	}
+
+	for _, line := range strings.Split(result.Output, "\n") {
+		if match := sockperfPercentileRegex.FindStringSubmatch(line); match != nil {
+			percentile, err := strconv.ParseFloat(match[1], 64)
+			value, valueErr := strconv.ParseFloat(match[2], 64)
+			if err != nil || valueErr != nil {
+				continue
+			}
+			name := strings.ReplaceAll(strconv.FormatFloat(percentile, 'f', -1, 64), ".", "_")
+			result.Metrics["latency_p"+name+"_usec"] = value
+		} else if match := sockperfExtremeRegex.FindStringSubmatch(line); match != nil {
+			if value, err := strconv.ParseFloat(match[2], 64); err == nil {
+				result.Metrics["latency_"+strings.ToLower(match[1])+"_usec"] = value
+			}
+		} else if match := sockperfObservationsRegex.FindStringSubmatch(line); match != nil {
+			if value, err := strconv.ParseInt(match[1], 10, 64); err == nil {
+				result.Metrics["observations"] = value
+			}
+		} else if match := sockperfAverageRegex.FindStringSubmatch(line); match != nil {
+			if value, err := strconv.ParseFloat(match[1], 64); err == nil {
+				result.Metrics["latency_avg_usec"] = value
+			}
+		} else if match := sockperfDroppedRegex.FindStringSubmatch(line); match != nil {
+			if value, err := strconv.ParseInt(match[1], 10, 64); err == nil {
+				result.Metrics["dropped_messages"] = value
+			}
+		}
+	}
+
+	return nil
+}
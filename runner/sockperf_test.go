@@ -0,0 +1,94 @@
+package runner
+
+import (
+	"testing"
+	"time"
+)
+
+const sockperfSample = `sockperf: == version #3.10-0.git5ebd327da983 ==
+sockperf[CLIENT] send on:sockperf: using recvfrom() to block on socket(s)
+[ 0] IP = 10.0.0.2        PORT = 11111 # UDP
+sockperf: Warmup stage (sending a few dummy messages)...
+sockperf: Starting test...
+sockperf: Test end (interrupted by timer)
+sockperf: Test ended
+sockperf: [Total Run] RunTime=10.000 sec; Warm up time=400 msec; SentMessages=436710; ReceivedMessages=436709
+sockperf: ========= Printing statistics for Server No: 0
+sockperf: [Valid Duration] RunTime=9.550 sec; SentMessages=417050; ReceivedMessages=417050
+sockperf: ====> avg-latency=11.453 (std-dev=1.210, mean-ad=0.723, median-ad=0.534, siqr=0.356, cv=0.106, std-error=0.002, 99.0% ci=[11.448, 11.458])
+sockperf: # dropped messages = 2; # duplicated messages = 0; # out-of-order messages = 0
+sockperf: Summary: Latency is 11.453 usec
+sockperf: Total 417050 observations; each percentile contains 4170.50 observations
+sockperf: ---> <MAX> observation =  162.061
+sockperf: ---> percentile 99.999 =   71.378
+sockperf: ---> percentile 99.990 =   28.564
+sockperf: ---> percentile 99.900 =   16.236
+sockperf: ---> percentile 99.000 =   14.036
+sockperf: ---> percentile 90.000 =   12.546
+sockperf: ---> percentile 75.000 =   11.791
+sockperf: ---> percentile 50.000 =   11.272
+sockperf: ---> percentile 25.000 =   10.844
+sockperf: ---> <MIN> observation =    9.734
+`
+
+func TestSockperfRunner_BuildCommand(t *testing.T) {
+	r := NewSockperfRunner("")
+	config := Config{
+		Duration:   10 * time.Second,
+		Port:       11111,
+		TargetHost: "10.0.0.2",
+		Args:       map[string]interface{}{"msg_size": 64, "mps": 100000},
+	}
+
+	config.Role = "client"
+	if got, want := r.BuildCommand(config), "sockperf ping-pong -i 10.0.0.2 -p 11111 -t 10 -m 64 --mps 100000"; got != want {
+		t.Errorf("client command = %q, want %q", got, want)
+	}
+	config.Role = "server"
+	if got, want := r.BuildCommand(config), "sockperf server -p 11111"; got != want {
+		t.Errorf("server command = %q, want %q", got, want)
+	}
+}
+
+func TestSockperfRunner_Validate(t *testing.T) {
+	r := NewSockperfRunner("")
+	tests := []struct {
+		name    string
+		config  Config
+		wantErr bool
+	}{
+		{"client", Config{Role: "client", TargetHost: "h", Args: map[string]interface{}{"mps": "max"}}, false},
+		{"client without target", Config{Role: "client"}, true},
+		{"invalid mps", Config{Role: "client", TargetHost: "h", Args: map[string]interface{}{"mps": "fast"}}, true},
+		{"server", Config{Role: "server"}, false},
+	}
+	for _, tt := range tests {
+		if err := r.Validate(tt.config); (err != nil) != tt.wantErr {
+			t.Errorf("%s: error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestSockperfRunner_ParseMetrics(t *testing.T) {
+	result := &Result{Output: sockperfSample}
+	if err := NewSockperfRunner("").ParseMetrics(result); err != nil {
+		t.Fatalf("ParseMetrics returned error: %v", err)
+	}
+
+	want := map[string]interface{}{
+		"observations":        int64(417050),
+		"dropped_messages":    int64(2),
+		"latency_avg_usec":    11.453,
+		"latency_min_usec":    9.734,
+		"latency_max_usec":    162.061,
+		"latency_p50_usec":    11.272,
+		"latency_p99_usec":    14.036,
+		"latency_p99_9_usec":  16.236,
+		"latency_p99_99_usec": 28.564,
+	}
+	for key, value := range want {
+		if result.Metrics[key] != value {
+			t.Errorf("%s = %v, want %v", key, result.Metrics[key], value)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package runner
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// TemplateVars are the scenario values args can reference, e.g.
+// "{{.ScenarioName}}-{{.Iteration}}.pcap"
+type TemplateVars struct {
+	ServerIP     string // Address the server is reached on
+	ScenarioName string
+	Iteration    int // Repeat number, starting at 1
+}
+
+// ExpandTemplates returns a copy of config whose string args, including
+// strings inside list args, have template references replaced by vars.
+// The args maps are copied, so config's own maps are left untouched.
+func ExpandTemplates(config Config, vars TemplateVars) (Config, error) {
+	var err error
+	if config.Args, err = expandArgs(config.Args, vars); err != nil {
+		return config, err
+	}
+	if config.ServerArgs, err = expandArgs(config.ServerArgs, vars); err != nil {
+		return config, err
+	}
+	if config.ClientArgs, err = expandArgs(config.ClientArgs, vars); err != nil {
+		return config, err
+	}
+	return config, nil
+}
+
+// expandArgs expands the templates in the values of args
+func expandArgs(args map[string]interface{}, vars TemplateVars) (map[string]interface{}, error) {
+	if args == nil {
+		return nil, nil
+	}
+	expanded := make(map[string]interface{}, len(args))
+	for key, value := range args {
+		result, err := expandValue(value, vars)
+		if err != nil {
+			return nil, fmt.Errorf("arg %s: %w", key, err)
+		}
+		expanded[key] = result
+	}
+	return expanded, nil
+}
+
+// expandValue expands a string, or the strings of a list; other values are
+// returned as they are
+func expandValue(value interface{}, vars TemplateVars) (interface{}, error) {
+	switch v := value.(type) {
+	case string:
+		return expandString(v, vars)
+	case []interface{}:
+		list := make([]interface{}, len(v))
+		for i, item := range v {
+			expanded, err := expandValue(item, vars)
+			if err != nil {
+				return nil, err
+			}
+			list[i] = expanded
+		}
+		return list, nil
+	}
+	return value, nil
+}
+
+// expandString executes s as a template over vars
+func expandString(s string, vars TemplateVars) (string, error) {
+	if !strings.Contains(s, "{{") {
+		return s, nil
+	}
+	tmpl, err := template.New("arg").Option("missingkey=error").Parse(s)
+	if err != nil {
+		return "", fmt.Errorf("invalid template %q: %w", s, err)
+	}
+	var out strings.Builder
+	if err := tmpl.Execute(&out, vars); err != nil {
+		return "", fmt.Errorf("invalid template %q: %w", s, err)
+	}
+	return out.String(), nil
+}
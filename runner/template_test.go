@@ -0,0 +1,47 @@
+package runner
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestExpandTemplates(t *testing.T) {
+	config := Config{
+		Args: map[string]interface{}{
+			"title":    "{{.ScenarioName}}-{{.Iteration}}",
+			"bind":     []interface{}{"--to", "{{.ServerIP}}"},
+			"parallel": 4,
+		},
+		ClientArgs: map[string]interface{}{"logfile": "/tmp/{{.ScenarioName}}.log"},
+	}
+	vars := TemplateVars{ServerIP: "10.0.0.2", ScenarioName: "tcp", Iteration: 2}
+
+	expanded, err := ExpandTemplates(config, vars)
+	if err != nil {
+		t.Fatalf("ExpandTemplates returned error: %v", err)
+	}
+	if expanded.Args["title"] != "tcp-2" {
+		t.Errorf("Expected title tcp-2, got %v", expanded.Args["title"])
+	}
+	if !reflect.DeepEqual(expanded.Args["bind"], []interface{}{"--to", "10.0.0.2"}) {
+		t.Errorf("Expected list entries expanded, got %v", expanded.Args["bind"])
+	}
+	if expanded.Args["parallel"] != 4 {
+		t.Errorf("Expected non-string args kept, got %v", expanded.Args["parallel"])
+	}
+	if expanded.ClientArgs["logfile"] != "/tmp/tcp.log" {
+		t.Errorf("Expected client args expanded, got %v", expanded.ClientArgs["logfile"])
+	}
+	if config.Args["title"] != "{{.ScenarioName}}-{{.Iteration}}" {
+		t.Errorf("Expected the original config untouched, got %v", config.Args["title"])
+	}
+}
+
+func TestExpandTemplates_UnknownVariable(t *testing.T) {
+	config := Config{Args: map[string]interface{}{"title": "{{.ServerIp}}"}}
+	_, err := ExpandTemplates(config, TemplateVars{})
+	if err == nil || !strings.Contains(err.Error(), "arg title") {
+		t.Errorf("Expected an error naming the arg, got %v", err)
+	}
+}
@@ -1,7 +1,9 @@
 package runner
 
 import (
+	"context"
 	"fmt"
+	"path"
 	"regexp"
 	"strconv"
 	"strings"
@@ -14,6 +16,25 @@ func init() {
 	})
 }
 
+// defaultHugepageDir is where DPDK creates its hugepage files unless
+// hugepage_dir is set
+const defaultHugepageDir = "/dev/hugepages"
+
+// filePrefixRegex matches file_prefix values that are safe to embed in paths
+var filePrefixRegex = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// hugepageDirRegex matches hugepage_dir values that are safe to pass to a shell
+var hugepageDirRegex = regexp.MustCompile(`^/[A-Za-z0-9._/-]*$`)
+
+// validateHugepageDir checks that dir is a clean absolute path below the
+// root, since Cleanup deletes files inside it
+func validateHugepageDir(dir string) error {
+	if !hugepageDirRegex.MatchString(dir) || path.Clean(dir) != dir || dir == "/" {
+		return fmt.Errorf("invalid hugepage_dir: %q (use a clean absolute path of letters, digits, '.', '_', '-' and '/', such as /dev/hugepages)", dir)
+	}
+	return nil
+}
+
 // TestpmdRunner implements the Runner interface for DPDK testpmd
 type TestpmdRunner struct {
 	executablePath string
@@ -34,11 +55,46 @@ func (r *TestpmdRunner) Name() string {
 	return "testpmd"
 }
 
+// Description summarizes the runner for listings
+func (r *TestpmdRunner) Description() string {
+	return "Measures DPDK packet forwarding rates with testpmd"
+}
+
 // SetExecutablePath sets the custom executable path for this runner
 func (r *TestpmdRunner) SetExecutablePath(path string) {
 	r.executablePath = path
 }
 
+// ExecutablePath returns the executable invoked on remote hosts
+func (r *TestpmdRunner) ExecutablePath() string {
+	return r.executablePath
+}
+
+// ServerMode reports that testpmd keeps forwarding until it is stopped
+func (r *TestpmdRunner) ServerMode() ServerMode {
+	return ServerPersistent
+}
+
+// PrimaryMetric reports the forwarding rate normalized to packets per second
+func (r *TestpmdRunner) PrimaryMetric() string {
+	return "throughput_pps"
+}
+
+// OutputStream parses stdout only; testpmd logs EAL/driver noise to stderr
+func (r *TestpmdRunner) OutputStream() Stream {
+	return StreamStdout
+}
+
+// testpmdForwardingPattern matches the line testpmd prints when packet
+// forwarding starts, e.g. "io packet forwarding - ports=2 - cores=1 - ..."
+const testpmdForwardingPattern = "packet forwarding - ports="
+
+// ReadinessProbe waits for testpmd to report that forwarding started; its
+// ports accept traffic only from then on
+func (r *TestpmdRunner) ReadinessProbe(config Config) ReadinessProbe {
+	return ReadinessProbe{LogPattern: testpmdForwardingPattern}
+}
+
 // SupportsRole returns true if the runner supports the given role
 func (r *TestpmdRunner) SupportsRole(role string) bool {
 	// testpmd is primarily designed for intermediate packet forwarding
@@ -89,6 +145,24 @@ func (r *TestpmdRunner) Validate(config Config) error {
 		}
 	}
 
+	// The file prefix names runtime files that Cleanup removes
+	if filePrefix, exists := effectiveArgs["file_prefix"]; exists {
+		if prefix, ok := filePrefix.(string); !ok || !filePrefixRegex.MatchString(prefix) {
+			return fmt.Errorf("invalid file_prefix: %v (use letters, digits, '_' or '-')", filePrefix)
+		}
+	}
+
+	// Cleanup deletes DPDK's files inside the hugepage directory
+	if hugepageDir, exists := effectiveArgs["hugepage_dir"]; exists {
+		dir, ok := hugepageDir.(string)
+		if !ok {
+			return fmt.Errorf("invalid hugepage_dir: %v (must be a path)", hugepageDir)
+		}
+		if err := validateHugepageDir(dir); err != nil {
+			return err
+		}
+	}
+
 	// For intermediate role, validate forwarding mode
 	if config.Role == "intermediate" {
 		if fwdMode, exists := effectiveArgs["forward_mode"]; exists {
@@ -111,6 +185,37 @@ func (r *TestpmdRunner) Validate(config Config) error {
 	return nil
 }
 
+// Cleanup removes the runtime directory and hugepage files DPDK leaves
+// behind for a file_prefix, which otherwise make the next run with the same
+// prefix fail to initialize. Without a file_prefix there is nothing to do,
+// since the default prefix may belong to another DPDK process. Hugepage
+// files are only removed when hugepage_dir is on a hugetlbfs mount.
+func (r *TestpmdRunner) Cleanup(ctx context.Context, executor CommandExecutor, config Config) error {
+	effectiveArgs := config.GetEffectiveArgs()
+	prefix, ok := effectiveArgs["file_prefix"].(string)
+	if !ok || !filePrefixRegex.MatchString(prefix) {
+		return nil
+	}
+
+	hugepageDir := defaultHugepageDir
+	if value, exists := effectiveArgs["hugepage_dir"]; exists {
+		dir, _ := value.(string)
+		if err := validateHugepageDir(dir); err != nil {
+			return fmt.Errorf("not removing testpmd runtime files for prefix %s: %w", prefix, err)
+		}
+		hugepageDir = dir
+	}
+	quotedDir := "'" + strings.ReplaceAll(hugepageDir, "'", `'\''`) + "'"
+
+	// Root uses /var/run/dpdk; other users $XDG_RUNTIME_DIR/dpdk or /tmp/dpdk
+	command := fmt.Sprintf(`rm -rf /var/run/dpdk/%[1]s "${XDG_RUNTIME_DIR:-/tmp}/dpdk/%[1]s"; `+
+		`if [ "$(stat -f -c %%T %[2]s 2>/dev/null)" = hugetlbfs ]; then rm -f %[2]s/%[1]smap_*; fi`, prefix, quotedDir)
+	if _, err := executor.Execute(ctx, command); err != nil {
+		return fmt.Errorf("failed to remove testpmd runtime files for prefix %s: %w", prefix, err)
+	}
+	return nil
+}
+
 // BuildCommand constructs the full command line for remote execution
 func (r *TestpmdRunner) BuildCommand(config Config) string {
 	// Build environment variable prefix
@@ -1,6 +1,8 @@
 package runner
 
 import (
+	"context"
+	"regexp"
 	"strings"
 	"testing"
 )
@@ -512,4 +514,91 @@ func TestTestpmdRunner_ValidationWithEffectiveArgs(t *testing.T) {
 	if err != nil {
 		t.Errorf("Validation should pass for intermediate role, got error: %v", err)
 	}
-}
\ No newline at end of file
+}
+// recordingExecutor records the commands it is asked to run
+type recordingExecutor struct {
+	commands []string
+}
+
+func (e *recordingExecutor) Execute(ctx context.Context, command string) (string, error) {
+	e.commands = append(e.commands, command)
+	return "", nil
+}
+
+func TestTestpmdRunner_CleanupRemovesFilePrefixFiles(t *testing.T) {
+	runner := NewTestpmdRunner("")
+	executor := &recordingExecutor{}
+	config := Config{
+		Role: "intermediate",
+		Args: map[string]interface{}{"file_prefix": "fwd1", "hugepage_dir": "/mnt/huge"},
+	}
+
+	if err := runner.Cleanup(context.Background(), executor, config); err != nil {
+		t.Fatalf("Cleanup returned error: %v", err)
+	}
+	if len(executor.commands) != 1 {
+		t.Fatalf("Expected one cleanup command, got %v", executor.commands)
+	}
+	for _, want := range []string{"rm -rf", "/var/run/dpdk/fwd1", "/dpdk/fwd1", "stat -f -c %T '/mnt/huge'", "= hugetlbfs", "rm -f '/mnt/huge'/fwd1map_*"} {
+		if !strings.Contains(executor.commands[0], want) {
+			t.Errorf("Expected cleanup command to contain %q, got %q", want, executor.commands[0])
+		}
+	}
+}
+
+func TestTestpmdRunner_CleanupRejectsUnsafeHugepageDir(t *testing.T) {
+	for _, dir := range []interface{}{"", "/", "mnt/huge", "/mnt/../etc", "/mnt/huge; rm -rf ~", 42} {
+		executor := &recordingExecutor{}
+		config := Config{
+			Role: "intermediate",
+			Args: map[string]interface{}{"file_prefix": "fwd1", "hugepage_dir": dir},
+		}
+
+		if err := NewTestpmdRunner("").Cleanup(context.Background(), executor, config); err == nil {
+			t.Errorf("hugepage_dir %q: expected an error", dir)
+		}
+		if len(executor.commands) != 0 {
+			t.Errorf("hugepage_dir %q: expected nothing removed, got %v", dir, executor.commands)
+		}
+		if err := NewTestpmdRunner("").Validate(config); err == nil {
+			t.Errorf("hugepage_dir %q: expected Validate to reject it", dir)
+		}
+	}
+}
+
+func TestTestpmdRunner_CleanupWithoutFilePrefix(t *testing.T) {
+	executor := &recordingExecutor{}
+	config := Config{Role: "intermediate", Args: map[string]interface{}{}}
+
+	if err := NewTestpmdRunner("").Cleanup(context.Background(), executor, config); err != nil {
+		t.Fatalf("Cleanup returned error: %v", err)
+	}
+	if len(executor.commands) != 0 {
+		t.Errorf("Expected no cleanup without a file_prefix, got %v", executor.commands)
+	}
+}
+
+func TestTestpmdRunner_ValidateFilePrefix(t *testing.T) {
+	runner := NewTestpmdRunner("")
+	for prefix, wantErr := range map[string]bool{"fwd1": false, "dpdk_a-2": false, "../etc": true, "a b": true} {
+		config := Config{Role: "intermediate", Args: map[string]interface{}{"file_prefix": prefix}}
+		if err := runner.Validate(config); (err != nil) != wantErr {
+			t.Errorf("file_prefix %q: error = %v, wantErr %v", prefix, err, wantErr)
+		}
+	}
+}
+
+func TestTestpmdRunner_ReadinessProbe(t *testing.T) {
+	probe := NewTestpmdRunner("").ReadinessProbe(Config{Role: "intermediate"})
+	if probe.Command != "" || probe.LogPattern == "" {
+		t.Fatalf("Expected a log pattern probe, got %+v", probe)
+	}
+
+	pattern := regexp.MustCompile(probe.LogPattern)
+	if !pattern.MatchString("io packet forwarding - ports=2 - cores=1 - streams=2 - NUMA support enabled, MP allocation mode: native") {
+		t.Error("Expected the forwarding start line to match")
+	}
+	if pattern.MatchString("Configuring Port 0 (socket 0)") {
+		t.Error("Expected port configuration not to match")
+	}
+}
@@ -0,0 +1,279 @@
+package runner
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Auto-register the wrk runner
+func init() {
+	Register("wrk", func() Runner {
+		return NewWrkRunner("")
+	})
+}
+
+// defaultWrkPort is the HTTP port used when no port is configured
+const defaultWrkPort = 8080
+
+var (
+	wrkPercentileRegex = regexp.MustCompile(`^\s*(\d+(?:\.\d+)?)%\s+([\d.]+)(us|ms|s|m)\s*$`)
+	wrkLatencyRegex    = regexp.MustCompile(`^\s*Latency\s+([\d.]+)(us|ms|s|m)\s+([\d.]+)(us|ms|s|m)\s+([\d.]+)(us|ms|s|m)`)
+	wrkRequestsRegex   = regexp.MustCompile(`^\s*(\d+) requests in`)
+	wrkSocketErrRegex  = regexp.MustCompile(`Socket errors: connect (\d+), read (\d+), write (\d+), timeout (\d+)`)
+	wrkTTFBAvgRegex    = regexp.MustCompile(`^\s*TTFB Avg:\s+([\d.]+)(us|ms|s|m)`)
+)
+
+// WrkRunner implements the Runner interface for the wrk HTTP benchmark.
+// The client runs wrk; the server runs an HTTP server (python3 -m http.server
+// unless server_command is set).
+type WrkRunner struct {
+	executablePath string
+}
+
+// NewWrkRunner creates a new wrk runner
+func NewWrkRunner(executablePath string) *WrkRunner {
+	if executablePath == "" {
+		executablePath = "wrk"
+	}
+	return &WrkRunner{
+		executablePath: executablePath,
+	}
+}
+
+// Name returns the name of the runner
+func (r *WrkRunner) Name() string {
+	return "wrk"
+}
+
+// Description summarizes the runner for listings
+func (r *WrkRunner) Description() string {
+	return "Measures HTTP request throughput and latency with wrk"
+}
+
+// SetExecutablePath sets the custom executable path for this runner
+func (r *WrkRunner) SetExecutablePath(path string) {
+	r.executablePath = path
+}
+
+// ExecutablePath returns the executable invoked on remote hosts
+func (r *WrkRunner) ExecutablePath() string {
+	return r.executablePath
+}
+
+// ExecutablesFor returns the programs each role actually starts: the
+// server_command program, or python3 for the default HTTP server, on servers;
+// wrk, plus python3 when ttfb_script is set, on clients
+func (r *WrkRunner) ExecutablesFor(config Config) []string {
+	effectiveArgs := config.GetEffectiveArgs()
+	if config.Role == "server" {
+		if serverCommand, ok := effectiveArgs["server_command"].(string); ok && serverCommand != "" {
+			return strings.Fields(serverCommand)[:1]
+		}
+		return []string{"python3"}
+	}
+	if script, ok := effectiveArgs["ttfb_script"].(string); ok && script != "" {
+		return []string{r.executablePath, "python3"}
+	}
+	return nil
+}
+
+// ServerMode reports that the HTTP server keeps serving until it is stopped
+func (r *WrkRunner) ServerMode() ServerMode {
+	return ServerPersistent
+}
+
+// ServesMultipleClients reports that the HTTP server serves many clients
+func (r *WrkRunner) ServesMultipleClients() bool {
+	return true
+}
+
+// PrimaryMetric reports requests per second
+func (r *WrkRunner) PrimaryMetric() string {
+	return "requests_per_sec"
+}
+
+// OutputStream parses stdout, where wrk and its Lua done() hook print results
+func (r *WrkRunner) OutputStream() Stream {
+	return StreamStdout
+}
+
+// SupportsRole returns true if the runner supports the given role
+func (r *WrkRunner) SupportsRole(role string) bool {
+	return role == "client" || role == "server"
+}
+
+// Validate checks if the configuration is valid for wrk
+func (r *WrkRunner) Validate(config Config) error {
+	if !r.SupportsRole(config.Role) {
+		return fmt.Errorf("unsupported role: %s", config.Role)
+	}
+
+	if config.Role == "client" && config.TargetHost == "" && config.Host == "" {
+		return fmt.Errorf("target_host or host is required for client role")
+	}
+
+	effectiveArgs := config.GetEffectiveArgs()
+	for _, key := range []string{"threads", "connections", "ttfb_requests"} {
+		if value, exists := effectiveArgs[key]; exists {
+			if n, ok := value.(int); ok && n <= 0 {
+				return fmt.Errorf("%s must be greater than 0", key)
+			}
+		}
+	}
+
+	if config.Port < 0 || config.Port > 65535 {
+		return fmt.Errorf("port must be between 0 and 65535")
+	}
+
+	return nil
+}
+
+// BuildCommand constructs the full command line for remote execution
+func (r *WrkRunner) BuildCommand(config Config) string {
+	envPrefix := buildEnvPrefix(config)
+	effectiveArgs := config.GetEffectiveArgs()
+
+	port := config.Port
+	if port <= 0 {
+		port = defaultWrkPort
+	}
+
+	if config.Role == "server" {
+		if serverCommand, ok := effectiveArgs["server_command"].(string); ok && serverCommand != "" {
+			return envPrefix + serverCommand
+		}
+		return envPrefix + fmt.Sprintf("python3 -m http.server %d", port)
+	}
+
+	targetHost := config.TargetHost
+	if targetHost == "" {
+		targetHost = config.Host
+	}
+
+	// Always request the latency distribution so percentiles can be parsed
+	cmd := r.executablePath + " --latency"
+
+	if threads, ok := effectiveArgs["threads"].(int); ok && threads > 0 {
+		cmd += fmt.Sprintf(" -t %d", threads)
+	}
+	if connections, ok := effectiveArgs["connections"].(int); ok && connections > 0 {
+		cmd += fmt.Sprintf(" -c %d", connections)
+	}
+	if config.Duration > 0 {
+		cmd += fmt.Sprintf(" -d %ds", int(config.Duration.Seconds()))
+	}
+	if timeout, ok := effectiveArgs["timeout"].(string); ok && timeout != "" {
+		cmd += fmt.Sprintf(" --timeout %s", timeout)
+	}
+
+	if script, ok := effectiveArgs["script"].(string); ok && script != "" {
+		cmd += fmt.Sprintf(" -s %s", script)
+	}
+
+	path, _ := effectiveArgs["path"].(string)
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	url := fmt.Sprintf("http://%s:%d%s", targetHost, port, path)
+	cmd += " " + url
+
+	// wrk's Lua hooks only see complete responses, so TTFB is timed by a
+	// separate probe (see examples/wrk-ttfb.py) over the same URL once the
+	// load run is done
+	if script, ok := effectiveArgs["ttfb_script"].(string); ok && script != "" {
+		cmd += fmt.Sprintf(" && python3 %s %s", script, url)
+		if requests, ok := effectiveArgs["ttfb_requests"].(int); ok && requests > 0 {
+			cmd += fmt.Sprintf(" %d", requests)
+		}
+	}
+
+	return envPrefix + cmd
+}
+
+// ParseMetrics extracts throughput, latency percentiles, and TTFB from wrk output
+func (r *WrkRunner) ParseMetrics(result *Result) error {
+	if result == nil {
+		return fmt.Errorf("result cannot be nil")
+	}
+
+	if result.Metrics == nil {
+		result.Metrics = make(map[string]interface{})
+	}
+
+	// Percentile rows follow either wrk's "Latency Distribution" header or
+	// the "TTFB Distribution" header printed by the TTFB script
+	prefix := ""
+	for _, line := range strings.Split(result.Output, "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(trimmed, "Latency Distribution"):
+			prefix = "latency"
+			continue
+		case strings.HasPrefix(trimmed, "TTFB Distribution"):
+			prefix = "ttfb"
+			continue
+		}
+
+		if m := wrkPercentileRegex.FindStringSubmatch(line); m != nil && prefix != "" {
+			if value, ok := wrkDurationMs(m[2], m[3]); ok {
+				result.Metrics[fmt.Sprintf("%s_p%s_ms", prefix, strings.TrimSuffix(m[1], ".000"))] = value
+			}
+			continue
+		}
+		prefix = ""
+
+		if m := wrkLatencyRegex.FindStringSubmatch(line); m != nil {
+			for i, key := range []string{"latency_avg_ms", "latency_stdev_ms", "latency_max_ms"} {
+				if value, ok := wrkDurationMs(m[1+2*i], m[2+2*i]); ok {
+					result.Metrics[key] = value
+				}
+			}
+		} else if m := wrkTTFBAvgRegex.FindStringSubmatch(line); m != nil {
+			if value, ok := wrkDurationMs(m[1], m[2]); ok {
+				result.Metrics["ttfb_ms"] = value
+			}
+		} else if m := wrkRequestsRegex.FindStringSubmatch(line); m != nil {
+			if requests, err := strconv.ParseInt(m[1], 10, 64); err == nil {
+				result.Metrics["requests"] = requests
+			}
+		} else if strings.HasPrefix(trimmed, "Requests/sec:") {
+			if rps, err := strconv.ParseFloat(strings.TrimSpace(strings.TrimPrefix(trimmed, "Requests/sec:")), 64); err == nil {
+				result.Metrics["requests_per_sec"] = rps
+			}
+		} else if m := wrkSocketErrRegex.FindStringSubmatch(line); m != nil {
+			total := 0
+			for _, count := range m[1:] {
+				n, _ := strconv.Atoi(count)
+				total += n
+			}
+			result.Metrics["socket_errors"] = total
+		} else if strings.HasPrefix(trimmed, "Non-2xx or 3xx responses:") {
+			if n, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(trimmed, "Non-2xx or 3xx responses:"))); err == nil {
+				result.Metrics["non_2xx_3xx_responses"] = n
+			}
+		}
+	}
+
+	return nil
+}
+
+// wrkDurationMs converts a wrk duration (value plus us/ms/s/m unit) to milliseconds
+func wrkDurationMs(value, unit string) (float64, bool) {
+	v, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, false
+	}
+	switch unit {
+	case "us":
+		return v / 1e3, true
+	case "ms":
+		return v, true
+	case "s":
+		return v * 1e3, true
+	case "m":
+		return v * 60e3, true
+	}
+	return 0, false
+}
@@ -0,0 +1,177 @@
+package runner
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const wrkLatencyOutput = `Running 30s test @ http://10.0.0.2:8080/
+  4 threads and 100 connections
+  Thread Stats   Avg      Stdev     Max   +/- Stdev
+    Latency     2.15ms    1.02ms   1.20s    89.00%
+    Req/Sec    11.95k     1.03k   14.23k    72.33%
+  Latency Distribution
+     50%    1.98ms
+     75%    2.50ms
+     90%    3.12ms
+     99%  850.00us
+  1427311 requests in 30.10s, 1.13GB read
+  Socket errors: connect 0, read 2, write 0, timeout 12
+Requests/sec:  47418.26
+Transfer/sec:     38.40MB
+`
+
+const wrkTTFBOutput = `Running 10s test @ http://10.0.0.2:8080/index.html
+  2 threads and 10 connections
+  Thread Stats   Avg      Stdev     Max   +/- Stdev
+    Latency   412.00us  120.00us   5.00ms   91.00%
+  Latency Distribution
+     50%  400.00us
+     75%  450.00us
+     90%  520.00us
+     99%    1.10ms
+  250000 requests in 10.00s, 20.00MB read
+Requests/sec:  25000.00
+Transfer/sec:      2.00MB
+TTFB Distribution
+  50%  0.40ms
+  75%  0.45ms
+  90%  0.52ms
+  99%  1.10ms
+TTFB Avg: 0.41ms
+`
+
+func TestWrkRunner_ParseMetrics_LatencyDistribution(t *testing.T) {
+	runner := NewWrkRunner("")
+	result := &Result{Output: wrkLatencyOutput}
+
+	if err := runner.ParseMetrics(result); err != nil {
+		t.Fatalf("ParseMetrics returned error: %v", err)
+	}
+
+	expected := map[string]interface{}{
+		"latency_p50_ms":   1.98,
+		"latency_p75_ms":   2.50,
+		"latency_p90_ms":   3.12,
+		"latency_p99_ms":   0.85,
+		"latency_avg_ms":   2.15,
+		"latency_stdev_ms": 1.02,
+		"latency_max_ms":   1200.0,
+		"requests_per_sec": 47418.26,
+		"requests":         int64(1427311),
+		"socket_errors":    14,
+	}
+	for key, want := range expected {
+		if got := result.Metrics[key]; got != want {
+			t.Errorf("%s = %v, want %v", key, got, want)
+		}
+	}
+	if _, ok := result.Metrics["ttfb_ms"]; ok {
+		t.Error("Expected no ttfb_ms without the TTFB script output")
+	}
+}
+
+func TestWrkRunner_ParseMetrics_TTFB(t *testing.T) {
+	runner := NewWrkRunner("")
+	result := &Result{Output: wrkTTFBOutput}
+
+	if err := runner.ParseMetrics(result); err != nil {
+		t.Fatalf("ParseMetrics returned error: %v", err)
+	}
+
+	expected := map[string]float64{
+		"ttfb_ms":        0.41,
+		"ttfb_p50_ms":    0.40,
+		"ttfb_p90_ms":    0.52,
+		"ttfb_p99_ms":    1.10,
+		"latency_p50_ms": 0.40,
+		"latency_avg_ms": 0.412,
+	}
+	for key, want := range expected {
+		if got := result.Metrics[key]; got != want {
+			t.Errorf("%s = %v, want %v", key, got, want)
+		}
+	}
+}
+
+func TestWrkRunner_BuildCommand(t *testing.T) {
+	runner := NewWrkRunner("")
+
+	tests := []struct {
+		name     string
+		config   Config
+		expected string
+	}{
+		{
+			name: "client with ttfb script",
+			config: Config{
+				Role:       "client",
+				TargetHost: "10.0.0.2",
+				Duration:   30 * time.Second,
+				Args: map[string]interface{}{
+					"threads":     4,
+					"connections": 100,
+					"path":        "index.html",
+					"ttfb_script": "/opt/wrk-ttfb.py",
+				},
+			},
+			expected: "wrk --latency -t 4 -c 100 -d 30s http://10.0.0.2:8080/index.html && python3 /opt/wrk-ttfb.py http://10.0.0.2:8080/index.html",
+		},
+		{
+			name: "client with lua script and ttfb requests",
+			config: Config{
+				Role:       "client",
+				TargetHost: "10.0.0.2",
+				Args: map[string]interface{}{
+					"script":        "/opt/post.lua",
+					"ttfb_script":   "/opt/wrk-ttfb.py",
+					"ttfb_requests": 500,
+				},
+			},
+			expected: "wrk --latency -s /opt/post.lua http://10.0.0.2:8080/ && python3 /opt/wrk-ttfb.py http://10.0.0.2:8080/ 500",
+		},
+		{
+			name:     "default server",
+			config:   Config{Role: "server", Port: 9000},
+			expected: "python3 -m http.server 9000",
+		},
+		{
+			name:     "custom server",
+			config:   Config{Role: "server", Args: map[string]interface{}{"server_command": "nginx -g 'daemon off;'"}},
+			expected: "nginx -g 'daemon off;'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := runner.BuildCommand(tt.config); got != tt.expected {
+				t.Errorf("BuildCommand() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestWrkRunner_Executables(t *testing.T) {
+	runner := NewWrkRunner("")
+
+	tests := []struct {
+		name     string
+		config   Config
+		expected []string
+	}{
+		{"client", Config{Role: "client"}, []string{"wrk"}},
+		{"client with ttfb", Config{Role: "client", Args: map[string]interface{}{"ttfb_script": "/opt/wrk-ttfb.py"}}, []string{"wrk", "python3"}},
+		{"default server", Config{Role: "server"}, []string{"python3"}},
+		{"custom server", Config{Role: "server", Args: map[string]interface{}{"server_command": "nginx -g 'daemon off;'"}}, []string{"nginx"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Executables(runner, tt.config)
+			if strings.Join(got, ",") != strings.Join(tt.expected, ",") {
+				t.Errorf("Executables() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
@@ -1,11 +1,15 @@
 package ssh
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"net"
 	"os"
 	"path/filepath"
+	"sync"
 	"time"
 
 	"golang.org/x/crypto/ssh"
@@ -20,21 +24,110 @@ type Config struct {
 	Password        string        `yaml:"password,omitempty"`
 	ConnectTimeout  time.Duration `yaml:"connect_timeout"`
 	CommandTimeout  time.Duration `yaml:"command_timeout"`
+	
+	// Passphrase for an encrypted private key, given directly or through an
+	// environment variable so it need not be stored in the YAML
+	KeyPassphrase    string `yaml:"key_passphrase,omitempty"`
+	KeyPassphraseEnv string `yaml:"key_passphrase_env,omitempty"`
+	
+	// Host key verification against an OpenSSH known_hosts file
+	KnownHostsPath    string `yaml:"known_hosts_path,omitempty"`     // Defaults to ~/.ssh/known_hosts
+	AcceptNewHostKeys bool   `yaml:"accept_new_host_keys,omitempty"` // Record keys of unknown hosts on first connect
+	InsecureHostKey   bool   `yaml:"insecure_host_key,omitempty"`    // Skip verification entirely
+	
+	// ProxyJump is a bastion host the connection is tunneled through, like
+	// ssh -J. It authenticates and verifies its host key on its own, and may
+	// itself have a proxy_jump.
+	ProxyJump *Config `yaml:"proxy_jump,omitempty"`
+	
+	// MaxSessions caps the sessions open at once on the connection, like
+	// sshd's MaxSessions; commands beyond it wait for a session to close.
+	// It must be at least MinSessions, since a host runs a command while
+	// its background role and its sampler each hold a session.
+	MaxSessions int `yaml:"max_sessions,omitempty"`
 }
 
+// DefaultMaxSessions matches OpenSSH's default MaxSessions
+const DefaultMaxSessions = 10
+
+// MinSessions is the smallest usable MaxSessions: a server or relay role
+// and the host's sampler each hold one session for the whole run while
+// readiness checks, the client, and the stop command need another
+const MinSessions = 3
+
+// DefaultCommandTimeout bounds a command when command_timeout is not set
+const DefaultCommandTimeout = 300 * time.Second
+
 // Client wraps SSH client functionality
 type Client struct {
 	config *Config
+	mu     sync.Mutex // Guards client and jump across reconnects
 	client *ssh.Client
+	jump   *Client // Connection to the proxy_jump host, if any
+	// sessions holds a token per open session, up to MaxSessions
+	sessions chan struct{}
 }
 
 // Result represents the result of a remote command execution
 type Result struct {
-	Output   string `json:"output"`
+	Output   string `json:"output"` // stdout and stderr interleaved
+	Stdout   string `json:"stdout,omitempty"`
+	Stderr   string `json:"stderr,omitempty"`
 	Error    string `json:"error,omitempty"`
 	ExitCode int    `json:"exit_code"`
 }
 
+// streamCapture records stdout and stderr separately while also keeping
+// them interleaved in arrival order, like CombinedOutput. If live is set,
+// each complete line is also written to it as it arrives.
+type streamCapture struct {
+	mu       sync.Mutex
+	combined bytes.Buffer
+	live     io.Writer
+}
+
+// streamWriter is one stream of a streamCapture
+type streamWriter struct {
+	capture *streamCapture
+	buf     bytes.Buffer
+	partial []byte // Start of a line not yet passed to live
+}
+
+func (w *streamWriter) Write(p []byte) (int, error) {
+	w.capture.mu.Lock()
+	defer w.capture.mu.Unlock()
+	w.buf.Write(p)
+	if w.capture.live != nil {
+		w.partial = append(w.partial, p...)
+		for {
+			end := bytes.IndexByte(w.partial, '\n')
+			if end < 0 {
+				break
+			}
+			w.capture.live.Write(w.partial[:end+1])
+			w.partial = w.partial[end+1:]
+		}
+	}
+	return w.capture.combined.Write(p)
+}
+
+// finish passes the streams' unterminated last lines to live and detaches
+// it, so output arriving after the command returned is not passed on
+func (c *streamCapture) finish(streams ...*streamWriter) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.live == nil {
+		return
+	}
+	for _, w := range streams {
+		if len(w.partial) > 0 {
+			c.live.Write(append(w.partial, '\n'))
+			w.partial = nil
+		}
+	}
+	c.live = nil
+}
+
 // NewClient creates a new SSH client
 func NewClient(config *Config) *Client {
 	if config.Port == 0 {
@@ -44,17 +137,21 @@ func NewClient(config *Config) *Client {
 		config.ConnectTimeout = 30 * time.Second
 	}
 	if config.CommandTimeout == 0 {
-		config.CommandTimeout = 300 * time.Second
+		config.CommandTimeout = DefaultCommandTimeout
+	}
+	if config.MaxSessions <= 0 {
+		config.MaxSessions = DefaultMaxSessions
 	}
 	
 	return &Client{
-		config: config,
+		config:   config,
+		sessions: make(chan struct{}, config.MaxSessions),
 	}
 }
 
 // Connect establishes an SSH connection
 func (c *Client) Connect(ctx context.Context) error {
-	if c.client != nil {
+	if c.conn() != nil {
 		return nil // Already connected
 	}
 	
@@ -79,39 +176,102 @@ func (c *Client) Connect(ctx context.Context) error {
 		return fmt.Errorf("no authentication method provided")
 	}
 	
+	hostKeyCallback, err := c.hostKeyCallback()
+	if err != nil {
+		return err
+	}
+	
 	// SSH client configuration
 	sshConfig := &ssh.ClientConfig{
 		User:            c.config.User,
 		Auth:            authMethods,
-		HostKeyCallback: ssh.InsecureIgnoreHostKey(), // In production, implement proper host key verification
+		HostKeyCallback: hostKeyCallback,
 		Timeout:         c.config.ConnectTimeout,
 	}
 	
 	// Connect
 	address := fmt.Sprintf("%s:%d", c.config.Host, c.config.Port)
 	
+	// Tunnel through the bastion when one is configured
+	var jump *Client
+	if c.config.ProxyJump != nil {
+		jump = NewClient(c.config.ProxyJump)
+		if err := jump.Connect(ctx); err != nil {
+			return fmt.Errorf("failed to connect to jump host %s: %w", c.config.ProxyJump.Host, err)
+		}
+	}
+	
 	// Use context for connection timeout
-	conn, err := c.dialWithContext(ctx, "tcp", address, sshConfig)
+	conn, err := c.dialWithContext(ctx, jump, "tcp", address, sshConfig)
 	if err != nil {
+		if jump != nil {
+			jump.Close()
+			return fmt.Errorf("failed to connect to %s through jump host %s: %w", address, c.config.ProxyJump.Host, err)
+		}
 		return fmt.Errorf("failed to connect to %s: %w", address, err)
 	}
 	
+	c.mu.Lock()
 	c.client = conn
+	c.jump = jump
+	c.mu.Unlock()
 	return nil
 }
 
+// conn returns the current connection, or nil when not connected
+func (c *Client) conn() *ssh.Client {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.client
+}
+
+// newSession opens a session on client once fewer than MaxSessions are
+// open, waiting for one to close otherwise. The returned function closes the
+// session and frees its slot; it may be called more than once.
+func (c *Client) newSession(ctx context.Context, client *ssh.Client) (*ssh.Session, func(), error) {
+	select {
+	case c.sessions <- struct{}{}:
+	case <-ctx.Done():
+		return nil, nil, fmt.Errorf("waiting for a free session: %w", ctx.Err())
+	}
+	
+	session, err := client.NewSession()
+	if err != nil {
+		<-c.sessions
+		return nil, nil, fmt.Errorf("failed to create session: %w", err)
+	}
+	
+	var once sync.Once
+	release := func() {
+		once.Do(func() {
+			session.Close()
+			<-c.sessions
+		})
+	}
+	return session, release, nil
+}
+
 // ExecuteCommand runs a command on the remote host
 func (c *Client) ExecuteCommand(ctx context.Context, command string) (*Result, error) {
-	if c.client == nil {
+	return c.ExecuteCommandStream(ctx, command, nil)
+}
+
+// ExecuteCommandStream runs a command on the remote host like ExecuteCommand,
+// also writing its stdout and stderr to live line by line as they arrive.
+// Each Write holds one complete line; a last line without a newline is
+// terminated when the command ends. A nil live only captures the output.
+func (c *Client) ExecuteCommandStream(ctx context.Context, command string, live io.Writer) (*Result, error) {
+	client := c.conn()
+	if client == nil {
 		return nil, fmt.Errorf("not connected")
 	}
 	
 	// Create session
-	session, err := c.client.NewSession()
+	session, release, err := c.newSession(ctx, client)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create session: %w", err)
+		return nil, err
 	}
-	defer session.Close()
+	defer release()
 	
 	result := &Result{}
 	
@@ -122,10 +282,21 @@ func (c *Client) ExecuteCommand(ctx context.Context, command string) (*Result, e
 	// Channel to receive command completion
 	done := make(chan error, 1)
 	
+	capture := &streamCapture{live: live}
+	stdout := &streamWriter{capture: capture}
+	stderr := &streamWriter{capture: capture}
+	session.Stdout = stdout
+	session.Stderr = stderr
+	defer capture.finish(stdout, stderr)
+	
 	go func() {
-		// Capture output
-		output, err := session.CombinedOutput(command)
-		result.Output = string(output)
+		// Capture output, keeping the streams apart for runners that parse only one
+		err := session.Run(command)
+		capture.mu.Lock()
+		result.Output = capture.combined.String()
+		result.Stdout = stdout.buf.String()
+		result.Stderr = stderr.buf.String()
+		capture.mu.Unlock()
 		
 		if err != nil {
 			result.Error = err.Error()
@@ -142,33 +313,35 @@ func (c *Client) ExecuteCommand(ctx context.Context, command string) (*Result, e
 	case err := <-done:
 		return result, err
 	case <-cmdCtx.Done():
-		// Try to close the session to terminate the command
-		session.Close()
+		// Ask the remote command to exit, then close the session
+		session.Signal(ssh.SIGTERM)
+		release()
 		return nil, fmt.Errorf("command timed out: %w", cmdCtx.Err())
 	}
 }
 
 // ExecuteCommandAsync runs a command without waiting for completion
 func (c *Client) ExecuteCommandAsync(ctx context.Context, command string) error {
-	if c.client == nil {
+	client := c.conn()
+	if client == nil {
 		return fmt.Errorf("not connected")
 	}
 	
-	session, err := c.client.NewSession()
+	session, release, err := c.newSession(ctx, client)
 	if err != nil {
-		return fmt.Errorf("failed to create session: %w", err)
+		return err
 	}
 	
 	// Start the command without waiting
 	if err := session.Start(command); err != nil {
-		session.Close()
+		release()
 		return fmt.Errorf("failed to start command: %w", err)
 	}
 	
 	// Close session in a goroutine to avoid blocking
 	go func() {
 		session.Wait()
-		session.Close()
+		release()
 	}()
 	
 	return nil
@@ -179,30 +352,69 @@ func (c *Client) Config() *Config {
 	return c.config
 }
 
-// Close closes the SSH connection
+// Close closes the SSH connection, then the jump host's connection it was
+// tunneled through
 func (c *Client) Close() error {
-	if c.client != nil {
-		err := c.client.Close()
-		c.client = nil
-		return err
+	c.mu.Lock()
+	client, jump := c.client, c.jump
+	c.client, c.jump = nil, nil
+	c.mu.Unlock()
+	
+	var err error
+	if client != nil {
+		err = client.Close()
 	}
-	return nil
+	if jump != nil {
+		if jumpErr := jump.Close(); err == nil {
+			err = jumpErr
+		}
+	}
+	return err
 }
 
 // IsConnected returns true if the client is connected
 func (c *Client) IsConnected() bool {
-	return c.client != nil
+	return c.conn() != nil
+}
+
+// Alive reports whether the connection still answers a keepalive request,
+// which distinguishes a dropped connection from a failing command. A
+// half-dead connection may never answer, so one that does not reply within
+// ConnectTimeout counts as dropped.
+func (c *Client) Alive() bool {
+	client := c.conn()
+	if client == nil {
+		return false
+	}
+	
+	replied := make(chan error, 1)
+	go func() {
+		// Returns once the connection is closed, if it never answers
+		_, _, err := client.SendRequest("keepalive@openssh.com", true, nil)
+		replied <- err
+	}()
+	
+	timer := time.NewTimer(c.config.ConnectTimeout)
+	defer timer.Stop()
+	select {
+	case err := <-replied:
+		return err == nil
+	case <-timer.C:
+		return false
+	}
+}
+
+// Reconnect drops the current connection, if any, and connects again
+func (c *Client) Reconnect(ctx context.Context) error {
+	c.Close()
+	return c.Connect(ctx)
 }
 
 // loadPrivateKey loads a private key from file
 func (c *Client) loadPrivateKey(keyPath string) (ssh.Signer, error) {
-	// Expand home directory
-	if keyPath[0] == '~' {
-		home, err := os.UserHomeDir()
-		if err != nil {
-			return nil, err
-		}
-		keyPath = filepath.Join(home, keyPath[1:])
+	keyPath, err := expandHome(keyPath)
+	if err != nil {
+		return nil, err
 	}
 	
 	keyData, err := os.ReadFile(keyPath)
@@ -210,23 +422,58 @@ func (c *Client) loadPrivateKey(keyPath string) (ssh.Signer, error) {
 		return nil, err
 	}
 	
-	// Try to parse the key
+	// Try to parse the key, decrypting it if it is passphrase protected
 	key, err := ssh.ParsePrivateKey(keyData)
+	var missing *ssh.PassphraseMissingError
+	if !errors.As(err, &missing) {
+		return key, err
+	}
+	
+	passphrase, err := c.keyPassphrase()
 	if err != nil {
 		return nil, err
 	}
-	
+	if passphrase == "" {
+		return nil, fmt.Errorf("private key %s is encrypted; set key_passphrase or key_passphrase_env", keyPath)
+	}
+	key, err = ssh.ParsePrivateKeyWithPassphrase(keyData, []byte(passphrase))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt private key %s: %w", keyPath, err)
+	}
 	return key, nil
 }
 
-// dialWithContext provides context-aware dialing
-func (c *Client) dialWithContext(ctx context.Context, network, address string, config *ssh.ClientConfig) (*ssh.Client, error) {
-	// Create a dialer with context
-	dialer := &net.Dialer{
-		Timeout: config.Timeout,
+// keyPassphrase returns the configured private key passphrase, reading it
+// from key_passphrase_env when that is set
+func (c *Client) keyPassphrase() (string, error) {
+	if c.config.KeyPassphraseEnv == "" {
+		return c.config.KeyPassphrase, nil
+	}
+	passphrase, ok := os.LookupEnv(c.config.KeyPassphraseEnv)
+	if !ok {
+		return "", fmt.Errorf("key passphrase environment variable %s is not set", c.config.KeyPassphraseEnv)
+	}
+	return passphrase, nil
+}
+
+// dialWithContext provides context-aware dialing, directly or through the
+// connection to a jump host
+func (c *Client) dialWithContext(ctx context.Context, jump *Client, network, address string, config *ssh.ClientConfig) (*ssh.Client, error) {
+	dialCtx := ctx
+	if config.Timeout > 0 {
+		var cancel context.CancelFunc
+		dialCtx, cancel = context.WithTimeout(ctx, config.Timeout)
+		defer cancel()
 	}
 	
-	conn, err := dialer.DialContext(ctx, network, address)
+	var conn net.Conn
+	var err error
+	if jump != nil {
+		conn, err = jump.conn().DialContext(dialCtx, network, address)
+	} else {
+		dialer := &net.Dialer{}
+		conn, err = dialer.DialContext(dialCtx, network, address)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -239,4 +486,16 @@ func (c *Client) dialWithContext(ctx context.Context, network, address string, c
 	}
 	
 	return ssh.NewClient(sshConn, chans, reqs), nil
-}
\ No newline at end of file
+}
+
+// expandHome replaces a leading ~ in path with the user's home directory
+func expandHome(path string) (string, error) {
+	if path == "" || path[0] != '~' {
+		return path, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, path[1:]), nil
+}
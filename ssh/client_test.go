@@ -0,0 +1,32 @@
+package ssh
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestAlive_StalledConnectionTimesOut(t *testing.T) {
+	server := newTestServer(t, "target")
+
+	config := server.config()
+	config.ConnectTimeout = 200 * time.Millisecond
+	client := NewClient(config)
+	if err := client.Connect(context.Background()); err != nil {
+		t.Fatalf("Connect returned error: %v", err)
+	}
+	defer client.Close()
+
+	if !client.Alive() {
+		t.Fatal("Expected a responsive connection to be alive")
+	}
+
+	server.stalled.Store(true)
+	start := time.Now()
+	if client.Alive() {
+		t.Error("Expected a connection that never answers the keepalive to be dropped")
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Expected the keepalive to give up after the connect timeout, took %v", elapsed)
+	}
+}
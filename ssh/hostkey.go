@@ -0,0 +1,102 @@
+package ssh
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// defaultKnownHostsPath is used when a host sets no known_hosts_path
+const defaultKnownHostsPath = "~/.ssh/known_hosts"
+
+// knownHostsMu serializes appends to known_hosts files by hosts that
+// connect concurrently
+var knownHostsMu sync.Mutex
+
+// hostKeyCallback verifies host keys against the configured known_hosts
+// file. Verification is skipped only with insecure_host_key.
+func (c *Client) hostKeyCallback() (ssh.HostKeyCallback, error) {
+	if c.config.InsecureHostKey {
+		return ssh.InsecureIgnoreHostKey(), nil
+	}
+
+	path := c.config.KnownHostsPath
+	if path == "" {
+		path = defaultKnownHostsPath
+	}
+	path, err := expandHome(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve known_hosts path: %w", err)
+	}
+
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) && c.config.AcceptNewHostKeys {
+		if err := createKnownHosts(path); err != nil {
+			return nil, err
+		}
+	}
+	verify, err := knownhosts.New(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load known_hosts file %s (set known_hosts_path, or accept_new_host_keys to create it): %w", path, err)
+	}
+
+	return func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+		err := verify(hostname, remote, key)
+		var keyErr *knownhosts.KeyError
+		if !errors.As(err, &keyErr) {
+			return err
+		}
+
+		fingerprint := ssh.FingerprintSHA256(key)
+		if len(keyErr.Want) > 0 {
+			want := keyErr.Want[0]
+			return fmt.Errorf("host key mismatch for %s: server sent %s %s but %s:%d has a different key; the host may have been reinstalled or the connection intercepted",
+				hostname, key.Type(), fingerprint, want.Filename, want.Line)
+		}
+		if !c.config.AcceptNewHostKeys {
+			return fmt.Errorf("host key for %s (%s %s) is not in %s; add it with ssh-keyscan or set accept_new_host_keys",
+				hostname, key.Type(), fingerprint, path)
+		}
+		return appendKnownHost(path, hostname, remote, key)
+	}, nil
+}
+
+// createKnownHosts creates an empty known_hosts file and its directory
+func createKnownHosts(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create known_hosts directory: %w", err)
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to create known_hosts file %s: %w", path, err)
+	}
+	return file.Close()
+}
+
+// appendKnownHost records the key of a host seen for the first time
+func appendKnownHost(path, hostname string, remote net.Addr, key ssh.PublicKey) error {
+	knownHostsMu.Lock()
+	defer knownHostsMu.Unlock()
+
+	addresses := []string{knownhosts.Normalize(hostname)}
+	if remote != nil {
+		if address := knownhosts.Normalize(remote.String()); address != addresses[0] {
+			addresses = append(addresses, address)
+		}
+	}
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to record host key for %s: %w", hostname, err)
+	}
+	defer file.Close()
+	if _, err := fmt.Fprintln(file, knownhosts.Line(addresses, key)); err != nil {
+		return fmt.Errorf("failed to record host key for %s: %w", hostname, err)
+	}
+	return nil
+}
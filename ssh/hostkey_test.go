@@ -0,0 +1,84 @@
+package ssh
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+func newHostKey(t *testing.T) ssh.PublicKey {
+	t.Helper()
+	pub, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := ssh.NewPublicKey(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func TestHostKeyCallback(t *testing.T) {
+	const hostname = "10.0.0.1:22"
+	remote := &net.TCPAddr{IP: net.ParseIP("10.0.0.1"), Port: 22}
+	known := newHostKey(t)
+
+	path := filepath.Join(t.TempDir(), "known_hosts")
+	line := knownhosts.Line([]string{knownhosts.Normalize(hostname)}, known) + "\n"
+	if err := os.WriteFile(path, []byte(line), 0600); err != nil {
+		t.Fatal(err)
+	}
+	callback := func(config *Config) ssh.HostKeyCallback {
+		t.Helper()
+		cb, err := NewClient(config).hostKeyCallback()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return cb
+	}
+
+	verify := callback(&Config{KnownHostsPath: path})
+	if err := verify(hostname, remote, known); err != nil {
+		t.Errorf("known key rejected: %v", err)
+	}
+	if err := verify(hostname, remote, newHostKey(t)); err == nil || !strings.Contains(err.Error(), "mismatch") {
+		t.Errorf("expected a mismatch error, got %v", err)
+	}
+	if err := verify("10.0.0.2:22", remote, newHostKey(t)); err == nil || !strings.Contains(err.Error(), "not in") {
+		t.Errorf("expected an unknown host error, got %v", err)
+	}
+
+	// Accepting new keys records them so the next connection verifies
+	fresh := newHostKey(t)
+	if err := callback(&Config{KnownHostsPath: path, AcceptNewHostKeys: true})("10.0.0.2:22", remote, fresh); err != nil {
+		t.Fatalf("new key not accepted: %v", err)
+	}
+	if err := callback(&Config{KnownHostsPath: path})("10.0.0.2:22", remote, fresh); err != nil {
+		t.Errorf("recorded key rejected: %v", err)
+	}
+
+	if err := callback(&Config{KnownHostsPath: path, InsecureHostKey: true})("10.0.0.3:22", remote, newHostKey(t)); err != nil {
+		t.Errorf("insecure mode rejected a key: %v", err)
+	}
+}
+
+func TestHostKeyCallback_MissingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ssh", "known_hosts")
+	if _, err := NewClient(&Config{KnownHostsPath: path}).hostKeyCallback(); err == nil {
+		t.Error("expected an error for a missing known_hosts file")
+	}
+	if _, err := NewClient(&Config{KnownHostsPath: path, AcceptNewHostKeys: true}).hostKeyCallback(); err != nil {
+		t.Fatalf("expected the file to be created: %v", err)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("known_hosts file not created: %v", err)
+	}
+}
@@ -0,0 +1,69 @@
+package ssh
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// writeKey writes a new ed25519 private key, encrypted when passphrase is set
+func writeKey(t *testing.T, passphrase string) string {
+	t.Helper()
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var block *pem.Block
+	if passphrase == "" {
+		block, err = ssh.MarshalPrivateKey(priv, "")
+	} else {
+		block, err = ssh.MarshalPrivateKeyWithPassphrase(priv, "", []byte(passphrase))
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "id_ed25519")
+	if err := os.WriteFile(path, pem.EncodeToMemory(block), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadPrivateKey(t *testing.T) {
+	plain := writeKey(t, "")
+	encrypted := writeKey(t, "secret")
+	t.Setenv("PERF_RUNNER_TEST_PASSPHRASE", "secret")
+
+	for _, tt := range []struct {
+		name    string
+		config  Config
+		wantErr string
+	}{
+		{"unencrypted", Config{KeyPath: plain}, ""},
+		{"unencrypted ignores passphrase", Config{KeyPath: plain, KeyPassphrase: "unused"}, ""},
+		{"passphrase", Config{KeyPath: encrypted, KeyPassphrase: "secret"}, ""},
+		{"passphrase from env", Config{KeyPath: encrypted, KeyPassphraseEnv: "PERF_RUNNER_TEST_PASSPHRASE"}, ""},
+		{"missing passphrase", Config{KeyPath: encrypted}, "is encrypted"},
+		{"wrong passphrase", Config{KeyPath: encrypted, KeyPassphrase: "wrong"}, "failed to decrypt"},
+		{"unset env", Config{KeyPath: encrypted, KeyPassphraseEnv: "PERF_RUNNER_TEST_UNSET"}, "is not set"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewClient(&tt.config).loadPrivateKey(tt.config.KeyPath)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected an error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
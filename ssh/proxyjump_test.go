@@ -0,0 +1,219 @@
+package ssh
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+)
+
+// testServer is an in-process SSH server accepting password "secret". It
+// answers every exec request with its name, after execDelay, serves SFTP on
+// the local filesystem, and forwards direct-tcpip channels, so it can act as
+// both a target and a jump host. A stalled server leaves global requests
+// such as keepalives unanswered, like a half-dead connection.
+type testServer struct {
+	name      string
+	listener  net.Listener
+	forwards  atomic.Int32
+	execDelay time.Duration
+	active    atomic.Int32 // Exec requests being answered
+	peak      atomic.Int32 // Most exec requests answered at once
+	stalled   atomic.Bool
+}
+
+func newTestServer(t *testing.T, name string) *testServer {
+	t.Helper()
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := ssh.NewSignerFromKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := &ssh.ServerConfig{
+		PasswordCallback: func(conn ssh.ConnMetadata, password []byte) (*ssh.Permissions, error) {
+			if string(password) != "secret" {
+				return nil, fmt.Errorf("wrong password")
+			}
+			return nil, nil
+		},
+	}
+	config.AddHostKey(signer)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	server := &testServer{name: name, listener: listener}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go server.serve(conn, config)
+		}
+	}()
+	return server
+}
+
+func (s *testServer) serve(conn net.Conn, config *ssh.ServerConfig) {
+	_, chans, reqs, err := ssh.NewServerConn(conn, config)
+	if err != nil {
+		conn.Close()
+		return
+	}
+	go func() {
+		for req := range reqs {
+			if req.WantReply && !s.stalled.Load() {
+				req.Reply(false, nil)
+			}
+		}
+	}()
+	for newChannel := range chans {
+		switch newChannel.ChannelType() {
+		case "session":
+			go s.serveSession(newChannel)
+		case "direct-tcpip":
+			go s.forward(newChannel)
+		default:
+			newChannel.Reject(ssh.UnknownChannelType, "unsupported")
+		}
+	}
+}
+
+func (s *testServer) serveSession(newChannel ssh.NewChannel) {
+	channel, requests, err := newChannel.Accept()
+	if err != nil {
+		return
+	}
+	for req := range requests {
+		var subsystem struct{ Name string }
+		if req.Type == "subsystem" && ssh.Unmarshal(req.Payload, &subsystem) == nil && subsystem.Name == "sftp" {
+			req.Reply(true, nil)
+			go ssh.DiscardRequests(requests)
+			s.serveSFTP(channel)
+			return
+		}
+		if req.Type != "exec" {
+			req.Reply(false, nil)
+			continue
+		}
+		req.Reply(true, nil)
+		active := s.active.Add(1)
+		for peak := s.peak.Load(); active > peak && !s.peak.CompareAndSwap(peak, active); peak = s.peak.Load() {
+		}
+		time.Sleep(s.execDelay)
+		s.active.Add(-1)
+		io.WriteString(channel, s.name)
+		channel.SendRequest("exit-status", false, ssh.Marshal(struct{ Status uint32 }{0}))
+		channel.Close()
+		return
+	}
+}
+
+// serveSFTP serves the local filesystem over channel until the client closes it
+func (s *testServer) serveSFTP(channel ssh.Channel) {
+	defer channel.Close()
+	server, err := sftp.NewServer(channel)
+	if err != nil {
+		return
+	}
+	server.Serve()
+	server.Close()
+}
+
+func (s *testServer) forward(newChannel ssh.NewChannel) {
+	var target struct {
+		Addr       string
+		Port       uint32
+		OriginAddr string
+		OriginPort uint32
+	}
+	if err := ssh.Unmarshal(newChannel.ExtraData(), &target); err != nil {
+		newChannel.Reject(ssh.ConnectionFailed, err.Error())
+		return
+	}
+	conn, err := net.Dial("tcp", net.JoinHostPort(target.Addr, strconv.Itoa(int(target.Port))))
+	if err != nil {
+		newChannel.Reject(ssh.ConnectionFailed, err.Error())
+		return
+	}
+	channel, requests, err := newChannel.Accept()
+	if err != nil {
+		conn.Close()
+		return
+	}
+	s.forwards.Add(1)
+	go ssh.DiscardRequests(requests)
+	go func() {
+		io.Copy(channel, conn)
+		channel.CloseWrite()
+	}()
+	io.Copy(conn, channel)
+	conn.Close()
+}
+
+// config returns a client config for the server
+func (s *testServer) config() *Config {
+	addr := s.listener.Addr().(*net.TCPAddr)
+	return &Config{Host: "127.0.0.1", Port: addr.Port, User: "test", Password: "secret", InsecureHostKey: true}
+}
+
+func TestConnect_ProxyJump(t *testing.T) {
+	bastion := newTestServer(t, "bastion")
+	target := newTestServer(t, "target")
+
+	config := target.config()
+	config.ProxyJump = bastion.config()
+	client := NewClient(config)
+	if err := client.Connect(context.Background()); err != nil {
+		t.Fatalf("Connect returned error: %v", err)
+	}
+
+	result, err := client.ExecuteCommand(context.Background(), "hostname")
+	if err != nil {
+		t.Fatalf("ExecuteCommand returned error: %v", err)
+	}
+	if result.Output != "target" {
+		t.Errorf("Expected the command to run on the target, got %q", result.Output)
+	}
+	if got := bastion.forwards.Load(); got != 1 {
+		t.Errorf("Expected one connection forwarded by the bastion, got %d", got)
+	}
+
+	jump := client.jump
+	if err := client.Close(); err != nil {
+		t.Errorf("Close returned error: %v", err)
+	}
+	if jump.IsConnected() || client.IsConnected() {
+		t.Error("Expected both connections closed")
+	}
+}
+
+func TestConnect_ProxyJumpFailure(t *testing.T) {
+	bastion := newTestServer(t, "bastion")
+	target := newTestServer(t, "target")
+
+	config := target.config()
+	config.ProxyJump = bastion.config()
+	config.ProxyJump.Password = "wrong"
+	err := NewClient(config).Connect(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "jump host") {
+		t.Errorf("Expected a jump host error, got %v", err)
+	}
+}
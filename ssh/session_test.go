@@ -0,0 +1,70 @@
+package ssh
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestExecuteCommand_QueuesBeyondMaxSessions(t *testing.T) {
+	server := newTestServer(t, "target")
+	server.execDelay = 50 * time.Millisecond
+
+	config := server.config()
+	config.MaxSessions = 2
+	client := NewClient(config)
+	if err := client.Connect(context.Background()); err != nil {
+		t.Fatalf("Connect returned error: %v", err)
+	}
+	defer client.Close()
+
+	const commands = 6
+	var wg sync.WaitGroup
+	errs := make(chan error, commands)
+	for i := 0; i < commands; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := client.ExecuteCommand(context.Background(), "hostname"); err != nil {
+				errs <- err
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Errorf("ExecuteCommand returned error: %v", err)
+	}
+	if peak := server.peak.Load(); peak > 2 {
+		t.Errorf("Expected at most 2 sessions at once, got %d", peak)
+	}
+	if peak := server.peak.Load(); peak < 2 {
+		t.Errorf("Expected sessions to run concurrently up to the limit, got %d", peak)
+	}
+}
+
+func TestExecuteCommand_WaitForSessionHonorsContext(t *testing.T) {
+	server := newTestServer(t, "target")
+	server.execDelay = time.Second
+
+	config := server.config()
+	config.MaxSessions = 1
+	client := NewClient(config)
+	if err := client.Connect(context.Background()); err != nil {
+		t.Fatalf("Connect returned error: %v", err)
+	}
+	defer client.Close()
+
+	go client.ExecuteCommand(context.Background(), "sleep")
+	for server.active.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := client.ExecuteCommand(ctx, "hostname"); err == nil {
+		t.Error("Expected an error while waiting for a free session")
+	}
+}
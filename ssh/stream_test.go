@@ -0,0 +1,47 @@
+package ssh
+
+import (
+	"bytes"
+	"testing"
+)
+
+// lineRecorder records each Write as one entry
+type lineRecorder struct {
+	writes []string
+}
+
+func (r *lineRecorder) Write(p []byte) (int, error) {
+	r.writes = append(r.writes, string(p))
+	return len(p), nil
+}
+
+func TestStreamCapture_PassesCompleteLines(t *testing.T) {
+	live := &lineRecorder{}
+	capture := &streamCapture{live: live}
+	stdout := &streamWriter{capture: capture}
+	stderr := &streamWriter{capture: capture}
+
+	stdout.Write([]byte("[ ID] Interval"))
+	stderr.Write([]byte("warning: low buffer\n"))
+	stdout.Write([]byte("\n[  5] 0.00-1.00 sec\n[  5] 1.00"))
+	capture.finish(stdout, stderr)
+	stdout.Write([]byte("-2.00 sec\n"))
+
+	want := []string{"warning: low buffer\n", "[ ID] Interval\n", "[  5] 0.00-1.00 sec\n", "[  5] 1.00\n"}
+	if len(live.writes) != len(want) {
+		t.Fatalf("Expected writes %q, got %q", want, live.writes)
+	}
+	for i := range want {
+		if live.writes[i] != want[i] {
+			t.Errorf("Write %d: expected %q, got %q", i, want[i], live.writes[i])
+		}
+	}
+
+	// The full output is still captured, including what arrived after finish
+	if got := stdout.buf.String(); got != "[ ID] Interval\n[  5] 0.00-1.00 sec\n[  5] 1.00-2.00 sec\n" {
+		t.Errorf("Unexpected captured stdout %q", got)
+	}
+	if !bytes.Contains(capture.combined.Bytes(), []byte("warning: low buffer\n")) {
+		t.Errorf("Expected stderr in the combined output, got %q", capture.combined.String())
+	}
+}
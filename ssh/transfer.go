@@ -0,0 +1,145 @@
+package ssh
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/pkg/sftp"
+)
+
+// UploadFile copies the local file at localPath to remotePath over SFTP on
+// the existing connection, creating the remote directory and keeping the
+// file's permission bits.
+func (c *Client) UploadFile(ctx context.Context, localPath, remotePath string) error {
+	file, err := os.Open(localPath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	if info.IsDir() {
+		return fmt.Errorf("%s is a directory", localPath)
+	}
+
+	err = c.withSFTP(ctx, func(client *sftp.Client) error {
+		if err := client.MkdirAll(path.Dir(remotePath)); err != nil {
+			return err
+		}
+		remote, err := client.OpenFile(remotePath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC)
+		if err != nil {
+			return err
+		}
+		if _, err := remote.ReadFrom(file); err != nil {
+			remote.Close()
+			return err
+		}
+		if err := remote.Close(); err != nil {
+			return err
+		}
+		return client.Chmod(remotePath, info.Mode().Perm())
+	})
+	if err != nil {
+		return fmt.Errorf("failed to upload %s to %s: %w", localPath, remotePath, err)
+	}
+	return nil
+}
+
+// DownloadFile copies remotePath over SFTP to the local file localPath,
+// creating the local directory and keeping the remote file's permission
+// bits. The local file is replaced only once the whole file has arrived.
+func (c *Client) DownloadFile(ctx context.Context, remotePath, localPath string) error {
+	if err := os.MkdirAll(filepath.Dir(localPath), 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(localPath), "."+filepath.Base(localPath)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	var mode os.FileMode
+	err = c.withSFTP(ctx, func(client *sftp.Client) error {
+		remote, err := client.Open(remotePath)
+		if err != nil {
+			return err
+		}
+		defer remote.Close()
+
+		info, err := remote.Stat()
+		if err != nil {
+			return err
+		}
+		mode = info.Mode().Perm()
+		_, err = remote.WriteTo(tmp)
+		return err
+	})
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to download %s: %w", remotePath, err)
+	}
+	if err := os.Chmod(tmp.Name(), mode); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), localPath)
+}
+
+// withSFTP runs fn with an SFTP client on a session of its own, which counts
+// against MaxSessions like any command, under the same timeout and
+// cancellation as ExecuteCommand
+func (c *Client) withSFTP(ctx context.Context, fn func(client *sftp.Client) error) error {
+	client := c.conn()
+	if client == nil {
+		return fmt.Errorf("not connected")
+	}
+
+	session, release, err := c.newSession(ctx, client)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	stdin, err := session.StdinPipe()
+	if err != nil {
+		return err
+	}
+	stdout, err := session.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := session.RequestSubsystem("sftp"); err != nil {
+		return fmt.Errorf("failed to start the sftp subsystem: %w", err)
+	}
+
+	cmdCtx, cancel := context.WithTimeout(ctx, c.config.CommandTimeout)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		sftpClient, err := sftp.NewClientPipe(stdout, stdin)
+		if err != nil {
+			done <- fmt.Errorf("failed to start sftp: %w", err)
+			return
+		}
+		defer sftpClient.Close()
+		done <- fn(sftpClient)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-cmdCtx.Done():
+		// Closing the session fails any request still in flight
+		release()
+		<-done // Nothing may be written locally once we return
+		return fmt.Errorf("transfer timed out: %w", cmdCtx.Err())
+	}
+}
@@ -0,0 +1,72 @@
+package ssh
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestTransfer_RoundTripKeepsContentAndMode(t *testing.T) {
+	server := newTestServer(t, "target")
+	client := NewClient(server.config())
+	if err := client.Connect(context.Background()); err != nil {
+		t.Fatalf("Connect returned error: %v", err)
+	}
+	defer client.Close()
+
+	dir := t.TempDir()
+	local := filepath.Join(dir, "tune.sh")
+	if err := os.WriteFile(local, []byte("#!/bin/sh\necho tuned\n"), 0750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(local, 0750); err != nil {
+		t.Fatal(err)
+	}
+
+	remote := filepath.Join(dir, "remote", "scripts", "tune.sh")
+	if err := client.UploadFile(context.Background(), local, remote); err != nil {
+		t.Fatalf("UploadFile returned error: %v", err)
+	}
+	back := filepath.Join(dir, "results", "tune.sh")
+	if err := client.DownloadFile(context.Background(), remote, back); err != nil {
+		t.Fatalf("DownloadFile returned error: %v", err)
+	}
+
+	for _, path := range []string{remote, back} {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(content) != "#!/bin/sh\necho tuned\n" {
+			t.Errorf("%s: unexpected content %q", path, content)
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if mode := info.Mode().Perm(); mode != 0750 {
+			t.Errorf("%s: expected mode 0750, got %o", path, mode)
+		}
+	}
+}
+
+func TestDownloadFile_MissingFileKeepsLocalFile(t *testing.T) {
+	server := newTestServer(t, "target")
+	client := NewClient(server.config())
+	if err := client.Connect(context.Background()); err != nil {
+		t.Fatalf("Connect returned error: %v", err)
+	}
+	defer client.Close()
+
+	local := filepath.Join(t.TempDir(), "capture.pcap")
+	if err := os.WriteFile(local, []byte("previous"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.DownloadFile(context.Background(), "/nonexistent/capture.pcap", local); err == nil {
+		t.Fatal("Expected an error for a missing remote file")
+	}
+	if content, _ := os.ReadFile(local); string(content) != "previous" {
+		t.Errorf("Expected the local file untouched, got %q", content)
+	}
+}